@@ -14,6 +14,7 @@ type Factory interface {
 type factory struct {
 	fs        boshsys.FileSystem
 	cmdRunner boshsys.CmdRunner
+	recorder  *cpiCallRecorder
 	logger    boshlog.Logger
 }
 
@@ -29,6 +30,21 @@ func NewFactory(
 	}
 }
 
+// NewRecordingFactory returns clouds that record all CPI calls into the file at recordPath
+func NewRecordingFactory(
+	fs boshsys.FileSystem,
+	cmdRunner boshsys.CmdRunner,
+	recordPath string,
+	logger boshlog.Logger,
+) Factory {
+	return &factory{
+		fs:        fs,
+		cmdRunner: cmdRunner,
+		recorder:  newCPICallRecorder(fs, recordPath),
+		logger:    logger,
+	}
+}
+
 func (f *factory) NewCloud(installation biinstall.Installation, directorID string) (Cloud, error) {
 	cpiJob := installation.Job()
 	target := installation.Target()
@@ -44,5 +60,27 @@ func (f *factory) NewCloud(installation biinstall.Installation, directorID strin
 	}
 
 	cpiCmdRunner := NewCPICmdRunner(f.cmdRunner, cpi, f.logger)
+	if f.recorder != nil {
+		cpiCmdRunner = newRecordingCPICmdRunner(cpiCmdRunner, f.recorder, f.logger)
+	}
+
 	return NewCloud(cpiCmdRunner, directorID, f.logger), nil
 }
+
+type replayFactory struct {
+	cpiCmdRunner CPICmdRunner
+	logger       boshlog.Logger
+}
+
+// NewReplayFactory returns clouds that serve CPI calls from the recording at replayPath
+// hence installed CPI is never executed
+func NewReplayFactory(fs boshsys.FileSystem, replayPath string, logger boshlog.Logger) Factory {
+	return &replayFactory{
+		cpiCmdRunner: NewReplayCPICmdRunner(fs, replayPath, logger),
+		logger:       logger,
+	}
+}
+
+func (f *replayFactory) NewCloud(_ biinstall.Installation, directorID string) (Cloud, error) {
+	return NewCloud(f.cpiCmdRunner, directorID, f.logger), nil
+}
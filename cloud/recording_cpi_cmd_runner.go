@@ -0,0 +1,189 @@
+package cloud
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const redactedValue = "<redacted>"
+
+var (
+	// Values of properties with these name fragments are never recorded
+	sensitivePropertyNames = []string{
+		"password", "secret", "private_key", "access_key", "json_key", "api_key", "token", "credential",
+	}
+
+	urlCredentialsRegexp = regexp.MustCompile(`://([^:/@\s]+):([^@/\s]+)@`)
+)
+
+// CPICallRecord is a single line of a CPI calls recording
+type CPICallRecord struct {
+	Input  CmdInput   `json:"input"`
+	Output *CmdOutput `json:"output,omitempty"`
+	Error  string     `json:"error,omitempty"`
+}
+
+type cpiCallRecorder struct {
+	fs   boshsys.FileSystem
+	path string
+
+	lines [][]byte
+	lock  sync.Mutex
+}
+
+func newCPICallRecorder(fs boshsys.FileSystem, path string) *cpiCallRecorder {
+	return &cpiCallRecorder{fs: fs, path: path}
+}
+
+// Record writes out all calls recorded so far so that recording
+// is complete even if the command is interrupted
+func (r *cpiCallRecorder) Record(input CmdInput, output CmdOutput, runErr error) error {
+	record := CPICallRecord{Input: input}
+
+	if runErr != nil {
+		record.Error = runErr.Error()
+	} else {
+		record.Output = &output
+	}
+
+	redactedRecord, err := redactCPICallRecord(record)
+	if err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(redactedRecord)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling CPI call record")
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.lines = append(r.lines, line)
+
+	contents := append(bytes.Join(r.lines, []byte("\n")), '\n')
+
+	err = r.fs.WriteFile(r.path, contents)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing CPI calls recording '%s'", r.path)
+	}
+
+	return nil
+}
+
+type recordingCPICmdRunner struct {
+	runner   CPICmdRunner
+	recorder *cpiCallRecorder
+	logger   boshlog.Logger
+	logTag   string
+}
+
+// NewRecordingCPICmdRunner records each CPI call made through runner
+// with credentials redacted as a JSON line into the file at path.
+func NewRecordingCPICmdRunner(runner CPICmdRunner, fs boshsys.FileSystem, path string, logger boshlog.Logger) CPICmdRunner {
+	return newRecordingCPICmdRunner(runner, newCPICallRecorder(fs, path), logger)
+}
+
+func newRecordingCPICmdRunner(runner CPICmdRunner, recorder *cpiCallRecorder, logger boshlog.Logger) CPICmdRunner {
+	return &recordingCPICmdRunner{
+		runner:   runner,
+		recorder: recorder,
+		logger:   logger,
+		logTag:   "recordingCPICmdRunner",
+	}
+}
+
+func (r *recordingCPICmdRunner) Run(context CmdContext, method string, args ...interface{}) (CmdOutput, error) {
+	cmdOutput, err := r.runner.Run(context, method, args...)
+
+	cmdInput := CmdInput{
+		Method:    method,
+		Arguments: args,
+		Context:   context,
+	}
+
+	// Failing to record must not fail the CPI call since its effects already happened
+	recordErr := r.recorder.Record(cmdInput, cmdOutput, err)
+	if recordErr != nil {
+		r.logger.Warn(r.logTag, "Failed to record CPI call '%s': %s", method, recordErr.Error())
+	}
+
+	return cmdOutput, err
+}
+
+func redactCPICallRecord(record CPICallRecord) (CPICallRecord, error) {
+	var redactedRecord CPICallRecord
+
+	// Round trip through JSON copies arguments so that they are not modified
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return redactedRecord, bosherr.WrapError(err, "Marshalling CPI call record")
+	}
+
+	err = json.Unmarshal(recordBytes, &redactedRecord)
+	if err != nil {
+		return redactedRecord, bosherr.WrapError(err, "Unmarshalling CPI call record")
+	}
+
+	for i, arg := range redactedRecord.Input.Arguments {
+		redactedRecord.Input.Arguments[i] = redactCPIValue(arg)
+	}
+
+	if redactedRecord.Output != nil {
+		redactedRecord.Output.Result = redactCPIValue(redactedRecord.Output.Result)
+		redactedRecord.Output.Log = redactCPIString(redactedRecord.Output.Log)
+	}
+
+	redactedRecord.Error = redactCPIString(redactedRecord.Error)
+
+	return redactedRecord, nil
+}
+
+func redactCPIValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for k, v := range typedValue {
+			if isSensitivePropertyName(k) {
+				typedValue[k] = redactedValue
+			} else {
+				typedValue[k] = redactCPIValue(v)
+			}
+		}
+		return typedValue
+
+	case []interface{}:
+		for i, v := range typedValue {
+			typedValue[i] = redactCPIValue(v)
+		}
+		return typedValue
+
+	case string:
+		return redactCPIString(typedValue)
+
+	default:
+		return value
+	}
+}
+
+func redactCPIString(value string) string {
+	return urlCredentialsRegexp.ReplaceAllString(value, "://$1:"+redactedValue+"@")
+}
+
+func isSensitivePropertyName(name string) bool {
+	name = strings.ToLower(name)
+
+	for _, sensitiveName := range sensitivePropertyNames {
+		if strings.Contains(name, sensitiveName) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,107 @@
+package cloud
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+type replayCPICmdRunner struct {
+	fs   boshsys.FileSystem
+	path string
+
+	records []CPICallRecord
+	next    int
+	loaded  bool
+	lock    sync.Mutex
+
+	logger boshlog.Logger
+	logTag string
+}
+
+// NewReplayCPICmdRunner serves CPI calls from a recording made by
+// recording CPI command runner instead of executing the CPI.
+// Calls must be made in the same order as they were recorded since
+// arguments (e.g. agent IDs) differ between runs and cannot be matched;
+// a call of a different method than the next record is an error.
+func NewReplayCPICmdRunner(fs boshsys.FileSystem, path string, logger boshlog.Logger) CPICmdRunner {
+	return &replayCPICmdRunner{
+		fs:     fs,
+		path:   path,
+		logger: logger,
+		logTag: "replayCPICmdRunner",
+	}
+}
+
+func (r *replayCPICmdRunner) Run(context CmdContext, method string, args ...interface{}) (CmdOutput, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	err := r.load()
+	if err != nil {
+		return CmdOutput{}, err
+	}
+
+	if r.next >= len(r.records) {
+		return CmdOutput{}, bosherr.Errorf(
+			"Expected CPI call '%s' to be recorded but all %d recorded calls in '%s' were already replayed",
+			method, len(r.records), r.path)
+	}
+
+	recordNum := r.next + 1
+	record := r.records[r.next]
+
+	if record.Input.Method != method {
+		return CmdOutput{}, bosherr.Errorf(
+			"Expected CPI call '%s' to match record %d in '%s' but it was recorded as '%s'",
+			method, recordNum, r.path, record.Input.Method)
+	}
+
+	r.next++
+
+	r.logger.Debug(r.logTag, "Replaying CPI call '%s' from record %d", method, recordNum)
+
+	if len(record.Error) > 0 {
+		return CmdOutput{}, bosherr.Errorf("Replayed CPI call '%s' error: %s", method, record.Error)
+	}
+
+	if record.Output == nil {
+		return CmdOutput{}, bosherr.Errorf("Expected CPI call record %d to include output", recordNum)
+	}
+
+	return *record.Output, nil
+}
+
+func (r *replayCPICmdRunner) load() error {
+	if r.loaded {
+		return nil
+	}
+
+	contents, err := r.fs.ReadFile(r.path)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Reading CPI calls recording '%s'", r.path)
+	}
+
+	for i, line := range bytes.Split(contents, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var record CPICallRecord
+
+		err := json.Unmarshal(line, &record)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Unmarshalling CPI call record on line %d", i+1)
+		}
+
+		r.records = append(r.records, record)
+	}
+
+	r.loaded = true
+
+	return nil
+}
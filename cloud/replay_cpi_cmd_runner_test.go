@@ -0,0 +1,102 @@
+package cloud_test
+
+import (
+	"errors"
+
+	. "github.com/cloudfoundry/bosh-cli/cloud"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReplayCPICmdRunner", func() {
+	var (
+		fs           *fakesys.FakeFileSystem
+		cpiCmdRunner CPICmdRunner
+		context      CmdContext
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		context = CmdContext{DirectorID: "fake-director-id"}
+
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		cpiCmdRunner = NewReplayCPICmdRunner(fs, "/some/cpi-calls.jsonl", logger)
+	})
+
+	Context("when recording exists", func() {
+		BeforeEach(func() {
+			fs.WriteFileString("/some/cpi-calls.jsonl", `{"input":{"method":"create_stemcell","arguments":["/image",{}]},"output":{"result":"fake-stemcell-cid","log":""}}
+{"input":{"method":"create_vm","arguments":["agent-1"]},"output":{"result":"fake-vm-cid-1","log":""}}
+{"input":{"method":"create_vm","arguments":["agent-2"]},"output":{"result":"fake-vm-cid-2","log":""}}
+{"input":{"method":"create_disk","arguments":[1024]},"error":"fake-create-disk-err"}
+
+`)
+		})
+
+		It("answers calls with recorded outputs in recorded order", func() {
+			output, err := cpiCmdRunner.Run(context, "create_stemcell", "/other-image", map[string]interface{}{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output).To(Equal(CmdOutput{Result: "fake-stemcell-cid"}))
+
+			output, err = cpiCmdRunner.Run(context, "create_vm", "other-agent")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output.Result).To(Equal("fake-vm-cid-1"))
+
+			output, err = cpiCmdRunner.Run(context, "create_vm", "other-agent")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output.Result).To(Equal("fake-vm-cid-2"))
+		})
+
+		It("returns recorded errors", func() {
+			for _, method := range []string{"create_stemcell", "create_vm", "create_vm"} {
+				_, err := cpiCmdRunner.Run(context, method)
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			_, err := cpiCmdRunner.Run(context, "create_disk", 1024)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Replayed CPI call 'create_disk' error: fake-create-disk-err"))
+		})
+
+		It("returns an error when call does not match method of the next record", func() {
+			_, err := cpiCmdRunner.Run(context, "create_stemcell")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = cpiCmdRunner.Run(context, "create_disk", 1024)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected CPI call 'create_disk' to match record 2 in '/some/cpi-calls.jsonl' but it was recorded as 'create_vm'"))
+
+			output, err := cpiCmdRunner.Run(context, "create_vm", "other-agent")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output.Result).To(Equal("fake-vm-cid-1"))
+		})
+
+		It("returns an error when all recorded calls were replayed", func() {
+			for _, method := range []string{"create_stemcell", "create_vm", "create_vm", "create_disk"} {
+				_, _ = cpiCmdRunner.Run(context, method)
+			}
+
+			_, err := cpiCmdRunner.Run(context, "delete_vm")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected CPI call 'delete_vm' to be recorded but all 4 recorded calls in '/some/cpi-calls.jsonl' were already replayed"))
+		})
+	})
+
+	It("returns an error when recording cannot be read", func() {
+		fs.RegisterReadFileError("/some/cpi-calls.jsonl", errors.New("fake-read-err"))
+
+		_, err := cpiCmdRunner.Run(context, "create_vm")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Reading CPI calls recording '/some/cpi-calls.jsonl'"))
+	})
+
+	It("returns an error when recording contains invalid lines", func() {
+		fs.WriteFileString("/some/cpi-calls.jsonl", "{\"input\":{}}\ninvalid-json\n")
+
+		_, err := cpiCmdRunner.Run(context, "create_vm")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unmarshalling CPI call record on line 2"))
+	})
+})
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/cloudfoundry/bosh-agent/agentclient"
+	bihttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http"
+	bihttpclient "github.com/cloudfoundry/bosh-utils/httpclient"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+
+	boshproxy "github.com/cloudfoundry/bosh-cli/proxy"
+)
+
+// agentClientFactory is the same as agent's HTTP client factory
+// except that agent connections go through BOSH_ALL_PROXY if it's set
+type agentClientFactory struct {
+	getTaskDelay time.Duration
+	logger       boshlog.Logger
+}
+
+func NewAgentClientFactory(getTaskDelay time.Duration, logger boshlog.Logger) bihttpagent.AgentClientFactory {
+	return agentClientFactory{getTaskDelay: getTaskDelay, logger: logger}
+}
+
+func (f agentClientFactory) NewAgentClient(directorID, mbusURL string) agentclient.AgentClient {
+	httpClient := bihttpclient.NewHTTPClient(boshproxy.CreateDefaultClientInsecureSkipVerify(), f.logger)
+	return bihttpagent.NewAgentClient(mbusURL, directorID, f.getTaskDelay, 10, httpClient, f.logger)
+}
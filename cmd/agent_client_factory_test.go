@@ -0,0 +1,45 @@
+package cmd_test
+
+import (
+	"net/http"
+	"time"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+)
+
+var _ = Describe("AgentClientFactory", func() {
+	var (
+		server *ghttp.Server
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewTLSServer()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("returns agent client that talks to agent at mbus URL", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/agent"),
+				ghttp.VerifyBody([]byte(`{"method":"ping","arguments":[],"reply_to":"director-id"}`)),
+				ghttp.RespondWith(http.StatusOK, `{"value":"pong"}`),
+			),
+		)
+
+		factory := NewAgentClientFactory(1*time.Second, boshlog.NewLogger(boshlog.LevelNone))
+
+		agentClient := factory.NewAgentClient("director-id", server.URL())
+
+		response, err := agentClient.Ping()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response).To(Equal("pong"))
+	})
+})
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	humanize "github.com/dustin/go-humanize"
+)
+
+// ByteSizeArg accepts sizes such as '500M' or '10GB'
+type ByteSizeArg uint64
+
+func (a *ByteSizeArg) UnmarshalFlag(data string) error {
+	size, err := humanize.ParseBytes(data)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Parsing size '%s'", data)
+	}
+
+	*a = ByteSizeArg(size)
+
+	return nil
+}
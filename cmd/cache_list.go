@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"time"
+
+	bitarball "github.com/cloudfoundry/bosh-cli/installation/tarball"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+type CacheListCmd struct {
+	cache bitarball.Cache
+	ui    boshui.UI
+}
+
+func NewCacheListCmd(cache bitarball.Cache, ui boshui.UI) CacheListCmd {
+	return CacheListCmd{cache: cache, ui: ui}
+}
+
+func (c CacheListCmd) Run() error {
+	entries, err := c.cache.List()
+	if err != nil {
+		return err
+	}
+
+	table := boshtbl.Table{
+		Content: "cached tarballs",
+
+		Header: []string{"URL", "SHA1", "Size", "Downloaded At", "Last Used At"},
+
+		Notes: []string{"Ordered from least to most recently used"},
+	}
+
+	for _, entry := range entries {
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(entry.URL),
+			boshtbl.NewValueString(entry.SHA1),
+			boshtbl.NewValueBytes(entry.Size),
+			cacheEntryTimeValue(entry.DownloadedAt),
+			cacheEntryTimeValue(entry.LastUsedAt),
+		})
+	}
+
+	c.ui.PrintTable(table)
+
+	return nil
+}
+
+// cacheEntryTimeValue leaves times empty for tarballs cached without metadata
+func cacheEntryTimeValue(t time.Time) boshtbl.Value {
+	if t.IsZero() {
+		return boshtbl.ValueNone{}
+	}
+
+	return boshtbl.NewValueTime(t)
+}
@@ -0,0 +1,93 @@
+package cmd_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	bitarball "github.com/cloudfoundry/bosh-cli/installation/tarball"
+	mock_tarball "github.com/cloudfoundry/bosh-cli/installation/tarball/mocks"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+var _ = Describe("CacheListCmd", func() {
+	var (
+		mockCtrl  *gomock.Controller
+		mockCache *mock_tarball.MockCache
+		ui        *fakeui.FakeUI
+		command   CacheListCmd
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockCache = mock_tarball.NewMockCache(mockCtrl)
+		ui = &fakeui.FakeUI{}
+		command = NewCacheListCmd(mockCache, ui)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("lists cached tarballs", func() {
+		downloadedAt := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+		lastUsedAt := time.Date(2016, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+		mockCache.EXPECT().List().Return([]bitarball.CacheEntry{
+			{
+				Path:         "/fake-path",
+				URL:          "https://fake-url",
+				SHA1:         "fake-sha1",
+				Size:         1024,
+				DownloadedAt: downloadedAt,
+				LastUsedAt:   lastUsedAt,
+			},
+			{
+				Path: "/fake-legacy-path",
+				SHA1: "fake-legacy-sha1",
+				Size: 2048,
+			},
+		}, nil)
+
+		err := command.Run()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ui.Table).To(Equal(boshtbl.Table{
+			Content: "cached tarballs",
+
+			Header: []string{"URL", "SHA1", "Size", "Downloaded At", "Last Used At"},
+
+			Rows: [][]boshtbl.Value{
+				{
+					boshtbl.NewValueString("https://fake-url"),
+					boshtbl.NewValueString("fake-sha1"),
+					boshtbl.NewValueBytes(1024),
+					boshtbl.NewValueTime(downloadedAt),
+					boshtbl.NewValueTime(lastUsedAt),
+				},
+				{
+					boshtbl.NewValueString(""),
+					boshtbl.NewValueString("fake-legacy-sha1"),
+					boshtbl.NewValueBytes(2048),
+					boshtbl.ValueNone{},
+					boshtbl.ValueNone{},
+				},
+			},
+
+			Notes: []string{"Ordered from least to most recently used"},
+		}))
+	})
+
+	It("returns error if listing fails", func() {
+		mockCache.EXPECT().List().Return(nil, errors.New("fake-err"))
+
+		err := command.Run()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-err"))
+	})
+})
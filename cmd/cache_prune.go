@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	bitarball "github.com/cloudfoundry/bosh-cli/installation/tarball"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+type CachePruneCmd struct {
+	cache bitarball.Cache
+	ui    boshui.UI
+}
+
+func NewCachePruneCmd(cache bitarball.Cache, ui boshui.UI) CachePruneCmd {
+	return CachePruneCmd{cache: cache, ui: ui}
+}
+
+func (c CachePruneCmd) Run(opts CachePruneOpts) error {
+	if opts.OlderThan <= 0 && opts.MaxSize == 0 {
+		return bosherr.Error("Expected '--older-than' or '--max-size' to be specified")
+	}
+
+	deleted, err := c.cache.Prune(time.Duration(opts.OlderThan), uint64(opts.MaxSize))
+
+	table := boshtbl.Table{
+		Content: "deleted tarballs",
+
+		Header: []string{"URL", "SHA1", "Size", "Last Used At"},
+	}
+
+	var freed uint64
+
+	for _, entry := range deleted {
+		freed += entry.Size
+
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(entry.URL),
+			boshtbl.NewValueString(entry.SHA1),
+			boshtbl.NewValueBytes(entry.Size),
+			cacheEntryTimeValue(entry.LastUsedAt),
+		})
+	}
+
+	table.Notes = []string{"Freed " + boshtbl.NewValueBytes(freed).String()}
+
+	c.ui.PrintTable(table)
+
+	return err
+}
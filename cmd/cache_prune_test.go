@@ -0,0 +1,89 @@
+package cmd_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	bitarball "github.com/cloudfoundry/bosh-cli/installation/tarball"
+	mock_tarball "github.com/cloudfoundry/bosh-cli/installation/tarball/mocks"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+var _ = Describe("CachePruneCmd", func() {
+	var (
+		mockCtrl  *gomock.Controller
+		mockCache *mock_tarball.MockCache
+		ui        *fakeui.FakeUI
+		command   CachePruneCmd
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockCache = mock_tarball.NewMockCache(mockCtrl)
+		ui = &fakeui.FakeUI{}
+		command = NewCachePruneCmd(mockCache, ui)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("prunes cache by age and size and shows deleted tarballs", func() {
+		lastUsedAt := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+		mockCache.EXPECT().Prune(720*time.Hour, uint64(1000)).Return([]bitarball.CacheEntry{
+			{URL: "https://fake-url", SHA1: "fake-sha1", Size: 2000, LastUsedAt: lastUsedAt},
+			{URL: "https://fake-other-url", SHA1: "fake-other-sha1", Size: 3000, LastUsedAt: lastUsedAt},
+		}, nil)
+
+		err := command.Run(CachePruneOpts{OlderThan: 720 * time.Hour, MaxSize: ByteSizeArg(1000)})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ui.Table).To(Equal(boshtbl.Table{
+			Content: "deleted tarballs",
+
+			Header: []string{"URL", "SHA1", "Size", "Last Used At"},
+
+			Rows: [][]boshtbl.Value{
+				{
+					boshtbl.NewValueString("https://fake-url"),
+					boshtbl.NewValueString("fake-sha1"),
+					boshtbl.NewValueBytes(2000),
+					boshtbl.NewValueTime(lastUsedAt),
+				},
+				{
+					boshtbl.NewValueString("https://fake-other-url"),
+					boshtbl.NewValueString("fake-other-sha1"),
+					boshtbl.NewValueBytes(3000),
+					boshtbl.NewValueTime(lastUsedAt),
+				},
+			},
+
+			Notes: []string{"Freed 5.0 kB"},
+		}))
+	})
+
+	It("returns error without any limit", func() {
+		err := command.Run(CachePruneOpts{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected '--older-than' or '--max-size' to be specified"))
+	})
+
+	It("shows deleted tarballs and returns error if pruning fails", func() {
+		mockCache.EXPECT().Prune(time.Duration(0), uint64(1000)).Return([]bitarball.CacheEntry{
+			{URL: "https://fake-url", SHA1: "fake-sha1", Size: 2000},
+		}, errors.New("fake-err"))
+
+		err := command.Run(CachePruneOpts{MaxSize: ByteSizeArg(1000)})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-err"))
+
+		Expect(ui.Table.Rows).To(HaveLen(1))
+	})
+})
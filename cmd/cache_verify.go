@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	bicrypto "github.com/cloudfoundry/bosh-cli/crypto"
+	bitarball "github.com/cloudfoundry/bosh-cli/installation/tarball"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+type CacheVerifyCmd struct {
+	cache    bitarball.Cache
+	sha1Calc bicrypto.SHA1Calculator
+	ui       boshui.UI
+}
+
+func NewCacheVerifyCmd(cache bitarball.Cache, sha1Calc bicrypto.SHA1Calculator, ui boshui.UI) CacheVerifyCmd {
+	return CacheVerifyCmd{cache: cache, sha1Calc: sha1Calc, ui: ui}
+}
+
+func (c CacheVerifyCmd) Run() error {
+	entries, err := c.cache.List()
+	if err != nil {
+		return err
+	}
+
+	table := boshtbl.Table{
+		Content: "cached tarballs",
+
+		Header: []string{"URL", "SHA1", "Actual SHA1", "State"},
+
+		Notes: []string{"Corrupt tarballs are downloaded again when needed"},
+	}
+
+	var corrupt int
+
+	for _, entry := range entries {
+		actualSHA1, err := c.sha1Calc.Calculate(entry.Path)
+
+		ok := err == nil && actualSHA1 == entry.SHA1
+		if !ok {
+			corrupt++
+		}
+
+		var state boshtbl.Value = boshtbl.NewValueString("ok")
+
+		if err != nil {
+			state = boshtbl.NewValueError(err)
+		} else if !ok {
+			state = boshtbl.NewValueString("corrupt")
+		}
+
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(entry.URL),
+			boshtbl.NewValueString(entry.SHA1),
+			boshtbl.NewValueString(actualSHA1),
+			boshtbl.NewValueFmt(state, !ok),
+		})
+	}
+
+	c.ui.PrintTable(table)
+
+	if corrupt > 0 {
+		return bosherr.Errorf("Found %d corrupt cached tarball(s)", corrupt)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package cmd_test
+
+import (
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	fakebicrypto "github.com/cloudfoundry/bosh-cli/crypto/fakes"
+	bitarball "github.com/cloudfoundry/bosh-cli/installation/tarball"
+	mock_tarball "github.com/cloudfoundry/bosh-cli/installation/tarball/mocks"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+var _ = Describe("CacheVerifyCmd", func() {
+	var (
+		mockCtrl  *gomock.Controller
+		mockCache *mock_tarball.MockCache
+		sha1Calc  *fakebicrypto.FakeSha1Calculator
+		ui        *fakeui.FakeUI
+		command   CacheVerifyCmd
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockCache = mock_tarball.NewMockCache(mockCtrl)
+		sha1Calc = fakebicrypto.NewFakeSha1Calculator()
+		ui = &fakeui.FakeUI{}
+		command = NewCacheVerifyCmd(mockCache, sha1Calc, ui)
+
+		mockCache.EXPECT().List().Return([]bitarball.CacheEntry{
+			{Path: "/fake-path", URL: "https://fake-url", SHA1: "fake-sha1"},
+			{Path: "/fake-other-path", URL: "https://fake-other-url", SHA1: "fake-other-sha1"},
+		}, nil)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("re-hashes cached tarballs", func() {
+		sha1Calc.SetCalculateBehavior(map[string]fakebicrypto.CalculateInput{
+			"/fake-path":       {Sha1: "fake-sha1"},
+			"/fake-other-path": {Sha1: "fake-other-sha1"},
+		})
+
+		err := command.Run()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ui.Table).To(Equal(boshtbl.Table{
+			Content: "cached tarballs",
+
+			Header: []string{"URL", "SHA1", "Actual SHA1", "State"},
+
+			Rows: [][]boshtbl.Value{
+				{
+					boshtbl.NewValueString("https://fake-url"),
+					boshtbl.NewValueString("fake-sha1"),
+					boshtbl.NewValueString("fake-sha1"),
+					boshtbl.NewValueFmt(boshtbl.NewValueString("ok"), false),
+				},
+				{
+					boshtbl.NewValueString("https://fake-other-url"),
+					boshtbl.NewValueString("fake-other-sha1"),
+					boshtbl.NewValueString("fake-other-sha1"),
+					boshtbl.NewValueFmt(boshtbl.NewValueString("ok"), false),
+				},
+			},
+
+			Notes: []string{"Corrupt tarballs are downloaded again when needed"},
+		}))
+	})
+
+	It("returns error when some tarballs are corrupt", func() {
+		sha1Calc.SetCalculateBehavior(map[string]fakebicrypto.CalculateInput{
+			"/fake-path":       {Sha1: "fake-wrong-sha1"},
+			"/fake-other-path": {Err: errors.New("fake-sha1-err")},
+		})
+
+		err := command.Run()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Found 2 corrupt cached tarball(s)"))
+
+		Expect(ui.Table.Rows[0][3]).To(Equal(boshtbl.NewValueFmt(boshtbl.NewValueString("corrupt"), true)))
+		Expect(ui.Table.Rows[1][3]).To(Equal(boshtbl.NewValueFmt(boshtbl.NewValueError(errors.New("fake-sha1-err")), true)))
+	})
+})
@@ -1,16 +1,26 @@
 package cmd
 
 import (
+	"crypto/rand"
 	"fmt"
+	"time"
 
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	"github.com/cppforlife/go-patch/patch"
 
 	cmdconf "github.com/cloudfoundry/bosh-cli/cmd/config"
 	boshdir "github.com/cloudfoundry/bosh-cli/director"
 	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	boshinst "github.com/cloudfoundry/bosh-cli/installation"
+	bitarball "github.com/cloudfoundry/bosh-cli/installation/tarball"
+	bilint "github.com/cloudfoundry/bosh-cli/lint"
 	boshrel "github.com/cloudfoundry/bosh-cli/release"
 	boshreldir "github.com/cloudfoundry/bosh-cli/releasedir"
 	boshssh "github.com/cloudfoundry/bosh-cli/ssh"
+	bitemplate "github.com/cloudfoundry/bosh-cli/templatescompiler"
+	bitemplateerb "github.com/cloudfoundry/bosh-cli/templatescompiler/erbrenderer"
+	boshuaa "github.com/cloudfoundry/bosh-cli/uaa"
 	boshui "github.com/cloudfoundry/bosh-cli/ui"
 	boshuit "github.com/cloudfoundry/bosh-cli/ui/task"
 )
@@ -44,6 +54,7 @@ func (c Cmd) Execute() (cmdErr error) {
 
 	c.configureUI()
 	c.configureFS()
+	c.configureVarsStore()
 
 	deps := c.deps
 
@@ -56,19 +67,50 @@ func (c Cmd) Execute() (cmdErr error) {
 
 	case *CreateEnvOpts:
 		envProvider := func(manifestPath string, statePath string, vars boshtpl.Variables, op patch.Op) DeploymentPreparer {
-			return NewEnvFactory(deps, manifestPath, statePath, vars, op).Preparer()
+			return NewEnvFactory(deps, manifestPath, statePath, opts.ForceUnlock, opts.CPICallsFlags, opts.CompilationWorkers, vars, op).Preparer()
 		}
 
-		stage := boshui.NewStage(deps.UI, deps.Time, deps.Logger)
-		return NewCreateEnvCmd(deps.UI, envProvider).Run(stage, *opts)
+		return c.performWithTimings(opts.TimingsPath, func(stage boshui.Stage) error {
+			return NewCreateEnvCmd(deps.UI, envProvider).Run(stage, *opts)
+		})
 
 	case *DeleteEnvOpts:
 		envProvider := func(manifestPath string, statePath string, vars boshtpl.Variables, op patch.Op) DeploymentDeleter {
-			return NewEnvFactory(deps, manifestPath, statePath, vars, op).Deleter()
+			return NewEnvFactory(deps, manifestPath, statePath, opts.ForceUnlock, opts.CPICallsFlags, 1, vars, op).Deleter()
 		}
 
-		stage := boshui.NewStage(deps.UI, deps.Time, deps.Logger)
-		return NewDeleteCmd(deps.UI, envProvider).Run(stage, *opts)
+		return c.performWithTimings(opts.TimingsPath, func(stage boshui.Stage) error {
+			return NewDeleteCmd(deps.UI, envProvider).Run(stage, *opts)
+		})
+
+	case *CacheListOpts:
+		return NewCacheListCmd(c.tarballCache(), deps.UI).Run()
+
+	case *CachePruneOpts:
+		return NewCachePruneCmd(c.tarballCache(), deps.UI).Run(*opts)
+
+	case *CacheVerifyOpts:
+		return NewCacheVerifyCmd(c.tarballCache(), deps.SHA1Calc, deps.UI).Run()
+
+	case *ExportCompiledPackageCacheOpts:
+		targetProvider := func(statePath string) (boshinst.Target, error) {
+			return NewEnvFactory(deps, "", statePath, false, CPICallsFlags{}, 1, nil, nil).InstallationTarget(true)
+		}
+
+		cache := boshinst.NewCompiledPackageCache(
+			boshinst.CurrentOS(deps.FS), deps.FS, deps.Compressor, deps.SHA1Calc, deps.UUIDGen, deps.Logger)
+
+		return NewExportCompiledPackageCacheCmd(targetProvider, cache, deps.UI).Run(*opts)
+
+	case *ImportCompiledPackageCacheOpts:
+		targetProvider := func(statePath string) (boshinst.Target, error) {
+			return NewEnvFactory(deps, "", statePath, false, CPICallsFlags{}, 1, nil, nil).InstallationTarget(false)
+		}
+
+		cache := boshinst.NewCompiledPackageCache(
+			boshinst.CurrentOS(deps.FS), deps.FS, deps.Compressor, deps.SHA1Calc, deps.UUIDGen, deps.Logger)
+
+		return NewImportCompiledPackageCacheCmd(targetProvider, cache, deps.UI).Run(*opts)
 
 	case *AliasEnvOpts:
 		sessionFactory := func(config cmdconf.Config) Session {
@@ -86,6 +128,13 @@ func (c Cmd) Execute() (cmdErr error) {
 		basicStrategy := NewBasicLoginStrategy(sessionFactory, config, deps.UI)
 		uaaStrategy := NewUAALoginStrategy(sessionFactory, config, deps.UI, deps.Logger)
 
+		listenerFactory := func(state string) (boshuaa.CallbackListener, error) {
+			return boshuaa.NewLocalCallbackListener(state, 5*time.Minute)
+		}
+
+		ssoStrategy := NewUAASSOLoginStrategy(
+			sessionFactory, listenerFactory, config, deps.UI, deps.UUIDGen, rand.Reader, opts.Browser, deps.Logger)
+
 		sess := NewSessionFromOpts(c.BoshOpts, c.config(), deps.UI, true, true, deps.FS, deps.Logger)
 
 		anonDirector, err := sess.AnonymousDirector()
@@ -93,7 +142,7 @@ func (c Cmd) Execute() (cmdErr error) {
 			return err
 		}
 
-		return NewLogInCmd(basicStrategy, uaaStrategy, anonDirector).Run()
+		return NewLogInCmd(basicStrategy, uaaStrategy, ssoStrategy, anonDirector).Run(*opts)
 
 	case *LogOutOpts:
 		config := c.config()
@@ -106,11 +155,16 @@ func (c Cmd) Execute() (cmdErr error) {
 		return NewTaskCmd(eventsTaskReporter, plainTaskReporter, c.director()).Run(*opts)
 
 	case *TasksOpts:
-		return NewTasksCmd(deps.UI, c.director()).Run(*opts)
+		return c.eachDirector(func(ui boshui.UI, director boshdir.Director) error {
+			return NewTasksCmd(ui, director).Run(*opts)
+		})
 
 	case *CancelTaskOpts:
 		return NewCancelTaskCmd(c.director()).Run(*opts)
 
+	case *TaskReportOpts:
+		return NewTaskReportCmd(deps.UI, c.director()).Run(*opts)
+
 	case *DeploymentOpts:
 		sessionFactory := func(config cmdconf.Config) Session {
 			return NewSessionFromOpts(c.BoshOpts, config, deps.UI, true, false, deps.FS, deps.Logger)
@@ -119,13 +173,17 @@ func (c Cmd) Execute() (cmdErr error) {
 		return NewDeploymentCmd(sessionFactory, c.config(), deps.UI).Run()
 
 	case *DeploymentsOpts:
-		return NewDeploymentsCmd(deps.UI, c.director()).Run()
+		return c.eachDirector(func(ui boshui.UI, director boshdir.Director) error {
+			return NewDeploymentsCmd(ui, director).Run()
+		})
 
 	case *DeleteDeploymentOpts:
 		return NewDeleteDeploymentCmd(deps.UI, c.deployment()).Run(*opts)
 
 	case *ReleasesOpts:
-		return NewReleasesCmd(deps.UI, c.director()).Run()
+		return c.eachDirector(func(ui boshui.UI, director boshdir.Director) error {
+			return NewReleasesCmd(ui, director).Run()
+		})
 
 	case *UploadReleaseOpts:
 		relProv, relDirProv := c.releaseProviders()
@@ -143,7 +201,7 @@ func (c Cmd) Execute() (cmdErr error) {
 		}
 
 		cmd := NewUploadReleaseCmd(
-			releaseDirFactory, releaseWriter, c.director(), releaseArchiveFactory, deps.CmdRunner, deps.FS, deps.UI)
+			releaseDirFactory, releaseWriter, c.director(), releaseArchiveFactory, deps.CmdRunner, deps.SHA1Calc, deps.FS, deps.UI)
 
 		return cmd.Run(*opts)
 
@@ -151,20 +209,24 @@ func (c Cmd) Execute() (cmdErr error) {
 		return NewDeleteReleaseCmd(deps.UI, c.director()).Run(*opts)
 
 	case *StemcellsOpts:
-		return NewStemcellsCmd(deps.UI, c.director()).Run()
+		return c.eachDirector(func(ui boshui.UI, director boshdir.Director) error {
+			return NewStemcellsCmd(ui, director).Run()
+		})
 
 	case *UploadStemcellOpts:
 		stemcellArchiveFactory := func(path string) boshdir.StemcellArchive {
 			return boshdir.NewFSStemcellArchive(path, deps.FS)
 		}
 
-		return NewUploadStemcellCmd(c.director(), stemcellArchiveFactory, deps.UI).Run(*opts)
+		return NewUploadStemcellCmd(c.director(), stemcellArchiveFactory, deps.SHA1Calc, deps.UI).Run(*opts)
 
 	case *DeleteStemcellOpts:
 		return NewDeleteStemcellCmd(deps.UI, c.director()).Run(*opts)
 
 	case *LocksOpts:
-		return NewLocksCmd(deps.UI, c.director()).Run()
+		return c.eachDirector(func(ui boshui.UI, director boshdir.Director) error {
+			return NewLocksCmd(ui, director).Run()
+		})
 
 	case *ErrandsOpts:
 		return NewErrandsCmd(deps.UI, c.deployment()).Run()
@@ -201,6 +263,29 @@ func (c Cmd) Execute() (cmdErr error) {
 	case *InterpolateOpts:
 		return NewInterpolateCmd(deps.UI).Run(*opts)
 
+	case *LintOpts:
+		relProv, relDirProv := c.releaseProviders()
+
+		releaseFactory := func(path string) (boshrel.Reader, boshreldir.ReleaseDir) {
+			readerOpts := boshrel.MultiReaderOpts{
+				ArchiveReader:  relProv.NewExtractingArchiveReader(),
+				ManifestReader: relProv.NewManifestReader(),
+				DirReader:      relProv.NewDirReader(path),
+			}
+			return boshrel.NewMultiReader(readerOpts, deps.FS), relDirProv.NewFSReleaseDir(path)
+		}
+
+		return NewLintCmd(releaseFactory, bilint.NewLinter(), deps.UI).Run(*opts)
+
+	case *VarsStoreRekeyOpts:
+		return NewVarsStoreRekeyCmd(deps.UI).Run(*opts)
+
+	case *VarsStoreCertsOpts:
+		return NewVarsStoreCertsCmd(deps.UI, deps.Time).Run(*opts)
+
+	case *VarsStoreRotateCertsOpts:
+		return NewVarsStoreRotateCertsCmd(deps.UI).Run(*opts)
+
 	case *CloudConfigOpts:
 		return NewCloudConfigCmd(deps.UI, c.director()).Run()
 
@@ -224,14 +309,25 @@ func (c Cmd) Execute() (cmdErr error) {
 	case *ManifestOpts:
 		return NewManifestCmd(deps.UI, c.deployment()).Run()
 
+	case *ExportDeploymentOpts:
+		director, deployment := c.directorAndDeployment()
+		archive := NewFSDeploymentBundleArchive(deps.FS, deps.Compressor)
+		return NewExportDeploymentCmd(deps.UI, director, deployment, archive).Run(*opts)
+
+	case *ImportDeploymentOpts:
+		archive := NewFSDeploymentBundleArchive(deps.FS, deps.Compressor)
+		return NewImportDeploymentCmd(deps.UI, c.director(), archive).Run(*opts)
+
 	case *EventsOpts:
-		return NewEventsCmd(deps.UI, c.director()).Run(*opts)
+		return NewEventsCmd(deps.UI, c.director(), deps.Time, c.BoshOpts.JSONOpt).Run(*opts)
 
 	case *InspectReleaseOpts:
 		return NewInspectReleaseCmd(deps.UI, c.director()).Run(*opts)
 
 	case *VMsOpts:
-		return NewVMsCmd(deps.UI, c.director()).Run(*opts)
+		return c.eachDirector(func(ui boshui.UI, director boshdir.Director) error {
+			return NewVMsCmd(ui, director).Run(*opts)
+		})
 
 	case *InstancesOpts:
 		return NewInstancesCmd(deps.UI, c.deployment()).Run(*opts)
@@ -248,7 +344,7 @@ func (c Cmd) Execute() (cmdErr error) {
 	case *DeployOpts:
 		director, deployment := c.directorAndDeployment()
 		releaseManager := c.releaseManager(director)
-		return NewDeployCmd(deps.UI, deployment, releaseManager).Run(*opts)
+		return NewDeployCmd(deps.UI, deployment, releaseManager, c.BoshOpts.JSONOpt).Run(*opts)
 
 	case *StartOpts:
 		return NewStartCmd(deps.UI, c.deployment()).Run(*opts)
@@ -273,6 +369,9 @@ func (c Cmd) Execute() (cmdErr error) {
 		downloader := NewUIDownloader(director, deps.SHA1Calc, deps.Time, deps.FS, deps.UI)
 		sshProvider := boshssh.NewProvider(deps.CmdRunner, deps.FS, deps.UI, deps.Logger)
 		nonIntSSHRunner := sshProvider.NewSSHRunner(false)
+		if opts.NativeSSH {
+			nonIntSSHRunner = sshProvider.NewNativeSSHRunner(false)
+		}
 		return NewLogsCmd(deployment, downloader, deps.UUIDGen, nonIntSSHRunner).Run(*opts)
 
 	case *SSHOpts:
@@ -280,11 +379,19 @@ func (c Cmd) Execute() (cmdErr error) {
 		intSSHRunner := sshProvider.NewSSHRunner(true)
 		nonIntSSHRunner := sshProvider.NewSSHRunner(false)
 		resultsSSHRunner := sshProvider.NewResultsSSHRunner(false)
+		if opts.NativeSSH {
+			intSSHRunner = sshProvider.NewNativeSSHRunner(true)
+			nonIntSSHRunner = sshProvider.NewNativeSSHRunner(false)
+			resultsSSHRunner = sshProvider.NewNativeResultsSSHRunner(false)
+		}
 		return NewSSHCmd(c.deployment(), deps.UUIDGen, intSSHRunner, nonIntSSHRunner, resultsSSHRunner, deps.UI).Run(*opts)
 
 	case *SCPOpts:
 		sshProvider := boshssh.NewProvider(deps.CmdRunner, deps.FS, deps.UI, deps.Logger)
 		scpRunner := sshProvider.NewSCPRunner()
+		if opts.NativeSSH {
+			scpRunner = sshProvider.NewNativeSCPRunner()
+		}
 		return NewSCPCmd(c.deployment(), deps.UUIDGen, scpRunner, deps.UI).Run(*opts)
 
 	case *ExportReleaseOpts:
@@ -310,6 +417,23 @@ func (c Cmd) Execute() (cmdErr error) {
 		releaseDir := relDirProv.NewFSReleaseDir(opts.Directory.Path)
 		return NewFinalizeReleaseCmd(releaseReader, releaseDir, deps.UI).Run(*opts)
 
+	case *RenderJobOpts:
+		relProv, _ := c.releaseProviders()
+
+		releaseReaderFactory := func(path string) boshrel.Reader {
+			readerOpts := boshrel.MultiReaderOpts{
+				ArchiveReader:  relProv.NewExtractingArchiveReader(),
+				ManifestReader: relProv.NewManifestReader(),
+				DirReader:      relProv.NewDirReader(path),
+			}
+			return boshrel.NewMultiReader(readerOpts, deps.FS)
+		}
+
+		erbRenderer := bitemplateerb.NewCompatERBRenderer(deps.FS, deps.CmdRunner, deps.Logger)
+		jobRenderer := bitemplate.NewJobRenderer(erbRenderer, deps.FS, deps.UUIDGen, deps.Logger)
+
+		return NewRenderJobCmd(releaseReaderFactory, jobRenderer, deps.FS, deps.UI).Run(*opts)
+
 	case *CreateReleaseOpts:
 		relProv, relDirProv := c.releaseProviders()
 
@@ -373,17 +497,60 @@ func (c Cmd) configureFS() {
 	c.panicIfErr(err)
 }
 
+func (c Cmd) configureVarsStore() {
+	type varsStoreOpts interface {
+		ConfigureVarsStore(func() cmdconf.Config, boshlog.Logger) error
+	}
+
+	if opts, ok := c.Opts.(varsStoreOpts); ok {
+		err := opts.ConfigureVarsStore(c.config, c.deps.Logger)
+		c.panicIfErr(err)
+	}
+}
+
 func (c Cmd) config() cmdconf.Config {
 	config, err := cmdconf.NewFSConfigFromPath(c.BoshOpts.ConfigPathOpt, c.deps.FS)
 	c.panicIfErr(err)
 
-	return config
+	helperFactory := func(program string) cmdconf.CredentialHelper {
+		return cmdconf.NewExecCredentialHelper(program, c.deps.CmdRunner)
+	}
+
+	return cmdconf.NewCredentialHelperConfig(config, helperFactory, c.deps.Logger)
 }
 
 func (c Cmd) session() Session {
+	if len(c.BoshOpts.EnvironmentsOpt) > 0 {
+		c.panicIfErr(bosherr.Error("Expected command to target single environment instead of environment group"))
+	}
+
 	return NewSessionFromOpts(c.BoshOpts, c.config(), c.deps.UI, true, true, c.deps.FS, c.deps.Logger)
 }
 
+// eachDirector runs read command against the targeted director
+// or against each director of the environment group concurrently
+func (c Cmd) eachDirector(run func(boshui.UI, boshdir.Director) error) error {
+	if len(c.BoshOpts.EnvironmentsOpt) == 0 {
+		return run(c.deps.UI, c.director())
+	}
+
+	config := c.config()
+
+	environments, err := config.EnvironmentGroup(c.BoshOpts.EnvironmentsOpt)
+	if err != nil {
+		return err
+	}
+
+	directorFactory := func(environment string) (boshdir.Director, error) {
+		opts := c.BoshOpts
+		opts.EnvironmentOpt = environment
+
+		return NewSessionFromOpts(opts, config, c.deps.UI, false, false, c.deps.FS, c.deps.Logger).Director()
+	}
+
+	return NewEnvironmentsFanOut(environments, directorFactory, c.deps.UI).Run(run)
+}
+
 func (c Cmd) director() boshdir.Director {
 	director, err := c.session().Director()
 	c.panicIfErr(err)
@@ -443,7 +610,7 @@ func (c Cmd) releaseManager(director boshdir.Director) ReleaseManager {
 	}
 
 	uploadReleaseCmd := NewUploadReleaseCmd(
-		releaseDirFactory, releaseWriter, director, releaseArchiveFactory, c.deps.CmdRunner, c.deps.FS, c.deps.UI)
+		releaseDirFactory, releaseWriter, director, releaseArchiveFactory, c.deps.CmdRunner, c.deps.SHA1Calc, c.deps.FS, c.deps.UI)
 
 	return NewReleaseManager(createReleaseCmd, uploadReleaseCmd)
 }
@@ -458,6 +625,25 @@ func (c Cmd) releaseDir(dir DirOrCWDArg) boshreldir.ReleaseDir {
 	return relDirProv.NewFSReleaseDir(dir.Path)
 }
 
+func (c Cmd) tarballCache() bitarball.Cache {
+	return NewEnvFactory(c.deps, "", "", false, CPICallsFlags{}, 1, nil, nil).TarballCache()
+}
+
+// performWithTimings reports stage timings even if performing fails
+// since timings of failed runs help to find stages that time out
+func (c Cmd) performWithTimings(timingsPath string, perform func(boshui.Stage) error) error {
+	stage := boshui.NewTimingStage(boshui.NewStage(c.deps.UI, c.deps.Time, c.deps.Logger), c.deps.Time)
+
+	err := perform(stage)
+
+	reportErr := NewStageTimingsReporter(c.deps.UI, c.deps.FS, c.BoshOpts.JSONOpt).Report(stage.Timings(), timingsPath)
+	if err != nil {
+		return err
+	}
+
+	return reportErr
+}
+
 func (c Cmd) panicIfErr(err error) {
 	if err != nil {
 		panic(cmdConveniencePanic{err})
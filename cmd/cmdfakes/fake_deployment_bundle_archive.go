@@ -0,0 +1,123 @@
+// This file was generated by counterfeiter
+package cmdfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/bosh-cli/cmd"
+)
+
+type FakeDeploymentBundleArchive struct {
+	WriteStub        func(bundle cmd.DeploymentBundle, path string) error
+	writeMutex       sync.RWMutex
+	writeArgsForCall []struct {
+		bundle cmd.DeploymentBundle
+		path   string
+	}
+	writeReturns struct {
+		result1 error
+	}
+	ReadStub        func(path string) (cmd.DeploymentBundle, error)
+	readMutex       sync.RWMutex
+	readArgsForCall []struct {
+		path string
+	}
+	readReturns struct {
+		result1 cmd.DeploymentBundle
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDeploymentBundleArchive) Write(bundle cmd.DeploymentBundle, path string) error {
+	fake.writeMutex.Lock()
+	fake.writeArgsForCall = append(fake.writeArgsForCall, struct {
+		bundle cmd.DeploymentBundle
+		path   string
+	}{bundle, path})
+	fake.recordInvocation("Write", []interface{}{bundle, path})
+	fake.writeMutex.Unlock()
+	if fake.WriteStub != nil {
+		return fake.WriteStub(bundle, path)
+	} else {
+		return fake.writeReturns.result1
+	}
+}
+
+func (fake *FakeDeploymentBundleArchive) WriteCallCount() int {
+	fake.writeMutex.RLock()
+	defer fake.writeMutex.RUnlock()
+	return len(fake.writeArgsForCall)
+}
+
+func (fake *FakeDeploymentBundleArchive) WriteArgsForCall(i int) (cmd.DeploymentBundle, string) {
+	fake.writeMutex.RLock()
+	defer fake.writeMutex.RUnlock()
+	return fake.writeArgsForCall[i].bundle, fake.writeArgsForCall[i].path
+}
+
+func (fake *FakeDeploymentBundleArchive) WriteReturns(result1 error) {
+	fake.WriteStub = nil
+	fake.writeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDeploymentBundleArchive) Read(path string) (cmd.DeploymentBundle, error) {
+	fake.readMutex.Lock()
+	fake.readArgsForCall = append(fake.readArgsForCall, struct {
+		path string
+	}{path})
+	fake.recordInvocation("Read", []interface{}{path})
+	fake.readMutex.Unlock()
+	if fake.ReadStub != nil {
+		return fake.ReadStub(path)
+	} else {
+		return fake.readReturns.result1, fake.readReturns.result2
+	}
+}
+
+func (fake *FakeDeploymentBundleArchive) ReadCallCount() int {
+	fake.readMutex.RLock()
+	defer fake.readMutex.RUnlock()
+	return len(fake.readArgsForCall)
+}
+
+func (fake *FakeDeploymentBundleArchive) ReadArgsForCall(i int) string {
+	fake.readMutex.RLock()
+	defer fake.readMutex.RUnlock()
+	return fake.readArgsForCall[i].path
+}
+
+func (fake *FakeDeploymentBundleArchive) ReadReturns(result1 cmd.DeploymentBundle, result2 error) {
+	fake.ReadStub = nil
+	fake.readReturns = struct {
+		result1 cmd.DeploymentBundle
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDeploymentBundleArchive) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.writeMutex.RLock()
+	defer fake.writeMutex.RUnlock()
+	fake.readMutex.RLock()
+	defer fake.readMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDeploymentBundleArchive) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ cmd.DeploymentBundleArchive = new(FakeDeploymentBundleArchive)
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	biinstall "github.com/cloudfoundry/bosh-cli/installation"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+)
+
+type ExportCompiledPackageCacheCmd struct {
+	targetProvider func(statePath string) (biinstall.Target, error)
+	cache          biinstall.CompiledPackageCache
+	ui             boshui.UI
+}
+
+func NewExportCompiledPackageCacheCmd(
+	targetProvider func(string) (biinstall.Target, error),
+	cache biinstall.CompiledPackageCache,
+	ui boshui.UI,
+) ExportCompiledPackageCacheCmd {
+	return ExportCompiledPackageCacheCmd{targetProvider: targetProvider, cache: cache, ui: ui}
+}
+
+func (c ExportCompiledPackageCacheCmd) Run(opts ExportCompiledPackageCacheOpts) error {
+	target, err := c.targetProvider(opts.StatePath)
+	if err != nil {
+		return err
+	}
+
+	count, err := c.cache.Export(target, opts.Args.Path)
+	if err != nil {
+		return err
+	}
+
+	c.ui.PrintLinef("Exported %d compiled package(s) to '%s'", count, opts.Args.Path)
+
+	return nil
+}
+
+type ImportCompiledPackageCacheCmd struct {
+	targetProvider func(statePath string) (biinstall.Target, error)
+	cache          biinstall.CompiledPackageCache
+	ui             boshui.UI
+}
+
+func NewImportCompiledPackageCacheCmd(
+	targetProvider func(string) (biinstall.Target, error),
+	cache biinstall.CompiledPackageCache,
+	ui boshui.UI,
+) ImportCompiledPackageCacheCmd {
+	return ImportCompiledPackageCacheCmd{targetProvider: targetProvider, cache: cache, ui: ui}
+}
+
+func (c ImportCompiledPackageCacheCmd) Run(opts ImportCompiledPackageCacheOpts) error {
+	target, err := c.targetProvider(opts.StatePath)
+	if err != nil {
+		return err
+	}
+
+	imported, skipped, err := c.cache.Import(target, opts.Args.Path)
+	if err != nil {
+		return err
+	}
+
+	c.ui.PrintLinef("Imported %d compiled package(s), skipped %d already present", imported, skipped)
+
+	return nil
+}
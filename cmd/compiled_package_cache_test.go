@@ -0,0 +1,124 @@
+package cmd_test
+
+import (
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	biinstall "github.com/cloudfoundry/bosh-cli/installation"
+	mock_install "github.com/cloudfoundry/bosh-cli/installation/mocks"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+)
+
+var _ = Describe("CompiledPackageCache commands", func() {
+	var (
+		mockCtrl  *gomock.Controller
+		mockCache *mock_install.MockCompiledPackageCache
+
+		ui *fakeui.FakeUI
+
+		target         biinstall.Target
+		targetErr      error
+		statePaths     []string
+		targetProvider func(string) (biinstall.Target, error)
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockCache = mock_install.NewMockCompiledPackageCache(mockCtrl)
+
+		ui = &fakeui.FakeUI{}
+
+		target = biinstall.NewTarget("/fake-installation")
+		targetErr = nil
+		statePaths = nil
+
+		targetProvider = func(statePath string) (biinstall.Target, error) {
+			statePaths = append(statePaths, statePath)
+			return target, targetErr
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Describe("ExportCompiledPackageCacheCmd", func() {
+		var (
+			command ExportCompiledPackageCacheCmd
+			opts    ExportCompiledPackageCacheOpts
+		)
+
+		BeforeEach(func() {
+			command = NewExportCompiledPackageCacheCmd(targetProvider, mockCache, ui)
+
+			opts = ExportCompiledPackageCacheOpts{
+				Args:      CompiledPackageCacheArgs{Path: "/fake-cache.tgz"},
+				StatePath: "/fake-state.json",
+			}
+		})
+
+		It("exports compiled packages of installation for given state", func() {
+			mockCache.EXPECT().Export(target, "/fake-cache.tgz").Return(3, nil)
+
+			err := command.Run(opts)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(statePaths).To(Equal([]string{"/fake-state.json"}))
+			Expect(ui.Said).To(Equal([]string{"Exported 3 compiled package(s) to '/fake-cache.tgz'"}))
+		})
+
+		It("returns an error when installation cannot be found", func() {
+			targetErr = errors.New("fake-target-err")
+
+			err := command.Run(opts)
+			Expect(err).To(Equal(targetErr))
+		})
+
+		It("returns an error when exporting fails", func() {
+			mockCache.EXPECT().Export(target, "/fake-cache.tgz").Return(0, errors.New("fake-export-err"))
+
+			err := command.Run(opts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("fake-export-err"))
+			Expect(ui.Said).To(BeEmpty())
+		})
+	})
+
+	Describe("ImportCompiledPackageCacheCmd", func() {
+		var (
+			command ImportCompiledPackageCacheCmd
+			opts    ImportCompiledPackageCacheOpts
+		)
+
+		BeforeEach(func() {
+			command = NewImportCompiledPackageCacheCmd(targetProvider, mockCache, ui)
+
+			opts = ImportCompiledPackageCacheOpts{
+				Args:      CompiledPackageCacheArgs{Path: "/fake-cache.tgz"},
+				StatePath: "/fake-state.json",
+			}
+		})
+
+		It("imports compiled packages into installation for given state", func() {
+			mockCache.EXPECT().Import(target, "/fake-cache.tgz").Return(2, 1, nil)
+
+			err := command.Run(opts)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(statePaths).To(Equal([]string{"/fake-state.json"}))
+			Expect(ui.Said).To(Equal([]string{"Imported 2 compiled package(s), skipped 1 already present"}))
+		})
+
+		It("returns an error when importing fails", func() {
+			mockCache.EXPECT().Import(target, "/fake-cache.tgz").Return(0, 0, errors.New("fake-import-err"))
+
+			err := command.Run(opts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("fake-import-err"))
+		})
+	})
+})
@@ -14,6 +14,15 @@ type FakeConfig struct {
 	environmentsReturns     struct {
 		result1 []config.Environment
 	}
+	EnvironmentGroupStub        func(name string) ([]string, error)
+	environmentGroupMutex       sync.RWMutex
+	environmentGroupArgsForCall []struct {
+		name string
+	}
+	environmentGroupReturns struct {
+		result1 []string
+		result2 error
+	}
 	ResolveEnvironmentStub        func(urlOrAlias string) string
 	resolveEnvironmentMutex       sync.RWMutex
 	resolveEnvironmentArgsForCall []struct {
@@ -66,6 +75,22 @@ type FakeConfig struct {
 	unsetCredentialsReturns struct {
 		result1 config.Config
 	}
+	CredentialHelperStub        func(url string) string
+	credentialHelperMutex       sync.RWMutex
+	credentialHelperArgsForCall []struct {
+		url string
+	}
+	credentialHelperReturns struct {
+		result1 string
+	}
+	CredentialStoreStub        func(url string) config.CredentialStore
+	credentialStoreMutex       sync.RWMutex
+	credentialStoreArgsForCall []struct {
+		url string
+	}
+	credentialStoreReturns struct {
+		result1 config.CredentialStore
+	}
 	SaveStub        func() error
 	saveMutex       sync.RWMutex
 	saveArgsForCall []struct{}
@@ -101,6 +126,40 @@ func (fake *FakeConfig) EnvironmentsReturns(result1 []config.Environment) {
 	}{result1}
 }
 
+func (fake *FakeConfig) EnvironmentGroup(name string) ([]string, error) {
+	fake.environmentGroupMutex.Lock()
+	fake.environmentGroupArgsForCall = append(fake.environmentGroupArgsForCall, struct {
+		name string
+	}{name})
+	fake.recordInvocation("EnvironmentGroup", []interface{}{name})
+	fake.environmentGroupMutex.Unlock()
+	if fake.EnvironmentGroupStub != nil {
+		return fake.EnvironmentGroupStub(name)
+	} else {
+		return fake.environmentGroupReturns.result1, fake.environmentGroupReturns.result2
+	}
+}
+
+func (fake *FakeConfig) EnvironmentGroupCallCount() int {
+	fake.environmentGroupMutex.RLock()
+	defer fake.environmentGroupMutex.RUnlock()
+	return len(fake.environmentGroupArgsForCall)
+}
+
+func (fake *FakeConfig) EnvironmentGroupArgsForCall(i int) string {
+	fake.environmentGroupMutex.RLock()
+	defer fake.environmentGroupMutex.RUnlock()
+	return fake.environmentGroupArgsForCall[i].name
+}
+
+func (fake *FakeConfig) EnvironmentGroupReturns(result1 []string, result2 error) {
+	fake.EnvironmentGroupStub = nil
+	fake.environmentGroupReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeConfig) ResolveEnvironment(urlOrAlias string) string {
 	fake.resolveEnvironmentMutex.Lock()
 	fake.resolveEnvironmentArgsForCall = append(fake.resolveEnvironmentArgsForCall, struct {
@@ -116,12 +175,16 @@ func (fake *FakeConfig) ResolveEnvironment(urlOrAlias string) string {
 }
 
 func (fake *FakeConfig) ResolveEnvironmentCallCount() int {
+	fake.environmentGroupMutex.RLock()
+	defer fake.environmentGroupMutex.RUnlock()
 	fake.resolveEnvironmentMutex.RLock()
 	defer fake.resolveEnvironmentMutex.RUnlock()
 	return len(fake.resolveEnvironmentArgsForCall)
 }
 
 func (fake *FakeConfig) ResolveEnvironmentArgsForCall(i int) string {
+	fake.environmentGroupMutex.RLock()
+	defer fake.environmentGroupMutex.RUnlock()
 	fake.resolveEnvironmentMutex.RLock()
 	defer fake.resolveEnvironmentMutex.RUnlock()
 	return fake.resolveEnvironmentArgsForCall[i].urlOrAlias
@@ -303,6 +366,72 @@ func (fake *FakeConfig) UnsetCredentialsReturns(result1 config.Config) {
 	}{result1}
 }
 
+func (fake *FakeConfig) CredentialHelper(url string) string {
+	fake.credentialHelperMutex.Lock()
+	fake.credentialHelperArgsForCall = append(fake.credentialHelperArgsForCall, struct {
+		url string
+	}{url})
+	fake.recordInvocation("CredentialHelper", []interface{}{url})
+	fake.credentialHelperMutex.Unlock()
+	if fake.CredentialHelperStub != nil {
+		return fake.CredentialHelperStub(url)
+	} else {
+		return fake.credentialHelperReturns.result1
+	}
+}
+
+func (fake *FakeConfig) CredentialHelperCallCount() int {
+	fake.credentialHelperMutex.RLock()
+	defer fake.credentialHelperMutex.RUnlock()
+	return len(fake.credentialHelperArgsForCall)
+}
+
+func (fake *FakeConfig) CredentialHelperArgsForCall(i int) string {
+	fake.credentialHelperMutex.RLock()
+	defer fake.credentialHelperMutex.RUnlock()
+	return fake.credentialHelperArgsForCall[i].url
+}
+
+func (fake *FakeConfig) CredentialHelperReturns(result1 string) {
+	fake.CredentialHelperStub = nil
+	fake.credentialHelperReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeConfig) CredentialStore(url string) config.CredentialStore {
+	fake.credentialStoreMutex.Lock()
+	fake.credentialStoreArgsForCall = append(fake.credentialStoreArgsForCall, struct {
+		url string
+	}{url})
+	fake.recordInvocation("CredentialStore", []interface{}{url})
+	fake.credentialStoreMutex.Unlock()
+	if fake.CredentialStoreStub != nil {
+		return fake.CredentialStoreStub(url)
+	} else {
+		return fake.credentialStoreReturns.result1
+	}
+}
+
+func (fake *FakeConfig) CredentialStoreCallCount() int {
+	fake.credentialStoreMutex.RLock()
+	defer fake.credentialStoreMutex.RUnlock()
+	return len(fake.credentialStoreArgsForCall)
+}
+
+func (fake *FakeConfig) CredentialStoreArgsForCall(i int) string {
+	fake.credentialStoreMutex.RLock()
+	defer fake.credentialStoreMutex.RUnlock()
+	return fake.credentialStoreArgsForCall[i].url
+}
+
+func (fake *FakeConfig) CredentialStoreReturns(result1 config.CredentialStore) {
+	fake.CredentialStoreStub = nil
+	fake.credentialStoreReturns = struct {
+		result1 config.CredentialStore
+	}{result1}
+}
+
 func (fake *FakeConfig) Save() error {
 	fake.saveMutex.Lock()
 	fake.saveArgsForCall = append(fake.saveArgsForCall, struct{}{})
@@ -333,6 +462,8 @@ func (fake *FakeConfig) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.environmentsMutex.RLock()
 	defer fake.environmentsMutex.RUnlock()
+	fake.environmentGroupMutex.RLock()
+	defer fake.environmentGroupMutex.RUnlock()
 	fake.resolveEnvironmentMutex.RLock()
 	defer fake.resolveEnvironmentMutex.RUnlock()
 	fake.aliasEnvironmentMutex.RLock()
@@ -345,6 +476,10 @@ func (fake *FakeConfig) Invocations() map[string][][]interface{} {
 	defer fake.setCredentialsMutex.RUnlock()
 	fake.unsetCredentialsMutex.RLock()
 	defer fake.unsetCredentialsMutex.RUnlock()
+	fake.credentialHelperMutex.RLock()
+	defer fake.credentialHelperMutex.RUnlock()
+	fake.credentialStoreMutex.RLock()
+	defer fake.credentialStoreMutex.RUnlock()
 	fake.saveMutex.RLock()
 	defer fake.saveMutex.RUnlock()
 	return fake.invocations
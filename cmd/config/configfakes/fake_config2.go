@@ -29,6 +29,10 @@ func (f *FakeConfig2) Environments() []config.Environment {
 	panic("Not implemented")
 }
 
+func (f *FakeConfig2) EnvironmentGroup(name string) ([]string, error) {
+	panic("Not implemented")
+}
+
 func (f *FakeConfig2) ResolveEnvironment(environmentOrName string) string {
 	return ""
 }
@@ -64,6 +68,14 @@ func (f *FakeConfig2) UnsetCredentials(environment string) config.Config {
 	panic("Not implemented")
 }
 
+func (f *FakeConfig2) CredentialHelper(environment string) string {
+	panic("Not implemented")
+}
+
+func (f *FakeConfig2) CredentialStore(url string) config.CredentialStore {
+	panic("Not implemented")
+}
+
 func (f *FakeConfig2) Deployment(environment string) string {
 	panic("Not implemented")
 }
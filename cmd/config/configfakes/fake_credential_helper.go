@@ -0,0 +1,166 @@
+// This file was generated by counterfeiter
+package configfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/bosh-cli/cmd/config"
+)
+
+type FakeCredentialHelper struct {
+	GetStub        func(url string) (config.Creds, error)
+	getMutex       sync.RWMutex
+	getArgsForCall []struct {
+		url string
+	}
+	getReturns struct {
+		result1 config.Creds
+		result2 error
+	}
+	StoreStub        func(url string, creds config.Creds) error
+	storeMutex       sync.RWMutex
+	storeArgsForCall []struct {
+		url   string
+		creds config.Creds
+	}
+	storeReturns struct {
+		result1 error
+	}
+	EraseStub        func(url string) error
+	eraseMutex       sync.RWMutex
+	eraseArgsForCall []struct {
+		url string
+	}
+	eraseReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeCredentialHelper) Get(url string) (config.Creds, error) {
+	fake.getMutex.Lock()
+	fake.getArgsForCall = append(fake.getArgsForCall, struct {
+		url string
+	}{url})
+	fake.recordInvocation("Get", []interface{}{url})
+	fake.getMutex.Unlock()
+	if fake.GetStub != nil {
+		return fake.GetStub(url)
+	} else {
+		return fake.getReturns.result1, fake.getReturns.result2
+	}
+}
+
+func (fake *FakeCredentialHelper) GetCallCount() int {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return len(fake.getArgsForCall)
+}
+
+func (fake *FakeCredentialHelper) GetArgsForCall(i int) string {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return fake.getArgsForCall[i].url
+}
+
+func (fake *FakeCredentialHelper) GetReturns(result1 config.Creds, result2 error) {
+	fake.GetStub = nil
+	fake.getReturns = struct {
+		result1 config.Creds
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCredentialHelper) Store(url string, creds config.Creds) error {
+	fake.storeMutex.Lock()
+	fake.storeArgsForCall = append(fake.storeArgsForCall, struct {
+		url   string
+		creds config.Creds
+	}{url, creds})
+	fake.recordInvocation("Store", []interface{}{url, creds})
+	fake.storeMutex.Unlock()
+	if fake.StoreStub != nil {
+		return fake.StoreStub(url, creds)
+	} else {
+		return fake.storeReturns.result1
+	}
+}
+
+func (fake *FakeCredentialHelper) StoreCallCount() int {
+	fake.storeMutex.RLock()
+	defer fake.storeMutex.RUnlock()
+	return len(fake.storeArgsForCall)
+}
+
+func (fake *FakeCredentialHelper) StoreArgsForCall(i int) (string, config.Creds) {
+	fake.storeMutex.RLock()
+	defer fake.storeMutex.RUnlock()
+	return fake.storeArgsForCall[i].url, fake.storeArgsForCall[i].creds
+}
+
+func (fake *FakeCredentialHelper) StoreReturns(result1 error) {
+	fake.StoreStub = nil
+	fake.storeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCredentialHelper) Erase(url string) error {
+	fake.eraseMutex.Lock()
+	fake.eraseArgsForCall = append(fake.eraseArgsForCall, struct {
+		url string
+	}{url})
+	fake.recordInvocation("Erase", []interface{}{url})
+	fake.eraseMutex.Unlock()
+	if fake.EraseStub != nil {
+		return fake.EraseStub(url)
+	} else {
+		return fake.eraseReturns.result1
+	}
+}
+
+func (fake *FakeCredentialHelper) EraseCallCount() int {
+	fake.eraseMutex.RLock()
+	defer fake.eraseMutex.RUnlock()
+	return len(fake.eraseArgsForCall)
+}
+
+func (fake *FakeCredentialHelper) EraseArgsForCall(i int) string {
+	fake.eraseMutex.RLock()
+	defer fake.eraseMutex.RUnlock()
+	return fake.eraseArgsForCall[i].url
+}
+
+func (fake *FakeCredentialHelper) EraseReturns(result1 error) {
+	fake.EraseStub = nil
+	fake.eraseReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCredentialHelper) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	fake.storeMutex.RLock()
+	defer fake.storeMutex.RUnlock()
+	fake.eraseMutex.RLock()
+	defer fake.eraseMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeCredentialHelper) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ config.CredentialHelper = new(FakeCredentialHelper)
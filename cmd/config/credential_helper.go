@@ -0,0 +1,127 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+//go:generate counterfeiter . CredentialHelper
+
+// CredentialHelper keeps environment credentials outside of the config file.
+type CredentialHelper interface {
+	Get(url string) (Creds, error)
+	Store(url string, creds Creds) error
+	Erase(url string) error
+}
+
+/*
+Similarly to git and docker credential helpers, program is executed
+with 'get', 'store' or 'erase' argument and a JSON message on stdin:
+
+$ echo '{"url":"https://192.168.50.4:25555"}' | helper get
+{"username":"admin","password":"admin"}
+
+$ echo '{"url":"https://192.168.50.4:25555","refresh_token":"..."}' | helper store
+$ echo '{"url":"https://192.168.50.4:25555"}' | helper erase
+
+Empty output of 'get' means that there are no stored credentials.
+*/
+type ExecCredentialHelper struct {
+	program   string
+	cmdRunner boshsys.CmdRunner
+}
+
+type credentialHelperMessage struct {
+	URL string `json:"url,omitempty"`
+
+	Username     string `json:"username,omitempty"`
+	Password     string `json:"password,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// NewExecCredentialHelper splits program on whitespace
+// to allow passing additional arguments to the helper
+func NewExecCredentialHelper(program string, cmdRunner boshsys.CmdRunner) ExecCredentialHelper {
+	return ExecCredentialHelper{program: program, cmdRunner: cmdRunner}
+}
+
+func (h ExecCredentialHelper) Get(url string) (Creds, error) {
+	stdout, err := h.run("get", credentialHelperMessage{URL: url})
+	if err != nil {
+		return Creds{}, err
+	}
+
+	if len(bytes.TrimSpace(stdout)) == 0 {
+		return Creds{}, nil
+	}
+
+	var msg credentialHelperMessage
+
+	err = json.Unmarshal(stdout, &msg)
+	if err != nil {
+		return Creds{}, bosherr.WrapErrorf(err, "Unmarshalling output of credential helper '%s'", h.program)
+	}
+
+	creds := Creds{
+		Username: msg.Username,
+		Password: msg.Password,
+
+		RefreshToken: msg.RefreshToken,
+	}
+
+	return creds, nil
+}
+
+func (h ExecCredentialHelper) Store(url string, creds Creds) error {
+	msg := credentialHelperMessage{
+		URL: url,
+
+		Username:     creds.Username,
+		Password:     creds.Password,
+		RefreshToken: creds.RefreshToken,
+	}
+
+	_, err := h.run("store", msg)
+
+	return err
+}
+
+func (h ExecCredentialHelper) Erase(url string) error {
+	_, err := h.run("erase", credentialHelperMessage{URL: url})
+
+	return err
+}
+
+func (h ExecCredentialHelper) run(action string, msg credentialHelperMessage) ([]byte, error) {
+	pieces := strings.Fields(h.program)
+	if len(pieces) == 0 {
+		return nil, bosherr.Error("Expected non-empty credential helper")
+	}
+
+	input, err := json.Marshal(msg)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Marshalling credential helper input")
+	}
+
+	// Output is captured into own buffer so that credentials are not logged
+	stdout := bytes.NewBuffer(nil)
+
+	cmd := boshsys.Command{
+		Name: pieces[0],
+		Args: append(pieces[1:], action),
+
+		Stdin:  bytes.NewReader(input),
+		Stdout: stdout,
+	}
+
+	_, _, _, err = h.cmdRunner.RunComplexCommand(cmd)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Running credential helper '%s %s'", h.program, action)
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+// CredentialHelperConfig keeps credentials of environments that specify
+// a credential helper out of the underlying config. Stored and erased
+// credentials are handed to the helper when config is saved.
+type CredentialHelperConfig struct {
+	Config
+
+	helperFactory func(program string) CredentialHelper
+	pending       []credentialHelperOp
+
+	logTag string
+	logger boshlog.Logger
+}
+
+type credentialHelperOp struct {
+	url     string
+	program string
+
+	creds *Creds // nil for erase
+}
+
+func NewCredentialHelperConfig(
+	config Config,
+	helperFactory func(program string) CredentialHelper,
+	logger boshlog.Logger,
+) CredentialHelperConfig {
+	return CredentialHelperConfig{
+		Config:        config,
+		helperFactory: helperFactory,
+
+		logTag: "CredentialHelperConfig",
+		logger: logger,
+	}
+}
+
+func (c CredentialHelperConfig) AliasEnvironment(url, alias, caCert string) (Config, error) {
+	config, err := c.Config.AliasEnvironment(url, alias, caCert)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.withConfig(config, nil), nil
+}
+
+// Credentials returns empty credentials when helper fails
+// since they are only used on a best-effort basis to authenticate
+func (c CredentialHelperConfig) Credentials(urlOrAlias string) Creds {
+	program := c.Config.CredentialHelper(urlOrAlias)
+	if len(program) == 0 {
+		return c.Config.Credentials(urlOrAlias)
+	}
+
+	url := c.Config.ResolveEnvironment(urlOrAlias)
+
+	for i := len(c.pending) - 1; i >= 0; i-- {
+		if c.pending[i].url == url {
+			if c.pending[i].creds == nil {
+				return Creds{}
+			}
+			return *c.pending[i].creds
+		}
+	}
+
+	creds, err := c.helperFactory(program).Get(url)
+	if err != nil {
+		c.logger.Error(c.logTag, "Failed to get credentials for '%s': %s", url, err)
+		return Creds{}
+	}
+
+	return creds
+}
+
+func (c CredentialHelperConfig) SetCredentials(urlOrAlias string, creds Creds) Config {
+	program := c.Config.CredentialHelper(urlOrAlias)
+	if len(program) == 0 {
+		return c.withConfig(c.Config.SetCredentials(urlOrAlias, creds), nil)
+	}
+
+	op := credentialHelperOp{
+		url:     c.Config.ResolveEnvironment(urlOrAlias),
+		program: program,
+		creds:   &creds,
+	}
+
+	// Previously saved plaintext credentials are removed from the config file
+	return c.withConfig(c.Config.UnsetCredentials(urlOrAlias), &op)
+}
+
+func (c CredentialHelperConfig) UnsetCredentials(urlOrAlias string) Config {
+	program := c.Config.CredentialHelper(urlOrAlias)
+	if len(program) == 0 {
+		return c.withConfig(c.Config.UnsetCredentials(urlOrAlias), nil)
+	}
+
+	op := credentialHelperOp{
+		url:     c.Config.ResolveEnvironment(urlOrAlias),
+		program: program,
+	}
+
+	return c.withConfig(c.Config.UnsetCredentials(urlOrAlias), &op)
+}
+
+func (c CredentialHelperConfig) Save() error {
+	for _, op := range c.pending {
+		helper := c.helperFactory(op.program)
+
+		if op.creds != nil {
+			err := helper.Store(op.url, *op.creds)
+			if err != nil {
+				return bosherr.WrapErrorf(err, "Storing credentials for '%s'", op.url)
+			}
+		} else {
+			err := helper.Erase(op.url)
+			if err != nil {
+				return bosherr.WrapErrorf(err, "Erasing credentials for '%s'", op.url)
+			}
+		}
+	}
+
+	return c.Config.Save()
+}
+
+func (c CredentialHelperConfig) withConfig(config Config, op *credentialHelperOp) CredentialHelperConfig {
+	pending := append([]credentialHelperOp{}, c.pending...)
+
+	if op != nil {
+		pending = append(pending, *op)
+	}
+
+	return CredentialHelperConfig{
+		Config:        config,
+		helperFactory: c.helperFactory,
+		pending:       pending,
+
+		logTag: c.logTag,
+		logger: c.logger,
+	}
+}
@@ -0,0 +1,158 @@
+package config_test
+
+import (
+	"errors"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd/config"
+	fakeconfig "github.com/cloudfoundry/bosh-cli/cmd/config/configfakes"
+)
+
+var _ = Describe("CredentialHelperConfig", func() {
+	var (
+		fs         *fakesys.FakeFileSystem
+		helper     *fakeconfig.FakeCredentialHelper
+		helperArgs []string
+		config     Config
+	)
+
+	readConfig := func() Config {
+		fsConfig, err := NewFSConfigFromPath("/config", fs)
+		Expect(err).ToNot(HaveOccurred())
+
+		helperFactory := func(program string) CredentialHelper {
+			helperArgs = append(helperArgs, program)
+			return helper
+		}
+
+		return NewCredentialHelperConfig(fsConfig, helperFactory, boshlog.NewLogger(boshlog.LevelNone))
+	}
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		fs.WriteFileString("/config", `
+environments:
+- url: url
+  alias: alias
+  credential_helper: fake-helper
+- url: plain-url
+`)
+
+		helper = &fakeconfig.FakeCredentialHelper{}
+		helperArgs = nil
+
+		config = readConfig()
+	})
+
+	Describe("Credentials", func() {
+		It("gets creds from helper by environment url", func() {
+			helper.GetReturns(Creds{RefreshToken: "token"}, nil)
+
+			Expect(config.Credentials("alias")).To(Equal(Creds{RefreshToken: "token"}))
+
+			Expect(helperArgs).To(Equal([]string{"fake-helper"}))
+			Expect(helper.GetArgsForCall(0)).To(Equal("url"))
+		})
+
+		It("returns empty creds if helper fails", func() {
+			helper.GetReturns(Creds{RefreshToken: "token"}, errors.New("fake-err"))
+
+			Expect(config.Credentials("url")).To(Equal(Creds{}))
+		})
+
+		It("returns creds from config when environment does not specify helper", func() {
+			updatedConfig := config.SetCredentials("plain-url", Creds{Username: "user", Password: "pass"})
+			Expect(updatedConfig.Credentials("plain-url")).To(Equal(Creds{Username: "user", Password: "pass"}))
+
+			Expect(helper.Invocations()).To(BeEmpty())
+		})
+	})
+
+	Describe("SetCredentials", func() {
+		It("stores creds via helper when config is saved", func() {
+			updatedConfig := config.SetCredentials("alias", Creds{RefreshToken: "token"})
+			Expect(updatedConfig.Credentials("alias")).To(Equal(Creds{RefreshToken: "token"}))
+
+			Expect(helper.StoreCallCount()).To(Equal(0))
+
+			err := updatedConfig.Save()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(helper.StoreCallCount()).To(Equal(1))
+
+			url, creds := helper.StoreArgsForCall(0)
+			Expect(url).To(Equal("url"))
+			Expect(creds).To(Equal(Creds{RefreshToken: "token"}))
+		})
+
+		It("does not save creds into config file", func() {
+			fs.WriteFileString("/config", `
+environments:
+- url: url
+  credential_helper: fake-helper
+  refresh_token: plaintext-token
+`)
+
+			err := readConfig().SetCredentials("url", Creds{RefreshToken: "token"}).Save()
+			Expect(err).ToNot(HaveOccurred())
+
+			contents, err := fs.ReadFileString("/config")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).ToNot(ContainSubstring("token"))
+			Expect(contents).To(ContainSubstring("credential_helper: fake-helper"))
+		})
+
+		It("saves creds into config file when environment does not specify helper", func() {
+			err := config.SetCredentials("plain-url", Creds{Username: "user", Password: "pass"}).Save()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(readConfig().Credentials("plain-url")).To(Equal(Creds{Username: "user", Password: "pass"}))
+			Expect(helper.Invocations()).To(BeEmpty())
+		})
+
+		It("returns error and does not write config if helper fails to store creds", func() {
+			helper.StoreReturns(errors.New("fake-err"))
+
+			err := config.SetCredentials("url", Creds{RefreshToken: "token"}).Save()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Storing credentials for 'url': fake-err"))
+		})
+	})
+
+	Describe("UnsetCredentials", func() {
+		It("erases creds via helper when config is saved", func() {
+			updatedConfig := config.UnsetCredentials("alias")
+			Expect(updatedConfig.Credentials("alias")).To(Equal(Creds{}))
+
+			err := updatedConfig.Save()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(helper.EraseCallCount()).To(Equal(1))
+			Expect(helper.EraseArgsForCall(0)).To(Equal("url"))
+		})
+
+		It("returns error if helper fails to erase creds", func() {
+			helper.EraseReturns(errors.New("fake-err"))
+
+			err := config.UnsetCredentials("url").Save()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Erasing credentials for 'url': fake-err"))
+		})
+	})
+
+	Describe("AliasEnvironment", func() {
+		It("keeps using helper for aliased environment", func() {
+			updatedConfig, err := config.AliasEnvironment("url", "new-alias", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = updatedConfig.SetCredentials("new-alias", Creds{RefreshToken: "token"}).Save()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(helper.StoreCallCount()).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,130 @@
+package config_test
+
+import (
+	"errors"
+	"io/ioutil"
+
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd/config"
+)
+
+var _ = Describe("ExecCredentialHelper", func() {
+	var (
+		cmdRunner *fakesys.FakeCmdRunner
+		helper    ExecCredentialHelper
+	)
+
+	BeforeEach(func() {
+		cmdRunner = fakesys.NewFakeCmdRunner()
+		helper = NewExecCredentialHelper("fake-helper --flag", cmdRunner)
+	})
+
+	stdinOf := func(cmd boshsys.Command) string {
+		bytes, err := ioutil.ReadAll(cmd.Stdin)
+		Expect(err).ToNot(HaveOccurred())
+
+		return string(bytes)
+	}
+
+	Describe("Get", func() {
+		It("returns creds printed by helper", func() {
+			cmdRunner.AddCmdResult("fake-helper --flag get", fakesys.FakeCmdResult{
+				Stdout: `{"username":"user","password":"pass","refresh_token":"token"}`,
+			})
+
+			creds, err := helper.Get("https://url")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(creds).To(Equal(Creds{Username: "user", Password: "pass", RefreshToken: "token"}))
+
+			Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
+
+			cmd := cmdRunner.RunComplexCommands[0]
+			Expect(cmd.Name).To(Equal("fake-helper"))
+			Expect(cmd.Args).To(Equal([]string{"--flag", "get"}))
+			Expect(stdinOf(cmd)).To(Equal(`{"url":"https://url"}`))
+		})
+
+		It("returns empty creds if helper does not print anything", func() {
+			cmdRunner.AddCmdResult("fake-helper --flag get", fakesys.FakeCmdResult{Stdout: "\n"})
+
+			creds, err := helper.Get("https://url")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(creds).To(Equal(Creds{}))
+		})
+
+		It("returns error if helper output cannot be unmarshalled", func() {
+			cmdRunner.AddCmdResult("fake-helper --flag get", fakesys.FakeCmdResult{Stdout: "-"})
+
+			_, err := helper.Get("https://url")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unmarshalling output of credential helper 'fake-helper --flag'"))
+		})
+
+		It("returns error if helper fails", func() {
+			cmdRunner.AddCmdResult("fake-helper --flag get", fakesys.FakeCmdResult{
+				ExitStatus: 1,
+				Error:      errors.New("fake-err"),
+			})
+
+			_, err := helper.Get("https://url")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Running credential helper 'fake-helper --flag get': fake-err"))
+		})
+	})
+
+	Describe("Store", func() {
+		It("passes creds to helper", func() {
+			cmdRunner.AddCmdResult("fake-helper --flag store", fakesys.FakeCmdResult{})
+
+			err := helper.Store("https://url", Creds{Username: "user", Password: "pass"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
+
+			cmd := cmdRunner.RunComplexCommands[0]
+			Expect(cmd.Args).To(Equal([]string{"--flag", "store"}))
+			Expect(stdinOf(cmd)).To(Equal(`{"url":"https://url","username":"user","password":"pass"}`))
+		})
+
+		It("returns error if helper fails", func() {
+			cmdRunner.AddCmdResult("fake-helper --flag store", fakesys.FakeCmdResult{Error: errors.New("fake-err")})
+
+			err := helper.Store("https://url", Creds{RefreshToken: "token"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-err"))
+		})
+	})
+
+	Describe("Erase", func() {
+		It("asks helper to erase creds", func() {
+			cmdRunner.AddCmdResult("fake-helper --flag erase", fakesys.FakeCmdResult{})
+
+			err := helper.Erase("https://url")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
+
+			cmd := cmdRunner.RunComplexCommands[0]
+			Expect(cmd.Args).To(Equal([]string{"--flag", "erase"}))
+			Expect(stdinOf(cmd)).To(Equal(`{"url":"https://url"}`))
+		})
+
+		It("returns error if helper fails", func() {
+			cmdRunner.AddCmdResult("fake-helper --flag erase", fakesys.FakeCmdResult{Error: errors.New("fake-err")})
+
+			err := helper.Erase("https://url")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-err"))
+		})
+	})
+
+	It("returns error if program is empty", func() {
+		_, err := NewExecCredentialHelper(" ", cmdRunner).Get("https://url")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected non-empty credential helper"))
+	})
+})
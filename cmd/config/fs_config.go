@@ -1,6 +1,8 @@
 package config
 
 import (
+	gourl "net/url"
+
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
 	"gopkg.in/yaml.v2"
@@ -12,6 +14,16 @@ environments:
   ca_cert: |...
   username: admin
   password: admin
+- url: https://10.0.0.6:25555
+  alias: prod
+  credential_helper: bosh-credential-keychain
+environment_groups:
+- name: all
+  environments: [https://192.168.50.4:25555, prod]
+credential_stores:
+- url: credhub://credhub.example.com:8844
+  ca_cert: |...
+  token: ...
 */
 
 type FSConfig struct {
@@ -22,7 +34,9 @@ type FSConfig struct {
 }
 
 type fsConfigSchema struct {
-	Environments []fsConfigSchema_Environment `yaml:"environments"`
+	Environments      []fsConfigSchema_Environment      `yaml:"environments"`
+	EnvironmentGroups []fsConfigSchema_EnvironmentGroup `yaml:"environment_groups,omitempty"`
+	CredentialStores  []fsConfigSchema_CredentialStore  `yaml:"credential_stores,omitempty"`
 }
 
 type fsConfigSchema_Environment struct {
@@ -35,6 +49,22 @@ type fsConfigSchema_Environment struct {
 	Username     string `yaml:"username,omitempty"`
 	Password     string `yaml:"password,omitempty"`
 	RefreshToken string `yaml:"refresh_token,omitempty"`
+
+	// Program that stores above credentials instead of the config
+	CredentialHelper string `yaml:"credential_helper,omitempty"`
+}
+
+type fsConfigSchema_EnvironmentGroup struct {
+	Name string `yaml:"name"`
+
+	// URLs or aliases
+	Environments []string `yaml:"environments"`
+}
+
+type fsConfigSchema_CredentialStore struct {
+	URL    string `yaml:"url"`
+	CACert string `yaml:"ca_cert,omitempty"`
+	Token  string `yaml:"token,omitempty"`
 }
 
 func NewFSConfigFromPath(path string, fs boshsys.FileSystem) (FSConfig, error) {
@@ -70,6 +100,20 @@ func (c FSConfig) Environments() []Environment {
 	return environments
 }
 
+func (c FSConfig) EnvironmentGroup(name string) ([]string, error) {
+	for _, group := range c.schema.EnvironmentGroups {
+		if group.Name == name {
+			if len(group.Environments) == 0 {
+				return nil, bosherr.Errorf("Expected environment group '%s' to include at least one environment", name)
+			}
+
+			return group.Environments, nil
+		}
+	}
+
+	return nil, bosherr.Errorf("Expected to find environment group '%s'", name)
+}
+
 func (c FSConfig) ResolveEnvironment(urlOrAlias string) string {
 	_, tg := c.findOrCreateEnvironment(urlOrAlias)
 
@@ -137,6 +181,33 @@ func (c FSConfig) UnsetCredentials(urlOrAlias string) Config {
 	return config
 }
 
+func (c FSConfig) CredentialHelper(urlOrAlias string) string {
+	_, tg := c.findOrCreateEnvironment(urlOrAlias)
+
+	return tg.CredentialHelper
+}
+
+// CredentialStore finds credential store settings by matching scheme and host of the URL
+func (c FSConfig) CredentialStore(url string) CredentialStore {
+	parsedURL, err := gourl.Parse(url)
+	if err != nil {
+		return CredentialStore{URL: url}
+	}
+
+	for _, store := range c.schema.CredentialStores {
+		parsedStoreURL, err := gourl.Parse(store.URL)
+		if err != nil {
+			continue
+		}
+
+		if parsedStoreURL.Scheme == parsedURL.Scheme && parsedStoreURL.Host == parsedURL.Host {
+			return CredentialStore{URL: url, CACert: store.CACert, Token: store.Token}
+		}
+	}
+
+	return CredentialStore{URL: url}
+}
+
 func (c FSConfig) Save() error {
 	bytes, err := yaml.Marshal(c.schema)
 	if err != nil {
@@ -111,6 +111,52 @@ var _ = Describe("FSConfig", func() {
 		})
 	})
 
+	Describe("EnvironmentGroup", func() {
+		BeforeEach(func() {
+			fs.WriteFileString("/dir/sub-dir/config", `
+environments:
+- url: url1
+  alias: alias1
+- url: url2
+environment_groups:
+- name: group
+  environments: [alias1, url2]
+- name: empty-group
+`)
+			config = readConfig()
+		})
+
+		It("returns environments in the group", func() {
+			envs, err := config.EnvironmentGroup("group")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(envs).To(Equal([]string{"alias1", "url2"}))
+		})
+
+		It("returns error if group is not found", func() {
+			_, err := config.EnvironmentGroup("other-group")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find environment group 'other-group'"))
+		})
+
+		It("returns error if group does not include any environments", func() {
+			_, err := config.EnvironmentGroup("empty-group")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected environment group 'empty-group' to include at least one environment"))
+		})
+
+		It("keeps groups when config is saved", func() {
+			updatedConfig, err := config.AliasEnvironment("url3", "alias3", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = updatedConfig.Save()
+			Expect(err).ToNot(HaveOccurred())
+
+			envs, err := readConfig().EnvironmentGroup("group")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(envs).To(Equal([]string{"alias1", "url2"}))
+		})
+	})
+
 	Describe("AliasEnvironment/CACert", func() {
 		It("returns empty if file does not exist", func() {
 			Expect(config.CACert("url")).To(Equal(""))
@@ -388,6 +434,38 @@ var _ = Describe("FSConfig", func() {
 		})
 	})
 
+	Describe("CredentialHelper", func() {
+		BeforeEach(func() {
+			fs.WriteFileString("/dir/sub-dir/config", `
+environments:
+- url: url
+  alias: alias
+  credential_helper: fake-helper --flag
+- url: other-url
+`)
+			config = readConfig()
+		})
+
+		It("returns credential helper of environment found by url or alias", func() {
+			Expect(config.CredentialHelper("url")).To(Equal("fake-helper --flag"))
+			Expect(config.CredentialHelper("alias")).To(Equal("fake-helper --flag"))
+		})
+
+		It("returns empty if environment does not specify credential helper", func() {
+			Expect(config.CredentialHelper("other-url")).To(Equal(""))
+			Expect(config.CredentialHelper("unknown-url")).To(Equal(""))
+		})
+
+		It("keeps credential helper when creds are set", func() {
+			updatedConfig := config.SetCredentials("url", Creds{Username: "user"})
+
+			err := updatedConfig.Save()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(readConfig().CredentialHelper("url")).To(Equal("fake-helper --flag"))
+		})
+	})
+
 	Describe("Save", func() {
 		It("returns error if writing file fails", func() {
 			fs.WriteFileError = errors.New("fake-err")
@@ -398,4 +476,48 @@ var _ = Describe("FSConfig", func() {
 			Expect(err.Error()).To(ContainSubstring("fake-err"))
 		})
 	})
+
+	Describe("CredentialStore", func() {
+		BeforeEach(func() {
+			fs.WriteFileString("/dir/sub-dir/config", `
+credential_stores:
+- url: credhub://credhub.example.com:8844
+  ca_cert: fake-ca-cert
+  token: fake-token
+- url: vault://vault.example.com
+  token: fake-vault-token
+`)
+			config = readConfig()
+		})
+
+		It("returns settings for store with matching scheme and host", func() {
+			Expect(config.CredentialStore("credhub://credhub.example.com:8844/bosh-lite")).To(Equal(CredentialStore{
+				URL:    "credhub://credhub.example.com:8844/bosh-lite",
+				CACert: "fake-ca-cert",
+				Token:  "fake-token",
+			}))
+
+			Expect(config.CredentialStore("vault://vault.example.com/secret/env")).To(Equal(CredentialStore{
+				URL:   "vault://vault.example.com/secret/env",
+				Token: "fake-vault-token",
+			}))
+		})
+
+		It("returns only url if store is not configured", func() {
+			Expect(config.CredentialStore("vault://credhub.example.com:8844/path")).To(Equal(CredentialStore{
+				URL: "vault://credhub.example.com:8844/path",
+			}))
+		})
+
+		It("keeps credential stores when saving config", func() {
+			updatedConfig, err := config.AliasEnvironment("url1", "alias1", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = updatedConfig.Save()
+			Expect(err).ToNot(HaveOccurred())
+
+			reloadedConfig := readConfig()
+			Expect(reloadedConfig.CredentialStore("vault://vault.example.com").Token).To(Equal("fake-vault-token"))
+		})
+	})
 })
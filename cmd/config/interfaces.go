@@ -4,6 +4,10 @@ package config
 
 type Config interface {
 	Environments() []Environment
+
+	// EnvironmentGroup returns URLs or aliases of environments in a named group
+	EnvironmentGroup(name string) ([]string, error)
+
 	ResolveEnvironment(urlOrAlias string) string
 	AliasEnvironment(url, alias, caCert string) (Config, error)
 
@@ -13,6 +17,11 @@ type Config interface {
 	SetCredentials(url string, creds Creds) Config
 	UnsetCredentials(url string) Config
 
+	// CredentialHelper returns program that stores credentials of the environment
+	CredentialHelper(url string) string
+
+	CredentialStore(url string) CredentialStore
+
 	Save() error
 }
 
@@ -20,3 +29,12 @@ type Environment struct {
 	URL   string
 	Alias string
 }
+
+type CredentialStore struct {
+	URL string
+
+	// CA certificate is not required
+	CACert string
+
+	Token string
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/cloud"
+)
+
+// Shared
+type CPICallsFlags struct {
+	RecordCPICalls string `long:"record-cpi-calls" value-name:"PATH" description:"Record CPI calls with redacted credentials into a JSONL file"`
+	ReplayCPICalls string `long:"replay-cpi-calls" value-name:"PATH" description:"Answer CPI calls from a file recorded with --record-cpi-calls instead of running the CPI; calls must be made in recorded order"`
+}
+
+func (f CPICallsFlags) AsCloudFactory(fs boshsys.FileSystem, cmdRunner boshsys.CmdRunner, logger boshlog.Logger) bicloud.Factory {
+	if len(f.ReplayCPICalls) > 0 {
+		return bicloud.NewReplayFactory(fs, f.ReplayCPICalls, logger)
+	}
+
+	if len(f.RecordCPICalls) > 0 {
+		return bicloud.NewRecordingFactory(fs, cmdRunner, f.RecordCPICalls, logger)
+	}
+
+	return bicloud.NewFactory(fs, cmdRunner, logger)
+}
@@ -0,0 +1,54 @@
+package cmd_test
+
+import (
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/cloud"
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+)
+
+var _ = Describe("CPICallsFlags", func() {
+	Describe("AsCloudFactory", func() {
+		var (
+			fs        *fakesys.FakeFileSystem
+			cmdRunner *fakesys.FakeCmdRunner
+			logger    boshlog.Logger
+		)
+
+		BeforeEach(func() {
+			fs = fakesys.NewFakeFileSystem()
+			cmdRunner = fakesys.NewFakeCmdRunner()
+			logger = boshlog.NewLogger(boshlog.LevelNone)
+		})
+
+		It("returns factory that runs the CPI by default", func() {
+			factory := CPICallsFlags{}.AsCloudFactory(fs, cmdRunner, logger)
+			Expect(factory).To(Equal(bicloud.NewFactory(fs, cmdRunner, logger)))
+		})
+
+		It("returns factory that records CPI calls when recording is requested", func() {
+			factory := CPICallsFlags{RecordCPICalls: "/record.jsonl"}.AsCloudFactory(fs, cmdRunner, logger)
+			Expect(factory).To(Equal(bicloud.NewRecordingFactory(fs, cmdRunner, "/record.jsonl", logger)))
+		})
+
+		It("returns factory that replays CPI calls when replay is requested", func() {
+			flags := CPICallsFlags{RecordCPICalls: "/record.jsonl", ReplayCPICalls: "/replay.jsonl"}
+
+			factory := flags.AsCloudFactory(fs, cmdRunner, logger)
+			Expect(factory).To(Equal(bicloud.NewReplayFactory(fs, "/replay.jsonl", logger)))
+
+			fs.WriteFileString("/replay.jsonl", `{"input":{"method":"has_vm"},"output":{"result":true,"log":""}}`)
+
+			cloud, err := factory.NewCloud(nil, "fake-director-id")
+			Expect(err).ToNot(HaveOccurred())
+
+			found, err := cloud.HasVM("fake-vm-cid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(cmdRunner.RunComplexCommands).To(BeEmpty())
+		})
+	})
+})
@@ -22,5 +22,9 @@ func (c *CreateEnvCmd) Run(stage boshui.Stage, opts CreateEnvOpts) error {
 	depPreparer := c.envProvider(
 		opts.Args.Manifest.Path, opts.StatePath, opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp())
 
+	if opts.DryRun {
+		return depPreparer.PlanDeployment(stage)
+	}
+
 	return depPreparer.PrepareDeployment(stage)
 }
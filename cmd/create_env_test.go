@@ -16,6 +16,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-golang/clock"
 
 	mock_httpagent "github.com/cloudfoundry/bosh-agent/agentclient/http/mocks"
 	mock_agentclient "github.com/cloudfoundry/bosh-cli/agentclient/mocks"
@@ -321,7 +322,7 @@ var _ = Describe("CreateEnvCmd", func() {
 				deploymentRecord := deployment.NewRecord(deploymentRepo, releaseRepo, stemcellRepo)
 
 				fakeHTTPClient := fakebihttpclient.NewFakeHTTPClient()
-				tarballCache := bitarball.NewCache("fake-base-path", fs, logger)
+				tarballCache := bitarball.NewCache("fake-base-path", fs, clock.NewClock(), logger)
 				tarballProvider := bitarball.NewProvider(tarballCache, fs, fakeHTTPClient, sha1Calculator, 1, 0, logger)
 
 				cpiInstaller := bicpirel.CpiInstaller{
@@ -363,6 +364,7 @@ var _ = Describe("CreateEnvCmd", func() {
 					mockLegacyDeploymentStateMigrator,
 					releaseManager,
 					deploymentRecord,
+					deployment.NewPlanner(),
 					mockCloudFactory,
 					fakeStemcellManagerFactory,
 					mockAgentClientFactory,
@@ -698,6 +700,61 @@ var _ = Describe("CreateEnvCmd", func() {
 			})
 		})
 
+		Context("when dry run is requested", func() {
+			BeforeEach(func() {
+				defaultCreateEnvOpts.DryRun = true
+			})
+
+			It("prints planned CPI actions without installing CPI or deploying", func() {
+				expectInstall.Times(0)
+				expectNewCloud.Times(0)
+				expectStemcellUpload.Times(0)
+				expectDeploy.Times(0)
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stdOut).To(gbytes.Say("upload_stemcell"))
+				Expect(stdOut).To(gbytes.Say("create_vm"))
+				Expect(stdOut).To(gbytes.Say("2 actions"))
+			})
+
+			It("does not create deployment state file", func() {
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fs.FileExists(deploymentStatePath)).To(BeFalse())
+			})
+
+			Context("when deployment has not changed", func() {
+				JustBeforeEach(func() {
+					previousDeploymentState := biconfig.DeploymentState{
+						DirectorID:        directorID,
+						CurrentReleaseIDs: []string{"my-release-id-1"},
+						Releases: []biconfig.ReleaseRecord{{
+							ID:      "my-release-id-1",
+							Name:    cpiRelease.Name(),
+							Version: cpiRelease.Version(),
+						}},
+						CurrentStemcellID: "my-stemcellRecordID",
+						Stemcells: []biconfig.StemcellRecord{{
+							ID:      "my-stemcellRecordID",
+							Name:    cloudStemcell.Name(),
+							Version: cloudStemcell.Version(),
+						}},
+						CurrentManifestSHA: manifestSHA,
+					}
+
+					err := setupDeploymentStateService.Save(previousDeploymentState)
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				It("prints that there is nothing to deploy", func() {
+					err := command.Run(fakeStage, defaultCreateEnvOpts)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(stdOut).To(gbytes.Say("No deployment, stemcell or release changes. Nothing to deploy."))
+				})
+			})
+		})
+
 		Context("when parsing the cpi deployment manifest fails", func() {
 			JustBeforeEach(func() {
 				manifest := bideplmanifest.Manifest{}
@@ -12,6 +12,10 @@ type DeployCmd struct {
 	ui              boshui.UI
 	deployment      boshdir.Deployment
 	releaseUploader ReleaseUploader
+
+	// When set, dry run prints deployment plan as JSON document instead of
+	// a textual diff and does not ask the Director to perform a dry run
+	jsonPlan bool
 }
 
 type ReleaseUploader interface {
@@ -22,8 +26,9 @@ func NewDeployCmd(
 	ui boshui.UI,
 	deployment boshdir.Deployment,
 	releaseUploader ReleaseUploader,
+	jsonPlan bool,
 ) DeployCmd {
-	return DeployCmd{ui, deployment, releaseUploader}
+	return DeployCmd{ui, deployment, releaseUploader, jsonPlan}
 }
 
 func (c DeployCmd) Run(opts DeployOpts) error {
@@ -49,11 +54,23 @@ func (c DeployCmd) Run(opts DeployOpts) error {
 		return err
 	}
 
+	// Structured plan is the whole output hence dry run is not sent to the Director
+	if opts.DryRun && c.jsonPlan {
+		return c.printDeploymentPlan(deploymentDiff)
+	}
+
 	err = c.printManifestDiff(deploymentDiff, bytes, opts)
 	if err != nil {
 		return bosherr.WrapError(err, "Diffing manifest")
 	}
 
+	if opts.DryRun {
+		err = c.printDeploymentPlan(deploymentDiff)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = c.ui.AskForConfirmation()
 	if err != nil {
 		return err
@@ -101,3 +118,18 @@ func (c DeployCmd) printManifestDiff(diff boshdir.DeploymentDiff, bytes []byte,
 
 	return nil
 }
+
+func (c DeployCmd) printDeploymentPlan(diff boshdir.DeploymentDiff) error {
+	plan, err := diff.Plan()
+	if err != nil {
+		return bosherr.WrapError(err, "Building deployment plan")
+	}
+
+	if c.jsonPlan {
+		c.ui.PrintJSON(plan)
+	} else {
+		DeploymentPlanTable{Plan: plan, UI: c.ui}.Print()
+	}
+
+	return nil
+}
@@ -1,8 +1,10 @@
 package cmd_test
 
 import (
+	"encoding/json"
 	"errors"
 
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	"github.com/cppforlife/go-patch/patch"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -12,7 +14,9 @@ import (
 	boshdir "github.com/cloudfoundry/bosh-cli/director"
 	fakedir "github.com/cloudfoundry/bosh-cli/director/directorfakes"
 	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
 	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
 )
 
 var _ = Describe("DeployCmd", func() {
@@ -33,7 +37,7 @@ var _ = Describe("DeployCmd", func() {
 			UploadReleasesStub: func(bytes []byte) ([]byte, error) { return bytes, nil },
 		}
 
-		command = NewDeployCmd(ui, deployment, releaseUploader)
+		command = NewDeployCmd(ui, deployment, releaseUploader, false)
 	})
 
 	Describe("Run", func() {
@@ -87,6 +91,8 @@ var _ = Describe("DeployCmd", func() {
 			err := act()
 			Expect(err).ToNot(HaveOccurred())
 
+			Expect(ui.Table.Content).To(Equal("deployment plan"))
+
 			Expect(deployment.UpdateCallCount()).To(Equal(1))
 
 			bytes, updateOpts := deployment.UpdateArgsForCall(0)
@@ -230,6 +236,95 @@ releases:
 			Expect(ui.Said).To(ContainElement("- some line that was removed\n"))
 		})
 
+		Context("when json output is enabled", func() {
+			var (
+				diff boshdir.DeploymentDiff
+			)
+
+			BeforeEach(func() {
+				command = NewDeployCmd(ui, deployment, releaseUploader, true)
+
+				diff = boshdir.NewDeploymentDiff([][]interface{}{
+					[]interface{}{"instance_groups:", nil},
+					[]interface{}{"- name: redis", nil},
+					[]interface{}{"  vm_type: small", "removed"},
+					[]interface{}{"  vm_type: large", "added"},
+				}, map[string]interface{}{"cloud_config_id": 2})
+
+				deployment.DiffReturns(diff, nil)
+			})
+
+			It("prints deployment plan as JSON document instead of diff lines for dry run and does not deploy", func() {
+				opts.DryRun = true
+
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(ui.Said).To(BeEmpty())
+				Expect(ui.Blocks).To(BeEmpty())
+				Expect(ui.Tables).To(BeEmpty())
+
+				expectedPlan, err := diff.Plan()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ui.JSONs).To(Equal([]interface{}{expectedPlan}))
+
+				Expect(ui.AskedConfirmationCalled).To(BeFalse())
+				Expect(deployment.UpdateCallCount()).To(Equal(0))
+			})
+
+			It("outputs only deployment plan with JSON UI for dry run", func() {
+				parentUI := &fakeui.FakeUI{}
+				jsonUI := boshui.NewJSONUI(parentUI, boshlog.NewLogger(boshlog.LevelNone))
+
+				command = NewDeployCmd(jsonUI, deployment, releaseUploader, true)
+
+				opts.DryRun = true
+
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				jsonUI.Flush()
+
+				Expect(parentUI.Blocks).To(HaveLen(1))
+
+				var doc map[string]interface{}
+
+				err = json.Unmarshal([]byte(parentUI.Blocks[0]), &doc)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(doc["schema_version"]).To(Equal(float64(1)))
+				Expect(doc["context"]).To(Equal(map[string]interface{}{"cloud_config_id": float64(2)}))
+				Expect(doc["recreates_vms"]).To(BeTrue())
+				Expect(doc["instance_groups"]).To(HaveLen(1))
+				Expect(doc).ToNot(HaveKey("Tables"))
+				Expect(doc).ToNot(HaveKey("Lines"))
+
+				Expect(deployment.UpdateCallCount()).To(Equal(0))
+			})
+
+			It("returns error if deployment plan cannot be built", func() {
+				opts.DryRun = true
+
+				deployment.DiffReturns(boshdir.NewDeploymentDiff([][]interface{}{
+					[]interface{}{"name: [dep", "added"},
+				}, nil), nil)
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Building deployment plan"))
+
+				Expect(deployment.UpdateCallCount()).To(Equal(0))
+			})
+
+			It("prints diff lines if it's not a dry run", func() {
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(ui.Table).To(Equal(boshtbl.Table{}))
+				Expect(ui.Said).To(ContainElement("+   vm_type: large\n"))
+			})
+		})
+
 		It("deploys manifest with diff context", func() {
 			context := map[string]interface{}{
 				"cloud_config_id":   2,
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshcmd "github.com/cloudfoundry/bosh-utils/fileutil"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	deploymentBundleMetadataName      = "bundle.yml"
+	deploymentBundleManifestName      = "manifest.yml"
+	deploymentBundleCloudConfigName   = "cloud-config.yml"
+	deploymentBundleRuntimeConfigName = "runtime-config.yml"
+)
+
+// DeploymentBundle includes everything necessary to reproduce
+// a deployment on another director except for values of variables
+// stored by the director (values interpolated by the CLI remain in the manifest)
+type DeploymentBundle struct {
+	Name string `yaml:"name"`
+
+	Releases  []DeploymentBundleRelease  `yaml:"releases"`
+	Stemcells []DeploymentBundleStemcell `yaml:"stemcells"`
+
+	// Only names are included since values are specific to the director
+	Variables []string `yaml:"variables"`
+
+	Manifest      string `yaml:"-"`
+	CloudConfig   string `yaml:"-"` // empty if director had no cloud config
+	RuntimeConfig string `yaml:"-"` // empty if director had no runtime config
+}
+
+type DeploymentBundleRelease struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+}
+
+type DeploymentBundleStemcell struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+	OS      string `yaml:"os"`
+}
+
+//go:generate counterfeiter . DeploymentBundleArchive
+
+type DeploymentBundleArchive interface {
+	Write(bundle DeploymentBundle, path string) error
+	Read(path string) (DeploymentBundle, error)
+}
+
+type FSDeploymentBundleArchive struct {
+	fs         boshsys.FileSystem
+	compressor boshcmd.Compressor
+}
+
+func NewFSDeploymentBundleArchive(fs boshsys.FileSystem, compressor boshcmd.Compressor) FSDeploymentBundleArchive {
+	return FSDeploymentBundleArchive{fs: fs, compressor: compressor}
+}
+
+func (a FSDeploymentBundleArchive) Write(bundle DeploymentBundle, path string) error {
+	bundleDir, err := a.fs.TempDir("bosh-deployment-bundle")
+	if err != nil {
+		return bosherr.WrapError(err, "Creating temporary directory")
+	}
+
+	defer a.fs.RemoveAll(bundleDir)
+
+	metadataBytes, err := yaml.Marshal(bundle)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling deployment bundle metadata")
+	}
+
+	files := map[string]string{
+		deploymentBundleMetadataName:      string(metadataBytes),
+		deploymentBundleManifestName:      bundle.Manifest,
+		deploymentBundleCloudConfigName:   bundle.CloudConfig,
+		deploymentBundleRuntimeConfigName: bundle.RuntimeConfig,
+	}
+
+	for name, content := range files {
+		if len(content) == 0 {
+			continue
+		}
+
+		err = a.fs.WriteFileString(filepath.Join(bundleDir, name), content)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Writing deployment bundle file '%s'", name)
+		}
+	}
+
+	compressedPath, err := a.compressor.CompressFilesInDir(bundleDir)
+	if err != nil {
+		return bosherr.WrapError(err, "Compressing deployment bundle")
+	}
+
+	defer a.compressor.CleanUp(compressedPath)
+
+	err = a.fs.CopyFile(compressedPath, path)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Copying deployment bundle to '%s'", path)
+	}
+
+	return nil
+}
+
+func (a FSDeploymentBundleArchive) Read(path string) (DeploymentBundle, error) {
+	var bundle DeploymentBundle
+
+	bundleDir, err := a.fs.TempDir("bosh-deployment-bundle")
+	if err != nil {
+		return bundle, bosherr.WrapError(err, "Creating temporary directory")
+	}
+
+	defer a.fs.RemoveAll(bundleDir)
+
+	err = a.compressor.DecompressFileToDir(path, bundleDir, boshcmd.CompressorOptions{})
+	if err != nil {
+		return bundle, bosherr.WrapErrorf(err, "Extracting deployment bundle '%s'", path)
+	}
+
+	metadataBytes, err := a.fs.ReadFile(filepath.Join(bundleDir, deploymentBundleMetadataName))
+	if err != nil {
+		return bundle, bosherr.WrapError(err, "Reading deployment bundle metadata")
+	}
+
+	err = yaml.Unmarshal(metadataBytes, &bundle)
+	if err != nil {
+		return bundle, bosherr.WrapError(err, "Unmarshalling deployment bundle metadata")
+	}
+
+	bundle.Manifest, err = a.fs.ReadFileString(filepath.Join(bundleDir, deploymentBundleManifestName))
+	if err != nil {
+		return bundle, bosherr.WrapError(err, "Reading deployment bundle manifest")
+	}
+
+	bundle.CloudConfig, err = a.readOptionalFile(bundleDir, deploymentBundleCloudConfigName)
+	if err != nil {
+		return bundle, err
+	}
+
+	bundle.RuntimeConfig, err = a.readOptionalFile(bundleDir, deploymentBundleRuntimeConfigName)
+	if err != nil {
+		return bundle, err
+	}
+
+	return bundle, nil
+}
+
+func (a FSDeploymentBundleArchive) readOptionalFile(bundleDir, name string) (string, error) {
+	path := filepath.Join(bundleDir, name)
+
+	if !a.fs.FileExists(path) {
+		return "", nil
+	}
+
+	content, err := a.fs.ReadFileString(path)
+	if err != nil {
+		return "", bosherr.WrapErrorf(err, "Reading deployment bundle file '%s'", name)
+	}
+
+	return content, nil
+}
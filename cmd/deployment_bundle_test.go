@@ -0,0 +1,165 @@
+package cmd_test
+
+import (
+	"errors"
+
+	fakeboshcmd "github.com/cloudfoundry/bosh-utils/fileutil/fakes"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+)
+
+var _ = Describe("FSDeploymentBundleArchive", func() {
+	var (
+		fs         *fakesys.FakeFileSystem
+		compressor *fakeboshcmd.FakeCompressor
+		archive    FSDeploymentBundleArchive
+		bundle     DeploymentBundle
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		fs.TempDirDir = "/fake-tmp-dir"
+		compressor = fakeboshcmd.NewFakeCompressor()
+		archive = NewFSDeploymentBundleArchive(fs, compressor)
+
+		bundle = DeploymentBundle{
+			Name: "dep",
+
+			Releases:  []DeploymentBundleRelease{{Name: "rel", Version: "1.1"}},
+			Stemcells: []DeploymentBundleStemcell{{Name: "stem", Version: "3421.9", OS: "ubuntu-trusty"}},
+			Variables: []string{"/dir/dep/var"},
+
+			Manifest:      "name: dep\n",
+			CloudConfig:   "networks: []\n",
+			RuntimeConfig: "addons: []\n",
+		}
+	})
+
+	Describe("Write", func() {
+		var files map[string]string
+
+		BeforeEach(func() {
+			files = map[string]string{}
+
+			err := fs.WriteFileString("/fake-compressed.tgz", "fake-tarball-content")
+			Expect(err).ToNot(HaveOccurred())
+
+			compressor.CompressFilesInDirTarballPath = "/fake-compressed.tgz"
+			compressor.CompressFilesInDirCallBack = func() {
+				for _, name := range []string{"bundle.yml", "manifest.yml", "cloud-config.yml", "runtime-config.yml"} {
+					if fs.FileExists("/fake-tmp-dir/" + name) {
+						files[name], err = fs.ReadFileString("/fake-tmp-dir/" + name)
+						Expect(err).ToNot(HaveOccurred())
+					}
+				}
+			}
+		})
+
+		It("compresses manifest, configs and metadata into the tarball", func() {
+			err := archive.Write(bundle, "/fake-bundle.tgz")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(compressor.CompressFilesInDirDir).To(Equal("/fake-tmp-dir"))
+			Expect(files).To(Equal(map[string]string{
+				"bundle.yml": `name: dep
+releases:
+- name: rel
+  version: "1.1"
+stemcells:
+- name: stem
+  version: "3421.9"
+  os: ubuntu-trusty
+variables:
+- /dir/dep/var
+`,
+				"manifest.yml":       "name: dep\n",
+				"cloud-config.yml":   "networks: []\n",
+				"runtime-config.yml": "addons: []\n",
+			}))
+
+			Expect(fs.ReadFileString("/fake-bundle.tgz")).To(Equal("fake-tarball-content"))
+		})
+
+		It("does not include configs that director did not have", func() {
+			bundle.CloudConfig = ""
+			bundle.RuntimeConfig = ""
+
+			err := archive.Write(bundle, "/fake-bundle.tgz")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(files).To(HaveKey("manifest.yml"))
+			Expect(files).ToNot(HaveKey("cloud-config.yml"))
+			Expect(files).ToNot(HaveKey("runtime-config.yml"))
+		})
+
+		It("cleans up temporary files", func() {
+			err := archive.Write(bundle, "/fake-bundle.tgz")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.FileExists("/fake-tmp-dir")).To(BeFalse())
+			Expect(compressor.CleanUpTarballPath).To(Equal("/fake-compressed.tgz"))
+		})
+
+		It("returns an error when compressing fails", func() {
+			compressor.CompressFilesInDirErr = errors.New("fake-compress-err")
+
+			err := archive.Write(bundle, "/fake-bundle.tgz")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-compress-err"))
+		})
+	})
+
+	Describe("Read", func() {
+		BeforeEach(func() {
+			compressor.DecompressFileToDirCallBack = func() {
+				fs.WriteFileString("/fake-tmp-dir/bundle.yml", `name: dep
+releases:
+- name: rel
+  version: "1.1"
+stemcells:
+- name: stem
+  version: "3421.9"
+  os: ubuntu-trusty
+variables:
+- /dir/dep/var
+`)
+				fs.WriteFileString("/fake-tmp-dir/manifest.yml", "name: dep\n")
+				fs.WriteFileString("/fake-tmp-dir/cloud-config.yml", "networks: []\n")
+			}
+		})
+
+		It("extracts bundle from the tarball", func() {
+			readBundle, err := archive.Read("/fake-bundle.tgz")
+			Expect(err).ToNot(HaveOccurred())
+
+			bundle.RuntimeConfig = ""
+			Expect(readBundle).To(Equal(bundle))
+
+			Expect(compressor.DecompressFileToDirTarballPaths).To(Equal([]string{"/fake-bundle.tgz"}))
+			Expect(compressor.DecompressFileToDirDirs).To(Equal([]string{"/fake-tmp-dir"}))
+			Expect(fs.FileExists("/fake-tmp-dir")).To(BeFalse())
+		})
+
+		It("returns an error when extracting fails", func() {
+			compressor.DecompressFileToDirErr = errors.New("fake-decompress-err")
+
+			_, err := archive.Read("/fake-bundle.tgz")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Extracting deployment bundle '/fake-bundle.tgz'"))
+			Expect(err.Error()).To(ContainSubstring("fake-decompress-err"))
+		})
+
+		It("returns an error when manifest is missing", func() {
+			compressor.DecompressFileToDirCallBack = func() {
+				fs.WriteFileString("/fake-tmp-dir/bundle.yml", "name: dep\n")
+			}
+
+			_, err := archive.Read("/fake-bundle.tgz")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Reading deployment bundle manifest"))
+		})
+	})
+})
@@ -3,7 +3,6 @@ package cmd
 import (
 	bihttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
-	bihttpclient "github.com/cloudfoundry/bosh-utils/httpclient"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	"github.com/cppforlife/go-patch/patch"
 
@@ -16,6 +15,7 @@ import (
 	biinstall "github.com/cloudfoundry/bosh-cli/installation"
 	boshinst "github.com/cloudfoundry/bosh-cli/installation"
 	biinstallmanifest "github.com/cloudfoundry/bosh-cli/installation/manifest"
+	boshproxy "github.com/cloudfoundry/bosh-cli/proxy"
 	birelsetmanifest "github.com/cloudfoundry/bosh-cli/release/set/manifest"
 	biui "github.com/cloudfoundry/bosh-cli/ui"
 )
@@ -90,6 +90,21 @@ type deploymentDeleter struct {
 func (c *deploymentDeleter) DeleteDeployment(stage biui.Stage) (err error) {
 	c.ui.BeginLinef("Deployment state: '%s'\n", c.deploymentStateService.Path())
 
+	// Shared deployment state is locked so that operators do not overwrite each other's changes
+	if locker, ok := c.deploymentStateService.(biconfig.DeploymentStateLocker); ok {
+		err = locker.Lock("delete-env")
+		if err != nil {
+			return bosherr.WrapError(err, "Locking deployment state")
+		}
+
+		defer func() {
+			unlockErr := locker.Unlock()
+			if unlockErr != nil {
+				c.logger.Warn(c.logTag, "Unlocking deployment state: %s", unlockErr.Error())
+			}
+		}()
+	}
+
 	if !c.deploymentStateService.Exists() {
 		c.ui.BeginLinef("No deployment state file found.\n")
 		return nil
@@ -214,7 +229,7 @@ func (c *deploymentDeleter) deploymentManager(installation biinstall.Installatio
 
 	c.logger.Debug(c.logTag, "Creating blobstore client...")
 
-	blobstore, err := c.blobstoreFactory.Create(installationMbus, bihttpclient.CreateDefaultClientInsecureSkipVerify())
+	blobstore, err := c.blobstoreFactory.Create(installationMbus, boshproxy.CreateDefaultClientInsecureSkipVerify())
 	if err != nil {
 		return nil, bosherr.WrapError(err, "Creating blobstore client")
 	}
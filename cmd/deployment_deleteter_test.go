@@ -2,6 +2,9 @@ package cmd_test
 
 import (
 	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 
@@ -16,6 +19,7 @@ import (
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/pivotal-golang/clock"
 
 	mock_agentclient "github.com/cloudfoundry/bosh-cli/agentclient/mocks"
 	mock_blobstore "github.com/cloudfoundry/bosh-cli/blobstore/mocks"
@@ -87,6 +91,7 @@ var _ = Describe("DeploymentDeleter", func() {
 
 			deploymentManifestPath = "/deployment-dir/fake-deployment-manifest.yml"
 			deploymentStatePath    string
+			remoteStateURL         string
 
 			expectCPIInstall *gomock.Call
 			expectNewCloud   *gomock.Call
@@ -167,10 +172,13 @@ cloud_provider:
 			installationValidator := biinstallmanifest.NewValidator(logger)
 			installationParser := biinstallmanifest.NewParser(fs, fakeUUIDGenerator, logger, installationValidator)
 			fakeHTTPClient := fakebihttpclient.NewFakeHTTPClient()
-			tarballCache := bitarball.NewCache("fake-base-path", fs, logger)
+			tarballCache := bitarball.NewCache("fake-base-path", fs, clock.NewClock(), logger)
 			fakeSHA1Calculator := fakebicrypto.NewFakeSha1Calculator()
 			tarballProvider := bitarball.NewProvider(tarballCache, fs, fakeHTTPClient, fakeSHA1Calculator, 1, 0, logger)
 			deploymentStateService := biconfig.NewFileSystemDeploymentStateService(fs, fakeUUIDGenerator, logger, biconfig.DeploymentStatePath(deploymentManifestPath, ""))
+			if len(remoteStateURL) > 0 {
+				deploymentStateService = biconfig.NewRemoteDeploymentStateService(remoteStateURL, false, fakeUUIDGenerator, logger)
+			}
 
 			cpiInstaller := bicpirel.CpiInstaller{
 				ReleaseManager:   releaseManager,
@@ -322,6 +330,68 @@ cloud_provider:
 			allowCPIToBeExtracted()
 		})
 
+		Context("when the deployment state is stored remotely", func() {
+			var (
+				server  *httptest.Server
+				objects map[string]string
+			)
+
+			BeforeEach(func() {
+				objects = map[string]string{}
+
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					switch req.Method {
+					case "GET":
+						contents, found := objects[req.URL.Path]
+						if !found {
+							w.WriteHeader(http.StatusNotFound)
+							return
+						}
+						w.Write([]byte(contents))
+					case "PUT":
+						if _, found := objects[req.URL.Path]; found && req.Header.Get("If-None-Match") == "*" {
+							w.WriteHeader(http.StatusPreconditionFailed)
+							return
+						}
+						contents, _ := ioutil.ReadAll(req.Body)
+						objects[req.URL.Path] = string(contents)
+					case "DELETE":
+						delete(objects, req.URL.Path)
+					}
+				}))
+
+				remoteStateURL = server.URL + "/state.json"
+			})
+
+			AfterEach(func() {
+				remoteStateURL = ""
+				server.Close()
+			})
+
+			It("releases the lock after finishing", func() {
+				err := newDeploymentDeleter().DeleteDeployment(fakeStage)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeUI.Said).To(Equal([]string{
+					"Deployment state: '" + remoteStateURL + "'\n",
+					"No deployment state file found.\n",
+				}))
+				Expect(objects).ToNot(HaveKey("/state.json.lock"))
+			})
+
+			It("returns an error without deleting anything when the lock is held by another operator", func() {
+				objects["/state.json.lock"] = `{"id":"other-lock-id","operator":"other@host","operation":"create-env"}`
+
+				err := newDeploymentDeleter().DeleteDeployment(fakeStage)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Locking deployment state"))
+				Expect(err.Error()).To(ContainSubstring("Deployment state is locked by 'other@host' for 'create-env'"))
+
+				Expect(fakeStage.PerformCalls).To(BeEmpty())
+				Expect(objects).To(HaveKey("/state.json.lock"))
+			})
+		})
+
 		Context("when the CPI installs", func() {
 			JustBeforeEach(func() {
 				allowCPIToBeInstalled()
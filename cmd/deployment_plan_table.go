@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+// DeploymentPlanTable prints one row per changed part of the deployment;
+// JSON output uses boshdir.DeploymentPlan as is instead
+type DeploymentPlanTable struct {
+	Plan boshdir.DeploymentPlan
+	UI   boshui.UI
+}
+
+func (t DeploymentPlanTable) Print() {
+	plan := t.Plan
+
+	// Rows are already in order of the diff
+	table := boshtbl.Table{
+		Content: "deployment plan",
+		Header:  []string{"Type", "Name", "Change", "Recreates VMs", "Details"},
+		Notes:   []string{fmt.Sprintf("Plan schema version %d", plan.SchemaVersion)},
+	}
+
+	table.Rows = append(table.Rows, []boshtbl.Value{
+		boshtbl.NewValueString("deployment"),
+		boshtbl.NewValueString(""),
+		boshtbl.NewValueString(t.deploymentChange()),
+		boshtbl.NewValueBool(plan.RecreatesVMs),
+		boshtbl.NewValueStrings(t.context()),
+	})
+
+	for _, group := range plan.InstanceGroups {
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString("instance_group"),
+			boshtbl.NewValueString(group.Name),
+			boshtbl.NewValueString(group.Change),
+			boshtbl.NewValueBool(group.RecreatesVMs),
+			boshtbl.NewValueStrings(group.ChangedKeys),
+		})
+
+		for _, prop := range group.Properties {
+			table.Rows = append(table.Rows, t.propertyRow("instance_group_property", group.Name+"/"+prop.Path, prop))
+		}
+	}
+
+	for _, rel := range plan.Releases {
+		table.Rows = append(table.Rows, t.versionedRow("release", rel, false))
+	}
+
+	for _, stemcell := range plan.Stemcells {
+		table.Rows = append(table.Rows, t.versionedRow("stemcell", stemcell, stemcell.Change != "added"))
+	}
+
+	for _, prop := range plan.Properties {
+		table.Rows = append(table.Rows, t.propertyRow("property", prop.Path, prop))
+	}
+
+	for _, section := range plan.OtherSections {
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString("section"),
+			boshtbl.NewValueString(section),
+			boshtbl.NewValueString("changed"),
+			boshtbl.NewValueBool(false),
+			boshtbl.NewValueString(""),
+		})
+	}
+
+	t.UI.PrintTable(table)
+}
+
+func (t DeploymentPlanTable) deploymentChange() string {
+	if t.Plan.HasChanges {
+		return "changed"
+	}
+
+	return "none"
+}
+
+func (t DeploymentPlanTable) context() []string {
+	var context []string
+
+	for key, val := range t.Plan.Context {
+		context = append(context, fmt.Sprintf("%s: %v", key, val))
+	}
+
+	sort.Strings(context)
+
+	return context
+}
+
+func (t DeploymentPlanTable) versionedRow(kind string, plan boshdir.VersionedPlan, recreatesVMs bool) []boshtbl.Value {
+	var details string
+
+	switch {
+	case len(plan.OldVersion) > 0 && len(plan.NewVersion) > 0:
+		details = plan.OldVersion + " -> " + plan.NewVersion
+	case len(plan.NewVersion) > 0:
+		details = plan.NewVersion
+	default:
+		details = plan.OldVersion
+	}
+
+	return []boshtbl.Value{
+		boshtbl.NewValueString(kind),
+		boshtbl.NewValueString(plan.Name),
+		boshtbl.NewValueString(plan.Change),
+		boshtbl.NewValueBool(recreatesVMs),
+		boshtbl.NewValueString(details),
+	}
+}
+
+func (t DeploymentPlanTable) propertyRow(kind, name string, plan boshdir.PropertyPlan) []boshtbl.Value {
+	return []boshtbl.Value{
+		boshtbl.NewValueString(kind),
+		boshtbl.NewValueString(name),
+		boshtbl.NewValueString(plan.Change),
+		boshtbl.NewValueBool(false),
+		boshtbl.NewValueString(""),
+	}
+}
@@ -0,0 +1,129 @@
+package cmd_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+var _ = Describe("DeploymentPlanTable", func() {
+	var (
+		ui *fakeui.FakeUI
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+	})
+
+	Describe("Print", func() {
+		It("shows a row for each changed part of the deployment", func() {
+			plan := boshdir.DeploymentPlan{
+				SchemaVersion: 1,
+				Context:       map[string]interface{}{"runtime_config_ids": []interface{}{3}, "cloud_config_id": 2},
+				HasChanges:    true,
+				RecreatesVMs:  true,
+				InstanceGroups: []boshdir.InstanceGroupPlan{
+					{
+						Name:         "web",
+						Change:       "changed",
+						RecreatesVMs: true,
+						ChangedKeys:  []string{"properties", "vm_type"},
+						Properties:   []boshdir.PropertyPlan{{Path: "port", Change: "removed"}},
+					},
+				},
+				Releases: []boshdir.VersionedPlan{
+					{Name: "redis", Change: "changed", OldVersion: "1", NewVersion: "2"},
+					{Name: "old", Change: "removed", OldVersion: "3"},
+				},
+				Stemcells: []boshdir.VersionedPlan{
+					{Name: "default", Change: "added", NewVersion: "3421.2"},
+				},
+				Properties:    []boshdir.PropertyPlan{{Path: "prop", Change: "added"}},
+				OtherSections: []string{"update"},
+			}
+
+			DeploymentPlanTable{Plan: plan, UI: ui}.Print()
+
+			Expect(ui.Table.Content).To(Equal("deployment plan"))
+			Expect(ui.Table.Header).To(Equal([]string{"Type", "Name", "Change", "Recreates VMs", "Details"}))
+			Expect(ui.Table.Notes).To(Equal([]string{"Plan schema version 1"}))
+
+			Expect(ui.Table.Rows).To(Equal([][]boshtbl.Value{
+				{
+					boshtbl.NewValueString("deployment"),
+					boshtbl.NewValueString(""),
+					boshtbl.NewValueString("changed"),
+					boshtbl.NewValueBool(true),
+					boshtbl.NewValueStrings([]string{"cloud_config_id: 2", "runtime_config_ids: [3]"}),
+				},
+				{
+					boshtbl.NewValueString("instance_group"),
+					boshtbl.NewValueString("web"),
+					boshtbl.NewValueString("changed"),
+					boshtbl.NewValueBool(true),
+					boshtbl.NewValueStrings([]string{"properties", "vm_type"}),
+				},
+				{
+					boshtbl.NewValueString("instance_group_property"),
+					boshtbl.NewValueString("web/port"),
+					boshtbl.NewValueString("removed"),
+					boshtbl.NewValueBool(false),
+					boshtbl.NewValueString(""),
+				},
+				{
+					boshtbl.NewValueString("release"),
+					boshtbl.NewValueString("redis"),
+					boshtbl.NewValueString("changed"),
+					boshtbl.NewValueBool(false),
+					boshtbl.NewValueString("1 -> 2"),
+				},
+				{
+					boshtbl.NewValueString("release"),
+					boshtbl.NewValueString("old"),
+					boshtbl.NewValueString("removed"),
+					boshtbl.NewValueBool(false),
+					boshtbl.NewValueString("3"),
+				},
+				{
+					boshtbl.NewValueString("stemcell"),
+					boshtbl.NewValueString("default"),
+					boshtbl.NewValueString("added"),
+					boshtbl.NewValueBool(false),
+					boshtbl.NewValueString("3421.2"),
+				},
+				{
+					boshtbl.NewValueString("property"),
+					boshtbl.NewValueString("prop"),
+					boshtbl.NewValueString("added"),
+					boshtbl.NewValueBool(false),
+					boshtbl.NewValueString(""),
+				},
+				{
+					boshtbl.NewValueString("section"),
+					boshtbl.NewValueString("update"),
+					boshtbl.NewValueString("changed"),
+					boshtbl.NewValueBool(false),
+					boshtbl.NewValueString(""),
+				},
+			}))
+		})
+
+		It("shows that there are no changes", func() {
+			DeploymentPlanTable{Plan: boshdir.DeploymentPlan{SchemaVersion: 1}, UI: ui}.Print()
+
+			Expect(ui.Table.Rows).To(Equal([][]boshtbl.Value{
+				{
+					boshtbl.NewValueString("deployment"),
+					boshtbl.NewValueString(""),
+					boshtbl.NewValueString("none"),
+					boshtbl.NewValueBool(false),
+					boshtbl.NewValueStrings(nil),
+				},
+			}))
+		})
+	})
+})
@@ -3,7 +3,6 @@ package cmd
 import (
 	bihttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
-	bihttpclient "github.com/cloudfoundry/bosh-utils/httpclient"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	"github.com/cppforlife/go-patch/patch"
 
@@ -18,9 +17,11 @@ import (
 	biinstall "github.com/cloudfoundry/bosh-cli/installation"
 	boshinst "github.com/cloudfoundry/bosh-cli/installation"
 	biinstallmanifest "github.com/cloudfoundry/bosh-cli/installation/manifest"
+	boshproxy "github.com/cloudfoundry/bosh-cli/proxy"
 	birelsetmanifest "github.com/cloudfoundry/bosh-cli/release/set/manifest"
 	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
 	biui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
 )
 
 func NewDeploymentPreparer(
@@ -31,6 +32,7 @@ func NewDeploymentPreparer(
 	legacyDeploymentStateMigrator biconfig.LegacyDeploymentStateMigrator,
 	releaseManager boshinst.ReleaseManager,
 	deploymentRecord bidepl.Record,
+	deploymentPlanner bidepl.Planner,
 	cloudFactory bicloud.Factory,
 	stemcellManagerFactory bistemcell.ManagerFactory,
 	agentClientFactory bihttpagent.AgentClientFactory,
@@ -56,6 +58,7 @@ func NewDeploymentPreparer(
 		legacyDeploymentStateMigrator:           legacyDeploymentStateMigrator,
 		releaseManager:                          releaseManager,
 		deploymentRecord:                        deploymentRecord,
+		deploymentPlanner:                       deploymentPlanner,
 		cloudFactory:                            cloudFactory,
 		stemcellManagerFactory:                  stemcellManagerFactory,
 		agentClientFactory:                      agentClientFactory,
@@ -83,6 +86,7 @@ type DeploymentPreparer struct {
 	legacyDeploymentStateMigrator           biconfig.LegacyDeploymentStateMigrator
 	releaseManager                          boshinst.ReleaseManager
 	deploymentRecord                        bidepl.Record
+	deploymentPlanner                       bidepl.Planner
 	cloudFactory                            bicloud.Factory
 	stemcellManagerFactory                  bistemcell.ManagerFactory
 	agentClientFactory                      bihttpagent.AgentClientFactory
@@ -104,6 +108,21 @@ type DeploymentPreparer struct {
 func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage) (err error) {
 	c.ui.BeginLinef("Deployment state: '%s'\n", c.deploymentStateService.Path())
 
+	// Shared deployment state is locked so that operators do not overwrite each other's changes
+	if locker, ok := c.deploymentStateService.(biconfig.DeploymentStateLocker); ok {
+		err = locker.Lock("create-env")
+		if err != nil {
+			return bosherr.WrapError(err, "Locking deployment state")
+		}
+
+		defer func() {
+			unlockErr := locker.Unlock()
+			if unlockErr != nil {
+				c.logger.Warn(c.logTag, "Unlocking deployment state: %s", unlockErr.Error())
+			}
+		}()
+	}
+
 	if !c.deploymentStateService.Exists() {
 		migrated, err := c.legacyDeploymentStateMigrator.MigrateIfExists(biconfig.LegacyDeploymentStatePath(c.deploymentManifestPath))
 		if err != nil {
@@ -136,39 +155,7 @@ func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage) (err error) {
 		}
 	}()
 
-	var (
-		extractedStemcell    bistemcell.ExtractedStemcell
-		deploymentManifest   bideplmanifest.Manifest
-		installationManifest biinstallmanifest.Manifest
-		manifestSHA          string
-	)
-	err = stage.PerformComplex("validating", func(stage biui.Stage) error {
-		var releaseSetManifest birelsetmanifest.Manifest
-		releaseSetManifest, installationManifest, err = c.releaseSetAndInstallationManifestParser.ReleaseSetAndInstallationManifest(c.deploymentManifestPath, c.deploymentVars, c.deploymentOp)
-		if err != nil {
-			return err
-		}
-
-		for _, releaseRef := range releaseSetManifest.Releases {
-			err = c.releaseFetcher.DownloadAndExtract(releaseRef, stage)
-			if err != nil {
-				return err
-			}
-		}
-
-		err := c.cpiInstaller.ValidateCpiRelease(installationManifest, stage)
-		if err != nil {
-			return err
-		}
-
-		deploymentManifest, manifestSHA, err = c.deploymentManifestParser.GetDeploymentManifest(c.deploymentManifestPath, c.deploymentVars, c.deploymentOp, releaseSetManifest, stage)
-		if err != nil {
-			return err
-		}
-
-		extractedStemcell, err = c.stemcellFetcher.GetStemcell(deploymentManifest, stage)
-		return err
-	})
+	extractedStemcell, deploymentManifest, installationManifest, manifestSHA, err := c.validate(stage)
 	if err != nil {
 		return err
 	}
@@ -206,6 +193,116 @@ func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage) (err error) {
 
 }
 
+// PlanDeployment validates manifests and prints CPI actions
+// that PrepareDeployment would perform without invoking the CPI.
+// Deployment state is only read hence it's not locked nor migrated.
+func (c *DeploymentPreparer) PlanDeployment(stage biui.Stage) error {
+	c.ui.BeginLinef("Deployment state: '%s'\n", c.deploymentStateService.Path())
+
+	var deploymentState biconfig.DeploymentState
+
+	deployed := c.deploymentStateService.Exists()
+	if deployed {
+		var err error
+
+		deploymentState, err = c.deploymentStateService.Load()
+		if err != nil {
+			return bosherr.WrapError(err, "Loading deployment state")
+		}
+	}
+
+	defer func() {
+		err := c.releaseManager.DeleteAll()
+		if err != nil {
+			c.logger.Warn(c.logTag, "Deleting all extracted releases: %s", err.Error())
+		}
+	}()
+
+	extractedStemcell, deploymentManifest, _, manifestSHA, err := c.validate(stage)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		deleteErr := extractedStemcell.Delete()
+		if deleteErr != nil {
+			c.logger.Warn(c.logTag, "Failed to delete extracted stemcell: %s", deleteErr.Error())
+		}
+	}()
+
+	if deployed {
+		isDeployed, err := c.deploymentRecord.IsDeployed(manifestSHA, c.releaseManager.List(), extractedStemcell)
+		if err != nil {
+			return bosherr.WrapError(err, "Checking if deployment has changed")
+		}
+
+		if isDeployed {
+			c.ui.BeginLinef("No deployment, stemcell or release changes. Nothing to deploy.\n")
+			return nil
+		}
+	}
+
+	actions, err := c.deploymentPlanner.Plan(deploymentState, deploymentManifest, extractedStemcell)
+	if err != nil {
+		return bosherr.WrapError(err, "Planning deployment")
+	}
+
+	table := boshtbl.Table{
+		Content: "actions",
+		Header:  []string{"#", "Action", "Description"},
+		SortBy:  []boshtbl.ColumnSort{{Column: 0, Asc: true}},
+	}
+
+	for i, action := range actions {
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueInt(i + 1),
+			boshtbl.NewValueString(action.Name),
+			boshtbl.NewValueString(action.Description),
+		})
+	}
+
+	c.ui.PrintTable(table)
+
+	return nil
+}
+
+func (c *DeploymentPreparer) validate(stage biui.Stage) (
+	extractedStemcell bistemcell.ExtractedStemcell,
+	deploymentManifest bideplmanifest.Manifest,
+	installationManifest biinstallmanifest.Manifest,
+	manifestSHA string,
+	err error,
+) {
+	err = stage.PerformComplex("validating", func(stage biui.Stage) error {
+		var releaseSetManifest birelsetmanifest.Manifest
+		releaseSetManifest, installationManifest, err = c.releaseSetAndInstallationManifestParser.ReleaseSetAndInstallationManifest(c.deploymentManifestPath, c.deploymentVars, c.deploymentOp)
+		if err != nil {
+			return err
+		}
+
+		for _, releaseRef := range releaseSetManifest.Releases {
+			err = c.releaseFetcher.DownloadAndExtract(releaseRef, stage)
+			if err != nil {
+				return err
+			}
+		}
+
+		err := c.cpiInstaller.ValidateCpiRelease(installationManifest, stage)
+		if err != nil {
+			return err
+		}
+
+		deploymentManifest, manifestSHA, err = c.deploymentManifestParser.GetDeploymentManifest(c.deploymentManifestPath, c.deploymentVars, c.deploymentOp, releaseSetManifest, stage)
+		if err != nil {
+			return err
+		}
+
+		extractedStemcell, err = c.stemcellFetcher.GetStemcell(deploymentManifest, stage)
+		return err
+	})
+
+	return
+}
+
 func (c *DeploymentPreparer) deploy(
 	installation biinstall.Installation,
 	deploymentState biconfig.DeploymentState,
@@ -230,7 +327,7 @@ func (c *DeploymentPreparer) deploy(
 	agentClient := c.agentClientFactory.NewAgentClient(deploymentState.DirectorID, installationManifest.Mbus)
 	vmManager := c.vmManagerFactory.NewManager(cloud, agentClient)
 
-	blobstore, err := c.blobstoreFactory.Create(installationManifest.Mbus, bihttpclient.CreateDefaultClientInsecureSkipVerify())
+	blobstore, err := c.blobstoreFactory.Create(installationManifest.Mbus, boshproxy.CreateDefaultClientInsecureSkipVerify())
 	if err != nil {
 		return bosherr.WrapError(err, "Creating blobstore client")
 	}
@@ -33,6 +33,7 @@ import (
 	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
 	bitemplate "github.com/cloudfoundry/bosh-cli/templatescompiler"
 	bitemplateerb "github.com/cloudfoundry/bosh-cli/templatescompiler/erbrenderer"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	bihttpclient "github.com/cloudfoundry/bosh-utils/httpclient"
 )
 
@@ -48,6 +49,7 @@ type envFactory struct {
 	releaseManager  boshinst.ReleaseManager
 	releaseFetcher  boshinst.ReleaseFetcher
 	stemcellFetcher bistemcell.Fetcher
+	tarballCache    bitarball.Cache
 
 	cpiInstaller   bicpirel.CpiInstaller
 	targetProvider boshinst.TargetProvider
@@ -66,7 +68,16 @@ type envFactory struct {
 	deploymentRecord   bidepl.Record
 }
 
-func NewEnvFactory(deps BasicDeps, manifestPath string, statePath string, manifestVars boshtpl.Variables, manifestOp patch.Op) *envFactory {
+func NewEnvFactory(
+	deps BasicDeps,
+	manifestPath string,
+	statePath string,
+	forceUnlock bool,
+	cpiCallsFlags CPICallsFlags,
+	compilationWorkers int,
+	manifestVars boshtpl.Variables,
+	manifestOp patch.Op,
+) *envFactory {
 	f := envFactory{
 		deps:         deps,
 		manifestPath: manifestPath,
@@ -82,10 +93,10 @@ func NewEnvFactory(deps BasicDeps, manifestPath string, statePath string, manife
 
 	{
 		tarballCacheBasePath := gopath.Join(workspaceRootPath, "downloads")
-		tarballCache := bitarball.NewCache(tarballCacheBasePath, deps.FS, deps.Logger)
+		f.tarballCache = bitarball.NewCache(tarballCacheBasePath, deps.FS, deps.Time, deps.Logger)
 		httpClient := bihttpclient.NewHTTPClient(bitarball.HTTPClient, deps.Logger)
 		tarballProvider := bitarball.NewProvider(
-			tarballCache, deps.FS, httpClient, deps.SHA1Calc, 3, 500*time.Millisecond, deps.Logger)
+			f.tarballCache, deps.FS, httpClient, deps.SHA1Calc, 3, 500*time.Millisecond, deps.Logger)
 
 		releaseProvider := boshrel.NewProvider(
 			deps.CmdRunner, deps.Compressor, deps.SHA1Calc, deps.FS, deps.Logger)
@@ -105,14 +116,19 @@ func NewEnvFactory(deps BasicDeps, manifestPath string, statePath string, manife
 		}
 	}
 
-	f.deploymentStateService = biconfig.NewFileSystemDeploymentStateService(
-		deps.FS, deps.UUIDGen, deps.Logger, biconfig.DeploymentStatePath(manifestPath, statePath))
+	if biconfig.IsRemoteDeploymentStatePath(statePath) {
+		f.deploymentStateService = biconfig.NewRemoteDeploymentStateService(
+			statePath, forceUnlock, deps.UUIDGen, deps.Logger)
+	} else {
+		f.deploymentStateService = biconfig.NewFileSystemDeploymentStateService(
+			deps.FS, deps.UUIDGen, deps.Logger, biconfig.DeploymentStatePath(manifestPath, statePath))
+	}
 
 	{
 		registryServer := biregistry.NewServerManager(deps.Logger)
 		installerFactory := boshinst.NewInstallerFactory(
 			deps.UI, deps.CmdRunner, deps.Compressor, releaseJobResolver,
-			deps.UUIDGen, registryServer, deps.Logger, deps.FS)
+			deps.UUIDGen, registryServer, compilationWorkers, deps.Logger, deps.FS)
 
 		f.cpiInstaller = bicpirel.CpiInstaller{
 			ReleaseManager:   f.releaseManager,
@@ -144,12 +160,12 @@ func NewEnvFactory(deps BasicDeps, manifestPath string, statePath string, manife
 	{
 		f.blobstoreFactory = biblobstore.NewBlobstoreFactory(deps.UUIDGen, deps.FS, deps.Logger)
 		f.deploymentFactory = bidepl.NewFactory(10*time.Second, 500*time.Millisecond)
-		f.agentClientFactory = bihttpagent.NewAgentClientFactory(1*time.Second, deps.Logger)
-		f.cloudFactory = bicloud.NewFactory(deps.FS, deps.CmdRunner, deps.Logger)
+		f.agentClientFactory = NewAgentClientFactory(1*time.Second, deps.Logger)
+		f.cloudFactory = cpiCallsFlags.AsCloudFactory(deps.FS, deps.CmdRunner, deps.Logger)
 	}
 
 	{
-		erbRenderer := bitemplateerb.NewERBRenderer(deps.FS, deps.CmdRunner, deps.Logger)
+		erbRenderer := bitemplateerb.NewCompatERBRenderer(deps.FS, deps.CmdRunner, deps.Logger)
 		jobRenderer := bitemplate.NewJobRenderer(erbRenderer, deps.FS, deps.UUIDGen, deps.Logger)
 
 		builderFactory := biinstancestate.NewBuilderFactory(
@@ -197,6 +213,7 @@ func (f *envFactory) Preparer() DeploymentPreparer {
 		),
 		f.releaseManager,
 		f.deploymentRecord,
+		bidepl.NewPlanner(),
 		f.cloudFactory,
 		f.stemcellManagerFactory,
 		f.agentClientFactory,
@@ -254,3 +271,19 @@ func (f *envFactory) Deleter() DeploymentDeleter {
 		f.targetProvider,
 	)
 }
+
+// TarballCache returns cache of releases and stemcells downloaded by create-env
+func (f *envFactory) TarballCache() bitarball.Cache {
+	return f.tarballCache
+}
+
+// InstallationTarget returns installation of the environment;
+// new installation is only recorded in deployment state when mustExist is false
+func (f *envFactory) InstallationTarget(mustExist bool) (boshinst.Target, error) {
+	if mustExist && !f.deploymentStateService.Exists() {
+		return boshinst.Target{}, bosherr.Errorf(
+			"Expected deployment state '%s' to exist", f.deploymentStateService.Path())
+	}
+
+	return f.targetProvider.NewTarget()
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"strings"
+	"sync"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+// EnvironmentsFanOut runs read command against multiple directors concurrently
+// and prints their tables merged together with an additional environment column.
+// Failure to query one director does not prevent others from being shown.
+type EnvironmentsFanOut struct {
+	environments    []string
+	directorFactory func(environment string) (boshdir.Director, error)
+	ui              boshui.UI
+}
+
+type envFanOutResult struct {
+	tables []boshtbl.Table
+	err    error
+}
+
+func NewEnvironmentsFanOut(
+	environments []string,
+	directorFactory func(environment string) (boshdir.Director, error),
+	ui boshui.UI,
+) EnvironmentsFanOut {
+	return EnvironmentsFanOut{
+		environments:    environments,
+		directorFactory: directorFactory,
+		ui:              ui,
+	}
+}
+
+func (f EnvironmentsFanOut) Run(run func(boshui.UI, boshdir.Director) error) error {
+	results := make([]envFanOutResult, len(f.environments))
+
+	var wg sync.WaitGroup
+
+	for i, environment := range f.environments {
+		wg.Add(1)
+
+		go func(i int, environment string) {
+			defer wg.Done()
+			results[i] = f.runOne(environment, run)
+		}(i, environment)
+	}
+
+	wg.Wait()
+
+	var tables []boshtbl.Table
+	var errs []error
+
+	for i, result := range results {
+		environment := f.environments[i]
+
+		if result.err != nil {
+			errs = append(errs, bosherr.WrapErrorf(result.err, "Environment '%s'", environment))
+			continue
+		}
+
+		for _, table := range result.tables {
+			tables = f.mergeTable(tables, table.AddColumn("Environment", boshtbl.NewValueString(environment)))
+		}
+	}
+
+	for _, table := range tables {
+		f.ui.PrintTable(table)
+	}
+
+	if len(errs) > 0 {
+		return bosherr.WrapErrorf(bosherr.NewMultiError(errs...),
+			"Failed to query %d of %d environments", len(errs), len(f.environments))
+	}
+
+	return nil
+}
+
+func (f EnvironmentsFanOut) runOne(environment string, run func(boshui.UI, boshdir.Director) error) envFanOutResult {
+	director, err := f.directorFactory(environment)
+	if err != nil {
+		return envFanOutResult{err: err}
+	}
+
+	ui := &tableCollectingUI{UI: f.ui}
+
+	err = run(ui, director)
+	if err != nil {
+		return envFanOutResult{err: err}
+	}
+
+	return envFanOutResult{tables: ui.tables}
+}
+
+// mergeTable appends rows to a previously collected table of the same shape
+// (e.g. vms of the same deployment name found in different environments)
+func (f EnvironmentsFanOut) mergeTable(tables []boshtbl.Table, table boshtbl.Table) []boshtbl.Table {
+	for i, existing := range tables {
+		if f.tableKey(existing) == f.tableKey(table) {
+			tables[i].Rows = append(tables[i].Rows, table.Rows...)
+			return tables
+		}
+	}
+
+	return append(tables, table)
+}
+
+func (f EnvironmentsFanOut) tableKey(table boshtbl.Table) string {
+	header := append([]string{table.Title, table.Content}, table.Header...)
+
+	for _, val := range table.HeaderVals {
+		header = append(header, val.String())
+	}
+
+	return strings.Join(header, "\x00")
+}
+
+// tableCollectingUI holds on to printed tables so that they could be merged
+type tableCollectingUI struct {
+	boshui.UI
+	tables []boshtbl.Table
+}
+
+func (ui *tableCollectingUI) PrintTable(table boshtbl.Table) {
+	ui.tables = append(ui.tables, table)
+}
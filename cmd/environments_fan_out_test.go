@@ -0,0 +1,114 @@
+package cmd_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	fakedir "github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+var _ = Describe("EnvironmentsFanOut", func() {
+	var (
+		directors    map[string]*fakedir.FakeDirector
+		directorErrs map[string]error
+		ui           *fakeui.FakeUI
+		fanOut       EnvironmentsFanOut
+	)
+
+	BeforeEach(func() {
+		directors = map[string]*fakedir.FakeDirector{
+			"env1": &fakedir.FakeDirector{},
+			"env2": &fakedir.FakeDirector{},
+			"env3": &fakedir.FakeDirector{},
+		}
+		directorErrs = map[string]error{}
+
+		directorFactory := func(environment string) (boshdir.Director, error) {
+			return directors[environment], directorErrs[environment]
+		}
+
+		ui = &fakeui.FakeUI{}
+		fanOut = NewEnvironmentsFanOut([]string{"env1", "env2", "env3"}, directorFactory, ui)
+	})
+
+	locksFor := func(resource string) []boshdir.Lock {
+		return []boshdir.Lock{{Type: "deployment", Resource: []string{resource}}}
+	}
+
+	runLocks := func(ui boshui.UI, director boshdir.Director) error {
+		return NewLocksCmd(ui, director).Run()
+	}
+
+	It("merges tables from all environments adding environment column", func() {
+		directors["env1"].LocksReturns(locksFor("dep1"), nil)
+		directors["env2"].LocksReturns(locksFor("dep2"), nil)
+		directors["env3"].LocksReturns(nil, nil)
+
+		err := fanOut.Run(runLocks)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ui.Tables).To(HaveLen(1))
+
+		table := ui.Tables[0]
+		Expect(table.Content).To(Equal("locks"))
+		Expect(table.Header).To(Equal([]string{"Environment", "Type", "Resource", "Expires at"}))
+		Expect(table.SortBy).To(Equal([]boshtbl.ColumnSort{
+			{Column: 0, Asc: true},
+			{Column: 3, Asc: true},
+		}))
+
+		Expect(table.Rows).To(HaveLen(2))
+		Expect(table.Rows[0][0]).To(Equal(boshtbl.NewValueString("env1")))
+		Expect(table.Rows[0][2]).To(Equal(boshtbl.NewValueString("dep1")))
+		Expect(table.Rows[1][0]).To(Equal(boshtbl.NewValueString("env2")))
+		Expect(table.Rows[1][2]).To(Equal(boshtbl.NewValueString("dep2")))
+	})
+
+	It("keeps tables of different shape separate", func() {
+		err := fanOut.Run(func(ui boshui.UI, director boshdir.Director) error {
+			title := "Deployment 'dep1'"
+			if director == directors["env2"] {
+				title = "Deployment 'dep2'"
+			}
+
+			ui.PrintTable(boshtbl.Table{
+				Title:  title,
+				Header: []string{"Instance"},
+				Rows:   [][]boshtbl.Value{{boshtbl.NewValueString("inst")}},
+			})
+
+			return nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ui.Tables).To(HaveLen(2))
+		Expect(ui.Tables[0].Title).To(Equal("Deployment 'dep1'"))
+		Expect(ui.Tables[0].Rows).To(HaveLen(2))
+		Expect(ui.Tables[1].Title).To(Equal("Deployment 'dep2'"))
+		Expect(ui.Tables[1].Rows).To(HaveLen(1))
+	})
+
+	It("shows results of other environments and returns error if some environments fail", func() {
+		directors["env1"].LocksReturns(nil, errors.New("fake-locks-err"))
+		directors["env3"].LocksReturns(locksFor("dep3"), nil)
+		directorErrs["env2"] = errors.New("fake-director-err")
+
+		err := fanOut.Run(runLocks)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(
+			"Failed to query 2 of 3 environments: " +
+				"Environment 'env1': fake-locks-err\n" +
+				"Environment 'env2': fake-director-err"))
+
+		Expect(ui.Tables).To(HaveLen(1))
+		Expect(ui.Tables[0].Rows).To(HaveLen(1))
+		Expect(ui.Tables[0].Rows[0][0]).To(Equal(boshtbl.NewValueString("env3")))
+	})
+})
@@ -1,18 +1,69 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/pivotal-golang/clock"
+
 	boshdir "github.com/cloudfoundry/bosh-cli/director"
 	boshui "github.com/cloudfoundry/bosh-cli/ui"
 	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
 )
 
+const eventsFollowInterval = 2 * time.Second
+
+// eventsFollowColumns are printed with fixed widths when following events
+// so that rows printed by different polls line up with the header.
+// Last column is not padded.
+var eventsFollowColumns = []struct {
+	Header string
+	Width  int
+}{
+	{"ID", 16},
+	{"Time", 29},
+	{"User", 16},
+	{"Action", 10},
+	{"Object Type", 16},
+	{"Object ID", 32},
+	{"Task ID", 8},
+	{"Deployment", 20},
+	{"Instance", 48},
+	{"Context", 24},
+	{"Error", 0},
+}
+
 type EventsCmd struct {
-	ui       boshui.UI
-	director boshdir.Director
+	ui          boshui.UI
+	director    boshdir.Director
+	timeService clock.Clock
+
+	// When set, UI buffers output until command finishes hence events cannot be followed
+	jsonOutput bool
 }
 
-func NewEventsCmd(ui boshui.UI, director boshdir.Director) EventsCmd {
-	return EventsCmd{ui: ui, director: director}
+func NewEventsCmd(ui boshui.UI, director boshdir.Director, timeService clock.Clock, jsonOutput bool) EventsCmd {
+	return EventsCmd{ui: ui, director: director, timeService: timeService, jsonOutput: jsonOutput}
+}
+
+type eventJSON struct {
+	ID         string                 `json:"id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Time       time.Time              `json:"time"`
+	User       string                 `json:"user"`
+	Action     string                 `json:"action"`
+	ObjectType string                 `json:"object_type"`
+	ObjectName string                 `json:"object_name"`
+	TaskID     string                 `json:"task"`
+	Deployment string                 `json:"deployment"`
+	Instance   string                 `json:"instance"`
+	Context    map[string]interface{} `json:"context"`
+	Error      string                 `json:"error"`
 }
 
 func (c EventsCmd) Run(opts EventsOpts) error {
@@ -29,25 +80,199 @@ func (c EventsCmd) Run(opts EventsOpts) error {
 		ObjectName: opts.ObjectName,
 	}
 
+	if opts.Follow {
+		return c.follow(filter, opts.NDJSON)
+	}
+
 	events, err := c.director.Events(filter)
 	if err != nil {
 		return err
 	}
 
-	table := boshtbl.Table{
-		Content: "events",
-		Header:  []string{"ID", "Time", "User", "Action", "Object Type", "Object ID", "Task ID", "Deployment", "Instance", "Context", "Error"},
+	if opts.NDJSON {
+		return c.printNDJSON(events)
+	}
+
+	c.ui.PrintTable(c.buildTable(events))
+
+	return nil
+}
+
+// follow keeps asking the Director for events newer than the last seen one
+// until listing events fails. Events are printed from oldest to newest.
+func (c EventsCmd) follow(filter boshdir.EventsFilter, ndjson bool) error {
+	if len(filter.BeforeID) > 0 || len(filter.Before) > 0 {
+		return bosherr.Error("Expected '--before-id' and '--before' to not be used when following events")
+	}
+
+	if c.jsonOutput {
+		return bosherr.Error("Expected '--json' to not be used when following events; use '--ndjson' instead")
+	}
+
+	if !ndjson {
+		c.printFollowRow(c.followHeader())
+	}
+
+	var lastID int
+
+	for {
+		events, err := c.director.Events(filter)
+		if err != nil {
+			return err
+		}
+
+		events, lastID = c.newEvents(events, lastID)
+
+		if ndjson {
+			err = c.printNDJSON(events)
+			if err != nil {
+				return err
+			}
+		} else {
+			for _, e := range events {
+				c.printFollowRow(c.followRow(e))
+			}
+		}
+
+		filter.AfterID = strconv.Itoa(lastID)
+
+		c.timeService.Sleep(eventsFollowInterval)
+	}
+}
+
+// newEvents returns events with IDs greater than lastID sorted by ID
+// since Director returns newest events first
+func (c EventsCmd) newEvents(events []boshdir.Event, lastID int) ([]boshdir.Event, int) {
+	var newEvents []boshdir.Event
+
+	maxID := lastID
+
+	for _, e := range events {
+		id, err := strconv.Atoi(e.ID())
+		if err != nil || id <= lastID {
+			continue
+		}
+
+		newEvents = append(newEvents, e)
+
+		if id > maxID {
+			maxID = id
+		}
 	}
 
+	sort.SliceStable(newEvents, func(i, j int) bool {
+		idI, _ := strconv.Atoi(newEvents[i].ID())
+		idJ, _ := strconv.Atoi(newEvents[j].ID())
+		return idI < idJ
+	})
+
+	return newEvents, maxID
+}
+
+func (c EventsCmd) printNDJSON(events []boshdir.Event) error {
 	for _, e := range events {
-		id := e.ID()
+		bytes, err := json.Marshal(eventJSON{
+			ID:         e.ID(),
+			ParentID:   e.ParentID(),
+			Time:       e.Timestamp(),
+			User:       e.User(),
+			Action:     e.Action(),
+			ObjectType: e.ObjectType(),
+			ObjectName: e.ObjectName(),
+			TaskID:     e.TaskID(),
+			Deployment: e.DeploymentName(),
+			Instance:   e.Instance(),
+			Context:    e.Context(),
+			Error:      e.Error(),
+		})
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Marshaling event '%s'", e.ID())
+		}
+
+		c.ui.PrintBlock(string(bytes) + "\n")
+	}
+
+	return nil
+}
+
+func (c EventsCmd) followHeader() []string {
+	var header []string
+
+	for _, col := range eventsFollowColumns {
+		header = append(header, col.Header)
+	}
+
+	return header
+}
+
+func (c EventsCmd) followRow(e boshdir.Event) []string {
+	var context string
+
+	if len(e.Context()) > 0 {
+		bytes, err := json.Marshal(e.Context())
+		if err != nil {
+			context = fmt.Sprintf("<serialization error> : %#v", e.Context())
+		} else {
+			context = string(bytes)
+		}
+	}
+
+	return []string{
+		c.eventID(e),
+		boshtbl.NewValueTime(e.Timestamp()).String(),
+		e.User(),
+		e.Action(),
+		e.ObjectType(),
+		e.ObjectName(),
+		e.TaskID(),
+		e.DeploymentName(),
+		e.Instance(),
+		context,
+		e.Error(),
+	}
+}
+
+// printFollowRow prints single line padding each value to its column width.
+// Values longer than their column are not truncated.
+func (c EventsCmd) printFollowRow(vals []string) {
+	var line string
+
+	for i, val := range vals {
+		val = strings.Replace(strings.Replace(val, "\r", "", -1), "\n", " ", -1)
 
-		if e.ParentID() != "" {
-			id += " <- " + e.ParentID()
+		if len(val) == 0 {
+			val = "-"
 		}
 
+		if i < len(vals)-1 {
+			line += fmt.Sprintf("%-*s ", eventsFollowColumns[i].Width-1, val)
+		} else {
+			line += val
+		}
+	}
+
+	c.ui.PrintLinef("%s", strings.TrimRight(line, " "))
+}
+
+func (c EventsCmd) eventID(e boshdir.Event) string {
+	id := e.ID()
+
+	if e.ParentID() != "" {
+		id += " <- " + e.ParentID()
+	}
+
+	return id
+}
+
+func (c EventsCmd) buildTable(events []boshdir.Event) boshtbl.Table {
+	table := boshtbl.Table{
+		Content: "events",
+		Header:  []string{"ID", "Time", "User", "Action", "Object Type", "Object ID", "Task ID", "Deployment", "Instance", "Context", "Error"},
+	}
+
+	for _, e := range events {
 		table.Rows = append(table.Rows, []boshtbl.Value{
-			boshtbl.NewValueString(id),
+			boshtbl.NewValueString(c.eventID(e)),
 			boshtbl.NewValueTime(e.Timestamp()),
 			boshtbl.NewValueString(e.User()),
 			boshtbl.NewValueString(e.Action()),
@@ -61,7 +286,5 @@ func (c EventsCmd) Run(opts EventsOpts) error {
 		})
 	}
 
-	c.ui.PrintTable(table)
-
-	return nil
+	return table
 }
@@ -6,6 +6,7 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/pivotal-golang/clock/fakeclock"
 
 	. "github.com/cloudfoundry/bosh-cli/cmd"
 	boshdir "github.com/cloudfoundry/bosh-cli/director"
@@ -16,16 +17,18 @@ import (
 
 var _ = Describe("EventsCmd", func() {
 	var (
-		ui       *fakeui.FakeUI
-		director *fakedir.FakeDirector
-		command  EventsCmd
-		events   []boshdir.Event
+		ui          *fakeui.FakeUI
+		director    *fakedir.FakeDirector
+		timeService *fakeclock.FakeClock
+		command     EventsCmd
+		events      []boshdir.Event
 	)
 
 	BeforeEach(func() {
 		ui = &fakeui.FakeUI{}
 		director = &fakedir.FakeDirector{}
-		command = NewEventsCmd(ui, director)
+		timeService = fakeclock.NewFakeClock(time.Now())
+		command = NewEventsCmd(ui, director, timeService, false)
 		events = []boshdir.Event{
 			&fakedir.FakeEvent{
 				IDStub:        func() string { return "4" },
@@ -66,6 +69,10 @@ var _ = Describe("EventsCmd", func() {
 			opts EventsOpts
 		)
 
+		BeforeEach(func() {
+			opts = EventsOpts{}
+		})
+
 		It("lists events", func() {
 			director.EventsReturns(events, nil)
 
@@ -148,5 +155,124 @@ var _ = Describe("EventsCmd", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("fake-err"))
 		})
+
+		It("lists events as newline-delimited JSON", func() {
+			opts.NDJSON = true
+
+			director.EventsReturns(events, nil)
+
+			err := command.Run(opts)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Tables).To(BeEmpty())
+			Expect(ui.Blocks).To(Equal([]string{
+				`{"id":"4","parent_id":"1","time":"2009-11-10T23:00:00Z","user":"user","action":"action","object_type":"object-type","object_name":"object-name","task":"task","deployment":"deployment","instance":"instance","context":{"user":"bosh_z$"},"error":""}` + "\n",
+				`{"id":"5","time":"2090-11-10T23:00:00Z","user":"user2","action":"action2","object_type":"object-type2","object_name":"object-name2","task":"task2","deployment":"deployment2","instance":"instance2","context":{},"error":"some-error"}` + "\n",
+			}))
+		})
+
+		Context("when following events", func() {
+			var (
+				newEvent boshdir.Event
+			)
+
+			BeforeEach(func() {
+				opts.Follow = true
+				opts.Deployment = "deployment"
+
+				newEvent = &fakedir.FakeEvent{
+					IDStub:        func() string { return "6" },
+					TimestampStub: func() time.Time { return time.Date(2091, time.November, 10, 23, 0, 0, 0, time.UTC) },
+					ContextStub:   func() map[string]interface{} { return map[string]interface{}{} },
+				}
+
+				// Lets command proceed once it starts waiting for next poll
+				incrementAfterSleep := func() {
+					go func() {
+						for timeService.WatcherCount() == 0 {
+							time.Sleep(time.Millisecond)
+						}
+						timeService.Increment(2 * time.Second)
+					}()
+				}
+
+				director.EventsStub = func(filter boshdir.EventsFilter) ([]boshdir.Event, error) {
+					switch director.EventsCallCount() {
+					case 1:
+						incrementAfterSleep()
+						// Director returns newest events first
+						return []boshdir.Event{events[1], events[0]}, nil
+					case 2:
+						incrementAfterSleep()
+						return []boshdir.Event{newEvent, events[1]}, nil
+					default:
+						return nil, errors.New("fake-err")
+					}
+				}
+			})
+
+			It("keeps listing events newer than the last seen event with the same filters", func() {
+				err := command.Run(opts)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-err"))
+
+				Expect(director.EventsCallCount()).To(Equal(3))
+				Expect(director.EventsArgsForCall(0)).To(Equal(boshdir.EventsFilter{Deployment: "deployment"}))
+				Expect(director.EventsArgsForCall(1)).To(Equal(boshdir.EventsFilter{Deployment: "deployment", AfterID: "5"}))
+				Expect(director.EventsArgsForCall(2)).To(Equal(boshdir.EventsFilter{Deployment: "deployment", AfterID: "6"}))
+
+				Expect(ui.Tables).To(BeEmpty())
+				Expect(ui.Said).To(Equal([]string{
+					"ID              Time                         User            Action    Object Type     Object ID                       Task ID Deployment          Instance                                        Context                 Error",
+					"4 <- 1          Tue Nov 10 23:00:00 UTC 2009 user            action    object-type     object-name                     task    deployment          instance                                        {\"user\":\"bosh_z$\"}      -",
+					"5               Fri Nov 10 23:00:00 UTC 2090 user2           action2   object-type2    object-name2                    task2   deployment2         instance2                                       -                       some-error",
+					"6               Sat Nov 10 23:00:00 UTC 2091 -               -         -               -                               -       -                   -                                               -                       -",
+				}))
+			})
+
+			It("keeps values longer than their column on a single line", func() {
+				newEvent.(*fakedir.FakeEvent).ErrorStub = func() string { return "multi\nline error" }
+
+				err := command.Run(opts)
+				Expect(err).To(HaveOccurred())
+
+				Expect(ui.Said).To(HaveLen(4))
+				Expect(ui.Said[3]).To(HaveSuffix(" multi line error"))
+			})
+
+			It("returns error if JSON output is requested", func() {
+				command = NewEventsCmd(ui, director, timeService, true)
+
+				err := command.Run(opts)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Expected '--json' to not be used when following events; use '--ndjson' instead"))
+
+				Expect(director.EventsCallCount()).To(Equal(0))
+				Expect(ui.Said).To(BeEmpty())
+			})
+
+			It("streams new events as newline-delimited JSON", func() {
+				opts.NDJSON = true
+
+				err := command.Run(opts)
+				Expect(err).To(HaveOccurred())
+
+				Expect(ui.Tables).To(BeEmpty())
+				Expect(ui.Blocks).To(HaveLen(3))
+				Expect(ui.Blocks[0]).To(ContainSubstring(`"id":"4"`))
+				Expect(ui.Blocks[1]).To(ContainSubstring(`"id":"5"`))
+				Expect(ui.Blocks[2]).To(ContainSubstring(`"id":"6"`))
+			})
+
+			It("returns error if before filters are used", func() {
+				opts.BeforeID = "3"
+
+				err := command.Run(opts)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Expected '--before-id' and '--before' to not be used when following events"))
+
+				Expect(director.EventsCallCount()).To(Equal(0))
+			})
+		})
 	})
 })
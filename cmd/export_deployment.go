@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+type ExportDeploymentCmd struct {
+	ui         boshui.UI
+	director   boshdir.Director
+	deployment boshdir.Deployment
+	archive    DeploymentBundleArchive
+}
+
+func NewExportDeploymentCmd(
+	ui boshui.UI,
+	director boshdir.Director,
+	deployment boshdir.Deployment,
+	archive DeploymentBundleArchive,
+) ExportDeploymentCmd {
+	return ExportDeploymentCmd{ui: ui, director: director, deployment: deployment, archive: archive}
+}
+
+func (c ExportDeploymentCmd) Run(opts ExportDeploymentOpts) error {
+	bundle := DeploymentBundle{Name: c.deployment.Name()}
+
+	manifest, err := c.deployment.Manifest()
+	if err != nil {
+		return err
+	}
+
+	bundle.Manifest = manifest
+
+	releases, err := c.deployment.Releases()
+	if err != nil {
+		return err
+	}
+
+	for _, rel := range releases {
+		bundle.Releases = append(bundle.Releases, DeploymentBundleRelease{
+			Name:    rel.Name(),
+			Version: rel.Version().String(),
+		})
+	}
+
+	stemcells, err := c.deployment.Stemcells()
+	if err != nil {
+		return err
+	}
+
+	for _, stem := range stemcells {
+		bundle.Stemcells = append(bundle.Stemcells, DeploymentBundleStemcell{
+			Name:    stem.Name(),
+			Version: stem.Version().String(),
+			OS:      stem.OSName(),
+		})
+	}
+
+	variables, err := c.deployment.Variables()
+	if err != nil {
+		return err
+	}
+
+	for _, variable := range variables {
+		bundle.Variables = append(bundle.Variables, variable.Name)
+	}
+
+	// Directors are not required to have cloud and runtime configs
+	cloudConfig, err := c.director.LatestCloudConfig()
+	if err != nil {
+		c.ui.ErrorLinef("Skipping cloud config: %s", err)
+	} else {
+		bundle.CloudConfig = cloudConfig.Properties
+	}
+
+	runtimeConfig, err := c.director.LatestRuntimeConfig()
+	if err != nil {
+		c.ui.ErrorLinef("Skipping runtime config: %s", err)
+	} else {
+		bundle.RuntimeConfig = runtimeConfig.Properties
+	}
+
+	err = c.archive.Write(bundle, opts.Args.Path)
+	if err != nil {
+		return err
+	}
+
+	c.ui.PrintTable(deploymentBundleTable(bundle, nil))
+	c.ui.PrintLinef("Exported deployment '%s' to '%s'", bundle.Name, opts.Args.Path)
+
+	// Director only knows names of variables it generated; values interpolated
+	// by the CLI before deploying (e.g. via --var or --vars-store) are part of the manifest
+	c.ui.ErrorLinef("Manifest may include values of variables interpolated by the CLI (e.g. via --var or --vars-store) in plaintext")
+
+	return nil
+}
+
+// deploymentBundleTable lists bundle contents; if missing is given
+// an additional column shows whether each item is missing on the director
+func deploymentBundleTable(bundle DeploymentBundle, missing map[string]bool) boshtbl.Table {
+	table := boshtbl.Table{
+		Content: "bundle contents",
+		Header:  []string{"Type", "Name", "Version"},
+		SortBy: []boshtbl.ColumnSort{
+			{Column: 0, Asc: true},
+			{Column: 1, Asc: true},
+		},
+	}
+
+	if missing != nil {
+		table.Header = append(table.Header, "Missing")
+	}
+
+	addRow := func(typ, name, version string) {
+		row := []boshtbl.Value{
+			boshtbl.NewValueString(typ),
+			boshtbl.NewValueString(name),
+			boshtbl.NewValueString(version),
+		}
+
+		switch {
+		case missing == nil:
+		case typ == "variable":
+			// Variables are generated or provided during deploy
+			row = append(row, boshtbl.NewValueString(""))
+		default:
+			isMissing := missing[typ+"/"+name+"/"+version]
+			row = append(row, boshtbl.NewValueFmt(boshtbl.NewValueBool(isMissing), isMissing))
+		}
+
+		table.Rows = append(table.Rows, row)
+	}
+
+	for _, rel := range bundle.Releases {
+		addRow("release", rel.Name, rel.Version)
+	}
+
+	for _, stem := range bundle.Stemcells {
+		addRow("stemcell", stem.Name, stem.Version)
+	}
+
+	for _, name := range bundle.Variables {
+		addRow("variable", name, "")
+	}
+
+	return table
+}
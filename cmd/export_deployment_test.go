@@ -0,0 +1,175 @@
+package cmd_test
+
+import (
+	"errors"
+
+	semver "github.com/cppforlife/go-semi-semantic/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	fakecmd "github.com/cloudfoundry/bosh-cli/cmd/cmdfakes"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	fakedir "github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+var _ = Describe("ExportDeploymentCmd", func() {
+	var (
+		ui         *fakeui.FakeUI
+		director   *fakedir.FakeDirector
+		deployment *fakedir.FakeDeployment
+		archive    *fakecmd.FakeDeploymentBundleArchive
+		command    ExportDeploymentCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		director = &fakedir.FakeDirector{}
+		deployment = &fakedir.FakeDeployment{
+			NameStub: func() string { return "dep" },
+		}
+		archive = &fakecmd.FakeDeploymentBundleArchive{}
+		command = NewExportDeploymentCmd(ui, director, deployment, archive)
+	})
+
+	Describe("Run", func() {
+		var (
+			opts ExportDeploymentOpts
+		)
+
+		BeforeEach(func() {
+			opts = ExportDeploymentOpts{
+				Args: DeploymentBundleArgs{Path: "/bundle.tgz"},
+			}
+
+			deployment.ManifestReturns("name: dep\n", nil)
+
+			deployment.ReleasesReturns([]boshdir.Release{
+				&fakedir.FakeRelease{
+					NameStub:    func() string { return "rel" },
+					VersionStub: func() semver.Version { return semver.MustNewVersionFromString("1.1") },
+				},
+			}, nil)
+
+			deployment.StemcellsReturns([]boshdir.Stemcell{
+				&fakedir.FakeStemcell{
+					NameStub:    func() string { return "stem" },
+					VersionStub: func() semver.Version { return semver.MustNewVersionFromString("3421.9") },
+					OSNameStub:  func() string { return "ubuntu-trusty" },
+				},
+			}, nil)
+
+			deployment.VariablesReturns([]boshdir.VariableResult{{ID: "1", Name: "/dir/dep/var"}}, nil)
+
+			director.LatestCloudConfigReturns(boshdir.CloudConfig{Properties: "networks: []\n"}, nil)
+			director.LatestRuntimeConfigReturns(boshdir.RuntimeConfig{Properties: "addons: []\n"}, nil)
+		})
+
+		act := func() error { return command.Run(opts) }
+
+		It("writes bundle with manifest, configs, releases, stemcells and variable names", func() {
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(archive.WriteCallCount()).To(Equal(1))
+
+			bundle, path := archive.WriteArgsForCall(0)
+			Expect(path).To(Equal("/bundle.tgz"))
+			Expect(bundle).To(Equal(DeploymentBundle{
+				Name: "dep",
+
+				Releases:  []DeploymentBundleRelease{{Name: "rel", Version: "1.1"}},
+				Stemcells: []DeploymentBundleStemcell{{Name: "stem", Version: "3421.9", OS: "ubuntu-trusty"}},
+				Variables: []string{"/dir/dep/var"},
+
+				Manifest:      "name: dep\n",
+				CloudConfig:   "networks: []\n",
+				RuntimeConfig: "addons: []\n",
+			}))
+
+			Expect(ui.Table).To(Equal(boshtbl.Table{
+				Content: "bundle contents",
+				Header:  []string{"Type", "Name", "Version"},
+				SortBy: []boshtbl.ColumnSort{
+					{Column: 0, Asc: true},
+					{Column: 1, Asc: true},
+				},
+				Rows: [][]boshtbl.Value{
+					{
+						boshtbl.NewValueString("release"),
+						boshtbl.NewValueString("rel"),
+						boshtbl.NewValueString("1.1"),
+					},
+					{
+						boshtbl.NewValueString("stemcell"),
+						boshtbl.NewValueString("stem"),
+						boshtbl.NewValueString("3421.9"),
+					},
+					{
+						boshtbl.NewValueString("variable"),
+						boshtbl.NewValueString("/dir/dep/var"),
+						boshtbl.NewValueString(""),
+					},
+				},
+			}))
+
+			Expect(ui.Said).To(Equal([]string{"Exported deployment 'dep' to '/bundle.tgz'"}))
+		})
+
+		It("warns that manifest may include values of variables interpolated by the CLI", func() {
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Errors).To(Equal([]string{
+				"Manifest may include values of variables interpolated by the CLI (e.g. via --var or --vars-store) in plaintext",
+			}))
+		})
+
+		It("skips configs that cannot be found", func() {
+			director.LatestCloudConfigReturns(boshdir.CloudConfig{}, errors.New("No cloud config"))
+			director.LatestRuntimeConfigReturns(boshdir.RuntimeConfig{}, errors.New("No runtime config"))
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			bundle, _ := archive.WriteArgsForCall(0)
+			Expect(bundle.CloudConfig).To(BeEmpty())
+			Expect(bundle.RuntimeConfig).To(BeEmpty())
+
+			Expect(ui.Errors).To(ContainElement("Skipping cloud config: No cloud config"))
+			Expect(ui.Errors).To(ContainElement("Skipping runtime config: No runtime config"))
+		})
+
+		It("returns error if manifest cannot be retrieved", func() {
+			deployment.ManifestReturns("", errors.New("fake-err"))
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-err"))
+
+			Expect(archive.WriteCallCount()).To(Equal(0))
+		})
+
+		It("returns error if variables cannot be retrieved", func() {
+			deployment.VariablesReturns(nil, errors.New("fake-err"))
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-err"))
+
+			Expect(archive.WriteCallCount()).To(Equal(0))
+		})
+
+		It("returns error if bundle cannot be written", func() {
+			archive.WriteReturns(errors.New("fake-err"))
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-err"))
+
+			Expect(ui.Said).To(BeEmpty())
+		})
+	})
+})
@@ -2,6 +2,7 @@ package cmd_test
 
 import (
 	"errors"
+	"time"
 
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
@@ -257,6 +258,26 @@ var _ = Describe("Factory", func() {
 		})
 	})
 
+	Describe("cache command", func() {
+		It("parses subcommands", func() {
+			cmd, err := factory.New([]string{"cache", "prune", "--older-than", "720h", "--max-size", "10GB"})
+			Expect(err).ToNot(HaveOccurred())
+
+			opts := cmd.Opts.(*CachePruneOpts)
+			Expect(opts.OlderThan).To(Equal(720 * time.Hour))
+			Expect(opts.MaxSize).To(Equal(ByteSizeArg(10000000000)))
+
+			cmd, err = factory.New([]string{"cache", "ls"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cmd.Opts).To(BeAssignableToTypeOf(&CacheListOpts{}))
+		})
+
+		It("requires subcommand", func() {
+			_, err := factory.New([]string{"cache"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	Describe("help command", func() {
 		It("has a help command", func() {
 			cmd, err := factory.New([]string{"help"})
@@ -307,6 +328,7 @@ var _ = Describe("Factory", func() {
 			boshOpts.CACertOpt.FS = nil // fs is populated by factory.New
 			boshOpts.UploadRelease = UploadReleaseOpts{}
 			boshOpts.ExportRelease = ExportReleaseOpts{}
+			boshOpts.CreateEnv = CreateEnvOpts{}
 			boshOpts.RunErrand = RunErrandOpts{}
 			boshOpts.Logs = LogsOpts{}
 			boshOpts.Interpolate = InterpolateOpts{}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	bicrypto "github.com/cloudfoundry/bosh-cli/crypto"
+)
+
+// verifyFileSHA1 checks local file against expected SHA1 (if given)
+// before it is uploaded; kind (e.g. 'release') is used in error messages.
+func verifyFileSHA1(sha1calc bicrypto.SHA1Calculator, path, expectedSHA1, kind string) error {
+	if len(expectedSHA1) == 0 {
+		return nil
+	}
+
+	actualSHA1, err := sha1calc.Calculate(path)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Calculating %s SHA1", kind)
+	}
+
+	if actualSHA1 != expectedSHA1 {
+		return bosherr.Errorf("Expected %s SHA1 to be '%s' but was '%s'", kind, expectedSHA1, actualSHA1)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+)
+
+type ImportDeploymentCmd struct {
+	ui       boshui.UI
+	director boshdir.Director
+	archive  DeploymentBundleArchive
+}
+
+func NewImportDeploymentCmd(
+	ui boshui.UI,
+	director boshdir.Director,
+	archive DeploymentBundleArchive,
+) ImportDeploymentCmd {
+	return ImportDeploymentCmd{ui: ui, director: director, archive: archive}
+}
+
+func (c ImportDeploymentCmd) Run(opts ImportDeploymentOpts) error {
+	bundle, err := c.archive.Read(opts.Args.Path)
+	if err != nil {
+		return err
+	}
+
+	missing, err := c.findMissing(bundle)
+	if err != nil {
+		return err
+	}
+
+	c.ui.PrintTable(deploymentBundleTable(bundle, missing))
+
+	if len(missing) > 0 {
+		return bosherr.Errorf(
+			"Expected director to have %d missing release(s) and stemcell(s) uploaded before importing deployment '%s'",
+			len(missing), bundle.Name)
+	}
+
+	if opts.DryRun {
+		c.ui.PrintLinef("Director has all releases and stemcells required by deployment '%s'", bundle.Name)
+		return nil
+	}
+
+	// Cloud and runtime configs are global to the director
+	// so they are only included in the bundle for reference
+	if len(bundle.CloudConfig) > 0 || len(bundle.RuntimeConfig) > 0 {
+		c.ui.PrintLinef("Bundled cloud and runtime configs are not applied; deployment will use configs of this director")
+	}
+
+	err = c.ui.AskForConfirmation()
+	if err != nil {
+		return err
+	}
+
+	deployment, err := c.director.FindDeployment(bundle.Name)
+	if err != nil {
+		return err
+	}
+
+	return deployment.Update([]byte(bundle.Manifest), boshdir.UpdateOpts{})
+}
+
+// findMissing returns releases and stemcells that are not uploaded to the director
+// keyed the same way as in deploymentBundleTable
+func (c ImportDeploymentCmd) findMissing(bundle DeploymentBundle) (map[string]bool, error) {
+	missing := map[string]bool{}
+
+	for _, rel := range bundle.Releases {
+		found, err := c.director.HasRelease(rel.Name, rel.Version)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Checking release '%s/%s'", rel.Name, rel.Version)
+		}
+
+		if !found {
+			missing["release/"+rel.Name+"/"+rel.Version] = true
+		}
+	}
+
+	if len(bundle.Stemcells) == 0 {
+		return missing, nil
+	}
+
+	stemcells, err := c.director.Stemcells()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Checking stemcells")
+	}
+
+	for _, stem := range bundle.Stemcells {
+		if !c.hasStemcell(stemcells, stem) {
+			missing["stemcell/"+stem.Name+"/"+stem.Version] = true
+		}
+	}
+
+	return missing, nil
+}
+
+// hasStemcell matches stemcells by OS and version since manifests refer
+// to stemcells that way and stemcell names are specific to the IaaS
+func (c ImportDeploymentCmd) hasStemcell(stemcells []boshdir.Stemcell, stem DeploymentBundleStemcell) bool {
+	for _, s := range stemcells {
+		if s.Version().String() != stem.Version {
+			continue
+		}
+
+		if len(stem.OS) > 0 && s.OSName() == stem.OS {
+			return true
+		}
+
+		if len(stem.OS) == 0 && s.Name() == stem.Name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,240 @@
+package cmd_test
+
+import (
+	"errors"
+
+	semver "github.com/cppforlife/go-semi-semantic/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	fakecmd "github.com/cloudfoundry/bosh-cli/cmd/cmdfakes"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	fakedir "github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+var _ = Describe("ImportDeploymentCmd", func() {
+	var (
+		ui         *fakeui.FakeUI
+		director   *fakedir.FakeDirector
+		deployment *fakedir.FakeDeployment
+		archive    *fakecmd.FakeDeploymentBundleArchive
+		command    ImportDeploymentCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		director = &fakedir.FakeDirector{}
+		deployment = &fakedir.FakeDeployment{}
+		archive = &fakecmd.FakeDeploymentBundleArchive{}
+		command = NewImportDeploymentCmd(ui, director, archive)
+	})
+
+	Describe("Run", func() {
+		var (
+			opts ImportDeploymentOpts
+		)
+
+		BeforeEach(func() {
+			opts = ImportDeploymentOpts{
+				Args: DeploymentBundleArgs{Path: "/bundle.tgz"},
+			}
+
+			archive.ReadReturns(DeploymentBundle{
+				Name: "dep",
+
+				Releases:  []DeploymentBundleRelease{{Name: "rel", Version: "1.1"}},
+				Stemcells: []DeploymentBundleStemcell{{Name: "stem", Version: "3421.9", OS: "ubuntu-trusty"}},
+				Variables: []string{"/dir/dep/var"},
+
+				Manifest:      "name: dep\n",
+				CloudConfig:   "networks: []\n",
+				RuntimeConfig: "addons: []\n",
+			}, nil)
+
+			director.HasReleaseReturns(true, nil)
+			director.StemcellsReturns([]boshdir.Stemcell{
+				newImportStemcell("other-stem", "3421.9", "ubuntu-xenial"),
+				newImportStemcell("other-iaas-stem", "3421.9", "ubuntu-trusty"),
+			}, nil)
+			director.FindDeploymentReturns(deployment, nil)
+		})
+
+		act := func() error { return command.Run(opts) }
+
+		expectedTable := func(releaseMissing, stemcellMissing bool) boshtbl.Table {
+			return boshtbl.Table{
+				Content: "bundle contents",
+				Header:  []string{"Type", "Name", "Version", "Missing"},
+				SortBy: []boshtbl.ColumnSort{
+					{Column: 0, Asc: true},
+					{Column: 1, Asc: true},
+				},
+				Rows: [][]boshtbl.Value{
+					{
+						boshtbl.NewValueString("release"),
+						boshtbl.NewValueString("rel"),
+						boshtbl.NewValueString("1.1"),
+						boshtbl.NewValueFmt(boshtbl.NewValueBool(releaseMissing), releaseMissing),
+					},
+					{
+						boshtbl.NewValueString("stemcell"),
+						boshtbl.NewValueString("stem"),
+						boshtbl.NewValueString("3421.9"),
+						boshtbl.NewValueFmt(boshtbl.NewValueBool(stemcellMissing), stemcellMissing),
+					},
+					{
+						boshtbl.NewValueString("variable"),
+						boshtbl.NewValueString("/dir/dep/var"),
+						boshtbl.NewValueString(""),
+						boshtbl.NewValueString(""),
+					},
+				},
+			}
+		}
+
+		Context("when dry run is requested", func() {
+			BeforeEach(func() {
+				opts.DryRun = true
+			})
+
+			It("reports that nothing is missing without deploying", func() {
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(archive.ReadArgsForCall(0)).To(Equal("/bundle.tgz"))
+
+				name, version := director.HasReleaseArgsForCall(0)
+				Expect(name).To(Equal("rel"))
+				Expect(version).To(Equal("1.1"))
+
+				Expect(ui.Table).To(Equal(expectedTable(false, false)))
+				Expect(ui.Said).To(Equal([]string{"Director has all releases and stemcells required by deployment 'dep'"}))
+
+				Expect(ui.AskedConfirmationCalled).To(BeFalse())
+				Expect(deployment.UpdateCallCount()).To(Equal(0))
+			})
+
+			It("reports missing releases and stemcells", func() {
+				director.HasReleaseReturns(false, nil)
+				director.StemcellsReturns([]boshdir.Stemcell{
+					newImportStemcell("stem", "3421.10", "ubuntu-trusty"),
+					newImportStemcell("stem", "3421.9", "ubuntu-xenial"),
+				}, nil)
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(
+					"Expected director to have 2 missing release(s) and stemcell(s) uploaded before importing deployment 'dep'"))
+
+				Expect(ui.Table).To(Equal(expectedTable(true, true)))
+				Expect(deployment.UpdateCallCount()).To(Equal(0))
+			})
+
+			It("returns error if release cannot be checked", func() {
+				director.HasReleaseReturns(false, errors.New("fake-err"))
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Checking release 'rel/1.1'"))
+				Expect(err.Error()).To(ContainSubstring("fake-err"))
+			})
+
+			It("matches stemcells by name when bundle does not include OS", func() {
+				archive.ReadReturns(DeploymentBundle{
+					Name:      "dep",
+					Stemcells: []DeploymentBundleStemcell{{Name: "other-stem", Version: "3421.9"}},
+				}, nil)
+
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns error if stemcells cannot be checked", func() {
+				director.StemcellsReturns(nil, errors.New("fake-err"))
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Checking stemcells"))
+				Expect(err.Error()).To(ContainSubstring("fake-err"))
+			})
+		})
+
+		It("deploys bundled manifest without changing director configs", func() {
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.AskedConfirmationCalled).To(BeTrue())
+			Expect(ui.Said).To(Equal([]string{
+				"Bundled cloud and runtime configs are not applied; deployment will use configs of this director",
+			}))
+
+			Expect(director.UpdateCloudConfigCallCount()).To(Equal(0))
+			Expect(director.UpdateRuntimeConfigCallCount()).To(Equal(0))
+
+			Expect(director.FindDeploymentArgsForCall(0)).To(Equal("dep"))
+
+			Expect(deployment.UpdateCallCount()).To(Equal(1))
+
+			manifest, updateOpts := deployment.UpdateArgsForCall(0)
+			Expect(manifest).To(Equal([]byte("name: dep\n")))
+			Expect(updateOpts).To(Equal(boshdir.UpdateOpts{}))
+		})
+
+		It("does not mention configs that were not bundled", func() {
+			archive.ReadReturns(DeploymentBundle{Name: "dep", Manifest: "name: dep\n"}, nil)
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Said).To(BeEmpty())
+			Expect(deployment.UpdateCallCount()).To(Equal(1))
+		})
+
+		It("does not deploy if releases or stemcells are missing", func() {
+			director.StemcellsReturns(nil, nil)
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+
+			Expect(ui.AskedConfirmationCalled).To(BeFalse())
+			Expect(deployment.UpdateCallCount()).To(Equal(0))
+		})
+
+		It("does not deploy if confirmation is rejected", func() {
+			ui.AskedConfirmationErr = errors.New("stop")
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("stop"))
+
+			Expect(deployment.UpdateCallCount()).To(Equal(0))
+		})
+
+		It("returns error if bundle cannot be read", func() {
+			archive.ReadReturns(DeploymentBundle{}, errors.New("fake-err"))
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-err"))
+		})
+
+		It("returns error if deploying fails", func() {
+			deployment.UpdateReturns(errors.New("fake-err"))
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-err"))
+		})
+	})
+})
+
+func newImportStemcell(name, version, os string) *fakedir.FakeStemcell {
+	return &fakedir.FakeStemcell{
+		NameStub:    func() string { return name },
+		VersionStub: func() semver.Version { return semver.MustNewVersionFromString(version) },
+		OSNameStub:  func() string { return os },
+	}
+}
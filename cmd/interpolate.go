@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/cppforlife/go-patch/patch"
 
 	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
 	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
 )
 
 type InterpolateCmd struct {
@@ -19,6 +22,11 @@ func (c InterpolateCmd) Run(opts InterpolateOpts) error {
 	tpl := boshtpl.NewTemplate(opts.Args.Manifest.Bytes)
 
 	vars := opts.VarFlags.AsVariables()
+
+	if opts.Explain.IsSet() {
+		return c.explain(tpl, vars, opts)
+	}
+
 	op := opts.OpsFlags.AsOp()
 	evalOpts := boshtpl.EvaluateOpts{
 		ExpectAllKeys:     opts.VarErrors,
@@ -41,3 +49,60 @@ func (c InterpolateCmd) Run(opts InterpolateOpts) error {
 
 	return nil
 }
+
+func (c InterpolateCmd) explain(tpl boshtpl.Template, vars boshtpl.Variables, opts InterpolateOpts) error {
+	var explanation boshtpl.Explanation
+
+	evalOpts := boshtpl.EvaluateOpts{
+		ExpectAllKeys:     opts.VarErrors,
+		ExpectAllVarsUsed: opts.VarErrorsUnused,
+		Explanation:       &explanation,
+	}
+
+	_, err := tpl.Evaluate(vars, opts.OpsFlags.AsSourcedOps(), evalOpts)
+	if err != nil {
+		return err
+	}
+
+	// Rows within sections are already in order of application
+	table := boshtbl.Table{
+		Content: "paths",
+		Header:  []string{"Path", "Source", "Change"},
+	}
+
+	for _, path := range explanation.Find(opts.Explain) {
+		section := boshtbl.Section{
+			FirstColumn: boshtbl.NewValueString(path.Path),
+		}
+
+		if path.InTemplate {
+			section.Rows = append(section.Rows, []boshtbl.Value{
+				boshtbl.NewValueString(""),
+				boshtbl.NewValueString("template"),
+				boshtbl.NewValueString("defined"),
+			})
+		}
+
+		for _, change := range path.Changes {
+			section.Rows = append(section.Rows, []boshtbl.Value{
+				boshtbl.NewValueString(""),
+				boshtbl.NewValueString(fmt.Sprintf("%s [%d]", change.Source, change.Index)),
+				boshtbl.NewValueString(change.Action),
+			})
+		}
+
+		for _, name := range path.Variables {
+			section.Rows = append(section.Rows, []boshtbl.Value{
+				boshtbl.NewValueString(""),
+				boshtbl.NewValueString(fmt.Sprintf("((%s))", name)),
+				boshtbl.NewValueString("interpolated"),
+			})
+		}
+
+		table.Sections = append(table.Sections, section)
+	}
+
+	c.ui.PrintTable(table)
+
+	return nil
+}
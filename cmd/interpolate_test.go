@@ -8,6 +8,7 @@ import (
 	. "github.com/cloudfoundry/bosh-cli/cmd"
 	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
 	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
 )
 
 var _ = Describe("InterpolateCmd", func() {
@@ -148,5 +149,121 @@ var _ = Describe("InterpolateCmd", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("Expected to use variables: name3"))
 		})
+
+		Context("when explain path is given", func() {
+			BeforeEach(func() {
+				opts.Args.Manifest = FileBytesArg{
+					Bytes: []byte("instance_groups:\n- name: web\n  vm_type: small\n- name: db\n  vm_type: ((db_vm_type))\n"),
+				}
+
+				opts.OpsFiles = []OpsFileArg{
+					{
+						FilePath: "/ops1.yml",
+						Ops: patch.Ops([]patch.Op{
+							patch.ReplaceOp{Path: patch.MustNewPointerFromString("/instance_groups/name=web/vm_type"), Value: "medium"},
+						}),
+					},
+					{
+						FilePath: "/ops2.yml",
+						Ops: patch.Ops([]patch.Op{
+							patch.ReplaceOp{Path: patch.MustNewPointerFromString("/instance_groups/name=web/azs?"), Value: []interface{}{"z1"}},
+							patch.ReplaceOp{Path: patch.MustNewPointerFromString("/instance_groups/name=web/vm_type"), Value: "((web_vm_type))"},
+						}),
+					},
+				}
+			})
+
+			It("shows template, ops files and variables that produced value at path", func() {
+				opts.Explain = patch.MustNewPointerFromString("/instance_groups/name=web/vm_type")
+
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(ui.Blocks).To(BeEmpty())
+				Expect(ui.Table).To(Equal(boshtbl.Table{
+					Content: "paths",
+					Header:  []string{"Path", "Source", "Change"},
+					Sections: []boshtbl.Section{
+						{
+							FirstColumn: boshtbl.NewValueString("/instance_groups/name=web/vm_type"),
+							Rows: [][]boshtbl.Value{
+								{
+									boshtbl.NewValueString(""),
+									boshtbl.NewValueString("template"),
+									boshtbl.NewValueString("defined"),
+								},
+								{
+									boshtbl.NewValueString(""),
+									boshtbl.NewValueString("/ops1.yml [0]"),
+									boshtbl.NewValueString("changed"),
+								},
+								{
+									boshtbl.NewValueString(""),
+									boshtbl.NewValueString("/ops2.yml [1]"),
+									boshtbl.NewValueString("changed"),
+								},
+								{
+									boshtbl.NewValueString(""),
+									boshtbl.NewValueString("((web_vm_type))"),
+									boshtbl.NewValueString("interpolated"),
+								},
+							},
+						},
+					},
+				}))
+			})
+
+			It("shows all changed and interpolated paths if root path is given", func() {
+				opts.Explain = patch.MustNewPointerFromString("/")
+
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				var paths []string
+
+				for _, section := range ui.Table.Sections {
+					paths = append(paths, section.FirstColumn.String())
+				}
+
+				Expect(paths).To(Equal([]string{
+					"/instance_groups/name=db/vm_type",
+					"/instance_groups/name=web/azs/0",
+					"/instance_groups/name=web/vm_type",
+				}))
+
+				Expect(ui.Table.Sections[0].Rows).To(Equal([][]boshtbl.Value{
+					{
+						boshtbl.NewValueString(""),
+						boshtbl.NewValueString("template"),
+						boshtbl.NewValueString("defined"),
+					},
+					{
+						boshtbl.NewValueString(""),
+						boshtbl.NewValueString("((db_vm_type))"),
+						boshtbl.NewValueString("interpolated"),
+					},
+				}))
+
+				Expect(ui.Table.Sections[1].Rows).To(Equal([][]boshtbl.Value{
+					{
+						boshtbl.NewValueString(""),
+						boshtbl.NewValueString("/ops2.yml [0]"),
+						boshtbl.NewValueString("added"),
+					},
+				}))
+			})
+
+			It("returns error if ops cannot be applied", func() {
+				opts.OpsFiles[0].Ops = patch.Ops([]patch.Op{
+					patch.RemoveOp{Path: patch.MustNewPointerFromString("/unknown")},
+				})
+
+				opts.Explain = patch.MustNewPointerFromString("/")
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(ui.Tables).To(BeEmpty())
+			})
+		})
 	})
 })
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	bilint "github.com/cloudfoundry/bosh-cli/lint"
+	boshrel "github.com/cloudfoundry/bosh-cli/release"
+	boshreldir "github.com/cloudfoundry/bosh-cli/releasedir"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+type LintCmd struct {
+	releaseFactory func(string) (boshrel.Reader, boshreldir.ReleaseDir)
+	linter         bilint.Linter
+	ui             boshui.UI
+}
+
+func NewLintCmd(
+	releaseFactory func(string) (boshrel.Reader, boshreldir.ReleaseDir),
+	linter bilint.Linter,
+	ui boshui.UI,
+) LintCmd {
+	return LintCmd{releaseFactory: releaseFactory, linter: linter, ui: ui}
+}
+
+func (c LintCmd) Run(opts LintOpts) error {
+	tpl := boshtpl.NewTemplate(opts.Args.Manifest.Bytes)
+
+	bytes, err := tpl.Evaluate(opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp(), boshtpl.EvaluateOpts{})
+	if err != nil {
+		return bosherr.WrapError(err, "Evaluating manifest")
+	}
+
+	var releases []boshrel.Release
+
+	for _, path := range opts.Releases {
+		release, err := c.readRelease(path)
+		if err != nil {
+			return err
+		}
+
+		defer release.CleanUp()
+
+		releases = append(releases, release)
+	}
+
+	problems, err := c.linter.Lint(bytes, releases)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) == 0 {
+		c.ui.PrintLinef("No problems found")
+		return nil
+	}
+
+	table := boshtbl.Table{
+		Content: "problems",
+
+		Header: []string{"Path", "Level", "Problem"},
+	}
+
+	var numErrors int
+
+	for _, problem := range problems {
+		level := "warning"
+
+		if !problem.Warning {
+			level = "error"
+			numErrors++
+		}
+
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(problem.Path),
+			boshtbl.NewValueString(level),
+			boshtbl.NewValueFmt(boshtbl.NewValueString(problem.Message), !problem.Warning),
+		})
+	}
+
+	c.ui.PrintTable(table)
+
+	// Warnings alone do not fail linting
+	if numErrors > 0 {
+		return bosherr.Errorf("Found %d problem(s)", numErrors)
+	}
+
+	return nil
+}
+
+func (c LintCmd) readRelease(path string) (boshrel.Release, error) {
+	releaseReader, releaseDir := c.releaseFactory(path)
+
+	release, err := releaseReader.Read(path)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Reading release '%s'", path)
+	}
+
+	// Releases read from a directory are named by its config
+	if len(release.Name()) == 0 {
+		name, err := releaseDir.DefaultName()
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Reading release '%s'", path)
+		}
+
+		release.SetName(name)
+	}
+
+	return release, nil
+}
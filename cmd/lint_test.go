@@ -0,0 +1,166 @@
+package cmd_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	bilint "github.com/cloudfoundry/bosh-cli/lint"
+	boshrel "github.com/cloudfoundry/bosh-cli/release"
+	boshjob "github.com/cloudfoundry/bosh-cli/release/job"
+	fakerel "github.com/cloudfoundry/bosh-cli/release/releasefakes"
+	. "github.com/cloudfoundry/bosh-cli/release/resource"
+	boshreldir "github.com/cloudfoundry/bosh-cli/releasedir"
+	fakereldir "github.com/cloudfoundry/bosh-cli/releasedir/releasedirfakes"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+var _ = Describe("LintCmd", func() {
+	var (
+		releaseReader *fakerel.FakeReader
+		release       *fakerel.FakeRelease
+		releaseDir    *fakereldir.FakeReleaseDir
+		readerPaths   []string
+		ui            *fakeui.FakeUI
+		command       LintCmd
+	)
+
+	BeforeEach(func() {
+		job := boshjob.NewJob(NewResource("web", "fp", nil))
+		job.Properties = map[string]boshjob.PropertyDefinition{"port": {}}
+
+		release = &fakerel.FakeRelease{}
+		release.NameReturns("app")
+		release.FindJobByNameStub = func(name string) (boshjob.Job, bool) {
+			return *job, name == "web"
+		}
+
+		releaseReader = &fakerel.FakeReader{}
+		releaseReader.ReadReturns(release, nil)
+
+		releaseDir = &fakereldir.FakeReleaseDir{}
+
+		readerPaths = nil
+
+		releaseFactory := func(path string) (boshrel.Reader, boshreldir.ReleaseDir) {
+			readerPaths = append(readerPaths, path)
+			return releaseReader, releaseDir
+		}
+
+		ui = &fakeui.FakeUI{}
+		command = NewLintCmd(releaseFactory, bilint.NewLinter(), ui)
+	})
+
+	lintOpts := func(manifest string) LintOpts {
+		return LintOpts{
+			Args:     LintArgs{Manifest: FileBytesArg{Bytes: []byte(manifest)}},
+			Releases: []string{"/release.tgz"},
+		}
+	}
+
+	It("reports that no problems were found", func() {
+		err := command.Run(lintOpts(`
+instance_groups:
+- name: web
+  jobs:
+  - {name: web, release: app, properties: {port: 80}}
+`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(readerPaths).To(Equal([]string{"/release.tgz"}))
+		Expect(releaseReader.ReadArgsForCall(0)).To(Equal("/release.tgz"))
+		Expect(ui.Said).To(Equal([]string{"No problems found"}))
+
+		Expect(release.CleanUpCallCount()).To(Equal(1))
+	})
+
+	It("interpolates variables before linting", func() {
+		opts := lintOpts(`
+instance_groups:
+- name: web
+  jobs:
+  - {name: ((job)), release: app, properties: {port: 80}}
+`)
+		opts.VarKVs = []boshtpl.VarKV{{Name: "job", Value: "web"}}
+
+		err := command.Run(opts)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("prints problems and returns an error counting problems that are not warnings", func() {
+		err := command.Run(lintOpts(`
+instance_groups:
+- name: web
+  jobs:
+  - {name: web, release: app, properties: {prot: 80}}
+`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Found 1 problem(s)"))
+
+		Expect(ui.Table).To(Equal(boshtbl.Table{
+			Content: "problems",
+
+			Header: []string{"Path", "Level", "Problem"},
+
+			Rows: [][]boshtbl.Value{
+				{
+					boshtbl.NewValueString("/instance_groups/name=web/jobs/name=web/properties/prot"),
+					boshtbl.NewValueString("error"),
+					boshtbl.NewValueFmt(boshtbl.NewValueString("Unknown property 'prot' for job 'web'"), true),
+				},
+				{
+					boshtbl.NewValueString("/instance_groups/name=web/jobs/name=web/properties"),
+					boshtbl.NewValueString("warning"),
+					boshtbl.NewValueFmt(boshtbl.NewValueString("Missing property 'port' without default value in job 'web'"), false),
+				},
+			},
+		}))
+	})
+
+	It("prints warnings without returning an error", func() {
+		err := command.Run(lintOpts(`
+instance_groups:
+- name: web
+  jobs:
+  - {name: web, release: app}
+`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ui.Table).To(Equal(boshtbl.Table{
+			Content: "problems",
+
+			Header: []string{"Path", "Level", "Problem"},
+
+			Rows: [][]boshtbl.Value{
+				{
+					boshtbl.NewValueString("/instance_groups/name=web/jobs/name=web/properties"),
+					boshtbl.NewValueString("warning"),
+					boshtbl.NewValueFmt(boshtbl.NewValueString("Missing property 'port' without default value in job 'web'"), false),
+				},
+			},
+		}))
+	})
+
+	It("names releases read from release directory by its config", func() {
+		release.NameReturns("")
+		releaseDir.DefaultNameReturns("app", nil)
+
+		err := command.Run(lintOpts("{}"))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(release.SetNameArgsForCall(0)).To(Equal("app"))
+	})
+
+	It("returns an error when reading release fails", func() {
+		releaseReader.ReadReturns(nil, errors.New("fake-err"))
+
+		err := command.Run(lintOpts("{}"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Reading release '/release.tgz'"))
+		Expect(err.Error()).To(ContainSubstring("fake-err"))
+	})
+})
@@ -15,27 +15,38 @@ type LoginStrategy interface {
 type LogInCmd struct {
 	basicStrategy LoginStrategy
 	uaaStrategy   LoginStrategy
+	ssoStrategy   LoginStrategy
 	director      boshdir.Director
 }
 
 func NewLogInCmd(
 	basicStrategy LoginStrategy,
 	uaaStrategy LoginStrategy,
+	ssoStrategy LoginStrategy,
 	director boshdir.Director,
 ) LogInCmd {
 	return LogInCmd{
 		basicStrategy: basicStrategy,
 		uaaStrategy:   uaaStrategy,
+		ssoStrategy:   ssoStrategy,
 		director:      director,
 	}
 }
 
-func (c LogInCmd) Run() error {
+func (c LogInCmd) Run(opts LogInOpts) error {
 	info, err := c.director.Info()
 	if err != nil {
 		return err
 	}
 
+	if opts.SSO || opts.Browser {
+		if info.Auth.Type != "uaa" {
+			return bosherr.Errorf("Expected director to use UAA auth for single sign-on but found '%s'", info.Auth.Type)
+		}
+
+		return c.ssoStrategy.Try()
+	}
+
 	switch info.Auth.Type {
 	case "uaa":
 		return c.uaaStrategy.Try()
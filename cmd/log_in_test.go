@@ -16,6 +16,7 @@ var _ = Describe("LogInCmd", func() {
 	var (
 		basic    *fakecmd.FakeLoginStrategy
 		uaa      *fakecmd.FakeLoginStrategy
+		sso      *fakecmd.FakeLoginStrategy
 		director *fakedir.FakeDirector
 		command  LogInCmd
 	)
@@ -23,12 +24,21 @@ var _ = Describe("LogInCmd", func() {
 	BeforeEach(func() {
 		basic = &fakecmd.FakeLoginStrategy{}
 		uaa = &fakecmd.FakeLoginStrategy{}
+		sso = &fakecmd.FakeLoginStrategy{}
 		director = &fakedir.FakeDirector{}
-		command = NewLogInCmd(basic, uaa, director)
+		command = NewLogInCmd(basic, uaa, sso, director)
 	})
 
 	Describe("Run", func() {
-		act := func() error { return command.Run() }
+		var (
+			opts LogInOpts
+		)
+
+		BeforeEach(func() {
+			opts = LogInOpts{}
+		})
+
+		act := func() error { return command.Run(opts) }
 
 		Context("when director uses basic auth", func() {
 			BeforeEach(func() {
@@ -41,6 +51,13 @@ var _ = Describe("LogInCmd", func() {
 				basic.TryReturns(errors.New("fake-err"))
 				Expect(act()).To(Equal(errors.New("fake-err")))
 			})
+
+			It("returns an error if single sign-on is requested", func() {
+				opts.SSO = true
+				Expect(act()).To(Equal(errors.New(
+					"Expected director to use UAA auth for single sign-on but found 'basic'")))
+				Expect(sso.TryCallCount()).To(Equal(0))
+			})
 		})
 
 		Context("when director uses uaa auth", func() {
@@ -54,6 +71,19 @@ var _ = Describe("LogInCmd", func() {
 				uaa.TryReturns(errors.New("fake-err"))
 				Expect(act()).To(Equal(errors.New("fake-err")))
 			})
+
+			It("uses sso login strategy when single sign-on is requested", func() {
+				opts.SSO = true
+				sso.TryReturns(errors.New("fake-err"))
+				Expect(act()).To(Equal(errors.New("fake-err")))
+				Expect(uaa.TryCallCount()).To(Equal(0))
+			})
+
+			It("uses sso login strategy when browser log in is requested", func() {
+				opts.Browser = true
+				sso.TryReturns(errors.New("fake-err"))
+				Expect(act()).To(Equal(errors.New("fake-err")))
+			})
 		})
 
 		Context("when director uses unknown auth", func() {
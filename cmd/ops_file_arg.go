@@ -10,7 +10,8 @@ import (
 type OpsFileArg struct {
 	FS boshsys.FileSystem
 
-	Ops patch.Ops
+	FilePath string
+	Ops      patch.Ops
 }
 
 func (a *OpsFileArg) UnmarshalFlag(filePath string) error {
@@ -35,6 +36,7 @@ func (a *OpsFileArg) UnmarshalFlag(filePath string) error {
 		return bosherr.WrapErrorf(err, "Building ops")
 	}
 
+	(*a).FilePath = filePath
 	(*a).Ops = ops
 
 	return nil
@@ -23,7 +23,7 @@ var _ = Describe("OpsFileArg", func() {
 			arg = OpsFileArg{FS: fs}
 		})
 
-		It("sets read operations", func() {
+		It("sets read operations and file path", func() {
 			fs.WriteFileString("/some/path", `
 - type: remove
   path: /a
@@ -38,6 +38,8 @@ var _ = Describe("OpsFileArg", func() {
 				patch.RemoveOp{Path: patch.MustNewPointerFromString("/a")},
 				patch.RemoveOp{Path: patch.MustNewPointerFromString("/b")},
 			}))
+
+			Expect(arg.FilePath).To(Equal("/some/path"))
 		})
 
 		It("returns an error if operations are not valid", func() {
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"github.com/cppforlife/go-patch/patch"
+
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
 )
 
 // Shared
@@ -18,3 +20,16 @@ func (f OpsFlags) AsOp() patch.Op {
 
 	return ops
 }
+
+// AsSourcedOps is similar to AsOp but keeps track of ops file each op came from
+func (f OpsFlags) AsSourcedOps() boshtpl.SourcedOps {
+	var ops boshtpl.SourcedOps
+
+	for _, opsFile := range f.OpsFiles {
+		for i, op := range opsFile.Ops {
+			ops = append(ops, boshtpl.SourcedOp{Op: op, Source: opsFile.FilePath, Index: i})
+		}
+	}
+
+	return ops
+}
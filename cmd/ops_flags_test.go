@@ -6,6 +6,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	. "github.com/cloudfoundry/bosh-cli/cmd"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
 )
 
 var _ = Describe("OpsFlags", func() {
@@ -34,4 +35,32 @@ var _ = Describe("OpsFlags", func() {
 			}))
 		})
 	})
+
+	Describe("AsSourcedOps", func() {
+		It("merges all ops into one in given order keeping track of ops files", func() {
+			flags := OpsFlags{
+				OpsFiles: []OpsFileArg{
+					{
+						FilePath: "/ops1.yml",
+						Ops: patch.Ops([]patch.Op{
+							patch.RemoveOp{Path: patch.MustNewPointerFromString("/a")},
+							patch.RemoveOp{Path: patch.MustNewPointerFromString("/b")},
+						}),
+					},
+					{
+						FilePath: "/ops2.yml",
+						Ops: patch.Ops([]patch.Op{
+							patch.RemoveOp{Path: patch.MustNewPointerFromString("/x")},
+						}),
+					},
+				},
+			}
+
+			Expect(flags.AsSourcedOps()).To(Equal(boshtpl.SourcedOps{
+				{Op: patch.RemoveOp{Path: patch.MustNewPointerFromString("/a")}, Source: "/ops1.yml", Index: 0},
+				{Op: patch.RemoveOp{Path: patch.MustNewPointerFromString("/b")}, Source: "/ops1.yml", Index: 1},
+				{Op: patch.RemoveOp{Path: patch.MustNewPointerFromString("/x")}, Source: "/ops2.yml", Index: 0},
+			}))
+		})
+	})
 })
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/cppforlife/go-patch/patch"
 
 	boshdir "github.com/cloudfoundry/bosh-cli/director"
@@ -17,6 +19,9 @@ type BoshOpts struct {
 	EnvironmentOpt string    `long:"environment" short:"e" description:"Director environment name or URL" env:"BOSH_ENVIRONMENT"`
 	CACertOpt      CACertArg `long:"ca-cert"               description:"Director CA certificate path or value" env:"BOSH_CA_CERT"`
 
+	// Read commands query every environment in a group configured in config file
+	EnvironmentsOpt string `long:"environments" value-name:"GROUP" description:"Environment group name to query with read commands"`
+
 	// Specify basic credentaials
 	UsernameOpt string `long:"user"     description:"Override username" env:"BOSH_USER"`
 	PasswordOpt string `long:"password" description:"Override password" env:"BOSH_PASSWORD"`
@@ -44,6 +49,11 @@ type BoshOpts struct {
 	DeleteEnv    DeleteEnvOpts    `command:"delete-env"                description:"Delete BOSH environment"`
 	AliasEnv     AliasEnvOpts     `command:"alias-env"                 description:"Alias environment to save URL and CA certificate"`
 
+	ExportCompiledPackageCache ExportCompiledPackageCacheOpts `command:"export-compiled-package-cache" description:"Export packages compiled by create-env into a tarball"`
+	ImportCompiledPackageCache ImportCompiledPackageCacheOpts `command:"import-compiled-package-cache" description:"Import packages compiled by create-env from a tarball"`
+
+	Cache CacheOpts `command:"cache" description:"Manage release and stemcell tarballs downloaded by create-env"`
+
 	// Authentication
 	LogIn  LogInOpts  `command:"log-in"  alias:"l" alias:"login"  description:"Log in"`
 	LogOut LogOutOpts `command:"log-out"           alias:"logout" description:"Log out"`
@@ -52,6 +62,7 @@ type BoshOpts struct {
 	Task       TaskOpts       `command:"task"        alias:"t"  description:"Show task status and start tracking its output"`
 	Tasks      TasksOpts      `command:"tasks"       alias:"ts" description:"List running or recent tasks"`
 	CancelTask CancelTaskOpts `command:"cancel-task" alias:"ct" description:"Cancel task at its next checkpoint"`
+	TaskReport TaskReportOpts `command:"task-report"            description:"Show timeline of stages and steps performed by task"`
 
 	// Misc
 	Locks   LocksOpts   `command:"locks"    description:"List current locks"`
@@ -81,7 +92,12 @@ type BoshOpts struct {
 	Deploy   DeployOpts   `command:"deploy"   alias:"d"                                       description:"Deploy according to the currently selected deployment manifest"`
 	Manifest ManifestOpts `command:"manifest" alias:"m" alias:"man" alias:"download-manifest" description:"Download deployment manifest locally"`
 
+	ExportDeployment ExportDeploymentOpts `command:"export-deployment" description:"Export deployment manifest, configs, releases and stemcells to a bundle tarball (manifest may include values of CLI interpolated variables)"`
+	ImportDeployment ImportDeploymentOpts `command:"import-deployment" description:"Deploy manifest from a bundle tarball created by export-deployment"`
+
 	Interpolate InterpolateOpts `command:"interpolate" alias:"int" description:"Interpolates variables into a manifest"`
+	VarsStore   VarsStoreOpts   `command:"vars-store"                description:"Manage vars store files"`
+	Lint        LintOpts        `command:"lint"                      description:"Check deployment manifest against job specs of releases"`
 
 	// Events
 	Events EventsOpts `command:"events" description:"List events"`
@@ -141,6 +157,7 @@ type BoshOpts struct {
 	GeneratePackage GeneratePackageOpts `command:"generate-package"              description:"Generate package"`
 	CreateRelease   CreateReleaseOpts   `command:"create-release"   alias:"cr"   description:"Create release"`
 	FinalizeRelease FinalizeReleaseOpts `command:"finalize-release" alias:"finr" description:"Create final release from dev release tarball"`
+	RenderJob       RenderJobOpts       `command:"render-job"                    description:"Render job templates with given properties"`
 
 	// Blob management
 	Blobs       BlobsOpts       `command:"blobs"        description:"List blobs"`
@@ -161,7 +178,13 @@ type CreateEnvOpts struct {
 	Args CreateEnvArgs `positional-args:"true" required:"true"`
 	VarFlags
 	OpsFlags
-	StatePath string `long:"state" value-name:"PATH" description:"State file path"`
+	CPICallsFlags
+	StatePath   string `long:"state"        value-name:"PATH" description:"State file path or URL (http://, https:// or s3://)"`
+	ForceUnlock bool   `long:"force-unlock"                   description:"Remove lock held on remote state before proceeding"`
+	DryRun      bool   `long:"dry-run"                        description:"Show CPI actions that would be performed without performing them"`
+	TimingsPath string `long:"timings"      value-name:"PATH" description:"Write timings of performed stages into a JSON file"`
+
+	CompilationWorkers int `long:"compilation-workers" value-name:"NUMBER" description:"Number of CPI release packages compiled concurrently on the local machine" default:"1"`
 	cmd
 }
 
@@ -173,7 +196,10 @@ type DeleteEnvOpts struct {
 	Args DeleteEnvArgs `positional-args:"true" required:"true"`
 	VarFlags
 	OpsFlags
-	StatePath string `long:"state" value-name:"PATH" description:"State file path"`
+	CPICallsFlags
+	StatePath   string `long:"state"        value-name:"PATH" description:"State file path or URL (http://, https:// or s3://)"`
+	ForceUnlock bool   `long:"force-unlock"                   description:"Remove lock held on remote state before proceeding"`
+	TimingsPath string `long:"timings"      value-name:"PATH" description:"Write timings of performed stages into a JSON file"`
 	cmd
 }
 
@@ -181,6 +207,42 @@ type DeleteEnvArgs struct {
 	Manifest FileBytesWithPathArg `positional-arg-name:"PATH" description:"Path to a manifest file"`
 }
 
+type ExportCompiledPackageCacheOpts struct {
+	Args      CompiledPackageCacheArgs `positional-args:"true" required:"true"`
+	StatePath string                   `long:"state" value-name:"PATH" description:"State file path" required:"true"`
+	cmd
+}
+
+type ImportCompiledPackageCacheOpts struct {
+	Args      CompiledPackageCacheArgs `positional-args:"true" required:"true"`
+	StatePath string                   `long:"state" value-name:"PATH" description:"State file path" required:"true"`
+	cmd
+}
+
+type CompiledPackageCacheArgs struct {
+	Path string `positional-arg-name:"PATH" description:"Path to a compiled packages tarball"`
+}
+
+type CacheOpts struct {
+	List   CacheListOpts   `command:"list"   alias:"ls" description:"List cached tarballs"`
+	Prune  CachePruneOpts  `command:"prune"             description:"Delete least recently used cached tarballs"`
+	Verify CacheVerifyOpts `command:"verify"            description:"Verify SHA1 of cached tarballs"`
+}
+
+type CacheListOpts struct {
+	cmd
+}
+
+type CachePruneOpts struct {
+	OlderThan time.Duration `long:"older-than" value-name:"DURATION" description:"Delete tarballs not used within duration (e.g. 720h)"`
+	MaxSize   ByteSizeArg   `long:"max-size"   value-name:"SIZE"     description:"Delete tarballs until cache fits into size (e.g. 10GB)"`
+	cmd
+}
+
+type CacheVerifyOpts struct {
+	cmd
+}
+
 // Environment
 type EnvironmentOpts struct {
 	cmd
@@ -204,6 +266,9 @@ type AliasEnvArgs struct {
 }
 
 type LogInOpts struct {
+	SSO     bool `long:"sso"     description:"Log in via UAA single sign-on with one-time passcode"`
+	Browser bool `long:"browser" description:"Log in via UAA single sign-on in a browser (implies --sso)"`
+
 	cmd
 }
 
@@ -242,6 +307,15 @@ type CancelTaskOpts struct {
 	cmd
 }
 
+type TaskReportOpts struct {
+	Args TaskArgs `positional-args:"true" required:"true"`
+
+	Format  string `long:"format"  value-name:"FORMAT" description:"Report format: text, json or csv (default: text)"`
+	Slowest int    `long:"slowest" value-name:"NUMBER" description:"Number of slowest instances to show (default: 10)"`
+
+	cmd
+}
+
 // Misc
 type LocksOpts struct {
 	cmd
@@ -285,6 +359,7 @@ type InterpolateOpts struct {
 	Path            patch.Pointer `long:"path" value-name:"OP-PATH" description:"Extract value out of template (e.g.: /private_key)"`
 	VarErrors       bool          `long:"var-errs"                  description:"Expect all variables to be found, otherwise error"`
 	VarErrorsUnused bool          `long:"var-errs-unused"           description:"Expect all variables to be used, otherwise error"`
+	Explain         patch.Pointer `long:"explain" value-name:"OP-PATH" description:"Show ops files and variables that produced values under path instead of template (use '/' for all paths)"`
 
 	cmd
 }
@@ -293,6 +368,68 @@ type InterpolateArgs struct {
 	Manifest FileBytesArg `positional-arg-name:"PATH" description:"Path to a template that will be interpolated"`
 }
 
+type LintOpts struct {
+	Args LintArgs `positional-args:"true" required:"true"`
+
+	VarFlags
+	OpsFlags
+
+	Releases []string `long:"release" value-name:"PATH" description:"Path to a release tarball or directory (multiple release paths may be specified)"`
+
+	cmd
+}
+
+type LintArgs struct {
+	Manifest FileBytesArg `positional-arg-name:"PATH" description:"Path to a manifest file"`
+}
+
+type VarsStoreOpts struct {
+	Rekey       VarsStoreRekeyOpts       `command:"rekey"        description:"Re-encrypt vars store file with a new key"`
+	Certs       VarsStoreCertsOpts       `command:"certs"        description:"List certificates in a vars store with their expiry"`
+	RotateCerts VarsStoreRotateCertsOpts `command:"rotate-certs" description:"Regenerate certificates in a vars store based on manifest variable definitions"`
+}
+
+type VarsStoreRekeyOpts struct {
+	Args VarsStoreRekeyArgs `positional-args:"true" required:"true"`
+
+	Key     VarsStoreKeyArg `long:"key"     value-name:"file:PATH|KEY" description:"Current key from a file (file:PATH) or a value" env:"BOSH_VARS_STORE_KEY"`
+	NewKey  VarsStoreKeyArg `long:"new-key" value-name:"file:PATH|KEY" description:"New key from a file (file:PATH) or a value"     env:"BOSH_VARS_STORE_NEW_KEY"`
+	Decrypt bool            `long:"decrypt"                            description:"Store file unencrypted"`
+
+	cmd
+}
+
+type VarsStoreRekeyArgs struct {
+	VarsStore VarsFSStore `positional-arg-name:"PATH" description:"Path to a vars store file"`
+}
+
+type VarsStoreCertsOpts struct {
+	Args VarsStoreCertsArgs `positional-args:"true" required:"true"`
+
+	Key VarsStoreKeyArg `long:"key" value-name:"file:PATH|KEY" description:"Key to decrypt vars store file (file:PATH or a value)" env:"BOSH_VARS_STORE_KEY"`
+
+	cmd
+}
+
+type VarsStoreCertsArgs struct {
+	VarsStore VarsFSStore `positional-arg-name:"PATH" description:"Path to a vars store file or a credential store URL"`
+}
+
+type VarsStoreRotateCertsOpts struct {
+	Args VarsStoreRotateCertsArgs `positional-args:"true" required:"true"`
+
+	VarFlags
+	OpsFlags
+
+	Certs []string `long:"cert" value-name:"NAME" description:"Certificate variable to regenerate; certificates signed by a CA are regenerated with it" required:"true"`
+
+	cmd
+}
+
+type VarsStoreRotateCertsArgs struct {
+	Manifest FileBytesArg `positional-arg-name:"PATH" description:"Path to a manifest with variable definitions"`
+}
+
 // Cloud config
 type CloudConfigOpts struct {
 	cmd
@@ -364,7 +501,7 @@ type DeployOpts struct {
 	Canaries    string `long:"canaries" description:"Override manifest values for canaries"`
 	MaxInFlight string `long:"max-in-flight" description:"Override manifest values for max_in_flight"`
 
-	DryRun bool `long:"dry-run" description:"Renders job templates without altering deployment"`
+	DryRun bool `long:"dry-run" description:"Renders job templates without altering deployment; with --json only prints deployment plan"`
 
 	cmd
 }
@@ -377,6 +514,21 @@ type ManifestOpts struct {
 	cmd
 }
 
+type ExportDeploymentOpts struct {
+	Args DeploymentBundleArgs `positional-args:"true" required:"true"`
+	cmd
+}
+
+type ImportDeploymentOpts struct {
+	Args   DeploymentBundleArgs `positional-args:"true" required:"true"`
+	DryRun bool                 `long:"dry-run" description:"Only report releases and stemcells missing on the director"`
+	cmd
+}
+
+type DeploymentBundleArgs struct {
+	Path string `positional-arg-name:"PATH" description:"Path to a deployment bundle tarball"`
+}
+
 type DeleteDeploymentOpts struct {
 	Force bool `long:"force" description:"Ignore errors"`
 	cmd
@@ -395,6 +547,9 @@ type EventsOpts struct {
 	ObjectType string `long:"object-type"  description:"Show events with given object type"`
 	ObjectName string `long:"object-id"    description:"Show events with given object ID"`
 
+	Follow bool `long:"follow" description:"Continuously show new events as they happen"`
+	NDJSON bool `long:"ndjson" description:"Show events as newline-delimited JSON objects"`
+
 	cmd
 }
 
@@ -411,7 +566,7 @@ type UploadStemcellOpts struct {
 	Name    string     `long:"name"     description:"Name used in existence check (is not used with local stemcell file)"`
 	Version VersionArg `long:"version"  description:"Version used in existence check (is not used with local stemcell file)"`
 
-	SHA1 string `long:"sha1" description:"SHA1 of the remote stemcell (is not used with local files)"`
+	SHA1 string `long:"sha1" description:"SHA1 of the stemcell (local files are verified before uploading)"`
 
 	cmd
 }
@@ -449,7 +604,7 @@ type UploadReleaseOpts struct {
 	Name    string     `long:"name"     description:"Name used in existence check (is not used with local release file)"`
 	Version VersionArg `long:"version"  description:"Version used in existence check (is not used with local release file)"`
 
-	SHA1 string `long:"sha1" description:"SHA1 of the remote release (is not used with local files)"`
+	SHA1 string `long:"sha1" description:"SHA1 of the release (local files are verified before uploading)"`
 
 	Release boshrel.Release
 
@@ -622,6 +777,8 @@ type LogsOpts struct {
 	Filters []string `long:"only"  description:"Filter logs (comma-separated)"`
 	Agent   bool     `long:"agent" description:"Include only agent logs"`
 
+	NativeSSH bool `long:"native-ssh" description:"Use built-in SSH client instead of ssh and scp executables" env:"BOSH_NATIVE_SSH"`
+
 	GatewayFlags
 
 	cmd
@@ -693,6 +850,8 @@ type SSHOpts struct {
 
 	Results bool `long:"results" short:"r" description:"Collect results into a table instead of streaming"`
 
+	NativeSSH bool `long:"native-ssh" description:"Use built-in SSH client instead of ssh and scp executables" env:"BOSH_NATIVE_SSH"`
+
 	GatewayFlags
 
 	cmd
@@ -703,6 +862,8 @@ type SCPOpts struct {
 
 	Recursive bool `long:"recursive" short:"r" description:"Recursively copy entire directories. Note that symbolic links encountered are followed in the tree traversal"`
 
+	NativeSSH bool `long:"native-ssh" description:"Use built-in SSH client instead of ssh and scp executables" env:"BOSH_NATIVE_SSH"`
+
 	GatewayFlags
 
 	cmd
@@ -796,6 +957,23 @@ type FinalizeReleaseArgs struct {
 	Path string `positional-arg-name:"PATH"`
 }
 
+type RenderJobOpts struct {
+	Args RenderJobArgs `positional-args:"true" required:"true"`
+
+	Properties   FileBytesArg `long:"properties"    value-name:"PATH" description:"Path to a YAML file with job properties"`
+	Links        FileBytesArg `long:"links"         value-name:"PATH" description:"Path to a YAML file with links consumed by job"`
+	InstanceSpec FileBytesArg `long:"instance-spec" value-name:"PATH" description:"Path to a YAML file with instance spec (deployment, name, id, index, az, bootstrap, address, networks)"`
+
+	Output string `long:"output" value-name:"DIR" description:"Directory to write rendered files into" required:"true"`
+
+	cmd
+}
+
+type RenderJobArgs struct {
+	Release string `positional-arg-name:"RELEASE-PATH" description:"Path to a release tarball or directory"`
+	Job     string `positional-arg-name:"JOB"          description:"Job name"`
+}
+
 // Blobs
 type BlobsOpts struct {
 	Directory DirOrCWDArg `long:"dir" description:"Release directory path if not current working directory" default:"."`
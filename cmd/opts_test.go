@@ -120,6 +120,14 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("EnvironmentsOpt", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("EnvironmentsOpt", opts)).To(Equal(
+					`long:"environments" value-name:"GROUP" description:"Environment group name to query with read commands"`,
+				))
+			})
+		})
+
 		Describe("UsernameOpt", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("UsernameOpt", opts)).To(Equal(
@@ -208,6 +216,30 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("ExportCompiledPackageCache", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("ExportCompiledPackageCache", opts)).To(Equal(
+					`command:"export-compiled-package-cache" description:"Export packages compiled by create-env into a tarball"`,
+				))
+			})
+		})
+
+		Describe("ImportCompiledPackageCache", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("ImportCompiledPackageCache", opts)).To(Equal(
+					`command:"import-compiled-package-cache" description:"Import packages compiled by create-env from a tarball"`,
+				))
+			})
+		})
+
+		Describe("Cache", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Cache", opts)).To(Equal(
+					`command:"cache" description:"Manage release and stemcell tarballs downloaded by create-env"`,
+				))
+			})
+		})
+
 		Describe("Environment", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("Environment", opts)).To(Equal(
@@ -264,6 +296,14 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("TaskReport", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("TaskReport", opts)).To(Equal(
+					`command:"task-report" description:"Show timeline of stages and steps performed by task"`,
+				))
+			})
+		})
+
 		Describe("Locks", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("Locks", opts)).To(Equal(
@@ -296,6 +336,22 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("Lint", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Lint", opts)).To(Equal(
+					`command:"lint" description:"Check deployment manifest against job specs of releases"`,
+				))
+			})
+		})
+
+		Describe("VarsStore", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("VarsStore", opts)).To(Equal(
+					`command:"vars-store" description:"Manage vars store files"`,
+				))
+			})
+		})
+
 		Describe("CloudConfig", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("CloudConfig", opts)).To(Equal(
@@ -360,6 +416,22 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("ExportDeployment", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("ExportDeployment", opts)).To(Equal(
+					`command:"export-deployment" description:"Export deployment manifest, configs, releases and stemcells to a bundle tarball (manifest may include values of CLI interpolated variables)"`,
+				))
+			})
+		})
+
+		Describe("ImportDeployment", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("ImportDeployment", opts)).To(Equal(
+					`command:"import-deployment" description:"Deploy manifest from a bundle tarball created by export-deployment"`,
+				))
+			})
+		})
+
 		Describe("DeleteDeployment", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("DeleteDeployment", opts)).To(Equal(
@@ -672,6 +744,14 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("RenderJob", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("RenderJob", opts)).To(Equal(
+					`command:"render-job" description:"Render job templates with given properties"`,
+				))
+			})
+		})
+
 		Describe("Blobs", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("Blobs", opts)).To(Equal(
@@ -721,6 +801,28 @@ var _ = Describe("Opts", func() {
 		})
 	})
 
+	Describe("CPICallsFlags", func() {
+		var (
+			opts *CPICallsFlags
+		)
+
+		BeforeEach(func() {
+			opts = &CPICallsFlags{}
+		})
+
+		It("has --record-cpi-calls", func() {
+			Expect(getStructTagForName("RecordCPICalls", opts)).To(Equal(
+				`long:"record-cpi-calls" value-name:"PATH" description:"Record CPI calls with redacted credentials into a JSONL file"`,
+			))
+		})
+
+		It("has --replay-cpi-calls", func() {
+			Expect(getStructTagForName("ReplayCPICalls", opts)).To(Equal(
+				`long:"replay-cpi-calls" value-name:"PATH" description:"Answer CPI calls from a file recorded with --record-cpi-calls instead of running the CPI; calls must be made in recorded order"`,
+			))
+		})
+	})
+
 	Describe("CreateEnvOpts", func() {
 		var opts *CreateEnvOpts
 
@@ -736,7 +838,31 @@ var _ = Describe("Opts", func() {
 
 		It("has --state", func() {
 			Expect(getStructTagForName("StatePath", opts)).To(Equal(
-				`long:"state" value-name:"PATH" description:"State file path"`,
+				`long:"state" value-name:"PATH" description:"State file path or URL (http://, https:// or s3://)"`,
+			))
+		})
+
+		It("has --force-unlock", func() {
+			Expect(getStructTagForName("ForceUnlock", opts)).To(Equal(
+				`long:"force-unlock" description:"Remove lock held on remote state before proceeding"`,
+			))
+		})
+
+		It("has --timings", func() {
+			Expect(getStructTagForName("TimingsPath", opts)).To(Equal(
+				`long:"timings" value-name:"PATH" description:"Write timings of performed stages into a JSON file"`,
+			))
+		})
+
+		It("has --dry-run", func() {
+			Expect(getStructTagForName("DryRun", opts)).To(Equal(
+				`long:"dry-run" description:"Show CPI actions that would be performed without performing them"`,
+			))
+		})
+
+		It("has --compilation-workers", func() {
+			Expect(getStructTagForName("CompilationWorkers", opts)).To(Equal(
+				`long:"compilation-workers" value-name:"NUMBER" description:"Number of CPI release packages compiled concurrently on the local machine" default:"1"`,
 			))
 		})
 	})
@@ -772,7 +898,19 @@ var _ = Describe("Opts", func() {
 
 		It("has --state", func() {
 			Expect(getStructTagForName("StatePath", opts)).To(Equal(
-				`long:"state" value-name:"PATH" description:"State file path"`,
+				`long:"state" value-name:"PATH" description:"State file path or URL (http://, https:// or s3://)"`,
+			))
+		})
+
+		It("has --force-unlock", func() {
+			Expect(getStructTagForName("ForceUnlock", opts)).To(Equal(
+				`long:"force-unlock" description:"Remove lock held on remote state before proceeding"`,
+			))
+		})
+
+		It("has --timings", func() {
+			Expect(getStructTagForName("TimingsPath", opts)).To(Equal(
+				`long:"timings" value-name:"PATH" description:"Write timings of performed stages into a JSON file"`,
 			))
 		})
 	})
@@ -823,6 +961,146 @@ var _ = Describe("Opts", func() {
 		})
 	})
 
+	Describe("ExportCompiledPackageCacheOpts", func() {
+		var opts *ExportCompiledPackageCacheOpts
+
+		BeforeEach(func() {
+			opts = &ExportCompiledPackageCacheOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		Describe("StatePath", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("StatePath", opts)).To(Equal(
+					`long:"state" value-name:"PATH" description:"State file path" required:"true"`,
+				))
+			})
+		})
+	})
+
+	Describe("ImportCompiledPackageCacheOpts", func() {
+		var opts *ImportCompiledPackageCacheOpts
+
+		BeforeEach(func() {
+			opts = &ImportCompiledPackageCacheOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		Describe("StatePath", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("StatePath", opts)).To(Equal(
+					`long:"state" value-name:"PATH" description:"State file path" required:"true"`,
+				))
+			})
+		})
+	})
+
+	Describe("CompiledPackageCacheArgs", func() {
+		var args *CompiledPackageCacheArgs
+
+		BeforeEach(func() {
+			args = &CompiledPackageCacheArgs{}
+		})
+
+		Describe("Path", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Path", args)).To(Equal(
+					`positional-arg-name:"PATH" description:"Path to a compiled packages tarball"`,
+				))
+			})
+		})
+	})
+
+	Describe("CacheOpts", func() {
+		var opts *CacheOpts
+
+		BeforeEach(func() {
+			opts = &CacheOpts{}
+		})
+
+		Describe("List", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("List", opts)).To(Equal(
+					`command:"list" alias:"ls" description:"List cached tarballs"`,
+				))
+			})
+		})
+
+		Describe("Prune", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Prune", opts)).To(Equal(
+					`command:"prune" description:"Delete least recently used cached tarballs"`,
+				))
+			})
+		})
+
+		Describe("Verify", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Verify", opts)).To(Equal(
+					`command:"verify" description:"Verify SHA1 of cached tarballs"`,
+				))
+			})
+		})
+	})
+
+	Describe("CachePruneOpts", func() {
+		var opts *CachePruneOpts
+
+		BeforeEach(func() {
+			opts = &CachePruneOpts{}
+		})
+
+		Describe("OlderThan", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("OlderThan", opts)).To(Equal(
+					`long:"older-than" value-name:"DURATION" description:"Delete tarballs not used within duration (e.g. 720h)"`,
+				))
+			})
+		})
+
+		Describe("MaxSize", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("MaxSize", opts)).To(Equal(
+					`long:"max-size" value-name:"SIZE" description:"Delete tarballs until cache fits into size (e.g. 10GB)"`,
+				))
+			})
+		})
+	})
+
+	Describe("LogInOpts", func() {
+		var opts *LogInOpts
+
+		BeforeEach(func() {
+			opts = &LogInOpts{}
+		})
+
+		Describe("SSO", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("SSO", opts)).To(Equal(
+					`long:"sso" description:"Log in via UAA single sign-on with one-time passcode"`,
+				))
+			})
+		})
+
+		Describe("Browser", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Browser", opts)).To(Equal(
+					`long:"browser" description:"Log in via UAA single sign-on in a browser (implies --sso)"`,
+				))
+			})
+		})
+	})
+
 	Describe("TaskOpts", func() {
 		var opts *TaskOpts
 
@@ -931,6 +1209,36 @@ var _ = Describe("Opts", func() {
 		})
 	})
 
+	Describe("TaskReportOpts", func() {
+		var opts *TaskReportOpts
+
+		BeforeEach(func() {
+			opts = &TaskReportOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		Describe("Format", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Format", opts)).To(Equal(
+					`long:"format" value-name:"FORMAT" description:"Report format: text, json or csv (default: text)"`,
+				))
+			})
+		})
+
+		Describe("Slowest", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Slowest", opts)).To(Equal(
+					`long:"slowest" value-name:"NUMBER" description:"Number of slowest instances to show (default: 10)"`,
+				))
+			})
+		})
+	})
+
 	Describe("CleanUpOpts", func() {
 		var opts *CleanUpOpts
 
@@ -1036,6 +1344,12 @@ var _ = Describe("Opts", func() {
 				`long:"var-errs-unused" description:"Expect all variables to be used, otherwise error"`,
 			))
 		})
+
+		It("has Explain", func() {
+			Expect(getStructTagForName("Explain", &opts)).To(Equal(
+				`long:"explain" value-name:"OP-PATH" description:"Show ops files and variables that produced values under path instead of template (use '/' for all paths)"`,
+			))
+		})
 	})
 
 	Describe("InterpolateArgs", func() {
@@ -1054,6 +1368,36 @@ var _ = Describe("Opts", func() {
 		})
 	})
 
+	Describe("LintOpts", func() {
+		var opts LintOpts
+
+		It("has Args", func() {
+			Expect(getStructTagForName("Args", &opts)).To(Equal(`positional-args:"true" required:"true"`))
+		})
+
+		It("has --release", func() {
+			Expect(getStructTagForName("Releases", &opts)).To(Equal(
+				`long:"release" value-name:"PATH" description:"Path to a release tarball or directory (multiple release paths may be specified)"`,
+			))
+		})
+	})
+
+	Describe("LintArgs", func() {
+		var opts *LintArgs
+
+		BeforeEach(func() {
+			opts = &LintArgs{}
+		})
+
+		Describe("Manifest", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Manifest", opts)).To(Equal(
+					`positional-arg-name:"PATH" description:"Path to a manifest file"`,
+				))
+			})
+		})
+	})
+
 	Describe("UpdateCloudConfigOpts", func() {
 		var opts *UpdateCloudConfigOpts
 
@@ -1170,7 +1514,7 @@ var _ = Describe("Opts", func() {
 		Describe("DryRun", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("DryRun", opts)).To(Equal(
-					`long:"dry-run" description:"Renders job templates without altering deployment"`,
+					`long:"dry-run" description:"Renders job templates without altering deployment; with --json only prints deployment plan"`,
 				))
 			})
 		})
@@ -1192,6 +1536,58 @@ var _ = Describe("Opts", func() {
 		})
 	})
 
+	Describe("ExportDeploymentOpts", func() {
+		var opts *ExportDeploymentOpts
+
+		BeforeEach(func() {
+			opts = &ExportDeploymentOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+	})
+
+	Describe("ImportDeploymentOpts", func() {
+		var opts *ImportDeploymentOpts
+
+		BeforeEach(func() {
+			opts = &ImportDeploymentOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		Describe("DryRun", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("DryRun", opts)).To(Equal(
+					`long:"dry-run" description:"Only report releases and stemcells missing on the director"`,
+				))
+			})
+		})
+	})
+
+	Describe("DeploymentBundleArgs", func() {
+		var opts *DeploymentBundleArgs
+
+		BeforeEach(func() {
+			opts = &DeploymentBundleArgs{}
+		})
+
+		Describe("Path", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Path", opts)).To(Equal(
+					`positional-arg-name:"PATH" description:"Path to a deployment bundle tarball"`,
+				))
+			})
+		})
+	})
+
 	Describe("DeleteDeploymentOpts", func() {
 		var opts *DeleteDeploymentOpts
 
@@ -1248,7 +1644,7 @@ var _ = Describe("Opts", func() {
 		Describe("SHA1", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("SHA1", opts)).To(Equal(
-					`long:"sha1" description:"SHA1 of the remote stemcell (is not used with local files)"`,
+					`long:"sha1" description:"SHA1 of the stemcell (local files are verified before uploading)"`,
 				))
 			})
 		})
@@ -1364,7 +1760,7 @@ var _ = Describe("Opts", func() {
 		Describe("SHA1", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("SHA1", opts)).To(Equal(
-					`long:"sha1" description:"SHA1 of the remote release (is not used with local files)"`,
+					`long:"sha1" description:"SHA1 of the release (local files are verified before uploading)"`,
 				))
 			})
 		})
@@ -1926,6 +2322,14 @@ var _ = Describe("Opts", func() {
 				))
 			})
 		})
+
+		Describe("NativeSSH", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("NativeSSH", opts)).To(Equal(
+					`long:"native-ssh" description:"Use built-in SSH client instead of ssh and scp executables" env:"BOSH_NATIVE_SSH"`,
+				))
+			})
+		})
 	})
 
 	Describe("StartOpts", func() {
@@ -2172,6 +2576,14 @@ var _ = Describe("Opts", func() {
 				))
 			})
 		})
+
+		Describe("NativeSSH", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("NativeSSH", opts)).To(Equal(
+					`long:"native-ssh" description:"Use built-in SSH client instead of ssh and scp executables" env:"BOSH_NATIVE_SSH"`,
+				))
+			})
+		})
 	})
 
 	Describe("SCPOpts", func() {
@@ -2194,6 +2606,14 @@ var _ = Describe("Opts", func() {
 				))
 			})
 		})
+
+		Describe("NativeSSH", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("NativeSSH", opts)).To(Equal(
+					`long:"native-ssh" description:"Use built-in SSH client instead of ssh and scp executables" env:"BOSH_NATIVE_SSH"`,
+				))
+			})
+		})
 	})
 
 	Describe("SCPArgs", func() {
@@ -2510,6 +2930,76 @@ var _ = Describe("Opts", func() {
 		})
 	})
 
+	Describe("RenderJobOpts", func() {
+		var opts *RenderJobOpts
+
+		BeforeEach(func() {
+			opts = &RenderJobOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		Describe("Properties", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Properties", opts)).To(Equal(
+					`long:"properties" value-name:"PATH" description:"Path to a YAML file with job properties"`,
+				))
+			})
+		})
+
+		Describe("Links", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Links", opts)).To(Equal(
+					`long:"links" value-name:"PATH" description:"Path to a YAML file with links consumed by job"`,
+				))
+			})
+		})
+
+		Describe("InstanceSpec", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("InstanceSpec", opts)).To(Equal(
+					`long:"instance-spec" value-name:"PATH" description:"Path to a YAML file with instance spec (deployment, name, id, index, az, bootstrap, address, networks)"`,
+				))
+			})
+		})
+
+		Describe("Output", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Output", opts)).To(Equal(
+					`long:"output" value-name:"DIR" description:"Directory to write rendered files into" required:"true"`,
+				))
+			})
+		})
+	})
+
+	Describe("RenderJobArgs", func() {
+		var opts *RenderJobArgs
+
+		BeforeEach(func() {
+			opts = &RenderJobArgs{}
+		})
+
+		Describe("Release", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Release", opts)).To(Equal(
+					`positional-arg-name:"RELEASE-PATH" description:"Path to a release tarball or directory"`,
+				))
+			})
+		})
+
+		Describe("Job", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Job", opts)).To(Equal(
+					`positional-arg-name:"JOB" description:"Job name"`,
+				))
+			})
+		})
+	})
+
 	Describe("BlobsOpts", func() {
 		var opts *BlobsOpts
 
@@ -2586,6 +3076,168 @@ var _ = Describe("Opts", func() {
 		})
 	})
 
+	Describe("VarsStoreRekeyOpts", func() {
+		var opts *VarsStoreRekeyOpts
+
+		BeforeEach(func() {
+			opts = &VarsStoreRekeyOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		Describe("Key", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Key", opts)).To(Equal(
+					`long:"key" value-name:"file:PATH|KEY" description:"Current key from a file (file:PATH) or a value" env:"BOSH_VARS_STORE_KEY"`,
+				))
+			})
+		})
+
+		Describe("NewKey", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("NewKey", opts)).To(Equal(
+					`long:"new-key" value-name:"file:PATH|KEY" description:"New key from a file (file:PATH) or a value" env:"BOSH_VARS_STORE_NEW_KEY"`,
+				))
+			})
+		})
+
+		Describe("Decrypt", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Decrypt", opts)).To(Equal(
+					`long:"decrypt" description:"Store file unencrypted"`,
+				))
+			})
+		})
+	})
+
+	Describe("VarsStoreRekeyArgs", func() {
+		var opts *VarsStoreRekeyArgs
+
+		BeforeEach(func() {
+			opts = &VarsStoreRekeyArgs{}
+		})
+
+		Describe("VarsStore", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("VarsStore", opts)).To(Equal(
+					`positional-arg-name:"PATH" description:"Path to a vars store file"`,
+				))
+			})
+		})
+	})
+
+	Describe("VarsStoreOpts", func() {
+		var opts *VarsStoreOpts
+
+		BeforeEach(func() {
+			opts = &VarsStoreOpts{}
+		})
+
+		Describe("Rekey", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Rekey", opts)).To(Equal(
+					`command:"rekey" description:"Re-encrypt vars store file with a new key"`,
+				))
+			})
+		})
+
+		Describe("Certs", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Certs", opts)).To(Equal(
+					`command:"certs" description:"List certificates in a vars store with their expiry"`,
+				))
+			})
+		})
+
+		Describe("RotateCerts", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("RotateCerts", opts)).To(Equal(
+					`command:"rotate-certs" description:"Regenerate certificates in a vars store based on manifest variable definitions"`,
+				))
+			})
+		})
+	})
+
+	Describe("VarsStoreCertsOpts", func() {
+		var opts *VarsStoreCertsOpts
+
+		BeforeEach(func() {
+			opts = &VarsStoreCertsOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		Describe("Key", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Key", opts)).To(Equal(
+					`long:"key" value-name:"file:PATH|KEY" description:"Key to decrypt vars store file (file:PATH or a value)" env:"BOSH_VARS_STORE_KEY"`,
+				))
+			})
+		})
+	})
+
+	Describe("VarsStoreCertsArgs", func() {
+		var opts *VarsStoreCertsArgs
+
+		BeforeEach(func() {
+			opts = &VarsStoreCertsArgs{}
+		})
+
+		Describe("VarsStore", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("VarsStore", opts)).To(Equal(
+					`positional-arg-name:"PATH" description:"Path to a vars store file or a credential store URL"`,
+				))
+			})
+		})
+	})
+
+	Describe("VarsStoreRotateCertsOpts", func() {
+		var opts *VarsStoreRotateCertsOpts
+
+		BeforeEach(func() {
+			opts = &VarsStoreRotateCertsOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		Describe("Certs", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Certs", opts)).To(Equal(
+					`long:"cert" value-name:"NAME" description:"Certificate variable to regenerate; certificates signed by a CA are regenerated with it" required:"true"`,
+				))
+			})
+		})
+	})
+
+	Describe("VarsStoreRotateCertsArgs", func() {
+		var opts *VarsStoreRotateCertsArgs
+
+		BeforeEach(func() {
+			opts = &VarsStoreRotateCertsArgs{}
+		})
+
+		Describe("Manifest", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Manifest", opts)).To(Equal(
+					`positional-arg-name:"PATH" description:"Path to a manifest with variable definitions"`,
+				))
+			})
+		})
+	})
+
 	Describe("SyncBlobsOpts", func() {
 		var opts *SyncBlobsOpts
 
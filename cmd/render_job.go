@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	"gopkg.in/yaml.v2"
+
+	boshrel "github.com/cloudfoundry/bosh-cli/release"
+	bitemplate "github.com/cloudfoundry/bosh-cli/templatescompiler"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+type RenderJobCmd struct {
+	releaseReaderFactory func(string) boshrel.Reader
+	jobRenderer          bitemplate.JobRenderer
+	fs                   boshsys.FileSystem
+	ui                   boshui.UI
+}
+
+type renderJobInstanceSpec struct {
+	Deployment string `yaml:"deployment"`
+
+	Name      string `yaml:"name"`
+	ID        string `yaml:"id"`
+	Index     int    `yaml:"index"`
+	AZ        string `yaml:"az"`
+	Bootstrap bool   `yaml:"bootstrap"`
+	Address   string `yaml:"address"`
+
+	Networks map[string]renderJobNetwork `yaml:"networks"`
+}
+
+type renderJobNetwork struct {
+	IP      string `yaml:"ip"`
+	Netmask string `yaml:"netmask"`
+	Gateway string `yaml:"gateway"`
+}
+
+type renderJobLink struct {
+	Address    string                      `yaml:"address"`
+	Properties map[interface{}]interface{} `yaml:"properties"`
+	Instances  []renderJobLinkInstance     `yaml:"instances"`
+}
+
+type renderJobLinkInstance struct {
+	Name      string `yaml:"name"`
+	ID        string `yaml:"id"`
+	Index     int    `yaml:"index"`
+	AZ        string `yaml:"az"`
+	Address   string `yaml:"address"`
+	Bootstrap bool   `yaml:"bootstrap"`
+}
+
+func NewRenderJobCmd(
+	releaseReaderFactory func(string) boshrel.Reader,
+	jobRenderer bitemplate.JobRenderer,
+	fs boshsys.FileSystem,
+	ui boshui.UI,
+) RenderJobCmd {
+	return RenderJobCmd{
+		releaseReaderFactory: releaseReaderFactory,
+		jobRenderer:          jobRenderer,
+		fs:                   fs,
+		ui:                   ui,
+	}
+}
+
+func (c RenderJobCmd) Run(opts RenderJobOpts) error {
+	properties, err := c.properties(opts.Properties.Bytes)
+	if err != nil {
+		return err
+	}
+
+	deploymentName, instance, err := c.instance(opts.InstanceSpec.Bytes)
+	if err != nil {
+		return err
+	}
+
+	instance.Links, err = c.links(opts.Links.Bytes)
+	if err != nil {
+		return err
+	}
+
+	release, err := c.releaseReaderFactory(opts.Args.Release).Read(opts.Args.Release)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Reading release '%s'", opts.Args.Release)
+	}
+
+	defer release.CleanUp()
+
+	job, found := release.FindJobByName(opts.Args.Job)
+	if !found {
+		return bosherr.Errorf("Expected to find job '%s' in release '%s'", opts.Args.Job, opts.Args.Release)
+	}
+
+	renderedJob, err := c.jobRenderer.RenderInstance(job, &properties, biproperty.Map{}, biproperty.Map{}, deploymentName, instance)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Rendering job '%s'", job.Name())
+	}
+
+	defer renderedJob.DeleteSilently()
+
+	err = c.fs.CopyDir(renderedJob.Path(), opts.Output)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Copying rendered job '%s' to '%s'", job.Name(), opts.Output)
+	}
+
+	table := boshtbl.Table{
+		Content: "rendered files",
+
+		Header: []string{"Template", "Path"},
+
+		SortBy: []boshtbl.ColumnSort{{Column: 0, Asc: true}},
+	}
+
+	for src, dst := range job.Templates {
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(filepath.Join("templates", src)),
+			boshtbl.NewValueString(filepath.Join(opts.Output, dst)),
+		})
+	}
+
+	table.Rows = append(table.Rows, []boshtbl.Value{
+		boshtbl.NewValueString("monit"),
+		boshtbl.NewValueString(filepath.Join(opts.Output, "monit")),
+	})
+
+	c.ui.PrintTable(table)
+
+	return nil
+}
+
+func (c RenderJobCmd) properties(bytes []byte) (biproperty.Map, error) {
+	var rawProperties map[interface{}]interface{}
+
+	err := yaml.Unmarshal(bytes, &rawProperties)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Unmarshalling properties")
+	}
+
+	properties, err := biproperty.BuildMap(rawProperties)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Building properties")
+	}
+
+	return properties, nil
+}
+
+// instance defaults to the same values that create-env uses for its single instance
+func (c RenderJobCmd) instance(bytes []byte) (string, bitemplate.InstanceSpec, error) {
+	spec := renderJobInstanceSpec{AZ: "unknown", Bootstrap: true}
+
+	err := yaml.Unmarshal(bytes, &spec)
+	if err != nil {
+		return "", bitemplate.InstanceSpec{}, bosherr.WrapError(err, "Unmarshalling instance spec")
+	}
+
+	instance := bitemplate.InstanceSpec{
+		Name:      spec.Name,
+		ID:        spec.ID,
+		Index:     spec.Index,
+		AZ:        spec.AZ,
+		Bootstrap: spec.Bootstrap,
+		Address:   spec.Address,
+		Networks:  map[string]bitemplate.NetworkSpec{},
+	}
+
+	for name, network := range spec.Networks {
+		instance.Networks[name] = bitemplate.NetworkSpec{
+			IP:      network.IP,
+			Netmask: network.Netmask,
+			Gateway: network.Gateway,
+		}
+	}
+
+	if len(instance.Networks) == 0 {
+		instance.Networks["default"] = bitemplate.NetworkSpec{}
+	}
+
+	return spec.Deployment, instance, nil
+}
+
+func (c RenderJobCmd) links(bytes []byte) (map[string]bitemplate.LinkSpec, error) {
+	var rawLinks map[string]renderJobLink
+
+	err := yaml.Unmarshal(bytes, &rawLinks)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Unmarshalling links")
+	}
+
+	links := map[string]bitemplate.LinkSpec{}
+
+	for name, rawLink := range rawLinks {
+		properties, err := biproperty.BuildMap(rawLink.Properties)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Building properties of link '%s'", name)
+		}
+
+		link := bitemplate.LinkSpec{
+			Address:    rawLink.Address,
+			Properties: properties,
+			Instances:  []bitemplate.LinkInstanceSpec{},
+		}
+
+		for _, inst := range rawLink.Instances {
+			link.Instances = append(link.Instances, bitemplate.LinkInstanceSpec{
+				Name:      inst.Name,
+				ID:        inst.ID,
+				Index:     inst.Index,
+				AZ:        inst.AZ,
+				Address:   inst.Address,
+				Bootstrap: inst.Bootstrap,
+			})
+		}
+
+		links[name] = link
+	}
+
+	return links, nil
+}
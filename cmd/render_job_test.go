@@ -0,0 +1,214 @@
+package cmd_test
+
+import (
+	"errors"
+
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	boshrel "github.com/cloudfoundry/bosh-cli/release"
+	boshjob "github.com/cloudfoundry/bosh-cli/release/job"
+	fakerel "github.com/cloudfoundry/bosh-cli/release/releasefakes"
+	. "github.com/cloudfoundry/bosh-cli/release/resource"
+	bitemplate "github.com/cloudfoundry/bosh-cli/templatescompiler"
+	mock_template "github.com/cloudfoundry/bosh-cli/templatescompiler/mocks"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+var _ = Describe("RenderJobCmd", func() {
+	var (
+		mockCtrl        *gomock.Controller
+		mockJobRenderer *mock_template.MockJobRenderer
+		mockRenderedJob *mock_template.MockRenderedJob
+		releaseReader   *fakerel.FakeReader
+		release         *fakerel.FakeRelease
+		job             *boshjob.Job
+		readerPaths     []string
+		fs              *fakesys.FakeFileSystem
+		ui              *fakeui.FakeUI
+		command         RenderJobCmd
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockJobRenderer = mock_template.NewMockJobRenderer(mockCtrl)
+		mockRenderedJob = mock_template.NewMockRenderedJob(mockCtrl)
+
+		job = boshjob.NewExtractedJob(NewResource("web", "fp", nil), "/release/jobs/web", nil)
+		job.Templates = map[string]string{"ctl.erb": "bin/ctl"}
+
+		release = &fakerel.FakeRelease{}
+		release.FindJobByNameStub = func(name string) (boshjob.Job, bool) {
+			return *job, name == "web"
+		}
+
+		releaseReader = &fakerel.FakeReader{}
+		releaseReader.ReadReturns(release, nil)
+
+		readerPaths = nil
+
+		releaseReaderFactory := func(path string) boshrel.Reader {
+			readerPaths = append(readerPaths, path)
+			return releaseReader
+		}
+
+		fs = fakesys.NewFakeFileSystem()
+		ui = &fakeui.FakeUI{}
+		command = NewRenderJobCmd(releaseReaderFactory, mockJobRenderer, fs, ui)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	renderJobOpts := func() RenderJobOpts {
+		return RenderJobOpts{
+			Args: RenderJobArgs{Release: "/release", Job: "web"},
+
+			Properties: FileBytesArg{Bytes: []byte("port: 8080\n")},
+
+			Output: "/output",
+		}
+	}
+
+	It("renders job with given properties into output directory", func() {
+		expectedInstance := bitemplate.InstanceSpec{
+			AZ:        "unknown",
+			Bootstrap: true,
+			Networks:  map[string]bitemplate.NetworkSpec{"default": {}},
+			Links:     map[string]bitemplate.LinkSpec{},
+		}
+
+		mockJobRenderer.EXPECT().RenderInstance(
+			*job, &biproperty.Map{"port": 8080}, biproperty.Map{}, biproperty.Map{}, "", expectedInstance,
+		).Return(mockRenderedJob, nil)
+
+		err := fs.WriteFileString("/rendered/bin/ctl", "rendered-ctl")
+		Expect(err).ToNot(HaveOccurred())
+
+		mockRenderedJob.EXPECT().Path().Return("/rendered")
+		mockRenderedJob.EXPECT().DeleteSilently()
+
+		err = command.Run(renderJobOpts())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(readerPaths).To(Equal([]string{"/release"}))
+		Expect(releaseReader.ReadArgsForCall(0)).To(Equal("/release"))
+		Expect(release.CleanUpCallCount()).To(Equal(1))
+
+		Expect(fs.ReadFileString("/output/bin/ctl")).To(Equal("rendered-ctl"))
+
+		Expect(ui.Table).To(Equal(boshtbl.Table{
+			Content: "rendered files",
+
+			Header: []string{"Template", "Path"},
+
+			SortBy: []boshtbl.ColumnSort{{Column: 0, Asc: true}},
+
+			Rows: [][]boshtbl.Value{
+				{boshtbl.NewValueString("templates/ctl.erb"), boshtbl.NewValueString("/output/bin/ctl")},
+				{boshtbl.NewValueString("monit"), boshtbl.NewValueString("/output/monit")},
+			},
+		}))
+	})
+
+	It("renders job for given instance spec and links", func() {
+		opts := renderJobOpts()
+		opts.InstanceSpec = FileBytesArg{Bytes: []byte(`
+deployment: dep
+name: web-group
+id: inst-id
+index: 2
+az: z1
+bootstrap: false
+address: web.bosh
+networks:
+  private: {ip: 10.0.0.5, netmask: 255.255.255.0, gateway: 10.0.0.1}
+`)}
+		opts.Links = FileBytesArg{Bytes: []byte(`
+db:
+  address: db.bosh
+  properties: {port: 5432}
+  instances:
+  - {name: db, index: 0, id: db-id, az: z1, address: 10.0.0.6, bootstrap: true}
+`)}
+
+		expectedInstance := bitemplate.InstanceSpec{
+			Name:      "web-group",
+			ID:        "inst-id",
+			Index:     2,
+			AZ:        "z1",
+			Bootstrap: false,
+			Address:   "web.bosh",
+			Networks: map[string]bitemplate.NetworkSpec{
+				"private": {IP: "10.0.0.5", Netmask: "255.255.255.0", Gateway: "10.0.0.1"},
+			},
+			Links: map[string]bitemplate.LinkSpec{
+				"db": {
+					Address:    "db.bosh",
+					Properties: biproperty.Map{"port": 5432},
+					Instances: []bitemplate.LinkInstanceSpec{
+						{Name: "db", Index: 0, ID: "db-id", AZ: "z1", Address: "10.0.0.6", Bootstrap: true},
+					},
+				},
+			},
+		}
+
+		mockJobRenderer.EXPECT().RenderInstance(
+			*job, &biproperty.Map{"port": 8080}, biproperty.Map{}, biproperty.Map{}, "dep", expectedInstance,
+		).Return(mockRenderedJob, nil)
+
+		mockRenderedJob.EXPECT().Path().Return("/rendered")
+		mockRenderedJob.EXPECT().DeleteSilently()
+
+		err := command.Run(opts)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("returns error if job is not found in release", func() {
+		opts := renderJobOpts()
+		opts.Args.Job = "other"
+
+		err := command.Run(opts)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find job 'other' in release '/release'"))
+
+		Expect(release.CleanUpCallCount()).To(Equal(1))
+	})
+
+	It("returns error if release cannot be read", func() {
+		releaseReader.ReadReturns(nil, errors.New("fake-err"))
+
+		err := command.Run(renderJobOpts())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Reading release '/release'"))
+		Expect(err.Error()).To(ContainSubstring("fake-err"))
+	})
+
+	It("returns error if properties are not valid YAML", func() {
+		opts := renderJobOpts()
+		opts.Properties = FileBytesArg{Bytes: []byte("-")}
+
+		err := command.Run(opts)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unmarshalling properties"))
+	})
+
+	It("returns error with template location if rendering fails", func() {
+		mockJobRenderer.EXPECT().RenderInstance(
+			gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		).Return(nil, errors.New("Error filling in template 'ctl.erb' for web/0 (line 3: fake-err)"))
+
+		err := command.Run(renderJobOpts())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Rendering job 'web'"))
+		Expect(err.Error()).To(ContainSubstring("Error filling in template 'ctl.erb' for web/0 (line 3: fake-err)"))
+
+		Expect(fs.FileExists("/output")).To(BeFalse())
+	})
+})
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	biuifmt "github.com/cloudfoundry/bosh-cli/ui/fmt"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+type StageTimingsReporter struct {
+	ui         boshui.UI
+	fs         boshsys.FileSystem
+	printTable bool
+}
+
+type stageTimingsFile struct {
+	Stages []boshui.StageTiming `json:"stages"`
+}
+
+// NewStageTimingsReporter prints timings table only when printTable is set (e.g. --json)
+// so that regular output is not duplicated
+func NewStageTimingsReporter(ui boshui.UI, fs boshsys.FileSystem, printTable bool) StageTimingsReporter {
+	return StageTimingsReporter{ui: ui, fs: fs, printTable: printTable}
+}
+
+func (r StageTimingsReporter) Report(timings []boshui.StageTiming, path string) error {
+	if len(path) > 0 {
+		err := r.writeFile(timings, path)
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.printTable {
+		r.ui.PrintTable(r.table(timings))
+	}
+
+	return nil
+}
+
+func (r StageTimingsReporter) writeFile(timings []boshui.StageTiming, path string) error {
+	if timings == nil {
+		timings = []boshui.StageTiming{}
+	}
+
+	bytes, err := json.MarshalIndent(stageTimingsFile{Stages: timings}, "", "  ")
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling stage timings")
+	}
+
+	err = r.fs.WriteFile(path, bytes)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing stage timings '%s'", path)
+	}
+
+	return nil
+}
+
+func (r StageTimingsReporter) table(timings []boshui.StageTiming) boshtbl.Table {
+	table := boshtbl.Table{
+		Content: "stage timings",
+
+		Header: []string{"Stage", "State", "Started At", "Duration"},
+	}
+
+	r.addRows(&table, timings, 0)
+
+	return table
+}
+
+func (r StageTimingsReporter) addRows(table *boshtbl.Table, timings []boshui.StageTiming, depth int) {
+	for _, timing := range timings {
+		duration := time.Duration(timing.Duration * float64(time.Second))
+
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(strings.Repeat("  ", depth) + timing.Name),
+			boshtbl.NewValueFmt(boshtbl.NewValueString(timing.State), timing.State == boshui.StageTimingStateFailed),
+			boshtbl.NewValueTime(timing.StartedAt),
+			boshtbl.NewValueString(biuifmt.Duration(duration)),
+		})
+
+		r.addRows(table, timing.Stages, depth+1)
+	}
+}
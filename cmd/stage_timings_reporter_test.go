@@ -0,0 +1,136 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+var _ = Describe("StageTimingsReporter", func() {
+	var (
+		ui      *fakeui.FakeUI
+		fs      *fakesys.FakeFileSystem
+		timings []boshui.StageTiming
+
+		startTime time.Time
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		fs = fakesys.NewFakeFileSystem()
+
+		startTime = time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+		timings = []boshui.StageTiming{
+			{
+				Name:       "Compiling packages",
+				State:      "finished",
+				StartedAt:  startTime,
+				FinishedAt: startTime.Add(90 * time.Second),
+				Duration:   90,
+				Stages: []boshui.StageTiming{
+					{
+						Name:       "Compiling package 'pkg/fp'",
+						State:      "failed",
+						Error:      "fake-err",
+						StartedAt:  startTime,
+						FinishedAt: startTime.Add(30 * time.Second),
+						Duration:   30,
+					},
+				},
+			},
+		}
+	})
+
+	It("writes timings into a JSON file", func() {
+		err := NewStageTimingsReporter(ui, fs, false).Report(timings, "/fake-timings.json")
+		Expect(err).ToNot(HaveOccurred())
+
+		contents, err := fs.ReadFile("/fake-timings.json")
+		Expect(err).ToNot(HaveOccurred())
+
+		var report map[string]interface{}
+		Expect(json.Unmarshal(contents, &report)).To(Succeed())
+
+		Expect(report).To(Equal(map[string]interface{}{
+			"stages": []interface{}{
+				map[string]interface{}{
+					"name":             "Compiling packages",
+					"state":            "finished",
+					"started_at":       "2016-01-01T00:00:00Z",
+					"finished_at":      "2016-01-01T00:01:30Z",
+					"duration_seconds": 90.0,
+					"stages": []interface{}{
+						map[string]interface{}{
+							"name":             "Compiling package 'pkg/fp'",
+							"state":            "failed",
+							"error":            "fake-err",
+							"started_at":       "2016-01-01T00:00:00Z",
+							"finished_at":      "2016-01-01T00:00:30Z",
+							"duration_seconds": 30.0,
+						},
+					},
+				},
+			},
+		}))
+
+		Expect(ui.Tables).To(BeEmpty())
+	})
+
+	It("writes empty list of stages when no stages were performed", func() {
+		err := NewStageTimingsReporter(ui, fs, false).Report(nil, "/fake-timings.json")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fs.ReadFileString("/fake-timings.json")).To(Equal("{\n  \"stages\": []\n}"))
+	})
+
+	It("does not write a file when path is not specified", func() {
+		err := NewStageTimingsReporter(ui, fs, false).Report(timings, "")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fs.FileExists("/fake-timings.json")).To(BeFalse())
+	})
+
+	It("returns an error when writing the file fails", func() {
+		fs.WriteFileError = errors.New("fake-write-err")
+
+		err := NewStageTimingsReporter(ui, fs, false).Report(timings, "/fake-timings.json")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Writing stage timings '/fake-timings.json'"))
+	})
+
+	It("prints timings table when requested", func() {
+		err := NewStageTimingsReporter(ui, fs, true).Report(timings, "")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ui.Table).To(Equal(boshtbl.Table{
+			Content: "stage timings",
+
+			Header: []string{"Stage", "State", "Started At", "Duration"},
+
+			Rows: [][]boshtbl.Value{
+				{
+					boshtbl.NewValueString("Compiling packages"),
+					boshtbl.NewValueFmt(boshtbl.NewValueString("finished"), false),
+					boshtbl.NewValueTime(startTime),
+					boshtbl.NewValueString("00:01:30"),
+				},
+				{
+					boshtbl.NewValueString("  Compiling package 'pkg/fp'"),
+					boshtbl.NewValueFmt(boshtbl.NewValueString("failed"), true),
+					boshtbl.NewValueTime(startTime),
+					boshtbl.NewValueString("00:00:30"),
+				},
+			},
+		}))
+	})
+})
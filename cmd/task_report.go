@@ -0,0 +1,345 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshuifmt "github.com/cloudfoundry/bosh-cli/ui/fmt"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+	boshuit "github.com/cloudfoundry/bosh-cli/ui/task"
+)
+
+const (
+	taskReportDefaultSlowest = 10
+	taskReportGanttWidth     = 40
+)
+
+type TaskReportCmd struct {
+	ui       boshui.UI
+	director boshdir.Director
+}
+
+type taskReportJSON struct {
+	ID    int    `json:"id"`
+	State string `json:"state"`
+
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Duration   float64   `json:"duration"`
+
+	Stages           []taskReportStageJSON    `json:"stages"`
+	CriticalPath     []taskReportStepJSON     `json:"critical_path"`
+	SlowestInstances []taskReportInstanceJSON `json:"slowest_instances"`
+	Errors           []string                 `json:"errors"`
+}
+
+type taskReportStageJSON struct {
+	Name       string               `json:"name"`
+	StartedAt  time.Time            `json:"started_at"`
+	FinishedAt time.Time            `json:"finished_at"`
+	Duration   float64              `json:"duration"`
+	Steps      []taskReportStepJSON `json:"steps"`
+}
+
+type taskReportStepJSON struct {
+	Stage      string    `json:"stage"`
+	Name       string    `json:"name"`
+	Instance   string    `json:"instance,omitempty"`
+	State      string    `json:"state"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Duration   float64   `json:"duration"`
+}
+
+type taskReportInstanceJSON struct {
+	Name     string  `json:"name"`
+	Steps    int     `json:"steps"`
+	Failed   bool    `json:"failed"`
+	Duration float64 `json:"duration"`
+}
+
+func NewTaskReportCmd(ui boshui.UI, director boshdir.Director) TaskReportCmd {
+	return TaskReportCmd{ui: ui, director: director}
+}
+
+func (c TaskReportCmd) Run(opts TaskReportOpts) error {
+	switch opts.Format {
+	case "", "text", "json", "csv":
+	default:
+		return bosherr.Errorf("Expected format to be one of 'text', 'json' or 'csv' but was '%s'", opts.Format)
+	}
+
+	task, err := c.director.FindTask(opts.Args.ID)
+	if err != nil {
+		return err
+	}
+
+	reporter := boshuit.NewTimelineReporter()
+
+	err = task.EventOutput(reporter)
+	if err != nil {
+		// Reports are mostly useful for tasks that did not succeed
+		switch reporter.State() {
+		case "error", "cancelled", "timeout":
+		default:
+			return err
+		}
+	}
+
+	timeline, err := reporter.Timeline()
+	if err != nil {
+		return err
+	}
+
+	slowest := opts.Slowest
+	if slowest <= 0 {
+		slowest = taskReportDefaultSlowest
+	}
+
+	switch opts.Format {
+	case "json":
+		return c.printJSON(opts.Args.ID, reporter.State(), timeline, slowest)
+	case "csv":
+		return c.printCSV(timeline)
+	default:
+		c.printText(opts.Args.ID, reporter.State(), timeline, slowest)
+		return nil
+	}
+}
+
+func (c TaskReportCmd) printText(id int, state string, timeline boshuit.Timeline, slowest int) {
+	summary := boshtbl.Table{
+		Rows: [][]boshtbl.Value{
+			{boshtbl.NewValueString("Task"), boshtbl.NewValueInt(id)},
+			{boshtbl.NewValueString("State"), boshtbl.NewValueString(state)},
+			{boshtbl.NewValueString("Started"), boshtbl.NewValueString(timeline.StartedAt.Format(boshuifmt.TimeFullFmt))},
+			{boshtbl.NewValueString("Finished"), boshtbl.NewValueString(timeline.FinishedAt.Format(boshuifmt.TimeFullFmt))},
+			{boshtbl.NewValueString("Duration"), boshtbl.NewValueString(boshuifmt.Duration(timeline.Duration()))},
+		},
+	}
+
+	for _, msg := range timeline.Errors {
+		summary.Rows = append(summary.Rows, []boshtbl.Value{
+			boshtbl.NewValueString("Error"),
+			boshtbl.NewValueFmt(boshtbl.NewValueString(msg), true),
+		})
+	}
+
+	c.ui.PrintTable(summary)
+
+	criticalPath := boshtbl.Table{
+		Title:   "Critical path",
+		Content: "steps",
+		Header:  []string{"Stage", "Step", "Start", "Duration", "Share"},
+		SortBy:  []boshtbl.ColumnSort{{Column: 2, Asc: true}},
+	}
+
+	for _, step := range timeline.CriticalPath() {
+		var share int64
+
+		if timeline.Duration() > 0 {
+			share = int64(step.Duration()) * 100 / int64(timeline.Duration())
+		}
+
+		criticalPath.Rows = append(criticalPath.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(step.Stage),
+			c.stepNameValue(step),
+			boshtbl.NewValueString(c.offset(timeline, step.StartedAt)),
+			boshtbl.NewValueString(boshuifmt.Duration(step.Duration())),
+			boshtbl.NewValueString(fmt.Sprintf("%d%%", share)),
+		})
+	}
+
+	c.ui.PrintTable(criticalPath)
+
+	instances := boshtbl.Table{
+		Title:   "Slowest instances",
+		Content: "instances",
+		Header:  []string{"Instance", "Steps", "Duration", "Failed"},
+		SortBy:  []boshtbl.ColumnSort{{Column: 2, Asc: false}},
+	}
+
+	for _, inst := range timeline.SlowestInstances(slowest) {
+		instances.Rows = append(instances.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(inst.Name),
+			boshtbl.NewValueInt(inst.Steps),
+			boshtbl.NewValueString(boshuifmt.Duration(inst.Duration)),
+			boshtbl.NewValueBool(inst.Failed),
+		})
+	}
+
+	c.ui.PrintTable(instances)
+
+	gantt := boshtbl.Table{
+		Title:   "Timeline",
+		Content: "steps",
+		Header:  []string{"Stage", "Step", "Start", "Duration", ""},
+		SortBy:  []boshtbl.ColumnSort{{Column: 2, Asc: true}},
+	}
+
+	for _, step := range timeline.Steps() {
+		gantt.Rows = append(gantt.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(step.Stage),
+			c.stepNameValue(step),
+			boshtbl.NewValueString(c.offset(timeline, step.StartedAt)),
+			boshtbl.NewValueString(boshuifmt.Duration(step.Duration())),
+			boshtbl.NewValueString(c.bar(timeline, step)),
+		})
+	}
+
+	c.ui.PrintTable(gantt)
+}
+
+func (c TaskReportCmd) stepNameValue(step boshuit.TimelineStep) boshtbl.Value {
+	return boshtbl.NewValueFmt(boshtbl.NewValueString(step.Name), step.State == boshuit.EventStateFailed)
+}
+
+// offset is formatted with a fixed width so that it could be sorted as a string
+func (c TaskReportCmd) offset(timeline boshuit.Timeline, t time.Time) string {
+	return "+" + boshuifmt.Duration(t.Sub(timeline.StartedAt))
+}
+
+// bar shows when step was performed relative to the whole task,
+// e.g. "    =====     " for a step that took a third of the task in the middle
+func (c TaskReportCmd) bar(timeline boshuit.Timeline, step boshuit.TimelineStep) string {
+	total := timeline.Duration()
+	if total <= 0 {
+		return strings.Repeat("=", taskReportGanttWidth)
+	}
+
+	start := int(int64(step.StartedAt.Sub(timeline.StartedAt)) * taskReportGanttWidth / int64(total))
+	length := int(int64(step.Duration()) * taskReportGanttWidth / int64(total))
+
+	if start >= taskReportGanttWidth {
+		start = taskReportGanttWidth - 1
+	}
+
+	if length < 1 {
+		length = 1
+	}
+
+	if start+length > taskReportGanttWidth {
+		length = taskReportGanttWidth - start
+	}
+
+	char := "="
+
+	switch step.State {
+	case boshuit.EventStateFailed:
+		char = "x"
+	case boshuit.EventStateStarted:
+		char = "?"
+	}
+
+	return strings.Repeat(" ", start) + strings.Repeat(char, length) + strings.Repeat(" ", taskReportGanttWidth-start-length)
+}
+
+func (c TaskReportCmd) printJSON(id int, state string, timeline boshuit.Timeline, slowest int) error {
+	report := taskReportJSON{
+		ID:    id,
+		State: state,
+
+		StartedAt:  timeline.StartedAt,
+		FinishedAt: timeline.FinishedAt,
+		Duration:   timeline.Duration().Seconds(),
+
+		Stages:           []taskReportStageJSON{},
+		CriticalPath:     []taskReportStepJSON{},
+		SlowestInstances: []taskReportInstanceJSON{},
+		Errors:           []string{},
+	}
+
+	for _, stage := range timeline.Stages {
+		stageJSON := taskReportStageJSON{
+			Name:       stage.Name,
+			StartedAt:  stage.StartedAt(),
+			FinishedAt: stage.FinishedAt(),
+			Duration:   stage.Duration().Seconds(),
+			Steps:      []taskReportStepJSON{},
+		}
+
+		for _, step := range stage.Steps {
+			stageJSON.Steps = append(stageJSON.Steps, c.stepJSON(step))
+		}
+
+		report.Stages = append(report.Stages, stageJSON)
+	}
+
+	for _, step := range timeline.CriticalPath() {
+		report.CriticalPath = append(report.CriticalPath, c.stepJSON(step))
+	}
+
+	for _, inst := range timeline.SlowestInstances(slowest) {
+		report.SlowestInstances = append(report.SlowestInstances, taskReportInstanceJSON{
+			Name:     inst.Name,
+			Steps:    inst.Steps,
+			Failed:   inst.Failed,
+			Duration: inst.Duration.Seconds(),
+		})
+	}
+
+	report.Errors = append(report.Errors, timeline.Errors...)
+
+	bytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Marshaling task '%d' report", id)
+	}
+
+	c.ui.PrintBlock(string(bytes) + "\n")
+
+	return nil
+}
+
+func (c TaskReportCmd) stepJSON(step boshuit.TimelineStep) taskReportStepJSON {
+	return taskReportStepJSON{
+		Stage:      step.Stage,
+		Name:       step.Name,
+		Instance:   step.InstanceName(),
+		State:      step.State,
+		Error:      step.Error,
+		StartedAt:  step.StartedAt,
+		FinishedAt: step.FinishedAt,
+		Duration:   step.Duration().Seconds(),
+	}
+}
+
+func (c TaskReportCmd) printCSV(timeline boshuit.Timeline) error {
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+
+	records := [][]string{
+		{"stage", "step", "instance", "state", "started_at", "finished_at", "duration", "error"},
+	}
+
+	for _, step := range timeline.Steps() {
+		records = append(records, []string{
+			step.Stage,
+			step.Name,
+			step.InstanceName(),
+			step.State,
+			step.StartedAt.Format(time.RFC3339),
+			step.FinishedAt.Format(time.RFC3339),
+			strconv.FormatFloat(step.Duration().Seconds(), 'f', -1, 64),
+			step.Error,
+		})
+	}
+
+	err := writer.WriteAll(records)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing CSV report")
+	}
+
+	c.ui.PrintBlock(buf.String())
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	fakedir "github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+var _ = Describe("TaskReportCmd", func() {
+	var (
+		ui       *fakeui.FakeUI
+		director *fakedir.FakeDirector
+		task     *fakedir.FakeTask
+		command  TaskReportCmd
+	)
+
+	const events = `{"time":1000,"stage":"Preparing deployment","tags":[],"total":1,"task":"Binding deployment","index":1,"state":"started","progress":0}
+{"time":1010,"stage":"Preparing deployment","tags":[],"total":1,"task":"Binding deployment","index":1,"state":"finished","progress":100}
+{"time":1010,"stage":"Updating instance","tags":["api"],"total":2,"task":"api/abc (0) (canary)","index":1,"state":"started","progress":0}
+{"time":1020,"stage":"Updating instance","tags":["api"],"total":2,"task":"api/def (1)","index":2,"state":"started","progress":0}
+{"time":1030,"stage":"Updating instance","tags":["api"],"total":2,"task":"api/abc (0) (canary)","index":1,"state":"finished","progress":100}
+{"time":1050,"stage":"Updating instance","tags":["api"],"total":2,"task":"api/def (1)","index":2,"state":"failed","progress":100,"data":{"error":"fake-step-err"}}
+`
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		director = &fakedir.FakeDirector{}
+		task = &fakedir.FakeTask{}
+		director.FindTaskReturns(task, nil)
+
+		task.EventOutputStub = func(reporter boshdir.TaskReporter) error {
+			reporter.TaskStarted(123)
+			reporter.TaskOutputChunk(123, []byte(events))
+			reporter.TaskFinished(123, "error")
+			return errors.New("fake-task-failed-err")
+		}
+
+		command = NewTaskReportCmd(ui, director)
+	})
+
+	Describe("Run", func() {
+		var (
+			opts TaskReportOpts
+		)
+
+		BeforeEach(func() {
+			opts = TaskReportOpts{}
+			opts.Args.ID = 123
+		})
+
+		act := func() error { return command.Run(opts) }
+
+		It("prints summary, critical path, slowest instances and timeline of failed task", func() {
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(director.FindTaskArgsForCall(0)).To(Equal(123))
+
+			Expect(ui.Tables).To(HaveLen(4))
+
+			Expect(ui.Tables[0].Rows[1]).To(Equal([]boshtbl.Value{
+				boshtbl.NewValueString("State"),
+				boshtbl.NewValueString("error"),
+			}))
+			Expect(ui.Tables[0].Rows[4]).To(Equal([]boshtbl.Value{
+				boshtbl.NewValueString("Duration"),
+				boshtbl.NewValueString("00:00:50"),
+			}))
+
+			Expect(ui.Tables[1].Title).To(Equal("Critical path"))
+			Expect(ui.Tables[1].Rows).To(Equal([][]boshtbl.Value{
+				{
+					boshtbl.NewValueString("Preparing deployment"),
+					boshtbl.NewValueFmt(boshtbl.NewValueString("Binding deployment"), false),
+					boshtbl.NewValueString("+00:00:00"),
+					boshtbl.NewValueString("00:00:10"),
+					boshtbl.NewValueString("20%"),
+				},
+				{
+					boshtbl.NewValueString("Updating instance api"),
+					boshtbl.NewValueFmt(boshtbl.NewValueString("api/def (1)"), true),
+					boshtbl.NewValueString("+00:00:20"),
+					boshtbl.NewValueString("00:00:30"),
+					boshtbl.NewValueString("60%"),
+				},
+			}))
+
+			Expect(ui.Tables[2].Title).To(Equal("Slowest instances"))
+			Expect(ui.Tables[2].Rows).To(Equal([][]boshtbl.Value{
+				{
+					boshtbl.NewValueString("api/def"),
+					boshtbl.NewValueInt(1),
+					boshtbl.NewValueString("00:00:30"),
+					boshtbl.NewValueBool(true),
+				},
+				{
+					boshtbl.NewValueString("api/abc"),
+					boshtbl.NewValueInt(1),
+					boshtbl.NewValueString("00:00:20"),
+					boshtbl.NewValueBool(false),
+				},
+			}))
+
+			Expect(ui.Tables[3].Title).To(Equal("Timeline"))
+			Expect(ui.Tables[3].Rows).To(HaveLen(3))
+			Expect(ui.Tables[3].Rows[0][4]).To(Equal(boshtbl.NewValueString(
+				"========                                ")))
+			Expect(ui.Tables[3].Rows[2][4]).To(Equal(boshtbl.NewValueString(
+				"                xxxxxxxxxxxxxxxxxxxxxxxx")))
+		})
+
+		It("limits number of slowest instances", func() {
+			opts.Slowest = 1
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ui.Tables[2].Rows).To(HaveLen(1))
+		})
+
+		It("prints report as JSON", func() {
+			opts.Format = "json"
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ui.Blocks).To(HaveLen(1))
+
+			var report map[string]interface{}
+
+			err = json.Unmarshal([]byte(ui.Blocks[0]), &report)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(report["id"]).To(Equal(123.0))
+			Expect(report["state"]).To(Equal("error"))
+			Expect(report["duration"]).To(Equal(50.0))
+			Expect(report["stages"]).To(HaveLen(2))
+			Expect(report["critical_path"]).To(HaveLen(2))
+			Expect(report["slowest_instances"]).To(HaveLen(2))
+
+			step := report["critical_path"].([]interface{})[1].(map[string]interface{})
+			Expect(step["instance"]).To(Equal("api/def"))
+			Expect(step["error"]).To(Equal("fake-step-err"))
+			Expect(step["started_at"]).To(Equal("1970-01-01T00:17:00Z"))
+		})
+
+		It("prints steps as CSV", func() {
+			opts.Format = "csv"
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ui.Blocks).To(Equal([]string{
+				"stage,step,instance,state,started_at,finished_at,duration,error\n" +
+					"Preparing deployment,Binding deployment,,finished,1970-01-01T00:16:40Z,1970-01-01T00:16:50Z,10,\n" +
+					"Updating instance api,api/abc (0) (canary),api/abc,finished,1970-01-01T00:16:50Z,1970-01-01T00:17:10Z,20,\n" +
+					"Updating instance api,api/def (1),api/def,failed,1970-01-01T00:17:00Z,1970-01-01T00:17:30Z,30,fake-step-err\n",
+			}))
+		})
+
+		It("returns error if format is unknown", func() {
+			opts.Format = "xml"
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected format to be one of 'text', 'json' or 'csv' but was 'xml'"))
+			Expect(director.FindTaskCallCount()).To(Equal(0))
+		})
+
+		It("returns error if task cannot be found", func() {
+			director.FindTaskReturns(nil, errors.New("fake-err"))
+
+			err := act()
+			Expect(err).To(Equal(errors.New("fake-err")))
+		})
+
+		It("returns error if task output cannot be retrieved", func() {
+			task.EventOutputStub = func(reporter boshdir.TaskReporter) error {
+				reporter.TaskFinished(123, "")
+				return errors.New("fake-err")
+			}
+
+			err := act()
+			Expect(err).To(Equal(errors.New("fake-err")))
+			Expect(ui.Tables).To(BeEmpty())
+		})
+
+		It("returns error if events cannot be parsed", func() {
+			task.EventOutputStub = func(reporter boshdir.TaskReporter) error {
+				reporter.TaskOutputChunk(123, []byte("-\n"))
+				reporter.TaskFinished(123, "done")
+				return nil
+			}
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unmarshalling task event"))
+		})
+	})
+})
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"io"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
+
+	cmdconf "github.com/cloudfoundry/bosh-cli/cmd/config"
+	boshuaa "github.com/cloudfoundry/bosh-cli/uaa"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+)
+
+// UAASSOLoginStrategy logs in user that authenticates with UAA
+// via external identity provider, either by entering one-time passcode
+// or by approving access in a browser (authorization code with PKCE)
+type UAASSOLoginStrategy struct {
+	sessionFactory  func(cmdconf.Config) Session
+	listenerFactory func(state string) (boshuaa.CallbackListener, error)
+
+	config  cmdconf.Config
+	ui      boshui.UI
+	uuidGen boshuuid.Generator
+	random  io.Reader
+	browser bool
+
+	logTag string
+	logger boshlog.Logger
+
+	successMsg string
+	failureMsg string
+}
+
+func NewUAASSOLoginStrategy(
+	sessionFactory func(cmdconf.Config) Session,
+	listenerFactory func(state string) (boshuaa.CallbackListener, error),
+	config cmdconf.Config,
+	ui boshui.UI,
+	uuidGen boshuuid.Generator,
+	random io.Reader,
+	browser bool,
+	logger boshlog.Logger,
+) UAASSOLoginStrategy {
+	return UAASSOLoginStrategy{
+		sessionFactory:  sessionFactory,
+		listenerFactory: listenerFactory,
+
+		config:  config,
+		ui:      ui,
+		uuidGen: uuidGen,
+		random:  random,
+		browser: browser,
+
+		logTag: "UAASSOLoginStrategy",
+		logger: logger,
+
+		successMsg: "Successfully authenticated with UAA",
+		failureMsg: "Failed to authenticate with UAA",
+	}
+}
+
+func (c UAASSOLoginStrategy) Try() error {
+	sess := c.sessionFactory(c.config)
+
+	uaa, err := sess.UAA()
+	if err != nil {
+		return err
+	}
+
+	if c.browser {
+		return c.tryBrowser(sess.Environment(), uaa)
+	}
+
+	return c.tryPasscode(sess.Environment(), uaa)
+}
+
+func (c UAASSOLoginStrategy) tryPasscode(environment string, uaa boshuaa.UAA) error {
+	prompts, err := uaa.Prompts()
+	if err != nil {
+		return err
+	}
+
+	var passcodePrompt *boshuaa.Prompt
+
+	for _, prompt := range prompts {
+		if prompt.Key == "passcode" {
+			passcodePrompt = &prompt
+			break
+		}
+	}
+
+	if passcodePrompt == nil {
+		return bosherr.Error("Expected UAA to offer one-time passcode prompt")
+	}
+
+	for {
+		passcode, err := c.ui.AskForPassword(passcodePrompt.Label)
+		if err != nil {
+			return err
+		}
+
+		accessToken, err := uaa.PasscodeGrant(passcode)
+		if err != nil {
+			c.logger.Error(c.logTag, "Failed to get access token: %s", err)
+			c.ui.ErrorLinef("%s", c.failureMsg)
+			continue
+		}
+
+		return c.saveToken(environment, accessToken)
+	}
+}
+
+func (c UAASSOLoginStrategy) tryBrowser(environment string, uaa boshuaa.UAA) error {
+	pkce, err := boshuaa.NewPKCE(c.random)
+	if err != nil {
+		return err
+	}
+
+	state, err := c.uuidGen.Generate()
+	if err != nil {
+		return bosherr.WrapError(err, "Generating authorization state")
+	}
+
+	listener, err := c.listenerFactory(state)
+	if err != nil {
+		return err
+	}
+
+	defer listener.Close()
+
+	redirectURI := listener.RedirectURI()
+
+	c.ui.PrintLinef("To log in, open following URL in a browser:")
+	c.ui.PrintBlock(uaa.AuthorizationCodeURL(redirectURI, state, pkce) + "\n")
+
+	code, err := listener.Code()
+	if err != nil {
+		c.ui.ErrorLinef("%s", c.failureMsg)
+		return err
+	}
+
+	accessToken, err := uaa.AuthorizationCodeGrant(code, redirectURI, pkce)
+	if err != nil {
+		c.ui.ErrorLinef("%s", c.failureMsg)
+		return err
+	}
+
+	return c.saveToken(environment, accessToken)
+}
+
+func (c UAASSOLoginStrategy) saveToken(environment string, accessToken boshuaa.AccessToken) error {
+	creds := cmdconf.Creds{
+		RefreshToken: accessToken.RefreshToken().Value(),
+	}
+
+	err := c.config.SetCredentials(environment, creds).Save()
+	if err != nil {
+		return err
+	}
+
+	c.ui.PrintLinef("%s", c.successMsg)
+
+	return nil
+}
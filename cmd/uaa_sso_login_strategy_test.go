@@ -0,0 +1,257 @@
+package cmd_test
+
+import (
+	"bytes"
+	"errors"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	fakecmd "github.com/cloudfoundry/bosh-cli/cmd/cmdfakes"
+	cmdconf "github.com/cloudfoundry/bosh-cli/cmd/config"
+	fakecmdconf "github.com/cloudfoundry/bosh-cli/cmd/config/configfakes"
+	boshuaa "github.com/cloudfoundry/bosh-cli/uaa"
+	fakeuaa "github.com/cloudfoundry/bosh-cli/uaa/uaafakes"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+)
+
+var _ = Describe("UAASSOLoginStrategy", func() {
+	var (
+		session       *fakecmd.FakeSession
+		config        *fakecmdconf.FakeConfig
+		updatedConfig *fakecmdconf.FakeConfig
+		ui            *fakeui.FakeUI
+		uuidGen       *fakeuuid.FakeGenerator
+		uaa           *fakeuaa.FakeUAA
+		accessToken   *fakeuaa.FakeAccessToken
+
+		listener       *fakeuaa.FakeCallbackListener
+		listenerStates []string
+		listenerErr    error
+	)
+
+	BeforeEach(func() {
+		uaa = &fakeuaa.FakeUAA{}
+
+		session = &fakecmd.FakeSession{}
+		session.UAAReturns(uaa, nil)
+		session.EnvironmentReturns("environment")
+
+		config = &fakecmdconf.FakeConfig{}
+		updatedConfig = &fakecmdconf.FakeConfig{}
+		config.SetCredentialsReturns(updatedConfig)
+
+		ui = &fakeui.FakeUI{}
+		uuidGen = &fakeuuid.FakeGenerator{GeneratedUUID: "state"}
+
+		refreshToken := &fakeuaa.FakeToken{}
+		refreshToken.ValueReturns("refresh-token")
+
+		accessToken = &fakeuaa.FakeAccessToken{}
+		accessToken.RefreshTokenReturns(refreshToken)
+
+		listener = &fakeuaa.FakeCallbackListener{}
+		listener.RedirectURIReturns("http://127.0.0.1:1234/callback")
+		listenerStates = nil
+		listenerErr = nil
+	})
+
+	buildStrategy := func(browser bool) UAASSOLoginStrategy {
+		sessionFactory := func(cmdconf.Config) Session { return session }
+
+		listenerFactory := func(state string) (boshuaa.CallbackListener, error) {
+			listenerStates = append(listenerStates, state)
+			return listener, listenerErr
+		}
+
+		random := bytes.NewReader(make([]byte, 32))
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+
+		return NewUAASSOLoginStrategy(
+			sessionFactory, listenerFactory, config, ui, uuidGen, random, browser, logger)
+	}
+
+	expectSavedRefreshToken := func() {
+		Expect(config.SetCredentialsCallCount()).To(Equal(1))
+
+		environment, creds := config.SetCredentialsArgsForCall(0)
+		Expect(environment).To(Equal("environment"))
+		Expect(creds).To(Equal(cmdconf.Creds{RefreshToken: "refresh-token"}))
+
+		Expect(updatedConfig.SaveCallCount()).To(Equal(1))
+	}
+
+	It("returns error if UAA cannot be built", func() {
+		session.UAAReturns(nil, errors.New("fake-err"))
+
+		err := buildStrategy(false).Try()
+		Expect(err).To(Equal(errors.New("fake-err")))
+	})
+
+	Describe("one-time passcode", func() {
+		var (
+			strategy UAASSOLoginStrategy
+		)
+
+		BeforeEach(func() {
+			strategy = buildStrategy(false)
+
+			uaa.PromptsReturns([]boshuaa.Prompt{
+				{Key: "username", Type: "text", Label: "username-label"},
+				{Key: "passcode", Type: "password", Label: "passcode-label"},
+			}, nil)
+		})
+
+		It("asks for passcode and saves refresh token", func() {
+			uaa.PasscodeGrantReturns(accessToken, nil)
+
+			ui.AskedPasswords = []fakeui.Answer{{Text: "passcode"}}
+
+			err := strategy.Try()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.AskedPasswordLabels).To(Equal([]string{"passcode-label"}))
+			Expect(ui.AskedTextLabels).To(BeEmpty())
+			Expect(uaa.PasscodeGrantArgsForCall(0)).To(Equal("passcode"))
+
+			expectSavedRefreshToken()
+
+			Expect(ui.Said).To(Equal([]string{"Successfully authenticated with UAA"}))
+		})
+
+		It("asks for passcode again if it is rejected", func() {
+			uaa.PasscodeGrantStub = func(passcode string) (boshuaa.AccessToken, error) {
+				if passcode == "passcode2" {
+					return accessToken, nil
+				}
+				return nil, errors.New("fake-err")
+			}
+
+			ui.AskedPasswords = []fakeui.Answer{{Text: "passcode1"}, {Text: "passcode2"}}
+
+			err := strategy.Try()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(uaa.PasscodeGrantCallCount()).To(Equal(2))
+			Expect(ui.Errors).To(Equal([]string{"Failed to authenticate with UAA"}))
+
+			expectSavedRefreshToken()
+		})
+
+		It("returns error if asking for passcode fails", func() {
+			ui.AskedPasswords = []fakeui.Answer{{Error: errors.New("fake-err")}}
+
+			err := strategy.Try()
+			Expect(err).To(Equal(errors.New("fake-err")))
+			Expect(uaa.PasscodeGrantCallCount()).To(Equal(0))
+		})
+
+		It("returns error if UAA does not offer passcode prompt", func() {
+			uaa.PromptsReturns([]boshuaa.Prompt{{Key: "username"}}, nil)
+
+			err := strategy.Try()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected UAA to offer one-time passcode prompt"))
+		})
+
+		It("returns error if prompts cannot be fetched", func() {
+			uaa.PromptsReturns(nil, errors.New("fake-err"))
+
+			err := strategy.Try()
+			Expect(err).To(Equal(errors.New("fake-err")))
+		})
+
+		It("returns error if config cannot be saved", func() {
+			uaa.PasscodeGrantReturns(accessToken, nil)
+			updatedConfig.SaveReturns(errors.New("fake-err"))
+
+			ui.AskedPasswords = []fakeui.Answer{{Text: "passcode"}}
+
+			err := strategy.Try()
+			Expect(err).To(Equal(errors.New("fake-err")))
+			Expect(ui.Said).To(BeEmpty())
+		})
+	})
+
+	Describe("browser", func() {
+		var (
+			strategy UAASSOLoginStrategy
+			pkce     boshuaa.PKCE
+		)
+
+		BeforeEach(func() {
+			strategy = buildStrategy(true)
+
+			var err error
+
+			pkce, err = boshuaa.NewPKCE(bytes.NewReader(make([]byte, 32)))
+			Expect(err).ToNot(HaveOccurred())
+
+			uaa.AuthorizationCodeURLReturns("https://uaa/oauth/authorize?fake")
+		})
+
+		It("prints authorize URL, waits for code and exchanges it for token", func() {
+			listener.CodeReturns("code", nil)
+			uaa.AuthorizationCodeGrantReturns(accessToken, nil)
+
+			err := strategy.Try()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(listenerStates).To(Equal([]string{"state"}))
+
+			redirectURI, state, urlPKCE := uaa.AuthorizationCodeURLArgsForCall(0)
+			Expect(redirectURI).To(Equal("http://127.0.0.1:1234/callback"))
+			Expect(state).To(Equal("state"))
+			Expect(urlPKCE).To(Equal(pkce))
+
+			Expect(ui.Blocks).To(Equal([]string{"https://uaa/oauth/authorize?fake\n"}))
+
+			code, redirectURI, grantPKCE := uaa.AuthorizationCodeGrantArgsForCall(0)
+			Expect(code).To(Equal("code"))
+			Expect(redirectURI).To(Equal("http://127.0.0.1:1234/callback"))
+			Expect(grantPKCE).To(Equal(pkce))
+
+			expectSavedRefreshToken()
+
+			Expect(listener.CloseCallCount()).To(Equal(1))
+		})
+
+		It("returns error if listener cannot be started", func() {
+			listenerErr = errors.New("fake-err")
+
+			err := strategy.Try()
+			Expect(err).To(Equal(errors.New("fake-err")))
+			Expect(uaa.AuthorizationCodeURLCallCount()).To(Equal(0))
+		})
+
+		It("returns error if state cannot be generated", func() {
+			uuidGen.GenerateError = errors.New("fake-err")
+
+			err := strategy.Try()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Generating authorization state"))
+		})
+
+		It("returns error if code is not received", func() {
+			listener.CodeReturns("", errors.New("fake-err"))
+
+			err := strategy.Try()
+			Expect(err).To(Equal(errors.New("fake-err")))
+			Expect(ui.Errors).To(Equal([]string{"Failed to authenticate with UAA"}))
+			Expect(uaa.AuthorizationCodeGrantCallCount()).To(Equal(0))
+			Expect(listener.CloseCallCount()).To(Equal(1))
+		})
+
+		It("returns error if code cannot be exchanged for token", func() {
+			listener.CodeReturns("code", nil)
+			uaa.AuthorizationCodeGrantReturns(nil, errors.New("fake-err"))
+
+			err := strategy.Try()
+			Expect(err).To(Equal(errors.New("fake-err")))
+			Expect(config.SetCredentialsCallCount()).To(Equal(0))
+		})
+	})
+})
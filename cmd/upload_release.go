@@ -5,6 +5,7 @@ import (
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
 	semver "github.com/cppforlife/go-semi-semantic/version"
 
+	bicrypto "github.com/cloudfoundry/bosh-cli/crypto"
 	boshdir "github.com/cloudfoundry/bosh-cli/director"
 	boshrel "github.com/cloudfoundry/bosh-cli/release"
 	boshreldir "github.com/cloudfoundry/bosh-cli/releasedir"
@@ -19,6 +20,7 @@ type UploadReleaseCmd struct {
 	releaseArchiveFactory func(string) boshdir.ReleaseArchive
 
 	cmdRunner boshsys.CmdRunner
+	sha1calc  bicrypto.SHA1Calculator
 	fs        boshsys.FileSystem
 	ui        boshui.UI
 }
@@ -29,6 +31,7 @@ func NewUploadReleaseCmd(
 	director boshdir.Director,
 	releaseArchiveFactory func(string) boshdir.ReleaseArchive,
 	cmdRunner boshsys.CmdRunner,
+	sha1calc bicrypto.SHA1Calculator,
 	fs boshsys.FileSystem,
 	ui boshui.UI,
 ) UploadReleaseCmd {
@@ -40,6 +43,7 @@ func NewUploadReleaseCmd(
 		releaseArchiveFactory: releaseArchiveFactory,
 
 		cmdRunner: cmdRunner,
+		sha1calc:  sha1calc,
 		fs:        fs,
 		ui:        ui,
 	}
@@ -98,6 +102,11 @@ func (c UploadReleaseCmd) uploadFile(opts UploadReleaseOpts) error {
 	path := opts.Args.URL.FilePath()
 
 	if len(path) > 0 {
+		err = verifyFileSHA1(c.sha1calc, path, opts.SHA1, "release")
+		if err != nil {
+			return err
+		}
+
 		release, err = releaseReader.Read(path)
 		if err != nil {
 			return err
@@ -114,8 +123,27 @@ func (c UploadReleaseCmd) uploadFile(opts UploadReleaseOpts) error {
 
 func (c UploadReleaseCmd) uploadRelease(release boshrel.Release, opts UploadReleaseOpts) error {
 	var pkgFpsToSkip []string
+	var uploaded func() (bool, error)
 	var err error
 
+	// Compiled releases may add compiled packages to an existing release
+	// and rebased releases receive a new version hence both are always uploaded
+	if !opts.Fix && !opts.Rebase && !release.IsCompiled() {
+		uploaded = func() (bool, error) {
+			return c.director.HasRelease(release.Name(), release.Version())
+		}
+
+		found, err := uploaded()
+		if err != nil {
+			return err
+		}
+
+		if found {
+			c.ui.PrintLinef("Release '%s/%s' already exists.", release.Name(), release.Version())
+			return nil
+		}
+	}
+
 	if !opts.Fix {
 		pkgFpsToSkip, err = c.director.MatchPackages(release.Manifest(), release.IsCompiled())
 		if err != nil {
@@ -133,7 +161,7 @@ func (c UploadReleaseCmd) uploadRelease(release boshrel.Release, opts UploadRele
 		return bosherr.WrapErrorf(err, "Opening release")
 	}
 
-	return c.director.UploadReleaseFile(file, opts.Rebase, opts.Fix)
+	return c.director.UploadReleaseFile(file, opts.Rebase, opts.Fix, uploaded)
 }
 
 func (c UploadReleaseCmd) uploadIfNecessary(opts UploadReleaseOpts, uploadFunc func(UploadReleaseOpts) error) error {
@@ -9,6 +9,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	. "github.com/cloudfoundry/bosh-cli/cmd"
+	fakecrypto "github.com/cloudfoundry/bosh-cli/crypto/fakes"
 	boshdir "github.com/cloudfoundry/bosh-cli/director"
 	fakedir "github.com/cloudfoundry/bosh-cli/director/directorfakes"
 	boshrel "github.com/cloudfoundry/bosh-cli/release"
@@ -26,6 +27,7 @@ var _ = Describe("UploadReleaseCmd", func() {
 		releaseDir    *fakereldir.FakeReleaseDir
 		director      *fakedir.FakeDirector
 		cmdRunner     *fakesys.FakeCmdRunner
+		sha1calc      *fakecrypto.FakeSha1Calculator
 		fs            *fakesys.FakeFileSystem
 		archive       *fakedir.FakeReleaseArchive
 		ui            *fakeui.FakeUI
@@ -44,6 +46,7 @@ var _ = Describe("UploadReleaseCmd", func() {
 		releaseWriter = &fakerel.FakeWriter{}
 		director = &fakedir.FakeDirector{}
 		cmdRunner = fakesys.NewFakeCmdRunner()
+		sha1calc = fakecrypto.NewFakeSha1Calculator()
 		fs = fakesys.NewFakeFileSystem()
 
 		archive = &fakedir.FakeReleaseArchive{}
@@ -59,7 +62,7 @@ var _ = Describe("UploadReleaseCmd", func() {
 
 		ui = &fakeui.FakeUI{}
 
-		command = NewUploadReleaseCmd(releaseDirFactory, releaseWriter, director, releaseArchiveFactory, cmdRunner, fs, ui)
+		command = NewUploadReleaseCmd(releaseDirFactory, releaseWriter, director, releaseArchiveFactory, cmdRunner, sha1calc, fs, ui)
 	})
 
 	Describe("Run", func() {
@@ -94,7 +97,7 @@ var _ = Describe("UploadReleaseCmd", func() {
 			})
 
 			It("uploads given release even if reader is nil", func() {
-				command = NewUploadReleaseCmd(nil, nil, director, nil, nil, nil, ui)
+				command = NewUploadReleaseCmd(nil, nil, director, nil, nil, nil, nil, ui)
 
 				err := command.Run(opts)
 				Expect(err).ToNot(HaveOccurred())
@@ -214,7 +217,7 @@ var _ = Describe("UploadReleaseCmd", func() {
 			})
 
 			It("returns an error if reader is nil", func() {
-				command = NewUploadReleaseCmd(nil, nil, director, nil, nil, nil, ui)
+				command = NewUploadReleaseCmd(nil, nil, director, nil, nil, nil, nil, ui)
 
 				err := command.Run(opts)
 				Expect(err).To(HaveOccurred())
@@ -245,12 +248,37 @@ var _ = Describe("UploadReleaseCmd", func() {
 				Expect(director.MatchPackagesCallCount()).To(Equal(1))
 				Expect(director.UploadReleaseFileCallCount()).To(Equal(1))
 
-				file, rebase, fix := director.UploadReleaseFileArgsForCall(0)
+				file, rebase, fix, _ := director.UploadReleaseFileArgsForCall(0)
 				Expect(file.(*fakesys.FakeFile).Name()).To(Equal("/archive-path"))
 				Expect(rebase).To(BeFalse())
 				Expect(fix).To(BeFalse())
 			})
 
+			It("checks if release exists before director retries upload", func() {
+				release.VersionStub = func() string { return "1.1" }
+
+				releaseReader.ReadReturns(release, nil)
+				releaseWriter.WriteReturns("/archive-path", nil)
+
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				_, _, _, uploaded := director.UploadReleaseFileArgsForCall(0)
+				Expect(uploaded).ToNot(BeNil())
+
+				director.HasReleaseReturns(true, nil)
+
+				found, err := uploaded()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeTrue())
+
+				Expect(director.HasReleaseCallCount()).To(Equal(2))
+
+				name, version := director.HasReleaseArgsForCall(1)
+				Expect(name).To(Equal("rel"))
+				Expect(version).To(Equal("1.1"))
+			})
+
 			It("uploads given release with a fix flag hence does not filter out any packages", func() {
 				opts.Fix = true
 
@@ -271,10 +299,100 @@ var _ = Describe("UploadReleaseCmd", func() {
 				Expect(director.MatchPackagesCallCount()).To(Equal(0))
 				Expect(director.UploadReleaseFileCallCount()).To(Equal(1))
 
-				file, rebase, fix := director.UploadReleaseFileArgsForCall(0)
+				file, rebase, fix, uploaded := director.UploadReleaseFileArgsForCall(0)
 				Expect(file.(*fakesys.FakeFile).Name()).To(Equal("/archive-path"))
 				Expect(rebase).To(BeFalse())
 				Expect(fix).To(BeTrue())
+				Expect(uploaded).To(BeNil())
+			})
+
+			It("verifies release SHA1 before uploading", func() {
+				opts.SHA1 = "fake-sha1"
+
+				sha1calc.SetCalculateBehavior(map[string]fakecrypto.CalculateInput{
+					"./some-file.tgz": fakecrypto.CalculateInput{Sha1: "fake-sha1"},
+				})
+
+				releaseReader.ReadReturns(release, nil)
+				releaseWriter.WriteReturns("/archive-path", nil)
+
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(director.UploadReleaseFileCallCount()).To(Equal(1))
+			})
+
+			It("returns error and does not upload release if release SHA1 does not match", func() {
+				opts.SHA1 = "fake-sha1"
+
+				sha1calc.SetCalculateBehavior(map[string]fakecrypto.CalculateInput{
+					"./some-file.tgz": fakecrypto.CalculateInput{Sha1: "other-sha1"},
+				})
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Expected release SHA1 to be 'fake-sha1' but was 'other-sha1'"))
+
+				Expect(releaseReader.ReadCallCount()).To(Equal(0))
+				Expect(director.UploadReleaseFileCallCount()).To(Equal(0))
+			})
+
+			It("returns error if calculating release SHA1 fails", func() {
+				opts.SHA1 = "fake-sha1"
+
+				sha1calc.SetCalculateBehavior(map[string]fakecrypto.CalculateInput{
+					"./some-file.tgz": fakecrypto.CalculateInput{Err: errors.New("fake-err")},
+				})
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-err"))
+
+				Expect(director.UploadReleaseFileCallCount()).To(Equal(0))
+			})
+
+			It("does not upload release if director already has it", func() {
+				release.VersionStub = func() string { return "1.1" }
+				releaseReader.ReadReturns(release, nil)
+
+				director.HasReleaseReturns(true, nil)
+
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				name, version := director.HasReleaseArgsForCall(0)
+				Expect(name).To(Equal("rel"))
+				Expect(version).To(Equal("1.1"))
+
+				Expect(releaseWriter.WriteCallCount()).To(Equal(0))
+				Expect(director.UploadReleaseFileCallCount()).To(Equal(0))
+
+				Expect(ui.Said).To(Equal([]string{"Release 'rel/1.1' already exists."}))
+			})
+
+			It("uploads compiled release even if director already has release", func() {
+				release.IsCompiledStub = func() bool { return true }
+				releaseReader.ReadReturns(release, nil)
+
+				director.HasReleaseReturns(true, nil)
+
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(director.HasReleaseCallCount()).To(Equal(0))
+				Expect(director.UploadReleaseFileCallCount()).To(Equal(1))
+			})
+
+			It("returns error if checking release existence fails", func() {
+				releaseReader.ReadReturns(release, nil)
+
+				director.HasReleaseReturns(false, errors.New("fake-err"))
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-err"))
+
+				Expect(director.UploadReleaseFileCallCount()).To(Equal(0))
 			})
 
 			It("returns error if opening file fails", func() {
@@ -323,7 +441,7 @@ var _ = Describe("UploadReleaseCmd", func() {
 			})
 
 			It("returns an error if reader is nil", func() {
-				command = NewUploadReleaseCmd(nil, nil, director, nil, cmdRunner, fs, ui)
+				command = NewUploadReleaseCmd(nil, nil, director, nil, cmdRunner, nil, fs, ui)
 
 				err := command.Run(opts)
 				Expect(err).To(HaveOccurred())
@@ -364,7 +482,7 @@ var _ = Describe("UploadReleaseCmd", func() {
 				Expect(director.MatchPackagesCallCount()).To(Equal(1))
 				Expect(director.UploadReleaseFileCallCount()).To(Equal(1))
 
-				file, rebase, fix := director.UploadReleaseFileArgsForCall(0)
+				file, rebase, fix, _ := director.UploadReleaseFileArgsForCall(0)
 				Expect(file.(*fakesys.FakeFile).Name()).To(Equal("/archive-path"))
 				Expect(rebase).To(BeFalse())
 				Expect(fix).To(BeFalse())
@@ -391,7 +509,7 @@ var _ = Describe("UploadReleaseCmd", func() {
 				Expect(director.MatchPackagesCallCount()).To(Equal(0))
 				Expect(director.UploadReleaseFileCallCount()).To(Equal(1))
 
-				file, rebase, fix := director.UploadReleaseFileArgsForCall(0)
+				file, rebase, fix, _ := director.UploadReleaseFileArgsForCall(0)
 				Expect(file.(*fakesys.FakeFile).Name()).To(Equal("/archive-path"))
 				Expect(rebase).To(BeFalse())
 				Expect(fix).To(BeTrue())
@@ -503,7 +621,7 @@ var _ = Describe("UploadReleaseCmd", func() {
 				Expect(director.MatchPackagesCallCount()).To(Equal(1))
 				Expect(director.UploadReleaseFileCallCount()).To(Equal(1))
 
-				file, rebase, fix := director.UploadReleaseFileArgsForCall(0)
+				file, rebase, fix, _ := director.UploadReleaseFileArgsForCall(0)
 				Expect(file.(*fakesys.FakeFile).Name()).To(Equal("/archive-path"))
 				Expect(rebase).To(BeFalse())
 				Expect(fix).To(BeFalse())
@@ -526,7 +644,7 @@ var _ = Describe("UploadReleaseCmd", func() {
 				Expect(director.MatchPackagesCallCount()).To(Equal(0))
 				Expect(director.UploadReleaseFileCallCount()).To(Equal(1))
 
-				file, rebase, fix := director.UploadReleaseFileArgsForCall(0)
+				file, rebase, fix, _ := director.UploadReleaseFileArgsForCall(0)
 				Expect(file.(*fakesys.FakeFile).Name()).To(Equal("/archive-path"))
 				Expect(rebase).To(BeFalse())
 				Expect(fix).To(BeTrue())
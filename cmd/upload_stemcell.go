@@ -4,6 +4,7 @@ import (
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	semver "github.com/cppforlife/go-semi-semantic/version"
 
+	bicrypto "github.com/cloudfoundry/bosh-cli/crypto"
 	boshdir "github.com/cloudfoundry/bosh-cli/director"
 	biui "github.com/cloudfoundry/bosh-cli/ui"
 )
@@ -11,6 +12,7 @@ import (
 type UploadStemcellCmd struct {
 	director               boshdir.Director
 	stemcellArchiveFactory func(string) boshdir.StemcellArchive
+	sha1calc               bicrypto.SHA1Calculator
 
 	ui biui.UI
 }
@@ -18,11 +20,14 @@ type UploadStemcellCmd struct {
 func NewUploadStemcellCmd(
 	director boshdir.Director,
 	stemcellArchiveFactory func(string) boshdir.StemcellArchive,
+	sha1calc bicrypto.SHA1Calculator,
 	ui biui.UI,
 ) UploadStemcellCmd {
 	return UploadStemcellCmd{
 		director:               director,
 		stemcellArchiveFactory: stemcellArchiveFactory,
+		sha1calc:               sha1calc,
+
 		ui: ui,
 	}
 }
@@ -32,7 +37,7 @@ func (c UploadStemcellCmd) Run(opts UploadStemcellOpts) error {
 		return c.uploadRemote(string(opts.Args.URL), opts)
 	}
 
-	return c.uploadFile(opts.Args.URL.FilePath(), opts.Fix)
+	return c.uploadFile(opts.Args.URL.FilePath(), opts.SHA1, opts.Fix)
 }
 
 func (c UploadStemcellCmd) uploadRemote(url string, opts UploadStemcellOpts) error {
@@ -46,7 +51,12 @@ func (c UploadStemcellCmd) uploadRemote(url string, opts UploadStemcellOpts) err
 	return c.director.UploadStemcellURL(url, opts.SHA1, opts.Fix)
 }
 
-func (c UploadStemcellCmd) uploadFile(path string, fix bool) error {
+func (c UploadStemcellCmd) uploadFile(path, sha1 string, fix bool) error {
+	err := verifyFileSHA1(c.sha1calc, path, sha1, "stemcell")
+	if err != nil {
+		return err
+	}
+
 	archive := c.stemcellArchiveFactory(path)
 
 	name, version, err := archive.Info()
@@ -64,7 +74,13 @@ func (c UploadStemcellCmd) uploadFile(path string, fix bool) error {
 		return bosherr.WrapErrorf(err, "Opening stemcell")
 	}
 
-	return c.director.UploadStemcellFile(file, fix)
+	var uploaded func() (bool, error)
+
+	if !fix {
+		uploaded = func() (bool, error) { return c.director.HasStemcell(name, version) }
+	}
+
+	return c.director.UploadStemcellFile(file, fix, uploaded)
 }
 
 func (c UploadStemcellCmd) needToUpload(name, version string, fix bool) (bool, error) {
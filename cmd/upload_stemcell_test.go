@@ -9,6 +9,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	. "github.com/cloudfoundry/bosh-cli/cmd"
+	fakecrypto "github.com/cloudfoundry/bosh-cli/crypto/fakes"
 	boshdir "github.com/cloudfoundry/bosh-cli/director"
 	fakedir "github.com/cloudfoundry/bosh-cli/director/directorfakes"
 	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
@@ -19,6 +20,7 @@ var _ = Describe("UploadStemcellCmd", func() {
 		director *fakedir.FakeDirector
 		fs       *fakesys.FakeFileSystem
 		archive  *fakedir.FakeStemcellArchive
+		sha1calc *fakecrypto.FakeSha1Calculator
 		ui       *fakeui.FakeUI
 		command  UploadStemcellCmd
 	)
@@ -27,6 +29,7 @@ var _ = Describe("UploadStemcellCmd", func() {
 		director = &fakedir.FakeDirector{}
 		fs = fakesys.NewFakeFileSystem()
 		archive = &fakedir.FakeStemcellArchive{}
+		sha1calc = fakecrypto.NewFakeSha1Calculator()
 		ui = &fakeui.FakeUI{}
 
 		stemcellArchiveFactory := func(path string) boshdir.StemcellArchive {
@@ -38,7 +41,7 @@ var _ = Describe("UploadStemcellCmd", func() {
 			return archive
 		}
 
-		command = NewUploadStemcellCmd(director, stemcellArchiveFactory, ui)
+		command = NewUploadStemcellCmd(director, stemcellArchiveFactory, sha1calc, ui)
 	})
 
 	Describe("Run", func() {
@@ -171,11 +174,31 @@ var _ = Describe("UploadStemcellCmd", func() {
 
 				Expect(director.UploadStemcellFileCallCount()).To(Equal(1))
 
-				file, fix := director.UploadStemcellFileArgsForCall(0)
+				file, fix, _ := director.UploadStemcellFileArgsForCall(0)
 				Expect(file.(*fakesys.FakeFile).Name()).To(Equal("./some-file.tgz"))
 				Expect(fix).To(BeFalse())
 			})
 
+			It("checks if stemcell exists before director retries upload", func() {
+				archive.InfoReturns("stem", "3421.9", nil)
+
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				_, _, uploaded := director.UploadStemcellFileArgsForCall(0)
+				Expect(uploaded).ToNot(BeNil())
+
+				director.HasStemcellReturns(true, nil)
+
+				found, err := uploaded()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeTrue())
+
+				name, version := director.HasStemcellArgsForCall(1)
+				Expect(name).To(Equal("stem"))
+				Expect(version).To(Equal("3421.9"))
+			})
+
 			It("uploads given stemcell with a fix flag without checking if stemcell exists", func() {
 				opts.Fix = true
 
@@ -186,9 +209,10 @@ var _ = Describe("UploadStemcellCmd", func() {
 
 				Expect(director.UploadStemcellFileCallCount()).To(Equal(1))
 
-				file, fix := director.UploadStemcellFileArgsForCall(0)
+				file, fix, uploaded := director.UploadStemcellFileArgsForCall(0)
 				Expect(file.(*fakesys.FakeFile).Name()).To(Equal("./some-file.tgz"))
 				Expect(fix).To(BeTrue())
+				Expect(uploaded).To(BeNil())
 			})
 
 			It("does not upload stemcell if name and version match existing stemcell", func() {
@@ -219,7 +243,7 @@ var _ = Describe("UploadStemcellCmd", func() {
 
 				Expect(director.UploadStemcellFileCallCount()).To(Equal(1))
 
-				file, fix := director.UploadStemcellFileArgsForCall(0)
+				file, fix, _ := director.UploadStemcellFileArgsForCall(0)
 				Expect(file.(*fakesys.FakeFile).Name()).To(Equal("./some-file.tgz"))
 				Expect(fix).To(BeFalse())
 
@@ -230,6 +254,48 @@ var _ = Describe("UploadStemcellCmd", func() {
 				Expect(ui.Said).To(BeEmpty())
 			})
 
+			It("verifies stemcell SHA1 before uploading", func() {
+				opts.SHA1 = "fake-sha1"
+
+				sha1calc.SetCalculateBehavior(map[string]fakecrypto.CalculateInput{
+					"./some-file.tgz": fakecrypto.CalculateInput{Sha1: "fake-sha1"},
+				})
+
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(director.UploadStemcellFileCallCount()).To(Equal(1))
+			})
+
+			It("returns error and does not upload stemcell if stemcell SHA1 does not match", func() {
+				opts.SHA1 = "fake-sha1"
+
+				sha1calc.SetCalculateBehavior(map[string]fakecrypto.CalculateInput{
+					"./some-file.tgz": fakecrypto.CalculateInput{Sha1: "other-sha1"},
+				})
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Expected stemcell SHA1 to be 'fake-sha1' but was 'other-sha1'"))
+
+				Expect(director.HasStemcellCallCount()).To(Equal(0))
+				Expect(director.UploadStemcellFileCallCount()).To(Equal(0))
+			})
+
+			It("returns error if calculating stemcell SHA1 fails", func() {
+				opts.SHA1 = "fake-sha1"
+
+				sha1calc.SetCalculateBehavior(map[string]fakecrypto.CalculateInput{
+					"./some-file.tgz": fakecrypto.CalculateInput{Err: errors.New("fake-err")},
+				})
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-err"))
+
+				Expect(director.UploadStemcellFileCallCount()).To(Equal(0))
+			})
+
 			It("returns error if retrieving stemcell archive info fails", func() {
 				archive.InfoReturns("", "", errors.New("fake-err"))
 
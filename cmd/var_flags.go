@@ -2,17 +2,20 @@ package cmd
 
 import (
 	cfgtypes "config_server/types"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 
+	cmdconf "github.com/cloudfoundry/bosh-cli/cmd/config"
 	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
 )
 
 // Shared
 type VarFlags struct {
-	VarKVs      []boshtpl.VarKV       `long:"var"        short:"v" value-name:"VAR=VALUE" description:"Set variable"`
-	VarFiles    []boshtpl.VarFileArg  `long:"var-file"             value-name:"VAR=PATH"  description:"Set variable to file contents"`
-	VarsFiles   []boshtpl.VarsFileArg `long:"vars-file"  short:"l" value-name:"PATH"      description:"Load variables from a YAML file"`
-	VarsEnvs    []boshtpl.VarsEnvArg  `long:"vars-env"             value-name:"PREFIX"    description:"Load variables from environment variables (e.g.: 'MY' to load MY_var=value)"`
-	VarsFSStore VarsFSStore           `long:"vars-store"           value-name:"PATH"      description:"Load/save variables from/to a YAML file"`
+	VarKVs       []boshtpl.VarKV       `long:"var"            short:"v" value-name:"VAR=VALUE" description:"Set variable"`
+	VarFiles     []boshtpl.VarFileArg  `long:"var-file"                 value-name:"VAR=PATH"  description:"Set variable to file contents"`
+	VarsFiles    []boshtpl.VarsFileArg `long:"vars-file"      short:"l" value-name:"PATH"      description:"Load variables from a YAML file"`
+	VarsEnvs     []boshtpl.VarsEnvArg  `long:"vars-env"                 value-name:"PREFIX"    description:"Load variables from environment variables (e.g.: 'MY' to load MY_var=value)"`
+	VarsFSStore  VarsFSStore           `long:"vars-store"               value-name:"PATH"      description:"Load/save variables from/to a YAML file or a credential store (credhub://host/path, vault://host/path)"`
+	VarsStoreKey VarsStoreKeyArg       `long:"vars-store-key"           value-name:"file:PATH|KEY" description:"Encrypt vars store file with a key from a file (file:PATH) or a value" env:"BOSH_VARS_STORE_KEY"`
 }
 
 func (f VarFlags) AsVariables() boshtpl.Variables {
@@ -33,6 +36,7 @@ func (f VarFlags) AsVariables() boshtpl.Variables {
 	}
 
 	store := &f.VarsFSStore
+	store.EncryptionKey = f.VarsStoreKey.Key
 
 	if f.VarsFSStore.IsSet() {
 		firstToUse = append(firstToUse, store)
@@ -47,6 +51,15 @@ func (f VarFlags) AsVariables() boshtpl.Variables {
 	return vars
 }
 
+// ConfigureVarsStore connects vars store to a credential store if one was specified
+func (f *VarFlags) ConfigureVarsStore(configFunc func() cmdconf.Config, logger boshlog.Logger) error {
+	if !f.VarsFSStore.IsRemote() {
+		return nil
+	}
+
+	return f.VarsFSStore.ConfigureRemote(configFunc(), logger)
+}
+
 func (f VarFlags) kvsAsVars() boshtpl.Variables {
 	vars := boshtpl.StaticVariables{}
 
@@ -3,9 +3,12 @@ package cmd
 import (
 	cfgtypes "config_server/types"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
 	"gopkg.in/yaml.v2"
 
+	cmdconf "github.com/cloudfoundry/bosh-cli/cmd/config"
+	"github.com/cloudfoundry/bosh-cli/credstore"
 	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
 )
 
@@ -14,14 +17,53 @@ type VarsFSStore struct {
 
 	ValueGeneratorFactory cfgtypes.ValueGeneratorFactory
 
+	// When set, file is kept encrypted; existing plaintext files are encrypted on next save
+	EncryptionKey []byte
+
 	path string
+
+	// Set when a credential store URL (e.g. credhub://host/path) is given instead of a path
+	url    string
+	client credstore.Client
 }
 
 var _ boshtpl.Variables = VarsFSStore{}
 
-func (s VarsFSStore) IsSet() bool { return len(s.path) > 0 }
+func (s VarsFSStore) IsSet() bool { return len(s.path) > 0 || len(s.url) > 0 }
+
+func (s VarsFSStore) IsRemote() bool { return len(s.url) > 0 }
+
+// ConfigureRemote connects to a credential store using CA certificate and token from CLI config
+func (s *VarsFSStore) ConfigureRemote(config cmdconf.Config, logger boshlog.Logger) error {
+	storeConfig, err := credstore.NewConfigFromURL(s.url)
+	if err != nil {
+		return err
+	}
+
+	settings := config.CredentialStore(s.url)
+	storeConfig.CACert = settings.CACert
+	storeConfig.Token = settings.Token
+
+	client, err := credstore.NewFactory(logger).New(storeConfig)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Connecting to credential store '%s'", s.url)
+	}
+
+	(*s).client = client
+
+	return nil
+}
 
 func (s VarsFSStore) Get(varDef boshtpl.VariableDefinition) (interface{}, bool, error) {
+	if s.IsRemote() {
+		remoteStore, err := s.remoteStore()
+		if err != nil {
+			return nil, false, err
+		}
+
+		return remoteStore.Get(varDef)
+	}
+
 	vars, err := s.load()
 	if err != nil {
 		return nil, false, err
@@ -45,6 +87,15 @@ func (s VarsFSStore) Get(varDef boshtpl.VariableDefinition) (interface{}, bool,
 }
 
 func (s VarsFSStore) List() ([]boshtpl.VariableDefinition, error) {
+	if s.IsRemote() {
+		remoteStore, err := s.remoteStore()
+		if err != nil {
+			return nil, err
+		}
+
+		return remoteStore.List()
+	}
+
 	vars, err := s.load()
 	if err != nil {
 		return nil, err
@@ -53,6 +104,33 @@ func (s VarsFSStore) List() ([]boshtpl.VariableDefinition, error) {
 	return vars.List()
 }
 
+// Regenerate replaces existing value with a newly generated one based on variable definition
+func (s VarsFSStore) Regenerate(varDef boshtpl.VariableDefinition) (interface{}, error) {
+	if s.IsRemote() {
+		remoteStore, err := s.remoteStore()
+		if err != nil {
+			return nil, err
+		}
+
+		return remoteStore.Regenerate(varDef)
+	}
+
+	val, err := s.generateAndSet(varDef)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Generating variable '%s'", varDef.Name)
+	}
+
+	return val, nil
+}
+
+func (s VarsFSStore) remoteStore() (VarsRemoteStore, error) {
+	if s.client == nil {
+		return VarsRemoteStore{}, bosherr.Errorf("Expected credential store '%s' to be configured", s.url)
+	}
+
+	return NewVarsRemoteStore(s.client, s.ValueGeneratorFactory), nil
+}
+
 func (s VarsFSStore) generateAndSet(varDef boshtpl.VariableDefinition) (interface{}, error) {
 	generator, err := s.ValueGeneratorFactory.GetGenerator(varDef.Type)
 	if err != nil {
@@ -92,6 +170,18 @@ func (s VarsFSStore) load() (boshtpl.StaticVariables, error) {
 			return vars, err
 		}
 
+		if isEncryptedVarsStore(bytes) {
+			if len(s.EncryptionKey) == 0 {
+				return vars, bosherr.Errorf(
+					"Expected vars store key to decrypt encrypted variables file store '%s'", s.path)
+			}
+
+			bytes, err = decryptVarsStore(s.EncryptionKey, bytes)
+			if err != nil {
+				return vars, bosherr.WrapErrorf(err, "Decrypting variables file store '%s'", s.path)
+			}
+		}
+
 		err = yaml.Unmarshal(bytes, &vars)
 		if err != nil {
 			return vars, bosherr.WrapErrorf(err, "Deserializing variables file store '%s'", s.path)
@@ -107,19 +197,67 @@ func (s VarsFSStore) save(vars boshtpl.StaticVariables) error {
 		return bosherr.WrapErrorf(err, "Serializing variables")
 	}
 
-	err = s.FS.WriteFile(s.path, bytes)
+	if len(s.EncryptionKey) > 0 {
+		bytes, err = encryptVarsStore(s.EncryptionKey, bytes)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Encrypting variables")
+		}
+	}
+
+	// Write next to the store and rename so that store is never left partially written
+	tmpPath := s.path + ".tmp"
+
+	err = s.FS.WriteFile(tmpPath, bytes)
 	if err != nil {
 		return bosherr.WrapErrorf(err, "Writing variables to file store '%s'", s.path)
 	}
 
+	err = s.FS.Rename(tmpPath, s.path)
+	if err != nil {
+		_ = s.FS.RemoveAll(tmpPath)
+		return bosherr.WrapErrorf(err, "Replacing file store '%s'", s.path)
+	}
+
 	return nil
 }
 
+// Rekey re-encrypts file store with a new key; empty key saves it as plaintext
+func (s VarsFSStore) Rekey(newKey []byte) error {
+	if s.IsRemote() {
+		return bosherr.Errorf("Expected vars store '%s' to be a file", s.url)
+	}
+
+	if !s.FS.FileExists(s.path) {
+		return bosherr.Errorf("Expected variables file store '%s' to exist", s.path)
+	}
+
+	vars, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	s.EncryptionKey = newKey
+
+	return s.save(vars)
+}
+
 func (s *VarsFSStore) UnmarshalFlag(data string) error {
 	if len(data) == 0 {
 		return bosherr.Errorf("Expected file path to be non-empty")
 	}
 
+	if credstore.IsURL(data) {
+		_, err := credstore.NewConfigFromURL(data)
+		if err != nil {
+			return err
+		}
+
+		(*s).url = data
+		(*s).ValueGeneratorFactory = cfgtypes.NewValueGeneratorConcrete(nil)
+
+		return nil
+	}
+
 	absPath, err := s.FS.ExpandPath(data)
 	if err != nil {
 		return bosherr.WrapErrorf(err, "Getting absolute path '%s'", data)
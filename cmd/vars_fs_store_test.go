@@ -1,15 +1,25 @@
 package cmd_test
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 
 	fakecfgtypes "config_server/types/fakes"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"golang.org/x/crypto/pbkdf2"
 
 	. "github.com/cloudfoundry/bosh-cli/cmd"
+	cmdconf "github.com/cloudfoundry/bosh-cli/cmd/config"
+	fakecmdconf "github.com/cloudfoundry/bosh-cli/cmd/config/configfakes"
 	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
 )
 
@@ -60,6 +70,27 @@ var _ = Describe("VarsFSStore", func() {
 				Expect(fs.ReadFileString("/file")).To(Equal(fmt.Sprintf("key: val\nkey2: %s\n", val.(string))))
 			})
 
+			It("saves file by renaming temporary file next to it", func() {
+				_, _, err := store.Get(boshtpl.VariableDefinition{Name: "key2", Type: "password"})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fs.RenameOldPaths).To(Equal([]string{"/file.tmp"}))
+				Expect(fs.RenameNewPaths).To(Equal([]string{"/file"}))
+				Expect(fs.FileExists("/file.tmp")).To(BeFalse())
+			})
+
+			It("keeps existing file and removes temporary file if renaming fails", func() {
+				fs.RenameError = errors.New("fake-err")
+
+				_, _, err := store.Get(boshtpl.VariableDefinition{Name: "key2", Type: "password"})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Replacing file store '/file'"))
+				Expect(err.Error()).To(ContainSubstring("fake-err"))
+
+				Expect(fs.ReadFileString("/file")).To(Equal("key: val"))
+				Expect(fs.FileExists("/file.tmp")).To(BeFalse())
+			})
+
 			It("returns error if variable type is not known", func() {
 				val, found, err := store.Get(boshtpl.VariableDefinition{Name: "key2", Type: "unknown"})
 				Expect(val).To(BeNil())
@@ -160,6 +191,114 @@ var _ = Describe("VarsFSStore", func() {
 		})
 	})
 
+	Describe("Regenerate", func() {
+		BeforeEach(func() {
+			err := (&store).UnmarshalFlag("/file")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("replaces existing value with a newly generated one", func() {
+			fs.WriteFileString("/file", "key: val\nkey2: val2")
+
+			val, err := store.Regenerate(boshtpl.VariableDefinition{Name: "key", Type: "password"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(val).ToNot(Equal("val"))
+
+			Expect(fs.ReadFileString("/file")).To(Equal(fmt.Sprintf("key: %s\nkey2: val2\n", val.(string))))
+		})
+
+		It("returns error if variable type is not known", func() {
+			_, err := store.Regenerate(boshtpl.VariableDefinition{Name: "key", Type: "unknown"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Generating variable 'key': Unsupported value type: unknown"))
+		})
+	})
+
+	Describe("encryption", func() {
+		BeforeEach(func() {
+			err := (&store).UnmarshalFlag("/file")
+			Expect(err).ToNot(HaveOccurred())
+
+			store.EncryptionKey = []byte("fake-key")
+		})
+
+		It("stores generated values encrypted and reads them back", func() {
+			val, found, err := store.Get(boshtpl.VariableDefinition{Name: "key", Type: "password"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+
+			contents, err := fs.ReadFileString("/file")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(HavePrefix("$BOSH_VARS_STORE;1.0;AES256-GCM\n"))
+			Expect(contents).ToNot(ContainSubstring(val.(string)))
+
+			readVal, found, err := store.Get(boshtpl.VariableDefinition{Name: "key"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(readVal).To(Equal(val))
+
+			defs, err := store.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(defs).To(Equal([]boshtpl.VariableDefinition{{Name: "key"}}))
+		})
+
+		It("encrypts existing plaintext store when new values are saved", func() {
+			fs.WriteFileString("/file", "key: val")
+
+			_, _, err := store.Get(boshtpl.VariableDefinition{Name: "key2", Type: "password"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.ReadFileString("/file")).ToNot(ContainSubstring("key: val"))
+
+			val, _, err := store.Get(boshtpl.VariableDefinition{Name: "key"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(val).To(Equal("val"))
+		})
+
+		It("reads store encrypted with AES-256-GCM key derived by PBKDF2-HMAC-SHA256", func() {
+			// Salt '0123456789abcdef', nonce '0123456789ab', 100000 iterations
+			fs.WriteFileString("/file", "$BOSH_VARS_STORE;1.0;AES256-GCM\n"+
+				"MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYpZjIRTxk5z4DfplL/FRQu6alZc04stKxhc=\n")
+
+			val, found, err := store.Get(boshtpl.VariableDefinition{Name: "key"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(val).To(Equal("val"))
+		})
+
+		It("derives keys matching PBKDF2-HMAC-SHA256 test vectors from RFC 7914", func() {
+			Expect(hex.EncodeToString(pbkdf2.Key([]byte("passwd"), []byte("salt"), 1, 64, sha256.New))).To(Equal(
+				"55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+					"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"))
+
+			Expect(hex.EncodeToString(pbkdf2.Key([]byte("Password"), []byte("NaCl"), 80000, 64, sha256.New))).To(Equal(
+				"4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56" +
+					"a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"))
+		})
+
+		It("returns error if key is not provided for encrypted store", func() {
+			_, _, err := store.Get(boshtpl.VariableDefinition{Name: "key", Type: "password"})
+			Expect(err).ToNot(HaveOccurred())
+
+			store.EncryptionKey = nil
+
+			_, _, err = store.Get(boshtpl.VariableDefinition{Name: "key"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected vars store key to decrypt encrypted variables file store '/file'"))
+		})
+
+		It("returns error if key does not match", func() {
+			_, _, err := store.Get(boshtpl.VariableDefinition{Name: "key", Type: "password"})
+			Expect(err).ToNot(HaveOccurred())
+
+			store.EncryptionKey = []byte("wrong-key")
+
+			_, err = store.List()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("key does not match or contents were modified"))
+		})
+	})
+
 	Describe("IsSet", func() {
 		It("returns true if store is configured with file path", func() {
 			err := (&store).UnmarshalFlag("/file")
@@ -167,11 +306,95 @@ var _ = Describe("VarsFSStore", func() {
 			Expect(store.IsSet()).To(BeTrue())
 		})
 
+		It("returns true if store is configured with credential store url", func() {
+			err := (&store).UnmarshalFlag("credhub://host/path")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(store.IsSet()).To(BeTrue())
+			Expect(store.IsRemote()).To(BeTrue())
+		})
+
 		It("returns false if store is not configured", func() {
 			Expect(store.IsSet()).To(BeFalse())
 		})
 	})
 
+	Describe("ConfigureRemote", func() {
+		var (
+			server *ghttp.Server
+			config *fakecmdconf.FakeConfig
+			logger boshlog.Logger
+		)
+
+		BeforeEach(func() {
+			server = ghttp.NewTLSServer()
+			config = &fakecmdconf.FakeConfig{}
+			logger = boshlog.NewLogger(boshlog.LevelNone)
+
+			caCert := pem.EncodeToMemory(&pem.Block{
+				Type:  "CERTIFICATE",
+				Bytes: server.HTTPTestServer.Certificate().Raw,
+			})
+
+			config.CredentialStoreReturns(cmdconf.CredentialStore{CACert: string(caCert), Token: "fake-token"})
+
+			storeURL := strings.Replace(server.URL(), "https://", "vault://", 1) + "/secret/env"
+
+			err := (&store).UnmarshalFlag(storeURL)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("gets and generates variables in credential store using settings from config", func() {
+			err := (&store).ConfigureRemote(config, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.CredentialStoreArgsForCall(0)).To(HavePrefix("vault://"))
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/secret/env/key"),
+					ghttp.VerifyHeaderKV("X-Vault-Token", "fake-token"),
+					ghttp.RespondWith(http.StatusOK, `{"data":{"value":"val"}}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/secret/env/key2"),
+					ghttp.RespondWith(http.StatusNotFound, ""),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v1/secret/env/key2"),
+					ghttp.RespondWith(http.StatusNoContent, ""),
+				),
+			)
+
+			val, found, err := store.Get(boshtpl.VariableDefinition{Name: "key"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(val).To(Equal("val"))
+
+			val, found, err = store.Get(boshtpl.VariableDefinition{Name: "key2", Type: "password"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(len(val.(string))).To(BeNumerically(">", 10))
+		})
+
+		It("returns error if token is not configured", func() {
+			config.CredentialStoreReturns(cmdconf.CredentialStore{})
+
+			err := (&store).ConfigureRemote(config, logger)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Missing 'Token'"))
+		})
+
+		It("returns error when getting variables before credential store is configured", func() {
+			_, _, err := store.Get(boshtpl.VariableDefinition{Name: "key"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("to be configured"))
+		})
+	})
+
 	Describe("UnmarshalFlag", func() {
 		It("returns error if file path is empty", func() {
 			err := (&store).UnmarshalFlag("")
@@ -179,6 +402,19 @@ var _ = Describe("VarsFSStore", func() {
 			Expect(err.Error()).To(Equal("Expected file path to be non-empty"))
 		})
 
+		It("does not expand credential store urls", func() {
+			fs.ExpandPathErr = errors.New("fake-err")
+
+			err := (&store).UnmarshalFlag("vault://host/secret")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns error if credential store url is not valid", func() {
+			err := (&store).UnmarshalFlag("vault:///secret")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected to extract host"))
+		})
+
 		It("returns error if path cannot be expanded", func() {
 			fs.ExpandPathErr = errors.New("fake-err")
 
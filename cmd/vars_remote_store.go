@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	cfgtypes "config_server/types"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	"github.com/cloudfoundry/bosh-cli/credstore"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+)
+
+// VarsRemoteStore keeps variables in an external credential store such as CredHub or Vault
+type VarsRemoteStore struct {
+	client                credstore.Client
+	valueGeneratorFactory cfgtypes.ValueGeneratorFactory
+}
+
+var _ boshtpl.Variables = VarsRemoteStore{}
+
+func NewVarsRemoteStore(client credstore.Client, valueGeneratorFactory cfgtypes.ValueGeneratorFactory) VarsRemoteStore {
+	return VarsRemoteStore{client: client, valueGeneratorFactory: valueGeneratorFactory}
+}
+
+func (s VarsRemoteStore) Get(varDef boshtpl.VariableDefinition) (interface{}, bool, error) {
+	val, found, err := s.client.Get(varDef.Name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if found {
+		return val, true, nil
+	}
+
+	if len(varDef.Type) == 0 {
+		return nil, false, nil
+	}
+
+	val, err = s.generateAndSet(varDef)
+	if err != nil {
+		return nil, false, bosherr.WrapErrorf(err, "Generating variable '%s'", varDef.Name)
+	}
+
+	return val, true, nil
+}
+
+func (s VarsRemoteStore) List() ([]boshtpl.VariableDefinition, error) {
+	names, err := s.client.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var defs []boshtpl.VariableDefinition
+
+	for _, name := range names {
+		defs = append(defs, boshtpl.VariableDefinition{Name: name})
+	}
+
+	return defs, nil
+}
+
+// Regenerate replaces existing value with a newly generated one based on variable definition
+func (s VarsRemoteStore) Regenerate(varDef boshtpl.VariableDefinition) (interface{}, error) {
+	val, err := s.generateAndSet(varDef)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Generating variable '%s'", varDef.Name)
+	}
+
+	return val, nil
+}
+
+func (s VarsRemoteStore) generateAndSet(varDef boshtpl.VariableDefinition) (interface{}, error) {
+	generator, err := s.valueGeneratorFactory.GetGenerator(varDef.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := generator.Generate(varDef.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.client.Set(varDef.Name, varDef.Type, val)
+	if err != nil {
+		return nil, err
+	}
+
+	return val, nil
+}
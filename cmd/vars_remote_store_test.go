@@ -0,0 +1,139 @@
+package cmd_test
+
+import (
+	"errors"
+
+	fakecfgtypes "config_server/types/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	fakecredstore "github.com/cloudfoundry/bosh-cli/credstore/credstorefakes"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+)
+
+var _ = Describe("VarsRemoteStore", func() {
+	var (
+		client    *fakecredstore.FakeClient
+		generator *fakecfgtypes.FakeValueGenerator
+		factory   *fakecfgtypes.FakeValueGeneratorFactory
+		store     VarsRemoteStore
+	)
+
+	BeforeEach(func() {
+		client = &fakecredstore.FakeClient{}
+		generator = &fakecfgtypes.FakeValueGenerator{}
+		factory = &fakecfgtypes.FakeValueGeneratorFactory{}
+		factory.GetGeneratorReturns(generator, nil)
+		store = NewVarsRemoteStore(client, factory)
+	})
+
+	Describe("Get", func() {
+		It("returns value and found if credential store finds variable", func() {
+			client.GetReturns("val", true, nil)
+
+			val, found, err := store.Get(boshtpl.VariableDefinition{Name: "key"})
+			Expect(val).To(Equal("val"))
+			Expect(found).To(BeTrue())
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.GetArgsForCall(0)).To(Equal("key"))
+			Expect(client.SetCallCount()).To(Equal(0))
+		})
+
+		It("returns nil and not found if variable type is not available", func() {
+			val, found, err := store.Get(boshtpl.VariableDefinition{Name: "key"})
+			Expect(val).To(BeNil())
+			Expect(found).To(BeFalse())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("generates value and saves it in credential store if variable type is available", func() {
+			generator.GenerateReturns("generated-val", nil)
+
+			val, found, err := store.Get(boshtpl.VariableDefinition{
+				Name: "key", Type: "password", Options: "opts"})
+			Expect(val).To(Equal("generated-val"))
+			Expect(found).To(BeTrue())
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(factory.GetGeneratorArgsForCall(0)).To(Equal("password"))
+			Expect(generator.GenerateArgsForCall(0)).To(Equal("opts"))
+
+			name, type_, value := client.SetArgsForCall(0)
+			Expect(name).To(Equal("key"))
+			Expect(type_).To(Equal("password"))
+			Expect(value).To(Equal("generated-val"))
+		})
+
+		It("returns error if getting variable fails", func() {
+			client.GetReturns(nil, false, errors.New("fake-err"))
+
+			_, _, err := store.Get(boshtpl.VariableDefinition{Name: "key"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-err"))
+		})
+
+		It("returns error if generating variable fails", func() {
+			generator.GenerateReturns(nil, errors.New("fake-err"))
+
+			_, found, err := store.Get(boshtpl.VariableDefinition{Name: "key", Type: "password"})
+			Expect(found).To(BeFalse())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Generating variable 'key': fake-err"))
+		})
+
+		It("returns error if saving variable fails", func() {
+			client.SetReturns(errors.New("fake-err"))
+
+			_, found, err := store.Get(boshtpl.VariableDefinition{Name: "key", Type: "password"})
+			Expect(found).To(BeFalse())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Generating variable 'key': fake-err"))
+		})
+	})
+
+	Describe("Regenerate", func() {
+		It("generates new value and saves it even if variable already exists", func() {
+			client.GetReturns("old-val", true, nil)
+			generator.GenerateReturns("new-val", nil)
+
+			val, err := store.Regenerate(boshtpl.VariableDefinition{Name: "key", Type: "password"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(val).To(Equal("new-val"))
+
+			Expect(client.GetCallCount()).To(Equal(0))
+
+			name, type_, setVal := client.SetArgsForCall(0)
+			Expect(name).To(Equal("key"))
+			Expect(type_).To(Equal("password"))
+			Expect(setVal).To(Equal("new-val"))
+		})
+
+		It("returns error if saving variable fails", func() {
+			client.SetReturns(errors.New("fake-err"))
+
+			_, err := store.Regenerate(boshtpl.VariableDefinition{Name: "key", Type: "password"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Generating variable 'key': fake-err"))
+		})
+	})
+
+	Describe("List", func() {
+		It("returns variable definitions for names in credential store", func() {
+			client.ListReturns([]string{"a", "b"}, nil)
+
+			defs, err := store.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(defs).To(Equal([]boshtpl.VariableDefinition{{Name: "a"}, {Name: "b"}}))
+		})
+
+		It("returns error if listing fails", func() {
+			client.ListReturns(nil, errors.New("fake-err"))
+
+			_, err := store.List()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-err"))
+		})
+	})
+})
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/pivotal-golang/clock"
+	"gopkg.in/yaml.v2"
+
+	cmdconf "github.com/cloudfoundry/bosh-cli/cmd/config"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+// Certificates expiring sooner than this are highlighted
+const varsStoreCertsExpiryWarningDays = 30
+
+type VarsStoreCertsCmd struct {
+	ui          boshui.UI
+	timeService clock.Clock
+}
+
+func NewVarsStoreCertsCmd(ui boshui.UI, timeService clock.Clock) VarsStoreCertsCmd {
+	return VarsStoreCertsCmd{ui: ui, timeService: timeService}
+}
+
+func (c VarsStoreCertsCmd) Run(opts VarsStoreCertsOpts) error {
+	store := opts.Args.VarsStore
+	store.EncryptionKey = opts.Key.Key
+
+	defs, err := store.List()
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Listing variables")
+	}
+
+	table := boshtbl.Table{
+		Content: "certificates",
+
+		Header: []string{"Name", "Subject", "Issuer", "Alternative Names", "CA", "Expires", "Days Left"},
+
+		SortBy: []boshtbl.ColumnSort{
+			{Column: 6, Asc: true},
+			{Column: 0, Asc: true},
+		},
+	}
+
+	now := c.timeService.Now()
+
+	for _, def := range defs {
+		val, _, err := store.Get(boshtpl.VariableDefinition{Name: def.Name})
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Getting variable '%s'", def.Name)
+		}
+
+		crt, found, err := certificateFromVariable(val)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Parsing certificate in variable '%s'", def.Name)
+		} else if !found {
+			continue
+		}
+
+		daysLeft := int(math.Floor(crt.NotAfter.Sub(now).Hours() / 24))
+
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(def.Name),
+			boshtbl.NewValueString(certificateNameString(crt.Subject)),
+			boshtbl.NewValueString(certificateNameString(crt.Issuer)),
+			boshtbl.NewValueStrings(certificateAltNames(crt)),
+			boshtbl.NewValueBool(crt.IsCA),
+			boshtbl.NewValueTime(crt.NotAfter.UTC()),
+			boshtbl.ValueFmt{
+				V:     boshtbl.NewValueInt(daysLeft),
+				Error: daysLeft < varsStoreCertsExpiryWarningDays,
+			},
+		})
+	}
+
+	c.ui.PrintTable(table)
+
+	return nil
+}
+
+// ConfigureVarsStore connects to a credential store if one was specified
+func (o *VarsStoreCertsOpts) ConfigureVarsStore(configFunc func() cmdconf.Config, logger boshlog.Logger) error {
+	if !o.Args.VarsStore.IsRemote() {
+		return nil
+	}
+
+	return o.Args.VarsStore.ConfigureRemote(configFunc(), logger)
+}
+
+// certificateFromVariable returns false if value does not look like a certificate variable
+func certificateFromVariable(val interface{}) (*x509.Certificate, bool, error) {
+	if _, ok := val.(string); ok {
+		return nil, false, nil
+	}
+
+	// Convert to YAML for easier struct parsing
+	valBytes, err := yaml.Marshal(val)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	var certVal struct {
+		Certificate string
+	}
+
+	err = yaml.Unmarshal(valBytes, &certVal)
+	if err != nil || len(certVal.Certificate) == 0 {
+		return nil, false, nil
+	}
+
+	crt, err := VarsCertLoader{}.parseCertificate(certVal.Certificate)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return crt, true, nil
+}
+
+func certificateNameString(name pkix.Name) string {
+	var pieces []string
+
+	for _, c := range name.Country {
+		pieces = append(pieces, "c="+c)
+	}
+
+	for _, o := range name.Organization {
+		pieces = append(pieces, "o="+o)
+	}
+
+	if len(name.CommonName) > 0 {
+		pieces = append(pieces, "cn="+name.CommonName)
+	}
+
+	return strings.Join(pieces, ", ")
+}
+
+func certificateAltNames(crt *x509.Certificate) []string {
+	names := append([]string{}, crt.DNSNames...)
+
+	for _, ip := range crt.IPAddresses {
+		names = append(names, ip.String())
+	}
+
+	return names
+}
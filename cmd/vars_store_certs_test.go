@@ -0,0 +1,170 @@
+package cmd_test
+
+import (
+	cfgtypes "config_server/types"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"time"
+
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-golang/clock/fakeclock"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+var _ = Describe("VarsStoreCertsCmd", func() {
+	var (
+		fs          *fakesys.FakeFileSystem
+		ui          *fakeui.FakeUI
+		timeService *fakeclock.FakeClock
+		command     VarsStoreCertsCmd
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		ui = &fakeui.FakeUI{}
+		timeService = fakeclock.NewFakeClock(time.Now())
+		command = NewVarsStoreCertsCmd(ui, timeService)
+	})
+
+	Describe("Run", func() {
+		var (
+			opts  VarsStoreCertsOpts
+			store VarsFSStore
+		)
+
+		BeforeEach(func() {
+			store = VarsFSStore{FS: fs}
+
+			err := (&store).UnmarshalFlag("/store")
+			Expect(err).ToNot(HaveOccurred())
+
+			store.ValueGeneratorFactory = cfgtypes.NewValueGeneratorConcrete(NewVarsCertLoader(store))
+
+			opts = VarsStoreCertsOpts{}
+			opts.Args.VarsStore = store
+		})
+
+		act := func() error { return command.Run(opts) }
+
+		generate := func(name string, options map[interface{}]interface{}) *x509.Certificate {
+			val, _, err := store.Get(boshtpl.VariableDefinition{Name: name, Type: "certificate", Options: options})
+			Expect(err).ToNot(HaveOccurred())
+
+			block, _ := pem.Decode([]byte(val.(cfgtypes.CertResponse).Certificate))
+			Expect(block).ToNot(BeNil())
+
+			crt, err := x509.ParseCertificate(block.Bytes)
+			Expect(err).ToNot(HaveOccurred())
+
+			return crt
+		}
+
+		It("lists certificates with their expiry sorted by days left", func() {
+			caCrt := generate("ca", map[interface{}]interface{}{"common_name": "fake-ca"})
+			generate("leaf", map[interface{}]interface{}{
+				"ca":                "ca",
+				"common_name":       "fake-leaf",
+				"alternative_names": []interface{}{"leaf.example.com", "10.0.0.1"},
+			})
+
+			_, _, err := store.Get(boshtpl.VariableDefinition{Name: "password", Type: "password"})
+			Expect(err).ToNot(HaveOccurred())
+
+			timeService.Increment(caCrt.NotAfter.Sub(timeService.Now()) - 10*24*time.Hour - time.Hour)
+
+			err = act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Table.Content).To(Equal("certificates"))
+			Expect(ui.Table.Header).To(Equal([]string{
+				"Name", "Subject", "Issuer", "Alternative Names", "CA", "Expires", "Days Left"}))
+
+			Expect(ui.Table.SortBy).To(Equal([]boshtbl.ColumnSort{
+				{Column: 6, Asc: true},
+				{Column: 0, Asc: true},
+			}))
+
+			Expect(ui.Table.Rows).To(HaveLen(2))
+
+			var caRow, leafRow []boshtbl.Value
+
+			for _, row := range ui.Table.Rows {
+				if row[0] == boshtbl.NewValueString("ca") {
+					caRow = row
+				} else {
+					leafRow = row
+				}
+			}
+
+			Expect(caRow[1]).To(Equal(boshtbl.NewValueString("c=USA, o=Cloud Foundry, cn=fake-ca")))
+			Expect(caRow[2]).To(Equal(boshtbl.NewValueString("c=USA, o=Cloud Foundry, cn=fake-ca")))
+			Expect(caRow[3]).To(Equal(boshtbl.NewValueStrings([]string{})))
+			Expect(caRow[4]).To(Equal(boshtbl.NewValueBool(true)))
+			Expect(caRow[5]).To(Equal(boshtbl.NewValueTime(caCrt.NotAfter.UTC())))
+			Expect(caRow[6]).To(Equal(boshtbl.ValueFmt{V: boshtbl.NewValueInt(10), Error: true}))
+
+			Expect(leafRow[0]).To(Equal(boshtbl.NewValueString("leaf")))
+			Expect(leafRow[1]).To(Equal(boshtbl.NewValueString("c=USA, o=Cloud Foundry, cn=fake-leaf")))
+			Expect(leafRow[2]).To(Equal(boshtbl.NewValueString("c=USA, o=Cloud Foundry, cn=fake-ca")))
+			Expect(leafRow[3]).To(Equal(boshtbl.NewValueStrings([]string{"leaf.example.com", "10.0.0.1"})))
+			Expect(leafRow[4]).To(Equal(boshtbl.NewValueBool(false)))
+		})
+
+		It("does not highlight certificates that are not expiring soon", func() {
+			caCrt := generate("ca", map[interface{}]interface{}{"common_name": "fake-ca"})
+
+			timeService.Increment(caCrt.NotAfter.Sub(timeService.Now()) - 30*24*time.Hour - time.Hour)
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Table.Rows[0][6]).To(Equal(boshtbl.ValueFmt{V: boshtbl.NewValueInt(30), Error: false}))
+		})
+
+		It("shows negative days left for expired certificates", func() {
+			caCrt := generate("ca", map[interface{}]interface{}{"common_name": "fake-ca"})
+
+			timeService.Increment(caCrt.NotAfter.Sub(timeService.Now()) + time.Hour)
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Table.Rows[0][6]).To(Equal(boshtbl.ValueFmt{V: boshtbl.NewValueInt(-1), Error: true}))
+		})
+
+		It("decrypts vars store with a key", func() {
+			store.EncryptionKey = []byte("fake-key")
+			generate("ca", map[interface{}]interface{}{"common_name": "fake-ca"})
+
+			opts.Key = VarsStoreKeyArg{Key: []byte("fake-key")}
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ui.Table.Rows).To(HaveLen(1))
+		})
+
+		It("returns error if certificate cannot be parsed", func() {
+			fs.WriteFileString("/store", "cert: {certificate: not-pem}")
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Parsing certificate in variable 'cert'"))
+		})
+
+		It("returns error if reading vars store fails", func() {
+			fs.WriteFileString("/store", "key: val")
+			fs.ReadFileError = errors.New("fake-err")
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-err"))
+		})
+	})
+})
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	gobytes "bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Encrypted vars store files start with a header line followed by
+// base64 encoded salt, nonce and AES-256-GCM sealed YAML.
+const (
+	varsStoreCipherHeader     = "$BOSH_VARS_STORE;1.0;AES256-GCM"
+	varsStoreCipherSaltLen    = 16
+	varsStoreCipherIterations = 100000
+	varsStoreCipherLineLen    = 76
+)
+
+func isEncryptedVarsStore(data []byte) bool {
+	return gobytes.HasPrefix(data, []byte(varsStoreCipherHeader+"\n"))
+}
+
+func encryptVarsStore(key, plaintext []byte) ([]byte, error) {
+	salt := make([]byte, varsStoreCipherSaltLen)
+
+	_, err := io.ReadFull(rand.Reader, salt)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Generating salt")
+	}
+
+	aead, err := newVarsStoreAEAD(key, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Generating nonce")
+	}
+
+	sealed := append(append(salt, nonce...), aead.Seal(nil, nonce, plaintext, []byte(varsStoreCipherHeader))...)
+	encoded := base64.StdEncoding.EncodeToString(sealed)
+
+	lines := []string{varsStoreCipherHeader}
+
+	for len(encoded) > varsStoreCipherLineLen {
+		lines = append(lines, encoded[:varsStoreCipherLineLen])
+		encoded = encoded[varsStoreCipherLineLen:]
+	}
+
+	lines = append(lines, encoded)
+
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+func decryptVarsStore(key, data []byte) ([]byte, error) {
+	if !isEncryptedVarsStore(data) {
+		return nil, bosherr.Error("Expected encrypted vars store header")
+	}
+
+	encoded := strings.Join(strings.Fields(string(data[len(varsStoreCipherHeader):])), "")
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Decoding encrypted vars store")
+	}
+
+	if len(sealed) < varsStoreCipherSaltLen {
+		return nil, bosherr.Error("Expected encrypted vars store to include salt")
+	}
+
+	aead, err := newVarsStoreAEAD(key, sealed[:varsStoreCipherSaltLen])
+	if err != nil {
+		return nil, err
+	}
+
+	sealed = sealed[varsStoreCipherSaltLen:]
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, bosherr.Error("Expected encrypted vars store to include nonce")
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(varsStoreCipherHeader))
+	if err != nil {
+		return nil, bosherr.Error("Decrypting vars store: key does not match or contents were modified")
+	}
+
+	return plaintext, nil
+}
+
+func newVarsStoreAEAD(key, salt []byte) (cipher.AEAD, error) {
+	derivedKey := pbkdf2.Key(key, salt, varsStoreCipherIterations, 32, sha256.New)
+
+	block, err := aes.NewCipher(derivedKey)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Building cipher")
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Building cipher")
+	}
+
+	return aead, nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const varsStoreKeyFilePrefix = "file:"
+
+type VarsStoreKeyArg struct {
+	FS boshsys.FileSystem
+
+	Key []byte
+}
+
+// UnmarshalFlag reads key from a file when value is prefixed with 'file:'
+// otherwise value itself is the key.
+func (a *VarsStoreKeyArg) UnmarshalFlag(data string) error {
+	if len(data) == 0 {
+		return bosherr.Errorf("Expected vars store key to be non-empty")
+	}
+
+	if strings.HasPrefix(data, varsStoreKeyFilePrefix) {
+		return a.readFile(strings.TrimPrefix(data, varsStoreKeyFilePrefix))
+	}
+
+	(*a).Key = []byte(data)
+
+	return nil
+}
+
+func (a *VarsStoreKeyArg) readFile(path string) error {
+	if len(path) == 0 {
+		return bosherr.Errorf("Expected vars store key file path to be non-empty")
+	}
+
+	absPath, err := a.FS.ExpandPath(path)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Getting absolute path '%s'", path)
+	}
+
+	content, err := a.FS.ReadFileString(absPath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Reading vars store key file '%s'", absPath)
+	}
+
+	content = strings.TrimSpace(content)
+
+	if len(content) == 0 {
+		return bosherr.Errorf("Expected vars store key file '%s' to be non-empty", absPath)
+	}
+
+	(*a).Key = []byte(content)
+
+	return nil
+}
+
+func (a VarsStoreKeyArg) IsSet() bool { return len(a.Key) > 0 }
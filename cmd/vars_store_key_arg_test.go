@@ -0,0 +1,100 @@
+package cmd_test
+
+import (
+	"errors"
+
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+)
+
+var _ = Describe("VarsStoreKeyArg", func() {
+	var (
+		fs  *fakesys.FakeFileSystem
+		arg VarsStoreKeyArg
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		arg = VarsStoreKeyArg{FS: fs}
+	})
+
+	Describe("UnmarshalFlag", func() {
+		It("reads trimmed key from a file if value is prefixed with 'file:'", func() {
+			fs.WriteFileString("/key", "file-key\n")
+
+			err := (&arg).UnmarshalFlag("file:/key")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(arg.Key).To(Equal([]byte("file-key")))
+			Expect(arg.IsSet()).To(BeTrue())
+		})
+
+		It("uses value as a key", func() {
+			err := (&arg).UnmarshalFlag("value-key")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(arg.Key).To(Equal([]byte("value-key")))
+		})
+
+		It("uses value as a key even if it looks like a path or names an existing file", func() {
+			fs.WriteFileString("/cwd/key", "file-key")
+			fs.ExpandPathExpanded = "/cwd/key"
+
+			for _, val := range []string{"/key", "./key", "../key", "~/key", "key"} {
+				err := (&arg).UnmarshalFlag(val)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(arg.Key).To(Equal([]byte(val)))
+			}
+		})
+
+		It("returns error if value is empty", func() {
+			err := (&arg).UnmarshalFlag("")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected vars store key to be non-empty"))
+		})
+
+		It("returns error if key file path is empty", func() {
+			err := (&arg).UnmarshalFlag("file:")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected vars store key file path to be non-empty"))
+		})
+
+		It("returns error if key file does not exist", func() {
+			err := (&arg).UnmarshalFlag("file:/key")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Reading vars store key file '/key'"))
+		})
+
+		It("returns error if key file is empty", func() {
+			fs.WriteFileString("/key", " \n")
+
+			err := (&arg).UnmarshalFlag("file:/key")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected vars store key file '/key' to be non-empty"))
+		})
+
+		It("returns error if reading key file fails", func() {
+			fs.WriteFileString("/key", "file-key")
+			fs.ReadFileError = errors.New("fake-err")
+
+			err := (&arg).UnmarshalFlag("file:/key")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-err"))
+		})
+
+		It("returns error if path cannot be expanded", func() {
+			fs.ExpandPathErr = errors.New("fake-err")
+
+			err := (&arg).UnmarshalFlag("file:/key")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-err"))
+		})
+	})
+
+	Describe("IsSet", func() {
+		It("returns false if key is not set", func() {
+			Expect(arg.IsSet()).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+)
+
+type VarsStoreRekeyCmd struct {
+	ui boshui.UI
+}
+
+func NewVarsStoreRekeyCmd(ui boshui.UI) VarsStoreRekeyCmd {
+	return VarsStoreRekeyCmd{ui: ui}
+}
+
+func (c VarsStoreRekeyCmd) Run(opts VarsStoreRekeyOpts) error {
+	if opts.NewKey.IsSet() == opts.Decrypt {
+		return bosherr.Errorf("Expected exactly one of '--new-key' or '--decrypt' to be specified")
+	}
+
+	store := opts.Args.VarsStore
+	store.EncryptionKey = opts.Key.Key
+
+	err := store.Rekey(opts.NewKey.Key)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Rekeying vars store")
+	}
+
+	if opts.Decrypt {
+		c.ui.PrintLinef("Decrypted vars store")
+	} else {
+		c.ui.PrintLinef("Re-encrypted vars store with new key")
+	}
+
+	return nil
+}
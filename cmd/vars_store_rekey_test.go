@@ -0,0 +1,141 @@
+package cmd_test
+
+import (
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+)
+
+var _ = Describe("VarsStoreRekeyCmd", func() {
+	var (
+		fs      *fakesys.FakeFileSystem
+		ui      *fakeui.FakeUI
+		command VarsStoreRekeyCmd
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		ui = &fakeui.FakeUI{}
+		command = NewVarsStoreRekeyCmd(ui)
+	})
+
+	Describe("Run", func() {
+		var (
+			opts VarsStoreRekeyOpts
+		)
+
+		BeforeEach(func() {
+			opts = VarsStoreRekeyOpts{}
+			opts.Args.VarsStore = VarsFSStore{FS: fs}
+
+			err := (&opts.Args.VarsStore).UnmarshalFlag("/file")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		act := func() error { return command.Run(opts) }
+
+		readWithKey := func(key string) (interface{}, error) {
+			store := VarsFSStore{FS: fs, EncryptionKey: []byte(key)}
+
+			err := (&store).UnmarshalFlag("/file")
+			Expect(err).ToNot(HaveOccurred())
+
+			val, _, err := store.Get(boshtpl.VariableDefinition{Name: "key"})
+			return val, err
+		}
+
+		It("encrypts plaintext vars store with new key", func() {
+			fs.WriteFileString("/file", "key: val")
+
+			opts.NewKey = VarsStoreKeyArg{Key: []byte("new-key")}
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.ReadFileString("/file")).ToNot(ContainSubstring("val"))
+
+			val, err := readWithKey("new-key")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(val).To(Equal("val"))
+
+			Expect(ui.Said).To(Equal([]string{"Re-encrypted vars store with new key"}))
+		})
+
+		It("re-encrypts encrypted vars store with new key", func() {
+			opts.Args.VarsStore.EncryptionKey = []byte("old-key")
+			_, _, err := opts.Args.VarsStore.Get(boshtpl.VariableDefinition{Name: "key", Type: "password"})
+			Expect(err).ToNot(HaveOccurred())
+			opts.Args.VarsStore.EncryptionKey = nil
+
+			opts.Key = VarsStoreKeyArg{Key: []byte("old-key")}
+			opts.NewKey = VarsStoreKeyArg{Key: []byte("new-key")}
+
+			err = act()
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = readWithKey("old-key")
+			Expect(err).To(HaveOccurred())
+
+			val, err := readWithKey("new-key")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(val.(string))).To(BeNumerically(">", 10))
+		})
+
+		It("decrypts vars store if requested", func() {
+			opts.Args.VarsStore.EncryptionKey = []byte("old-key")
+			_, _, err := opts.Args.VarsStore.Get(boshtpl.VariableDefinition{Name: "key", Type: "password"})
+			Expect(err).ToNot(HaveOccurred())
+			opts.Args.VarsStore.EncryptionKey = nil
+
+			opts.Key = VarsStoreKeyArg{Key: []byte("old-key")}
+			opts.Decrypt = true
+
+			err = act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.ReadFileString("/file")).To(HavePrefix("key: "))
+			Expect(ui.Said).To(Equal([]string{"Decrypted vars store"}))
+		})
+
+		It("returns error if current key does not match", func() {
+			opts.Args.VarsStore.EncryptionKey = []byte("old-key")
+			_, _, err := opts.Args.VarsStore.Get(boshtpl.VariableDefinition{Name: "key", Type: "password"})
+			Expect(err).ToNot(HaveOccurred())
+			opts.Args.VarsStore.EncryptionKey = nil
+
+			opts.Key = VarsStoreKeyArg{Key: []byte("wrong-key")}
+			opts.NewKey = VarsStoreKeyArg{Key: []byte("new-key")}
+
+			err = act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("key does not match"))
+		})
+
+		It("returns error if vars store file does not exist", func() {
+			opts.NewKey = VarsStoreKeyArg{Key: []byte("new-key")}
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected variables file store '/file' to exist"))
+		})
+
+		It("returns error if neither new key nor decrypt is specified", func() {
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected exactly one of '--new-key' or '--decrypt' to be specified"))
+		})
+
+		It("returns error if both new key and decrypt are specified", func() {
+			opts.NewKey = VarsStoreKeyArg{Key: []byte("new-key")}
+			opts.Decrypt = true
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected exactly one of '--new-key' or '--decrypt' to be specified"))
+		})
+	})
+})
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	cfgtypes "config_server/types"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"gopkg.in/yaml.v2"
+
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+)
+
+type VarsStoreRotateCertsCmd struct {
+	ui boshui.UI
+}
+
+func NewVarsStoreRotateCertsCmd(ui boshui.UI) VarsStoreRotateCertsCmd {
+	return VarsStoreRotateCertsCmd{ui: ui}
+}
+
+func (c VarsStoreRotateCertsCmd) Run(opts VarsStoreRotateCertsOpts) error {
+	if !opts.VarsFSStore.IsSet() {
+		return bosherr.Errorf("Expected '--vars-store' to be specified")
+	}
+
+	vars := opts.VarFlags.AsVariables()
+
+	// Evaluate manifest so that definition options (e.g. common_name) are interpolated
+	bytes, err := boshtpl.NewTemplate(opts.Args.Manifest.Bytes).Evaluate(vars, opts.OpsFlags.AsOp(), boshtpl.EvaluateOpts{})
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Evaluating manifest")
+	}
+
+	var manifest struct {
+		Variables []boshtpl.VariableDefinition `yaml:"variables"`
+	}
+
+	err = yaml.Unmarshal(bytes, &manifest)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Deserializing manifest variable definitions")
+	}
+
+	defs, err := c.certsToRotate(manifest.Variables, opts.Certs)
+	if err != nil {
+		return err
+	}
+
+	store := opts.VarsFSStore
+	store.EncryptionKey = opts.VarsStoreKey.Key
+	store.ValueGeneratorFactory = cfgtypes.NewValueGeneratorConcrete(NewVarsCertLoader(vars))
+
+	for _, def := range defs {
+		_, err := store.Regenerate(def)
+		if err != nil {
+			return err
+		}
+
+		c.ui.PrintLinef("Regenerated certificate '%s'", def.Name)
+	}
+
+	return nil
+}
+
+// certsToRotate includes chosen certificates and all certificates signed by them,
+// ordered so that each CA is regenerated before certificates it signs
+func (c VarsStoreRotateCertsCmd) certsToRotate(allDefs []boshtpl.VariableDefinition, names []string) ([]boshtpl.VariableDefinition, error) {
+	certDefs := map[string]boshtpl.VariableDefinition{}
+
+	for _, def := range allDefs {
+		if def.Type == "certificate" {
+			certDefs[def.Name] = def
+		}
+	}
+
+	selected := map[string]struct{}{}
+	queue := []string{}
+
+	for _, name := range names {
+		if _, found := certDefs[name]; !found {
+			return nil, bosherr.Errorf("Expected to find certificate variable '%s' in manifest variable definitions", name)
+		}
+
+		queue = append(queue, name)
+	}
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		if _, found := selected[name]; found {
+			continue
+		}
+
+		selected[name] = struct{}{}
+
+		for _, def := range allDefs {
+			if def.Type == "certificate" && c.caName(def) == name {
+				queue = append(queue, def.Name)
+			}
+		}
+	}
+
+	var ordered []boshtpl.VariableDefinition
+
+	emitted := map[string]struct{}{}
+
+	var emit func(def boshtpl.VariableDefinition)
+
+	emit = func(def boshtpl.VariableDefinition) {
+		if _, found := emitted[def.Name]; found {
+			return
+		}
+
+		emitted[def.Name] = struct{}{}
+
+		if caName := c.caName(def); len(caName) > 0 {
+			if _, found := selected[caName]; found {
+				emit(certDefs[caName])
+			}
+		}
+
+		ordered = append(ordered, def)
+	}
+
+	for _, def := range allDefs {
+		if _, found := selected[def.Name]; found && def.Type == "certificate" {
+			emit(def)
+		}
+	}
+
+	return ordered, nil
+}
+
+func (VarsStoreRotateCertsCmd) caName(def boshtpl.VariableDefinition) string {
+	options, ok := def.Options.(map[interface{}]interface{})
+	if !ok {
+		return ""
+	}
+
+	caName, _ := options["ca"].(string)
+
+	return caName
+}
@@ -0,0 +1,198 @@
+package cmd_test
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+)
+
+var _ = Describe("VarsStoreRotateCertsCmd", func() {
+	var (
+		fs      *fakesys.FakeFileSystem
+		ui      *fakeui.FakeUI
+		command VarsStoreRotateCertsCmd
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		ui = &fakeui.FakeUI{}
+		command = NewVarsStoreRotateCertsCmd(ui)
+	})
+
+	Describe("Run", func() {
+		var (
+			opts VarsStoreRotateCertsOpts
+		)
+
+		const manifest = `
+variables:
+- name: ca
+  type: certificate
+  options:
+    common_name: fake-ca
+- name: server
+  type: certificate
+  options:
+    ca: ca
+    common_name: fake-server
+- name: leaf
+  type: certificate
+  options:
+    ca: ca
+    common_name: ((domain))
+- name: other-ca
+  type: certificate
+  options:
+    common_name: fake-other-ca
+- name: other-leaf
+  type: certificate
+  options:
+    ca: other-ca
+    common_name: fake-other-leaf
+- name: password
+  type: password
+`
+
+		newStore := func() VarsFSStore {
+			store := VarsFSStore{FS: fs}
+
+			err := (&store).UnmarshalFlag("/store")
+			Expect(err).ToNot(HaveOccurred())
+
+			return store
+		}
+
+		BeforeEach(func() {
+			opts = VarsStoreRotateCertsOpts{}
+			opts.Args.Manifest = FileBytesArg{Bytes: []byte(manifest)}
+			opts.VarKVs = []boshtpl.VarKV{{Name: "domain", Value: "fake-domain"}}
+			opts.VarsFSStore = newStore()
+		})
+
+		act := func() error { return command.Run(opts) }
+
+		certificates := func() map[string]string {
+			vars := newStore()
+			result := map[string]string{}
+
+			for _, name := range []string{"ca", "server", "leaf", "other-ca", "other-leaf"} {
+				val, found, err := vars.Get(boshtpl.VariableDefinition{Name: name})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeTrue())
+
+				result[name] = val.(map[interface{}]interface{})["certificate"].(string)
+			}
+
+			return result
+		}
+
+		parseCert := func(data string) *x509.Certificate {
+			block, _ := pem.Decode([]byte(data))
+			Expect(block).ToNot(BeNil())
+
+			crt, err := x509.ParseCertificate(block.Bytes)
+			Expect(err).ToNot(HaveOccurred())
+
+			return crt
+		}
+
+		It("regenerates CA before all certificates signed by it", func() {
+			opts.Certs = []string{"leaf", "ca"}
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			before := certificates()
+
+			err = act()
+			Expect(err).ToNot(HaveOccurred())
+
+			after := certificates()
+
+			Expect(after["ca"]).ToNot(Equal(before["ca"]))
+			Expect(after["server"]).ToNot(Equal(before["server"]))
+			Expect(after["leaf"]).ToNot(Equal(before["leaf"]))
+			Expect(after["other-ca"]).To(Equal(before["other-ca"]))
+			Expect(after["other-leaf"]).To(Equal(before["other-leaf"]))
+
+			Expect(parseCert(after["server"]).CheckSignatureFrom(parseCert(after["ca"]))).To(Succeed())
+			Expect(parseCert(after["leaf"]).CheckSignatureFrom(parseCert(after["ca"]))).To(Succeed())
+
+			Expect(ui.Said[3:]).To(Equal([]string{
+				"Regenerated certificate 'ca'",
+				"Regenerated certificate 'server'",
+				"Regenerated certificate 'leaf'",
+			}))
+		})
+
+		It("regenerates only chosen leaf certificate using its original options", func() {
+			opts.Certs = []string{"other-leaf"}
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			before := certificates()
+
+			err = act()
+			Expect(err).ToNot(HaveOccurred())
+
+			after := certificates()
+
+			Expect(after["other-ca"]).To(Equal(before["other-ca"]))
+			Expect(after["other-leaf"]).ToNot(Equal(before["other-leaf"]))
+			Expect(after["leaf"]).To(Equal(before["leaf"]))
+
+			otherLeaf := parseCert(after["other-leaf"])
+			Expect(otherLeaf.Subject.CommonName).To(Equal("fake-other-leaf"))
+			Expect(otherLeaf.CheckSignatureFrom(parseCert(after["other-ca"]))).To(Succeed())
+
+			Expect(ui.Said).To(Equal([]string{
+				"Regenerated certificate 'other-leaf'",
+				"Regenerated certificate 'other-leaf'",
+			}))
+		})
+
+		It("interpolates definition options with provided variables", func() {
+			opts.Certs = []string{"leaf"}
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(parseCert(certificates()["leaf"]).Subject.CommonName).To(Equal("fake-domain"))
+		})
+
+		It("returns error if certificate is not defined in manifest", func() {
+			opts.Certs = []string{"password"}
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find certificate variable 'password' in manifest variable definitions"))
+		})
+
+		It("returns error if vars store is not specified", func() {
+			opts.VarsFSStore = VarsFSStore{}
+			opts.Certs = []string{"ca"}
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected '--vars-store' to be specified"))
+		})
+
+		It("returns error if manifest cannot be evaluated", func() {
+			opts.Args.Manifest = FileBytesArg{Bytes: []byte("invalid: [")}
+			opts.Certs = []string{"ca"}
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Evaluating manifest"))
+		})
+	})
+})
@@ -0,0 +1,276 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
+)
+
+// DeploymentStateLocker is implemented by deployment state services
+// that may be shared by multiple operators.
+type DeploymentStateLocker interface {
+	Lock(operation string) error
+	Unlock() error
+}
+
+type DeploymentStateLockInfo struct {
+	ID        string    `json:"id"`
+	Operator  string    `json:"operator"`
+	Operation string    `json:"operation"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type remoteDeploymentStateService struct {
+	stateURL    string
+	forceUnlock bool
+
+	uuidGenerator boshuuid.Generator
+
+	lockID string
+
+	logger boshlog.Logger
+	logTag string
+}
+
+// NewRemoteDeploymentStateService keeps deployment state at a remote URL
+// (see IsRemoteDeploymentStatePath) next to an advisory lock object
+// with '.lock' suffix. Lock is taken with a conditional write hence
+// store must honor 'If-None-Match: *' header for the lock to be exclusive.
+func NewRemoteDeploymentStateService(stateURL string, forceUnlock bool, uuidGenerator boshuuid.Generator, logger boshlog.Logger) DeploymentStateService {
+	return &remoteDeploymentStateService{
+		stateURL:    stateURL,
+		forceUnlock: forceUnlock,
+
+		uuidGenerator: uuidGenerator,
+
+		logger: logger,
+		logTag: "remoteDeploymentStateService",
+	}
+}
+
+// Path returns state URL without credentials
+func (s *remoteDeploymentStateService) Path() string {
+	parsedURL, err := url.Parse(s.stateURL)
+	if err != nil || parsedURL.User == nil {
+		return s.stateURL
+	}
+
+	parsedURL.User = url.User(parsedURL.User.Username())
+
+	return parsedURL.String()
+}
+
+// Exists returns true if existence cannot be determined
+// so that subsequent Load surfaces the error instead of state being reset
+func (s *remoteDeploymentStateService) Exists() bool {
+	client, objectURL, err := newRemoteStateClient(s.stateURL)
+	if err != nil {
+		return true
+	}
+
+	_, found, err := client.Get(objectURL)
+	if err != nil {
+		s.logger.Debug(s.logTag, "Failed to check deployment state existence: %s", err)
+		return true
+	}
+
+	return found
+}
+
+func (s *remoteDeploymentStateService) Load() (DeploymentState, error) {
+	client, objectURL, err := newRemoteStateClient(s.stateURL)
+	if err != nil {
+		return DeploymentState{}, err
+	}
+
+	s.logger.Debug(s.logTag, "Loading deployment state: %s", objectURL)
+
+	var deploymentState DeploymentState
+
+	contents, found, err := client.Get(objectURL)
+	if err != nil {
+		return DeploymentState{}, bosherr.WrapError(err, "Reading remote deployment state")
+	}
+
+	if found {
+		err = json.Unmarshal(contents, &deploymentState)
+		if err != nil {
+			return DeploymentState{}, bosherr.WrapErrorf(err, "Unmarshalling deployment state '%s'", objectURL)
+		}
+	}
+
+	if deploymentState.DirectorID == "" {
+		uuid, err := s.uuidGenerator.Generate()
+		if err != nil {
+			return DeploymentState{}, bosherr.WrapError(err, "Generating DirectorID")
+		}
+
+		deploymentState.DirectorID = uuid
+
+		err = s.Save(deploymentState)
+		if err != nil {
+			return DeploymentState{}, bosherr.WrapError(err, "Saving deployment state")
+		}
+	}
+
+	return deploymentState, nil
+}
+
+func (s *remoteDeploymentStateService) Save(deploymentState DeploymentState) error {
+	client, objectURL, err := newRemoteStateClient(s.stateURL)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Debug(s.logTag, "Saving deployment state %#v", deploymentState)
+
+	jsonContent, err := json.MarshalIndent(deploymentState, "", "    ")
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling deployment state into JSON")
+	}
+
+	err = client.Put(objectURL, jsonContent)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing remote deployment state")
+	}
+
+	return nil
+}
+
+func (s *remoteDeploymentStateService) Cleanup() error {
+	client, objectURL, err := newRemoteStateClient(s.stateURL)
+	if err != nil {
+		return err
+	}
+
+	err = client.Delete(objectURL)
+	if err != nil {
+		return bosherr.WrapError(err, "Deleting remote deployment state")
+	}
+
+	return nil
+}
+
+// Lock fails if lock is held by someone else unless lock was asked to be forcefully removed
+func (s *remoteDeploymentStateService) Lock(operation string) error {
+	client, objectURL, err := newRemoteStateClient(s.stateURL)
+	if err != nil {
+		return err
+	}
+
+	lockURL := objectURL + ".lock"
+
+	if s.forceUnlock {
+		s.logger.Debug(s.logTag, "Forcefully removing deployment state lock '%s'", lockURL)
+
+		err = client.Delete(lockURL)
+		if err != nil {
+			return bosherr.WrapError(err, "Removing deployment state lock")
+		}
+	}
+
+	lockID, err := s.uuidGenerator.Generate()
+	if err != nil {
+		return bosherr.WrapError(err, "Generating lock ID")
+	}
+
+	lockInfo := DeploymentStateLockInfo{
+		ID:        lockID,
+		Operator:  s.operator(),
+		Operation: operation,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	lockBytes, err := json.Marshal(lockInfo)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling deployment state lock")
+	}
+
+	acquired, err := client.PutIfAbsent(lockURL, lockBytes)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing deployment state lock")
+	}
+
+	if !acquired {
+		heldLock, err := s.readLock(client, lockURL)
+		if err != nil {
+			return bosherr.WrapError(err, "Deployment state is locked by another operation")
+		}
+
+		return bosherr.Errorf(
+			"Deployment state is locked by '%s' for '%s' since '%s' (lock ID '%s'). "+
+				"Use '--force-unlock' if the lock is no longer held",
+			heldLock.Operator, heldLock.Operation, heldLock.CreatedAt.Format(time.RFC3339), heldLock.ID)
+	}
+
+	s.lockID = lockID
+
+	return nil
+}
+
+// Unlock only removes the lock if it's still held by this service
+func (s *remoteDeploymentStateService) Unlock() error {
+	if len(s.lockID) == 0 {
+		return nil
+	}
+
+	client, objectURL, err := newRemoteStateClient(s.stateURL)
+	if err != nil {
+		return err
+	}
+
+	lockURL := objectURL + ".lock"
+
+	heldLock, err := s.readLock(client, lockURL)
+	if err != nil {
+		return err
+	}
+
+	if heldLock.ID != s.lockID {
+		return bosherr.Errorf("Expected deployment state lock to be held by lock ID '%s' but was '%s'", s.lockID, heldLock.ID)
+	}
+
+	err = client.Delete(lockURL)
+	if err != nil {
+		return bosherr.WrapError(err, "Removing deployment state lock")
+	}
+
+	s.lockID = ""
+
+	return nil
+}
+
+func (s *remoteDeploymentStateService) readLock(client remoteStateClient, lockURL string) (DeploymentStateLockInfo, error) {
+	var lockInfo DeploymentStateLockInfo
+
+	contents, found, err := client.Get(lockURL)
+	if err != nil {
+		return lockInfo, bosherr.WrapError(err, "Reading deployment state lock")
+	}
+
+	if !found {
+		return lockInfo, bosherr.Errorf("Expected deployment state lock '%s' to exist", lockURL)
+	}
+
+	err = json.Unmarshal(contents, &lockInfo)
+	if err != nil {
+		return lockInfo, bosherr.WrapError(err, "Unmarshalling deployment state lock")
+	}
+
+	return lockInfo, nil
+}
+
+func (s *remoteDeploymentStateService) operator() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return fmt.Sprintf("%s@%s", os.Getenv("USER"), hostname)
+}
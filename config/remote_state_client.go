@@ -0,0 +1,221 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/signer/v4"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	boshproxy "github.com/cloudfoundry/bosh-cli/proxy"
+)
+
+var remoteStateSchemes = []string{"http://", "https://", "s3://"}
+
+// IsRemoteDeploymentStatePath returns true when state path refers to
+// an HTTP endpoint or an S3 compatible bucket instead of a local file.
+func IsRemoteDeploymentStatePath(path string) bool {
+	for _, scheme := range remoteStateSchemes {
+		if strings.HasPrefix(path, scheme) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// remoteStateClient reads and writes objects at HTTP(S) URLs.
+// Requests to S3 compatible endpoints are signed with AWS signature v4.
+type remoteStateClient struct {
+	httpClient *http.Client
+
+	username string
+	password string
+
+	signer *v4.Signer
+	region string
+}
+
+// newRemoteStateClient parses state URL and returns client
+// along with the URL of the state object. Supported URLs are:
+// 'http(s)://[user:password@]host/path' and
+// 's3://bucket/key[?region=us-east-1&endpoint=https://host:port]'.
+// S3 credentials are taken from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+// and optionally AWS_SESSION_TOKEN environment variables.
+func newRemoteStateClient(rawURL string) (remoteStateClient, string, error) {
+	client := remoteStateClient{httpClient: boshproxy.CreateDefaultClient(nil)}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return client, "", bosherr.WrapErrorf(err, "Parsing deployment state URL")
+	}
+
+	switch parsedURL.Scheme {
+	case "http", "https":
+		if parsedURL.User != nil {
+			client.username = parsedURL.User.Username()
+			client.password, _ = parsedURL.User.Password()
+			parsedURL.User = nil
+		}
+
+		return client, parsedURL.String(), nil
+
+	case "s3":
+		bucket := parsedURL.Host
+		key := strings.TrimPrefix(parsedURL.Path, "/")
+
+		if len(bucket) == 0 || len(key) == 0 {
+			return client, "", bosherr.Errorf("Expected deployment state URL to be in format 's3://bucket/key'")
+		}
+
+		query := parsedURL.Query()
+
+		client.region = query.Get("region")
+		if len(client.region) == 0 {
+			client.region = os.Getenv("AWS_REGION")
+		}
+		if len(client.region) == 0 {
+			client.region = "us-east-1"
+		}
+
+		endpoint := query.Get("endpoint")
+		if len(endpoint) == 0 {
+			endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", client.region)
+		}
+
+		// Path style addressing is used since it works with all S3 compatible stores
+		objectURL := strings.TrimSuffix(endpoint, "/") + "/" + bucket + "/" + key
+
+		client.signer = v4.NewSigner(credentials.NewEnvCredentials())
+
+		return client, objectURL, nil
+
+	default:
+		return client, "", bosherr.Errorf("Expected deployment state URL scheme to be 'http', 'https' or 's3'")
+	}
+}
+
+// Get returns object contents and false if object does not exist
+func (c remoteStateClient) Get(objectURL string) ([]byte, bool, error) {
+	resp, err := c.do("GET", objectURL, nil, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, bosherr.WrapErrorf(err, "Reading '%s'", objectURL)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, c.statusErr("GET", objectURL, resp, body)
+	}
+
+	return body, true, nil
+}
+
+func (c remoteStateClient) Put(objectURL string, contents []byte) error {
+	_, err := c.put(objectURL, contents, false)
+	return err
+}
+
+// PutIfAbsent returns false when object already exists.
+// Stores that ignore 'If-None-Match' header will always overwrite the object.
+func (c remoteStateClient) PutIfAbsent(objectURL string, contents []byte) (bool, error) {
+	return c.put(objectURL, contents, true)
+}
+
+func (c remoteStateClient) put(objectURL string, contents []byte, ifAbsent bool) (bool, error) {
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+
+	if ifAbsent {
+		headers.Set("If-None-Match", "*")
+	}
+
+	resp, err := c.do("PUT", objectURL, contents, headers)
+	if err != nil {
+		return false, err
+	}
+
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return true, nil
+
+	// S3 responds with 409 when concurrent conditional writes conflict
+	case ifAbsent && (resp.StatusCode == http.StatusPreconditionFailed || resp.StatusCode == http.StatusConflict):
+		return false, nil
+
+	default:
+		return false, c.statusErr("PUT", objectURL, resp, body)
+	}
+}
+
+// Delete succeeds if object does not exist
+func (c remoteStateClient) Delete(objectURL string) error {
+	resp, err := c.do("DELETE", objectURL, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode == http.StatusNotFound || (resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		return nil
+	}
+
+	return c.statusErr("DELETE", objectURL, resp, body)
+}
+
+func (c remoteStateClient) do(method, objectURL string, contents []byte, headers http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(method, objectURL, bytes.NewReader(contents))
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Building request %s '%s'", method, objectURL)
+	}
+
+	for name, vals := range headers {
+		req.Header[name] = vals
+	}
+
+	if len(c.username) > 0 {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	if c.signer != nil {
+		_, err = c.signer.Sign(req, bytes.NewReader(contents), "s3", c.region, time.Now())
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Signing request %s '%s'", method, objectURL)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Performing request %s '%s'", method, objectURL)
+	}
+
+	return resp, nil
+}
+
+func (c remoteStateClient) statusErr(method, objectURL string, resp *http.Response, body []byte) error {
+	return bosherr.Errorf("Request %s '%s' responded with status code '%d' response '%s'",
+		method, objectURL, resp.StatusCode, body)
+}
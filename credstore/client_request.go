@@ -0,0 +1,79 @@
+package credstore
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshhttp "github.com/cloudfoundry/bosh-utils/httpclient"
+)
+
+type clientRequest struct {
+	endpoint   string
+	httpClient boshhttp.HTTPClient
+	setHeaders func(*http.Request)
+}
+
+// Get decodes JSON response into given value; returns false if resource is not found
+func (r clientRequest) Get(path string, response interface{}) (bool, error) {
+	url := r.endpoint + path
+
+	resp, err := r.httpClient.GetCustomized(url, r.setHeaders)
+	if err != nil {
+		return false, bosherr.WrapErrorf(err, "Performing request GET '%s'", url)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return false, nil
+	}
+
+	return true, r.readResponse("GET", url, resp, response)
+}
+
+func (r clientRequest) Put(path string, payload interface{}) error {
+	url := r.endpoint + path
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshaling request body")
+	}
+
+	setHeaders := func(req *http.Request) {
+		req.Header.Set("Content-Type", "application/json")
+		r.setHeaders(req)
+	}
+
+	resp, err := r.httpClient.PutCustomized(url, payloadBytes, setHeaders)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Performing request PUT '%s'", url)
+	}
+
+	return r.readResponse("PUT", url, resp, nil)
+}
+
+func (r clientRequest) readResponse(method, url string, resp *http.Response, response interface{}) error {
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Reading response of %s '%s'", method, url)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return bosherr.Errorf("%s '%s': Credential store responded with non-successful status code '%d' response '%s'",
+			method, url, resp.StatusCode, respBody)
+	}
+
+	if response == nil || len(respBody) == 0 {
+		return nil
+	}
+
+	err = json.Unmarshal(respBody, response)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Unmarshaling response of %s '%s'", method, url)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package credstore
+
+import (
+	"net/http"
+	gourl "net/url"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshhttp "github.com/cloudfoundry/bosh-utils/httpclient"
+)
+
+// CredHubClient talks to the CredHub v1 data API
+type CredHubClient struct {
+	prefix  string
+	request clientRequest
+}
+
+type credHubDataResp struct {
+	Data []struct {
+		Type  string      `json:"type"`
+		Value interface{} `json:"value"`
+	} `json:"data"`
+}
+
+type credHubFindResp struct {
+	Credentials []struct {
+		Name string `json:"name"`
+	} `json:"credentials"`
+}
+
+type credHubSetReq struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// credHubTypes maps variable types onto CredHub credential types
+// (ssh keys are saved as json since generated values include a fingerprint)
+var credHubTypes = map[string]string{
+	"password":    "password",
+	"certificate": "certificate",
+	"rsa":         "rsa",
+}
+
+func NewCredHubClient(endpoint, path, token string, httpClient boshhttp.HTTPClient) CredHubClient {
+	setHeaders := func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	prefix := "/"
+	if len(path) > 0 {
+		prefix = "/" + path + "/"
+	}
+
+	return CredHubClient{
+		prefix:  prefix,
+		request: clientRequest{endpoint: endpoint, httpClient: httpClient, setHeaders: setHeaders},
+	}
+}
+
+func (c CredHubClient) Get(name string) (interface{}, bool, error) {
+	var resp credHubDataResp
+
+	query := gourl.Values{"name": {c.prefix + name}, "current": {"true"}}
+
+	found, err := c.request.Get("/api/v1/data?"+query.Encode(), &resp)
+	if err != nil {
+		return nil, false, bosherr.WrapErrorf(err, "Getting credential '%s'", name)
+	}
+
+	if !found || len(resp.Data) == 0 {
+		return nil, false, nil
+	}
+
+	return resp.Data[0].Value, true, nil
+}
+
+func (c CredHubClient) Set(name, type_ string, value interface{}) error {
+	credType, found := credHubTypes[type_]
+	if !found {
+		if _, ok := value.(string); ok {
+			credType = "value"
+		} else {
+			credType = "json"
+		}
+	}
+
+	req := credHubSetReq{Name: c.prefix + name, Type: credType, Value: value}
+
+	err := c.request.Put("/api/v1/data", req)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Setting credential '%s'", name)
+	}
+
+	return nil
+}
+
+func (c CredHubClient) List() ([]string, error) {
+	var resp credHubFindResp
+
+	path := c.prefix
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+
+	query := gourl.Values{"path": {path}}
+
+	_, err := c.request.Get("/api/v1/data?"+query.Encode(), &resp)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Listing credentials")
+	}
+
+	var names []string
+
+	for _, cred := range resp.Credentials {
+		names = append(names, strings.TrimPrefix(cred.Name, c.prefix))
+	}
+
+	return names, nil
+}
@@ -0,0 +1,123 @@
+package credstore_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/cloudfoundry/bosh-cli/credstore"
+)
+
+var _ = Describe("CredHubClient", func() {
+	var (
+		client Client
+		server *ghttp.Server
+	)
+
+	BeforeEach(func() {
+		client, server = buildClient("credhub", "env/name")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("Get", func() {
+		It("returns current value of credential under path", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/data", "current=true&name=%2Fenv%2Fname%2Fadmin_password"),
+					ghttp.VerifyHeaderKV("Authorization", "Bearer fake-token"),
+					ghttp.RespondWith(http.StatusOK, `{"data":[{"type":"password","value":"secret"}]}`),
+				),
+			)
+
+			val, found, err := client.Get("admin_password")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(val).To(Equal("secret"))
+		})
+
+		It("returns structured values", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"data":[{"type":"certificate","value":{"ca":"ca","certificate":"cert"}}]}`),
+			)
+
+			val, found, err := client.Get("cert")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(val).To(Equal(map[string]interface{}{"ca": "ca", "certificate": "cert"}))
+		})
+
+		It("returns not found if credential does not exist", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusNotFound, `{"error":"not found"}`))
+
+			val, found, err := client.Get("missing")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeFalse())
+			Expect(val).To(BeNil())
+		})
+
+		It("returns error if response is not successful", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusUnauthorized, `{"error":"invalid_token"}`))
+
+			_, _, err := client.Get("name")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Getting credential 'name'"))
+			Expect(err.Error()).To(ContainSubstring("non-successful status code '401'"))
+		})
+	})
+
+	Describe("Set", func() {
+		It("sets password typed credential", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/api/v1/data"),
+					ghttp.VerifyHeaderKV("Authorization", "Bearer fake-token"),
+					ghttp.VerifyJSON(`{"name":"/env/name/admin_password","type":"password","value":"secret"}`),
+					ghttp.RespondWith(http.StatusOK, `{}`),
+				),
+			)
+
+			err := client.Set("admin_password", "password", "secret")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("sets json typed credential for structured values of other types", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyJSON(`{"name":"/env/name/key","type":"json","value":{"private_key":"priv"}}`),
+					ghttp.RespondWith(http.StatusOK, `{}`),
+				),
+			)
+
+			err := client.Set("key", "ssh", map[string]string{"private_key": "priv"})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns error if response is not successful", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusBadRequest, `{"error":"bad"}`))
+
+			err := client.Set("name", "", "val")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Setting credential 'name'"))
+		})
+	})
+
+	Describe("List", func() {
+		It("returns names relative to path", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/data", "path=%2Fenv%2Fname"),
+					ghttp.RespondWith(http.StatusOK, `{"credentials":[{"name":"/env/name/a"},{"name":"/env/name/b"}]}`),
+				),
+			)
+
+			names, err := client.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names).To(Equal([]string{"a", "b"}))
+		})
+	})
+})
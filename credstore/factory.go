@@ -0,0 +1,54 @@
+package credstore
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshhttp "github.com/cloudfoundry/bosh-utils/httpclient"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+
+	boshproxy "github.com/cloudfoundry/bosh-cli/proxy"
+)
+
+type Factory struct {
+	logTag string
+	logger boshlog.Logger
+}
+
+func NewFactory(logger boshlog.Logger) Factory {
+	return Factory{
+		logTag: "credstore.Factory",
+		logger: logger,
+	}
+}
+
+func (f Factory) New(config Config) (Client, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, bosherr.WrapErrorf(
+			err, "Validating credential store connection config")
+	}
+
+	certPool, err := config.CACertPool()
+	if err != nil {
+		return nil, err
+	}
+
+	if certPool == nil {
+		f.logger.Debug(f.logTag, "Using default root CAs")
+	} else {
+		f.logger.Debug(f.logTag, "Using custom root CAs")
+	}
+
+	rawClient := boshproxy.CreateDefaultClient(certPool)
+
+	httpOpts := boshhttp.Opts{NoRedactUrlQuery: true}
+	httpClient := boshhttp.NewHTTPClientOpts(rawClient, f.logger, httpOpts)
+
+	switch config.Type {
+	case TypeCredHub:
+		return NewCredHubClient(config.endpoint(), config.Path, config.Token, httpClient), nil
+	case TypeVault:
+		return NewVaultClient(config.endpoint(), config.Path, config.Token, httpClient), nil
+	default:
+		return nil, bosherr.Errorf("Unknown credential store type '%s'", config.Type)
+	}
+}
@@ -0,0 +1,152 @@
+package credstore
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	gonet "net"
+	gourl "net/url"
+	"strconv"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+const (
+	TypeCredHub = "credhub"
+	TypeVault   = "vault"
+)
+
+var defaultPorts = map[string]int{
+	TypeCredHub: 8844,
+	TypeVault:   8200,
+}
+
+type Config struct {
+	Type string
+	Host string
+	Port int
+
+	// Path is a name prefix for CredHub and a secret path for Vault
+	Path string
+
+	// CA certificate is not required
+	CACert string
+
+	Token string
+}
+
+// IsURL returns true for URLs that should be handled by a credential store
+func IsURL(url string) bool {
+	for type_ := range defaultPorts {
+		if strings.HasPrefix(url, type_+"://") {
+			return true
+		}
+	}
+	return false
+}
+
+func NewConfigFromURL(url string) (Config, error) {
+	if len(url) == 0 {
+		return Config{}, bosherr.Error("Expected non-empty credential store URL")
+	}
+
+	parsedURL, err := gourl.Parse(url)
+	if err != nil {
+		return Config{}, bosherr.WrapErrorf(err, "Parsing credential store URL '%s'", url)
+	}
+
+	port, found := defaultPorts[parsedURL.Scheme]
+	if !found {
+		return Config{}, bosherr.Errorf(
+			"Expected credential store URL '%s' to start with 'credhub://' or 'vault://'", url)
+	}
+
+	host := parsedURL.Host
+
+	if strings.Contains(host, ":") {
+		var portStr string
+
+		host, portStr, err = gonet.SplitHostPort(host)
+		if err != nil {
+			return Config{}, bosherr.WrapErrorf(
+				err, "Extracting host/port from URL '%s'", parsedURL.Host)
+		}
+
+		port, err = strconv.Atoi(portStr)
+		if err != nil {
+			return Config{}, bosherr.WrapErrorf(
+				err, "Extracting port from URL '%s'", parsedURL.Host)
+		}
+	}
+
+	if len(host) == 0 {
+		return Config{}, bosherr.Errorf("Expected to extract host from URL '%s'", url)
+	}
+
+	config := Config{
+		Type: parsedURL.Scheme,
+		Host: host,
+		Port: port,
+		Path: strings.Trim(parsedURL.Path, "/"),
+	}
+
+	return config, nil
+}
+
+func (c Config) Validate() error {
+	if _, found := defaultPorts[c.Type]; !found {
+		return bosherr.Errorf("Unknown credential store type '%s'", c.Type)
+	}
+
+	if len(c.Host) == 0 {
+		return bosherr.Error("Missing 'Host'")
+	}
+
+	if c.Port == 0 {
+		return bosherr.Error("Missing 'Port'")
+	}
+
+	if c.Type == TypeVault && len(c.Path) == 0 {
+		return bosherr.Error("Missing 'Path'")
+	}
+
+	if len(c.Token) == 0 {
+		return bosherr.Error("Missing 'Token'")
+	}
+
+	if _, err := c.CACertPool(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c Config) CACertPool() (*x509.CertPool, error) {
+	if len(c.CACert) == 0 {
+		return nil, nil
+	}
+
+	certPool := x509.NewCertPool()
+
+	block, _ := pem.Decode([]byte(c.CACert))
+	if block == nil {
+		return nil, bosherr.Error("Parsing CA certificate: Missing PEM block")
+	}
+
+	if block.Type != "CERTIFICATE" || len(block.Headers) != 0 {
+		return nil, bosherr.Error("Parsing CA certificate: Not a certificate")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Parsing CA certificate")
+	}
+
+	certPool.AddCert(cert)
+
+	return certPool, nil
+}
+
+func (c Config) endpoint() string {
+	return "https://" + gonet.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
@@ -0,0 +1,102 @@
+package credstore_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/credstore"
+)
+
+var _ = Describe("IsURL", func() {
+	It("returns true for credhub and vault urls", func() {
+		Expect(IsURL("credhub://host/path")).To(BeTrue())
+		Expect(IsURL("vault://host/path")).To(BeTrue())
+	})
+
+	It("returns false for file paths", func() {
+		Expect(IsURL("/path/creds.yml")).To(BeFalse())
+		Expect(IsURL("creds.yml")).To(BeFalse())
+		Expect(IsURL("https://host/path")).To(BeFalse())
+	})
+})
+
+var _ = Describe("NewConfigFromURL", func() {
+	It("sets type, host, port and path", func() {
+		config, err := NewConfigFromURL("credhub://host:1234/env/name/")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config).To(Equal(Config{Type: "credhub", Host: "host", Port: 1234, Path: "env/name"}))
+	})
+
+	It("uses default credhub port", func() {
+		config, err := NewConfigFromURL("credhub://host")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config).To(Equal(Config{Type: "credhub", Host: "host", Port: 8844}))
+	})
+
+	It("uses default vault port", func() {
+		config, err := NewConfigFromURL("vault://host/secret/env")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config).To(Equal(Config{Type: "vault", Host: "host", Port: 8200, Path: "secret/env"}))
+	})
+
+	It("returns error if url is empty", func() {
+		_, err := NewConfigFromURL("")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected non-empty credential store URL"))
+	})
+
+	It("returns error if scheme is unknown", func() {
+		_, err := NewConfigFromURL("https://host")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("to start with 'credhub://' or 'vault://'"))
+	})
+
+	It("returns error if url cannot be parsed", func() {
+		_, err := NewConfigFromURL("vault://host:port/secret")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Parsing credential store URL 'vault://host:port/secret'"))
+	})
+
+	It("returns error if host is empty", func() {
+		_, err := NewConfigFromURL("vault:///secret")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to extract host from URL 'vault:///secret'"))
+	})
+})
+
+var _ = Describe("Config", func() {
+	var (
+		config Config
+	)
+
+	BeforeEach(func() {
+		config = Config{Type: "vault", Host: "host", Port: 8200, Path: "secret", Token: "token"}
+	})
+
+	Describe("Validate", func() {
+		It("returns no error for complete config", func() {
+			Expect(config.Validate()).ToNot(HaveOccurred())
+		})
+
+		It("returns error if token is missing", func() {
+			config.Token = ""
+			Expect(config.Validate()).To(MatchError("Missing 'Token'"))
+		})
+
+		It("returns error if vault path is missing", func() {
+			config.Path = ""
+			Expect(config.Validate()).To(MatchError("Missing 'Path'"))
+		})
+
+		It("does not require path for credhub", func() {
+			config.Type = "credhub"
+			config.Path = ""
+			Expect(config.Validate()).ToNot(HaveOccurred())
+		})
+
+		It("returns error if CA cert cannot be parsed", func() {
+			config.CACert = "not-a-cert"
+			Expect(config.Validate()).To(MatchError("Parsing CA certificate: Missing PEM block"))
+		})
+	})
+})
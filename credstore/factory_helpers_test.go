@@ -0,0 +1,54 @@
+package credstore_test
+
+import (
+	"encoding/pem"
+	gonet "net"
+	gourl "net/url"
+	"strconv"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/cloudfoundry/bosh-cli/credstore"
+)
+
+func buildClient(type_, path string) (Client, *ghttp.Server) {
+	server := ghttp.NewTLSServer()
+
+	host, port := hostAndPort(server.URL())
+
+	caCert := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.HTTPTestServer.Certificate().Raw,
+	})
+
+	config := Config{
+		Type:   type_,
+		Host:   host,
+		Port:   port,
+		Path:   path,
+		CACert: string(caCert),
+		Token:  "fake-token",
+	}
+
+	logger := boshlog.NewLogger(boshlog.LevelNone)
+
+	client, err := NewFactory(logger).New(config)
+	Expect(err).ToNot(HaveOccurred())
+
+	return client, server
+}
+
+func hostAndPort(url string) (string, int) {
+	parsedURL, err := gourl.Parse(url)
+	Expect(err).ToNot(HaveOccurred())
+
+	host, portStr, err := gonet.SplitHostPort(parsedURL.Host)
+	Expect(err).ToNot(HaveOccurred())
+
+	port, err := strconv.Atoi(portStr)
+	Expect(err).ToNot(HaveOccurred())
+
+	return host, port
+}
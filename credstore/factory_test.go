@@ -0,0 +1,54 @@
+package credstore_test
+
+import (
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/credstore"
+)
+
+var _ = Describe("Factory", func() {
+	var (
+		factory Factory
+	)
+
+	BeforeEach(func() {
+		factory = NewFactory(boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	Describe("New", func() {
+		It("returns credhub client", func() {
+			client, err := factory.New(Config{Type: "credhub", Host: "host", Port: 1, Token: "token"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client).To(BeAssignableToTypeOf(CredHubClient{}))
+		})
+
+		It("returns vault client", func() {
+			client, err := factory.New(Config{Type: "vault", Host: "host", Port: 1, Path: "secret", Token: "token"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client).To(BeAssignableToTypeOf(VaultClient{}))
+		})
+
+		It("returns error if config is invalid", func() {
+			_, err := factory.New(Config{Type: "vault"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Validating credential store connection config"))
+		})
+	})
+
+	It("does not trust servers signed by unknown CAs", func() {
+		_, server := buildClient("vault", "secret")
+		defer server.Close()
+
+		config := Config{Type: "vault", Path: "secret", Token: "token"}
+		config.Host, config.Port = hostAndPort(server.URL())
+
+		untrustingClient, err := factory.New(config)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, _, err = untrustingClient.Get("name")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("certificate"))
+	})
+})
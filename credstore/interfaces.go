@@ -0,0 +1,14 @@
+package credstore
+
+//go:generate counterfeiter . Client
+
+type Client interface {
+	// Get returns current value of a credential and whether it was found
+	Get(name string) (interface{}, bool, error)
+
+	// Set saves value of a credential; type is a variable type (e.g. password or certificate)
+	Set(name, type_ string, value interface{}) error
+
+	// List returns names of all credentials under configured path
+	List() ([]string, error)
+}
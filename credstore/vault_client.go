@@ -0,0 +1,76 @@
+package credstore
+
+import (
+	"net/http"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshhttp "github.com/cloudfoundry/bosh-utils/httpclient"
+)
+
+// VaultClient keeps each variable as a 'value' key of a Vault KV (version 1) secret
+type VaultClient struct {
+	path    string
+	request clientRequest
+}
+
+type vaultSecretResp struct {
+	Data struct {
+		Value interface{} `json:"value"`
+	} `json:"data"`
+}
+
+type vaultListResp struct {
+	Data struct {
+		Keys []string `json:"keys"`
+	} `json:"data"`
+}
+
+type vaultSecretReq struct {
+	Value interface{} `json:"value"`
+}
+
+func NewVaultClient(endpoint, path, token string, httpClient boshhttp.HTTPClient) VaultClient {
+	setHeaders := func(req *http.Request) {
+		req.Header.Set("X-Vault-Token", token)
+	}
+
+	return VaultClient{
+		path:    "/v1/" + path,
+		request: clientRequest{endpoint: endpoint, httpClient: httpClient, setHeaders: setHeaders},
+	}
+}
+
+func (c VaultClient) Get(name string) (interface{}, bool, error) {
+	var resp vaultSecretResp
+
+	found, err := c.request.Get(c.path+"/"+name, &resp)
+	if err != nil {
+		return nil, false, bosherr.WrapErrorf(err, "Getting secret '%s'", name)
+	}
+
+	if !found {
+		return nil, false, nil
+	}
+
+	return resp.Data.Value, true, nil
+}
+
+func (c VaultClient) Set(name, _ string, value interface{}) error {
+	err := c.request.Put(c.path+"/"+name, vaultSecretReq{Value: value})
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Setting secret '%s'", name)
+	}
+
+	return nil
+}
+
+func (c VaultClient) List() ([]string, error) {
+	var resp vaultListResp
+
+	_, err := c.request.Get(c.path+"?list=true", &resp)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Listing secrets")
+	}
+
+	return resp.Data.Keys, nil
+}
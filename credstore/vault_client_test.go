@@ -0,0 +1,99 @@
+package credstore_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/cloudfoundry/bosh-cli/credstore"
+)
+
+var _ = Describe("VaultClient", func() {
+	var (
+		client Client
+		server *ghttp.Server
+	)
+
+	BeforeEach(func() {
+		client, server = buildClient("vault", "secret/env")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("Get", func() {
+		It("returns value of secret", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/secret/env/admin_password"),
+					ghttp.VerifyHeaderKV("X-Vault-Token", "fake-token"),
+					ghttp.RespondWith(http.StatusOK, `{"data":{"value":"secret"}}`),
+				),
+			)
+
+			val, found, err := client.Get("admin_password")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(val).To(Equal("secret"))
+		})
+
+		It("returns not found if secret does not exist", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusNotFound, `{"errors":[]}`))
+
+			_, found, err := client.Get("missing")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+
+		It("returns error if response is not successful", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusForbidden, `{"errors":["permission denied"]}`))
+
+			_, _, err := client.Get("name")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Getting secret 'name'"))
+			Expect(err.Error()).To(ContainSubstring("permission denied"))
+		})
+	})
+
+	Describe("Set", func() {
+		It("writes value of secret", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v1/secret/env/cert"),
+					ghttp.VerifyHeaderKV("X-Vault-Token", "fake-token"),
+					ghttp.VerifyJSON(`{"value":{"ca":"ca"}}`),
+					ghttp.RespondWith(http.StatusNoContent, ""),
+				),
+			)
+
+			err := client.Set("cert", "certificate", map[string]string{"ca": "ca"})
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Describe("List", func() {
+		It("returns keys under path", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/secret/env", "list=true"),
+					ghttp.RespondWith(http.StatusOK, `{"data":{"keys":["a","b"]}}`),
+				),
+			)
+
+			names, err := client.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names).To(Equal([]string{"a", "b"}))
+		})
+
+		It("returns empty list if path does not exist", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusNotFound, ""))
+
+			names, err := client.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names).To(BeEmpty())
+		})
+	})
+})
@@ -34,13 +34,17 @@ func NewBuilderFactory(
 		releaseJobResolver:        releaseJobResolver,
 		jobRenderer:               jobRenderer,
 		renderedJobListCompressor: renderedJobListCompressor,
-		logger: logger,
+		logger:                    logger,
 	}
 }
 
 func (f *builderFactory) NewBuilder(blobstore biblobstore.Blobstore, agentClient biagentclient.AgentClient) Builder {
 	packageCompiler := NewRemotePackageCompiler(blobstore, agentClient, f.packageRepo)
-	jobDependencyCompiler := bistatejob.NewDependencyCompiler(packageCompiler, f.logger)
+
+	// Agent removes installed packages at the start and end of each compilation
+	// hence compiling multiple packages at the same time on a single VM
+	// would remove dependencies of packages that are still being compiled
+	jobDependencyCompiler := bistatejob.NewDependencyCompiler(packageCompiler, 1, f.logger)
 
 	return NewBuilder(
 		f.releaseJobResolver,
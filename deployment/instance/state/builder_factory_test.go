@@ -0,0 +1,141 @@
+package state_test
+
+import (
+	"sync"
+	"time"
+
+	biac "github.com/cloudfoundry/bosh-agent/agentclient"
+	fakebiac "github.com/cloudfoundry/bosh-agent/agentclient/fakes"
+	biindex "github.com/cloudfoundry/bosh-cli/index"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	mock_blobstore "github.com/cloudfoundry/bosh-cli/blobstore/mocks"
+	. "github.com/cloudfoundry/bosh-cli/deployment/instance/state"
+	bideplmanifest "github.com/cloudfoundry/bosh-cli/deployment/manifest"
+	mock_deployment_release "github.com/cloudfoundry/bosh-cli/deployment/release/mocks"
+	boshjob "github.com/cloudfoundry/bosh-cli/release/job"
+	boshpkg "github.com/cloudfoundry/bosh-cli/release/pkg"
+	. "github.com/cloudfoundry/bosh-cli/release/resource"
+	bistatepkg "github.com/cloudfoundry/bosh-cli/state/pkg"
+	mock_template "github.com/cloudfoundry/bosh-cli/templatescompiler/mocks"
+	fakebiui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+)
+
+var _ = Describe("BuilderFactory", func() {
+	var mockCtrl *gomock.Controller
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Describe("NewBuilder", func() {
+		var (
+			mockReleaseJobResolver     *mock_deployment_release.MockJobResolver
+			mockJobListRenderer        *mock_template.MockJobListRenderer
+			mockCompressor             *mock_template.MockRenderedJobListCompressor
+			mockRenderedJobList        *mock_template.MockRenderedJobList
+			mockRenderedJobListArchive *mock_template.MockRenderedJobListArchive
+			mockBlobstore              *mock_blobstore.MockBlobstore
+			fakeAgentClient            *fakebiac.FakeAgentClient
+
+			factory BuilderFactory
+		)
+
+		BeforeEach(func() {
+			logger := boshlog.NewLogger(boshlog.LevelNone)
+
+			mockReleaseJobResolver = mock_deployment_release.NewMockJobResolver(mockCtrl)
+			mockJobListRenderer = mock_template.NewMockJobListRenderer(mockCtrl)
+			mockCompressor = mock_template.NewMockRenderedJobListCompressor(mockCtrl)
+			mockRenderedJobList = mock_template.NewMockRenderedJobList(mockCtrl)
+			mockRenderedJobListArchive = mock_template.NewMockRenderedJobListArchive(mockCtrl)
+			mockBlobstore = mock_blobstore.NewMockBlobstore(mockCtrl)
+			fakeAgentClient = &fakebiac.FakeAgentClient{}
+
+			factory = NewBuilderFactory(
+				bistatepkg.NewCompiledPackageRepo(biindex.NewInMemoryIndex()),
+				mockReleaseJobResolver,
+				mockJobListRenderer,
+				mockCompressor,
+				logger,
+			)
+		})
+
+		It("returns builder that compiles packages on the agent one at a time", func() {
+			var pkgs []*boshpkg.Package
+
+			for _, name := range []string{"pkg1", "pkg2", "pkg3", "pkg4"} {
+				pkgs = append(pkgs, boshpkg.NewPackage(NewResourceWithBuiltArchive(
+					name, name+"-fp", name+"-path", name+"-sha1"), nil))
+			}
+
+			releaseJob := *boshjob.NewJob(NewResource("job-name", "job-fp", nil))
+			releaseJob.PackageNames = []string{"pkg1", "pkg2", "pkg3", "pkg4"}
+			err := releaseJob.AttachPackages(pkgs)
+			Expect(err).ToNot(HaveOccurred())
+
+			mockReleaseJobResolver.EXPECT().Resolve("job-name", "fake-release-name").Return(releaseJob, nil)
+
+			mockJobListRenderer.EXPECT().Render(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(mockRenderedJobList, nil)
+			mockRenderedJobList.EXPECT().DeleteSilently()
+			mockCompressor.EXPECT().Compress(mockRenderedJobList).Return(mockRenderedJobListArchive, nil)
+			mockRenderedJobListArchive.EXPECT().DeleteSilently()
+			mockRenderedJobListArchive.EXPECT().Path().Return("fake-rendered-job-list-archive-path")
+			mockRenderedJobListArchive.EXPECT().SHA1().Return("fake-rendered-job-list-archive-sha1")
+			mockRenderedJobListArchive.EXPECT().Fingerprint().Return("fake-rendered-job-list-fingerprint")
+
+			mockBlobstore.EXPECT().Add(gomock.Any()).Return("fake-blob-id", nil).AnyTimes()
+
+			var (
+				compilingLock sync.Mutex
+				compiling     int
+				maxCompiling  int
+			)
+
+			fakeAgentClient.CompilePackageStub = func(source biac.BlobRef, _ []biac.BlobRef) (biac.BlobRef, error) {
+				compilingLock.Lock()
+				compiling++
+				if compiling > maxCompiling {
+					maxCompiling = compiling
+				}
+				compilingLock.Unlock()
+
+				// Gives other compilations a chance to overlap
+				time.Sleep(10 * time.Millisecond)
+
+				compilingLock.Lock()
+				compiling--
+				compilingLock.Unlock()
+
+				return biac.BlobRef{Name: source.Name, Version: source.Version, BlobstoreID: source.Name + "-blob-id", SHA1: source.Name + "-sha1"}, nil
+			}
+
+			deploymentManifest := bideplmanifest.Manifest{
+				Name: "fake-deployment-name",
+				Jobs: []bideplmanifest.Job{
+					{
+						Name:      "fake-deployment-job-name",
+						Networks:  []bideplmanifest.JobNetwork{{Name: "fake-network-name", StaticIPs: []string{"1.2.3.4"}}},
+						Templates: []bideplmanifest.ReleaseJobRef{{Name: "job-name", Release: "fake-release-name"}},
+					},
+				},
+				Networks: []bideplmanifest.Network{{Name: "fake-network-name", Type: "fake-network-type"}},
+			}
+
+			builder := factory.NewBuilder(mockBlobstore, fakeAgentClient)
+
+			_, err = builder.Build("fake-deployment-job-name", 0, deploymentManifest, fakebiui.NewFakeStage(), biac.AgentState{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeAgentClient.CompilePackageCallCount()).To(Equal(4))
+			Expect(maxCompiling).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,133 @@
+package deployment
+
+import (
+	"fmt"
+
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	bidisk "github.com/cloudfoundry/bosh-cli/deployment/disk"
+	bideplmanifest "github.com/cloudfoundry/bosh-cli/deployment/manifest"
+	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+const (
+	PlannedActionUploadStemcell = "upload_stemcell"
+	PlannedActionDeleteVM       = "delete_vm"
+	PlannedActionCreateVM       = "create_vm"
+	PlannedActionCreateDisk     = "create_disk"
+	PlannedActionMigrateDisk    = "migrate"
+)
+
+type PlannedAction struct {
+	Name        string
+	Description string
+}
+
+type Planner interface {
+	Plan(biconfig.DeploymentState, bideplmanifest.Manifest, bistemcell.ExtractedStemcell) ([]PlannedAction, error)
+}
+
+type planner struct{}
+
+func NewPlanner() Planner {
+	return planner{}
+}
+
+// Plan returns CPI actions, in order, that Deployer would perform to converge
+// deployment state to the deployment manifest. It mirrors Deployer behaviour:
+// stemcell is uploaded unless already known, existing VM is always recreated
+// and persistent disk is created or migrated when its size or cloud properties change.
+func (p planner) Plan(
+	deploymentState biconfig.DeploymentState,
+	deploymentManifest bideplmanifest.Manifest,
+	extractedStemcell bistemcell.ExtractedStemcell,
+) ([]PlannedAction, error) {
+	actions := []PlannedAction{}
+
+	stemcellManifest := extractedStemcell.Manifest()
+
+	if !p.hasStemcell(deploymentState, stemcellManifest) {
+		actions = append(actions, PlannedAction{
+			Name:        PlannedActionUploadStemcell,
+			Description: fmt.Sprintf("Upload stemcell '%s/%s'", stemcellManifest.Name, stemcellManifest.Version),
+		})
+	}
+
+	if len(deploymentState.CurrentVMCID) > 0 {
+		actions = append(actions, PlannedAction{
+			Name:        PlannedActionDeleteVM,
+			Description: fmt.Sprintf("Delete VM '%s'", deploymentState.CurrentVMCID),
+		})
+	}
+
+	if len(deploymentManifest.Jobs) != 1 {
+		return actions, bosherr.Errorf("There must only be one job, found %d", len(deploymentManifest.Jobs))
+	}
+
+	jobName := deploymentManifest.JobName()
+
+	actions = append(actions, PlannedAction{
+		Name:        PlannedActionCreateVM,
+		Description: fmt.Sprintf("Create VM for instance '%s/0' from stemcell '%s/%s'", jobName, stemcellManifest.Name, stemcellManifest.Version),
+	})
+
+	diskPool, err := deploymentManifest.DiskPool(jobName)
+	if err != nil {
+		return actions, bosherr.WrapError(err, "Getting disk pool")
+	}
+
+	if diskPool.DiskSize == 0 {
+		return actions, nil
+	}
+
+	diskRecord, found := p.currentDisk(deploymentState)
+	if !found {
+		actions = append(actions, PlannedAction{
+			Name:        PlannedActionCreateDisk,
+			Description: fmt.Sprintf("Create disk of size %d", diskPool.DiskSize),
+		})
+
+		return actions, nil
+	}
+
+	// Cloud is not needed to check whether disk needs migration
+	disk := bidisk.NewDisk(diskRecord, nil, nil)
+
+	if disk.NeedsMigration(diskPool.DiskSize, diskPool.CloudProperties) {
+		actions = append(actions, PlannedAction{
+			Name:        PlannedActionCreateDisk,
+			Description: fmt.Sprintf("Create disk of size %d", diskPool.DiskSize),
+		})
+
+		actions = append(actions, PlannedAction{
+			Name:        PlannedActionMigrateDisk,
+			Description: fmt.Sprintf("Migrate disk content from '%s' (size %d) to new disk and delete '%s'", diskRecord.CID, diskRecord.Size, diskRecord.CID),
+		})
+	}
+
+	return actions, nil
+}
+
+func (p planner) hasStemcell(deploymentState biconfig.DeploymentState, stemcellManifest bistemcell.Manifest) bool {
+	for _, stemcellRecord := range deploymentState.Stemcells {
+		if stemcellRecord.Name == stemcellManifest.Name && stemcellRecord.Version == stemcellManifest.Version {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p planner) currentDisk(deploymentState biconfig.DeploymentState) (biconfig.DiskRecord, bool) {
+	if len(deploymentState.CurrentDiskID) == 0 {
+		return biconfig.DiskRecord{}, false
+	}
+
+	for _, diskRecord := range deploymentState.Disks {
+		if diskRecord.ID == deploymentState.CurrentDiskID {
+			return diskRecord, true
+		}
+	}
+
+	return biconfig.DiskRecord{}, false
+}
@@ -0,0 +1,157 @@
+package deployment_test
+
+import (
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	. "github.com/cloudfoundry/bosh-cli/deployment"
+	bideplmanifest "github.com/cloudfoundry/bosh-cli/deployment/manifest"
+	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
+)
+
+var _ = Describe("Planner", func() {
+	var (
+		planner            Planner
+		deploymentState    biconfig.DeploymentState
+		deploymentManifest bideplmanifest.Manifest
+		stemcell           bistemcell.ExtractedStemcell
+	)
+
+	BeforeEach(func() {
+		planner = NewPlanner()
+
+		deploymentState = biconfig.DeploymentState{}
+
+		deploymentManifest = bideplmanifest.Manifest{
+			Name: "fake-deployment-name",
+			Jobs: []bideplmanifest.Job{
+				{
+					Name:           "fake-job-name",
+					Instances:      1,
+					PersistentDisk: 1024,
+				},
+			},
+		}
+
+		stemcell = bistemcell.NewExtractedStemcell(
+			bistemcell.Manifest{
+				Name:    "fake-stemcell-name",
+				Version: "fake-stemcell-version",
+			},
+			"fake-extracted-path",
+			fakesys.NewFakeFileSystem(),
+		)
+	})
+
+	Context("when nothing has been deployed", func() {
+		It("plans to upload stemcell, create vm and create disk", func() {
+			actions, err := planner.Plan(deploymentState, deploymentManifest, stemcell)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actions).To(Equal([]PlannedAction{
+				{Name: "upload_stemcell", Description: "Upload stemcell 'fake-stemcell-name/fake-stemcell-version'"},
+				{Name: "create_vm", Description: "Create VM for instance 'fake-job-name/0' from stemcell 'fake-stemcell-name/fake-stemcell-version'"},
+				{Name: "create_disk", Description: "Create disk of size 1024"},
+			}))
+		})
+
+		It("does not plan to create disk when job does not have persistent disk", func() {
+			deploymentManifest.Jobs[0].PersistentDisk = 0
+
+			actions, err := planner.Plan(deploymentState, deploymentManifest, stemcell)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actions).To(HaveLen(2))
+			Expect(actions[1].Name).To(Equal("create_vm"))
+		})
+	})
+
+	Context("when deployment exists", func() {
+		BeforeEach(func() {
+			deploymentState = biconfig.DeploymentState{
+				CurrentVMCID:      "fake-vm-cid",
+				CurrentStemcellID: "fake-stemcell-id",
+				CurrentDiskID:     "fake-disk-id",
+				Stemcells: []biconfig.StemcellRecord{
+					{ID: "fake-stemcell-id", Name: "fake-stemcell-name", Version: "fake-stemcell-version", CID: "fake-stemcell-cid"},
+				},
+				Disks: []biconfig.DiskRecord{
+					{ID: "fake-disk-id", CID: "fake-disk-cid", Size: 1024, CloudProperties: biproperty.Map{}},
+				},
+			}
+		})
+
+		It("plans to recreate vm and keep existing stemcell and disk", func() {
+			actions, err := planner.Plan(deploymentState, deploymentManifest, stemcell)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actions).To(Equal([]PlannedAction{
+				{Name: "delete_vm", Description: "Delete VM 'fake-vm-cid'"},
+				{Name: "create_vm", Description: "Create VM for instance 'fake-job-name/0' from stemcell 'fake-stemcell-name/fake-stemcell-version'"},
+			}))
+		})
+
+		It("plans to upload stemcell when stemcell version changed", func() {
+			deploymentState.Stemcells[0].Version = "fake-old-stemcell-version"
+
+			actions, err := planner.Plan(deploymentState, deploymentManifest, stemcell)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actions[0].Name).To(Equal("upload_stemcell"))
+			Expect(actions[1].Name).To(Equal("delete_vm"))
+		})
+
+		It("plans to migrate disk when disk size changed", func() {
+			deploymentManifest.Jobs[0].PersistentDisk = 2048
+
+			actions, err := planner.Plan(deploymentState, deploymentManifest, stemcell)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actions).To(Equal([]PlannedAction{
+				{Name: "delete_vm", Description: "Delete VM 'fake-vm-cid'"},
+				{Name: "create_vm", Description: "Create VM for instance 'fake-job-name/0' from stemcell 'fake-stemcell-name/fake-stemcell-version'"},
+				{Name: "create_disk", Description: "Create disk of size 2048"},
+				{Name: "migrate", Description: "Migrate disk content from 'fake-disk-cid' (size 1024) to new disk and delete 'fake-disk-cid'"},
+			}))
+		})
+
+		It("plans to migrate disk when disk cloud properties changed", func() {
+			deploymentManifest.Jobs[0].PersistentDisk = 0
+			deploymentManifest.Jobs[0].PersistentDiskPool = "fake-disk-pool"
+			deploymentManifest.DiskPools = []bideplmanifest.DiskPool{
+				{
+					Name:            "fake-disk-pool",
+					DiskSize:        1024,
+					CloudProperties: biproperty.Map{"type": "ssd"},
+				},
+			}
+
+			actions, err := planner.Plan(deploymentState, deploymentManifest, stemcell)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actions).To(HaveLen(4))
+			Expect(actions[3].Name).To(Equal("migrate"))
+		})
+
+		It("plans to create disk when current disk record is missing", func() {
+			deploymentState.CurrentDiskID = ""
+
+			actions, err := planner.Plan(deploymentState, deploymentManifest, stemcell)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actions[2]).To(Equal(PlannedAction{Name: "create_disk", Description: "Create disk of size 1024"}))
+		})
+	})
+
+	It("returns an error when deployment manifest has more than one job", func() {
+		deploymentManifest.Jobs = append(deploymentManifest.Jobs, bideplmanifest.Job{Name: "fake-other-job-name"})
+
+		_, err := planner.Plan(deploymentState, deploymentManifest, stemcell)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("There must only be one job, found 2"))
+	})
+
+	It("returns an error when disk pool cannot be found", func() {
+		deploymentManifest.Jobs[0].PersistentDiskPool = "fake-missing-disk-pool"
+
+		_, err := planner.Plan(deploymentState, deploymentManifest, stemcell)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Getting disk pool"))
+	})
+})
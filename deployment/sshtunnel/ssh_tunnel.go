@@ -12,6 +12,8 @@ import (
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	"github.com/pivotal-golang/clock"
+
+	boshproxy "github.com/cloudfoundry/bosh-cli/proxy"
 )
 
 type SSHTunnel interface {
@@ -79,7 +81,7 @@ func (s *sshTunnel) Start(readyErrCh chan<- error, errCh chan<- error) {
 	var err error
 	for i := 0; ; i++ {
 		s.logger.Debug(s.logTag, "Making attempt #%d", i)
-		conn, err = ssh.Dial("tcp", remoteAddr, sshConfig)
+		conn, err = s.dial(remoteAddr, sshConfig)
 
 		if err == nil {
 			break
@@ -152,6 +154,22 @@ func (s *sshTunnel) Start(readyErrCh chan<- error, errCh chan<- error) {
 	}
 }
 
+// dial is similar to ssh.Dial but respects BOSH_ALL_PROXY
+func (s *sshTunnel) dial(addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	conn, err := boshproxy.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
 func (s *sshTunnel) Stop() error {
 	if s.remoteListener == nil {
 		return nil
@@ -10,6 +10,9 @@ import (
 type Client struct {
 	clientRequest     ClientRequest
 	taskClientRequest TaskClientRequest
+
+	uploadAttempts   int
+	uploadRetryDelay time.Duration
 }
 
 func NewClient(
@@ -21,5 +24,12 @@ func NewClient(
 ) Client {
 	clientRequest := NewClientRequest(endpoint, httpClient, fileReporter, logger)
 	taskClientRequest := NewTaskClientRequest(clientRequest, taskReporter, 500*time.Millisecond)
-	return Client{clientRequest, taskClientRequest}
+
+	return Client{
+		clientRequest:     clientRequest,
+		taskClientRequest: taskClientRequest,
+
+		uploadAttempts:   5,
+		uploadRetryDelay: 1 * time.Second,
+	}
 }
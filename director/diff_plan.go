@@ -0,0 +1,591 @@
+package director
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const DeploymentPlanSchemaVersion = 1
+
+// DeploymentPlan is a structured summary of a deployment diff;
+// fields are only ever added to keep schema stable for consumers
+type DeploymentPlan struct {
+	SchemaVersion int `json:"schema_version"`
+
+	Context map[string]interface{} `json:"context"`
+
+	HasChanges   bool `json:"has_changes"`
+	RecreatesVMs bool `json:"recreates_vms"`
+
+	InstanceGroups []InstanceGroupPlan `json:"instance_groups"`
+	Releases       []VersionedPlan     `json:"releases"`
+	Stemcells      []VersionedPlan     `json:"stemcells"`
+	Properties     []PropertyPlan      `json:"properties"`
+
+	// Other top level sections that changed (e.g. update, variables, addons)
+	OtherSections []string `json:"other_sections"`
+}
+
+type InstanceGroupPlan struct {
+	Name   string `json:"name"`
+	Change string `json:"change"` // added, removed or changed
+
+	RecreatesVMs    bool     `json:"recreates_vms"`
+	RecreateReasons []string `json:"recreate_reasons"`
+
+	ChangedKeys []string       `json:"changed_keys"`
+	Jobs        []string       `json:"jobs"`
+	Properties  []PropertyPlan `json:"properties"`
+}
+
+type VersionedPlan struct {
+	Name   string `json:"name"`
+	Change string `json:"change"` // added, removed or changed
+
+	OldVersion string `json:"old_version,omitempty"`
+	NewVersion string `json:"new_version,omitempty"`
+}
+
+type PropertyPlan struct {
+	Path   string `json:"path"`
+	Change string `json:"change"` // added, removed or changed
+}
+
+const (
+	planChangeAdded   = "added"
+	planChangeRemoved = "removed"
+	planChangeChanged = "changed"
+)
+
+// Instance group keys that result in VMs being recreated when changed
+var planRecreateKeys = map[string]struct{}{
+	"azs":           struct{}{},
+	"env":           struct{}{},
+	"networks":      struct{}{},
+	"resource_pool": struct{}{},
+	"stemcell":      struct{}{},
+	"vm_extensions": struct{}{},
+	"vm_resources":  struct{}{},
+	"vm_type":       struct{}{},
+}
+
+// Plan builds structured deployment plan from diff lines and context.
+// Diff lines are parsed as YAML documents (before and after the change,
+// as well as unchanged lines only) that are then compared by location
+// since redacted values may stay the same even though they changed.
+func (d DeploymentDiff) Plan() (DeploymentPlan, error) {
+	plan := DeploymentPlan{
+		SchemaVersion:  DeploymentPlanSchemaVersion,
+		Context:        d.context,
+		InstanceGroups: []InstanceGroupPlan{},
+		Releases:       []VersionedPlan{},
+		Stemcells:      []VersionedPlan{},
+		Properties:     []PropertyPlan{},
+		OtherSections:  []string{},
+	}
+
+	if plan.Context == nil {
+		plan.Context = map[string]interface{}{}
+	}
+
+	lines, err := diffPlanLines(d.Diff)
+	if err != nil {
+		return DeploymentPlan{}, err
+	}
+
+	builder := newPlanBuilder()
+
+	for _, line := range lines {
+		builder.Add(line)
+	}
+
+	builder.Fill(&plan)
+
+	return plan, nil
+}
+
+type diffPlanLine struct {
+	Path   []diffPlanNode
+	Value  string
+	Change string
+}
+
+type diffPlanNode struct {
+	isItem bool
+
+	// Key for keys, or identifying name for list items
+	Name string
+}
+
+// diffPlanLines returns changed leaf values ordered as in the new manifest
+// followed by values that were only present in the old manifest
+func diffPlanLines(diff [][]interface{}) ([]diffPlanLine, error) {
+	var unchanged, before, after []string
+
+	for _, rawLine := range diff {
+		if len(rawLine) == 0 {
+			continue
+		}
+
+		text, _ := rawLine[0].(string)
+
+		var change string
+
+		if len(rawLine) > 1 {
+			change, _ = rawLine[1].(string)
+		}
+
+		switch change {
+		case planChangeAdded:
+			after = append(after, text)
+		case planChangeRemoved:
+			before = append(before, text)
+		default:
+			unchanged = append(unchanged, text)
+			before = append(before, text)
+			after = append(after, text)
+		}
+	}
+
+	unchangedLeaves, err := diffPlanLeaves(unchanged)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeLeaves, err := diffPlanLeaves(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterLeaves, err := diffPlanLeaves(after)
+	if err != nil {
+		return nil, err
+	}
+
+	unchangedKeys := diffPlanLeafKeys(unchangedLeaves)
+	beforeKeys := diffPlanLeafKeys(beforeLeaves)
+	afterKeys := diffPlanLeafKeys(afterLeaves)
+
+	var lines []diffPlanLine
+
+	for _, leaf := range afterLeaves {
+		key := diffPlanLeafKey(leaf.Path)
+		if _, found := unchangedKeys[key]; found {
+			continue
+		}
+
+		if beforeLeaf, found := beforeKeys[key]; found {
+			lines = append(lines, diffPlanLine{Path: leaf.Path, Value: beforeLeaf.Value, Change: planChangeRemoved})
+		}
+
+		lines = append(lines, diffPlanLine{Path: leaf.Path, Value: leaf.Value, Change: planChangeAdded})
+	}
+
+	for _, leaf := range beforeLeaves {
+		key := diffPlanLeafKey(leaf.Path)
+		if _, found := unchangedKeys[key]; found {
+			continue
+		}
+
+		if _, found := afterKeys[key]; !found {
+			lines = append(lines, diffPlanLine{Path: leaf.Path, Value: leaf.Value, Change: planChangeRemoved})
+		}
+	}
+
+	return lines, nil
+}
+
+// diffPlanLeaves parses lines as YAML and returns location of each scalar value
+func diffPlanLeaves(lines []string) ([]diffPlanLine, error) {
+	var doc yaml.MapSlice
+
+	err := yaml.Unmarshal([]byte(strings.Join(lines, "\n")), &doc)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Parsing deployment diff")
+	}
+
+	var leaves []diffPlanLine
+
+	diffPlanCollectLeaves(doc, nil, &leaves)
+
+	return leaves, nil
+}
+
+func diffPlanCollectLeaves(val interface{}, path []diffPlanNode, leaves *[]diffPlanLine) {
+	switch typedVal := val.(type) {
+	case yaml.MapSlice:
+		if len(typedVal) > 0 {
+			for _, item := range typedVal {
+				key := fmt.Sprintf("%v", item.Key)
+				diffPlanCollectLeaves(item.Value, diffPlanAppendNode(path, diffPlanNode{Name: key}), leaves)
+			}
+			return
+		}
+
+	case []interface{}:
+		if len(typedVal) > 0 {
+			for i, item := range typedVal {
+				node := diffPlanNode{isItem: true, Name: diffPlanItemName(item, i)}
+				diffPlanCollectLeaves(item, diffPlanAppendNode(path, node), leaves)
+			}
+			return
+		}
+	}
+
+	var value string
+
+	if val != nil {
+		value = fmt.Sprintf("%v", val)
+	}
+
+	*leaves = append(*leaves, diffPlanLine{Path: path, Value: value})
+}
+
+// diffPlanItemName identifies list items by their name (or alias for stemcells)
+// falling back to their position in the list
+func diffPlanItemName(item interface{}, i int) string {
+	switch typedItem := item.(type) {
+	case yaml.MapSlice:
+		for _, key := range []string{"name", "alias"} {
+			for _, pair := range typedItem {
+				if pair.Key == key && pair.Value != nil {
+					return fmt.Sprintf("%v", pair.Value)
+				}
+			}
+		}
+		return strconv.Itoa(i)
+
+	case []interface{}:
+		return strconv.Itoa(i)
+
+	default:
+		return fmt.Sprintf("%v", typedItem)
+	}
+}
+
+func diffPlanAppendNode(path []diffPlanNode, node diffPlanNode) []diffPlanNode {
+	return append(append([]diffPlanNode{}, path...), node)
+}
+
+func diffPlanLeafKeys(leaves []diffPlanLine) map[string]diffPlanLine {
+	keys := map[string]diffPlanLine{}
+
+	for _, leaf := range leaves {
+		keys[diffPlanLeafKey(leaf.Path)] = leaf
+	}
+
+	return keys
+}
+
+func diffPlanLeafKey(path []diffPlanNode) string {
+	var pieces []string
+
+	for _, node := range path {
+		if node.isItem {
+			pieces = append(pieces, "name="+node.Name)
+		} else {
+			pieces = append(pieces, node.Name)
+		}
+	}
+
+	return strings.Join(pieces, "/")
+}
+
+type planBuilder struct {
+	groups     map[string]*planGroupBuilder
+	groupNames []string
+
+	releases  *planVersionedBuilder
+	stemcells *planVersionedBuilder
+
+	properties *planPropertiesBuilder
+
+	otherSections map[string]struct{}
+}
+
+func newPlanBuilder() *planBuilder {
+	return &planBuilder{
+		groups:        map[string]*planGroupBuilder{},
+		releases:      newPlanVersionedBuilder(),
+		stemcells:     newPlanVersionedBuilder(),
+		properties:    newPlanPropertiesBuilder(),
+		otherSections: map[string]struct{}{},
+	}
+}
+
+func (b *planBuilder) Add(line diffPlanLine) {
+	if len(line.Path) == 0 {
+		return
+	}
+
+	section := line.Path[0].Name
+
+	switch section {
+	case "instance_groups", "jobs":
+		if len(line.Path) < 2 || !line.Path[1].isItem {
+			return
+		}
+
+		name := line.Path[1].Name
+
+		group, found := b.groups[name]
+		if !found {
+			group = newPlanGroupBuilder()
+			b.groups[name] = group
+			b.groupNames = append(b.groupNames, name)
+		}
+
+		group.Add(line.Path[2:], line)
+
+	case "releases":
+		b.releases.Add(line.Path[1:], line)
+
+	case "stemcells":
+		b.stemcells.Add(line.Path[1:], line)
+
+	case "properties":
+		b.properties.Add(diffPlanPropertyPath(line.Path[1:]), line.Change)
+
+	default:
+		b.otherSections[section] = struct{}{}
+	}
+}
+
+func (b *planBuilder) Fill(plan *DeploymentPlan) {
+	for _, name := range b.groupNames {
+		groupPlan := b.groups[name].Plan(name)
+
+		if groupPlan.RecreatesVMs {
+			plan.RecreatesVMs = true
+		}
+
+		plan.InstanceGroups = append(plan.InstanceGroups, groupPlan)
+	}
+
+	plan.Releases = b.releases.Plans()
+	plan.Stemcells = b.stemcells.Plans()
+	plan.Properties = b.properties.Plans()
+
+	// Stemcell version changes recreate VMs of instance groups that use them
+	for _, stemcell := range plan.Stemcells {
+		if stemcell.Change != planChangeAdded {
+			plan.RecreatesVMs = true
+		}
+	}
+
+	for section, _ := range b.otherSections {
+		plan.OtherSections = append(plan.OtherSections, section)
+	}
+
+	sort.Strings(plan.OtherSections)
+
+	plan.HasChanges = len(plan.InstanceGroups) > 0 || len(plan.Releases) > 0 ||
+		len(plan.Stemcells) > 0 || len(plan.Properties) > 0 || len(plan.OtherSections) > 0
+}
+
+type planGroupBuilder struct {
+	nameChange string
+
+	changedKeys map[string]struct{}
+	jobs        map[string]struct{}
+	properties  *planPropertiesBuilder
+}
+
+func newPlanGroupBuilder() *planGroupBuilder {
+	return &planGroupBuilder{
+		changedKeys: map[string]struct{}{},
+		jobs:        map[string]struct{}{},
+		properties:  newPlanPropertiesBuilder(),
+	}
+}
+
+func (b *planGroupBuilder) Add(path []diffPlanNode, line diffPlanLine) {
+	if len(path) == 0 {
+		return
+	}
+
+	key := path[0].Name
+
+	if key == "name" && len(path) == 1 {
+		b.nameChange = diffPlanMergeChange(b.nameChange, line.Change)
+		return
+	}
+
+	b.changedKeys[key] = struct{}{}
+
+	switch key {
+	case "jobs", "templates":
+		if len(path) > 1 && path[1].isItem {
+			jobName := path[1].Name
+			b.jobs[jobName] = struct{}{}
+
+			for i, node := range path[2:] {
+				if node.Name == "properties" && !node.isItem {
+					propPath := diffPlanPropertyPath(path[2+i+1:])
+					if len(propPath) > 0 {
+						b.properties.Add(jobName+"."+propPath, line.Change)
+					}
+					break
+				}
+			}
+		}
+
+	case "properties":
+		if propPath := diffPlanPropertyPath(path[1:]); len(propPath) > 0 {
+			b.properties.Add(propPath, line.Change)
+		}
+	}
+}
+
+func (b *planGroupBuilder) Plan(name string) InstanceGroupPlan {
+	plan := InstanceGroupPlan{
+		Name:            name,
+		Change:          planChangeChanged,
+		RecreateReasons: []string{},
+		ChangedKeys:     []string{},
+		Jobs:            []string{},
+		Properties:      b.properties.Plans(),
+	}
+
+	if b.nameChange == planChangeAdded || b.nameChange == planChangeRemoved {
+		plan.Change = b.nameChange
+	}
+
+	for key, _ := range b.changedKeys {
+		plan.ChangedKeys = append(plan.ChangedKeys, key)
+
+		if _, found := planRecreateKeys[key]; found && plan.Change == planChangeChanged {
+			plan.RecreateReasons = append(plan.RecreateReasons, key)
+		}
+	}
+
+	for job, _ := range b.jobs {
+		plan.Jobs = append(plan.Jobs, job)
+	}
+
+	sort.Strings(plan.ChangedKeys)
+	sort.Strings(plan.RecreateReasons)
+	sort.Strings(plan.Jobs)
+
+	plan.RecreatesVMs = len(plan.RecreateReasons) > 0
+
+	return plan
+}
+
+type planVersionedBuilder struct {
+	names []string
+	plans map[string]*VersionedPlan
+
+	// Track whether entry's identifying line was added/removed
+	nameChanges map[string]string
+}
+
+func newPlanVersionedBuilder() *planVersionedBuilder {
+	return &planVersionedBuilder{
+		plans:       map[string]*VersionedPlan{},
+		nameChanges: map[string]string{},
+	}
+}
+
+func (b *planVersionedBuilder) Add(path []diffPlanNode, line diffPlanLine) {
+	if len(path) < 2 || !path[0].isItem {
+		return
+	}
+
+	name := path[0].Name
+
+	plan, found := b.plans[name]
+	if !found {
+		plan = &VersionedPlan{Name: name, Change: planChangeChanged}
+		b.plans[name] = plan
+		b.names = append(b.names, name)
+	}
+
+	key := path[1].Name
+
+	switch {
+	case len(path) == 2 && (key == "name" || key == "alias") && line.Value == name:
+		b.nameChanges[name] = diffPlanMergeChange(b.nameChanges[name], line.Change)
+
+	case len(path) == 2 && key == "version":
+		if line.Change == planChangeRemoved {
+			plan.OldVersion = line.Value
+		} else if line.Change == planChangeAdded {
+			plan.NewVersion = line.Value
+		}
+	}
+}
+
+func (b *planVersionedBuilder) Plans() []VersionedPlan {
+	plans := []VersionedPlan{}
+
+	for _, name := range b.names {
+		plan := *b.plans[name]
+
+		if change := b.nameChanges[name]; change == planChangeAdded || change == planChangeRemoved {
+			plan.Change = change
+		}
+
+		plans = append(plans, plan)
+	}
+
+	return plans
+}
+
+type planPropertiesBuilder struct {
+	paths   []string
+	changes map[string]string
+}
+
+func newPlanPropertiesBuilder() *planPropertiesBuilder {
+	return &planPropertiesBuilder{changes: map[string]string{}}
+}
+
+func (b *planPropertiesBuilder) Add(path, change string) {
+	if len(path) == 0 {
+		return
+	}
+
+	existing, found := b.changes[path]
+	if !found {
+		b.paths = append(b.paths, path)
+	}
+
+	b.changes[path] = diffPlanMergeChange(existing, change)
+}
+
+func (b *planPropertiesBuilder) Plans() []PropertyPlan {
+	plans := []PropertyPlan{}
+
+	for _, path := range b.paths {
+		plans = append(plans, PropertyPlan{Path: path, Change: b.changes[path]})
+	}
+
+	return plans
+}
+
+func diffPlanPropertyPath(path []diffPlanNode) string {
+	var pieces []string
+
+	for _, node := range path {
+		if len(node.Name) > 0 {
+			pieces = append(pieces, node.Name)
+		}
+	}
+
+	return strings.Join(pieces, ".")
+}
+
+// diffPlanMergeChange considers same location being both added and removed as changed
+func diffPlanMergeChange(existing, change string) string {
+	if len(existing) == 0 || existing == change {
+		return change
+	}
+
+	return planChangeChanged
+}
@@ -0,0 +1,251 @@
+package director_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/director"
+)
+
+var _ = Describe("DeploymentDiff", func() {
+	Describe("Plan", func() {
+		It("returns empty plan without changes", func() {
+			diff := NewDeploymentDiff([][]interface{}{
+				[]interface{}{"name: dep", nil},
+			}, nil)
+
+			plan, err := diff.Plan()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(plan).To(Equal(DeploymentPlan{
+				SchemaVersion:  1,
+				Context:        map[string]interface{}{},
+				InstanceGroups: []InstanceGroupPlan{},
+				Releases:       []VersionedPlan{},
+				Stemcells:      []VersionedPlan{},
+				Properties:     []PropertyPlan{},
+				OtherSections:  []string{},
+			}))
+		})
+
+		It("includes diff context", func() {
+			context := map[string]interface{}{"cloud_config_id": 2, "runtime_config_ids": []interface{}{3}}
+
+			plan, err := NewDeploymentDiff(nil, context).Plan()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.Context).To(Equal(context))
+		})
+
+		It("summarizes instance group changes", func() {
+			diff := NewDeploymentDiff([][]interface{}{
+				[]interface{}{"instance_groups:", nil},
+				[]interface{}{"- name: redis", nil},
+				[]interface{}{"  instances: 1", "removed"},
+				[]interface{}{"  instances: 2", "added"},
+				[]interface{}{"  jobs:", nil},
+				[]interface{}{"  - name: redis-server", nil},
+				[]interface{}{"    properties:", nil},
+				[]interface{}{"      redis:", nil},
+				[]interface{}{"        port: \"<redacted>\"", "removed"},
+				[]interface{}{"        port: \"<redacted>\"", "added"},
+				[]interface{}{"        maxclients: \"<redacted>\"", "added"},
+				[]interface{}{"  - name: syslog", "added"},
+				[]interface{}{"    release: syslog", "added"},
+				[]interface{}{"- name: web", nil},
+				[]interface{}{"  vm_type: small", "removed"},
+				[]interface{}{"  vm_type: large", "added"},
+				[]interface{}{"  networks:", nil},
+				[]interface{}{"  - name: default", nil},
+				[]interface{}{"    static_ips:", nil},
+				[]interface{}{"    - 10.0.0.1", "removed"},
+				[]interface{}{"  properties:", nil},
+				[]interface{}{"    port: \"<redacted>\"", "removed"},
+				[]interface{}{"- name: worker", "added"},
+				[]interface{}{"  instances: 1", "added"},
+				[]interface{}{"  vm_type: small", "added"},
+				[]interface{}{"- name: old", "removed"},
+				[]interface{}{"  instances: 1", "removed"},
+			}, nil)
+
+			plan, err := diff.Plan()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.HasChanges).To(BeTrue())
+			Expect(plan.RecreatesVMs).To(BeTrue())
+
+			Expect(plan.InstanceGroups).To(Equal([]InstanceGroupPlan{
+				{
+					Name:            "redis",
+					Change:          "changed",
+					RecreatesVMs:    false,
+					RecreateReasons: []string{},
+					ChangedKeys:     []string{"instances", "jobs"},
+					Jobs:            []string{"redis-server", "syslog"},
+					Properties: []PropertyPlan{
+						{Path: "redis-server.redis.port", Change: "changed"},
+						{Path: "redis-server.redis.maxclients", Change: "added"},
+					},
+				},
+				{
+					Name:            "web",
+					Change:          "changed",
+					RecreatesVMs:    true,
+					RecreateReasons: []string{"networks", "vm_type"},
+					ChangedKeys:     []string{"networks", "properties", "vm_type"},
+					Jobs:            []string{},
+					Properties:      []PropertyPlan{{Path: "port", Change: "removed"}},
+				},
+				{
+					Name:            "worker",
+					Change:          "added",
+					RecreateReasons: []string{},
+					ChangedKeys:     []string{"instances", "vm_type"},
+					Jobs:            []string{},
+					Properties:      []PropertyPlan{},
+				},
+				{
+					Name:            "old",
+					Change:          "removed",
+					RecreateReasons: []string{},
+					ChangedKeys:     []string{"instances"},
+					Jobs:            []string{},
+					Properties:      []PropertyPlan{},
+				},
+			}))
+		})
+
+		It("does not consider VMs recreated when only jobs change", func() {
+			diff := NewDeploymentDiff([][]interface{}{
+				[]interface{}{"instance_groups:", nil},
+				[]interface{}{"- name: redis", nil},
+				[]interface{}{"  jobs:", nil},
+				[]interface{}{"  - name: syslog", "added"},
+			}, nil)
+
+			plan, err := diff.Plan()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.HasChanges).To(BeTrue())
+			Expect(plan.RecreatesVMs).To(BeFalse())
+		})
+
+		It("summarizes release changes", func() {
+			diff := NewDeploymentDiff([][]interface{}{
+				[]interface{}{"releases:", nil},
+				[]interface{}{"- name: redis", nil},
+				[]interface{}{"  version: 1", "removed"},
+				[]interface{}{"  version: 2", "added"},
+				[]interface{}{"- name: syslog", "added"},
+				[]interface{}{"  version: 11", "added"},
+				[]interface{}{"- name: old", "removed"},
+				[]interface{}{"  version: 3", "removed"},
+			}, nil)
+
+			plan, err := diff.Plan()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.RecreatesVMs).To(BeFalse())
+			Expect(plan.Releases).To(Equal([]VersionedPlan{
+				{Name: "redis", Change: "changed", OldVersion: "1", NewVersion: "2"},
+				{Name: "syslog", Change: "added", NewVersion: "11"},
+				{Name: "old", Change: "removed", OldVersion: "3"},
+			}))
+		})
+
+		It("summarizes stemcell changes and considers VMs recreated", func() {
+			diff := NewDeploymentDiff([][]interface{}{
+				[]interface{}{"stemcells:", nil},
+				[]interface{}{"- alias: default", nil},
+				[]interface{}{"  os: ubuntu-trusty", nil},
+				[]interface{}{"  version: '3421.1'", "removed"},
+				[]interface{}{"  version: '3421.2'", "added"},
+			}, nil)
+
+			plan, err := diff.Plan()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.RecreatesVMs).To(BeTrue())
+			Expect(plan.Stemcells).To(Equal([]VersionedPlan{
+				{Name: "default", Change: "changed", OldVersion: "3421.1", NewVersion: "3421.2"},
+			}))
+		})
+
+		It("summarizes top level property and other section changes", func() {
+			diff := NewDeploymentDiff([][]interface{}{
+				[]interface{}{"name: simple manifest", nil},
+				[]interface{}{"properties:", nil},
+				[]interface{}{"  - property1", "removed"},
+				[]interface{}{"  - property2", "added"},
+				[]interface{}{"update:", nil},
+				[]interface{}{"  canaries: 1", "removed"},
+				[]interface{}{"  canaries: 2", "added"},
+				[]interface{}{"variables:", nil},
+				[]interface{}{"- name: password", "added"},
+			}, nil)
+
+			plan, err := diff.Plan()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.HasChanges).To(BeTrue())
+			Expect(plan.RecreatesVMs).To(BeFalse())
+			Expect(plan.Properties).To(Equal([]PropertyPlan{
+				{Path: "property2", Change: "added"},
+				{Path: "property1", Change: "removed"},
+			}))
+			Expect(plan.OtherSections).To(Equal([]string{"update", "variables"}))
+		})
+
+		It("considers redacted values changed even though their text stays the same", func() {
+			diff := NewDeploymentDiff([][]interface{}{
+				[]interface{}{"instance_groups:", nil},
+				[]interface{}{"- name: redis", nil},
+				[]interface{}{"  properties:", nil},
+				[]interface{}{"    password: \"<redacted>\"", "removed"},
+				[]interface{}{"    password: \"<redacted>\"", "added"},
+				[]interface{}{"    port: \"<redacted>\"", nil},
+			}, nil)
+
+			plan, err := diff.Plan()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.InstanceGroups).To(HaveLen(1))
+			Expect(plan.InstanceGroups[0].Properties).To(Equal([]PropertyPlan{
+				{Path: "password", Change: "changed"},
+			}))
+		})
+
+		It("returns error if diff lines do not form YAML document", func() {
+			diff := NewDeploymentDiff([][]interface{}{
+				[]interface{}{"instance_groups:", nil},
+				[]interface{}{"- name: [redis", "added"},
+			}, nil)
+
+			_, err := diff.Plan()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Parsing deployment diff"))
+		})
+
+		It("serializes with a stable schema", func() {
+			diff := NewDeploymentDiff([][]interface{}{
+				[]interface{}{"releases:", nil},
+				[]interface{}{"- name: redis", nil},
+				[]interface{}{"  version: 1", "removed"},
+				[]interface{}{"  version: 2", "added"},
+			}, map[string]interface{}{"cloud_config_id": 2})
+
+			plan, err := diff.Plan()
+			Expect(err).ToNot(HaveOccurred())
+
+			bytes, err := json.Marshal(plan)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(bytes).To(MatchJSON(`{
+				"schema_version": 1,
+				"context": {"cloud_config_id": 2},
+				"has_changes": true,
+				"recreates_vms": false,
+				"instance_groups": [],
+				"releases": [{"name": "redis", "change": "changed", "old_version": "1", "new_version": "2"}],
+				"stemcells": [],
+				"properties": [],
+				"other_sections": []
+			}`))
+		})
+	})
+})
@@ -129,12 +129,13 @@ type FakeDirector struct {
 	uploadReleaseURLReturns struct {
 		result1 error
 	}
-	UploadReleaseFileStub        func(file director.UploadFile, rebase, fix bool) error
+	UploadReleaseFileStub        func(file director.UploadFile, rebase, fix bool, uploaded func() (bool, error)) error
 	uploadReleaseFileMutex       sync.RWMutex
 	uploadReleaseFileArgsForCall []struct {
-		file   director.UploadFile
-		rebase bool
-		fix    bool
+		file     director.UploadFile
+		rebase   bool
+		fix      bool
+		uploaded func() (bool, error)
 	}
 	uploadReleaseFileReturns struct {
 		result1 error
@@ -185,11 +186,12 @@ type FakeDirector struct {
 	uploadStemcellURLReturns struct {
 		result1 error
 	}
-	UploadStemcellFileStub        func(file director.UploadFile, fix bool) error
+	UploadStemcellFileStub        func(file director.UploadFile, fix bool, uploaded func() (bool, error)) error
 	uploadStemcellFileMutex       sync.RWMutex
 	uploadStemcellFileArgsForCall []struct {
-		file director.UploadFile
-		fix  bool
+		file     director.UploadFile
+		fix      bool
+		uploaded func() (bool, error)
 	}
 	uploadStemcellFileReturns struct {
 		result1 error
@@ -724,17 +726,18 @@ func (fake *FakeDirector) UploadReleaseURLReturns(result1 error) {
 	}{result1}
 }
 
-func (fake *FakeDirector) UploadReleaseFile(file director.UploadFile, rebase bool, fix bool) error {
+func (fake *FakeDirector) UploadReleaseFile(file director.UploadFile, rebase bool, fix bool, uploaded func() (bool, error)) error {
 	fake.uploadReleaseFileMutex.Lock()
 	fake.uploadReleaseFileArgsForCall = append(fake.uploadReleaseFileArgsForCall, struct {
-		file   director.UploadFile
-		rebase bool
-		fix    bool
-	}{file, rebase, fix})
-	fake.recordInvocation("UploadReleaseFile", []interface{}{file, rebase, fix})
+		file     director.UploadFile
+		rebase   bool
+		fix      bool
+		uploaded func() (bool, error)
+	}{file, rebase, fix, uploaded})
+	fake.recordInvocation("UploadReleaseFile", []interface{}{file, rebase, fix, uploaded})
 	fake.uploadReleaseFileMutex.Unlock()
 	if fake.UploadReleaseFileStub != nil {
-		return fake.UploadReleaseFileStub(file, rebase, fix)
+		return fake.UploadReleaseFileStub(file, rebase, fix, uploaded)
 	} else {
 		return fake.uploadReleaseFileReturns.result1
 	}
@@ -746,10 +749,10 @@ func (fake *FakeDirector) UploadReleaseFileCallCount() int {
 	return len(fake.uploadReleaseFileArgsForCall)
 }
 
-func (fake *FakeDirector) UploadReleaseFileArgsForCall(i int) (director.UploadFile, bool, bool) {
+func (fake *FakeDirector) UploadReleaseFileArgsForCall(i int) (director.UploadFile, bool, bool, func() (bool, error)) {
 	fake.uploadReleaseFileMutex.RLock()
 	defer fake.uploadReleaseFileMutex.RUnlock()
-	return fake.uploadReleaseFileArgsForCall[i].file, fake.uploadReleaseFileArgsForCall[i].rebase, fake.uploadReleaseFileArgsForCall[i].fix
+	return fake.uploadReleaseFileArgsForCall[i].file, fake.uploadReleaseFileArgsForCall[i].rebase, fake.uploadReleaseFileArgsForCall[i].fix, fake.uploadReleaseFileArgsForCall[i].uploaded
 }
 
 func (fake *FakeDirector) UploadReleaseFileReturns(result1 error) {
@@ -924,16 +927,17 @@ func (fake *FakeDirector) UploadStemcellURLReturns(result1 error) {
 	}{result1}
 }
 
-func (fake *FakeDirector) UploadStemcellFile(file director.UploadFile, fix bool) error {
+func (fake *FakeDirector) UploadStemcellFile(file director.UploadFile, fix bool, uploaded func() (bool, error)) error {
 	fake.uploadStemcellFileMutex.Lock()
 	fake.uploadStemcellFileArgsForCall = append(fake.uploadStemcellFileArgsForCall, struct {
-		file director.UploadFile
-		fix  bool
-	}{file, fix})
-	fake.recordInvocation("UploadStemcellFile", []interface{}{file, fix})
+		file     director.UploadFile
+		fix      bool
+		uploaded func() (bool, error)
+	}{file, fix, uploaded})
+	fake.recordInvocation("UploadStemcellFile", []interface{}{file, fix, uploaded})
 	fake.uploadStemcellFileMutex.Unlock()
 	if fake.UploadStemcellFileStub != nil {
-		return fake.UploadStemcellFileStub(file, fix)
+		return fake.UploadStemcellFileStub(file, fix, uploaded)
 	} else {
 		return fake.uploadStemcellFileReturns.result1
 	}
@@ -945,10 +949,10 @@ func (fake *FakeDirector) UploadStemcellFileCallCount() int {
 	return len(fake.uploadStemcellFileArgsForCall)
 }
 
-func (fake *FakeDirector) UploadStemcellFileArgsForCall(i int) (director.UploadFile, bool) {
+func (fake *FakeDirector) UploadStemcellFileArgsForCall(i int) (director.UploadFile, bool, func() (bool, error)) {
 	fake.uploadStemcellFileMutex.RLock()
 	defer fake.uploadStemcellFileMutex.RUnlock()
-	return fake.uploadStemcellFileArgsForCall[i].file, fake.uploadStemcellFileArgsForCall[i].fix
+	return fake.uploadStemcellFileArgsForCall[i].file, fake.uploadStemcellFileArgsForCall[i].fix, fake.uploadStemcellFileArgsForCall[i].uploaded
 }
 
 func (fake *FakeDirector) UploadStemcellFileReturns(result1 error) {
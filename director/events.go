@@ -99,6 +99,9 @@ func (c Client) Events(opts EventsFilter) ([]EventResp, error) {
 	if len(opts.BeforeID) > 0 {
 		q.Set("before_id", opts.BeforeID)
 	}
+	if len(opts.AfterID) > 0 {
+		q.Set("after_id", opts.AfterID)
+	}
 	if len(opts.Before) > 0 {
 		q.Set("before_time", opts.Before)
 	}
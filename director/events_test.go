@@ -126,6 +126,19 @@ var _ = Describe("Director", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		It("filters events based on 'after-id' option", func() {
+			opts := EventsFilter{AfterID: "3"}
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/events", "after_id=3"),
+					ghttp.RespondWith(http.StatusOK, `[]`),
+				),
+			)
+
+			_, err := director.Events(opts)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
 		It("filters events based on 'before' option", func() {
 			opts := EventsFilter{Before: "1440318200"}
 			server.AppendHandlers(
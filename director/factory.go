@@ -9,6 +9,8 @@ import (
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshhttp "github.com/cloudfoundry/bosh-utils/httpclient"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+
+	boshproxy "github.com/cloudfoundry/bosh-cli/proxy"
 )
 
 type Factory struct {
@@ -50,7 +52,7 @@ func (f Factory) httpClient(config Config, taskReporter TaskReporter, fileReport
 		f.logger.Debug(f.logTag, "Using custom root CAs")
 	}
 
-	rawClient := boshhttp.CreateDefaultClient(certPool)
+	rawClient := boshproxy.CreateDefaultClient(certPool)
 
 	authAdjustment := NewAuthRequestAdjustment(
 		config.TokenFunc, config.Username, config.Password)
@@ -30,14 +30,14 @@ type Director interface {
 	FindRelease(ReleaseSlug) (Release, error)
 	FindReleaseSeries(ReleaseSeriesSlug) (ReleaseSeries, error)
 	UploadReleaseURL(url, sha1 string, rebase, fix bool) error
-	UploadReleaseFile(file UploadFile, rebase, fix bool) error
+	UploadReleaseFile(file UploadFile, rebase, fix bool, uploaded func() (bool, error)) error
 	MatchPackages(manifest interface{}, compiled bool) ([]string, error)
 
 	Stemcells() ([]Stemcell, error)
 	HasStemcell(name, version string) (bool, error)
 	FindStemcell(StemcellSlug) (Stemcell, error)
 	UploadStemcellURL(url, sha1 string, fix bool) error
-	UploadStemcellFile(file UploadFile, fix bool) error
+	UploadStemcellFile(file UploadFile, fix bool, uploaded func() (bool, error)) error
 
 	LatestCloudConfig() (CloudConfig, error)
 	UpdateCloudConfig([]byte) error
@@ -258,6 +258,7 @@ type OrphanedDisk interface {
 
 type EventsFilter struct {
 	BeforeID   string
+	AfterID    string
 	Before     string
 	After      string
 	Deployment string
@@ -187,8 +187,8 @@ func (d DirectorImpl) UploadReleaseURL(url, sha1 string, rebase, fix bool) error
 	return d.client.UploadReleaseURL(url, sha1, rebase, fix)
 }
 
-func (d DirectorImpl) UploadReleaseFile(file UploadFile, rebase, fix bool) error {
-	return d.client.UploadReleaseFile(file, rebase, fix)
+func (d DirectorImpl) UploadReleaseFile(file UploadFile, rebase, fix bool, uploaded func() (bool, error)) error {
+	return d.client.UploadReleaseFile(file, rebase, fix, uploaded)
 }
 
 func (c Client) Release(name, version string) (ReleaseResp, error) {
@@ -276,12 +276,7 @@ func (c Client) UploadReleaseURL(url, sha1 string, rebase, fix bool) error {
 	return nil
 }
 
-func (c Client) UploadReleaseFile(file UploadFile, rebase, fix bool) error {
-	fileInfo, err := file.Stat()
-	if err != nil {
-		return bosherr.WrapErrorf(err, "Determining release file size")
-	}
-
+func (c Client) UploadReleaseFile(file UploadFile, rebase, fix bool, uploaded func() (bool, error)) error {
 	query := gourl.Values{}
 
 	if rebase {
@@ -294,13 +289,7 @@ func (c Client) UploadReleaseFile(file UploadFile, rebase, fix bool) error {
 
 	path := "/releases?" + query.Encode()
 
-	setHeadersAndBody := func(req *http.Request) {
-		req.Header.Add("Content-Type", "application/x-compressed")
-		req.ContentLength = fileInfo.Size()
-		req.Body = file
-	}
-
-	_, err = c.taskClientRequest.PostResult(path, nil, setHeadersAndBody)
+	_, err := c.uploadFile(path, file, uploaded)
 	if err != nil {
 		return bosherr.WrapErrorf(err, "Uploading release file")
 	}
@@ -2,6 +2,7 @@ package director_test
 
 import (
 	"errors"
+	"io/ioutil"
 	"net/http"
 	"os"
 
@@ -290,7 +291,7 @@ var _ = Describe("Director", func() {
 				server,
 			)
 
-			Expect(director.UploadReleaseFile(file, false, false)).ToNot(HaveOccurred())
+			Expect(director.UploadReleaseFile(file, false, false, nil)).ToNot(HaveOccurred())
 		})
 
 		It("uploads release file with rebase and fix", func() {
@@ -308,13 +309,13 @@ var _ = Describe("Director", func() {
 				server,
 			)
 
-			Expect(director.UploadReleaseFile(file, true, true)).ToNot(HaveOccurred())
+			Expect(director.UploadReleaseFile(file, true, true, nil)).ToNot(HaveOccurred())
 		})
 
 		It("returns error if response is non-200", func() {
 			AppendBadRequest(ghttp.VerifyRequest("POST", "/releases"), server)
 
-			err := director.UploadReleaseFile(file, false, false)
+			err := director.UploadReleaseFile(file, false, false, nil)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring(
 				"Uploading release file: Director responded with non-successful status code"))
@@ -328,11 +329,86 @@ var _ = Describe("Director", func() {
 				),
 			)
 
-			err := director.UploadReleaseFile(file, false, false)
+			err := director.UploadReleaseFile(file, false, false, nil)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring(
 				"Uploading release file: Unmarshaling Director response"))
 		})
+
+		Context("when connection to the director is lost", func() {
+			BeforeEach(func() {
+				tmpFile, err := ioutil.TempFile("", "bosh-director-upload")
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = tmpFile.Write([]byte("content"))
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = tmpFile.Seek(0, 0)
+				Expect(err).ToNot(HaveOccurred())
+
+				file = tmpFile
+
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/releases"),
+						func(w http.ResponseWriter, req *http.Request) {
+							conn, _, err := w.(http.Hijacker).Hijack()
+							Expect(err).ToNot(HaveOccurred())
+							conn.Close()
+						},
+					),
+				)
+			})
+
+			AfterEach(func() {
+				os.Remove(file.(*os.File).Name())
+			})
+
+			It("uploads whole file again", func() {
+				ConfigureTaskResult(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/releases"),
+						ghttp.VerifyHeader(http.Header{
+							"Content-Type":   []string{"application/x-compressed"},
+							"Content-Length": []string{"7"},
+						}),
+						ghttp.VerifyBody([]byte("content")),
+					),
+					"",
+					server,
+				)
+
+				Expect(director.UploadReleaseFile(file, false, false, nil)).ToNot(HaveOccurred())
+			})
+
+			It("uploads whole file again if release was not uploaded", func() {
+				ConfigureTaskResult(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/releases"),
+						ghttp.VerifyBody([]byte("content")),
+					),
+					"",
+					server,
+				)
+
+				var checked int
+
+				uploaded := func() (bool, error) {
+					checked++
+					return false, nil
+				}
+
+				Expect(director.UploadReleaseFile(file, false, false, uploaded)).ToNot(HaveOccurred())
+				Expect(checked).To(Equal(1))
+			})
+
+			It("does not upload file again if release was uploaded before connection was lost", func() {
+				uploaded := func() (bool, error) { return true, nil }
+
+				Expect(director.UploadReleaseFile(file, false, false, uploaded)).ToNot(HaveOccurred())
+				Expect(server.ReceivedRequests()).To(HaveLen(1))
+			})
+		})
 	})
 })
 
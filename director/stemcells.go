@@ -120,8 +120,8 @@ func (d DirectorImpl) UploadStemcellURL(url, sha1 string, fix bool) error {
 	return d.client.UploadStemcellURL(url, sha1, fix)
 }
 
-func (d DirectorImpl) UploadStemcellFile(file UploadFile, fix bool) error {
-	return d.client.UploadStemcellFile(file, fix)
+func (d DirectorImpl) UploadStemcellFile(file UploadFile, fix bool, uploaded func() (bool, error)) error {
+	return d.client.UploadStemcellFile(file, fix, uploaded)
 }
 
 func (c Client) Stemcells() ([]StemcellResp, error) {
@@ -186,12 +186,7 @@ func (c Client) UploadStemcellURL(url, sha1 string, fix bool) error {
 	return nil
 }
 
-func (c Client) UploadStemcellFile(file UploadFile, fix bool) error {
-	fileInfo, err := file.Stat()
-	if err != nil {
-		return bosherr.WrapErrorf(err, "Determining stemcell file size")
-	}
-
+func (c Client) UploadStemcellFile(file UploadFile, fix bool, uploaded func() (bool, error)) error {
 	query := gourl.Values{}
 
 	if fix {
@@ -200,13 +195,7 @@ func (c Client) UploadStemcellFile(file UploadFile, fix bool) error {
 
 	path := "/stemcells?" + query.Encode()
 
-	setHeadersAndBody := func(req *http.Request) {
-		req.Header.Add("Content-Type", "application/x-compressed")
-		req.ContentLength = fileInfo.Size()
-		req.Body = file
-	}
-
-	_, err = c.taskClientRequest.PostResult(path, nil, setHeadersAndBody)
+	_, err := c.uploadFile(path, file, uploaded)
 	if err != nil {
 		return bosherr.WrapErrorf(err, "Uploading stemcell file")
 	}
@@ -2,6 +2,7 @@ package director_test
 
 import (
 	"errors"
+	"io/ioutil"
 	"net/http"
 	"os"
 
@@ -273,7 +274,7 @@ var _ = Describe("Director", func() {
 				server,
 			)
 
-			Expect(director.UploadStemcellFile(file, false)).ToNot(HaveOccurred())
+			Expect(director.UploadStemcellFile(file, false, nil)).ToNot(HaveOccurred())
 		})
 
 		It("uploads stemcell file with fix", func() {
@@ -291,13 +292,13 @@ var _ = Describe("Director", func() {
 				server,
 			)
 
-			Expect(director.UploadStemcellFile(file, true)).ToNot(HaveOccurred())
+			Expect(director.UploadStemcellFile(file, true, nil)).ToNot(HaveOccurred())
 		})
 
 		It("returns error if response is non-200", func() {
 			AppendBadRequest(ghttp.VerifyRequest("POST", "/stemcells"), server)
 
-			err := director.UploadStemcellFile(file, true)
+			err := director.UploadStemcellFile(file, true, nil)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring(
 				"Uploading stemcell file: Director responded with non-successful status code"))
@@ -311,11 +312,51 @@ var _ = Describe("Director", func() {
 				),
 			)
 
-			err := director.UploadStemcellFile(file, true)
+			err := director.UploadStemcellFile(file, true, nil)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring(
 				"Uploading stemcell file: Unmarshaling Director response"))
 		})
+
+		It("uploads whole file again when connection is lost unless stemcell was uploaded", func() {
+			tmpFile, err := ioutil.TempFile("", "bosh-director-upload")
+			Expect(err).ToNot(HaveOccurred())
+
+			defer os.Remove(tmpFile.Name())
+
+			_, err = tmpFile.Write([]byte("content"))
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = tmpFile.Seek(0, 0)
+			Expect(err).ToNot(HaveOccurred())
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/stemcells"),
+					func(w http.ResponseWriter, req *http.Request) {
+						conn, _, err := w.(http.Hijacker).Hijack()
+						Expect(err).ToNot(HaveOccurred())
+						conn.Close()
+					},
+				),
+			)
+
+			ConfigureTaskResult(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/stemcells", ""),
+					ghttp.VerifyHeader(http.Header{
+						"Content-Length": []string{"7"},
+					}),
+					ghttp.VerifyBody([]byte("content")),
+				),
+				"",
+				server,
+			)
+
+			uploaded := func() (bool, error) { return false, errors.New("fake-err") }
+
+			Expect(director.UploadStemcellFile(tmpFile, false, uploaded)).ToNot(HaveOccurred())
+		})
 	})
 })
 
@@ -0,0 +1,296 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/cppforlife/go-patch/patch"
+)
+
+// SourcedOp associates operation with its origin
+// so that changes made by it could be explained
+type SourcedOp struct {
+	patch.Op
+
+	Source string // e.g. ops file path
+	Index  int    // position of operation within its source
+}
+
+type SourcedOps []SourcedOp
+
+func (ops SourcedOps) Apply(doc interface{}) (interface{}, error) {
+	var err error
+
+	for _, op := range ops {
+		doc, err = op.Apply(doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+// Explanation records which operations and variables
+// produced each path of the evaluated template
+type Explanation struct {
+	Paths []ExplainedPath // sorted by path
+}
+
+type ExplainedPath struct {
+	Path string // e.g. "/instance_groups/name=web/vm_type"
+
+	// Path was defined by the template before any operations were applied
+	InTemplate bool
+
+	Changes   []ExplainedChange // in order of application
+	Variables []string          // variables referenced by the value after all operations
+}
+
+type ExplainedChange struct {
+	Source string
+	Index  int
+	Action string // added, changed or removed
+}
+
+const (
+	ExplainedChangeAdded   = "added"
+	ExplainedChangeChanged = "changed"
+	ExplainedChangeRemoved = "removed"
+)
+
+// Find returns explained paths that are equal to, nested under
+// or contain given path; root path (or "/") matches all paths
+func (e Explanation) Find(path patch.Pointer) []ExplainedPath {
+	query := strings.TrimSuffix(path.String(), "/")
+
+	if len(query) == 0 {
+		return e.Paths
+	}
+
+	var paths []ExplainedPath
+
+	for _, p := range e.Paths {
+		if p.Path == query || strings.HasPrefix(p.Path, query+"/") || strings.HasPrefix(query, p.Path+"/") {
+			paths = append(paths, p)
+		}
+	}
+
+	return paths
+}
+
+type explainer struct {
+	template map[string]interface{}
+	paths    map[string]*ExplainedPath
+}
+
+func newExplainer() *explainer {
+	return &explainer{paths: map[string]*ExplainedPath{}}
+}
+
+// Apply applies operations one by one comparing document
+// before and after each operation to find out which paths it changed
+func (e *explainer) Apply(obj interface{}, op patch.Op) (interface{}, error) {
+	before := explainablePaths(obj)
+
+	if e.template == nil {
+		e.template = before
+	}
+
+	for _, sourcedOp := range e.sourcedOps(op) {
+		var err error
+
+		obj, err = sourcedOp.Apply(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		after := explainablePaths(obj)
+
+		for _, path := range e.sortedKeys(after) {
+			prevVal, found := before[path]
+
+			switch {
+			case !found:
+				e.recordChange(path, sourcedOp, ExplainedChangeAdded)
+			case !reflect.DeepEqual(prevVal, after[path]):
+				e.recordChange(path, sourcedOp, ExplainedChangeChanged)
+			}
+		}
+
+		for _, path := range e.sortedKeys(before) {
+			if _, found := after[path]; !found {
+				e.recordChange(path, sourcedOp, ExplainedChangeRemoved)
+			}
+		}
+
+		before = after
+	}
+
+	return obj, nil
+}
+
+// RecordVariables has to be called before variables are interpolated
+func (e *explainer) RecordVariables(obj interface{}) {
+	if e.template == nil {
+		e.template = explainablePaths(obj)
+	}
+
+	paths := explainablePaths(obj)
+
+	for _, path := range e.sortedKeys(paths) {
+		str, ok := paths[path].(string)
+		if !ok {
+			continue
+		}
+
+		for _, name := range (interpolator{}).extractVarNames(str) {
+			explainedPath := e.path(path)
+
+			if !e.includesString(explainedPath.Variables, name) {
+				explainedPath.Variables = append(explainedPath.Variables, name)
+			}
+		}
+	}
+}
+
+func (e *explainer) Explanation() Explanation {
+	var explanation Explanation
+
+	for _, explainedPath := range e.paths {
+		explanation.Paths = append(explanation.Paths, *explainedPath)
+	}
+
+	sort.Slice(explanation.Paths, func(i, j int) bool {
+		return explanation.Paths[i].Path < explanation.Paths[j].Path
+	})
+
+	return explanation
+}
+
+func (e *explainer) sourcedOps(op patch.Op) SourcedOps {
+	switch typedOp := op.(type) {
+	case SourcedOps:
+		return typedOp
+
+	case patch.Ops:
+		var ops SourcedOps
+
+		for i, op := range typedOp {
+			ops = append(ops, SourcedOp{Op: op, Index: i})
+		}
+
+		return ops
+
+	default:
+		return SourcedOps{{Op: op}}
+	}
+}
+
+func (e *explainer) recordChange(path string, op SourcedOp, action string) {
+	explainedPath := e.path(path)
+
+	explainedPath.Changes = append(explainedPath.Changes, ExplainedChange{
+		Source: op.Source,
+		Index:  op.Index,
+		Action: action,
+	})
+}
+
+func (e *explainer) path(path string) *ExplainedPath {
+	explainedPath, found := e.paths[path]
+	if !found {
+		_, inTemplate := e.template[path]
+		explainedPath = &ExplainedPath{Path: path, InTemplate: inTemplate}
+		e.paths[path] = explainedPath
+	}
+
+	return explainedPath
+}
+
+func (e *explainer) sortedKeys(paths map[string]interface{}) []string {
+	var keys []string
+
+	for key := range paths {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (e *explainer) includesString(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+
+	return false
+}
+
+// explainablePaths flattens document into leaf values keyed by their paths;
+// array items that are maps with unique names are referred to by name
+// (e.g. "/instance_groups/name=web") to keep paths stable when items are added
+func explainablePaths(obj interface{}) map[string]interface{} {
+	paths := map[string]interface{}{}
+	collectExplainablePaths(obj, []patch.Token{patch.RootToken{}}, paths)
+	return paths
+}
+
+func collectExplainablePaths(obj interface{}, tokens []patch.Token, paths map[string]interface{}) {
+	switch typedObj := obj.(type) {
+	case map[interface{}]interface{}:
+		if len(typedObj) == 0 {
+			// Copy since empty maps may be modified in place by subsequent operations
+			paths[patch.NewPointer(tokens).String()] = map[interface{}]interface{}{}
+			return
+		}
+
+		for key, val := range typedObj {
+			keyToken := patch.KeyToken{Key: fmt.Sprintf("%v", key)}
+			collectExplainablePaths(val, append(tokens[:len(tokens):len(tokens)], keyToken), paths)
+		}
+
+	case []interface{}:
+		if len(typedObj) == 0 {
+			paths[patch.NewPointer(tokens).String()] = []interface{}{}
+			return
+		}
+
+		names := map[string]int{}
+
+		for _, item := range typedObj {
+			if name, ok := explainableItemName(item); ok {
+				names[name]++
+			}
+		}
+
+		for i, item := range typedObj {
+			var itemToken patch.Token = patch.IndexToken{Index: i}
+
+			if name, ok := explainableItemName(item); ok && names[name] == 1 {
+				itemToken = patch.MatchingIndexToken{Key: "name", Value: name}
+			}
+
+			collectExplainablePaths(item, append(tokens[:len(tokens):len(tokens)], itemToken), paths)
+		}
+
+	default:
+		paths[patch.NewPointer(tokens).String()] = obj
+	}
+}
+
+func explainableItemName(item interface{}) (string, bool) {
+	typedItem, ok := item.(map[interface{}]interface{})
+	if !ok {
+		return "", false
+	}
+
+	name, ok := typedItem["name"].(string)
+
+	return name, ok && len(name) > 0
+}
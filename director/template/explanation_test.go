@@ -0,0 +1,207 @@
+package template_test
+
+import (
+	"errors"
+
+	"github.com/cppforlife/go-patch/patch"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/director/template"
+)
+
+var _ = Describe("Explanation", func() {
+	var (
+		template Template
+		vars     StaticVariables
+	)
+
+	BeforeEach(func() {
+		template = NewTemplate([]byte(`
+instance_groups:
+- name: web
+  vm_type: small
+  azs: []
+- name: db
+  vm_type: ((db_vm_type))
+`))
+
+		vars = StaticVariables{"db_vm_type": "large", "web_vm_type": "xlarge"}
+	})
+
+	explain := func(op patch.Op) Explanation {
+		var explanation Explanation
+
+		_, err := template.Evaluate(vars, op, EvaluateOpts{Explanation: &explanation})
+		Expect(err).ToNot(HaveOccurred())
+
+		return explanation
+	}
+
+	It("records which ops changed each path", func() {
+		explanation := explain(SourcedOps{
+			{
+				Op:     patch.ReplaceOp{Path: patch.MustNewPointerFromString("/instance_groups/name=web/vm_type"), Value: "medium"},
+				Source: "ops1.yml",
+				Index:  0,
+			},
+			{
+				Op:     patch.ReplaceOp{Path: patch.MustNewPointerFromString("/instance_groups/name=web/azs/-"), Value: "z1"},
+				Source: "ops1.yml",
+				Index:  1,
+			},
+			{
+				Op:     patch.ReplaceOp{Path: patch.MustNewPointerFromString("/instance_groups/name=web/vm_type"), Value: "((web_vm_type))"},
+				Source: "ops2.yml",
+				Index:  0,
+			},
+		})
+
+		Expect(explanation).To(Equal(Explanation{
+			Paths: []ExplainedPath{
+				{
+					Path:       "/instance_groups/name=db/vm_type",
+					InTemplate: true,
+					Variables:  []string{"db_vm_type"},
+				},
+				{
+					Path:       "/instance_groups/name=web/azs",
+					InTemplate: true,
+					Changes:    []ExplainedChange{{Source: "ops1.yml", Index: 1, Action: "removed"}},
+				},
+				{
+					Path:    "/instance_groups/name=web/azs/0",
+					Changes: []ExplainedChange{{Source: "ops1.yml", Index: 1, Action: "added"}},
+				},
+				{
+					Path:       "/instance_groups/name=web/vm_type",
+					InTemplate: true,
+					Changes: []ExplainedChange{
+						{Source: "ops1.yml", Index: 0, Action: "changed"},
+						{Source: "ops2.yml", Index: 0, Action: "changed"},
+					},
+					Variables: []string{"web_vm_type"},
+				},
+			},
+		}))
+	})
+
+	It("records removed paths", func() {
+		explanation := explain(SourcedOps{
+			{
+				Op:     patch.RemoveOp{Path: patch.MustNewPointerFromString("/instance_groups/name=db")},
+				Source: "ops1.yml",
+			},
+		})
+
+		Expect(explanation.Paths).To(Equal([]ExplainedPath{
+			{
+				Path:       "/instance_groups/name=db/name",
+				InTemplate: true,
+				Changes:    []ExplainedChange{{Source: "ops1.yml", Action: "removed"}},
+			},
+			{
+				Path:       "/instance_groups/name=db/vm_type",
+				InTemplate: true,
+				Changes:    []ExplainedChange{{Source: "ops1.yml", Action: "removed"}},
+			},
+		}))
+	})
+
+	It("uses op position as index when ops do not have sources", func() {
+		explanation := explain(patch.Ops{
+			patch.ReplaceOp{Path: patch.MustNewPointerFromString("/name?"), Value: "dep"},
+			patch.ReplaceOp{Path: patch.MustNewPointerFromString("/name"), Value: "((name))"},
+		})
+
+		Expect(explanation.Find(patch.MustNewPointerFromString("/name"))).To(Equal([]ExplainedPath{
+			{
+				Path: "/name",
+				Changes: []ExplainedChange{
+					{Index: 0, Action: "added"},
+					{Index: 1, Action: "changed"},
+				},
+				Variables: []string{"name"},
+			},
+		}))
+	})
+
+	It("records variables when there are no ops", func() {
+		Expect(explain(nil).Paths).To(Equal([]ExplainedPath{
+			{
+				Path:       "/instance_groups/name=db/vm_type",
+				InTemplate: true,
+				Variables:  []string{"db_vm_type"},
+			},
+		}))
+	})
+
+	It("refers to array items by index when names are not unique", func() {
+		template = NewTemplate([]byte("items:\n- name: a\n- name: a\n- ((item))\n"))
+
+		var paths []string
+
+		for _, path := range explain(nil).Paths {
+			paths = append(paths, path.Path)
+		}
+
+		Expect(paths).To(Equal([]string{"/items/2"}))
+	})
+
+	It("returns error if op cannot be applied", func() {
+		var explanation Explanation
+
+		_, err := template.Evaluate(vars, SourcedOps{
+			{Op: patch.ErrOp{Err: errors.New("fake-err")}, Source: "ops1.yml"},
+		}, EvaluateOpts{Explanation: &explanation})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-err"))
+	})
+
+	It("produces the same template as without explanation", func() {
+		op := SourcedOps{
+			{Op: patch.ReplaceOp{Path: patch.MustNewPointerFromString("/instance_groups/name=web/vm_type"), Value: "((web_vm_type))"}},
+		}
+
+		var explanation Explanation
+
+		explainedBytes, err := template.Evaluate(vars, op, EvaluateOpts{Explanation: &explanation})
+		Expect(err).ToNot(HaveOccurred())
+
+		bytes, err := template.Evaluate(vars, op, EvaluateOpts{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(explainedBytes).To(Equal(bytes))
+	})
+
+	Describe("Find", func() {
+		var explanation Explanation
+
+		BeforeEach(func() {
+			explanation = Explanation{
+				Paths: []ExplainedPath{
+					{Path: "/a"},
+					{Path: "/b/c"},
+					{Path: "/b/cd"},
+					{Path: "/b/c/d/e"},
+				},
+			}
+		})
+
+		It("returns all paths for root path", func() {
+			Expect(explanation.Find(patch.MustNewPointerFromString(""))).To(Equal(explanation.Paths))
+			Expect(explanation.Find(patch.MustNewPointerFromString("/"))).To(Equal(explanation.Paths))
+		})
+
+		It("returns equal, nested and containing paths", func() {
+			Expect(explanation.Find(patch.MustNewPointerFromString("/b/c/d"))).To(Equal([]ExplainedPath{
+				{Path: "/b/c"},
+				{Path: "/b/c/d/e"},
+			}))
+		})
+
+		It("returns no paths if nothing matches", func() {
+			Expect(explanation.Find(patch.MustNewPointerFromString("/x"))).To(BeEmpty())
+		})
+	})
+})
@@ -20,6 +20,10 @@ type EvaluateOpts struct {
 	ExpectAllVarsUsed     bool
 	PostVarSubstitutionOp patch.Op
 	UnescapedMultiline    bool
+
+	// If set, records which operations and variables produced
+	// each path; operations could be given as SourcedOps to include their origin
+	Explanation *Explanation
 }
 
 func NewTemplate(bytes []byte) Template {
@@ -34,7 +38,20 @@ func (t Template) Evaluate(vars Variables, op patch.Op, opts EvaluateOpts) ([]by
 		return []byte{}, err
 	}
 
-	if op != nil {
+	if opts.Explanation != nil {
+		explainer := newExplainer()
+
+		if op != nil {
+			obj, err = explainer.Apply(obj, op)
+			if err != nil {
+				return []byte{}, err
+			}
+		}
+
+		explainer.RecordVariables(obj)
+
+		*opts.Explanation = explainer.Explanation()
+	} else if op != nil {
 		obj, err = op.Apply(obj)
 		if err != nil {
 			return []byte{}, err
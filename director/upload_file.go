@@ -0,0 +1,83 @@
+package director
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// uploadFile posts file contents and waits for the resulting task.
+// When connection to the Director is lost before it responds, upload
+// is retried from the beginning of the file. Uploads are not resumed
+// from the reached offset since the Director does not provide a way
+// to accept partially received files. Since the Director may have
+// received the whole file before the connection was lost, uploaded func
+// (if given) is consulted before each retry to avoid uploading it again.
+func (c Client) uploadFile(path string, file UploadFile, uploaded func() (bool, error)) ([]byte, error) {
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Determining file size")
+	}
+
+	seeker, seekable := file.(io.Seeker)
+
+	for attempt := 1; ; attempt++ {
+		respBody, resp, err := c.postFile(path, file, fileInfo.Size())
+		if err == nil {
+			return c.waitForUploadTask(respBody)
+		}
+
+		// Director received the request and responded hence retrying will not help
+		if resp != nil || !seekable || attempt >= c.uploadAttempts {
+			return nil, err
+		}
+
+		c.clientRequest.logger.Debug("director.Client",
+			"Upload attempt %d of %d failed: %s", attempt, c.uploadAttempts, err)
+
+		time.Sleep(c.uploadRetryDelay)
+
+		if uploaded != nil {
+			found, checkErr := uploaded()
+			if checkErr != nil {
+				c.clientRequest.logger.Debug("director.Client", "Failed to check if upload completed: %s", checkErr)
+			} else if found {
+				return nil, nil
+			}
+		}
+
+		_, err = seeker.Seek(0, io.SeekStart)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Seeking to the beginning of file")
+		}
+	}
+}
+
+func (c Client) postFile(path string, file UploadFile, size int64) ([]byte, *http.Response, error) {
+	setHeadersAndBody := func(req *http.Request) {
+		req.Header.Add("Content-Type", "application/x-compressed")
+		req.ContentLength = size
+
+		// HTTP client closes request body but file is needed for subsequent attempts
+		req.Body = ioutil.NopCloser(file)
+	}
+
+	return c.clientRequest.RawPost(path, nil, setHeadersAndBody)
+}
+
+func (c Client) waitForUploadTask(respBody []byte) ([]byte, error) {
+	var taskResp taskShortResp
+
+	err := json.Unmarshal(respBody, &taskResp)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Unmarshaling Director response")
+	}
+
+	return c.taskClientRequest.waitForResult(taskResp)
+}
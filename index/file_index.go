@@ -3,6 +3,7 @@ package index
 import (
 	"encoding/json"
 	"reflect"
+	"sync"
 
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
@@ -11,6 +12,9 @@ import (
 type FileIndex struct {
 	path string
 	fs   boshsys.FileSystem
+
+	// Shared between copies so that concurrent saves do not lose entries
+	lock *sync.RWMutex
 }
 
 type indexEntry struct {
@@ -19,10 +23,13 @@ type indexEntry struct {
 }
 
 func NewFileIndex(path string, fs boshsys.FileSystem) FileIndex {
-	return FileIndex{path: path, fs: fs}
+	return FileIndex{path: path, fs: fs, lock: &sync.RWMutex{}}
 }
 
 func (ri FileIndex) Find(key interface{}, value interface{}) error {
+	ri.lock.RLock()
+	defer ri.lock.RUnlock()
+
 	rawEntries, err := ri.readRawEntries()
 	if err != nil {
 		return err
@@ -48,6 +55,9 @@ func (ri FileIndex) Find(key interface{}, value interface{}) error {
 }
 
 func (ri FileIndex) Save(key interface{}, value interface{}) error {
+	ri.lock.Lock()
+	defer ri.lock.Unlock()
+
 	rawEntries, err := ri.readRawEntries()
 	if err != nil {
 		return err
@@ -89,6 +99,29 @@ func (ri FileIndex) Save(key interface{}, value interface{}) error {
 	return nil
 }
 
+func (ri FileIndex) Entries() ([]Entry, error) {
+	ri.lock.RLock()
+	defer ri.lock.RUnlock()
+
+	rawEntries, err := ri.readRawEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []Entry{}
+
+	for _, rawEntry := range rawEntries {
+		keyBytes, err := json.Marshal(rawEntry.Key)
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Marshalling index entry key")
+		}
+
+		entries = append(entries, Entry{Key: keyBytes, Value: rawEntry.Value})
+	}
+
+	return entries, nil
+}
+
 func (ri FileIndex) readRawEntries() ([]indexEntry, error) {
 	var entries []indexEntry
 
@@ -1,6 +1,8 @@
 package index_test
 
 import (
+	"encoding/json"
+
 	. "github.com/cloudfoundry/bosh-cli/index"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
@@ -171,4 +173,37 @@ var _ = Describe("FileIndex", func() {
 			})
 		})
 	})
+	Describe("Entries", func() {
+		It("returns all saved keys and values in order of saving", func() {
+			err := index.Save(Key{Key: "key-2"}, Value{Name: "value-2", Count: 2})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = index.Save(Key{Key: "key-1"}, Value{Name: "value-1", Count: 1})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = index.Save(Key{Key: "key-2"}, Value{Name: "value-3", Count: 3})
+			Expect(err).ToNot(HaveOccurred())
+
+			entries, err := index.Entries()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(2))
+
+			var key Key
+			var value Value
+
+			Expect(json.Unmarshal(entries[0].Key, &key)).To(Succeed())
+			Expect(json.Unmarshal(entries[0].Value, &value)).To(Succeed())
+			Expect(key).To(Equal(Key{Key: "key-2"}))
+			Expect(value).To(Equal(Value{Name: "value-3", Count: 3}))
+
+			Expect(json.Unmarshal(entries[1].Key, &key)).To(Succeed())
+			Expect(key).To(Equal(Key{Key: "key-1"}))
+		})
+
+		It("returns no entries when nothing was saved", func() {
+			entries, err := index.Entries()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+	})
 })
@@ -2,12 +2,15 @@ package index
 
 import (
 	"encoding/json"
+	"sync"
 
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 )
 
 type inMemoryIndex struct {
 	entryMap map[string][]byte
+	keys     []string
+	lock     sync.RWMutex
 }
 
 func NewInMemoryIndex() Index {
@@ -22,7 +25,10 @@ func (ri *inMemoryIndex) Find(key interface{}, valuePtr interface{}) error {
 		return bosherr.WrapErrorf(err, "Marshalling key %#v", key)
 	}
 
+	ri.lock.RLock()
 	valueBytes, exists := ri.entryMap[string(keyBytes)]
+	ri.lock.RUnlock()
+
 	if !exists {
 		return ErrNotFound
 	}
@@ -46,7 +52,27 @@ func (ri *inMemoryIndex) Save(key interface{}, value interface{}) error {
 		return bosherr.WrapErrorf(err, "Marshalling value %#v", value)
 	}
 
+	ri.lock.Lock()
+	defer ri.lock.Unlock()
+
+	if _, found := ri.entryMap[string(keyBytes)]; !found {
+		ri.keys = append(ri.keys, string(keyBytes))
+	}
+
 	ri.entryMap[string(keyBytes)] = valueBytes
 
 	return nil
 }
+
+func (ri *inMemoryIndex) Entries() ([]Entry, error) {
+	ri.lock.RLock()
+	defer ri.lock.RUnlock()
+
+	entries := []Entry{}
+
+	for _, key := range ri.keys {
+		entries = append(entries, Entry{Key: []byte(key), Value: ri.entryMap[key]})
+	}
+
+	return entries, nil
+}
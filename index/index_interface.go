@@ -1,6 +1,7 @@
 package index
 
 import (
+	"encoding/json"
 	"errors"
 )
 
@@ -11,4 +12,12 @@ var (
 type Index interface {
 	Find(interface{}, interface{}) error
 	Save(interface{}, interface{}) error
+
+	// Entries returns all saved keys and values as JSON in order of saving
+	Entries() ([]Entry, error)
+}
+
+type Entry struct {
+	Key   json.RawMessage
+	Value json.RawMessage
 }
@@ -0,0 +1,332 @@
+package installation
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	boshblob "github.com/cloudfoundry/bosh-utils/blobstore"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshcmd "github.com/cloudfoundry/bosh-utils/fileutil"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
+
+	bicrypto "github.com/cloudfoundry/bosh-cli/crypto"
+	biindex "github.com/cloudfoundry/bosh-cli/index"
+	bistatepkg "github.com/cloudfoundry/bosh-cli/state/pkg"
+)
+
+const compiledPackageCacheManifestName = "compiled_packages.json"
+
+// CompiledPackageCache moves compiled packages between installations
+// so that packages compiled once (e.g. by CI) do not need to be compiled again.
+type CompiledPackageCache interface {
+	Export(target Target, tarballPath string) (int, error)
+	// Import returns number of imported packages and number of packages that were already present
+	Import(target Target, tarballPath string) (int, int, error)
+}
+
+type compiledPackageCacheManifest struct {
+	OS       string                              `json:"os"`
+	Packages []compiledPackageCacheManifestEntry `json:"packages"`
+}
+
+type compiledPackageCacheManifestEntry struct {
+	Name          string `json:"name"`
+	Fingerprint   string `json:"fingerprint"`
+	DependencyKey string `json:"dependency_key"`
+	SHA1          string `json:"sha1"`
+	Path          string `json:"path"`
+}
+
+type compiledPackageCache struct {
+	os string
+
+	fs            boshsys.FileSystem
+	compressor    boshcmd.Compressor
+	sha1Calc      bicrypto.SHA1Calculator
+	uuidGenerator boshuuid.Generator
+
+	logger boshlog.Logger
+	logTag string
+}
+
+// NewCompiledPackageCache only imports packages compiled on the same OS
+// since compiled packages are generally not portable between OSes.
+func NewCompiledPackageCache(
+	os string,
+	fs boshsys.FileSystem,
+	compressor boshcmd.Compressor,
+	sha1Calc bicrypto.SHA1Calculator,
+	uuidGenerator boshuuid.Generator,
+	logger boshlog.Logger,
+) CompiledPackageCache {
+	return compiledPackageCache{
+		os: os,
+
+		fs:            fs,
+		compressor:    compressor,
+		sha1Calc:      sha1Calc,
+		uuidGenerator: uuidGenerator,
+
+		logger: logger,
+		logTag: "compiledPackageCache",
+	}
+}
+
+func (c compiledPackageCache) Export(target Target, tarballPath string) (int, error) {
+	repo, _ := c.repoAndBlobstore(target)
+
+	entries, err := repo.All()
+	if err != nil {
+		return 0, err
+	}
+
+	exportDir, err := c.fs.TempDir("bosh-compiled-package-cache")
+	if err != nil {
+		return 0, bosherr.WrapError(err, "Creating temporary directory")
+	}
+
+	defer c.fs.RemoveAll(exportDir)
+
+	manifest := compiledPackageCacheManifest{OS: c.os}
+
+	for _, entry := range entries {
+		manifestEntry, err := c.exportEntry(entry, target, exportDir)
+		if err != nil {
+			return 0, bosherr.WrapErrorf(err, "Exporting compiled package '%s/%s'", entry.Key.PackageName, entry.Key.PackageFingerprint)
+		}
+
+		manifest.Packages = append(manifest.Packages, manifestEntry)
+	}
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return 0, bosherr.WrapError(err, "Marshalling compiled packages manifest")
+	}
+
+	err = c.fs.WriteFile(filepath.Join(exportDir, compiledPackageCacheManifestName), manifestBytes)
+	if err != nil {
+		return 0, bosherr.WrapError(err, "Writing compiled packages manifest")
+	}
+
+	compressedPath, err := c.compressor.CompressFilesInDir(exportDir)
+	if err != nil {
+		return 0, bosherr.WrapError(err, "Compressing compiled packages")
+	}
+
+	defer c.compressor.CleanUp(compressedPath)
+
+	err = c.fs.CopyFile(compressedPath, tarballPath)
+	if err != nil {
+		return 0, bosherr.WrapErrorf(err, "Copying compiled packages to '%s'", tarballPath)
+	}
+
+	return len(manifest.Packages), nil
+}
+
+func (c compiledPackageCache) exportEntry(entry bistatepkg.CompiledPackageEntry, target Target, exportDir string) (compiledPackageCacheManifestEntry, error) {
+	var manifestEntry compiledPackageCacheManifestEntry
+
+	// Installation blobstore is always local
+	blobPath := filepath.Join(target.BlobstorePath(), entry.Record.BlobID)
+
+	err := c.verifySHA1(blobPath, entry.Record.BlobSHA1)
+	if err != nil {
+		return manifestEntry, err
+	}
+
+	relativePath := filepath.Join("blobs", entry.Record.BlobSHA1)
+
+	err = c.fs.MkdirAll(filepath.Join(exportDir, "blobs"), 0755)
+	if err != nil {
+		return manifestEntry, bosherr.WrapError(err, "Creating blobs directory")
+	}
+
+	err = c.fs.CopyFile(blobPath, filepath.Join(exportDir, relativePath))
+	if err != nil {
+		return manifestEntry, bosherr.WrapError(err, "Copying blob")
+	}
+
+	manifestEntry = compiledPackageCacheManifestEntry{
+		Name:          entry.Key.PackageName,
+		Fingerprint:   entry.Key.PackageFingerprint,
+		DependencyKey: entry.Key.DependencyKey,
+		SHA1:          entry.Record.BlobSHA1,
+		Path:          relativePath,
+	}
+
+	return manifestEntry, nil
+}
+
+func (c compiledPackageCache) Import(target Target, tarballPath string) (int, int, error) {
+	repo, blobstore := c.repoAndBlobstore(target)
+
+	importDir, err := c.fs.TempDir("bosh-compiled-package-cache")
+	if err != nil {
+		return 0, 0, bosherr.WrapError(err, "Creating temporary directory")
+	}
+
+	defer c.fs.RemoveAll(importDir)
+
+	err = c.compressor.DecompressFileToDir(tarballPath, importDir, boshcmd.CompressorOptions{})
+	if err != nil {
+		return 0, 0, bosherr.WrapErrorf(err, "Extracting compiled packages '%s'", tarballPath)
+	}
+
+	manifestBytes, err := c.fs.ReadFile(filepath.Join(importDir, compiledPackageCacheManifestName))
+	if err != nil {
+		return 0, 0, bosherr.WrapError(err, "Reading compiled packages manifest")
+	}
+
+	var manifest compiledPackageCacheManifest
+
+	err = json.Unmarshal(manifestBytes, &manifest)
+	if err != nil {
+		return 0, 0, bosherr.WrapError(err, "Unmarshalling compiled packages manifest")
+	}
+
+	if manifest.OS != c.os {
+		return 0, 0, bosherr.Errorf("Expected compiled packages to be compiled on '%s' but they were compiled on '%s'", c.os, manifest.OS)
+	}
+
+	existingEntries, err := repo.All()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var imported, skipped int
+
+	for _, manifestEntry := range manifest.Packages {
+		key := bistatepkg.CompiledPackageKey{
+			PackageName:        manifestEntry.Name,
+			PackageFingerprint: manifestEntry.Fingerprint,
+			DependencyKey:      manifestEntry.DependencyKey,
+		}
+
+		if c.hasEntry(existingEntries, key) {
+			c.logger.Debug(c.logTag, "Skipping already present compiled package '%s/%s'", key.PackageName, key.PackageFingerprint)
+			skipped++
+			continue
+		}
+
+		err := c.importEntry(key, manifestEntry, importDir, repo, blobstore)
+		if err != nil {
+			return imported, skipped, bosherr.WrapErrorf(err, "Importing compiled package '%s/%s'", key.PackageName, key.PackageFingerprint)
+		}
+
+		imported++
+	}
+
+	return imported, skipped, nil
+}
+
+func (c compiledPackageCache) importEntry(
+	key bistatepkg.CompiledPackageKey,
+	manifestEntry compiledPackageCacheManifestEntry,
+	importDir string,
+	repo bistatepkg.CompiledPackageRepo,
+	blobstore boshblob.Blobstore,
+) error {
+	blobPath := filepath.Join(importDir, manifestEntry.Path)
+
+	// Prevent paths from escaping extracted directory
+	if !strings.HasPrefix(filepath.Clean(blobPath), filepath.Clean(importDir)+string(filepath.Separator)) {
+		return bosherr.Errorf("Expected blob path '%s' to be within the tarball", manifestEntry.Path)
+	}
+
+	err := c.verifySHA1(blobPath, manifestEntry.SHA1)
+	if err != nil {
+		return err
+	}
+
+	blobID, _, err := blobstore.Create(blobPath)
+	if err != nil {
+		return bosherr.WrapError(err, "Creating blob")
+	}
+
+	entry := bistatepkg.CompiledPackageEntry{
+		Key: key,
+		Record: bistatepkg.CompiledPackageRecord{
+			BlobID:   blobID,
+			BlobSHA1: manifestEntry.SHA1,
+		},
+	}
+
+	return repo.SaveEntry(entry)
+}
+
+func (c compiledPackageCache) verifySHA1(path, expectedSHA1 string) error {
+	actualSHA1, err := c.sha1Calc.Calculate(path)
+	if err != nil {
+		return bosherr.WrapError(err, "Calculating blob SHA1")
+	}
+
+	if actualSHA1 != expectedSHA1 {
+		return bosherr.Errorf("Expected blob SHA1 to be '%s' but was '%s'", expectedSHA1, actualSHA1)
+	}
+
+	return nil
+}
+
+func (c compiledPackageCache) hasEntry(entries []bistatepkg.CompiledPackageEntry, key bistatepkg.CompiledPackageKey) bool {
+	for _, entry := range entries {
+		if entry.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c compiledPackageCache) repoAndBlobstore(target Target) (bistatepkg.CompiledPackageRepo, boshblob.Blobstore) {
+	index := biindex.NewFileIndex(target.CompiledPackagedIndexPath(), c.fs)
+	repo := bistatepkg.NewCompiledPackageRepo(index)
+
+	options := map[string]interface{}{"blobstore_path": target.BlobstorePath()}
+	blobstore := boshblob.NewLocalBlobstore(c.fs, c.uuidGenerator, options)
+
+	return repo, blobstore
+}
+
+// CurrentOS identifies OS on which packages are compiled locally,
+// e.g. 'linux/amd64/ubuntu/16.04' based on '/etc/os-release'
+func CurrentOS(fs boshsys.FileSystem) string {
+	osName := fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH)
+
+	contents, err := fs.ReadFileString("/etc/os-release")
+	if err != nil {
+		return osName
+	}
+
+	var id, versionID string
+
+	for _, line := range strings.Split(contents, "\n") {
+		pieces := strings.SplitN(strings.TrimSpace(line), "=", 2)
+		if len(pieces) != 2 {
+			continue
+		}
+
+		val := strings.Trim(pieces[1], `"'`)
+
+		switch pieces[0] {
+		case "ID":
+			id = val
+		case "VERSION_ID":
+			versionID = val
+		}
+	}
+
+	if len(id) > 0 {
+		osName += "/" + id
+	}
+
+	if len(versionID) > 0 {
+		osName += "/" + versionID
+	}
+
+	return osName
+}
@@ -0,0 +1,269 @@
+package installation_test
+
+import (
+	"encoding/json"
+	"errors"
+
+	fakeboshcmd "github.com/cloudfoundry/bosh-utils/fileutil/fakes"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	fakebicrypto "github.com/cloudfoundry/bosh-cli/crypto/fakes"
+	biindex "github.com/cloudfoundry/bosh-cli/index"
+	. "github.com/cloudfoundry/bosh-cli/installation"
+	bistatepkg "github.com/cloudfoundry/bosh-cli/state/pkg"
+)
+
+var _ = Describe("CompiledPackageCache", func() {
+	var (
+		fs                *fakesys.FakeFileSystem
+		compressor        *fakeboshcmd.FakeCompressor
+		sha1Calc          *fakebicrypto.FakeSha1Calculator
+		fakeUUIDGenerator *fakeuuid.FakeGenerator
+
+		target Target
+		repo   bistatepkg.CompiledPackageRepo
+
+		cache CompiledPackageCache
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		compressor = fakeboshcmd.NewFakeCompressor()
+		sha1Calc = fakebicrypto.NewFakeSha1Calculator()
+		fakeUUIDGenerator = fakeuuid.NewFakeGenerator()
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+
+		target = NewTarget("/fake-installation")
+		repo = bistatepkg.NewCompiledPackageRepo(biindex.NewFileIndex(target.CompiledPackagedIndexPath(), fs))
+
+		fs.TempDirDir = "/fake-tmp-dir"
+
+		cache = NewCompiledPackageCache("linux/amd64/ubuntu/16.04", fs, compressor, sha1Calc, fakeUUIDGenerator, logger)
+	})
+
+	compiledPackageEntry := func(name, blobID, blobSHA1 string) bistatepkg.CompiledPackageEntry {
+		return bistatepkg.CompiledPackageEntry{
+			Key: bistatepkg.CompiledPackageKey{
+				PackageName:        name,
+				PackageFingerprint: name + "-fingerprint",
+				DependencyKey:      "[]",
+			},
+			Record: bistatepkg.CompiledPackageRecord{
+				BlobID:   blobID,
+				BlobSHA1: blobSHA1,
+			},
+		}
+	}
+
+	Describe("Export", func() {
+		var (
+			exportedManifest []byte
+			exportedBlob     string
+		)
+
+		BeforeEach(func() {
+			err := repo.SaveEntry(compiledPackageEntry("fake-package-name", "fake-blob-id", "fake-blob-sha1"))
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.WriteFileString("/fake-installation/blobs/fake-blob-id", "fake-blob-content")
+			Expect(err).ToNot(HaveOccurred())
+
+			sha1Calc.SetCalculateBehavior(map[string]fakebicrypto.CalculateInput{
+				"/fake-installation/blobs/fake-blob-id": {Sha1: "fake-blob-sha1"},
+			})
+
+			err = fs.WriteFileString("/fake-compressed.tgz", "fake-tarball-content")
+			Expect(err).ToNot(HaveOccurred())
+
+			compressor.CompressFilesInDirTarballPath = "/fake-compressed.tgz"
+			compressor.CompressFilesInDirCallBack = func() {
+				var err error
+				exportedManifest, err = fs.ReadFile("/fake-tmp-dir/compiled_packages.json")
+				Expect(err).ToNot(HaveOccurred())
+
+				exportedBlob, err = fs.ReadFileString("/fake-tmp-dir/blobs/fake-blob-sha1")
+				Expect(err).ToNot(HaveOccurred())
+			}
+		})
+
+		It("compresses compiled packages with a manifest into the tarball", func() {
+			count, err := cache.Export(target, "/fake-export.tgz")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(count).To(Equal(1))
+
+			Expect(compressor.CompressFilesInDirDir).To(Equal("/fake-tmp-dir"))
+			Expect(exportedBlob).To(Equal("fake-blob-content"))
+
+			var manifest map[string]interface{}
+			Expect(json.Unmarshal(exportedManifest, &manifest)).To(Succeed())
+			Expect(manifest).To(Equal(map[string]interface{}{
+				"os": "linux/amd64/ubuntu/16.04",
+				"packages": []interface{}{
+					map[string]interface{}{
+						"name":           "fake-package-name",
+						"fingerprint":    "fake-package-name-fingerprint",
+						"dependency_key": "[]",
+						"sha1":           "fake-blob-sha1",
+						"path":           "blobs/fake-blob-sha1",
+					},
+				},
+			}))
+
+			Expect(fs.ReadFileString("/fake-export.tgz")).To(Equal("fake-tarball-content"))
+		})
+
+		It("cleans up temporary files", func() {
+			_, err := cache.Export(target, "/fake-export.tgz")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.FileExists("/fake-tmp-dir")).To(BeFalse())
+			Expect(compressor.CleanUpTarballPath).To(Equal("/fake-compressed.tgz"))
+		})
+
+		It("returns an error when blob SHA1 does not match", func() {
+			sha1Calc.SetCalculateBehavior(map[string]fakebicrypto.CalculateInput{
+				"/fake-installation/blobs/fake-blob-id": {Sha1: "fake-other-sha1"},
+			})
+
+			_, err := cache.Export(target, "/fake-export.tgz")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Exporting compiled package 'fake-package-name/fake-package-name-fingerprint'"))
+			Expect(err.Error()).To(ContainSubstring("Expected blob SHA1 to be 'fake-blob-sha1' but was 'fake-other-sha1'"))
+		})
+
+		It("returns an error when compressing fails", func() {
+			compressor.CompressFilesInDirErr = errors.New("fake-compress-err")
+
+			_, err := cache.Export(target, "/fake-export.tgz")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-compress-err"))
+		})
+	})
+
+	Describe("Import", func() {
+		var manifestOS string
+
+		BeforeEach(func() {
+			manifestOS = "linux/amd64/ubuntu/16.04"
+
+			compressor.DecompressFileToDirCallBack = func() {
+				manifest := map[string]interface{}{
+					"os": manifestOS,
+					"packages": []map[string]string{
+						{
+							"name":           "fake-package-name",
+							"fingerprint":    "fake-package-name-fingerprint",
+							"dependency_key": "[]",
+							"sha1":           "fake-blob-sha1",
+							"path":           "blobs/fake-blob-sha1",
+						},
+						{
+							"name":           "fake-other-package-name",
+							"fingerprint":    "fake-other-package-name-fingerprint",
+							"dependency_key": "[]",
+							"sha1":           "fake-other-blob-sha1",
+							"path":           "blobs/fake-other-blob-sha1",
+						},
+					},
+				}
+
+				manifestBytes, err := json.Marshal(manifest)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fs.WriteFile("/fake-tmp-dir/compiled_packages.json", manifestBytes)).To(Succeed())
+				Expect(fs.WriteFileString("/fake-tmp-dir/blobs/fake-blob-sha1", "fake-blob-content")).To(Succeed())
+				Expect(fs.WriteFileString("/fake-tmp-dir/blobs/fake-other-blob-sha1", "fake-other-blob-content")).To(Succeed())
+			}
+
+			sha1Calc.SetCalculateBehavior(map[string]fakebicrypto.CalculateInput{
+				"/fake-tmp-dir/blobs/fake-blob-sha1":       {Sha1: "fake-blob-sha1"},
+				"/fake-tmp-dir/blobs/fake-other-blob-sha1": {Sha1: "fake-other-blob-sha1"},
+			})
+
+			fakeUUIDGenerator.GeneratedUUID = "fake-imported-blob-id"
+		})
+
+		It("extracts tarball and saves compiled packages into the installation", func() {
+			imported, skipped, err := cache.Import(target, "/fake-import.tgz")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(imported).To(Equal(2))
+			Expect(skipped).To(Equal(0))
+
+			Expect(compressor.DecompressFileToDirTarballPaths).To(Equal([]string{"/fake-import.tgz"}))
+			Expect(compressor.DecompressFileToDirDirs).To(Equal([]string{"/fake-tmp-dir"}))
+
+			Expect(fs.ReadFileString("/fake-installation/blobs/fake-imported-blob-id")).ToNot(BeEmpty())
+
+			entries, err := repo.All()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(Equal([]bistatepkg.CompiledPackageEntry{
+				compiledPackageEntry("fake-package-name", "fake-imported-blob-id", "fake-blob-sha1"),
+				compiledPackageEntry("fake-other-package-name", "fake-imported-blob-id", "fake-other-blob-sha1"),
+			}))
+
+			Expect(fs.FileExists("/fake-tmp-dir")).To(BeFalse())
+		})
+
+		It("skips compiled packages that are already present", func() {
+			existingEntry := compiledPackageEntry("fake-package-name", "fake-existing-blob-id", "fake-blob-sha1")
+			Expect(repo.SaveEntry(existingEntry)).To(Succeed())
+
+			imported, skipped, err := cache.Import(target, "/fake-import.tgz")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(imported).To(Equal(1))
+			Expect(skipped).To(Equal(1))
+
+			entries, err := repo.All()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries[0]).To(Equal(existingEntry))
+		})
+
+		It("returns an error when packages were compiled on another OS", func() {
+			manifestOS = "linux/amd64/centos/7"
+
+			_, _, err := cache.Import(target, "/fake-import.tgz")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected compiled packages to be compiled on 'linux/amd64/ubuntu/16.04' but they were compiled on 'linux/amd64/centos/7'"))
+
+			entries, err := repo.All()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+
+		It("returns an error when blob SHA1 does not match", func() {
+			sha1Calc.SetCalculateBehavior(map[string]fakebicrypto.CalculateInput{
+				"/fake-tmp-dir/blobs/fake-blob-sha1": {Sha1: "fake-wrong-sha1"},
+			})
+
+			_, _, err := cache.Import(target, "/fake-import.tgz")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Importing compiled package 'fake-package-name/fake-package-name-fingerprint'"))
+			Expect(err.Error()).To(ContainSubstring("Expected blob SHA1 to be 'fake-blob-sha1' but was 'fake-wrong-sha1'"))
+		})
+
+		It("returns an error when extracting fails", func() {
+			compressor.DecompressFileToDirErr = errors.New("fake-decompress-err")
+
+			_, _, err := cache.Import(target, "/fake-import.tgz")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Extracting compiled packages '/fake-import.tgz'"))
+		})
+	})
+
+	Describe("CurrentOS", func() {
+		It("includes distribution name and version from os-release", func() {
+			fs := fakesys.NewFakeFileSystem()
+			Expect(fs.WriteFileString("/etc/os-release", "NAME=\"Ubuntu\"\nID=ubuntu\nVERSION_ID=\"16.04\"\n")).To(Succeed())
+
+			Expect(CurrentOS(fs)).To(HaveSuffix("/ubuntu/16.04"))
+		})
+
+		It("returns only platform when os-release is missing", func() {
+			Expect(CurrentOS(fakesys.NewFakeFileSystem())).ToNot(ContainSubstring("ubuntu"))
+		})
+	})
+})
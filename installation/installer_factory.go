@@ -29,6 +29,7 @@ type installerFactory struct {
 	releaseJobResolver    bideplrel.JobResolver
 	uuidGenerator         boshuuid.Generator
 	registryServerManager biregistry.ServerManager
+	compilationWorkers    int
 	logger                boshlog.Logger
 	logTag                string
 	fs                    boshsys.FileSystem
@@ -41,6 +42,7 @@ func NewInstallerFactory(
 	releaseJobResolver bideplrel.JobResolver,
 	uuidGenerator boshuuid.Generator,
 	registryServerManager biregistry.ServerManager,
+	compilationWorkers int,
 	logger boshlog.Logger,
 	fs boshsys.FileSystem,
 ) InstallerFactory {
@@ -51,6 +53,7 @@ func NewInstallerFactory(
 		releaseJobResolver:    releaseJobResolver,
 		uuidGenerator:         uuidGenerator,
 		registryServerManager: registryServerManager,
+		compilationWorkers:    compilationWorkers,
 		logger:                logger,
 		logTag:                "installer",
 		fs:                    fs,
@@ -65,6 +68,7 @@ func (f *installerFactory) NewInstaller(target Target) Installer {
 		extractor:          f.extractor,
 		uuidGenerator:      f.uuidGenerator,
 		releaseJobResolver: f.releaseJobResolver,
+		compilationWorkers: f.compilationWorkers,
 		fs:                 f.fs,
 	}
 
@@ -87,6 +91,7 @@ type installerFactoryContext struct {
 	extractor          boshcmd.Compressor
 	uuidGenerator      boshuuid.Generator
 	releaseJobResolver bideplrel.JobResolver
+	compilationWorkers int
 
 	jobDependencyCompiler bistatejob.DependencyCompiler
 	packageCompiler       bistatepkg.Compiler
@@ -97,7 +102,7 @@ type installerFactoryContext struct {
 
 func (c *installerFactoryContext) JobRenderer() JobRenderer {
 
-	erbRenderer := bierbrenderer.NewERBRenderer(c.fs, c.runner, c.logger)
+	erbRenderer := bierbrenderer.NewCompatERBRenderer(c.fs, c.runner, c.logger)
 	jobRenderer := bitemplate.NewJobRenderer(erbRenderer, c.fs, c.uuidGenerator, c.logger)
 	jobListRenderer := bitemplate.NewJobListRenderer(jobRenderer, c.logger)
 
@@ -126,6 +131,7 @@ func (c *installerFactoryContext) JobDependencyCompiler() bistatejob.DependencyC
 
 	c.jobDependencyCompiler = bistatejob.NewDependencyCompiler(
 		c.InstallationStatePackageCompiler(),
+		c.compilationWorkers,
 		c.logger,
 	)
 
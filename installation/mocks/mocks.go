@@ -1,5 +1,5 @@
 // Automatically generated by MockGen. DO NOT EDIT!
-// Source: github.com/cloudfoundry/bosh-cli/installation (interfaces: Installation,Installer,InstallerFactory,Uninstaller,JobResolver,PackageCompiler,JobRenderer)
+// Source: github.com/cloudfoundry/bosh-cli/installation (interfaces: Installation,Installer,InstallerFactory,Uninstaller,JobResolver,PackageCompiler,JobRenderer,CompiledPackageCache)
 
 package mocks
 
@@ -282,3 +282,47 @@ func (_m *MockJobRenderer) RenderAndUploadFrom(_param0 manifest.Manifest, _param
 func (_mr *_MockJobRendererRecorder) RenderAndUploadFrom(arg0, arg1, arg2 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "RenderAndUploadFrom", arg0, arg1, arg2)
 }
+
+// Mock of CompiledPackageCache interface
+type MockCompiledPackageCache struct {
+	ctrl     *gomock.Controller
+	recorder *_MockCompiledPackageCacheRecorder
+}
+
+// Recorder for MockCompiledPackageCache (not exported)
+type _MockCompiledPackageCacheRecorder struct {
+	mock *MockCompiledPackageCache
+}
+
+func NewMockCompiledPackageCache(ctrl *gomock.Controller) *MockCompiledPackageCache {
+	mock := &MockCompiledPackageCache{ctrl: ctrl}
+	mock.recorder = &_MockCompiledPackageCacheRecorder{mock}
+	return mock
+}
+
+func (_m *MockCompiledPackageCache) EXPECT() *_MockCompiledPackageCacheRecorder {
+	return _m.recorder
+}
+
+func (_m *MockCompiledPackageCache) Export(_param0 installation.Target, _param1 string) (int, error) {
+	ret := _m.ctrl.Call(_m, "Export", _param0, _param1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockCompiledPackageCacheRecorder) Export(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Export", arg0, arg1)
+}
+
+func (_m *MockCompiledPackageCache) Import(_param0 installation.Target, _param1 string) (int, int, error) {
+	ret := _m.ctrl.Call(_m, "Import", _param0, _param1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+func (_mr *_MockCompiledPackageCacheRecorder) Import(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Import", arg0, arg1)
+}
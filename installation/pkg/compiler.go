@@ -4,6 +4,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sync"
 
 	"github.com/cloudfoundry/bosh-cli/installation/blobextract"
 	birelpkg "github.com/cloudfoundry/bosh-cli/release/pkg"
@@ -25,6 +26,12 @@ type compiler struct {
 	blobExtractor       blobextract.Extractor
 	logger              boshlog.Logger
 	logTag              string
+
+	// Packages may be compiled concurrently; dependencies installed into
+	// packages dir are shared between compilations and removed when unused
+	lock              sync.Mutex
+	compiling         int
+	installedPackages map[string]int
 }
 
 func NewPackageCompiler(
@@ -47,6 +54,8 @@ func NewPackageCompiler(
 		blobExtractor:       blobExtractor,
 		logger:              logger,
 		logTag:              "packageCompiler",
+
+		installedPackages: map[string]int{},
 	}
 }
 
@@ -67,21 +76,22 @@ func (c *compiler) Compile(pkg birelpkg.Compilable) (bistatepkg.CompiledPackageR
 
 	c.logger.Debug(c.logTag, "Installing dependencies of package '%s/%s'", pkg.Name(), pkg.Fingerprint())
 
-	err = c.installPackages(pkg.Deps())
+	installDir := path.Join(c.packagesDir, pkg.Name())
+
+	c.lock.Lock()
+	c.compiling++
+	c.lock.Unlock()
+
+	installedDeps, err := c.installPackages(pkg.Deps())
+
+	defer c.cleanUp(installedDeps, installDir)
+
 	if err != nil {
 		return record, isCompiledPackage, bosherr.WrapErrorf(err, "Installing dependencies of package '%s'", pkg.Name())
 	}
 
-	defer func() {
-		if err = c.fileSystem.RemoveAll(c.packagesDir); err != nil {
-			c.logger.Warn(c.logTag, "Failed to remove packages dir: %s", err.Error())
-		}
-	}()
-
 	c.logger.Debug(c.logTag, "Compiling package '%s/%s'", pkg.Name(), pkg.Fingerprint())
 
-	installDir := path.Join(c.packagesDir, pkg.Name())
-
 	err = c.fileSystem.MkdirAll(installDir, os.ModePerm)
 	if err != nil {
 		return record, isCompiledPackage, bosherr.WrapError(err, "Creating package install dir")
@@ -141,24 +151,79 @@ func (c *compiler) Compile(pkg birelpkg.Compilable) (bistatepkg.CompiledPackageR
 	return record, isCompiledPackage, nil
 }
 
-func (c *compiler) installPackages(packages []birelpkg.Compilable) error {
+// installPackages returns names of packages that were installed even if installing other packages fails
+func (c *compiler) installPackages(packages []birelpkg.Compilable) ([]string, error) {
+	installed := []string{}
+
 	for _, pkg := range packages {
 		c.logger.Debug(c.logTag, "Checking for compiled package '%s/%s'", pkg.Name(), pkg.Fingerprint())
 
 		record, found, err := c.compiledPackageRepo.Find(pkg)
 		if err != nil {
-			return bosherr.WrapErrorf(err, "Attempting to find compiled package '%s'", pkg.Name())
+			return installed, bosherr.WrapErrorf(err, "Attempting to find compiled package '%s'", pkg.Name())
 		} else if !found {
-			return bosherr.Errorf("Finding compiled package '%s'", pkg.Name())
+			return installed, bosherr.Errorf("Finding compiled package '%s'", pkg.Name())
 		}
 
+		err = c.installPackage(pkg, record)
+		if err != nil {
+			return installed, err
+		}
+
+		installed = append(installed, pkg.Name())
+	}
+
+	return installed, nil
+}
+
+func (c *compiler) installPackage(pkg birelpkg.Compilable, record bistatepkg.CompiledPackageRecord) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.installedPackages[pkg.Name()] == 0 {
 		c.logger.Debug(c.logTag, "Installing package '%s/%s'", pkg.Name(), pkg.Fingerprint())
 
-		err = c.blobExtractor.Extract(record.BlobID, record.BlobSHA1, filepath.Join(c.packagesDir, pkg.Name()))
+		err := c.blobExtractor.Extract(record.BlobID, record.BlobSHA1, filepath.Join(c.packagesDir, pkg.Name()))
 		if err != nil {
 			return bosherr.WrapErrorf(err, "Installing package '%s' into '%s'", pkg.Name(), c.packagesDir)
 		}
 	}
 
+	c.installedPackages[pkg.Name()]++
+
 	return nil
 }
+
+// cleanUp removes compiled package and dependencies no longer used by other compilations
+func (c *compiler) cleanUp(installedDeps []string, installDir string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.compiling--
+
+	if c.compiling == 0 {
+		c.installedPackages = map[string]int{}
+
+		if err := c.fileSystem.RemoveAll(c.packagesDir); err != nil {
+			c.logger.Warn(c.logTag, "Failed to remove packages dir: %s", err.Error())
+		}
+
+		return
+	}
+
+	for _, name := range installedDeps {
+		c.installedPackages[name]--
+
+		if c.installedPackages[name] == 0 {
+			delete(c.installedPackages, name)
+
+			if err := c.fileSystem.RemoveAll(filepath.Join(c.packagesDir, name)); err != nil {
+				c.logger.Warn(c.logTag, "Failed to remove installed package: %s", err.Error())
+			}
+		}
+	}
+
+	if err := c.fileSystem.RemoveAll(installDir); err != nil {
+		c.logger.Warn(c.logTag, "Failed to remove package install dir: %s", err.Error())
+	}
+}
@@ -2,35 +2,71 @@ package tarball
 
 import (
 	"crypto/sha1"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshfu "github.com/cloudfoundry/bosh-utils/fileutil"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	"github.com/pivotal-golang/clock"
 )
 
+const cacheMetadataSuffix = ".json"
+
 type Cache interface {
 	Get(source Source) (path string, found bool)
 	Path(source Source) (path string)
 	Save(sourcePath string, source Source) error
+
+	// List returns cached tarballs ordered from least to most recently used
+	List() ([]CacheEntry, error)
+	Delete(path string) error
+
+	// Prune deletes least recently used tarballs that were not used within maxAge
+	// and then until cache takes no more than maxSize bytes. Zero values disable a limit.
+	Prune(maxAge time.Duration, maxSize uint64) ([]CacheEntry, error)
+}
+
+type CacheEntry struct {
+	Path string
+	URL  string
+	SHA1 string
+	Size uint64
+
+	DownloadedAt time.Time
+	LastUsedAt   time.Time
+}
+
+// cacheEntryMetadata is kept next to a cached tarball since
+// tarball file name only includes SHA1 of the source URL
+type cacheEntryMetadata struct {
+	URL          string    `json:"url"`
+	SHA1         string    `json:"sha1"`
+	DownloadedAt time.Time `json:"downloaded_at"`
+	LastUsedAt   time.Time `json:"last_used_at"`
 }
 
 type cache struct {
-	basePath string
-	fs       boshsys.FileSystem
-	logger   boshlog.Logger
-	logTag   string
+	basePath    string
+	fs          boshsys.FileSystem
+	timeService clock.Clock
+	logger      boshlog.Logger
+	logTag      string
 }
 
-func NewCache(basePath string, fs boshsys.FileSystem, logger boshlog.Logger) Cache {
+func NewCache(basePath string, fs boshsys.FileSystem, timeService clock.Clock, logger boshlog.Logger) Cache {
 	return &cache{
-		basePath: basePath,
-		fs:       fs,
-		logger:   logger,
-		logTag:   "tarballCache",
+		basePath:    basePath,
+		fs:          fs,
+		timeService: timeService,
+		logger:      logger,
+		logTag:      "tarballCache",
 	}
 }
 
@@ -38,6 +74,20 @@ func (c *cache) Get(source Source) (string, bool) {
 	cachedPath := c.Path(source)
 	if c.fs.FileExists(cachedPath) {
 		c.logger.Debug(c.logTag, "Found cached tarball at: '%s'", cachedPath)
+
+		metadata, found := c.readMetadata(cachedPath)
+		if !found {
+			metadata = cacheEntryMetadata{URL: source.GetURL(), SHA1: source.GetSHA1()}
+		}
+
+		metadata.LastUsedAt = c.timeService.Now()
+
+		// Failing to track usage should not prevent using cached tarball
+		err := c.writeMetadata(cachedPath, metadata)
+		if err != nil {
+			c.logger.Warn(c.logTag, "Failed to update cached tarball metadata: %s", err.Error())
+		}
+
 		return cachedPath, true
 	}
 
@@ -55,6 +105,20 @@ func (c *cache) Save(sourcePath string, source Source) error {
 		return bosherr.WrapErrorf(err, "Failed to save tarball path '%s' in cache", sourcePath)
 	}
 
+	now := c.timeService.Now()
+
+	metadata := cacheEntryMetadata{
+		URL:          source.GetURL(),
+		SHA1:         source.GetSHA1(),
+		DownloadedAt: now,
+		LastUsedAt:   now,
+	}
+
+	err = c.writeMetadata(c.Path(source), metadata)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Failed to save tarball path '%s' in cache", sourcePath)
+	}
+
 	c.logger.Debug(c.logTag, "Saving tarball in cache at: '%s'", c.Path(source))
 	return nil
 }
@@ -64,3 +128,162 @@ func (c *cache) Path(source Source) string {
 	filename := fmt.Sprintf("%x-%s", string(urlSHA1[:]), source.GetSHA1())
 	return filepath.Join(c.basePath, filename)
 }
+
+func (c *cache) List() ([]CacheEntry, error) {
+	var entries []CacheEntry
+
+	if !c.fs.FileExists(c.basePath) {
+		return entries, nil
+	}
+
+	err := c.fs.Walk(c.basePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if filepath.Clean(path) == filepath.Clean(c.basePath) {
+				return nil
+			}
+			return filepath.SkipDir
+		}
+
+		if strings.HasSuffix(path, cacheMetadataSuffix) {
+			return nil
+		}
+
+		entries = append(entries, c.entry(path, uint64(info.Size())))
+
+		return nil
+	})
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Listing cache directory '%s'", c.basePath)
+	}
+
+	sort.Sort(cacheEntriesByLastUsed(entries))
+
+	return entries, nil
+}
+
+func (c *cache) Delete(path string) error {
+	err := c.fs.RemoveAll(path)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Deleting cached tarball '%s'", path)
+	}
+
+	err = c.fs.RemoveAll(path + cacheMetadataSuffix)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Deleting cached tarball metadata '%s'", path)
+	}
+
+	c.logger.Debug(c.logTag, "Deleted cached tarball at: '%s'", path)
+
+	return nil
+}
+
+func (c *cache) Prune(maxAge time.Duration, maxSize uint64) ([]CacheEntry, error) {
+	var deleted []CacheEntry
+
+	entries, err := c.List()
+	if err != nil {
+		return deleted, err
+	}
+
+	var totalSize uint64
+
+	for _, entry := range entries {
+		totalSize += entry.Size
+	}
+
+	now := c.timeService.Now()
+
+	for _, entry := range entries {
+		tooOld := maxAge > 0 && now.Sub(entry.LastUsedAt) > maxAge
+		tooBig := maxSize > 0 && totalSize > maxSize
+
+		if !tooOld && !tooBig {
+			continue
+		}
+
+		err := c.Delete(entry.Path)
+		if err != nil {
+			return deleted, err
+		}
+
+		totalSize -= entry.Size
+		deleted = append(deleted, entry)
+	}
+
+	return deleted, nil
+}
+
+func (c *cache) entry(path string, size uint64) CacheEntry {
+	entry := CacheEntry{Path: path, Size: size}
+
+	metadata, found := c.readMetadata(path)
+	if found {
+		entry.URL = metadata.URL
+		entry.SHA1 = metadata.SHA1
+		entry.DownloadedAt = metadata.DownloadedAt
+		entry.LastUsedAt = metadata.LastUsedAt
+		return entry
+	}
+
+	// Tarballs cached before metadata was introduced only include SHA1 in their name
+	pieces := strings.SplitN(filepath.Base(path), "-", 2)
+	if len(pieces) == 2 {
+		entry.SHA1 = pieces[1]
+	}
+
+	return entry
+}
+
+func (c *cache) readMetadata(path string) (cacheEntryMetadata, bool) {
+	var metadata cacheEntryMetadata
+
+	metadataPath := path + cacheMetadataSuffix
+
+	if !c.fs.FileExists(metadataPath) {
+		return metadata, false
+	}
+
+	bytes, err := c.fs.ReadFile(metadataPath)
+	if err != nil {
+		c.logger.Warn(c.logTag, "Failed to read cached tarball metadata '%s': %s", metadataPath, err.Error())
+		return metadata, false
+	}
+
+	err = json.Unmarshal(bytes, &metadata)
+	if err != nil {
+		c.logger.Warn(c.logTag, "Failed to unmarshal cached tarball metadata '%s': %s", metadataPath, err.Error())
+		return metadata, false
+	}
+
+	return metadata, true
+}
+
+func (c *cache) writeMetadata(path string, metadata cacheEntryMetadata) error {
+	bytes, err := json.Marshal(metadata)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling cached tarball metadata")
+	}
+
+	err = c.fs.WriteFile(path+cacheMetadataSuffix, bytes)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing cached tarball metadata")
+	}
+
+	return nil
+}
+
+type cacheEntriesByLastUsed []CacheEntry
+
+func (s cacheEntriesByLastUsed) Len() int      { return len(s) }
+func (s cacheEntriesByLastUsed) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func (s cacheEntriesByLastUsed) Less(i, j int) bool {
+	if s[i].LastUsedAt.Equal(s[j].LastUsedAt) {
+		return s[i].Path < s[j].Path
+	}
+	return s[i].LastUsedAt.Before(s[j].LastUsedAt)
+}
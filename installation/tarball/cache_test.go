@@ -3,26 +3,33 @@ package tarball_test
 import (
 	"os"
 	"syscall"
+	"time"
 
 	. "github.com/cloudfoundry/bosh-cli/installation/tarball"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/pivotal-golang/clock/fakeclock"
 )
 
 var _ = Describe("Cache", func() {
 	var (
-		cache Cache
-		fs    *fakesys.FakeFileSystem
+		cache       Cache
+		fs          *fakesys.FakeFileSystem
+		timeService *fakeclock.FakeClock
+		startTime   time.Time
 	)
 
 	BeforeEach(func() {
 		logger := boshlog.NewLogger(boshlog.LevelNone)
 		fs = fakesys.NewFakeFileSystem()
+		startTime = time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+		timeService = fakeclock.NewFakeClock(startTime)
 		cache = NewCache(
 			"/fake-base-path",
 			fs,
+			timeService,
 			logger,
 		)
 	})
@@ -113,4 +120,140 @@ var _ = Describe("Cache", func() {
 		})
 		Expect(err).ToNot(HaveOccurred())
 	})
+
+	Describe("List", func() {
+		saveTarball := func(url, sha1, content string) {
+			fs.WriteFileString("source-path", content)
+
+			err := cache.Save("source-path", &fakeSource{sha1: sha1, url: url, description: "some tarball"})
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		It("returns no entries when nothing was cached", func() {
+			entries, err := cache.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+
+		It("returns cached tarballs with their source URL, SHA1 and size ordered by last use", func() {
+			saveTarball("http://foo.bar.com", "fake-sha1", "fake-content")
+
+			timeService.Increment(time.Hour)
+			saveTarball("http://baz.bar.com", "fake-other-sha1", "fake-other-content")
+
+			timeService.Increment(time.Hour)
+			_, found := cache.Get(&fakeSource{sha1: "fake-sha1", url: "http://foo.bar.com"})
+			Expect(found).To(BeTrue())
+
+			entries, err := cache.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(Equal([]CacheEntry{
+				{
+					Path:         cache.Path(&fakeSource{sha1: "fake-other-sha1", url: "http://baz.bar.com"}),
+					URL:          "http://baz.bar.com",
+					SHA1:         "fake-other-sha1",
+					Size:         18,
+					DownloadedAt: startTime.Add(time.Hour),
+					LastUsedAt:   startTime.Add(time.Hour),
+				},
+				{
+					Path:         "/fake-base-path/587cd74a86333e7f1ebca70474a1f4456e4b5d3e-fake-sha1",
+					URL:          "http://foo.bar.com",
+					SHA1:         "fake-sha1",
+					Size:         12,
+					DownloadedAt: startTime,
+					LastUsedAt:   startTime.Add(2 * time.Hour),
+				},
+			}))
+		})
+
+		It("returns SHA1 from file name for tarballs cached without metadata", func() {
+			fs.WriteFileString("/fake-base-path/587cd74a86333e7f1ebca70474a1f4456e4b5d3e-fake-sha1", "fake-content")
+
+			entries, err := cache.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(Equal([]CacheEntry{
+				{
+					Path: "/fake-base-path/587cd74a86333e7f1ebca70474a1f4456e4b5d3e-fake-sha1",
+					SHA1: "fake-sha1",
+					Size: 12,
+				},
+			}))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("deletes cached tarball and its metadata", func() {
+			source := &fakeSource{sha1: "fake-sha1", url: "http://foo.bar.com"}
+
+			fs.WriteFileString("source-path", "")
+			Expect(cache.Save("source-path", source)).To(Succeed())
+
+			err := cache.Delete(cache.Path(source))
+			Expect(err).ToNot(HaveOccurred())
+
+			_, found := cache.Get(source)
+			Expect(found).To(BeFalse())
+
+			entries, err := cache.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+	})
+
+	Describe("Prune", func() {
+		var (
+			oldSource    *fakeSource
+			middleSource *fakeSource
+			newSource    *fakeSource
+		)
+
+		BeforeEach(func() {
+			oldSource = &fakeSource{sha1: "fake-old-sha1", url: "http://old"}
+			middleSource = &fakeSource{sha1: "fake-middle-sha1", url: "http://middle"}
+			newSource = &fakeSource{sha1: "fake-new-sha1", url: "http://new"}
+
+			for _, source := range []*fakeSource{oldSource, middleSource, newSource} {
+				fs.WriteFileString("source-path", "0123456789")
+				Expect(cache.Save("source-path", source)).To(Succeed())
+				timeService.Increment(24 * time.Hour)
+			}
+		})
+
+		It("deletes tarballs that were not used within max age", func() {
+			deleted, err := cache.Prune(36*time.Hour, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deleted).To(HaveLen(2))
+			Expect(deleted[0].URL).To(Equal("http://old"))
+			Expect(deleted[1].URL).To(Equal("http://middle"))
+
+			Expect(fs.FileExists(cache.Path(oldSource))).To(BeFalse())
+			Expect(fs.FileExists(cache.Path(middleSource))).To(BeFalse())
+			Expect(fs.FileExists(cache.Path(newSource))).To(BeTrue())
+		})
+
+		It("deletes least recently used tarballs until cache fits into max size", func() {
+			_, found := cache.Get(oldSource)
+			Expect(found).To(BeTrue())
+
+			deleted, err := cache.Prune(0, 20)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deleted).To(HaveLen(1))
+			Expect(deleted[0].URL).To(Equal("http://middle"))
+
+			Expect(fs.FileExists(cache.Path(oldSource))).To(BeTrue())
+			Expect(fs.FileExists(cache.Path(middleSource))).To(BeFalse())
+			Expect(fs.FileExists(cache.Path(newSource))).To(BeTrue())
+		})
+
+		It("does not delete anything without limits", func() {
+			deleted, err := cache.Prune(0, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deleted).To(BeEmpty())
+
+			entries, err := cache.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(3))
+		})
+	})
 })
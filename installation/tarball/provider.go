@@ -3,12 +3,12 @@ package tarball
 import (
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"strings"
 	"time"
 
 	bicrypto "github.com/cloudfoundry/bosh-cli/crypto"
+	boshproxy "github.com/cloudfoundry/bosh-cli/proxy"
 	biui "github.com/cloudfoundry/bosh-cli/ui"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	bihttpclient "github.com/cloudfoundry/bosh-utils/httpclient"
@@ -30,10 +30,8 @@ type Provider interface {
 var HTTPClient = &http.Client{
 	Transport: &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
-		Dial: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 0 * time.Second,
-		}).Dial,
+		Dial:  boshproxy.Dial,
+
 		TLSHandshakeTimeout: 10 * time.Second,
 	},
 }
@@ -96,8 +94,15 @@ func (p *provider) Get(source Source, stage biui.Stage) (string, error) {
 
 		cachedPath, found = p.cache.Get(source)
 		if found {
-			p.logger.Debug(p.logTag, "Using the tarball from cache: '%s'", cachedPath)
-			return biui.NewSkipStageError(bosherr.Error("Already downloaded"), "Found in local cache")
+			if p.isCachedTarballValid(cachedPath, source) {
+				p.logger.Debug(p.logTag, "Using the tarball from cache: '%s'", cachedPath)
+				return biui.NewSkipStageError(bosherr.Error("Already downloaded"), "Found in local cache")
+			}
+
+			err := p.cache.Delete(cachedPath)
+			if err != nil {
+				return bosherr.WrapError(err, "Deleting corrupt tarball from cache")
+			}
 		}
 
 		retryStrategy := boshretry.NewAttemptRetryStrategy(
@@ -119,6 +124,23 @@ func (p *provider) Get(source Source, stage biui.Stage) (string, error) {
 	return p.cache.Path(source), nil
 }
 
+// isCachedTarballValid protects against cached tarballs
+// that were corrupted after being downloaded
+func (p *provider) isCachedTarballValid(cachedPath string, source Source) bool {
+	cachedSha1, err := p.sha1Calculator.Calculate(cachedPath)
+	if err != nil {
+		p.logger.Warn(p.logTag, "Failed to calculate sha1 for cached tarball '%s', downloading again: %s", cachedPath, err.Error())
+		return false
+	}
+
+	if cachedSha1 != source.GetSHA1() {
+		p.logger.Warn(p.logTag, "SHA1 '%s' of cached tarball '%s' does not match expected SHA1 '%s', downloading again", cachedSha1, cachedPath, source.GetSHA1())
+		return false
+	}
+
+	return true
+}
+
 func (p *provider) downloadRetryable(source Source) boshretry.Retryable {
 	return boshretry.NewRetryable(func() (bool, error) {
 		downloadedFile, err := p.fs.TempFile("tarballProvider")
@@ -13,6 +13,7 @@ import (
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/pivotal-golang/clock"
 )
 
 var _ = Describe("Provider", func() {
@@ -29,7 +30,7 @@ var _ = Describe("Provider", func() {
 	BeforeEach(func() {
 		fs = fakesys.NewFakeFileSystem()
 		logger := boshlog.NewLogger(boshlog.LevelNone)
-		cache = NewCache("/fake-base-path", fs, logger)
+		cache = NewCache("/fake-base-path", fs, clock.NewClock(), logger)
 		sha1Calculator = fakebicrypto.NewFakeSha1Calculator()
 		httpClient = fakebihttpclient.NewFakeHTTPClient()
 		provider = NewProvider(cache, fs, httpClient, sha1Calculator, 3, 0, logger)
@@ -60,6 +61,10 @@ var _ = Describe("Provider", func() {
 				BeforeEach(func() {
 					fs.WriteFileString("fake-source-path", "")
 					cache.Save("fake-source-path", source)
+
+					sha1Calculator.SetCalculateBehavior(map[string]fakebicrypto.CalculateInput{
+						"/fake-base-path/9db1fb7c47637e8709e944a232e1aa98ce6fec26-fake-sha1": {Sha1: "fake-sha1"},
+					})
 				})
 
 				It("returns cached tarball path", func() {
@@ -75,6 +80,41 @@ var _ = Describe("Provider", func() {
 					Expect(fakeStage.PerformCalls[0].Name).To(Equal("Downloading fake-description"))
 					Expect(fakeStage.PerformCalls[0].SkipError.Error()).To(Equal("Found in local cache: Already downloaded"))
 				})
+
+				Context("when cached tarball is corrupt", func() {
+					var tempDownloadFilePath string
+
+					BeforeEach(func() {
+						tempDownloadFile, err := ioutil.TempFile("", "temp-download-file")
+						Expect(err).ToNot(HaveOccurred())
+						fs.ReturnTempFile = tempDownloadFile
+						tempDownloadFilePath = tempDownloadFile.Name()
+
+						sha1Calculator.SetCalculateBehavior(map[string]fakebicrypto.CalculateInput{
+							"/fake-base-path/9db1fb7c47637e8709e944a232e1aa98ce6fec26-fake-sha1": {Sha1: "fake-corrupt-sha1"},
+							tempDownloadFilePath: {Sha1: "fake-sha1"},
+						})
+
+						httpClient.SetGetBehavior("fake-body", 200, nil)
+					})
+
+					AfterEach(func() {
+						os.RemoveAll(tempDownloadFilePath)
+					})
+
+					It("downloads tarball again and returns saved cache tarball path", func() {
+						path, err := provider.Get(source, fakeStage)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(path).To(Equal("/fake-base-path/9db1fb7c47637e8709e944a232e1aa98ce6fec26-fake-sha1"))
+
+						Expect(httpClient.GetInputs).To(HaveLen(1))
+						Expect(httpClient.GetInputs[0].Endpoint).To(Equal("http://fake-url"))
+
+						Expect(fakeStage.PerformCalls).To(Equal([]*fakebiui.PerformCall{
+							{Name: "Downloading fake-description"},
+						}))
+					})
+				})
 			})
 
 			Context("when tarball is not present in cache", func() {
@@ -18,6 +18,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-golang/clock"
 
 	biagentclient "github.com/cloudfoundry/bosh-agent/agentclient"
 	bias "github.com/cloudfoundry/bosh-agent/agentclient/applyspec"
@@ -377,7 +378,7 @@ cloud_provider:
 					logger,
 				)
 				fakeHTTPClient := fakebihttpclient.NewFakeHTTPClient()
-				tarballCache := bitarball.NewCache("fake-base-path", fs, logger)
+				tarballCache := bitarball.NewCache("fake-base-path", fs, clock.NewClock(), logger)
 				tarballProvider := bitarball.NewProvider(tarballCache, fs, fakeHTTPClient, fakeSHA1Calculator, 1, 0, logger)
 
 				cpiInstaller := bicpirel.CpiInstaller{
@@ -420,6 +421,7 @@ cloud_provider:
 					legacyDeploymentStateMigrator,
 					releaseManager,
 					deploymentRecord,
+					bidepl.NewPlanner(),
 					mockCloudFactory,
 					stemcellManagerFactory,
 					mockAgentClientFactory,
@@ -0,0 +1,292 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cppforlife/go-patch/patch"
+	"gopkg.in/yaml.v2"
+
+	boshrel "github.com/cloudfoundry/bosh-cli/release"
+	boshjob "github.com/cloudfoundry/bosh-cli/release/job"
+)
+
+// Problem is found in a deployment manifest at Path,
+// e.g. '/instance_groups/name=web/jobs/name=nginx/properties/port'
+type Problem struct {
+	Path    string
+	Message string
+
+	// Warning is set for problems that may not be problems once deployed,
+	// e.g. missing properties could be provided by runtime configs
+	Warning bool
+}
+
+type Linter interface {
+	Lint(manifestBytes []byte, releases []boshrel.Release) ([]Problem, error)
+}
+
+type linter struct{}
+
+type manifest struct {
+	Properties     map[interface{}]interface{} `yaml:"properties"`
+	InstanceGroups []manifestInstanceGroup     `yaml:"instance_groups"`
+}
+
+type manifestInstanceGroup struct {
+	Name       string                      `yaml:"name"`
+	Jobs       []manifestJob               `yaml:"jobs"`
+	Properties map[interface{}]interface{} `yaml:"properties"`
+}
+
+type manifestJob struct {
+	Name       string                      `yaml:"name"`
+	Release    string                      `yaml:"release"`
+	Properties map[interface{}]interface{} `yaml:"properties"`
+
+	Consumes map[string]interface{} `yaml:"consumes"`
+	Provides map[string]interface{} `yaml:"provides"`
+}
+
+// NewLinter checks deployment manifest against specs of jobs
+// without contacting the Director, hence it does not know about
+// properties and links provided by runtime configs.
+func NewLinter() Linter {
+	return linter{}
+}
+
+func (l linter) Lint(manifestBytes []byte, releases []boshrel.Release) ([]Problem, error) {
+	var man manifest
+
+	err := yaml.Unmarshal(manifestBytes, &man)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Unmarshalling manifest")
+	}
+
+	releasesByName := map[string]boshrel.Release{}
+
+	for _, release := range releases {
+		releasesByName[release.Name()] = release
+	}
+
+	var problems []Problem
+
+	for i, group := range man.InstanceGroups {
+		groupTokens := []patch.Token{patch.RootToken{}, patch.KeyToken{Key: "instance_groups"}, l.nameToken(group.Name, i)}
+
+		for j, manJob := range group.Jobs {
+			jobTokens := l.appendToken(l.appendToken(groupTokens, patch.KeyToken{Key: "jobs"}), l.nameToken(manJob.Name, j))
+
+			release, found := releasesByName[manJob.Release]
+			if !found {
+				problems = append(problems, Problem{
+					Path:    l.path(l.appendToken(jobTokens, patch.KeyToken{Key: "release"})),
+					Message: fmt.Sprintf("Release '%s' of job '%s' was not provided", manJob.Release, manJob.Name),
+				})
+				continue
+			}
+
+			job, found := release.FindJobByName(manJob.Name)
+			if !found {
+				problems = append(problems, Problem{
+					Path:    l.path(jobTokens),
+					Message: fmt.Sprintf("Unknown job '%s' in release '%s'", manJob.Name, manJob.Release),
+				})
+				continue
+			}
+
+			problems = append(problems, l.lintJob(jobTokens, manJob, job, man.Properties, group.Properties)...)
+		}
+	}
+
+	return problems, nil
+}
+
+func (l linter) lintJob(
+	jobTokens []patch.Token,
+	manJob manifestJob,
+	job boshjob.Job,
+	globalProps map[interface{}]interface{},
+	groupProps map[interface{}]interface{},
+) []Problem {
+	var problems []Problem
+
+	propsTokens := l.appendToken(jobTokens, patch.KeyToken{Key: "properties"})
+
+	// Director falls back to instance group and global properties
+	// only when job does not specify its own properties
+	props := manJob.Properties
+
+	if props != nil {
+		problems = append(problems, l.lintUnknownProperties(propsTokens, "", props, job)...)
+	} else {
+		props = l.mergeProperties(globalProps, groupProps)
+	}
+
+	var propNames []string
+
+	for propName, propDef := range job.Properties {
+		if propDef.Default == nil {
+			propNames = append(propNames, propName)
+		}
+	}
+
+	sort.Strings(propNames)
+
+	for _, propName := range propNames {
+		if !l.hasProperty(props, strings.Split(propName, ".")) {
+			problems = append(problems, Problem{
+				Path:    l.path(propsTokens),
+				Message: fmt.Sprintf("Missing property '%s' without default value in job '%s'", propName, job.Name()),
+				Warning: true,
+			})
+		}
+	}
+
+	problems = append(problems, l.lintLinks(jobTokens, "consumes", manJob.Consumes, job.Consumes, job.Name())...)
+	problems = append(problems, l.lintLinks(jobTokens, "provides", manJob.Provides, job.Provides, job.Name())...)
+
+	return problems
+}
+
+func (l linter) lintUnknownProperties(tokens []patch.Token, prefix string, props map[interface{}]interface{}, job boshjob.Job) []Problem {
+	var problems []Problem
+
+	for _, key := range l.sortedKeys(props) {
+		propName := prefix + key
+		propTokens := l.appendToken(tokens, patch.KeyToken{Key: key})
+
+		if _, found := job.Properties[propName]; found {
+			continue
+		}
+
+		if l.hasNestedPropertyDefinition(job, propName) {
+			if nestedProps, ok := props[key].(map[interface{}]interface{}); ok {
+				problems = append(problems, l.lintUnknownProperties(propTokens, propName+".", nestedProps, job)...)
+			}
+			continue
+		}
+
+		problems = append(problems, Problem{
+			Path:    l.path(propTokens),
+			Message: fmt.Sprintf("Unknown property '%s' for job '%s'", propName, job.Name()),
+		})
+	}
+
+	return problems
+}
+
+func (l linter) lintLinks(jobTokens []patch.Token, kind string, manLinks map[string]interface{}, linkDefs []boshjob.LinkDefinition, jobName string) []Problem {
+	var problems []Problem
+
+	var linkNames []string
+
+	for linkName := range manLinks {
+		linkNames = append(linkNames, linkName)
+	}
+
+	sort.Strings(linkNames)
+
+	for _, linkName := range linkNames {
+		var found bool
+
+		for _, linkDef := range linkDefs {
+			if linkDef.Name == linkName {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			linkTokens := l.appendToken(l.appendToken(jobTokens, patch.KeyToken{Key: kind}), patch.KeyToken{Key: linkName})
+
+			problems = append(problems, Problem{
+				Path:    l.path(linkTokens),
+				Message: fmt.Sprintf("Unknown link '%s' in '%s' of job '%s'", linkName, kind, jobName),
+			})
+		}
+	}
+
+	return problems
+}
+
+func (l linter) hasNestedPropertyDefinition(job boshjob.Job, propName string) bool {
+	for definedName := range job.Properties {
+		if strings.HasPrefix(definedName, propName+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l linter) hasProperty(props map[interface{}]interface{}, nameParts []string) bool {
+	val, found := props[nameParts[0]]
+	if !found || val == nil {
+		return false
+	}
+
+	if len(nameParts) == 1 {
+		return true
+	}
+
+	nestedProps, ok := val.(map[interface{}]interface{})
+	if !ok {
+		return false
+	}
+
+	return l.hasProperty(nestedProps, nameParts[1:])
+}
+
+func (l linter) mergeProperties(props, overrides map[interface{}]interface{}) map[interface{}]interface{} {
+	result := map[interface{}]interface{}{}
+
+	for k, v := range props {
+		result[k] = v
+	}
+
+	for k, v := range overrides {
+		existingProps, existingOk := result[k].(map[interface{}]interface{})
+		overrideProps, overrideOk := v.(map[interface{}]interface{})
+
+		if existingOk && overrideOk {
+			result[k] = l.mergeProperties(existingProps, overrideProps)
+		} else {
+			result[k] = v
+		}
+	}
+
+	return result
+}
+
+func (l linter) sortedKeys(props map[interface{}]interface{}) []string {
+	var keys []string
+
+	for k := range props {
+		keys = append(keys, fmt.Sprintf("%v", k))
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (l linter) nameToken(name string, index int) patch.Token {
+	if len(name) == 0 {
+		return patch.IndexToken{Index: index}
+	}
+
+	return patch.MatchingIndexToken{Key: "name", Value: name}
+}
+
+func (l linter) appendToken(tokens []patch.Token, token patch.Token) []patch.Token {
+	result := make([]patch.Token, len(tokens), len(tokens)+1)
+	copy(result, tokens)
+	return append(result, token)
+}
+
+func (l linter) path(tokens []patch.Token) string {
+	return patch.NewPointer(tokens).String()
+}
@@ -0,0 +1,186 @@
+package lint_test
+
+import (
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/lint"
+	boshrel "github.com/cloudfoundry/bosh-cli/release"
+	boshjob "github.com/cloudfoundry/bosh-cli/release/job"
+	fakerel "github.com/cloudfoundry/bosh-cli/release/releasefakes"
+	. "github.com/cloudfoundry/bosh-cli/release/resource"
+)
+
+var _ = Describe("Linter", func() {
+	var (
+		release  *fakerel.FakeRelease
+		releases []boshrel.Release
+		linter   Linter
+	)
+
+	BeforeEach(func() {
+		job := boshjob.NewJob(NewResource("web", "fp", nil))
+		job.Properties = map[string]boshjob.PropertyDefinition{
+			"port":          {Default: biproperty.Property(80)},
+			"tls.cert":      {},
+			"tls.key":       {Default: biproperty.Property("")},
+			"env":           {Default: biproperty.Map{}},
+			"admin.user":    {Default: biproperty.Property("admin")},
+			"admin.enabled": {Default: biproperty.Property(false)},
+		}
+		job.Consumes = []boshjob.LinkDefinition{{Name: "db", Type: "database"}}
+		job.Provides = []boshjob.LinkDefinition{{Name: "web", Type: "http"}}
+
+		release = &fakerel.FakeRelease{}
+		release.NameReturns("app")
+		release.FindJobByNameStub = func(name string) (boshjob.Job, bool) {
+			if name == "web" {
+				return *job, true
+			}
+			return boshjob.Job{}, false
+		}
+
+		releases = []boshrel.Release{release}
+
+		linter = NewLinter()
+	})
+
+	It("returns no problems when manifest matches job specs", func() {
+		problems, err := linter.Lint([]byte(`
+instance_groups:
+- name: web
+  jobs:
+  - name: web
+    release: app
+    consumes:
+      db: {from: other-db}
+    provides:
+      web: {as: app-web}
+    properties:
+      port: 8080
+      tls: {cert: cert}
+      env: {ANY_KEY: value}
+`), releases)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(problems).To(BeEmpty())
+	})
+
+	It("reports unknown properties with manifest paths", func() {
+		problems, err := linter.Lint([]byte(`
+instance_groups:
+- name: web
+  jobs:
+  - name: web
+    release: app
+    properties:
+      prot: 8080
+      tls: {cert: cert, kye: key}
+      admin: true
+`), releases)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(problems).To(Equal([]Problem{
+			{
+				Path:    "/instance_groups/name=web/jobs/name=web/properties/prot",
+				Message: "Unknown property 'prot' for job 'web'",
+			},
+			{
+				Path:    "/instance_groups/name=web/jobs/name=web/properties/tls/kye",
+				Message: "Unknown property 'tls.kye' for job 'web'",
+			},
+		}))
+	})
+
+	It("reports missing properties without default values as warnings", func() {
+		problems, err := linter.Lint([]byte(`
+instance_groups:
+- name: web
+  jobs:
+  - name: web
+    release: app
+    properties:
+      tls: {cert: ~}
+`), releases)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(problems).To(Equal([]Problem{
+			{
+				Path:    "/instance_groups/name=web/jobs/name=web/properties",
+				Message: "Missing property 'tls.cert' without default value in job 'web'",
+				Warning: true,
+			},
+		}))
+	})
+
+	It("uses instance group and global properties when job does not specify properties", func() {
+		problems, err := linter.Lint([]byte(`
+properties:
+  tls: {key: key}
+  unrelated: value
+instance_groups:
+- name: web
+  properties:
+    tls: {cert: cert}
+  jobs:
+  - name: web
+    release: app
+`), releases)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(problems).To(BeEmpty())
+	})
+
+	It("reports unknown jobs and releases that were not provided", func() {
+		problems, err := linter.Lint([]byte(`
+instance_groups:
+- name: web
+  jobs:
+  - name: wbe
+    release: app
+  - name: web
+    release: other
+`), releases)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(problems).To(Equal([]Problem{
+			{
+				Path:    "/instance_groups/name=web/jobs/name=wbe",
+				Message: "Unknown job 'wbe' in release 'app'",
+			},
+			{
+				Path:    "/instance_groups/name=web/jobs/name=web/release",
+				Message: "Release 'other' of job 'web' was not provided",
+			},
+		}))
+	})
+
+	It("reports mismatched link names", func() {
+		problems, err := linter.Lint([]byte(`
+instance_groups:
+- name: web
+  jobs:
+  - name: web
+    release: app
+    consumes:
+      database: {from: db}
+    provides:
+      http: {as: web}
+    properties:
+      tls: {cert: cert}
+`), releases)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(problems).To(Equal([]Problem{
+			{
+				Path:    "/instance_groups/name=web/jobs/name=web/consumes/database",
+				Message: "Unknown link 'database' in 'consumes' of job 'web'",
+			},
+			{
+				Path:    "/instance_groups/name=web/jobs/name=web/provides/http",
+				Message: "Unknown link 'http' in 'provides' of job 'web'",
+			},
+		}))
+	})
+
+	It("returns an error when manifest cannot be parsed", func() {
+		_, err := linter.Lint([]byte(`-`), releases)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unmarshalling manifest"))
+	})
+})
@@ -0,0 +1,157 @@
+package proxy
+
+import (
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// AllProxyEnvVar configures proxy used for all Director, UAA, blobstore and agent connections.
+// Supported formats are 'socks5://host:port' and
+// 'ssh+socks5://user@jumpbox:port?private-key=/path/to/key'.
+// Jumpbox host key is verified against ~/.ssh/known_hosts unless
+// 'known-hosts=/path/to/known_hosts' or URL encoded 'host-key=ssh-rsa AAAA...' is given.
+const AllProxyEnvVar = "BOSH_ALL_PROXY"
+
+// Dialer dials connections directly or through a configured SOCKS5 proxy.
+// When proxy is reached over SSH, SSH connection is established on first dial.
+type Dialer struct {
+	direct DialFunc
+
+	socks5Addr string
+	sshProxy   *SSHSOCKS5Proxy
+
+	startOnce sync.Once
+	dialFunc  DialFunc
+	startErr  error
+}
+
+func NewDialer(proxyURL string, fs boshsys.FileSystem, logger boshlog.Logger) (*Dialer, error) {
+	direct := (&net.Dialer{Timeout: 30 * time.Second}).Dial
+
+	dialer := &Dialer{direct: direct}
+
+	if len(proxyURL) == 0 {
+		return dialer, nil
+	}
+
+	parsedURL, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Parsing proxy URL")
+	}
+
+	switch parsedURL.Scheme {
+	case "socks5":
+		if len(parsedURL.Hostname()) == 0 {
+			return nil, bosherr.Errorf("Expected proxy URL to include host")
+		}
+
+		dialer.socks5Addr = hostWithDefaultPort(parsedURL, "1080")
+
+	case "ssh+socks5":
+		if len(parsedURL.Hostname()) == 0 {
+			return nil, bosherr.Errorf("Expected proxy URL to include jumpbox host")
+		}
+
+		if parsedURL.User == nil || len(parsedURL.User.Username()) == 0 {
+			return nil, bosherr.Errorf("Expected proxy URL to include jumpbox username")
+		}
+
+		privKeyPath := parsedURL.Query().Get("private-key")
+		if len(privKeyPath) == 0 {
+			return nil, bosherr.Errorf("Expected proxy URL to include 'private-key' query parameter")
+		}
+
+		opts := SSHSOCKS5ProxyOpts{
+			Username:       parsedURL.User.Username(),
+			Host:           hostWithDefaultPort(parsedURL, "22"),
+			PrivateKeyPath: privKeyPath,
+
+			HostKey:        parsedURL.Query().Get("host-key"),
+			KnownHostsPath: parsedURL.Query().Get("known-hosts"),
+		}
+
+		dialer.sshProxy = NewSSHSOCKS5Proxy(opts, direct, fs, logger)
+
+	default:
+		return nil, bosherr.Errorf(
+			"Expected proxy URL scheme to be 'socks5' or 'ssh+socks5' but was '%s'", parsedURL.Scheme)
+	}
+
+	return dialer, nil
+}
+
+func (d *Dialer) Dial(network, addr string) (net.Conn, error) {
+	d.startOnce.Do(d.start)
+
+	if d.startErr != nil {
+		return nil, d.startErr
+	}
+
+	return d.dialFunc(network, addr)
+}
+
+// Stop closes SSH connection to the jumpbox if one was established
+func (d *Dialer) Stop() error {
+	if d.sshProxy != nil {
+		return d.sshProxy.Stop()
+	}
+
+	return nil
+}
+
+func (d *Dialer) start() {
+	switch {
+	case d.sshProxy != nil:
+		addr, err := d.sshProxy.Start()
+		if err != nil {
+			d.startErr = bosherr.WrapError(err, "Starting SOCKS5 proxy over SSH")
+			return
+		}
+
+		d.dialFunc = NewSOCKS5Dialer(addr, d.direct).Dial
+
+	case len(d.socks5Addr) > 0:
+		d.dialFunc = NewSOCKS5Dialer(d.socks5Addr, d.direct).Dial
+
+	default:
+		d.dialFunc = d.direct
+	}
+}
+
+func hostWithDefaultPort(parsedURL *url.URL, defaultPort string) string {
+	port := parsedURL.Port()
+	if len(port) == 0 {
+		port = defaultPort
+	}
+
+	return net.JoinHostPort(parsedURL.Hostname(), port)
+}
+
+var (
+	defaultDialer     *Dialer
+	defaultDialerErr  error
+	defaultDialerOnce sync.Once
+)
+
+// Dial connects through the proxy configured via BOSH_ALL_PROXY
+// or directly if it's not set. It can be used in place of net.Dial.
+func Dial(network, addr string) (net.Conn, error) {
+	defaultDialerOnce.Do(func() {
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		fs := boshsys.NewOsFileSystem(logger)
+		defaultDialer, defaultDialerErr = NewDialer(os.Getenv(AllProxyEnvVar), fs, logger)
+	})
+
+	if defaultDialerErr != nil {
+		return nil, bosherr.WrapErrorf(defaultDialerErr, "Configuring proxy from '%s'", AllProxyEnvVar)
+	}
+
+	return defaultDialer.Dial(network, addr)
+}
@@ -0,0 +1,177 @@
+package proxy_test
+
+import (
+	"net"
+	"net/url"
+	"path/filepath"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/proxy"
+)
+
+var _ = Describe("Dialer", func() {
+	var (
+		echoListener net.Listener
+		fs           boshsys.FileSystem
+		logger       boshlog.Logger
+	)
+
+	BeforeEach(func() {
+		echoListener = startEchoServer()
+
+		logger = boshlog.NewLogger(boshlog.LevelNone)
+		fs = boshsys.NewOsFileSystem(logger)
+	})
+
+	AfterEach(func() {
+		_ = echoListener.Close()
+	})
+
+	It("connects directly when proxy URL is empty", func() {
+		dialer, err := NewDialer("", fs, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		conn, err := dialer.Dial("tcp", echoListener.Addr().String())
+		Expect(err).ToNot(HaveOccurred())
+
+		defer conn.Close()
+
+		expectEcho(conn)
+	})
+
+	It("connects through SOCKS5 proxy", func() {
+		var dialedAddrs []string
+
+		server := NewSOCKS5Server(func(network, addr string) (net.Conn, error) {
+			dialedAddrs = append(dialedAddrs, addr)
+			return net.Dial(network, addr)
+		}, logger)
+
+		serverAddr, err := server.Start()
+		Expect(err).ToNot(HaveOccurred())
+
+		defer server.Stop()
+
+		dialer, err := NewDialer("socks5://"+serverAddr, fs, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		conn, err := dialer.Dial("tcp", echoListener.Addr().String())
+		Expect(err).ToNot(HaveOccurred())
+
+		defer conn.Close()
+
+		expectEcho(conn)
+		Expect(dialedAddrs).To(Equal([]string{echoListener.Addr().String()}))
+	})
+
+	Context("when proxy is reached over SSH", func() {
+		var (
+			jumpbox *testJumpbox
+			tmpDir  string
+			keyPath string
+		)
+
+		BeforeEach(func() {
+			privKey, signer := newTestKey()
+			jumpbox = newTestJumpbox(signer.PublicKey())
+
+			var err error
+
+			tmpDir, err = fs.TempDir("dialer-test")
+			Expect(err).ToNot(HaveOccurred())
+
+			keyPath = filepath.Join(tmpDir, "key")
+			Expect(fs.WriteFileString(keyPath, privKey)).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			jumpbox.Close()
+			_ = fs.RemoveAll(tmpDir)
+		})
+
+		It("connects to jumpbox on first dial and forwards connections through it", func() {
+			proxyURL := "ssh+socks5://jumpbox-user@" + jumpbox.Addr() + "?private-key=" + url.QueryEscape(keyPath) +
+				"&host-key=" + url.QueryEscape(jumpbox.AuthorizedHostKey())
+
+			dialer, err := NewDialer(proxyURL, fs, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			defer dialer.Stop()
+
+			Expect(jumpbox.Users()).To(BeEmpty())
+
+			for i := 0; i < 2; i++ {
+				conn, err := dialer.Dial("tcp", echoListener.Addr().String())
+				Expect(err).ToNot(HaveOccurred())
+
+				expectEcho(conn)
+				conn.Close()
+			}
+
+			Expect(jumpbox.Users()).To(Equal([]string{"jumpbox-user"}))
+			Expect(jumpbox.Forwardings()).To(HaveLen(2))
+		})
+
+		It("verifies jumpbox host key against given known hosts file", func() {
+			knownHostsPath := filepath.Join(tmpDir, "known_hosts")
+			Expect(fs.WriteFileString(knownHostsPath, "other-host "+jumpbox.AuthorizedHostKey()+"\n")).ToNot(HaveOccurred())
+
+			proxyURL := "ssh+socks5://jumpbox-user@" + jumpbox.Addr() + "?private-key=" + url.QueryEscape(keyPath) +
+				"&known-hosts=" + url.QueryEscape(knownHostsPath)
+
+			dialer, err := NewDialer(proxyURL, fs, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = dialer.Dial("tcp", echoListener.Addr().String())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("to be listed in known hosts '" + knownHostsPath + "'"))
+		})
+
+		It("returns error on dial if jumpbox connection fails", func() {
+			proxyURL := "ssh+socks5://jumpbox-user@" + jumpbox.Addr() + "?private-key=" + url.QueryEscape(keyPath+"-missing")
+
+			dialer, err := NewDialer(proxyURL, fs, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = dialer.Dial("tcp", echoListener.Addr().String())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Starting SOCKS5 proxy over SSH"))
+		})
+	})
+
+	Describe("invalid proxy URLs", func() {
+		expectErr := func(proxyURL, expectedErr string) {
+			_, err := NewDialer(proxyURL, fs, logger)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(expectedErr))
+		}
+
+		It("returns error for unknown scheme", func() {
+			expectErr("http://proxy:3128", "Expected proxy URL scheme to be 'socks5' or 'ssh+socks5' but was 'http'")
+		})
+
+		It("returns error if socks5 URL does not include host", func() {
+			expectErr("socks5://", "Expected proxy URL to include host")
+		})
+
+		It("returns error if ssh+socks5 URL does not include host", func() {
+			expectErr("ssh+socks5://user@?private-key=/key", "Expected proxy URL to include jumpbox host")
+		})
+
+		It("returns error if ssh+socks5 URL does not include username", func() {
+			expectErr("ssh+socks5://jumpbox?private-key=/key", "Expected proxy URL to include jumpbox username")
+		})
+
+		It("returns error if ssh+socks5 URL does not include private key", func() {
+			expectErr("ssh+socks5://user@jumpbox", "Expected proxy URL to include 'private-key' query parameter")
+		})
+
+		It("returns error if URL cannot be parsed", func() {
+			expectErr("socks5://[::1", "Parsing proxy URL")
+		})
+	})
+})
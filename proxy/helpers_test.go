@@ -0,0 +1,210 @@
+package proxy_test
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+)
+
+// startEchoServer starts TCP server that replies with 'echo: <line>' to each line
+func startEchoServer() net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					fmt.Fprintf(conn, "echo: %s\n", scanner.Text())
+				}
+			}()
+		}
+	}()
+
+	return listener
+}
+
+func expectEcho(conn net.Conn) {
+	_, err := fmt.Fprintf(conn, "hello\n")
+	Expect(err).ToNot(HaveOccurred())
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	Expect(err).ToNot(HaveOccurred())
+	Expect(line).To(Equal("echo: hello\n"))
+}
+
+func closedPortAddr() string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+
+	addr := listener.Addr().String()
+	Expect(listener.Close()).ToNot(HaveOccurred())
+
+	return addr
+}
+
+func newTestKey() (string, ssh.Signer) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).ToNot(HaveOccurred())
+
+	signer, err := ssh.NewSignerFromKey(key)
+	Expect(err).ToNot(HaveOccurred())
+
+	privKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	return string(privKey), signer
+}
+
+// testJumpbox is an in-process SSH server that only allows TCP forwarding
+type testJumpbox struct {
+	listener net.Listener
+	hostKey  ssh.PublicKey
+
+	lock        sync.Mutex
+	users       []string
+	forwardings []string
+}
+
+func newTestJumpbox(authorizedKey ssh.PublicKey) *testJumpbox {
+	jumpbox := &testJumpbox{}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if !bytes.Equal(key.Marshal(), authorizedKey.Marshal()) {
+				return nil, fmt.Errorf("unknown public key for %s", conn.User())
+			}
+			jumpbox.lock.Lock()
+			jumpbox.users = append(jumpbox.users, conn.User())
+			jumpbox.lock.Unlock()
+			return nil, nil
+		},
+	}
+
+	_, hostKey := newTestKey()
+	config.AddHostKey(hostKey)
+
+	jumpbox.hostKey = hostKey.PublicKey()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+
+	jumpbox.listener = listener
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go jumpbox.handleConn(conn, config)
+		}
+	}()
+
+	return jumpbox
+}
+
+func (j *testJumpbox) Addr() string { return j.listener.Addr().String() }
+
+func (j *testJumpbox) Close() { _ = j.listener.Close() }
+
+// AuthorizedHostKey returns host key in authorized_keys format
+func (j *testJumpbox) AuthorizedHostKey() string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(j.hostKey)))
+}
+
+func (j *testJumpbox) Users() []string {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return append([]string{}, j.users...)
+}
+
+func (j *testJumpbox) Forwardings() []string {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return append([]string{}, j.forwardings...)
+}
+
+func (j *testJumpbox) handleConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+
+	go ssh.DiscardRequests(reqs)
+
+	for newCh := range chans {
+		if newCh.ChannelType() != "direct-tcpip" {
+			_ = newCh.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+
+		go j.forward(newCh)
+	}
+}
+
+func (j *testJumpbox) forward(newCh ssh.NewChannel) {
+	var payload struct {
+		Host     string
+		Port     uint32
+		OrigHost string
+		OrigPort uint32
+	}
+
+	err := ssh.Unmarshal(newCh.ExtraData(), &payload)
+	if err != nil {
+		_ = newCh.Reject(ssh.Prohibited, err.Error())
+		return
+	}
+
+	addr := net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port)))
+
+	j.lock.Lock()
+	j.forwardings = append(j.forwardings, addr)
+	j.lock.Unlock()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		_ = newCh.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+
+	ch, reqs, err := newCh.Accept()
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+
+	go ssh.DiscardRequests(reqs)
+
+	go func() {
+		_, _ = io.Copy(ch, conn)
+		_ = ch.Close()
+	}()
+
+	go func() {
+		_, _ = io.Copy(conn, ch)
+		_ = conn.Close()
+	}()
+}
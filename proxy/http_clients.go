@@ -0,0 +1,35 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"time"
+)
+
+// CreateDefaultClient is similar to httpclient.CreateDefaultClient
+// but makes connections via Dial so that BOSH_ALL_PROXY is respected.
+func CreateDefaultClient(certPool *x509.CertPool) *http.Client {
+	return createDefaultClient(false, certPool)
+}
+
+func CreateDefaultClientInsecureSkipVerify() *http.Client {
+	return createDefaultClient(true, nil)
+}
+
+func createDefaultClient(insecureSkipVerify bool, certPool *x509.CertPool) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:            certPool,
+				InsecureSkipVerify: insecureSkipVerify,
+			},
+
+			Proxy: http.ProxyFromEnvironment,
+			Dial:  Dial,
+
+			TLSHandshakeTimeout: 30 * time.Second,
+			DisableKeepAlives:   true,
+		},
+	}
+}
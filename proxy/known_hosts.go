@@ -0,0 +1,96 @@
+package proxy
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// knownHostKeys returns keys listed for host (host:port) in OpenSSH known_hosts content.
+// Plain and hashed host names are supported; wildcard patterns and markers are not.
+func knownHostKeys(content []byte, hostPort string) ([]ssh.PublicKey, error) {
+	name, err := knownHostName(hostPort)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []ssh.PublicKey
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+
+		if len(line) == 0 || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "@") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 3 || !knownHostMatches(fields[0], name) {
+			continue
+		}
+
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.Join(fields[1:], " ")))
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Parsing known host key for '%s'", name)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// knownHostName formats host the same way as OpenSSH does in known_hosts
+func knownHostName(hostPort string) (string, error) {
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return "", bosherr.WrapErrorf(err, "Parsing jumpbox address '%s'", hostPort)
+	}
+
+	if port == "22" {
+		return host, nil
+	}
+
+	return "[" + host + "]:" + port, nil
+}
+
+func knownHostMatches(hosts, name string) bool {
+	for _, host := range strings.Split(hosts, ",") {
+		if strings.HasPrefix(host, "|1|") {
+			if knownHostHashMatches(host, name) {
+				return true
+			}
+		} else if host == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// knownHostHashMatches checks hashed host name in '|1|salt|hash' format
+func knownHostHashMatches(host, name string) bool {
+	pieces := strings.Split(host, "|")
+	if len(pieces) != 4 {
+		return false
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(pieces[2])
+	if err != nil {
+		return false
+	}
+
+	hash, err := base64.StdEncoding.DecodeString(pieces[3])
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha1.New, salt)
+	mac.Write([]byte(name))
+
+	return hmac.Equal(mac.Sum(nil), hash)
+}
@@ -0,0 +1,215 @@
+package proxy
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+const (
+	socks5Version = 5
+
+	socks5AuthNone         = 0
+	socks5AuthNoAcceptable = 0xff
+
+	socks5CmdConnect = 1
+
+	socks5AddrIPv4   = 1
+	socks5AddrDomain = 3
+	socks5AddrIPv6   = 4
+
+	socks5ReplySucceeded          = 0
+	socks5ReplyGeneralFailure     = 1
+	socks5ReplyHostUnreachable    = 4
+	socks5ReplyConnectionRefused  = 5
+	socks5ReplyCmdNotSupported    = 7
+	socks5ReplyAddrTypeNotSupport = 8
+)
+
+var socks5ReplyMessages = map[byte]string{
+	socks5ReplyGeneralFailure:     "general SOCKS server failure",
+	2:                             "connection not allowed by ruleset",
+	3:                             "network unreachable",
+	socks5ReplyHostUnreachable:    "host unreachable",
+	socks5ReplyConnectionRefused:  "connection refused",
+	6:                             "TTL expired",
+	socks5ReplyCmdNotSupported:    "command not supported",
+	socks5ReplyAddrTypeNotSupport: "address type not supported",
+}
+
+// DialFunc has the same signature as net.Dial
+type DialFunc func(network, addr string) (net.Conn, error)
+
+// SOCKS5Dialer establishes TCP connections through a SOCKS5 proxy
+// that does not require authentication.
+type SOCKS5Dialer struct {
+	proxyAddr string
+	forward   DialFunc
+}
+
+func NewSOCKS5Dialer(proxyAddr string, forward DialFunc) SOCKS5Dialer {
+	return SOCKS5Dialer{proxyAddr: proxyAddr, forward: forward}
+}
+
+func (d SOCKS5Dialer) Dial(network, addr string) (net.Conn, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, bosherr.Errorf("Expected network '%s' to be TCP based when dialing through SOCKS5 proxy", network)
+	}
+
+	conn, err := d.forward("tcp", d.proxyAddr)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Connecting to SOCKS5 proxy '%s'", d.proxyAddr)
+	}
+
+	err = d.connect(conn, addr)
+	if err != nil {
+		_ = conn.Close()
+		return nil, bosherr.WrapErrorf(err, "Connecting to '%s' through SOCKS5 proxy '%s'", addr, d.proxyAddr)
+	}
+
+	return conn, nil
+}
+
+func (d SOCKS5Dialer) connect(conn net.Conn, addr string) error {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Parsing address")
+	}
+
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Parsing port '%s'", portStr)
+	}
+
+	_, err = conn.Write([]byte{socks5Version, 1, socks5AuthNone})
+	if err != nil {
+		return bosherr.WrapError(err, "Writing greeting")
+	}
+
+	resp := make([]byte, 2)
+
+	_, err = io.ReadFull(conn, resp)
+	if err != nil {
+		return bosherr.WrapError(err, "Reading greeting")
+	}
+
+	if resp[0] != socks5Version {
+		return bosherr.Errorf("Unexpected SOCKS version '%d'", resp[0])
+	}
+
+	if resp[1] != socks5AuthNone {
+		return bosherr.Errorf("Proxy requires unsupported authentication method")
+	}
+
+	req := []byte{socks5Version, socks5CmdConnect, 0}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			req = append(req, socks5AddrIPv4)
+			req = append(req, ip4...)
+		} else {
+			req = append(req, socks5AddrIPv6)
+			req = append(req, ip.To16()...)
+		}
+	} else {
+		if len(host) > 255 {
+			return bosherr.Errorf("Host name '%s' is too long", host)
+		}
+		req = append(req, socks5AddrDomain, byte(len(host)))
+		req = append(req, host...)
+	}
+
+	req = append(req, 0, 0)
+	binary.BigEndian.PutUint16(req[len(req)-2:], uint16(port))
+
+	_, err = conn.Write(req)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing connect request")
+	}
+
+	// Reply has the same layout as the request
+	_, _, reply, err := readSOCKS5Request(conn)
+	if err != nil {
+		return bosherr.WrapError(err, "Reading connect reply")
+	}
+
+	if reply != socks5ReplySucceeded {
+		msg, found := socks5ReplyMessages[reply]
+		if !found {
+			msg = "unknown error"
+		}
+		return bosherr.Errorf("Proxy replied with '%s'", msg)
+	}
+
+	return nil
+}
+
+// readSOCKS5Request reads request or reply from the wire
+// and returns destination address, address type and command/reply code.
+func readSOCKS5Request(r io.Reader) (string, byte, byte, error) {
+	header := make([]byte, 4)
+
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	if header[0] != socks5Version {
+		return "", 0, 0, bosherr.Errorf("Unexpected SOCKS version '%d'", header[0])
+	}
+
+	var host string
+
+	switch header[3] {
+	case socks5AddrIPv4, socks5AddrIPv6:
+		size := net.IPv4len
+		if header[3] == socks5AddrIPv6 {
+			size = net.IPv6len
+		}
+
+		ip := make([]byte, size)
+
+		_, err = io.ReadFull(r, ip)
+		if err != nil {
+			return "", 0, 0, err
+		}
+
+		host = net.IP(ip).String()
+
+	case socks5AddrDomain:
+		size := make([]byte, 1)
+
+		_, err = io.ReadFull(r, size)
+		if err != nil {
+			return "", 0, 0, err
+		}
+
+		name := make([]byte, size[0])
+
+		_, err = io.ReadFull(r, name)
+		if err != nil {
+			return "", 0, 0, err
+		}
+
+		host = string(name)
+
+	default:
+		return "", header[3], header[1], bosherr.Errorf("Unsupported address type '%d'", header[3])
+	}
+
+	port := make([]byte, 2)
+
+	_, err = io.ReadFull(r, port)
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	addr := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+
+	return addr, header[3], header[1], nil
+}
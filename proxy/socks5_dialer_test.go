@@ -0,0 +1,88 @@
+package proxy_test
+
+import (
+	"errors"
+	"net"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/proxy"
+)
+
+var _ = Describe("SOCKS5Dialer", func() {
+	var (
+		echoListener net.Listener
+		server       *SOCKS5Server
+		serverAddr   string
+	)
+
+	BeforeEach(func() {
+		echoListener = startEchoServer()
+
+		server = NewSOCKS5Server(net.Dial, boshlog.NewLogger(boshlog.LevelNone))
+
+		var err error
+
+		serverAddr, err = server.Start()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(server.Stop()).ToNot(HaveOccurred())
+		_ = echoListener.Close()
+	})
+
+	It("connects to IPv4 address through the proxy", func() {
+		conn, err := NewSOCKS5Dialer(serverAddr, net.Dial).Dial("tcp", echoListener.Addr().String())
+		Expect(err).ToNot(HaveOccurred())
+
+		defer conn.Close()
+
+		expectEcho(conn)
+	})
+
+	It("connects to proxy with forward dial function", func() {
+		var forwardAddrs []string
+
+		forward := func(network, addr string) (net.Conn, error) {
+			forwardAddrs = append(forwardAddrs, addr)
+			return net.Dial(network, addr)
+		}
+
+		conn, err := NewSOCKS5Dialer(serverAddr, forward).Dial("tcp", echoListener.Addr().String())
+		Expect(err).ToNot(HaveOccurred())
+
+		defer conn.Close()
+
+		Expect(forwardAddrs).To(Equal([]string{serverAddr}))
+	})
+
+	It("returns error if proxy cannot be reached", func() {
+		forward := func(string, string) (net.Conn, error) { return nil, errors.New("fake-err") }
+
+		_, err := NewSOCKS5Dialer(serverAddr, forward).Dial("tcp", echoListener.Addr().String())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Connecting to SOCKS5 proxy"))
+		Expect(err.Error()).To(ContainSubstring("fake-err"))
+	})
+
+	It("returns error if destination refuses connection", func() {
+		_, err := NewSOCKS5Dialer(serverAddr, net.Dial).Dial("tcp", closedPortAddr())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("connection refused"))
+	})
+
+	It("returns error for non-TCP networks", func() {
+		_, err := NewSOCKS5Dialer(serverAddr, net.Dial).Dial("udp", "127.0.0.1:53")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Expected network 'udp' to be TCP based"))
+	})
+
+	It("returns error if address cannot be parsed", func() {
+		_, err := NewSOCKS5Dialer(serverAddr, net.Dial).Dial("tcp", "no-port")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Parsing address"))
+	})
+})
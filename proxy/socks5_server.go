@@ -0,0 +1,187 @@
+package proxy
+
+import (
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+// SOCKS5Server is a minimal SOCKS5 server listening on loopback interface.
+// It only supports CONNECT command without authentication and
+// establishes outgoing connections with provided dial function.
+type SOCKS5Server struct {
+	dialFunc DialFunc
+
+	listener     net.Listener
+	listenerLock sync.Mutex
+
+	logTag string
+	logger boshlog.Logger
+}
+
+func NewSOCKS5Server(dialFunc DialFunc, logger boshlog.Logger) *SOCKS5Server {
+	return &SOCKS5Server{
+		dialFunc: dialFunc,
+
+		logTag: "SOCKS5Server",
+		logger: logger,
+	}
+}
+
+// Start listens on a random loopback port and returns its address
+func (s *SOCKS5Server) Start() (string, error) {
+	s.listenerLock.Lock()
+	defer s.listenerLock.Unlock()
+
+	if s.listener != nil {
+		return s.listener.Addr().String(), nil
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", bosherr.WrapError(err, "Listening for SOCKS5 connections")
+	}
+
+	s.listener = listener
+
+	s.logger.Debug(s.logTag, "Listening on '%s'", listener.Addr())
+
+	go s.accept(listener)
+
+	return listener.Addr().String(), nil
+}
+
+func (s *SOCKS5Server) Stop() error {
+	s.listenerLock.Lock()
+	defer s.listenerLock.Unlock()
+
+	if s.listener == nil {
+		return nil
+	}
+
+	err := s.listener.Close()
+	s.listener = nil
+
+	return err
+}
+
+func (s *SOCKS5Server) accept(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.logger.Debug(s.logTag, "Stopped accepting connections: %s", err)
+			return
+		}
+
+		go s.serve(conn)
+	}
+}
+
+func (s *SOCKS5Server) serve(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	err := s.negotiate(conn)
+	if err != nil {
+		s.logger.Debug(s.logTag, "Failed negotiating: %s", err)
+		return
+	}
+
+	addr, addrType, cmd, err := readSOCKS5Request(conn)
+	if err != nil {
+		s.logger.Debug(s.logTag, "Failed reading request: %s", err)
+		if addrType != 0 {
+			s.reply(conn, socks5ReplyAddrTypeNotSupport)
+		}
+		return
+	}
+
+	if cmd != socks5CmdConnect {
+		s.reply(conn, socks5ReplyCmdNotSupported)
+		return
+	}
+
+	s.logger.Debug(s.logTag, "Connecting to '%s'", addr)
+
+	remoteConn, err := s.dialFunc("tcp", addr)
+	if err != nil {
+		s.logger.Debug(s.logTag, "Failed connecting to '%s': %s", addr, err)
+
+		if strings.Contains(strings.ToLower(err.Error()), "connection refused") {
+			s.reply(conn, socks5ReplyConnectionRefused)
+		} else {
+			s.reply(conn, socks5ReplyHostUnreachable)
+		}
+		return
+	}
+
+	defer func() {
+		_ = remoteConn.Close()
+	}()
+
+	if !s.reply(conn, socks5ReplySucceeded) {
+		return
+	}
+
+	doneCh := make(chan struct{}, 2)
+
+	go func() {
+		_, _ = io.Copy(remoteConn, conn)
+		doneCh <- struct{}{}
+	}()
+
+	go func() {
+		_, _ = io.Copy(conn, remoteConn)
+		doneCh <- struct{}{}
+	}()
+
+	// Either side closing terminates both connections
+	<-doneCh
+}
+
+func (s *SOCKS5Server) negotiate(conn net.Conn) error {
+	header := make([]byte, 2)
+
+	_, err := io.ReadFull(conn, header)
+	if err != nil {
+		return err
+	}
+
+	if header[0] != socks5Version {
+		return bosherr.Errorf("Unexpected SOCKS version '%d'", header[0])
+	}
+
+	methods := make([]byte, header[1])
+
+	_, err = io.ReadFull(conn, methods)
+	if err != nil {
+		return err
+	}
+
+	for _, method := range methods {
+		if method == socks5AuthNone {
+			_, err = conn.Write([]byte{socks5Version, socks5AuthNone})
+			return err
+		}
+	}
+
+	_, _ = conn.Write([]byte{socks5Version, socks5AuthNoAcceptable})
+
+	return bosherr.Errorf("Client does not support connecting without authentication")
+}
+
+func (s *SOCKS5Server) reply(conn net.Conn, code byte) bool {
+	// Bound address is not meaningful for clients, hence zeros
+	_, err := conn.Write([]byte{socks5Version, code, 0, socks5AddrIPv4, 0, 0, 0, 0, 0, 0})
+	if err != nil {
+		s.logger.Debug(s.logTag, "Failed writing reply: %s", err)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,150 @@
+package proxy_test
+
+import (
+	"errors"
+	"io"
+	"net"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/proxy"
+)
+
+var _ = Describe("SOCKS5Server", func() {
+	var (
+		echoListener net.Listener
+		dialedAddrs  []string
+		dialErr      error
+		server       *SOCKS5Server
+		serverAddr   string
+	)
+
+	BeforeEach(func() {
+		echoListener = startEchoServer()
+
+		dialedAddrs = nil
+		dialErr = nil
+
+		dialFunc := func(network, addr string) (net.Conn, error) {
+			dialedAddrs = append(dialedAddrs, addr)
+			if dialErr != nil {
+				return nil, dialErr
+			}
+			return net.Dial(network, addr)
+		}
+
+		server = NewSOCKS5Server(dialFunc, boshlog.NewLogger(boshlog.LevelNone))
+
+		var err error
+
+		serverAddr, err = server.Start()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(server.Stop()).ToNot(HaveOccurred())
+		_ = echoListener.Close()
+	})
+
+	rawRequest := func(greeting, request []byte) ([]byte, []byte) {
+		conn, err := net.Dial("tcp", serverAddr)
+		Expect(err).ToNot(HaveOccurred())
+
+		defer conn.Close()
+
+		_, err = conn.Write(greeting)
+		Expect(err).ToNot(HaveOccurred())
+
+		greetingReply := make([]byte, 2)
+		_, err = io.ReadFull(conn, greetingReply)
+		Expect(err).ToNot(HaveOccurred())
+
+		if request == nil {
+			return greetingReply, nil
+		}
+
+		_, err = conn.Write(request)
+		Expect(err).ToNot(HaveOccurred())
+
+		reply := make([]byte, 10)
+		_, err = io.ReadFull(conn, reply)
+		Expect(err).ToNot(HaveOccurred())
+
+		return greetingReply, reply
+	}
+
+	It("listens on loopback interface", func() {
+		host, _, err := net.SplitHostPort(serverAddr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(host).To(Equal("127.0.0.1"))
+	})
+
+	It("returns the same address when started again", func() {
+		addr, err := server.Start()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(addr).To(Equal(serverAddr))
+	})
+
+	It("connects to requested address using dial function", func() {
+		conn, err := NewSOCKS5Dialer(serverAddr, net.Dial).Dial("tcp", echoListener.Addr().String())
+		Expect(err).ToNot(HaveOccurred())
+
+		defer conn.Close()
+
+		expectEcho(conn)
+		Expect(dialedAddrs).To(Equal([]string{echoListener.Addr().String()}))
+	})
+
+	It("resolves domain names with dial function", func() {
+		_, port, err := net.SplitHostPort(echoListener.Addr().String())
+		Expect(err).ToNot(HaveOccurred())
+
+		conn, err := NewSOCKS5Dialer(serverAddr, net.Dial).Dial("tcp", net.JoinHostPort("localhost", port))
+		Expect(err).ToNot(HaveOccurred())
+
+		defer conn.Close()
+
+		expectEcho(conn)
+		Expect(dialedAddrs).To(Equal([]string{net.JoinHostPort("localhost", port)}))
+	})
+
+	It("replies with connection refused if dialing is refused", func() {
+		dialErr = errors.New("dial tcp: connection refused")
+
+		_, err := NewSOCKS5Dialer(serverAddr, net.Dial).Dial("tcp", "10.0.0.1:25555")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Proxy replied with 'connection refused'"))
+	})
+
+	It("replies with host unreachable if dialing fails", func() {
+		dialErr = errors.New("fake-err")
+
+		_, err := NewSOCKS5Dialer(serverAddr, net.Dial).Dial("tcp", "10.0.0.1:25555")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Proxy replied with 'host unreachable'"))
+	})
+
+	It("rejects clients that require authentication", func() {
+		greetingReply, _ := rawRequest([]byte{5, 1, 2}, nil)
+		Expect(greetingReply).To(Equal([]byte{5, 0xff}))
+	})
+
+	It("rejects commands other than connect", func() {
+		bindReq := []byte{5, 2, 0, 1, 127, 0, 0, 1, 0, 80}
+
+		greetingReply, reply := rawRequest([]byte{5, 1, 0}, bindReq)
+		Expect(greetingReply).To(Equal([]byte{5, 0}))
+		Expect(reply[1]).To(Equal(byte(7)))
+
+		Expect(dialedAddrs).To(BeEmpty())
+	})
+
+	It("rejects unsupported address types", func() {
+		req := []byte{5, 1, 0, 9, 0, 0, 0, 0, 0, 0}
+
+		_, reply := rawRequest([]byte{5, 1, 0}, req)
+		Expect(reply[1]).To(Equal(byte(8)))
+	})
+})
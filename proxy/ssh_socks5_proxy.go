@@ -0,0 +1,204 @@
+package proxy
+
+import (
+	"bytes"
+	"net"
+	"sync"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	"golang.org/x/crypto/ssh"
+)
+
+// DefaultKnownHostsPath is used to verify jumpbox host key
+// when neither host key nor known hosts path is given
+const DefaultKnownHostsPath = "~/.ssh/known_hosts"
+
+type SSHSOCKS5ProxyOpts struct {
+	Username       string
+	Host           string // host:port
+	PrivateKeyPath string
+
+	HostKey        string // in authorized_keys format (e.g. 'ssh-rsa AAAA...')
+	KnownHostsPath string
+}
+
+// SSHSOCKS5Proxy runs local SOCKS5 server that forwards
+// all connections over an SSH connection to a jumpbox.
+type SSHSOCKS5Proxy struct {
+	opts SSHSOCKS5ProxyOpts
+
+	dialFunc    DialFunc
+	dialTimeout time.Duration
+
+	client *ssh.Client
+	server *SOCKS5Server
+	addr   string
+	lock   sync.Mutex
+
+	fs     boshsys.FileSystem
+	logTag string
+	logger boshlog.Logger
+}
+
+func NewSSHSOCKS5Proxy(opts SSHSOCKS5ProxyOpts, dialFunc DialFunc, fs boshsys.FileSystem, logger boshlog.Logger) *SSHSOCKS5Proxy {
+	return &SSHSOCKS5Proxy{
+		opts: opts,
+
+		dialFunc:    dialFunc,
+		dialTimeout: 30 * time.Second,
+
+		fs:     fs,
+		logTag: "SSHSOCKS5Proxy",
+		logger: logger,
+	}
+}
+
+// Start connects to the jumpbox and starts local SOCKS5 server.
+// Returns address of the SOCKS5 server. Subsequent calls return the same address.
+func (p *SSHSOCKS5Proxy) Start() (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.server != nil {
+		return p.addr, nil
+	}
+
+	client, err := p.dial()
+	if err != nil {
+		return "", err
+	}
+
+	server := NewSOCKS5Server(client.Dial, p.logger)
+
+	addr, err := server.Start()
+	if err != nil {
+		_ = client.Close()
+		return "", err
+	}
+
+	p.client = client
+	p.server = server
+	p.addr = addr
+
+	return addr, nil
+}
+
+func (p *SSHSOCKS5Proxy) Stop() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.server == nil {
+		return nil
+	}
+
+	serverErr := p.server.Stop()
+	clientErr := p.client.Close()
+
+	p.server = nil
+	p.client = nil
+
+	if serverErr != nil {
+		return serverErr
+	}
+
+	return clientErr
+}
+
+func (p *SSHSOCKS5Proxy) dial() (*ssh.Client, error) {
+	expandedPath, err := p.fs.ExpandPath(p.opts.PrivateKeyPath)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Expanding private key path '%s'", p.opts.PrivateKeyPath)
+	}
+
+	privKey, err := p.fs.ReadFile(expandedPath)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Reading private key '%s'", expandedPath)
+	}
+
+	signer, err := ssh.ParsePrivateKey(privKey)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Parsing private key '%s'", expandedPath)
+	}
+
+	hostKeys, err := p.hostKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &ssh.ClientConfig{
+		User: p.opts.Username,
+		Auth: []ssh.AuthMethod{ssh.PublicKeys(signer)},
+
+		HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			for _, hostKey := range hostKeys {
+				if bytes.Equal(hostKey.Marshal(), key.Marshal()) {
+					return nil
+				}
+			}
+			return bosherr.Errorf("Expected jumpbox '%s' host key to match known host key", p.opts.Host)
+		},
+	}
+
+	p.logger.Debug(p.logTag, "Connecting to jumpbox '%s@%s'", p.opts.Username, p.opts.Host)
+
+	conn, err := p.dialFunc("tcp", p.opts.Host)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Connecting to jumpbox '%s'", p.opts.Host)
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(p.dialTimeout))
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, p.opts.Host, config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, bosherr.WrapErrorf(err, "Establishing SSH connection to jumpbox '%s'", p.opts.Host)
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+// hostKeys returns keys that jumpbox is allowed to present:
+// either explicitly given one or ones listed in known hosts file
+func (p *SSHSOCKS5Proxy) hostKeys() ([]ssh.PublicKey, error) {
+	if len(p.opts.HostKey) > 0 {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(p.opts.HostKey))
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Parsing jumpbox host key")
+		}
+
+		return []ssh.PublicKey{key}, nil
+	}
+
+	knownHostsPath := p.opts.KnownHostsPath
+
+	if len(knownHostsPath) == 0 {
+		knownHostsPath = DefaultKnownHostsPath
+	}
+
+	expandedPath, err := p.fs.ExpandPath(knownHostsPath)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Expanding known hosts path '%s'", knownHostsPath)
+	}
+
+	content, err := p.fs.ReadFile(expandedPath)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Reading known hosts '%s'", expandedPath)
+	}
+
+	keys, err := knownHostKeys(content, p.opts.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, bosherr.Errorf(
+			"Expected jumpbox '%s' to be listed in known hosts '%s'", p.opts.Host, expandedPath)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,231 @@
+package proxy_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net"
+	"path/filepath"
+	"strings"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+
+	. "github.com/cloudfoundry/bosh-cli/proxy"
+)
+
+var _ = Describe("SSHSOCKS5Proxy", func() {
+	var (
+		echoListener net.Listener
+		jumpbox      *testJumpbox
+		fs           boshsys.FileSystem
+		tmpDir       string
+		opts         SSHSOCKS5ProxyOpts
+		logger       boshlog.Logger
+	)
+
+	BeforeEach(func() {
+		echoListener = startEchoServer()
+
+		privKey, signer := newTestKey()
+		jumpbox = newTestJumpbox(signer.PublicKey())
+
+		logger = boshlog.NewLogger(boshlog.LevelNone)
+		fs = boshsys.NewOsFileSystem(logger)
+
+		var err error
+
+		tmpDir, err = fs.TempDir("ssh-socks5-proxy-test")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fs.WriteFileString(filepath.Join(tmpDir, "key"), privKey)).ToNot(HaveOccurred())
+
+		opts = SSHSOCKS5ProxyOpts{
+			Username:       "jumpbox-user",
+			Host:           jumpbox.Addr(),
+			PrivateKeyPath: filepath.Join(tmpDir, "key"),
+			HostKey:        jumpbox.AuthorizedHostKey(),
+		}
+	})
+
+	AfterEach(func() {
+		jumpbox.Close()
+		_ = echoListener.Close()
+		_ = fs.RemoveAll(tmpDir)
+	})
+
+	It("forwards SOCKS5 connections over SSH connection to jumpbox", func() {
+		proxy := NewSSHSOCKS5Proxy(opts, net.Dial, fs, logger)
+
+		addr, err := proxy.Start()
+		Expect(err).ToNot(HaveOccurred())
+
+		defer proxy.Stop()
+
+		conn, err := NewSOCKS5Dialer(addr, net.Dial).Dial("tcp", echoListener.Addr().String())
+		Expect(err).ToNot(HaveOccurred())
+
+		defer conn.Close()
+
+		expectEcho(conn)
+
+		Expect(jumpbox.Users()).To(Equal([]string{"jumpbox-user"}))
+		Expect(jumpbox.Forwardings()).To(Equal([]string{echoListener.Addr().String()}))
+	})
+
+	It("reuses SSH connection when started multiple times", func() {
+		proxy := NewSSHSOCKS5Proxy(opts, net.Dial, fs, logger)
+
+		addr1, err := proxy.Start()
+		Expect(err).ToNot(HaveOccurred())
+
+		defer proxy.Stop()
+
+		addr2, err := proxy.Start()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(addr2).To(Equal(addr1))
+		Expect(jumpbox.Users()).To(HaveLen(1))
+	})
+
+	It("stops accepting connections after being stopped", func() {
+		proxy := NewSSHSOCKS5Proxy(opts, net.Dial, fs, logger)
+
+		addr, err := proxy.Start()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(proxy.Stop()).ToNot(HaveOccurred())
+		Expect(proxy.Stop()).ToNot(HaveOccurred())
+
+		_, err = NewSOCKS5Dialer(addr, net.Dial).Dial("tcp", echoListener.Addr().String())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns error if private key cannot be read", func() {
+		opts.PrivateKeyPath = filepath.Join(tmpDir, "missing")
+
+		_, err := NewSSHSOCKS5Proxy(opts, net.Dial, fs, logger).Start()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Reading private key"))
+	})
+
+	It("returns error if private key is not valid", func() {
+		Expect(fs.WriteFileString(opts.PrivateKeyPath, "invalid")).ToNot(HaveOccurred())
+
+		_, err := NewSSHSOCKS5Proxy(opts, net.Dial, fs, logger).Start()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Parsing private key"))
+	})
+
+	It("returns error if jumpbox rejects private key", func() {
+		otherPrivKey, _ := newTestKey()
+		Expect(fs.WriteFileString(opts.PrivateKeyPath, otherPrivKey)).ToNot(HaveOccurred())
+
+		_, err := NewSSHSOCKS5Proxy(opts, net.Dial, fs, logger).Start()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Establishing SSH connection to jumpbox"))
+	})
+
+	It("returns error if jumpbox host key does not match given host key", func() {
+		_, otherHostKey := newTestKey()
+		opts.HostKey = string(ssh.MarshalAuthorizedKey(otherHostKey.PublicKey()))
+
+		_, err := NewSSHSOCKS5Proxy(opts, net.Dial, fs, logger).Start()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Expected jumpbox '" + jumpbox.Addr() + "' host key to match known host key"))
+		Expect(jumpbox.Users()).To(BeEmpty())
+	})
+
+	It("returns error if given host key is not valid", func() {
+		opts.HostKey = "invalid"
+
+		_, err := NewSSHSOCKS5Proxy(opts, net.Dial, fs, logger).Start()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Parsing jumpbox host key"))
+	})
+
+	Context("when host key is not given", func() {
+		var (
+			knownHostName string
+		)
+
+		BeforeEach(func() {
+			host, port, err := net.SplitHostPort(jumpbox.Addr())
+			Expect(err).ToNot(HaveOccurred())
+
+			knownHostName = "[" + host + "]:" + port
+
+			opts.HostKey = ""
+			opts.KnownHostsPath = filepath.Join(tmpDir, "known_hosts")
+		})
+
+		It("verifies host key against known hosts file", func() {
+			Expect(fs.WriteFileString(opts.KnownHostsPath,
+				"# comment\nother-host "+jumpbox.AuthorizedHostKey()+"\n"+knownHostName+" "+jumpbox.AuthorizedHostKey()+"\n")).ToNot(HaveOccurred())
+
+			proxy := NewSSHSOCKS5Proxy(opts, net.Dial, fs, logger)
+
+			_, err := proxy.Start()
+			Expect(err).ToNot(HaveOccurred())
+
+			defer proxy.Stop()
+
+			Expect(jumpbox.Users()).To(Equal([]string{"jumpbox-user"}))
+		})
+
+		It("matches hashed host names", func() {
+			salt := []byte("0123456789abcdefghij")
+
+			mac := hmac.New(sha1.New, salt)
+			mac.Write([]byte(knownHostName))
+
+			hashedName := "|1|" + base64.StdEncoding.EncodeToString(salt) + "|" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+			Expect(fs.WriteFileString(opts.KnownHostsPath, hashedName+" "+jumpbox.AuthorizedHostKey()+"\n")).ToNot(HaveOccurred())
+
+			proxy := NewSSHSOCKS5Proxy(opts, net.Dial, fs, logger)
+
+			_, err := proxy.Start()
+			Expect(err).ToNot(HaveOccurred())
+
+			defer proxy.Stop()
+		})
+
+		It("returns error if jumpbox host key does not match known host key", func() {
+			_, otherHostKey := newTestKey()
+			otherKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(otherHostKey.PublicKey())))
+
+			Expect(fs.WriteFileString(opts.KnownHostsPath, knownHostName+" "+otherKey+"\n")).ToNot(HaveOccurred())
+
+			_, err := NewSSHSOCKS5Proxy(opts, net.Dial, fs, logger).Start()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("host key to match known host key"))
+		})
+
+		It("returns error if jumpbox is not listed in known hosts file", func() {
+			Expect(fs.WriteFileString(opts.KnownHostsPath, "other-host "+jumpbox.AuthorizedHostKey()+"\n")).ToNot(HaveOccurred())
+
+			_, err := NewSSHSOCKS5Proxy(opts, net.Dial, fs, logger).Start()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected jumpbox '" + jumpbox.Addr() + "' to be listed in known hosts"))
+			Expect(jumpbox.Users()).To(BeEmpty())
+		})
+
+		It("returns error if known hosts file cannot be read", func() {
+			_, err := NewSSHSOCKS5Proxy(opts, net.Dial, fs, logger).Start()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Reading known hosts"))
+		})
+	})
+
+	It("returns error if jumpbox cannot be reached", func() {
+		opts.Host = closedPortAddr()
+
+		_, err := NewSSHSOCKS5Proxy(opts, net.Dial, fs, logger).Start()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Connecting to jumpbox"))
+	})
+})
@@ -32,9 +32,14 @@ func (r DirReaderImpl) Read(path string) (*Job, error) {
 		return nil, err
 	}
 
-	job := NewJob(NewResource(manifest.Name, fp, archive))
-	job.PackageNames = manifest.Packages
-	// Does not read all manifest values...
+	// Job's directory is used in place of extracted job (e.g. to render templates)
+	// without giving it a file system so that it's never removed by CleanUp
+	job := NewExtractedJob(NewResource(manifest.Name, fp, archive), path, nil)
+
+	err = job.setManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
 
 	return job, nil
 }
@@ -3,6 +3,7 @@ package job_test
 import (
 	"errors"
 
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -44,6 +45,10 @@ properties:
   prop:
     description: prop-desc
     default: prop-default
+consumes:
+- {name: db, type: database, optional: true}
+provides:
+- {name: conn, type: http}
 `)
 
 			fs.WriteFileString("/dir/monit", "monit-content")
@@ -51,8 +56,17 @@ properties:
 
 			archive.FingerprintReturns("fp", nil)
 
-			expectedJob := NewJob(NewResource("name", "fp", archive))
-			expectedJob.PackageNames = []string{"pkg"} // only expect pkg names
+			expectedJob := NewExtractedJob(NewResource("name", "fp", archive), "/dir", nil)
+			expectedJob.Templates = map[string]string{"src": "dst"}
+			expectedJob.PackageNames = []string{"pkg"}
+			expectedJob.Properties = map[string]PropertyDefinition{
+				"prop": PropertyDefinition{
+					Description: "prop-desc",
+					Default:     biproperty.Property("prop-default"),
+				},
+			}
+			expectedJob.Consumes = []LinkDefinition{{Name: "db", Type: "database", Optional: true}}
+			expectedJob.Provides = []LinkDefinition{{Name: "conn", Type: "http"}}
 
 			job, err := reader.Read("/dir")
 			Expect(err).NotTo(HaveOccurred())
@@ -73,9 +87,12 @@ properties:
 
 			archive.FingerprintReturns("fp", nil)
 
+			expectedJob := NewExtractedJob(NewResource("name", "fp", archive), "/dir", nil)
+			expectedJob.Properties = map[string]PropertyDefinition{}
+
 			job, err := reader.Read("/dir")
 			Expect(err).NotTo(HaveOccurred())
-			Expect(job).To(Equal(NewJob(NewResource("name", "fp", archive))))
+			Expect(job).To(Equal(expectedJob))
 
 			Expect(collectedFiles).To(Equal([]File{
 				File{Path: "/dir/spec", DirPath: "/dir", RelativePath: "job.MF"},
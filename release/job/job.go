@@ -5,6 +5,7 @@ import (
 	biproperty "github.com/cloudfoundry/bosh-utils/property"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
 
+	boshjobman "github.com/cloudfoundry/bosh-cli/release/job/manifest"
 	boshpkg "github.com/cloudfoundry/bosh-cli/release/pkg"
 	. "github.com/cloudfoundry/bosh-cli/release/resource"
 )
@@ -23,6 +24,9 @@ type Job struct {
 	Packages     []boshpkg.Compilable
 	Properties   map[string]PropertyDefinition
 
+	Consumes []LinkDefinition
+	Provides []LinkDefinition
+
 	extractedPath string
 	fs            boshsys.FileSystem
 }
@@ -32,6 +36,12 @@ type PropertyDefinition struct {
 	Default     biproperty.Property
 }
 
+type LinkDefinition struct {
+	Name     string
+	Type     string
+	Optional bool
+}
+
 func NewJob(resource Resource) *Job {
 	return &Job{resource: resource}
 }
@@ -90,6 +100,47 @@ func (j *Job) AttachCompilablePackages(packages []boshpkg.Compilable) error {
 	return nil
 }
 
+// setManifest records values of job's spec that are not captured by its resource
+func (j *Job) setManifest(manifest boshjobman.Manifest) error {
+	j.Templates = manifest.Templates
+	j.PackageNames = manifest.Packages
+
+	properties := make(map[string]PropertyDefinition, len(manifest.Properties))
+
+	for propertyName, rawPropertyDef := range manifest.Properties {
+		defaultValue, err := biproperty.Build(rawPropertyDef.Default)
+		if err != nil {
+			errMsg := "Parsing job '%s' property '%s' default: %#v"
+			return bosherr.WrapErrorf(err, errMsg, j.Name(), propertyName, rawPropertyDef.Default)
+		}
+
+		properties[propertyName] = PropertyDefinition{
+			Description: rawPropertyDef.Description,
+			Default:     defaultValue,
+		}
+	}
+
+	j.Properties = properties
+	j.Consumes = newLinkDefinitions(manifest.Consumes)
+	j.Provides = newLinkDefinitions(manifest.Provides)
+
+	return nil
+}
+
+func newLinkDefinitions(rawLinkDefs []boshjobman.LinkDefinition) []LinkDefinition {
+	var linkDefs []LinkDefinition
+
+	for _, rawLinkDef := range rawLinkDefs {
+		linkDefs = append(linkDefs, LinkDefinition{
+			Name:     rawLinkDef.Name,
+			Type:     rawLinkDef.Type,
+			Optional: rawLinkDef.Optional,
+		})
+	}
+
+	return linkDefs
+}
+
 func (j Job) ExtractedPath() string { return j.extractedPath }
 
 func (j Job) CleanUp() error {
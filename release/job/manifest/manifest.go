@@ -11,6 +11,9 @@ type Manifest struct {
 	Templates  map[string]string             `yaml:"templates"`
 	Packages   []string                      `yaml:"packages"`
 	Properties map[string]PropertyDefinition `yaml:"properties"`
+
+	Consumes []LinkDefinition `yaml:"consumes"`
+	Provides []LinkDefinition `yaml:"provides"`
 }
 
 type PropertyDefinition struct {
@@ -18,6 +21,12 @@ type PropertyDefinition struct {
 	Default     interface{} `yaml:"default"`
 }
 
+type LinkDefinition struct {
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"`
+	Optional bool   `yaml:"optional"`
+}
+
 func NewManifestFromPath(path string, fs boshsys.FileSystem) (Manifest, error) {
 	var manifest Manifest
 
@@ -37,6 +37,15 @@ properties:
   prop1.prop2:
     description: prop2-desc
     default: prop2-default
+
+consumes:
+- name: db
+  type: database
+  optional: true
+
+provides:
+- name: conn
+  type: http
 `
 
 		fs.WriteFileString("/path", contents)
@@ -60,6 +69,14 @@ properties:
 					Default:     "prop2-default",
 				},
 			},
+
+			Consumes: []LinkDefinition{
+				{Name: "db", Type: "database", Optional: true},
+			},
+
+			Provides: []LinkDefinition{
+				{Name: "conn", Type: "http"},
+			},
 		}))
 	})
 
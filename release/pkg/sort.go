@@ -12,10 +12,14 @@ func Sort(releasePackages []Compilable) ([]Compilable, error) {
 	incomingEdges, outgoingEdges := getEdgeMaps(releasePackages)
 	noIncomingEdgesSet := []Compilable{}
 
-	for pkg, edgeList := range incomingEdges {
-		if len(edgeList) == 0 {
+	// Iterate over given packages instead of the edge map to keep the order stable
+	seen := map[Compilable]bool{}
+
+	for _, pkg := range releasePackages {
+		if !seen[pkg] && len(incomingEdges[pkg]) == 0 {
 			noIncomingEdgesSet = append(noIncomingEdgesSet, pkg)
 		}
+		seen[pkg] = true
 	}
 	for len(noIncomingEdgesSet) > 0 {
 		elem := noIncomingEdgesSet[0]
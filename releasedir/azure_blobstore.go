@@ -0,0 +1,246 @@
+package releasedir
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
+)
+
+const azureStorageAPIVersion = "2019-12-12"
+
+/*
+blobstore:
+  provider: azure-storage
+  options:
+    account_name: cfreleaseblobs
+    container_name: cf-release-blobs
+    account_key: ... # omit for read-only access to public containers
+*/
+
+type AzureBlobstore struct {
+	fs         boshsys.FileSystem
+	uuidGen    boshuuid.Generator
+	httpClient *http.Client
+	options    map[string]interface{}
+}
+
+type azureBlobstoreConfig struct {
+	AccountName   string `json:"account_name"`
+	AccountKey    string `json:"account_key"`
+	ContainerName string `json:"container_name"`
+	Endpoint      string `json:"endpoint"`
+
+	decodedKey []byte
+}
+
+func NewAzureBlobstore(
+	fs boshsys.FileSystem,
+	uuidGen boshuuid.Generator,
+	httpClient *http.Client,
+	options map[string]interface{},
+) AzureBlobstore {
+	return AzureBlobstore{
+		fs:         fs,
+		uuidGen:    uuidGen,
+		httpClient: httpClient,
+		options:    options,
+	}
+}
+
+func (b AzureBlobstore) Get(blobID, _ string) (string, error) {
+	conf, err := newAzureBlobstoreConfig(b.options)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := b.newRequest(conf, "GET", blobID, 0)
+	if err != nil {
+		return "", err
+	}
+
+	return b.request().Download(req, "bosh-azure-blob")
+}
+
+func (b AzureBlobstore) Create(path string) (string, string, error) {
+	conf, err := newAzureBlobstoreConfig(b.options)
+	if err != nil {
+		return "", "", err
+	}
+
+	if len(conf.decodedKey) == 0 {
+		return "", "", bosherr.Error("Uploading blobs requires non-empty 'account_key'")
+	}
+
+	blobID, err := b.uuidGen.Generate()
+	if err != nil {
+		return "", "", bosherr.WrapError(err, "Generating blobstore ID")
+	}
+
+	file, size, err := openBlobFile(b.fs, path)
+	if err != nil {
+		return "", "", err
+	}
+
+	defer file.Close()
+
+	req, err := b.newRequest(conf, "PUT", blobID, size)
+	if err != nil {
+		return "", "", err
+	}
+
+	req.Body = file
+
+	resp, err := b.request().Do(req, http.StatusCreated)
+	if err != nil {
+		return "", "", bosherr.WrapErrorf(err, "Uploading blob '%s'", blobID)
+	}
+
+	resp.Body.Close()
+
+	return blobID, "", nil
+}
+
+func (b AzureBlobstore) CleanUp(path string) error {
+	return b.fs.RemoveAll(path)
+}
+
+func (b AzureBlobstore) Delete(blobID string) error {
+	conf, err := newAzureBlobstoreConfig(b.options)
+	if err != nil {
+		return err
+	}
+
+	req, err := b.newRequest(conf, "DELETE", blobID, 0)
+	if err != nil {
+		return err
+	}
+
+	resp, err := b.request().Do(req, http.StatusAccepted)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Deleting blob '%s'", blobID)
+	}
+
+	resp.Body.Close()
+
+	return nil
+}
+
+func (b AzureBlobstore) Validate() error {
+	_, err := newAzureBlobstoreConfig(b.options)
+	return err
+}
+
+func (b AzureBlobstore) request() httpBlobRequest {
+	return httpBlobRequest{client: b.httpClient, fs: b.fs}
+}
+
+func (b AzureBlobstore) newRequest(conf azureBlobstoreConfig, method, blobID string, contentLength int64) (*http.Request, error) {
+	blobURL := strings.TrimSuffix(conf.Endpoint, "/") + "/" + url.PathEscape(conf.ContainerName) + "/" + url.PathEscape(blobID)
+
+	req, err := http.NewRequest(method, blobURL, nil)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Building request")
+	}
+
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureStorageAPIVersion)
+
+	if method == "PUT" {
+		req.ContentLength = contentLength
+		req.Header.Set("Content-Type", "application/octet-stream")
+		req.Header.Set("x-ms-blob-type", "BlockBlob")
+	}
+
+	if len(conf.decodedKey) > 0 {
+		req.Header.Set("Authorization", fmt.Sprintf(
+			"SharedKey %s:%s", conf.AccountName, azureSharedKeySignature(req, conf)))
+	}
+
+	return req, nil
+}
+
+// azureSharedKeySignature implements the Shared Key authorization scheme of the Blob service
+func azureSharedKeySignature(req *http.Request, conf azureBlobstoreConfig) string {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	var msHeaders []string
+
+	for name := range req.Header {
+		lowerName := strings.ToLower(name)
+		if strings.HasPrefix(lowerName, "x-ms-") {
+			msHeaders = append(msHeaders, lowerName+":"+strings.TrimSpace(req.Header.Get(name)))
+		}
+	}
+
+	sort.Strings(msHeaders)
+
+	canonicalizedResource := "/" + conf.AccountName + req.URL.EscapedPath()
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date is sent via x-ms-date
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		strings.Join(msHeaders, "\n"),
+		canonicalizedResource,
+	}, "\n")
+
+	mac := hmac.New(sha256.New, conf.decodedKey)
+	mac.Write([]byte(stringToSign))
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newAzureBlobstoreConfig(options map[string]interface{}) (azureBlobstoreConfig, error) {
+	var conf azureBlobstoreConfig
+
+	err := decodeBlobstoreOptions(options, &conf)
+	if err != nil {
+		return conf, err
+	}
+
+	err = requireBlobstoreOption("account_name", conf.AccountName)
+	if err != nil {
+		return conf, err
+	}
+
+	err = requireBlobstoreOption("container_name", conf.ContainerName)
+	if err != nil {
+		return conf, err
+	}
+
+	if len(conf.AccountKey) > 0 {
+		conf.decodedKey, err = base64.StdEncoding.DecodeString(conf.AccountKey)
+		if err != nil {
+			return conf, bosherr.WrapError(err, "Expected 'account_key' to be base64 encoded")
+		}
+	}
+
+	if len(conf.Endpoint) == 0 {
+		conf.Endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", conf.AccountName)
+	}
+
+	return conf, nil
+}
@@ -0,0 +1,165 @@
+package releasedir_test
+
+import (
+	"encoding/base64"
+	"net/http"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/cloudfoundry/bosh-cli/releasedir"
+)
+
+var _ = Describe("AzureBlobstore", func() {
+	var (
+		server  *ghttp.Server
+		fs      boshsys.FileSystem
+		uuidGen *fakeuuid.FakeGenerator
+		options map[string]interface{}
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		fs = boshsys.NewOsFileSystem(boshlog.NewLogger(boshlog.LevelNone))
+		uuidGen = &fakeuuid.FakeGenerator{GeneratedUUID: "fake-uuid"}
+
+		options = map[string]interface{}{
+			"account_name":   "account",
+			"container_name": "container",
+			"account_key":    base64.StdEncoding.EncodeToString([]byte("fake-key")),
+			"endpoint":       server.URL(),
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	verifySharedKey := func(w http.ResponseWriter, req *http.Request) {
+		Expect(req.Header.Get("Authorization")).To(MatchRegexp(`^SharedKey account:[A-Za-z0-9+/]+=*$`))
+		Expect(req.Header.Get("x-ms-version")).ToNot(BeEmpty())
+		Expect(req.Header.Get("x-ms-date")).ToNot(BeEmpty())
+	}
+
+	Describe("Get", func() {
+		It("downloads blob with shared key authorization", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/container/fake-blob-id"),
+					verifySharedKey,
+					ghttp.RespondWith(http.StatusOK, "blob-content"),
+				),
+			)
+
+			blobstore := NewAzureBlobstore(fs, uuidGen, http.DefaultClient, options)
+
+			path, err := blobstore.Get("fake-blob-id", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			defer fs.RemoveAll(path)
+
+			Expect(fs.ReadFileString(path)).To(Equal("blob-content"))
+		})
+
+		It("downloads blob anonymously without account key", func() {
+			delete(options, "account_key")
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/container/fake-blob-id"),
+					func(w http.ResponseWriter, req *http.Request) {
+						Expect(req.Header.Get("Authorization")).To(BeEmpty())
+					},
+					ghttp.RespondWith(http.StatusOK, "blob-content"),
+				),
+			)
+
+			blobstore := NewAzureBlobstore(fs, uuidGen, http.DefaultClient, options)
+
+			path, err := blobstore.Get("fake-blob-id", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			defer fs.RemoveAll(path)
+
+			Expect(fs.ReadFileString(path)).To(Equal("blob-content"))
+		})
+
+		It("returns error if request fails", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusForbidden, "denied"))
+
+			blobstore := NewAzureBlobstore(fs, uuidGen, http.DefaultClient, options)
+
+			_, err := blobstore.Get("fake-blob-id", "")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Wrong response code: 403; body: denied"))
+		})
+	})
+
+	Describe("Create", func() {
+		It("uploads file as a block blob", func() {
+			file, err := fs.TempFile("azure-blobstore-test")
+			Expect(err).ToNot(HaveOccurred())
+
+			defer fs.RemoveAll(file.Name())
+
+			_, err = file.Write([]byte("blob-content"))
+			Expect(err).ToNot(HaveOccurred())
+			file.Close()
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/container/fake-uuid"),
+					ghttp.VerifyHeaderKV("x-ms-blob-type", "BlockBlob"),
+					verifySharedKey,
+					ghttp.VerifyBody([]byte("blob-content")),
+					ghttp.RespondWith(http.StatusCreated, ""),
+				),
+			)
+
+			blobstore := NewAzureBlobstore(fs, uuidGen, http.DefaultClient, options)
+
+			blobID, _, err := blobstore.Create(file.Name())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(blobID).To(Equal("fake-uuid"))
+		})
+
+		It("returns error without account key", func() {
+			delete(options, "account_key")
+
+			blobstore := NewAzureBlobstore(fs, uuidGen, http.DefaultClient, options)
+
+			_, _, err := blobstore.Create("/some/path")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("requires non-empty 'account_key'"))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("deletes blob", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/container/fake-blob-id"),
+					verifySharedKey,
+					ghttp.RespondWith(http.StatusAccepted, ""),
+				),
+			)
+
+			err := NewAzureBlobstore(fs, uuidGen, http.DefaultClient, options).Delete("fake-blob-id")
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Describe("Validate", func() {
+		It("returns error if account name is missing", func() {
+			delete(options, "account_name")
+
+			err := NewAzureBlobstore(fs, uuidGen, http.DefaultClient, options).Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected non-empty 'account_name'"))
+		})
+	})
+})
@@ -0,0 +1,161 @@
+package releasedir
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+
+	boshdavcli "github.com/cloudfoundry/bosh-davcli/client"
+	boshdavcliconf "github.com/cloudfoundry/bosh-davcli/config"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
+)
+
+/*
+blobstore:
+  provider: dav
+  options:
+    endpoint: https://blobs.example.com/cf-release
+    user: ...     # typically in private.yml
+    password: ...
+*/
+
+type DavBlobstore struct {
+	fs         boshsys.FileSystem
+	uuidGen    boshuuid.Generator
+	httpClient *http.Client
+	options    map[string]interface{}
+	logger     boshlog.Logger
+}
+
+type davBlobstoreConfig struct {
+	Endpoint string `json:"endpoint"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+func NewDavBlobstore(
+	fs boshsys.FileSystem,
+	uuidGen boshuuid.Generator,
+	httpClient *http.Client,
+	options map[string]interface{},
+	logger boshlog.Logger,
+) DavBlobstore {
+	return DavBlobstore{
+		fs:         fs,
+		uuidGen:    uuidGen,
+		httpClient: httpClient,
+		options:    options,
+		logger:     logger,
+	}
+}
+
+func (b DavBlobstore) Get(blobID, _ string) (string, error) {
+	client, err := b.client()
+	if err != nil {
+		return "", err
+	}
+
+	file, err := b.fs.TempFile("bosh-dav-blob")
+	if err != nil {
+		return "", bosherr.WrapError(err, "Creating destination file")
+	}
+
+	defer file.Close()
+
+	content, err := client.Get(blobID)
+	if err != nil {
+		_ = b.fs.RemoveAll(file.Name())
+		return "", err
+	}
+
+	defer content.Close()
+
+	_, err = io.Copy(file, content)
+	if err != nil {
+		_ = b.fs.RemoveAll(file.Name())
+		return "", bosherr.WrapErrorf(err, "Downloading blob to '%s'", file.Name())
+	}
+
+	return file.Name(), nil
+}
+
+func (b DavBlobstore) Create(path string) (string, string, error) {
+	client, err := b.client()
+	if err != nil {
+		return "", "", err
+	}
+
+	blobID, err := b.uuidGen.Generate()
+	if err != nil {
+		return "", "", bosherr.WrapError(err, "Generating blobstore ID")
+	}
+
+	file, size, err := openBlobFile(b.fs, path)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Put closes the file once the upload finishes
+	err = client.Put(blobID, file, size)
+	if err != nil {
+		return "", "", bosherr.WrapErrorf(err, "Uploading blob '%s'", blobID)
+	}
+
+	return blobID, "", nil
+}
+
+func (b DavBlobstore) CleanUp(path string) error {
+	return b.fs.RemoveAll(path)
+}
+
+func (b DavBlobstore) Delete(blobID string) error {
+	panic("Not implemented")
+}
+
+func (b DavBlobstore) Validate() error {
+	_, err := b.client()
+	return err
+}
+
+func (b DavBlobstore) client() (boshdavcli.Client, error) {
+	conf, err := newDavBlobstoreConfig(b.options)
+	if err != nil {
+		return nil, err
+	}
+
+	davConf := boshdavcliconf.Config{
+		Endpoint: conf.Endpoint,
+		User:     conf.User,
+		Password: conf.Password,
+	}
+
+	return boshdavcli.NewClient(davConf, b.httpClient, b.logger), nil
+}
+
+func newDavBlobstoreConfig(options map[string]interface{}) (davBlobstoreConfig, error) {
+	var conf davBlobstoreConfig
+
+	err := decodeBlobstoreOptions(options, &conf)
+	if err != nil {
+		return conf, err
+	}
+
+	err = requireBlobstoreOption("endpoint", conf.Endpoint)
+	if err != nil {
+		return conf, err
+	}
+
+	endpointURL, err := url.Parse(conf.Endpoint)
+	if err != nil {
+		return conf, bosherr.WrapError(err, "Parsing 'endpoint'")
+	}
+
+	if endpointURL.Scheme != "http" && endpointURL.Scheme != "https" {
+		return conf, bosherr.Errorf("Expected 'endpoint' to be an http or https URL but was '%s'", conf.Endpoint)
+	}
+
+	return conf, nil
+}
@@ -0,0 +1,165 @@
+package releasedir_test
+
+import (
+	"net/http"
+	"path/filepath"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/cloudfoundry/bosh-cli/releasedir"
+)
+
+var _ = Describe("DavBlobstore", func() {
+	var (
+		server  *ghttp.Server
+		fs      boshsys.FileSystem
+		uuidGen *fakeuuid.FakeGenerator
+		logger  boshlog.Logger
+		options map[string]interface{}
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		logger = boshlog.NewLogger(boshlog.LevelNone)
+		fs = boshsys.NewOsFileSystem(logger)
+		uuidGen = &fakeuuid.FakeGenerator{GeneratedUUID: "fake-uuid"}
+
+		options = map[string]interface{}{
+			"endpoint": server.URL() + "/blobs",
+			"user":     "user",
+			"password": "pass",
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("Get", func() {
+		It("downloads blob from sha1 prefixed path", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					// sha1("fake-blob-id") starts with 0x80
+					ghttp.VerifyRequest("GET", "/blobs/80/fake-blob-id"),
+					ghttp.VerifyBasicAuth("user", "pass"),
+					ghttp.RespondWith(http.StatusOK, "blob-content"),
+				),
+			)
+
+			blobstore := NewDavBlobstore(fs, uuidGen, http.DefaultClient, options, logger)
+
+			path, err := blobstore.Get("fake-blob-id", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			defer fs.RemoveAll(path)
+
+			Expect(fs.ReadFileString(path)).To(Equal("blob-content"))
+		})
+
+		It("returns error if blob cannot be downloaded", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusNotFound, ""),
+				ghttp.RespondWith(http.StatusNotFound, ""),
+				ghttp.RespondWith(http.StatusNotFound, ""),
+			)
+
+			blobstore := NewDavBlobstore(fs, uuidGen, http.DefaultClient, options, logger)
+
+			_, err := blobstore.Get("fake-blob-id", "")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("StatusCode: 404"))
+		})
+
+		Context("when temporary files are created in a known directory", func() {
+			var (
+				tempRoot string
+			)
+
+			BeforeEach(func() {
+				var err error
+
+				tempRoot, err = fs.TempDir("dav-blobstore-test")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = fs.ChangeTempRoot(tempRoot)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				fs.RemoveAll(tempRoot)
+			})
+
+			It("removes destination file if blob cannot be downloaded", func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusNotFound, ""),
+					ghttp.RespondWith(http.StatusNotFound, ""),
+					ghttp.RespondWith(http.StatusNotFound, ""),
+				)
+
+				blobstore := NewDavBlobstore(fs, uuidGen, http.DefaultClient, options, logger)
+
+				_, err := blobstore.Get("fake-blob-id", "")
+				Expect(err).To(HaveOccurred())
+
+				Expect(fs.Glob(filepath.Join(tempRoot, "*"))).To(BeEmpty())
+			})
+
+			It("removes destination file if blob content cannot be copied", func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusOK, "partial", http.Header{"Content-Length": []string{"100"}}),
+				)
+
+				blobstore := NewDavBlobstore(fs, uuidGen, http.DefaultClient, options, logger)
+
+				_, err := blobstore.Get("fake-blob-id", "")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Downloading blob to"))
+
+				Expect(fs.Glob(filepath.Join(tempRoot, "*"))).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Create", func() {
+		It("uploads file under generated blob id", func() {
+			file, err := fs.TempFile("dav-blobstore-test")
+			Expect(err).ToNot(HaveOccurred())
+
+			defer fs.RemoveAll(file.Name())
+
+			_, err = file.Write([]byte("blob-content"))
+			Expect(err).ToNot(HaveOccurred())
+			file.Close()
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", MatchRegexp("^/blobs/[0-9a-f]{2}/fake-uuid$")),
+					ghttp.VerifyBasicAuth("user", "pass"),
+					ghttp.VerifyBody([]byte("blob-content")),
+					ghttp.RespondWith(http.StatusCreated, ""),
+				),
+			)
+
+			blobstore := NewDavBlobstore(fs, uuidGen, http.DefaultClient, options, logger)
+
+			blobID, _, err := blobstore.Create(file.Name())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(blobID).To(Equal("fake-uuid"))
+		})
+	})
+
+	Describe("Validate", func() {
+		It("returns error if endpoint is missing", func() {
+			delete(options, "endpoint")
+
+			err := NewDavBlobstore(fs, uuidGen, http.DefaultClient, options, logger).Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected non-empty 'endpoint'"))
+		})
+	})
+})
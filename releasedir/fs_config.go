@@ -21,6 +21,9 @@ blobstore:
   options: { ... }
 */
 
+// BlobstoreProviders lists supported values of 'blobstore.provider'
+var BlobstoreProviders = []string{"local", "s3", "gcs", "azure-storage", "dav"}
+
 type FSConfig struct {
 	publicPath  string
 	privatePath string
@@ -110,7 +113,32 @@ func (c FSConfig) Blobstore() (string, map[string]interface{}, error) {
 		opts[k] = v
 	}
 
-	return publicSchema.Blobstore.Provider, opts, nil
+	provider := publicSchema.Blobstore.Provider
+
+	err = c.validateBlobstoreOptions(provider, opts)
+	if err != nil {
+		return "", nil, bosherr.WrapErrorf(err,
+			"Validating '%s' blobstore options in config '%s'", provider, c.publicPath)
+	}
+
+	return provider, opts, nil
+}
+
+// validateBlobstoreOptions checks options of providers that do not validate on their own.
+// Unknown providers are left for the release dir provider to report.
+func (c FSConfig) validateBlobstoreOptions(provider string, opts map[string]interface{}) error {
+	var err error
+
+	switch provider {
+	case "gcs":
+		_, err = newGCSBlobstoreConfig(opts)
+	case "azure-storage":
+		_, err = newAzureBlobstoreConfig(opts)
+	case "dav":
+		_, err = newDavBlobstoreConfig(opts)
+	}
+
+	return err
 }
 
 func (c FSConfig) read() (fsConfigPublicSchema, fsConfigPrivateSchema, error) {
@@ -130,6 +130,68 @@ var _ = Describe("FSConfig", func() {
 			Expect(opts).To(Equal(map[string]interface{}{"opt1": "val1", "opt2": "priv-val"}))
 		})
 
+		It("returns gcs options that pass validation", func() {
+			fs.WriteFileString("/dir/public.yml", "blobstore: {provider: gcs, options: {bucket_name: bucket}}")
+
+			provider, opts, err := config.Blobstore()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(provider).To(Equal("gcs"))
+			Expect(opts).To(Equal(map[string]interface{}{"bucket_name": "bucket"}))
+		})
+
+		It("returns error if gcs options are missing bucket name", func() {
+			fs.WriteFileString("/dir/public.yml", "blobstore: {provider: gcs}")
+
+			_, _, err := config.Blobstore()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Validating 'gcs' blobstore options in config '/dir/public.yml': Expected non-empty 'bucket_name'"))
+		})
+
+		It("returns error if gcs static credentials are missing json key", func() {
+			fs.WriteFileString("/dir/public.yml", "blobstore: {provider: gcs, options: {bucket_name: bucket, credentials_source: static}}")
+
+			_, _, err := config.Blobstore()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected non-empty 'json_key'"))
+		})
+
+		It("returns error if azure-storage options are missing container name", func() {
+			fs.WriteFileString("/dir/public.yml", "blobstore: {provider: azure-storage, options: {account_name: acct}}")
+
+			_, _, err := config.Blobstore()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected non-empty 'container_name'"))
+		})
+
+		It("returns error if azure-storage account key is not base64 encoded", func() {
+			fs.WriteFileString("/dir/public.yml", "blobstore: {provider: azure-storage, options: {account_name: acct, container_name: cont}}")
+			fs.WriteFileString("/dir/private.yml", "blobstore: {options: {account_key: '!!!'}}")
+
+			_, _, err := config.Blobstore()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected 'account_key' to be base64 encoded"))
+		})
+
+		It("returns dav options merged with private config", func() {
+			fs.WriteFileString("/dir/public.yml", "blobstore: {provider: dav, options: {endpoint: 'https://host/path'}}")
+			fs.WriteFileString("/dir/private.yml", "blobstore: {options: {user: user, password: pass}}")
+
+			provider, opts, err := config.Blobstore()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(provider).To(Equal("dav"))
+			Expect(opts).To(Equal(map[string]interface{}{
+				"endpoint": "https://host/path", "user": "user", "password": "pass"}))
+		})
+
+		It("returns error if dav endpoint is not an http url", func() {
+			fs.WriteFileString("/dir/public.yml", "blobstore: {provider: dav, options: {endpoint: 'ftp://host'}}")
+
+			_, _, err := config.Blobstore()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected 'endpoint' to be an http or https URL but was 'ftp://host'"))
+		})
+
 		It("returns error if cannot read public config", func() {
 			fs.WriteFileString("/dir/public.yml", "-")
 			fs.RegisterReadFileError("/dir/public.yml", errors.New("fake-err"))
@@ -0,0 +1,332 @@
+package releasedir
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
+)
+
+const (
+	gcsDefaultEndpoint = "https://storage.googleapis.com"
+	gcsScope           = "https://www.googleapis.com/auth/devstorage.read_write"
+	gcsJWTGrantType    = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+)
+
+/*
+blobstore:
+  provider: gcs
+  options:
+    bucket_name: cf-release-blobs
+    credentials_source: static # or none for read-only access to public buckets
+    json_key: |
+      { ... service account key ... }
+*/
+
+type GCSBlobstore struct {
+	fs         boshsys.FileSystem
+	uuidGen    boshuuid.Generator
+	httpClient *http.Client
+	options    map[string]interface{}
+}
+
+type gcsBlobstoreConfig struct {
+	BucketName        string `json:"bucket_name"`
+	CredentialsSource string `json:"credentials_source"`
+	JSONKey           string `json:"json_key"`
+	Endpoint          string `json:"endpoint"`
+}
+
+type gcsServiceAccountKey struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+func NewGCSBlobstore(
+	fs boshsys.FileSystem,
+	uuidGen boshuuid.Generator,
+	httpClient *http.Client,
+	options map[string]interface{},
+) GCSBlobstore {
+	return GCSBlobstore{
+		fs:         fs,
+		uuidGen:    uuidGen,
+		httpClient: httpClient,
+		options:    options,
+	}
+}
+
+func (b GCSBlobstore) Get(blobID, _ string) (string, error) {
+	conf, err := newGCSBlobstoreConfig(b.options)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := b.newRequest(conf, "GET", blobID)
+	if err != nil {
+		return "", err
+	}
+
+	return b.request().Download(req, "bosh-gcs-blob")
+}
+
+func (b GCSBlobstore) Create(path string) (string, string, error) {
+	conf, err := newGCSBlobstoreConfig(b.options)
+	if err != nil {
+		return "", "", err
+	}
+
+	if conf.CredentialsSource == "none" {
+		return "", "", bosherr.Error("Uploading blobs requires 'credentials_source' other than 'none'")
+	}
+
+	blobID, err := b.uuidGen.Generate()
+	if err != nil {
+		return "", "", bosherr.WrapError(err, "Generating blobstore ID")
+	}
+
+	file, size, err := openBlobFile(b.fs, path)
+	if err != nil {
+		return "", "", err
+	}
+
+	defer file.Close()
+
+	req, err := b.newRequest(conf, "PUT", blobID)
+	if err != nil {
+		return "", "", err
+	}
+
+	req.Body = file
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := b.request().Do(req, http.StatusOK)
+	if err != nil {
+		return "", "", bosherr.WrapErrorf(err, "Uploading blob '%s'", blobID)
+	}
+
+	resp.Body.Close()
+
+	return blobID, "", nil
+}
+
+func (b GCSBlobstore) CleanUp(path string) error {
+	return b.fs.RemoveAll(path)
+}
+
+func (b GCSBlobstore) Delete(blobID string) error {
+	conf, err := newGCSBlobstoreConfig(b.options)
+	if err != nil {
+		return err
+	}
+
+	req, err := b.newRequest(conf, "DELETE", blobID)
+	if err != nil {
+		return err
+	}
+
+	resp, err := b.request().Do(req, http.StatusOK, http.StatusNoContent)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Deleting blob '%s'", blobID)
+	}
+
+	resp.Body.Close()
+
+	return nil
+}
+
+func (b GCSBlobstore) Validate() error {
+	_, err := newGCSBlobstoreConfig(b.options)
+	return err
+}
+
+func (b GCSBlobstore) request() httpBlobRequest {
+	return httpBlobRequest{client: b.httpClient, fs: b.fs}
+}
+
+func (b GCSBlobstore) newRequest(conf gcsBlobstoreConfig, method, blobID string) (*http.Request, error) {
+	blobURL := strings.TrimSuffix(conf.Endpoint, "/") + "/" + url.PathEscape(conf.BucketName) + "/" + url.PathEscape(blobID)
+
+	req, err := http.NewRequest(method, blobURL, nil)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Building request")
+	}
+
+	if conf.CredentialsSource == "static" {
+		token, err := b.accessToken(conf)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return req, nil
+}
+
+// accessToken exchanges a signed JWT assertion for an OAuth access token
+func (b GCSBlobstore) accessToken(conf gcsBlobstoreConfig) (string, error) {
+	key, privateKey, err := conf.serviceAccountKey()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	assertion, err := gcsSignJWT(privateKey, map[string]interface{}{
+		"iss":   key.ClientEmail,
+		"scope": gcsScope,
+		"aud":   key.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{"grant_type": {gcsJWTGrantType}, "assertion": {assertion}}
+
+	req, err := http.NewRequest("POST", key.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", bosherr.WrapError(err, "Building token request")
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := b.request().Do(req, http.StatusOK)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Requesting GCS access token")
+	}
+
+	defer resp.Body.Close()
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&tokenResp)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Unmarshaling GCS access token response")
+	}
+
+	if len(tokenResp.AccessToken) == 0 {
+		return "", bosherr.Error("Expected GCS access token response to include 'access_token'")
+	}
+
+	return tokenResp.AccessToken, nil
+}
+
+func newGCSBlobstoreConfig(options map[string]interface{}) (gcsBlobstoreConfig, error) {
+	var conf gcsBlobstoreConfig
+
+	err := decodeBlobstoreOptions(options, &conf)
+	if err != nil {
+		return conf, err
+	}
+
+	err = requireBlobstoreOption("bucket_name", conf.BucketName)
+	if err != nil {
+		return conf, err
+	}
+
+	if len(conf.CredentialsSource) == 0 {
+		if len(conf.JSONKey) > 0 {
+			conf.CredentialsSource = "static"
+		} else {
+			conf.CredentialsSource = "none"
+		}
+	}
+
+	switch conf.CredentialsSource {
+	case "static":
+		_, _, err = conf.serviceAccountKey()
+		if err != nil {
+			return conf, err
+		}
+	case "none":
+	default:
+		return conf, bosherr.Errorf(
+			"Expected 'credentials_source' to be 'static' or 'none' but was '%s'", conf.CredentialsSource)
+	}
+
+	if len(conf.Endpoint) == 0 {
+		conf.Endpoint = gcsDefaultEndpoint
+	}
+
+	return conf, nil
+}
+
+func (c gcsBlobstoreConfig) serviceAccountKey() (gcsServiceAccountKey, *rsa.PrivateKey, error) {
+	var key gcsServiceAccountKey
+
+	if len(c.JSONKey) == 0 {
+		return key, nil, bosherr.Error("Expected non-empty 'json_key' when 'credentials_source' is 'static'")
+	}
+
+	err := json.Unmarshal([]byte(c.JSONKey), &key)
+	if err != nil {
+		return key, nil, bosherr.WrapError(err, "Unmarshaling 'json_key'")
+	}
+
+	if len(key.ClientEmail) == 0 || len(key.TokenURI) == 0 {
+		return key, nil, bosherr.Error("Expected 'json_key' to include 'client_email' and 'token_uri'")
+	}
+
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return key, nil, bosherr.Error("Expected 'json_key' to include PEM encoded 'private_key'")
+	}
+
+	parsedKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsedKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return key, nil, bosherr.WrapError(err, "Parsing 'json_key' private key")
+		}
+	}
+
+	rsaKey, ok := parsedKey.(*rsa.PrivateKey)
+	if !ok {
+		return key, nil, bosherr.Error("Expected 'json_key' private key to be an RSA key")
+	}
+
+	return key, rsaKey, nil
+}
+
+func gcsSignJWT(key *rsa.PrivateKey, claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", bosherr.WrapError(err, "Marshaling JWT header")
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Marshaling JWT claims")
+	}
+
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", bosherr.WrapError(err, "Signing JWT")
+	}
+
+	return signingInput + "." + enc.EncodeToString(signature), nil
+}
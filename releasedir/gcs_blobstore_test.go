@@ -0,0 +1,220 @@
+package releasedir_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/cloudfoundry/bosh-cli/releasedir"
+)
+
+var _ = Describe("GCSBlobstore", func() {
+	var (
+		server  *ghttp.Server
+		fs      boshsys.FileSystem
+		uuidGen *fakeuuid.FakeGenerator
+		options map[string]interface{}
+		jsonKey string
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		fs = boshsys.NewOsFileSystem(boshlog.NewLogger(boshlog.LevelNone))
+		uuidGen = &fakeuuid.FakeGenerator{GeneratedUUID: "fake-uuid"}
+
+		privKey, err := rsa.GenerateKey(rand.Reader, 1024)
+		Expect(err).ToNot(HaveOccurred())
+
+		keyPEM := pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(privKey),
+		})
+
+		keyBytes, err := json.Marshal(map[string]string{
+			"client_email": "fake-email@example.com",
+			"private_key":  string(keyPEM),
+			"token_uri":    server.URL() + "/token",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		jsonKey = string(keyBytes)
+
+		options = map[string]interface{}{
+			"bucket_name": "bucket",
+			"json_key":    jsonKey,
+			"endpoint":    server.URL(),
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	tokenHandler := ghttp.CombineHandlers(
+		ghttp.VerifyRequest("POST", "/token"),
+		ghttp.VerifyFormKV("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer"),
+		ghttp.RespondWith(http.StatusOK, `{"access_token":"fake-token"}`),
+	)
+
+	Describe("Get", func() {
+		It("downloads blob using access token", func() {
+			server.AppendHandlers(
+				tokenHandler,
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/bucket/fake-blob-id"),
+					ghttp.VerifyHeaderKV("Authorization", "Bearer fake-token"),
+					ghttp.RespondWith(http.StatusOK, "blob-content"),
+				),
+			)
+
+			blobstore := NewGCSBlobstore(fs, uuidGen, http.DefaultClient, options)
+
+			path, err := blobstore.Get("fake-blob-id", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			defer fs.RemoveAll(path)
+
+			Expect(fs.ReadFileString(path)).To(Equal("blob-content"))
+		})
+
+		It("downloads blob anonymously when credentials source is none", func() {
+			delete(options, "json_key")
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/bucket/fake-blob-id"),
+					func(w http.ResponseWriter, req *http.Request) {
+						Expect(req.Header.Get("Authorization")).To(BeEmpty())
+					},
+					ghttp.RespondWith(http.StatusOK, "blob-content"),
+				),
+			)
+
+			blobstore := NewGCSBlobstore(fs, uuidGen, http.DefaultClient, options)
+
+			path, err := blobstore.Get("fake-blob-id", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			defer fs.RemoveAll(path)
+
+			Expect(fs.ReadFileString(path)).To(Equal("blob-content"))
+		})
+
+		It("returns error if blob cannot be found", func() {
+			server.AppendHandlers(
+				tokenHandler,
+				ghttp.RespondWith(http.StatusNotFound, "not-found"),
+			)
+
+			blobstore := NewGCSBlobstore(fs, uuidGen, http.DefaultClient, options)
+
+			_, err := blobstore.Get("fake-blob-id", "")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Wrong response code: 404; body: not-found"))
+		})
+
+		It("returns error if access token cannot be obtained", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusUnauthorized, "denied"))
+
+			blobstore := NewGCSBlobstore(fs, uuidGen, http.DefaultClient, options)
+
+			_, err := blobstore.Get("fake-blob-id", "")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Requesting GCS access token"))
+		})
+	})
+
+	Describe("Create", func() {
+		It("uploads file under generated blob id", func() {
+			file, err := fs.TempFile("gcs-blobstore-test")
+			Expect(err).ToNot(HaveOccurred())
+
+			defer fs.RemoveAll(file.Name())
+
+			_, err = file.Write([]byte("blob-content"))
+			Expect(err).ToNot(HaveOccurred())
+			file.Close()
+
+			server.AppendHandlers(
+				tokenHandler,
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/bucket/fake-uuid"),
+					ghttp.VerifyHeaderKV("Authorization", "Bearer fake-token"),
+					ghttp.VerifyBody([]byte("blob-content")),
+					ghttp.RespondWith(http.StatusOK, ""),
+				),
+			)
+
+			blobstore := NewGCSBlobstore(fs, uuidGen, http.DefaultClient, options)
+
+			blobID, fingerprint, err := blobstore.Create(file.Name())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(blobID).To(Equal("fake-uuid"))
+			Expect(fingerprint).To(BeEmpty())
+		})
+
+		It("returns error without credentials", func() {
+			delete(options, "json_key")
+
+			blobstore := NewGCSBlobstore(fs, uuidGen, http.DefaultClient, options)
+
+			_, _, err := blobstore.Create("/some/path")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("requires 'credentials_source' other than 'none'"))
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+
+	Describe("Delete", func() {
+		It("deletes blob", func() {
+			server.AppendHandlers(
+				tokenHandler,
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/bucket/fake-blob-id"),
+					ghttp.RespondWith(http.StatusNoContent, ""),
+				),
+			)
+
+			blobstore := NewGCSBlobstore(fs, uuidGen, http.DefaultClient, options)
+
+			err := blobstore.Delete("fake-blob-id")
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Describe("Validate", func() {
+		It("returns error if bucket name is missing", func() {
+			delete(options, "bucket_name")
+
+			err := NewGCSBlobstore(fs, uuidGen, http.DefaultClient, options).Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected non-empty 'bucket_name'"))
+		})
+
+		It("returns error if json key is not valid", func() {
+			options["json_key"] = "{}"
+
+			err := NewGCSBlobstore(fs, uuidGen, http.DefaultClient, options).Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected 'json_key' to include 'client_email' and 'token_uri'"))
+		})
+
+		It("returns error if credentials source is unknown", func() {
+			options["credentials_source"] = "env_or_profile"
+
+			err := NewGCSBlobstore(fs, uuidGen, http.DefaultClient, options).Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected 'credentials_source' to be 'static' or 'none'"))
+		})
+	})
+})
@@ -0,0 +1,103 @@
+package releasedir
+
+import (
+	gobytes "bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// httpBlobRequest is used by blobstores that talk directly to an HTTP object storage API
+type httpBlobRequest struct {
+	client *http.Client
+	fs     boshsys.FileSystem
+}
+
+func (r httpBlobRequest) Download(req *http.Request, tempFilePrefix string) (string, error) {
+	resp, err := r.Do(req, http.StatusOK)
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	file, err := r.fs.TempFile(tempFilePrefix)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Creating destination file")
+	}
+
+	defer file.Close()
+
+	_, err = io.Copy(file, resp.Body)
+	if err != nil {
+		_ = r.fs.RemoveAll(file.Name())
+		return "", bosherr.WrapErrorf(err, "Downloading blob to '%s'", file.Name())
+	}
+
+	return file.Name(), nil
+}
+
+func (r httpBlobRequest) Do(req *http.Request, expectedStatuses ...int) (*http.Response, error) {
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Performing request %s '%s'", req.Method, req.URL)
+	}
+
+	for _, status := range expectedStatuses {
+		if resp.StatusCode == status {
+			return resp, nil
+		}
+	}
+
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	return nil, bosherr.Errorf("Performing request %s '%s': Wrong response code: %d; body: %s",
+		req.Method, req.URL, resp.StatusCode, body)
+}
+
+// openBlobFile opens a file that is going to be uploaded along with its size
+func openBlobFile(fs boshsys.FileSystem, path string) (boshsys.File, int64, error) {
+	file, err := fs.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, 0, bosherr.WrapError(err, "Opening source file")
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, bosherr.WrapError(err, "Determining source file size")
+	}
+
+	return file, info.Size(), nil
+}
+
+// decodeBlobstoreOptions converts release blobstore options into provider specific config
+func decodeBlobstoreOptions(options map[string]interface{}, config interface{}) error {
+	bytes, err := json.Marshal(options)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshaling config")
+	}
+
+	decoder := json.NewDecoder(gobytes.NewReader(bytes))
+
+	err = decoder.Decode(config)
+	if err != nil {
+		return bosherr.WrapError(err, "Reading config")
+	}
+
+	return nil
+}
+
+func requireBlobstoreOption(name, value string) error {
+	if len(value) == 0 {
+		return bosherr.Errorf("Expected non-empty '%s'", name)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package releasedir
 
 import (
 	gopath "path"
+	"strings"
 
 	boshblob "github.com/cloudfoundry/bosh-utils/blobstore"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
@@ -11,6 +12,7 @@ import (
 	"github.com/pivotal-golang/clock"
 
 	bicrypto "github.com/cloudfoundry/bosh-cli/crypto"
+	boshproxy "github.com/cloudfoundry/bosh-cli/proxy"
 	boshrel "github.com/cloudfoundry/bosh-cli/release"
 	boshidx "github.com/cloudfoundry/bosh-cli/releasedir/index"
 )
@@ -99,8 +101,16 @@ func (p Provider) newBlobstore(dirPath string) boshblob.Blobstore {
 		blobstore = boshblob.NewLocalBlobstore(p.fs, p.uuidGen, options)
 	case "s3":
 		blobstore = NewS3Blobstore(p.fs, p.uuidGen, options)
+	case "gcs":
+		blobstore = NewGCSBlobstore(p.fs, p.uuidGen, boshproxy.CreateDefaultClient(nil), options)
+	case "azure-storage":
+		blobstore = NewAzureBlobstore(p.fs, p.uuidGen, boshproxy.CreateDefaultClient(nil), options)
+	case "dav":
+		blobstore = NewDavBlobstore(p.fs, p.uuidGen, boshproxy.CreateDefaultClient(nil), options, p.logger)
 	default:
-		return NewErrBlobstore(bosherr.Error("Expected release blobstore to be configured"))
+		return NewErrBlobstore(bosherr.Errorf(
+			"Expected release blobstore provider to be one of '%s' but was '%s'",
+			strings.Join(BlobstoreProviders, "', '"), provider))
 	}
 
 	blobstore = boshblob.NewSHA1VerifiableBlobstore(blobstore)
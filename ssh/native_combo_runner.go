@@ -0,0 +1,145 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/hashicorp/go-multierror"
+	gossh "golang.org/x/crypto/ssh"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+)
+
+// ExitStatusError is returned when remote command exits with non-zero exit status.
+type ExitStatusError struct {
+	Host       string
+	ExitStatus int
+}
+
+func (e ExitStatusError) Error() string {
+	return fmt.Sprintf("Command on '%s' exited with status %d", e.Host, e.ExitStatus)
+}
+
+// NativeHostFunc runs an action against a single host using established SSH connection.
+type NativeHostFunc func(*gossh.Client, boshdir.Host, InstanceWriter) error
+
+// NativeComboRunner is similar to ComboRunner but uses built-in SSH client
+// instead of executing ssh/scp binaries. All hosts are handled in parallel.
+type NativeComboRunner struct {
+	sessionFactory   func(ConnectionOpts, boshdir.SSHResult) *NativeSession
+	signalNotifyFunc func(chan<- os.Signal, ...os.Signal)
+
+	writer Writer
+	ui     boshui.UI
+
+	logTag string
+	logger boshlog.Logger
+}
+
+func NewNativeComboRunner(
+	sessionFactory func(ConnectionOpts, boshdir.SSHResult) *NativeSession,
+	signalNotifyFunc func(chan<- os.Signal, ...os.Signal),
+	writer Writer,
+	ui boshui.UI,
+	logger boshlog.Logger,
+) NativeComboRunner {
+	return NativeComboRunner{
+		sessionFactory:   sessionFactory,
+		signalNotifyFunc: signalNotifyFunc,
+
+		writer: writer,
+		ui:     ui,
+
+		logTag: "NativeComboRunner",
+		logger: logger,
+	}
+}
+
+func (r NativeComboRunner) Run(connOpts ConnectionOpts, result boshdir.SSHResult, hostFunc NativeHostFunc) error {
+	sess := r.sessionFactory(connOpts, result)
+
+	defer func() {
+		_ = sess.Finish()
+	}()
+
+	err := sess.Start()
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Setting up SSH session")
+	}
+
+	go r.setUpInterrupt(sess)
+
+	errCh := make(chan error, len(result.Hosts))
+
+	for _, host := range result.Hosts {
+		jobName := "?"
+		if len(host.Job) > 0 {
+			jobName = host.Job
+		}
+
+		instWriter := r.writer.ForInstance(jobName, host.IndexOrID)
+
+		go func(host boshdir.Host, instWriter InstanceWriter) {
+			err := r.runHost(sess, host, instWriter, hostFunc)
+			instWriter.End(r.exitStatus(err), err)
+			errCh <- err
+		}(host, instWriter)
+	}
+
+	r.logger.Debug(r.logTag, "Started all hosts")
+
+	var errs error
+
+	for i := 0; i < len(result.Hosts); i++ {
+		err := <-errCh
+		if err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	r.logger.Debug(r.logTag, "All hosts finished with errors '%s'", errs)
+
+	r.writer.Flush()
+
+	return errs
+}
+
+func (r NativeComboRunner) runHost(sess *NativeSession, host boshdir.Host, instWriter InstanceWriter, hostFunc NativeHostFunc) error {
+	client, err := sess.Dial(host)
+	if err != nil {
+		return err
+	}
+
+	err = hostFunc(client, host, instWriter)
+	if exitErr, ok := err.(*gossh.ExitError); ok {
+		return ExitStatusError{Host: host.Host, ExitStatus: exitErr.ExitStatus()}
+	}
+
+	return err
+}
+
+func (r NativeComboRunner) exitStatus(err error) int {
+	if exitErr, ok := err.(ExitStatusError); ok {
+		return exitErr.ExitStatus
+	}
+
+	return 0
+}
+
+func (r NativeComboRunner) setUpInterrupt(sess *NativeSession) {
+	signalCh := make(chan os.Signal, 1)
+
+	r.signalNotifyFunc(signalCh, os.Interrupt)
+
+	for _ = range signalCh {
+		r.logger.Debug(r.logTag, "Received an interrupt")
+
+		r.ui.PrintLinef("\nReceived an interrupt, exiting...\n")
+
+		// Closing connections makes all in-progress hosts finish
+		_ = sess.Finish()
+	}
+}
@@ -0,0 +1,92 @@
+package ssh
+
+import (
+	"io"
+	"os"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/terminal"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+)
+
+type NativeInteractiveRunner struct {
+	comboRunner NativeComboRunner
+
+	stdin  *os.File
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func NewNativeInteractiveRunner(comboRunner NativeComboRunner) NativeInteractiveRunner {
+	return NativeInteractiveRunner{
+		comboRunner: comboRunner,
+
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+}
+
+func (r NativeInteractiveRunner) Run(connOpts ConnectionOpts, result boshdir.SSHResult, rawCmd []string) error {
+	if len(result.Hosts) != 1 {
+		return bosherr.Errorf("Interactive SSH only works for a single host at a time")
+	}
+
+	if len(rawCmd) != 0 {
+		return bosherr.Errorf("Interactive SSH does not accept commands")
+	}
+
+	hostFunc := func(client *gossh.Client, host boshdir.Host, _ InstanceWriter) error {
+		sess, err := client.NewSession()
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Opening SSH session")
+		}
+
+		defer func() {
+			_ = sess.Close()
+		}()
+
+		width, height := 80, 40
+		fd := int(r.stdin.Fd())
+
+		if terminal.IsTerminal(fd) {
+			state, err := terminal.MakeRaw(fd)
+			if err != nil {
+				return bosherr.WrapErrorf(err, "Putting terminal into raw mode")
+			}
+
+			defer func() {
+				_ = terminal.Restore(fd, state)
+			}()
+
+			if w, h, err := terminal.GetSize(fd); err == nil {
+				width, height = w, h
+			}
+		}
+
+		term := os.Getenv("TERM")
+		if len(term) == 0 {
+			term = "xterm"
+		}
+
+		err = sess.RequestPty(term, height, width, gossh.TerminalModes{})
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Requesting pseudo terminal")
+		}
+
+		sess.Stdin = r.stdin
+		sess.Stdout = r.stdout
+		sess.Stderr = r.stderr
+
+		err = sess.Shell()
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Starting shell")
+		}
+
+		return sess.Wait()
+	}
+
+	return r.comboRunner.Run(connOpts, result, hostFunc)
+}
@@ -0,0 +1,54 @@
+package ssh
+
+import (
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	gossh "golang.org/x/crypto/ssh"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+)
+
+type NativeNonInteractiveRunner struct {
+	comboRunner NativeComboRunner
+}
+
+func NewNativeNonInteractiveRunner(comboRunner NativeComboRunner) NativeNonInteractiveRunner {
+	return NativeNonInteractiveRunner{comboRunner}
+}
+
+func (r NativeNonInteractiveRunner) Run(connOpts ConnectionOpts, result boshdir.SSHResult, rawCmd []string) error {
+	if len(result.Hosts) == 0 {
+		return bosherr.Errorf("Non-interactive SSH expects at least one host")
+	}
+
+	if len(rawCmd) == 0 {
+		return bosherr.Errorf("Non-interactive SSH expects non-empty command")
+	}
+
+	hostFunc := func(client *gossh.Client, host boshdir.Host, instWriter InstanceWriter) error {
+		sess, err := client.NewSession()
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Opening SSH session")
+		}
+
+		defer func() {
+			_ = sess.Close()
+		}()
+
+		// Similarly to 'ssh -tt' force TTY allocation so that
+		// remote processes are terminated when connection is closed
+		err = sess.RequestPty("xterm", 40, 80, gossh.TerminalModes{})
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Requesting pseudo terminal")
+		}
+
+		sess.Stdout = instWriter.Stdout()
+		sess.Stderr = instWriter.Stderr()
+
+		// Same as ssh executable, command arguments are joined with spaces
+		return sess.Run(strings.Join(rawCmd, " "))
+	}
+
+	return r.comboRunner.Run(connOpts, result, hostFunc)
+}
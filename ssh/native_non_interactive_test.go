@@ -0,0 +1,138 @@
+package ssh_test
+
+import (
+	"os"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	. "github.com/cloudfoundry/bosh-cli/ssh"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+)
+
+var _ = Describe("NativeNonInteractiveRunner", func() {
+	var (
+		server1, server2 *testSSHServer
+		writer           *testWriter
+		connOpts         ConnectionOpts
+		result           boshdir.SSHResult
+		runner           NativeNonInteractiveRunner
+	)
+
+	BeforeEach(func() {
+		privKey, signer := newTestKey()
+
+		server1 = newTestSSHServer(signer.PublicKey())
+		server2 = newTestSSHServer(signer.PublicKey())
+
+		fs := boshsys.NewOsFileSystem(boshlog.NewLogger(boshlog.LevelNone))
+
+		sessFactory := func(connOpts ConnectionOpts, result boshdir.SSHResult) *NativeSession {
+			return NewNativeSession(connOpts, result, fs)
+		}
+
+		signalNotifyFunc := func(chan<- os.Signal, ...os.Signal) {}
+
+		writer = newTestWriter()
+
+		comboRunner := NewNativeComboRunner(
+			sessFactory, signalNotifyFunc, writer, &fakeui.FakeUI{}, boshlog.NewLogger(boshlog.LevelNone))
+
+		runner = NewNativeNonInteractiveRunner(comboRunner)
+
+		connOpts = ConnectionOpts{PrivateKey: privKey}
+
+		result = boshdir.SSHResult{
+			Hosts: []boshdir.Host{
+				{
+					Job:           "job1",
+					IndexOrID:     "id1",
+					Username:      "user1",
+					Host:          server1.Addr(),
+					HostPublicKey: server1.HostPublicKey(),
+				},
+				{
+					IndexOrID:     "id2",
+					Username:      "user2",
+					Host:          server2.Addr(),
+					HostPublicKey: server2.HostPublicKey(),
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		server1.Close()
+		server2.Close()
+	})
+
+	It("runs command on all hosts and streams output per instance", func() {
+		err := runner.Run(connOpts, result, []string{"echo", "out;", "echo", "err", ">&2"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(server1.Commands()).To(Equal([]string{"echo out; echo err >&2"}))
+		Expect(server2.Commands()).To(Equal([]string{"echo out; echo err >&2"}))
+
+		instWriter := writer.Instance("job1/id1")
+		Expect(instWriter.StdoutString()).To(Equal("out\n"))
+		Expect(instWriter.StderrString()).To(Equal("err\n"))
+		Expect(instWriter.ended).To(BeTrue())
+		Expect(instWriter.exitStatus).To(Equal(0))
+		Expect(instWriter.err).ToNot(HaveOccurred())
+
+		instWriter = writer.Instance("?/id2")
+		Expect(instWriter.StdoutString()).To(Equal("out\n"))
+		Expect(instWriter.ended).To(BeTrue())
+
+		Expect(writer.flushed).To(BeTrue())
+	})
+
+	It("propagates remote exit statuses", func() {
+		err := runner.Run(connOpts, result, []string{"exit", "4"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("exited with status 4"))
+
+		instWriter := writer.Instance("job1/id1")
+		Expect(instWriter.exitStatus).To(Equal(4))
+		Expect(instWriter.err).To(Equal(ExitStatusError{Host: server1.Addr(), ExitStatus: 4}))
+
+		Expect(writer.Instance("?/id2").exitStatus).To(Equal(4))
+	})
+
+	It("returns connection errors without affecting other hosts", func() {
+		result.Hosts[1].HostPublicKey = result.Hosts[0].HostPublicKey
+
+		err := runner.Run(connOpts, result, []string{"echo", "hi"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Host key verification failed"))
+
+		Expect(writer.Instance("job1/id1").StdoutString()).To(Equal("hi\n"))
+		Expect(writer.Instance("job1/id1").err).ToNot(HaveOccurred())
+
+		Expect(writer.Instance("?/id2").err).To(HaveOccurred())
+		Expect(writer.Instance("?/id2").exitStatus).To(Equal(0))
+	})
+
+	It("returns error when session cannot be set up", func() {
+		connOpts.PrivateKey = "invalid"
+
+		err := runner.Run(connOpts, result, []string{"echo"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Setting up SSH session"))
+	})
+
+	It("returns error if there are no hosts", func() {
+		err := runner.Run(connOpts, boshdir.SSHResult{}, []string{"cmd"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Non-interactive SSH expects at least one host"))
+	})
+
+	It("returns error if command is empty", func() {
+		err := runner.Run(connOpts, result, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Non-interactive SSH expects non-empty command"))
+	})
+})
@@ -0,0 +1,450 @@
+package ssh
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	gossh "golang.org/x/crypto/ssh"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+)
+
+// NativeSCPRunner implements SCP protocol on top of built-in SSH client.
+// Remote side is expected to have scp binary available
+// (same as when using scp executable locally).
+type NativeSCPRunner struct {
+	comboRunner NativeComboRunner
+	fs          boshsys.FileSystem
+}
+
+func NewNativeSCPRunner(comboRunner NativeComboRunner, fs boshsys.FileSystem) NativeSCPRunner {
+	return NativeSCPRunner{comboRunner: comboRunner, fs: fs}
+}
+
+func (r NativeSCPRunner) Run(connOpts ConnectionOpts, result boshdir.SSHResult, scpArgs SCPArgs) error {
+	srcs, dst, upload, err := scpArgs.nativePaths()
+	if err != nil {
+		return err
+	}
+
+	hostFunc := func(client *gossh.Client, host boshdir.Host, instWriter InstanceWriter) error {
+		sess, err := client.NewSession()
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Opening SSH session")
+		}
+
+		defer func() {
+			_ = sess.Close()
+		}()
+
+		sess.Stderr = instWriter.Stderr()
+
+		stdin, err := sess.StdinPipe()
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Opening SCP stdin")
+		}
+
+		stdout, err := sess.StdoutPipe()
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Opening SCP stdout")
+		}
+
+		proto := nativeSCPProtocol{
+			w:         stdin,
+			r:         bufio.NewReader(stdout),
+			stderr:    instWriter.Stderr(),
+			recursive: scpArgs.recursive,
+			fs:        r.fs,
+		}
+
+		var cmd string
+		var protoFunc func() error
+
+		if upload {
+			cmd = proto.command("-t", []string{dst})
+			protoFunc = func() error { return proto.upload(srcs) }
+		} else {
+			cmd = proto.command("-f", srcs)
+			protoFunc = func() error { return proto.download(dst) }
+		}
+
+		err = sess.Start(cmd)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Starting remote scp")
+		}
+
+		protoErr := protoFunc()
+
+		_ = stdin.Close()
+
+		// Exit status takes precedence since remote side
+		// usually explains failure on stderr
+		err = sess.Wait()
+		if err != nil {
+			return err
+		}
+
+		return protoErr
+	}
+
+	return r.comboRunner.Run(connOpts, result, hostFunc)
+}
+
+// nativePaths splits raw arguments into sources and destination.
+// Either all sources or destination must be remote, but not both.
+func (a SCPArgs) nativePaths() ([]string, string, bool, error) {
+	if len(a.raw) < 2 {
+		return nil, "", false, bosherr.Errorf("Expected at least one source and a destination")
+	}
+
+	isRemote := func(rawArg string) (string, bool) {
+		pieces := strings.SplitN(rawArg, ":", 2)
+		if len(pieces) == 2 {
+			return pieces[1], true
+		}
+		return rawArg, false
+	}
+
+	var srcs []string
+	var remoteSrcs, localSrcs int
+
+	for _, rawArg := range a.raw[:len(a.raw)-1] {
+		path, remote := isRemote(rawArg)
+		if remote {
+			remoteSrcs++
+		} else {
+			localSrcs++
+		}
+		srcs = append(srcs, path)
+	}
+
+	dst, remoteDst := isRemote(a.raw[len(a.raw)-1])
+
+	switch {
+	case remoteDst && remoteSrcs == 0:
+		return srcs, dst, true, nil
+	case !remoteDst && localSrcs == 0:
+		return srcs, dst, false, nil
+	default:
+		return nil, "", false, bosherr.Errorf(
+			"Expected either all sources or only destination to refer to remote paths")
+	}
+}
+
+type nativeSCPProtocol struct {
+	w         io.Writer
+	r         *bufio.Reader
+	stderr    io.Writer
+	recursive bool
+	fs        boshsys.FileSystem
+}
+
+func (p nativeSCPProtocol) command(mode string, paths []string) string {
+	args := []string{"scp", mode}
+
+	if p.recursive {
+		args = append(args, "-r")
+	}
+
+	for _, path := range paths {
+		args = append(args, p.quote(path))
+	}
+
+	return strings.Join(args, " ")
+}
+
+func (p nativeSCPProtocol) quote(path string) string {
+	return "'" + strings.Replace(path, "'", `'\''`, -1) + "'"
+}
+
+func (p nativeSCPProtocol) upload(srcs []string) error {
+	err := p.readAck()
+	if err != nil {
+		return err
+	}
+
+	for _, src := range srcs {
+		info, err := p.fs.Stat(src)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Checking local path '%s'", src)
+		}
+
+		if info.IsDir() {
+			if !p.recursive {
+				return bosherr.Errorf("Expected '%s' to be a file since recursive copy is not enabled", src)
+			}
+			err = p.sendDir(src)
+		} else {
+			err = p.sendFile(src, info)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p nativeSCPProtocol) sendDir(root string) error {
+	var dirs []string
+
+	leaveDirs := func(path string) error {
+		for len(dirs) > 0 && filepath.Dir(path) != dirs[len(dirs)-1] {
+			dirs = dirs[:len(dirs)-1]
+
+			err := p.sendCmd("E\n")
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err := p.fs.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path != root {
+			err = leaveDirs(path)
+			if err != nil {
+				return err
+			}
+		}
+
+		if info.IsDir() {
+			dirs = append(dirs, path)
+			return p.sendCmd(fmt.Sprintf("D%04o 0 %s\n", info.Mode().Perm(), info.Name()))
+		}
+
+		if info.Mode().IsRegular() {
+			return p.sendFile(path, info)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Sending directory '%s'", root)
+	}
+
+	for range dirs {
+		err := p.sendCmd("E\n")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p nativeSCPProtocol) sendFile(path string, info os.FileInfo) error {
+	file, err := p.fs.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Opening local file '%s'", path)
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	err = p.sendCmd(fmt.Sprintf("C%04o %d %s\n", info.Mode().Perm(), info.Size(), filepath.Base(path)))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.CopyN(p.w, file, info.Size())
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Sending file '%s'", path)
+	}
+
+	return p.sendCmd("\x00")
+}
+
+func (p nativeSCPProtocol) download(dst string) error {
+	var dirs []string
+
+	targetPath := func(name string) string {
+		if len(dirs) > 0 {
+			return filepath.Join(dirs[len(dirs)-1], name)
+		}
+		if info, err := p.fs.Stat(dst); err == nil && info.IsDir() {
+			return filepath.Join(dst, name)
+		}
+		return dst
+	}
+
+	err := p.writeAck()
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+
+	for {
+		line, err := p.r.ReadString('\n')
+		if err == io.EOF && len(line) == 0 {
+			break
+		} else if err != nil {
+			return bosherr.WrapErrorf(err, "Reading SCP command")
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+		if len(line) == 0 {
+			return bosherr.Errorf("Received empty SCP command")
+		}
+
+		switch line[0] {
+		case 'C', 'D':
+			mode, size, name, err := p.parseEntry(line)
+			if err != nil {
+				return err
+			}
+
+			path := targetPath(name)
+
+			if line[0] == 'D' {
+				err = p.fs.MkdirAll(path, mode)
+				if err != nil {
+					return bosherr.WrapErrorf(err, "Creating local directory '%s'", path)
+				}
+
+				dirs = append(dirs, path)
+
+				err = p.writeAck()
+			} else {
+				err = p.receiveFile(path, mode, size)
+			}
+			if err != nil {
+				return err
+			}
+
+		case 'E':
+			if len(dirs) == 0 {
+				return bosherr.Errorf("Received unexpected end of directory")
+			}
+
+			dirs = dirs[:len(dirs)-1]
+
+			err = p.writeAck()
+			if err != nil {
+				return err
+			}
+
+		case 'T':
+			err = p.writeAck()
+			if err != nil {
+				return err
+			}
+
+		case '\x01', '\x02':
+			// Remote side continues with other files on warnings
+			// and exits with non-zero exit status
+			errs = append(errs, line[1:])
+			fmt.Fprintf(p.stderr, "%s\n", line[1:])
+
+		default:
+			return bosherr.Errorf("Received unknown SCP command '%s'", line)
+		}
+	}
+
+	if len(errs) > 0 {
+		return bosherr.Errorf("Copying files: %s", strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+func (p nativeSCPProtocol) receiveFile(path string, mode os.FileMode, size int64) error {
+	file, err := p.fs.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Opening local file '%s'", path)
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	err = p.writeAck()
+	if err != nil {
+		return err
+	}
+
+	_, err = io.CopyN(file, p.r, size)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Receiving file '%s'", path)
+	}
+
+	err = p.readAck()
+	if err != nil {
+		return err
+	}
+
+	return p.writeAck()
+}
+
+// parseEntry parses 'C0644 123 name' and 'D0755 0 name' commands
+func (p nativeSCPProtocol) parseEntry(line string) (os.FileMode, int64, string, error) {
+	pieces := strings.SplitN(line[1:], " ", 3)
+	if len(pieces) != 3 {
+		return 0, 0, "", bosherr.Errorf("Parsing SCP command '%s'", line)
+	}
+
+	mode, err := strconv.ParseUint(pieces[0], 8, 32)
+	if err != nil {
+		return 0, 0, "", bosherr.WrapErrorf(err, "Parsing SCP file mode '%s'", pieces[0])
+	}
+
+	size, err := strconv.ParseInt(pieces[1], 10, 64)
+	if err != nil {
+		return 0, 0, "", bosherr.WrapErrorf(err, "Parsing SCP file size '%s'", pieces[1])
+	}
+
+	name := pieces[2]
+
+	// Do not let remote side write outside of destination
+	if name == "." || name == ".." || strings.ContainsAny(name, "/\\") {
+		return 0, 0, "", bosherr.Errorf("Received unexpected file name '%s'", name)
+	}
+
+	return os.FileMode(mode).Perm(), size, name, nil
+}
+
+func (p nativeSCPProtocol) sendCmd(cmd string) error {
+	_, err := io.WriteString(p.w, cmd)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing SCP command")
+	}
+
+	return p.readAck()
+}
+
+func (p nativeSCPProtocol) writeAck() error {
+	_, err := p.w.Write([]byte{0})
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing SCP acknowledgement")
+	}
+
+	return nil
+}
+
+func (p nativeSCPProtocol) readAck() error {
+	b, err := p.r.ReadByte()
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Reading SCP acknowledgement")
+	}
+
+	if b == 0 {
+		return nil
+	}
+
+	msg, _ := p.r.ReadString('\n')
+
+	return bosherr.Errorf("Remote scp: %s", strings.TrimSpace(msg))
+}
@@ -0,0 +1,222 @@
+package ssh_test
+
+import (
+	"os"
+	"path/filepath"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	. "github.com/cloudfoundry/bosh-cli/ssh"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+)
+
+var _ = Describe("NativeSCPRunner", func() {
+	var (
+		server    *testSSHServer
+		writer    *testWriter
+		fs        boshsys.FileSystem
+		localDir  string
+		remoteDir string
+		connOpts  ConnectionOpts
+		result    boshdir.SSHResult
+		runner    NativeSCPRunner
+	)
+
+	BeforeEach(func() {
+		privKey, signer := newTestKey()
+
+		server = newTestSSHServer(signer.PublicKey())
+
+		fs = boshsys.NewOsFileSystem(boshlog.NewLogger(boshlog.LevelNone))
+
+		var err error
+
+		localDir, err = fs.TempDir("native-scp-local")
+		Expect(err).ToNot(HaveOccurred())
+
+		// In-process server runs commands locally so remote paths are local as well
+		remoteDir, err = fs.TempDir("native-scp-remote")
+		Expect(err).ToNot(HaveOccurred())
+
+		sessFactory := func(connOpts ConnectionOpts, result boshdir.SSHResult) *NativeSession {
+			return NewNativeSession(connOpts, result, fs)
+		}
+
+		signalNotifyFunc := func(chan<- os.Signal, ...os.Signal) {}
+
+		writer = newTestWriter()
+
+		comboRunner := NewNativeComboRunner(
+			sessFactory, signalNotifyFunc, writer, &fakeui.FakeUI{}, boshlog.NewLogger(boshlog.LevelNone))
+
+		runner = NewNativeSCPRunner(comboRunner, fs)
+
+		connOpts = ConnectionOpts{PrivateKey: privKey}
+
+		result = boshdir.SSHResult{
+			Hosts: []boshdir.Host{
+				{
+					Job:           "job",
+					IndexOrID:     "id",
+					Username:      "user",
+					Host:          server.Addr(),
+					HostPublicKey: server.HostPublicKey(),
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		_ = fs.RemoveAll(localDir)
+		_ = fs.RemoveAll(remoteDir)
+	})
+
+	writeFile := func(path, content string) {
+		Expect(fs.WriteFileString(path, content)).ToNot(HaveOccurred())
+	}
+
+	readFile := func(path string) string {
+		content, err := fs.ReadFileString(path)
+		Expect(err).ToNot(HaveOccurred())
+		return content
+	}
+
+	Describe("uploading", func() {
+		It("copies local files to remote destination", func() {
+			writeFile(filepath.Join(localDir, "file1"), "content1")
+			writeFile(filepath.Join(localDir, "file2"), "content2")
+
+			args := NewSCPArgs([]string{
+				filepath.Join(localDir, "file1"),
+				filepath.Join(localDir, "file2"),
+				"job/id:" + remoteDir,
+			}, false)
+
+			err := runner.Run(connOpts, result, args)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(server.Commands()).To(Equal([]string{"scp -t '" + remoteDir + "'"}))
+
+			Expect(readFile(filepath.Join(remoteDir, "file1"))).To(Equal("content1"))
+			Expect(readFile(filepath.Join(remoteDir, "file2"))).To(Equal("content2"))
+
+			Expect(writer.Instance("job/id").ended).To(BeTrue())
+			Expect(writer.Instance("job/id").err).ToNot(HaveOccurred())
+		})
+
+		It("copies local directories recursively", func() {
+			writeFile(filepath.Join(localDir, "dir", "file1"), "content1")
+			writeFile(filepath.Join(localDir, "dir", "sub1", "file2"), "content2")
+			writeFile(filepath.Join(localDir, "dir", "sub1", "sub2", "file3"), "content3")
+			writeFile(filepath.Join(localDir, "dir", "sub3", "file4"), "content4")
+
+			args := NewSCPArgs([]string{filepath.Join(localDir, "dir"), "job/id:" + remoteDir}, true)
+
+			err := runner.Run(connOpts, result, args)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(readFile(filepath.Join(remoteDir, "dir", "file1"))).To(Equal("content1"))
+			Expect(readFile(filepath.Join(remoteDir, "dir", "sub1", "file2"))).To(Equal("content2"))
+			Expect(readFile(filepath.Join(remoteDir, "dir", "sub1", "sub2", "file3"))).To(Equal("content3"))
+			Expect(readFile(filepath.Join(remoteDir, "dir", "sub3", "file4"))).To(Equal("content4"))
+		})
+
+		It("returns error when copying directory without recursive flag", func() {
+			Expect(fs.MkdirAll(filepath.Join(localDir, "dir"), os.ModePerm)).ToNot(HaveOccurred())
+
+			args := NewSCPArgs([]string{filepath.Join(localDir, "dir"), "job/id:" + remoteDir}, false)
+
+			err := runner.Run(connOpts, result, args)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("recursive copy is not enabled"))
+		})
+
+		It("returns error when remote side fails", func() {
+			writeFile(filepath.Join(localDir, "file1"), "content1")
+
+			args := NewSCPArgs([]string{
+				filepath.Join(localDir, "file1"),
+				"job/id:" + filepath.Join(remoteDir, "missing", "file1"),
+			}, false)
+
+			err := runner.Run(connOpts, result, args)
+			Expect(err).To(HaveOccurred())
+
+			Expect(writer.Instance("job/id").exitStatus).ToNot(Equal(0))
+		})
+	})
+
+	Describe("downloading", func() {
+		It("copies remote file into local directory", func() {
+			writeFile(filepath.Join(remoteDir, "file1"), "content1")
+
+			args := NewSCPArgs([]string{"job/id:" + filepath.Join(remoteDir, "file1"), localDir}, false)
+
+			err := runner.Run(connOpts, result, args)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(server.Commands()).To(Equal([]string{"scp -f '" + filepath.Join(remoteDir, "file1") + "'"}))
+
+			Expect(readFile(filepath.Join(localDir, "file1"))).To(Equal("content1"))
+		})
+
+		It("copies remote file to local file path", func() {
+			writeFile(filepath.Join(remoteDir, "file1"), "content1")
+
+			args := NewSCPArgs([]string{
+				"job/id:" + filepath.Join(remoteDir, "file1"),
+				filepath.Join(localDir, "renamed"),
+			}, false)
+
+			err := runner.Run(connOpts, result, args)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(readFile(filepath.Join(localDir, "renamed"))).To(Equal("content1"))
+		})
+
+		It("copies remote directories recursively", func() {
+			writeFile(filepath.Join(remoteDir, "dir", "file1"), "content1")
+			writeFile(filepath.Join(remoteDir, "dir", "sub1", "file2"), "content2")
+
+			args := NewSCPArgs([]string{"job/id:" + filepath.Join(remoteDir, "dir"), localDir}, true)
+
+			err := runner.Run(connOpts, result, args)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(readFile(filepath.Join(localDir, "dir", "file1"))).To(Equal("content1"))
+			Expect(readFile(filepath.Join(localDir, "dir", "sub1", "file2"))).To(Equal("content2"))
+		})
+
+		It("returns error with exit status when remote file does not exist", func() {
+			args := NewSCPArgs([]string{"job/id:" + filepath.Join(remoteDir, "missing"), localDir}, false)
+
+			err := runner.Run(connOpts, result, args)
+			Expect(err).To(HaveOccurred())
+
+			instWriter := writer.Instance("job/id")
+			Expect(instWriter.exitStatus).To(Equal(1))
+			Expect(instWriter.StderrString()).To(ContainSubstring("missing"))
+		})
+	})
+
+	It("returns error when both sources and destination are remote", func() {
+		args := NewSCPArgs([]string{"job/id:/src", "job/id:/dst"}, false)
+
+		err := runner.Run(connOpts, result, args)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected either all sources or only destination to refer to remote paths"))
+	})
+
+	It("returns error when destination is missing", func() {
+		args := NewSCPArgs([]string{"job/id:/src"}, false)
+
+		err := runner.Run(connOpts, result, args)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected at least one source and a destination"))
+	})
+})
@@ -0,0 +1,314 @@
+package ssh_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/gomega"
+	gossh "golang.org/x/crypto/ssh"
+
+	. "github.com/cloudfoundry/bosh-cli/ssh"
+)
+
+// testSSHServer is an in-process SSH server that executes commands
+// with local shell and allows TCP forwarding (to act as a gateway).
+type testSSHServer struct {
+	listener net.Listener
+	config   *gossh.ServerConfig
+	hostKey  gossh.Signer
+
+	lock        sync.Mutex
+	users       []string
+	commands    []string
+	forwardings []string
+}
+
+func newTestSSHServer(authorizedKey gossh.PublicKey) *testSSHServer {
+	_, hostKey := newTestKey()
+
+	server := &testSSHServer{hostKey: hostKey}
+
+	server.config = &gossh.ServerConfig{
+		PublicKeyCallback: func(conn gossh.ConnMetadata, key gossh.PublicKey) (*gossh.Permissions, error) {
+			if !bytes.Equal(key.Marshal(), authorizedKey.Marshal()) {
+				return nil, fmt.Errorf("unknown public key for %s", conn.User())
+			}
+			server.lock.Lock()
+			server.users = append(server.users, conn.User())
+			server.lock.Unlock()
+			return nil, nil
+		},
+	}
+
+	server.config.AddHostKey(server.hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+
+	server.listener = listener
+
+	go server.accept()
+
+	return server
+}
+
+func newTestKey() (string, gossh.Signer) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).ToNot(HaveOccurred())
+
+	signer, err := gossh.NewSignerFromKey(key)
+	Expect(err).ToNot(HaveOccurred())
+
+	privKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	return string(privKey), signer
+}
+
+func (s *testSSHServer) Addr() string { return s.listener.Addr().String() }
+
+func (s *testSSHServer) HostPublicKey() string {
+	return strings.TrimSpace(string(gossh.MarshalAuthorizedKey(s.hostKey.PublicKey())))
+}
+
+func (s *testSSHServer) Close() { _ = s.listener.Close() }
+
+func (s *testSSHServer) Users() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.users...)
+}
+
+func (s *testSSHServer) Commands() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.commands...)
+}
+
+func (s *testSSHServer) Forwardings() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.forwardings...)
+}
+
+func (s *testSSHServer) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go s.handleConn(conn)
+	}
+}
+
+func (s *testSSHServer) handleConn(conn net.Conn) {
+	_, chans, reqs, err := gossh.NewServerConn(conn, s.config)
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+
+	go gossh.DiscardRequests(reqs)
+
+	for newCh := range chans {
+		switch newCh.ChannelType() {
+		case "session":
+			go s.handleSession(newCh)
+		case "direct-tcpip":
+			go s.handleDirectTCPIP(newCh)
+		default:
+			_ = newCh.Reject(gossh.UnknownChannelType, "unknown channel type")
+		}
+	}
+}
+
+func (s *testSSHServer) handleSession(newCh gossh.NewChannel) {
+	ch, reqs, err := newCh.Accept()
+	if err != nil {
+		return
+	}
+
+	for req := range reqs {
+		switch req.Type {
+		case "pty-req", "env":
+			_ = req.Reply(true, nil)
+
+		case "exec":
+			var payload struct{ Command string }
+
+			err := gossh.Unmarshal(req.Payload, &payload)
+			if err != nil {
+				_ = req.Reply(false, nil)
+				continue
+			}
+
+			s.lock.Lock()
+			s.commands = append(s.commands, payload.Command)
+			s.lock.Unlock()
+
+			_ = req.Reply(true, nil)
+
+			go s.exec(ch, payload.Command)
+
+		default:
+			_ = req.Reply(false, nil)
+		}
+	}
+}
+
+func (s *testSSHServer) exec(ch gossh.Channel, command string) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout = ch
+	cmd.Stderr = ch.Stderr()
+
+	var status uint32
+
+	// Similarly to sshd do not wait for client to close stdin
+	// once command exits
+	stdin, err := cmd.StdinPipe()
+	if err == nil {
+		go func() {
+			_, _ = io.Copy(stdin, ch)
+			_ = stdin.Close()
+		}()
+
+		err = cmd.Run()
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		status = uint32(exitErr.ExitCode())
+	} else if err != nil {
+		status = 255
+	}
+
+	_, _ = ch.SendRequest("exit-status", false, gossh.Marshal(&struct{ Status uint32 }{status}))
+	_ = ch.Close()
+}
+
+func (s *testSSHServer) handleDirectTCPIP(newCh gossh.NewChannel) {
+	var payload struct {
+		Host     string
+		Port     uint32
+		OrigHost string
+		OrigPort uint32
+	}
+
+	err := gossh.Unmarshal(newCh.ExtraData(), &payload)
+	if err != nil {
+		_ = newCh.Reject(gossh.Prohibited, err.Error())
+		return
+	}
+
+	addr := net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port)))
+
+	s.lock.Lock()
+	s.forwardings = append(s.forwardings, addr)
+	s.lock.Unlock()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		_ = newCh.Reject(gossh.ConnectionFailed, err.Error())
+		return
+	}
+
+	ch, reqs, err := newCh.Accept()
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+
+	go gossh.DiscardRequests(reqs)
+
+	go func() {
+		_, _ = io.Copy(ch, conn)
+		_ = ch.Close()
+	}()
+
+	go func() {
+		_, _ = io.Copy(conn, ch)
+		_ = conn.Close()
+	}()
+}
+
+// testWriter records output and results per instance
+type testWriter struct {
+	lock      sync.Mutex
+	instances map[string]*testInstanceWriter
+	flushed   bool
+}
+
+func newTestWriter() *testWriter {
+	return &testWriter{instances: map[string]*testInstanceWriter{}}
+}
+
+func (w *testWriter) ForInstance(jobName, indexOrID string) InstanceWriter {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	instWriter := &testInstanceWriter{}
+	w.instances[jobName+"/"+indexOrID] = instWriter
+
+	return instWriter
+}
+
+func (w *testWriter) Flush() { w.flushed = true }
+
+func (w *testWriter) Instance(name string) *testInstanceWriter {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.instances[name]
+}
+
+type testInstanceWriter struct {
+	lock   sync.Mutex
+	stdout bytes.Buffer
+	stderr bytes.Buffer
+
+	ended      bool
+	exitStatus int
+	err        error
+}
+
+func (w *testInstanceWriter) Stdout() io.Writer { return lockedWriter{&w.lock, &w.stdout} }
+func (w *testInstanceWriter) Stderr() io.Writer { return lockedWriter{&w.lock, &w.stderr} }
+
+func (w *testInstanceWriter) End(exitStatus int, err error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.ended = true
+	w.exitStatus = exitStatus
+	w.err = err
+}
+
+func (w *testInstanceWriter) StdoutString() string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.stdout.String()
+}
+
+func (w *testInstanceWriter) StderrString() string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.stderr.String()
+}
+
+type lockedWriter struct {
+	lock *sync.Mutex
+	w    io.Writer
+}
+
+func (w lockedWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.w.Write(p)
+}
@@ -0,0 +1,257 @@
+package ssh
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+)
+
+const nativeSessionDefaultPort = "22"
+
+// NativeSession keeps track of SSH connections made with built-in SSH client.
+// Unlike SessionImpl it does not write any files since private keys
+// and known host keys are kept in memory.
+type NativeSession struct {
+	connOpts ConnectionOpts
+	result   boshdir.SSHResult
+
+	signer    gossh.Signer
+	gwClient  *gossh.Client
+	agentConn net.Conn
+
+	clients     []*gossh.Client
+	clientsLock sync.Mutex
+	finished    bool
+
+	dialFunc    func(network, addr string) (net.Conn, error)
+	getenvFunc  func(string) string
+	dialTimeout time.Duration
+
+	fs boshsys.FileSystem
+}
+
+func NewNativeSession(connOpts ConnectionOpts, result boshdir.SSHResult, fs boshsys.FileSystem) *NativeSession {
+	return &NativeSession{
+		connOpts: connOpts,
+		result:   result,
+
+		dialFunc:    net.Dial,
+		getenvFunc:  os.Getenv,
+		dialTimeout: 30 * time.Second,
+
+		fs: fs,
+	}
+}
+
+func (s *NativeSession) Start() error {
+	if len(s.connOpts.RawOpts) > 0 {
+		return bosherr.Errorf("Built-in SSH client does not support passing through SSH options")
+	}
+
+	signer, err := gossh.ParsePrivateKey([]byte(s.connOpts.PrivateKey))
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Parsing SSH private key")
+	}
+
+	s.signer = signer
+
+	gwUsername, gwHost, gwPrivKeyPath := gatewayOpts(s.connOpts, s.result)
+
+	if len(gwHost) > 0 {
+		s.gwClient, err = s.dialGateway(gwUsername, gwHost, gwPrivKeyPath)
+		if err != nil {
+			if s.agentConn != nil {
+				_ = s.agentConn.Close()
+				s.agentConn = nil
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Dial connects to a host directly or through a gateway if one is configured.
+// Host key must match the one provided by the Director for that host.
+func (s *NativeSession) Dial(host boshdir.Host) (*gossh.Client, error) {
+	addr := s.hostAddr(host.Host)
+
+	config := &gossh.ClientConfig{
+		User:            host.Username,
+		Auth:            []gossh.AuthMethod{gossh.PublicKeys(s.signer)},
+		HostKeyCallback: s.hostKeyCallback(host),
+	}
+
+	var conn net.Conn
+	var err error
+
+	if s.gwClient != nil {
+		conn, err = s.gwClient.Dial("tcp", addr)
+	} else {
+		conn, err = s.dialFunc("tcp", addr)
+	}
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Connecting to '%s'", addr)
+	}
+
+	client, err := s.newClient(conn, addr, config)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Establishing SSH connection to '%s'", addr)
+	}
+
+	s.clientsLock.Lock()
+	defer s.clientsLock.Unlock()
+
+	if s.finished {
+		_ = client.Close()
+		return nil, bosherr.Errorf("SSH session was closed while connecting to '%s'", addr)
+	}
+
+	s.clients = append(s.clients, client)
+
+	return client, nil
+}
+
+// Finish closes all connections including gateway connection.
+// It's safe to call it multiple times.
+func (s *NativeSession) Finish() error {
+	s.clientsLock.Lock()
+	clients := s.clients
+	s.clients = nil
+	s.finished = true
+	s.clientsLock.Unlock()
+
+	for _, client := range clients {
+		_ = client.Close()
+	}
+
+	if s.gwClient != nil {
+		_ = s.gwClient.Close()
+	}
+
+	if s.agentConn != nil {
+		_ = s.agentConn.Close()
+	}
+
+	return nil
+}
+
+func (s *NativeSession) dialGateway(username, host, privKeyPath string) (*gossh.Client, error) {
+	auths, err := s.gatewayAuthMethods(privKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Strict host key checking for a gateway is not necessary
+	// since it's only used for forwarding TCP connections
+	// and instance host keys are still verified
+	config := &gossh.ClientConfig{
+		User:            username,
+		Auth:            auths,
+		HostKeyCallback: func(string, net.Addr, gossh.PublicKey) error { return nil },
+	}
+
+	addr := s.hostAddr(host)
+
+	conn, err := s.dialFunc("tcp", addr)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Connecting to gateway '%s'", addr)
+	}
+
+	client, err := s.newClient(conn, addr, config)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Establishing SSH connection to gateway '%s'", addr)
+	}
+
+	return client, nil
+}
+
+func (s *NativeSession) gatewayAuthMethods(privKeyPath string) ([]gossh.AuthMethod, error) {
+	if len(privKeyPath) > 0 {
+		expandedPath, err := s.fs.ExpandPath(privKeyPath)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Expanding gateway private key path '%s'", privKeyPath)
+		}
+
+		privKey, err := s.fs.ReadFile(expandedPath)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Reading gateway private key '%s'", expandedPath)
+		}
+
+		signer, err := gossh.ParsePrivateKey(privKey)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Parsing gateway private key '%s'", expandedPath)
+		}
+
+		return []gossh.AuthMethod{gossh.PublicKeys(signer)}, nil
+	}
+
+	// Similarly to ssh executable fall back to SSH agent
+	agentSock := s.getenvFunc("SSH_AUTH_SOCK")
+	if len(agentSock) > 0 {
+		conn, err := s.dialFunc("unix", agentSock)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Connecting to SSH agent")
+		}
+
+		// Agent connection is used for signing during gateway handshake
+		// and is kept open until session is finished
+		s.agentConn = conn
+
+		return []gossh.AuthMethod{gossh.PublicKeysCallback(agent.NewClient(conn).Signers)}, nil
+	}
+
+	return nil, bosherr.Errorf(
+		"Expected gateway private key to be specified via '--gw-private-key' or SSH agent to be available")
+}
+
+func (s *NativeSession) newClient(conn net.Conn, addr string, config *gossh.ClientConfig) (*gossh.Client, error) {
+	// Only bound time spent on handshake since sessions may be long lived
+	_ = conn.SetDeadline(time.Now().Add(s.dialTimeout))
+
+	sshConn, chans, reqs, err := gossh.NewClientConn(conn, addr, config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	return gossh.NewClient(sshConn, chans, reqs), nil
+}
+
+func (s *NativeSession) hostKeyCallback(host boshdir.Host) func(string, net.Addr, gossh.PublicKey) error {
+	return func(_ string, _ net.Addr, key gossh.PublicKey) error {
+		if len(host.HostPublicKey) == 0 {
+			return bosherr.Errorf("Expected host public key for host '%s' to be known", host.Host)
+		}
+
+		expectedKey, _, _, _, err := gossh.ParseAuthorizedKey([]byte(host.HostPublicKey))
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Parsing host public key for host '%s'", host.Host)
+		}
+
+		if !bytes.Equal(expectedKey.Marshal(), key.Marshal()) {
+			return bosherr.Errorf("Host key verification failed for host '%s'", host.Host)
+		}
+
+		return nil
+	}
+}
+
+func (s *NativeSession) hostAddr(host string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+
+	return net.JoinHostPort(host, nativeSessionDefaultPort)
+}
@@ -0,0 +1,286 @@
+package ssh_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	. "github.com/cloudfoundry/bosh-cli/ssh"
+)
+
+var _ = Describe("NativeSession", func() {
+	var (
+		privKey  string
+		signer   gossh.Signer
+		server   *testSSHServer
+		fs       boshsys.FileSystem
+		connOpts ConnectionOpts
+		result   boshdir.SSHResult
+		host     boshdir.Host
+	)
+
+	BeforeEach(func() {
+		privKey, signer = newTestKey()
+		server = newTestSSHServer(signer.PublicKey())
+
+		fs = boshsys.NewOsFileSystem(boshlog.NewLogger(boshlog.LevelNone))
+
+		connOpts = ConnectionOpts{PrivateKey: privKey}
+
+		host = boshdir.Host{
+			Job:           "job",
+			IndexOrID:     "id",
+			Username:      "user",
+			Host:          server.Addr(),
+			HostPublicKey: server.HostPublicKey(),
+		}
+
+		result = boshdir.SSHResult{Hosts: []boshdir.Host{host}}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	runCmd := func(client *gossh.Client, cmd string) string {
+		sess, err := client.NewSession()
+		Expect(err).ToNot(HaveOccurred())
+
+		defer sess.Close()
+
+		output, err := sess.Output(cmd)
+		Expect(err).ToNot(HaveOccurred())
+
+		return string(output)
+	}
+
+	It("connects to host directly with provided private key", func() {
+		session := NewNativeSession(connOpts, result, fs)
+		Expect(session.Start()).ToNot(HaveOccurred())
+
+		defer session.Finish()
+
+		client, err := session.Dial(host)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(runCmd(client, "echo hello")).To(Equal("hello\n"))
+		Expect(server.Users()).To(Equal([]string{"user"}))
+	})
+
+	It("returns error if host public key does not match", func() {
+		_, otherSigner := newTestKey()
+		host.HostPublicKey = string(gossh.MarshalAuthorizedKey(otherSigner.PublicKey()))
+
+		session := NewNativeSession(connOpts, result, fs)
+		Expect(session.Start()).ToNot(HaveOccurred())
+
+		defer session.Finish()
+
+		_, err := session.Dial(host)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Host key verification failed"))
+	})
+
+	It("returns error if host public key is not known", func() {
+		host.HostPublicKey = ""
+
+		session := NewNativeSession(connOpts, result, fs)
+		Expect(session.Start()).ToNot(HaveOccurred())
+
+		defer session.Finish()
+
+		_, err := session.Dial(host)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Expected host public key"))
+	})
+
+	It("returns error if private key is invalid", func() {
+		connOpts.PrivateKey = "invalid"
+
+		err := NewNativeSession(connOpts, result, fs).Start()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Parsing SSH private key"))
+	})
+
+	It("returns error if raw SSH options are provided", func() {
+		connOpts.RawOpts = []string{"-o", "Opt=val"}
+
+		err := NewNativeSession(connOpts, result, fs).Start()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Built-in SSH client does not support passing through SSH options"))
+	})
+
+	It("does not allow connecting after session is finished", func() {
+		session := NewNativeSession(connOpts, result, fs)
+		Expect(session.Start()).ToNot(HaveOccurred())
+		Expect(session.Finish()).ToNot(HaveOccurred())
+
+		_, err := session.Dial(host)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("SSH session was closed"))
+	})
+
+	Context("when gateway is configured", func() {
+		var (
+			gwServer  *testSSHServer
+			gwPrivKey string
+			gwKeyPath string
+		)
+
+		BeforeEach(func() {
+			var gwSigner gossh.Signer
+			gwPrivKey, gwSigner = newTestKey()
+			gwServer = newTestSSHServer(gwSigner.PublicKey())
+
+			tmpDir, err := fs.TempDir("native-session-test")
+			Expect(err).ToNot(HaveOccurred())
+
+			gwKeyPath = filepath.Join(tmpDir, "gw-key")
+			Expect(fs.WriteFileString(gwKeyPath, gwPrivKey)).ToNot(HaveOccurred())
+
+			result.GatewayUsername = "result-gw-user"
+			result.GatewayHost = "127.0.0.1:1"
+
+			connOpts.GatewayUsername = "gw-user"
+			connOpts.GatewayHost = gwServer.Addr()
+			connOpts.GatewayPrivateKeyPath = gwKeyPath
+		})
+
+		AfterEach(func() {
+			gwServer.Close()
+			_ = fs.RemoveAll(filepath.Dir(gwKeyPath))
+		})
+
+		It("connects to host through gateway", func() {
+			session := NewNativeSession(connOpts, result, fs)
+			Expect(session.Start()).ToNot(HaveOccurred())
+
+			defer session.Finish()
+
+			client, err := session.Dial(host)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(runCmd(client, "echo hello")).To(Equal("hello\n"))
+
+			Expect(gwServer.Users()).To(Equal([]string{"gw-user"}))
+			Expect(gwServer.Forwardings()).To(Equal([]string{server.Addr()}))
+			Expect(server.Users()).To(Equal([]string{"user"}))
+		})
+
+		It("uses gateway settings from the Director unless overridden", func() {
+			result.GatewayHost = gwServer.Addr()
+			connOpts.GatewayUsername = ""
+			connOpts.GatewayHost = ""
+
+			session := NewNativeSession(connOpts, result, fs)
+			Expect(session.Start()).ToNot(HaveOccurred())
+
+			defer session.Finish()
+
+			_, err := session.Dial(host)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(gwServer.Users()).To(Equal([]string{"result-gw-user"}))
+		})
+
+		It("connects directly when gateway is disabled", func() {
+			connOpts.GatewayDisable = true
+
+			session := NewNativeSession(connOpts, result, fs)
+			Expect(session.Start()).ToNot(HaveOccurred())
+
+			defer session.Finish()
+
+			_, err := session.Dial(host)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(gwServer.Forwardings()).To(BeEmpty())
+		})
+
+		It("returns error if gateway private key cannot be read", func() {
+			connOpts.GatewayPrivateKeyPath = gwKeyPath + "-missing"
+
+			err := NewNativeSession(connOpts, result, fs).Start()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Reading gateway private key"))
+		})
+
+		It("authenticates with SSH agent if gateway private key is not provided and closes agent connection when finished", func() {
+			connOpts.GatewayPrivateKeyPath = ""
+
+			rawKey, err := gossh.ParseRawPrivateKey([]byte(gwPrivKey))
+			Expect(err).ToNot(HaveOccurred())
+
+			keyring := agent.NewKeyring()
+			Expect(keyring.Add(rawKey, nil, "gw-key")).ToNot(HaveOccurred())
+
+			agentSockPath := filepath.Join(filepath.Dir(gwKeyPath), "agent.sock")
+
+			listener, err := net.Listen("unix", agentSockPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			defer listener.Close()
+
+			agentServed := make(chan struct{})
+
+			go func() {
+				defer close(agentServed)
+
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+
+				defer conn.Close()
+
+				// Returns once client closes its side of the connection
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+
+			agentSock := os.Getenv("SSH_AUTH_SOCK")
+			os.Setenv("SSH_AUTH_SOCK", agentSockPath)
+			defer os.Setenv("SSH_AUTH_SOCK", agentSock)
+
+			session := NewNativeSession(connOpts, result, fs)
+			Expect(session.Start()).ToNot(HaveOccurred())
+
+			_, err = session.Dial(host)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(gwServer.Users()).To(Equal([]string{"gw-user"}))
+			Consistently(agentServed).ShouldNot(BeClosed())
+
+			Expect(session.Finish()).ToNot(HaveOccurred())
+			Eventually(agentServed).Should(BeClosed())
+		})
+
+		It("returns error if gateway private key is not provided and SSH agent is not available", func() {
+			connOpts.GatewayPrivateKeyPath = ""
+
+			agentSock := os.Getenv("SSH_AUTH_SOCK")
+			os.Unsetenv("SSH_AUTH_SOCK")
+			defer os.Setenv("SSH_AUTH_SOCK", agentSock)
+
+			err := NewNativeSession(connOpts, result, fs).Start()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected gateway private key"))
+		})
+
+		It("returns error if gateway rejects private key", func() {
+			otherPrivKey, _ := newTestKey()
+			Expect(fs.WriteFileString(gwKeyPath, otherPrivKey)).ToNot(HaveOccurred())
+
+			err := NewNativeSession(connOpts, result, fs).Start()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Establishing SSH connection to gateway"))
+		})
+	})
+})
@@ -14,6 +14,11 @@ type Provider struct {
 	streamingSSH ComboRunner
 	resultsSSH   ComboRunner
 	scp          ComboRunner
+
+	nativeStreamingSSH NativeComboRunner
+	nativeResultsSSH   NativeComboRunner
+
+	fs boshsys.FileSystem
 }
 
 func NewProvider(cmdRunner boshsys.CmdRunner, fs boshsys.FileSystem, ui boshui.UI, logger boshlog.Logger) Provider {
@@ -35,7 +40,26 @@ func NewProvider(cmdRunner boshsys.CmdRunner, fs boshsys.FileSystem, ui boshui.U
 
 	scp := NewComboRunner(cmdRunner, scpSessionFactory, signal.Notify, streamingWriter, fs, ui, logger)
 
-	return Provider{streamingSSH: streamingSSH, resultsSSH: resultsSSH, scp: scp}
+	nativeSessionFactory := func(connOpts ConnectionOpts, result boshdir.SSHResult) *NativeSession {
+		return NewNativeSession(connOpts, result, fs)
+	}
+
+	nativeStreamingSSH := NewNativeComboRunner(
+		nativeSessionFactory, signal.Notify, streamingWriter, ui, logger)
+
+	nativeResultsSSH := NewNativeComboRunner(
+		nativeSessionFactory, signal.Notify, NewResultsWriter(ui), ui, logger)
+
+	return Provider{
+		streamingSSH: streamingSSH,
+		resultsSSH:   resultsSSH,
+		scp:          scp,
+
+		nativeStreamingSSH: nativeStreamingSSH,
+		nativeResultsSSH:   nativeResultsSSH,
+
+		fs: fs,
+	}
 }
 
 func (p Provider) NewResultsSSHRunner(interactive bool) Runner {
@@ -50,3 +74,18 @@ func (p Provider) NewSSHRunner(interactive bool) Runner {
 }
 
 func (p Provider) NewSCPRunner() SCPRunner { return NewSCPRunner(p.scp) }
+
+func (p Provider) NewNativeResultsSSHRunner(interactive bool) Runner {
+	return NewNativeNonInteractiveRunner(p.nativeResultsSSH)
+}
+
+func (p Provider) NewNativeSSHRunner(interactive bool) Runner {
+	if interactive {
+		return NewNativeInteractiveRunner(p.nativeStreamingSSH)
+	}
+	return NewNativeNonInteractiveRunner(p.nativeStreamingSSH)
+}
+
+func (p Provider) NewNativeSCPRunner() SCPRunner {
+	return NewNativeSCPRunner(p.nativeStreamingSSH, p.fs)
+}
@@ -161,6 +161,10 @@ func (r SessionImpl) makeKnownHostsFile() (boshsys.File, error) {
 }
 
 func (r SessionImpl) gwOpts(connOpts ConnectionOpts, result boshdir.SSHResult) (string, string, string) {
+	return gatewayOpts(connOpts, result)
+}
+
+func gatewayOpts(connOpts ConnectionOpts, result boshdir.SSHResult) (string, string, string) {
 	if connOpts.GatewayDisable {
 		return "", "", ""
 	}
@@ -26,14 +26,22 @@ type DependencyCompiler interface {
 
 type dependencyCompiler struct {
 	packageCompiler bistatepkg.Compiler
+	workers         int
 
 	logTag string
 	logger boshlog.Logger
 }
 
-func NewDependencyCompiler(packageCompiler bistatepkg.Compiler, logger boshlog.Logger) DependencyCompiler {
+// NewDependencyCompiler compiles up to workers packages at the same time;
+// package compiler must be safe for concurrent use when workers is more than 1.
+func NewDependencyCompiler(packageCompiler bistatepkg.Compiler, workers int, logger boshlog.Logger) DependencyCompiler {
+	if workers < 1 {
+		workers = 1
+	}
+
 	return &dependencyCompiler{
 		packageCompiler: packageCompiler,
+		workers:         workers,
 
 		logTag: "dependencyCompiler",
 		logger: logger,
@@ -58,23 +66,21 @@ func (c *dependencyCompiler) Compile(jobs []bireljob.Job, stage biui.Stage) ([]C
 // resolveJobPackageCompilationDependencies returns all packages required by all specified jobs, in compilation order (reverse dependency order)
 func (c *dependencyCompiler) resolveJobCompilationDependencies(jobs []bireljob.Job) ([]birelpkg.Compilable, error) {
 	// collect and de-dupe all required packages (dependencies of jobs)
+	// in job and dependency order so that the compilation order is stable
 	packageMap := map[string]birelpkg.Compilable{}
+	packages := []birelpkg.Compilable{}
 
 	for _, releaseJob := range jobs {
 		for _, releasePackage := range releaseJob.Packages {
 			pkgKey := c.pkgKey(releasePackage)
-			packageMap[pkgKey] = releasePackage
-			c.resolvePackageDependencies(releasePackage, packageMap)
+			if _, found := packageMap[pkgKey]; !found {
+				packageMap[pkgKey] = releasePackage
+				packages = append(packages, releasePackage)
+			}
+			packages = c.resolvePackageDependencies(releasePackage, packageMap, packages)
 		}
 	}
 
-	// flatten map values to array
-	packages := make([]birelpkg.Compilable, 0, len(packageMap))
-
-	for _, releasePackage := range packageMap {
-		packages = append(packages, releasePackage)
-	}
-
 	// sort in compilation order
 	sortedPackages, err := birelpkg.Sort(packages)
 	if err != nil {
@@ -92,51 +98,184 @@ func (c *dependencyCompiler) resolveJobCompilationDependencies(jobs []bireljob.J
 	return sortedPackages, nil
 }
 
-// resolvePackageDependencies adds the releasePackage's dependencies to the packageMap recursively
-func (c *dependencyCompiler) resolvePackageDependencies(releasePackage birelpkg.Compilable, packageMap map[string]birelpkg.Compilable) {
+// resolvePackageDependencies adds the releasePackage's dependencies to the packageMap and packages recursively
+func (c *dependencyCompiler) resolvePackageDependencies(releasePackage birelpkg.Compilable, packageMap map[string]birelpkg.Compilable, packages []birelpkg.Compilable) []birelpkg.Compilable {
 	for _, dependency := range releasePackage.Deps() {
 		// only add un-added packages, to avoid endless looping in case of cycles
 		pkgKey := c.pkgKey(dependency)
 		if _, found := packageMap[pkgKey]; !found {
 			packageMap[pkgKey] = dependency
-			c.resolvePackageDependencies(dependency, packageMap)
+			packages = append(packages, dependency)
+			packages = c.resolvePackageDependencies(dependency, packageMap, packages)
 		}
 	}
+
+	return packages
 }
 
 // compilePackages compiles the specified packages, in the order specified, uploads them to the Blobstore, and returns the blob references
 func (c *dependencyCompiler) compilePackages(requiredPackages []birelpkg.Compilable, stage biui.Stage) ([]CompiledPackageRef, error) {
+	if c.workers > 1 {
+		return c.compilePackagesConcurrently(requiredPackages, stage.Concurrent())
+	}
+
 	packageRefs := make([]CompiledPackageRef, 0, len(requiredPackages))
 
 	for _, pkg := range requiredPackages {
-		stepName := fmt.Sprintf("Compiling package '%s/%s'", pkg.Name(), pkg.Fingerprint())
+		packageRef, err := c.compilePackage(pkg, stage)
+		if err != nil {
+			return nil, err
+		}
 
-		err := stage.Perform(stepName, func() error {
-			compiledPackageRecord, isAlreadyCompiled, err := c.packageCompiler.Compile(pkg)
-			if err != nil {
-				return err
-			}
+		packageRefs = append(packageRefs, packageRef)
+	}
+
+	return packageRefs, nil
+}
 
-			packageRef := CompiledPackageRef{
-				Name:        pkg.Name(),
-				Version:     pkg.Fingerprint(),
-				BlobstoreID: compiledPackageRecord.BlobID,
-				SHA1:        compiledPackageRecord.BlobSHA1,
+type compiledPackageResult struct {
+	index int
+	ref   CompiledPackageRef
+	err   error
+}
+
+// compilePackagesConcurrently compiles each package as soon as all of its dependencies are compiled.
+// Once any package fails to compile no more packages are started, and packages in progress are awaited.
+func (c *dependencyCompiler) compilePackagesConcurrently(requiredPackages []birelpkg.Compilable, stage biui.Stage) ([]CompiledPackageRef, error) {
+	packageRefs := make([]CompiledPackageRef, len(requiredPackages))
+
+	indices := map[string]int{}
+	remainingDeps := make([]int, len(requiredPackages))
+	dependents := make([][]int, len(requiredPackages))
+
+	for i, pkg := range requiredPackages {
+		indices[c.pkgKey(pkg)] = i
+	}
+
+	for i, pkg := range requiredPackages {
+		for _, dep := range pkg.Deps() {
+			depIndex, found := indices[c.pkgKey(dep)]
+			if !found {
+				return nil, bosherr.Errorf("Expected package '%s' required by '%s' to be resolved", dep.Name(), pkg.Name())
 			}
-			packageRefs = append(packageRefs, packageRef)
 
-			if isAlreadyCompiled {
-				return biui.NewSkipStageError(bosherr.Error(fmt.Sprintf("Package '%s' is already compiled. Skipped compilation", pkg.Name())), "Package already compiled")
+			remainingDeps[i]++
+			dependents[depIndex] = append(dependents[depIndex], i)
+		}
+	}
+
+	ready := []int{}
+
+	for i := range requiredPackages {
+		if remainingDeps[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	results := make(chan compiledPackageResult)
+	running := 0
+	compiled := 0
+	errs := []error{}
+
+	for {
+		for len(errs) == 0 && len(ready) > 0 && running < c.workers {
+			i := ready[0]
+			ready = ready[1:]
+			running++
+
+			go func(i int) {
+				ref, err := c.compilePackage(requiredPackages[i], stage)
+				results <- compiledPackageResult{index: i, ref: ref, err: err}
+			}(i)
+		}
+
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		running--
+
+		if result.err != nil {
+			errs = append(errs, bosherr.WrapErrorf(result.err, "Compiling package branch %s",
+				c.dependencyBranch(result.index, requiredPackages, dependents)))
+			continue
+		}
+
+		packageRefs[result.index] = result.ref
+		compiled++
+
+		// Keep compilation order stable by starting packages in sorted order
+		for _, dependentIndex := range dependents[result.index] {
+			remainingDeps[dependentIndex]--
+			if remainingDeps[dependentIndex] == 0 {
+				ready = c.insertSorted(ready, dependentIndex)
 			}
+		}
+	}
 
-			return nil
-		})
+	if len(errs) > 0 {
+		c.logger.Debug(c.logTag, "Skipped compiling %d package(s) after compilation failure", len(requiredPackages)-compiled-len(errs))
+		return nil, bosherr.NewMultiError(errs...)
+	}
+
+	return packageRefs, nil
+}
+
+func (c *dependencyCompiler) compilePackage(pkg birelpkg.Compilable, stage biui.Stage) (CompiledPackageRef, error) {
+	var packageRef CompiledPackageRef
+
+	stepName := fmt.Sprintf("Compiling package '%s/%s'", pkg.Name(), pkg.Fingerprint())
+
+	err := stage.Perform(stepName, func() error {
+		compiledPackageRecord, isAlreadyCompiled, err := c.packageCompiler.Compile(pkg)
 		if err != nil {
-			return nil, err
+			return err
+		}
+
+		packageRef = CompiledPackageRef{
+			Name:        pkg.Name(),
+			Version:     pkg.Fingerprint(),
+			BlobstoreID: compiledPackageRecord.BlobID,
+			SHA1:        compiledPackageRecord.BlobSHA1,
+		}
+
+		if isAlreadyCompiled {
+			return biui.NewSkipStageError(bosherr.Error(fmt.Sprintf("Package '%s' is already compiled. Skipped compilation", pkg.Name())), "Package already compiled")
 		}
+
+		return nil
+	})
+
+	return packageRef, err
+}
+
+// dependencyBranch describes path from a failed package to a package that is not required by others,
+// e.g. 'libyaml' <- 'ruby' <- 'cpi'
+func (c *dependencyCompiler) dependencyBranch(index int, requiredPackages []birelpkg.Compilable, dependents [][]int) string {
+	names := []string{}
+
+	for {
+		names = append(names, fmt.Sprintf("'%s'", requiredPackages[index].Name()))
+
+		if len(dependents[index]) == 0 {
+			break
+		}
+
+		index = dependents[index][0]
 	}
 
-	return packageRefs, nil
+	return strings.Join(names, " <- ")
+}
+
+func (c *dependencyCompiler) insertSorted(indices []int, index int) []int {
+	for i, existing := range indices {
+		if index < existing {
+			return append(indices[:i], append([]int{index}, indices[i:]...)...)
+		}
+	}
+
+	return append(indices, index)
 }
 
 func (c *dependencyCompiler) pkgKey(pkg birelpkg.Compilable) string { return pkg.Name() }
@@ -1,6 +1,9 @@
 package job_test
 
 import (
+	"errors"
+	"time"
+
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
@@ -47,7 +50,7 @@ var _ = Describe("DependencyCompiler", func() {
 		mockPackageCompiler = mock_state_package.NewMockCompiler(mockCtrl)
 
 		logger = boshlog.NewLogger(boshlog.LevelNone)
-		dependencyCompiler = NewDependencyCompiler(mockPackageCompiler, logger)
+		dependencyCompiler = NewDependencyCompiler(mockPackageCompiler, 1, logger)
 
 		stage = fakeui.NewFakeStage()
 
@@ -215,4 +218,97 @@ var _ = Describe("DependencyCompiler", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 	})
+
+	Context("when compiling with multiple workers", func() {
+		var (
+			pkg3 *boshrelpkg.Package
+		)
+
+		BeforeEach(func() {
+			dependencyCompiler = NewDependencyCompiler(mockPackageCompiler, 2, logger)
+
+			pkg3 = newPkg("pkg3-name", "pkg3-fp", nil)
+
+			job.PackageNames = append(job.PackageNames, pkg3.Name())
+			job.AttachPackages([]*boshrelpkg.Package{pkg2, pkg3})
+			jobs = []boshreljob.Job{*job}
+		})
+
+		It("compiles packages in concurrent stage", func() {
+			mockPackageCompiler.EXPECT().Compile(pkg3).Return(bistatepkg.CompiledPackageRecord{}, false, nil)
+
+			_, err := dependencyCompiler.Compile(jobs, stage)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(stage.ConcurrentCalled).To(BeTrue())
+			Expect(stage.PerformCalls).To(HaveLen(3))
+		})
+
+		It("compiles packages without dependencies on each other at the same time", func() {
+			pkg3Started := make(chan struct{})
+
+			mockPackageCompiler.EXPECT().Compile(pkg3).Do(func(_ *boshrelpkg.Package) {
+				close(pkg3Started)
+			}).Return(bistatepkg.CompiledPackageRecord{}, false, nil)
+
+			expectCompilePkg1.Times(1).Do(func(_ *boshrelpkg.Package) {
+				select {
+				case <-pkg3Started:
+				case <-time.After(5 * time.Second):
+					Fail("Expected pkg3 to be compiled while pkg1 is compiling")
+				}
+			})
+			expectCompilePkg2.After(expectCompilePkg1)
+
+			_, err := dependencyCompiler.Compile(jobs, stage)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns references to the compiled packages in the same order as when compiling sequentially", func() {
+			mockPackageCompiler.EXPECT().Compile(pkg3).Return(bistatepkg.CompiledPackageRecord{
+				BlobID:   "fake-compiled-package-blobstore-id-3",
+				BlobSHA1: "fake-compiled-package-sha1-3",
+			}, false, nil)
+
+			compiledPackageRefs, err := dependencyCompiler.Compile(jobs, stage)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(compiledPackageRefs).To(Equal([]CompiledPackageRef{
+				{
+					Name:        "pkg1-name",
+					Version:     "pkg1-fp",
+					BlobstoreID: "fake-compiled-package-blobstore-id-1",
+					SHA1:        "fake-compiled-package-sha1-1",
+				},
+				{
+					Name:        "pkg3-name",
+					Version:     "pkg3-fp",
+					BlobstoreID: "fake-compiled-package-blobstore-id-3",
+					SHA1:        "fake-compiled-package-sha1-3",
+				},
+				{
+					Name:        "pkg2-name",
+					Version:     "pkg2-fp",
+					BlobstoreID: "fake-compiled-package-blobstore-id-2",
+					SHA1:        "fake-compiled-package-sha1-2",
+				},
+			}))
+		})
+
+		Context("when compiling a package fails", func() {
+			BeforeEach(func() {
+				mockPackageCompiler.EXPECT().Compile(pkg1).Return(bistatepkg.CompiledPackageRecord{}, false, errors.New("fake-compile-err"))
+				mockPackageCompiler.EXPECT().Compile(pkg3).Return(bistatepkg.CompiledPackageRecord{}, false, nil).AnyTimes()
+			})
+
+			It("does not compile packages depending on it and returns failed dependency branch", func() {
+				expectCompilePkg2.Times(0)
+
+				_, err := dependencyCompiler.Compile(jobs, stage)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Compiling package branch 'pkg1-name' <- 'pkg2-name'"))
+				Expect(err.Error()).To(ContainSubstring("fake-compile-err"))
+			})
+		})
+	})
 })
@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -15,9 +16,18 @@ type CompiledPackageRecord struct {
 	BlobSHA1 string
 }
 
+type CompiledPackageEntry struct {
+	Key    CompiledPackageKey
+	Record CompiledPackageRecord
+}
+
 type CompiledPackageRepo interface {
 	Save(birelpkg.Compilable, CompiledPackageRecord) error
 	Find(birelpkg.Compilable) (CompiledPackageRecord, bool, error)
+
+	// All and SaveEntry allow to move compiled packages between installations
+	All() ([]CompiledPackageEntry, error)
+	SaveEntry(CompiledPackageEntry) error
 }
 
 type compiledPackageRepo struct {
@@ -53,7 +63,43 @@ func (cpr *compiledPackageRepo) Find(pkg birelpkg.Compilable) (CompiledPackageRe
 	return record, true, nil
 }
 
-type packageToCompiledPackageKey struct {
+func (cpr *compiledPackageRepo) All() ([]CompiledPackageEntry, error) {
+	var entries []CompiledPackageEntry
+
+	indexEntries, err := cpr.index.Entries()
+	if err != nil {
+		return entries, bosherr.WrapError(err, "Listing compiled packages")
+	}
+
+	for _, indexEntry := range indexEntries {
+		var entry CompiledPackageEntry
+
+		err := json.Unmarshal(indexEntry.Key, &entry.Key)
+		if err != nil {
+			return entries, bosherr.WrapError(err, "Unmarshalling compiled package key")
+		}
+
+		err = json.Unmarshal(indexEntry.Value, &entry.Record)
+		if err != nil {
+			return entries, bosherr.WrapError(err, "Unmarshalling compiled package record")
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (cpr *compiledPackageRepo) SaveEntry(entry CompiledPackageEntry) error {
+	err := cpr.index.Save(entry.Key, entry.Record)
+	if err != nil {
+		return bosherr.WrapError(err, "Saving compiled package")
+	}
+
+	return nil
+}
+
+type CompiledPackageKey struct {
 	PackageName string
 	// Fingerprint of a package captures the sorted names of its dependencies
 	// (but not the dependencies' fingerprints)
@@ -61,8 +107,8 @@ type packageToCompiledPackageKey struct {
 	DependencyKey      string
 }
 
-func (cpr compiledPackageRepo) pkgKey(pkg birelpkg.Compilable) packageToCompiledPackageKey {
-	return packageToCompiledPackageKey{
+func (cpr compiledPackageRepo) pkgKey(pkg birelpkg.Compilable) CompiledPackageKey {
+	return CompiledPackageKey{
 		PackageName:        pkg.Name(),
 		PackageFingerprint: pkg.Fingerprint(),
 		DependencyKey:      cpr.convertToDependencyKey(ResolveDependencies(pkg)),
@@ -148,4 +148,59 @@ var _ = Describe("CompiledPackageRepo", func() {
 			Expect(err.Error()).To(ContainSubstring("Finding compiled package"))
 		})
 	})
+	Context("All/SaveEntry", func() {
+		It("lists saved compiled packages with their keys", func() {
+			dependency := newPkg("dep-name", "dep-fp", nil)
+			pkg := newPkg("pkg-name", "pkg-fp", []string{"dep-name"})
+			pkg.AttachDependencies([]*boshrelpkg.Package{dependency})
+
+			record := CompiledPackageRecord{BlobID: "fake-blob-id", BlobSHA1: "fake-sha1"}
+
+			err := compiledPackageRepo.Save(pkg, record)
+			Expect(err).ToNot(HaveOccurred())
+
+			entries, err := compiledPackageRepo.All()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(Equal([]CompiledPackageEntry{
+				{
+					Key: CompiledPackageKey{
+						PackageName:        "pkg-name",
+						PackageFingerprint: "pkg-fp",
+						DependencyKey:      "dep-name:dep-fp",
+					},
+					Record: record,
+				},
+			}))
+		})
+
+		It("saves entries so that they can be found by package", func() {
+			entry := CompiledPackageEntry{
+				Key: CompiledPackageKey{
+					PackageName:        "pkg-name",
+					PackageFingerprint: "pkg-fp",
+					DependencyKey:      "",
+				},
+				Record: CompiledPackageRecord{BlobID: "fake-blob-id", BlobSHA1: "fake-sha1"},
+			}
+
+			err := compiledPackageRepo.SaveEntry(entry)
+			Expect(err).ToNot(HaveOccurred())
+
+			result, found, err := compiledPackageRepo.Find(newPkg("pkg-name", "pkg-fp", nil))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(result).To(Equal(entry.Record))
+		})
+
+		It("returns error when reading from index fails", func() {
+			err := compiledPackageRepo.Save(newPkg("pkg-name", "pkg-fp", nil), CompiledPackageRecord{})
+			Expect(err).ToNot(HaveOccurred())
+
+			fs.ReadFileError = errors.New("fake-error")
+
+			_, err = compiledPackageRepo.All()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Listing compiled packages"))
+		})
+	})
 })
@@ -63,6 +63,17 @@ func (_m *MockCompiledPackageRepo) EXPECT() *_MockCompiledPackageRepoRecorder {
 	return _m.recorder
 }
 
+func (_m *MockCompiledPackageRepo) All() ([]pkg0.CompiledPackageEntry, error) {
+	ret := _m.ctrl.Call(_m, "All")
+	ret0, _ := ret[0].([]pkg0.CompiledPackageEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockCompiledPackageRepoRecorder) All() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "All")
+}
+
 func (_m *MockCompiledPackageRepo) Find(_param0 pkg.Compilable) (pkg0.CompiledPackageRecord, bool, error) {
 	ret := _m.ctrl.Call(_m, "Find", _param0)
 	ret0, _ := ret[0].(pkg0.CompiledPackageRecord)
@@ -84,3 +95,13 @@ func (_m *MockCompiledPackageRepo) Save(_param0 pkg.Compilable, _param1 pkg0.Com
 func (_mr *_MockCompiledPackageRepoRecorder) Save(arg0, arg1 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Save", arg0, arg1)
 }
+
+func (_m *MockCompiledPackageRepo) SaveEntry(_param0 pkg0.CompiledPackageEntry) error {
+	ret := _m.ctrl.Call(_m, "SaveEntry", _param0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockCompiledPackageRepoRecorder) SaveEntry(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SaveEntry", arg0)
+}
@@ -0,0 +1,51 @@
+package erbrenderer
+
+import (
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// compatERBRenderer renders templates in-process and only falls back to Ruby
+// for templates that use syntax the Go renderer does not understand.
+type compatERBRenderer struct {
+	goRenderer   ERBRenderer
+	rubyRenderer ERBRenderer
+	logger       boshlog.Logger
+	logTag       string
+}
+
+func NewCompatERBRenderer(
+	fs boshsys.FileSystem,
+	runner boshsys.CmdRunner,
+	logger boshlog.Logger,
+) ERBRenderer {
+	return NewCompatERBRendererWithRenderers(
+		NewGoERBRenderer(fs, logger),
+		NewERBRenderer(fs, runner, logger),
+		logger,
+	)
+}
+
+func NewCompatERBRendererWithRenderers(goRenderer, rubyRenderer ERBRenderer, logger boshlog.Logger) ERBRenderer {
+	return compatERBRenderer{
+		goRenderer:   goRenderer,
+		rubyRenderer: rubyRenderer,
+		logger:       logger,
+		logTag:       "compatERBRenderer",
+	}
+}
+
+func (r compatERBRenderer) Render(srcPath, dstPath string, context TemplateEvaluationContext) error {
+	err := r.goRenderer.Render(srcPath, dstPath, context)
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(UnsupportedTemplateError); !ok {
+		return err
+	}
+
+	r.logger.Debug(r.logTag, "Falling back to ruby: %s", err.Error())
+
+	return r.rubyRenderer.Render(srcPath, dstPath, context)
+}
@@ -0,0 +1,88 @@
+package erbrenderer_test
+
+import (
+	"errors"
+
+	. "github.com/cloudfoundry/bosh-cli/templatescompiler/erbrenderer"
+	fakebierbrenderer "github.com/cloudfoundry/bosh-cli/templatescompiler/erbrenderer/fakes"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CompatERBRenderer", func() {
+	var (
+		fs          *fakesys.FakeFileSystem
+		runner      *fakesys.FakeCmdRunner
+		erbRenderer ERBRenderer
+		context     *fakebierbrenderer.FakeTemplateEvaluationContext
+	)
+
+	BeforeEach(func() {
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		fs = fakesys.NewFakeFileSystem()
+		runner = fakesys.NewFakeCmdRunner()
+		context = &fakebierbrenderer.FakeTemplateEvaluationContext{}
+
+		erbRenderer = NewCompatERBRenderer(fs, runner, logger)
+		fs.TempDirDir = "fake-temp-dir"
+	})
+
+	It("renders supported templates without running ruby", func() {
+		err := fs.WriteFileString("fake-src-path", "<%= 1 + 1 %>")
+		Expect(err).ToNot(HaveOccurred())
+
+		err = erbRenderer.Render("fake-src-path", "fake-dst-path", context)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fs.ReadFileString("fake-dst-path")).To(Equal("2"))
+		Expect(runner.RunComplexCommands).To(BeEmpty())
+	})
+
+	It("falls back to ruby for unsupported templates", func() {
+		err := fs.WriteFileString("fake-src-path", "<%= Time.now %>")
+		Expect(err).ToNot(HaveOccurred())
+
+		err = erbRenderer.Render("fake-src-path", "fake-dst-path", context)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(runner.RunComplexCommands).To(Equal([]boshsys.Command{
+			boshsys.Command{
+				Name: "ruby",
+				Args: []string{
+					"fake-temp-dir/erb-render.rb",
+					"fake-temp-dir/erb-context.json",
+					"fake-src-path",
+					"fake-dst-path",
+				},
+			},
+		}))
+	})
+
+	It("does not fall back to ruby when template raises an error", func() {
+		err := fs.WriteFileString("fake-src-path", `<%= p("missing") %>`)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = erbRenderer.Render("fake-src-path", "fake-dst-path", context)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Can't find property 'missing'"))
+
+		Expect(runner.RunComplexCommands).To(BeEmpty())
+	})
+
+	It("returns an error when ruby fallback fails", func() {
+		err := fs.WriteFileString("fake-src-path", "<%= Time.now %>")
+		Expect(err).ToNot(HaveOccurred())
+
+		runner.AddCmdResult(
+			"ruby fake-temp-dir/erb-render.rb fake-temp-dir/erb-context.json fake-src-path fake-dst-path",
+			fakesys.FakeCmdResult{Error: errors.New("fake-cmd-error")},
+		)
+
+		err = erbRenderer.Render("fake-src-path", "fake-dst-path", context)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-cmd-error"))
+	})
+})
@@ -0,0 +1,609 @@
+package erbrenderer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// erbTemplate is a parsed ERB template limited to the subset of Ruby used by job templates
+type erbTemplate struct {
+	body []erbNode
+}
+
+type erbNode interface{}
+
+type erbTextNode struct {
+	text string
+}
+
+type erbOutputNode struct {
+	expr rbExpr
+	line int
+}
+
+type erbStatementNode struct {
+	assignTo string // empty for plain expression statements
+	expr     rbExpr
+	line     int
+}
+
+type erbIfNode struct {
+	branches []erbIfBranch
+	elseBody []erbNode
+}
+
+type erbIfBranch struct {
+	cond   rbExpr
+	negate bool
+	body   []erbNode
+	line   int
+}
+
+// erbBlockNode is a method call with a do...end block, e.g. p("a").each do |x|
+type erbBlockNode struct {
+	call   rbCallExpr
+	params []string
+	body   []erbNode
+	line   int
+
+	// elseChain is set for if_p(...) do ... end.else ... blocks
+	elseChain *erbElseNode
+}
+
+type erbElseNode struct {
+	line int
+
+	// Exactly one is set: body for end.else, ifP for end.else_if_p
+	body []erbNode
+	ifP  *erbBlockNode
+}
+
+type erbUnsupportedError struct {
+	reason string
+	line   int
+}
+
+func newUnsupportedSyntaxError(format string, args ...interface{}) *erbUnsupportedError {
+	return &erbUnsupportedError{reason: fmt.Sprintf(format, args...)}
+}
+
+func (e *erbUnsupportedError) Error() string {
+	if e.line > 0 {
+		return fmt.Sprintf("Unsupported template syntax on line %d: %s", e.line, e.reason)
+	}
+	return fmt.Sprintf("Unsupported template syntax: %s", e.reason)
+}
+
+type erbTagKind int
+
+const (
+	erbTagText erbTagKind = iota
+	erbTagCode
+	erbTagOutput
+)
+
+type erbSegment struct {
+	kind erbTagKind
+	text string
+	line int
+}
+
+// erbItem is a single statement flattened out of template tags before nesting is resolved
+type erbItem struct {
+	kind   string
+	node   erbNode
+	cond   rbExpr
+	negate bool
+	call   rbCallExpr
+	params []string
+	line   int
+}
+
+const (
+	erbItemNode       = "node"
+	erbItemIf         = "if"
+	erbItemElsif      = "elsif"
+	erbItemElse       = "else"
+	erbItemEnd        = "end"
+	erbItemBlock      = "block"
+	erbItemEndElse    = "end.else"
+	erbItemEndElseIfP = "end.else_if_p"
+)
+
+func parseERBTemplate(src string) (*erbTemplate, error) {
+	segments, err := scanERBSegments(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []erbItem
+
+	for _, segment := range segments {
+		segmentItems, err := parseERBSegment(segment)
+		if err != nil {
+			if unsupportedErr, ok := err.(*erbUnsupportedError); ok && unsupportedErr.line == 0 {
+				unsupportedErr.line = segment.line
+			}
+			return nil, err
+		}
+
+		items = append(items, segmentItems...)
+	}
+
+	b := &erbTreeBuilder{items: items}
+
+	body, closer, err := b.build()
+	if err != nil {
+		return nil, err
+	}
+
+	if closer != nil {
+		return nil, &erbUnsupportedError{reason: fmt.Sprintf("unexpected '%s'", closer.kind), line: closer.line}
+	}
+
+	return &erbTemplate{body: body}, nil
+}
+
+// scanERBSegments splits a template into text, code and output segments.
+// Templates are evaluated without a trim mode, the same way the Ruby renderer does.
+func scanERBSegments(src string) ([]erbSegment, error) {
+	var segments []erbSegment
+	var text strings.Builder
+
+	line := 1
+	textLine := 1
+
+	for len(src) > 0 {
+		start := strings.Index(src, "<%")
+		if start == -1 {
+			text.WriteString(src)
+			break
+		}
+
+		text.WriteString(src[:start])
+		line += strings.Count(src[:start], "\n")
+		src = src[start:]
+
+		if strings.HasPrefix(src, "<%%") {
+			text.WriteString("<%")
+			src = src[3:]
+			continue
+		}
+
+		if text.Len() > 0 {
+			segments = append(segments, erbSegment{kind: erbTagText, text: text.String(), line: textLine})
+			text.Reset()
+		}
+
+		end := strings.Index(src, "%>")
+		if end == -1 {
+			return nil, &erbUnsupportedError{reason: "unterminated ERB tag", line: line}
+		}
+
+		tag := src[2:end]
+		src = src[end+2:]
+
+		kind := erbTagCode
+
+		switch {
+		case strings.HasPrefix(tag, "#"):
+			kind = erbTagText
+			tag = ""
+		case strings.HasPrefix(tag, "="):
+			kind = erbTagOutput
+			tag = tag[1:]
+		case strings.HasPrefix(tag, "-") || strings.HasSuffix(tag, "-"):
+			return nil, &erbUnsupportedError{reason: "trim mode tags '<%-' and '-%>'", line: line}
+		}
+
+		if kind != erbTagText {
+			segments = append(segments, erbSegment{kind: kind, text: tag, line: line})
+		}
+
+		line += strings.Count(tag, "\n")
+		textLine = line
+	}
+
+	if text.Len() > 0 {
+		segments = append(segments, erbSegment{kind: erbTagText, text: text.String(), line: textLine})
+	}
+
+	return segments, nil
+}
+
+func parseERBSegment(segment erbSegment) ([]erbItem, error) {
+	if segment.kind == erbTagText {
+		return []erbItem{{kind: erbItemNode, node: erbTextNode{text: segment.text}, line: segment.line}}, nil
+	}
+
+	tokens, err := lexRuby(segment.text)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &erbStatementParser{rbParser: newRbParser(tokens), line: segment.line}
+
+	if segment.kind == erbTagOutput {
+		p.skipNewlines()
+
+		expr, err := p.parseModifiedExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipNewlines()
+
+		if !p.at(rbTokenEOF) {
+			return nil, p.unexpected()
+		}
+
+		return []erbItem{{kind: erbItemNode, node: erbOutputNode{expr: expr, line: segment.line}, line: segment.line}}, nil
+	}
+
+	var items []erbItem
+
+	for {
+		p.skipSeparators()
+
+		if p.at(rbTokenEOF) {
+			return items, nil
+		}
+
+		item, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+
+		// Openers such as 'if x then' or 'do |y|' may be followed by more statements on the same line,
+		// and single line blocks such as 'if x then y end' close without a separator
+		requiresSeparator := item.kind == erbItemNode || item.kind == erbItemEnd
+		closesBlock := p.isIdent("end") || p.isIdent("else") || p.isIdent("elsif")
+		if requiresSeparator && !closesBlock && !p.at(rbTokenEOF) && !p.at(rbTokenNewline) && !p.isOp(";") {
+			return nil, p.unexpected()
+		}
+	}
+}
+
+type erbStatementParser struct {
+	*rbParser
+	line int
+}
+
+func (p *erbStatementParser) skipSeparators() {
+	for p.at(rbTokenNewline) || p.isOp(";") {
+		if p.at(rbTokenNewline) {
+			p.line++
+		}
+		p.advance()
+	}
+}
+
+func (p *erbStatementParser) parseStatement() (erbItem, error) {
+	line := p.line
+
+	switch {
+	case p.isIdent("end"):
+		p.advance()
+		return p.parseEnd(line)
+
+	case p.isIdent("else"):
+		p.advance()
+		return erbItem{kind: erbItemElse, line: line}, nil
+
+	case p.isIdent("if") || p.isIdent("unless") || p.isIdent("elsif"):
+		kind := erbItemIf
+		if p.peek().text == "elsif" {
+			kind = erbItemElsif
+		}
+
+		negate := p.advance().text == "unless"
+
+		cond, err := p.parseExpr()
+		if err != nil {
+			return erbItem{}, err
+		}
+
+		if p.isIdent("then") {
+			p.advance()
+		}
+
+		return erbItem{kind: kind, cond: cond, negate: negate, line: line}, nil
+	}
+
+	if p.at(rbTokenIdent) && p.tokens[p.pos+1].kind == rbTokenOp && p.tokens[p.pos+1].text == "=" {
+		name := p.advance().text
+		p.advance()
+
+		expr, err := p.parseModifiedExpr()
+		if err != nil {
+			return erbItem{}, err
+		}
+
+		node := erbStatementNode{assignTo: name, expr: expr, line: line}
+
+		return erbItem{kind: erbItemNode, node: node, line: line}, nil
+	}
+
+	call, params, isBlock, err := p.parseBlockCall()
+	if err != nil {
+		return erbItem{}, err
+	}
+
+	if isBlock {
+		return erbItem{kind: erbItemBlock, call: call, params: params, line: line}, nil
+	}
+
+	expr, err := p.parseModifiers(call.receiver)
+	if err != nil {
+		return erbItem{}, err
+	}
+
+	return erbItem{kind: erbItemNode, node: erbStatementNode{expr: expr, line: line}, line: line}, nil
+}
+
+// parseEnd parses 'end' optionally followed by '.else do' or '.else_if_p(...) do |...|'
+func (p *erbStatementParser) parseEnd(line int) (erbItem, error) {
+	if !p.isOp(".") {
+		return erbItem{kind: erbItemEnd, line: line}, nil
+	}
+
+	p.advance()
+
+	switch {
+	case p.isIdent("else"):
+		p.advance()
+
+		if !p.isIdent("do") {
+			return erbItem{}, p.unexpected()
+		}
+
+		p.advance()
+
+		return erbItem{kind: erbItemEndElse, line: line}, nil
+
+	case p.isIdent("else_if_p"):
+		p.advance()
+
+		args, err := p.parseArgs()
+		if err != nil {
+			return erbItem{}, err
+		}
+
+		params, err := p.parseDoBlockOpening()
+		if err != nil {
+			return erbItem{}, err
+		}
+
+		call := rbCallExpr{method: "if_p", args: args}
+
+		return erbItem{kind: erbItemEndElseIfP, call: call, params: params, line: line}, nil
+	}
+
+	return erbItem{}, p.unexpected()
+}
+
+// parseBlockCall parses an expression and reports whether it opens a do...end block.
+// Non-block expressions are returned as the receiver of an empty call.
+func (p *erbStatementParser) parseBlockCall() (rbCallExpr, []string, bool, error) {
+	if p.isIdent("if_p") || p.isIdent("if_link") {
+		method := p.advance().text
+
+		if !p.isOp("(") {
+			return rbCallExpr{}, nil, false, p.unexpected()
+		}
+
+		args, err := p.parseArgs()
+		if err != nil {
+			return rbCallExpr{}, nil, false, err
+		}
+
+		params, err := p.parseDoBlockOpening()
+		if err != nil {
+			return rbCallExpr{}, nil, false, err
+		}
+
+		return rbCallExpr{method: method, args: args}, params, true, nil
+	}
+
+	expr, err := p.parseExpr()
+	if err != nil {
+		return rbCallExpr{}, nil, false, err
+	}
+
+	if !p.isIdent("do") {
+		return rbCallExpr{receiver: expr}, nil, false, nil
+	}
+
+	call, ok := expr.(rbCallExpr)
+	if !ok || call.block != nil {
+		return rbCallExpr{}, nil, false, p.unexpected()
+	}
+
+	switch call.method {
+	case "each", "each_with_index", "each_pair", "times":
+	default:
+		return rbCallExpr{}, nil, false, newUnsupportedSyntaxError("do block for method '%s'", call.method)
+	}
+
+	params, err := p.parseDoBlockOpening()
+	if err != nil {
+		return rbCallExpr{}, nil, false, err
+	}
+
+	return call, params, true, nil
+}
+
+func (p *erbStatementParser) parseDoBlockOpening() ([]string, error) {
+	if !p.isIdent("do") {
+		return nil, p.unexpected()
+	}
+
+	p.advance()
+
+	return p.parseBlockParams()
+}
+
+// parseModifiedExpr parses an expression with optional trailing 'if'/'unless' modifiers
+func (p *erbStatementParser) parseModifiedExpr() (rbExpr, error) {
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.parseModifiers(expr)
+}
+
+func (p *erbStatementParser) parseModifiers(expr rbExpr) (rbExpr, error) {
+	for p.isIdent("if") || p.isIdent("unless") {
+		negate := p.advance().text == "unless"
+
+		cond, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		if negate {
+			cond = rbUnaryExpr{op: "!", operand: cond}
+		}
+
+		expr = rbTernaryExpr{cond: cond, ifTrue: expr, ifFalse: rbLiteralExpr{value: nil}}
+	}
+
+	return expr, nil
+}
+
+type erbTreeBuilder struct {
+	items []erbItem
+	pos   int
+}
+
+// build nests items until a closing item (elsif, else, end...) is found and returns that closer
+func (b *erbTreeBuilder) build() ([]erbNode, *erbItem, error) {
+	var body []erbNode
+
+	for b.pos < len(b.items) {
+		item := b.items[b.pos]
+		b.pos++
+
+		switch item.kind {
+		case erbItemNode:
+			body = append(body, item.node)
+
+		case erbItemIf:
+			node, err := b.buildIf(item)
+			if err != nil {
+				return nil, nil, err
+			}
+			body = append(body, node)
+
+		case erbItemBlock:
+			node, err := b.buildBlock(item)
+			if err != nil {
+				return nil, nil, err
+			}
+			body = append(body, node)
+
+		default:
+			return body, &item, nil
+		}
+	}
+
+	return body, nil, nil
+}
+
+func (b *erbTreeBuilder) buildIf(item erbItem) (erbNode, error) {
+	node := erbIfNode{}
+	branch := erbIfBranch{cond: item.cond, negate: item.negate, line: item.line}
+
+	for {
+		body, closer, err := b.build()
+		if err != nil {
+			return nil, err
+		}
+
+		if closer == nil {
+			return nil, &erbUnsupportedError{reason: "'if' without matching 'end'", line: item.line}
+		}
+
+		switch closer.kind {
+		case erbItemElsif:
+			branch.body = body
+			node.branches = append(node.branches, branch)
+			branch = erbIfBranch{cond: closer.cond, negate: closer.negate, line: closer.line}
+
+		case erbItemElse:
+			branch.body = body
+			node.branches = append(node.branches, branch)
+
+			elseBody, elseCloser, err := b.build()
+			if err != nil {
+				return nil, err
+			}
+
+			if elseCloser == nil || elseCloser.kind != erbItemEnd {
+				return nil, &erbUnsupportedError{reason: "'else' without matching 'end'", line: closer.line}
+			}
+
+			node.elseBody = elseBody
+
+			return node, nil
+
+		case erbItemEnd:
+			branch.body = body
+			node.branches = append(node.branches, branch)
+			return node, nil
+
+		default:
+			return nil, &erbUnsupportedError{reason: fmt.Sprintf("unexpected '%s'", closer.kind), line: closer.line}
+		}
+	}
+}
+
+func (b *erbTreeBuilder) buildBlock(item erbItem) (*erbBlockNode, error) {
+	node := &erbBlockNode{call: item.call, params: item.params, line: item.line}
+
+	body, closer, err := b.build()
+	if err != nil {
+		return nil, err
+	}
+
+	if closer == nil {
+		return nil, &erbUnsupportedError{reason: "'do' without matching 'end'", line: item.line}
+	}
+
+	node.body = body
+
+	switch closer.kind {
+	case erbItemEnd:
+		return node, nil
+
+	case erbItemEndElse:
+		elseBody, elseCloser, err := b.build()
+		if err != nil {
+			return nil, err
+		}
+
+		if elseCloser == nil || elseCloser.kind != erbItemEnd {
+			return nil, &erbUnsupportedError{reason: "'else' without matching 'end'", line: closer.line}
+		}
+
+		node.elseChain = &erbElseNode{body: elseBody, line: closer.line}
+
+		return node, nil
+
+	case erbItemEndElseIfP:
+		ifP, err := b.buildBlock(erbItem{call: closer.call, params: closer.params, line: closer.line})
+		if err != nil {
+			return nil, err
+		}
+
+		node.elseChain = &erbElseNode{ifP: ifP, line: closer.line}
+
+		return node, nil
+
+	default:
+		return nil, &erbUnsupportedError{reason: fmt.Sprintf("unexpected '%s'", closer.kind), line: closer.line}
+	}
+}
@@ -1,8 +1,12 @@
 package fakes
 
 type FakeTemplateEvaluationContext struct {
+	ContextJSON string
 }
 
 func (f FakeTemplateEvaluationContext) MarshalJSON() ([]byte, error) {
+	if len(f.ContextJSON) > 0 {
+		return []byte(f.ContextJSON), nil
+	}
 	return []byte("{}"), nil
 }
@@ -0,0 +1,237 @@
+package erbrenderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// goERBRenderer renders the subset of ERB used by job templates without shelling out to Ruby.
+// It mirrors the behaviour of templateEvaluationContextRb.
+type goERBRenderer struct {
+	fs     boshsys.FileSystem
+	logger boshlog.Logger
+	logTag string
+}
+
+// UnsupportedTemplateError is returned when a template uses Ruby that cannot be evaluated in-process
+type UnsupportedTemplateError struct {
+	Path   string
+	Reason error
+}
+
+func (e UnsupportedTemplateError) Error() string {
+	return fmt.Sprintf("Rendering template '%s' without Ruby: %s", e.Path, e.Reason)
+}
+
+func NewGoERBRenderer(fs boshsys.FileSystem, logger boshlog.Logger) ERBRenderer {
+	return goERBRenderer{
+		fs:     fs,
+		logger: logger,
+		logTag: "goERBRenderer",
+	}
+}
+
+func (r goERBRenderer) Render(srcPath, dstPath string, context TemplateEvaluationContext) error {
+	r.logger.Debug(r.logTag, "Rendering template %s", dstPath)
+
+	src, err := r.fs.ReadFileString(srcPath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Reading template '%s'", srcPath)
+	}
+
+	template, err := parseERBTemplate(src)
+	if err != nil {
+		return UnsupportedTemplateError{Path: srcPath, Reason: err}
+	}
+
+	contextBytes, err := json.Marshal(context)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling context")
+	}
+
+	evalContext, err := r.buildContext(contextBytes)
+	if err != nil {
+		if _, ok := err.(*erbUnsupportedError); ok {
+			return UnsupportedTemplateError{Path: srcPath, Reason: err}
+		}
+		return bosherr.WrapError(err, "Building template evaluation context")
+	}
+
+	evaluator := &rbEvaluator{context: evalContext}
+
+	result, err := evaluator.render(template)
+	if err != nil {
+		switch typedErr := err.(type) {
+		case *erbUnsupportedError:
+			return UnsupportedTemplateError{Path: srcPath, Reason: typedErr}
+		case rbLineError:
+			name := fmt.Sprintf("%s/%s", rbToS(evalContext.name), rbToS(evalContext.index))
+			return bosherr.Errorf("Error filling in template '%s' for %s (line %d: %s)", srcPath, name, typedErr.line, typedErr.err)
+		default:
+			return bosherr.WrapErrorf(err, "Rendering template '%s'", srcPath)
+		}
+	}
+
+	err = r.fs.WriteFileString(dstPath, result)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing rendered template '%s'", dstPath)
+	}
+
+	return nil
+}
+
+// buildContext follows TemplateEvaluationContext#initialize from the Ruby renderer
+func (r goERBRenderer) buildContext(contextBytes []byte) (rbTemplateContext, error) {
+	decoded, err := decodeRbJSON(contextBytes)
+	if err != nil {
+		if _, ok := err.(*erbUnsupportedError); ok {
+			return rbTemplateContext{}, err
+		}
+		return rbTemplateContext{}, bosherr.WrapError(err, "Unmarshalling context")
+	}
+
+	spec, ok := decoded.(*rbHash)
+	if !ok {
+		return rbTemplateContext{}, bosherr.Errorf("Expected context to be a hash but was %s", rbClassName(decoded))
+	}
+
+	context := rbTemplateContext{}
+
+	if job, ok := spec.values["job"].(*rbHash); ok {
+		context.name = job.values["name"]
+	}
+
+	context.index = spec.values["index"]
+	context.links, _ = spec.values["links"].(*rbHash)
+
+	var srcProperties interface{}
+
+	if jobProperties := spec.values["job_properties"]; jobProperties != nil {
+		srcProperties = jobProperties
+	} else {
+		globalProperties, _ := spec.values["global_properties"].(*rbHash)
+		clusterProperties, _ := spec.values["cluster_properties"].(*rbHash)
+
+		if globalProperties == nil {
+			globalProperties = newRbHash()
+			spec.Set("global_properties", globalProperties)
+		}
+
+		rbRecursiveMerge(globalProperties, clusterProperties)
+
+		srcProperties = globalProperties
+	}
+
+	properties := newRbHash()
+
+	if defaultProperties, ok := spec.values["default_properties"].(*rbHash); ok {
+		for _, name := range defaultProperties.keys {
+			err := rbCopyProperty(properties, srcProperties, name, defaultProperties.values[name])
+			if err != nil {
+				return rbTemplateContext{}, err
+			}
+		}
+	}
+
+	context.rawProperties = properties
+	context.properties = rbOpenStructValue(properties).(rbOpenStruct)
+	context.spec = rbOpenStructValue(spec).(rbOpenStruct)
+
+	return context, nil
+}
+
+func rbRecursiveMerge(dst, src *rbHash) {
+	if src == nil {
+		return
+	}
+
+	for _, key := range src.keys {
+		srcValue := src.values[key]
+
+		dstHash, dstOk := dst.values[key].(*rbHash)
+		srcHash, srcOk := srcValue.(*rbHash)
+
+		if dstOk && srcOk {
+			rbRecursiveMerge(dstHash, srcHash)
+		} else {
+			dst.Set(key, srcValue)
+		}
+	}
+}
+
+func rbCopyProperty(dst *rbHash, src interface{}, name string, defaultValue interface{}) error {
+	keys := strings.Split(name, ".")
+
+	srcRef := src
+
+	for _, key := range keys {
+		srcHash, ok := srcRef.(*rbHash)
+		if !ok {
+			if srcRef != nil {
+				return newUnsupportedSyntaxError("property '%s' traverses a non-hash value", name)
+			}
+			break
+		}
+
+		srcRef = srcHash.values[key]
+		if srcRef == nil {
+			break
+		}
+	}
+
+	dstRef := dst
+
+	for _, key := range keys[:len(keys)-1] {
+		if dstRef.values[key] == nil {
+			dstRef.Set(key, newRbHash())
+		}
+
+		nextRef, ok := dstRef.values[key].(*rbHash)
+		if !ok {
+			return newUnsupportedSyntaxError("property '%s' nests under a non-hash default", name)
+		}
+
+		dstRef = nextRef
+	}
+
+	lastKey := keys[len(keys)-1]
+
+	if dstRef.values[lastKey] == nil {
+		dstRef.Set(lastKey, newRbHash())
+	}
+
+	if srcRef == nil {
+		dstRef.Set(lastKey, defaultValue)
+	} else {
+		dstRef.Set(lastKey, srcRef)
+	}
+
+	return nil
+}
+
+// rbOpenStructValue converts nested hashes to open structs like the openstruct helper in the Ruby renderer
+func rbOpenStructValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case *rbHash:
+		mapped := newRbHash()
+		for _, key := range typedValue.keys {
+			mapped.Set(key, rbOpenStructValue(typedValue.values[key]))
+		}
+		return rbOpenStruct{hash: mapped}
+
+	case []interface{}:
+		mapped := make([]interface{}, len(typedValue))
+		for i, item := range typedValue {
+			mapped[i] = rbOpenStructValue(item)
+		}
+		return mapped
+
+	default:
+		return value
+	}
+}
@@ -0,0 +1,432 @@
+package erbrenderer_test
+
+import (
+	"errors"
+
+	. "github.com/cloudfoundry/bosh-cli/templatescompiler/erbrenderer"
+	fakebierbrenderer "github.com/cloudfoundry/bosh-cli/templatescompiler/erbrenderer/fakes"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GoERBRenderer", func() {
+	var (
+		fs          *fakesys.FakeFileSystem
+		erbRenderer ERBRenderer
+		context     *fakebierbrenderer.FakeTemplateEvaluationContext
+	)
+
+	BeforeEach(func() {
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		fs = fakesys.NewFakeFileSystem()
+		context = &fakebierbrenderer.FakeTemplateEvaluationContext{
+			ContextJSON: `{
+				"index": 0,
+				"id": "fake-uuid",
+				"az": "unknown",
+				"bootstrap": true,
+				"job": {"name": "fake-job"},
+				"deployment": "fake-deployment",
+				"networks": {"default": {"ip": "10.0.0.5", "netmask": "", "gateway": ""}},
+				"global_properties": {"global": {"key": "global-value"}},
+				"cluster_properties": {"cluster": {"key": "cluster-value"}, "global": {"other": "merged"}},
+				"job_properties": null,
+				"default_properties": {
+					"cluster.key": null,
+					"global.key": null,
+					"global.other": null,
+					"defaulted": "default-value",
+					"int": 5,
+					"float": 1.0,
+					"enabled": false,
+					"list": ["a", "b"],
+					"hash": {"z": 1, "a": 2},
+					"missing": null
+				}
+			}`,
+		}
+
+		erbRenderer = NewGoERBRenderer(fs, logger)
+	})
+
+	render := func(template string) (string, error) {
+		err := fs.WriteFileString("/src", template)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = erbRenderer.Render("/src", "/dst", context)
+		if err != nil {
+			return "", err
+		}
+
+		return fs.ReadFileString("/dst")
+	}
+
+	expectRendered := func(template, expected string) {
+		result, err := render(template)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(expected))
+	}
+
+	expectUnsupported := func(template string) {
+		_, err := render(template)
+		Expect(err).To(HaveOccurred())
+		Expect(err).To(BeAssignableToTypeOf(UnsupportedTemplateError{}))
+	}
+
+	It("copies text verbatim including newlines", func() {
+		expectRendered("line1\n\nline2\n", "line1\n\nline2\n")
+	})
+
+	It("treats <%% as a literal and drops comments", func() {
+		expectRendered("<%%= x %><%# comment %>a", "<%= x %>a")
+	})
+
+	Describe("properties", func() {
+		It("looks up job properties with p()", func() {
+			expectRendered(`<%= p("cluster.key") %> <%= p("global.key") %>`, "cluster-value global-value")
+		})
+
+		It("deep merges instance group properties into global properties", func() {
+			expectRendered(`<%= p("global.other") %>`, "merged")
+		})
+
+		It("uses spec defaults for unset properties", func() {
+			expectRendered(`<%= p("defaulted") %>`, "default-value")
+		})
+
+		It("returns the first set property from a list of names", func() {
+			expectRendered(`<%= p(["missing", "defaulted"]) %>`, "default-value")
+		})
+
+		It("returns the explicit default when property is not set", func() {
+			expectRendered(`<%= p("missing", "fallback") %>`, "fallback")
+		})
+
+		It("keeps false values instead of using the default", func() {
+			expectRendered(`<%= p("enabled", true) %>`, "false")
+		})
+
+		It("exposes properties as open structs", func() {
+			expectRendered(`<%= properties.cluster.key %>`, "cluster-value")
+		})
+
+		It("reports unknown open struct attributes as unsupported", func() {
+			expectUnsupported(`<%= properties.unknown %>`)
+		})
+
+		It("ignores properties that are not declared in the job spec", func() {
+			expectRendered(`<%= p("undeclared", "fallback") %>`, "fallback")
+		})
+
+		It("formats values the way Ruby's to_s does", func() {
+			expectRendered(
+				`<%= p("int") %> <%= p("float") %> <%= p("list") %> <%= p("hash") %> <%= p("missing", nil) %>`,
+				`5 1.0 ["a", "b"] {"z"=>1, "a"=>2} `,
+			)
+		})
+
+		It("uses job properties instead of global and cluster properties when present", func() {
+			context.ContextJSON = `{
+				"job": {"name": "fake-job"},
+				"index": 0,
+				"global_properties": {"key": "global"},
+				"cluster_properties": {"key": "cluster"},
+				"job_properties": {"key": "job"},
+				"default_properties": {"key": null}
+			}`
+
+			expectRendered(`<%= p("key") %>`, "job")
+		})
+
+		It("returns an error with template location when property is missing", func() {
+			_, err := render("ok\n<%= p(\"missing\") %>")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Error filling in template '/src' for fake-job/0 (line 2: " +
+					"#<TemplateEvaluationContext::UnknownProperty: Can't find property 'missing'>)"))
+		})
+	})
+
+	Describe("if_p", func() {
+		It("yields property values when all of them are set", func() {
+			expectRendered(
+				`<% if_p("cluster.key", "global.key") do |a, b| %><%= a %>,<%= b %><% end %>`,
+				"cluster-value,global-value",
+			)
+		})
+
+		It("renders the else block when a property is missing", func() {
+			expectRendered(
+				`<% if_p("cluster.key", "missing") do |a, b| %>yes<% end.else do %>no<% end %>`,
+				"no",
+			)
+		})
+
+		It("supports else_if_p chains", func() {
+			expectRendered(
+				`<% if_p("missing") do |a| %>1<% end.else_if_p("defaulted") do |d| %><%= d %><% end.else do %>3<% end %>`,
+				"default-value",
+			)
+		})
+
+		It("does not yield links that are not in the context", func() {
+			expectRendered(`<% if_link("db") do |db| %>yes<% end %>done`, "done")
+		})
+
+		It("renders the else block when link is not in the context", func() {
+			expectRendered(`<% if_link("db") do |db| %>yes<% end.else do %>no<% end %>`, "no")
+		})
+
+		It("raises the same error as Ruby when link is not in the context", func() {
+			_, err := render(`<%= link("db") %>`)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Can't find link 'db'"))
+		})
+
+		Context("when links are in the context", func() {
+			BeforeEach(func() {
+				context.ContextJSON = `{
+					"index": 0,
+					"job": {"name": "fake-job"},
+					"default_properties": {},
+					"links": {"db": {"address": "db.bosh", "properties": {}, "instances": []}}
+				}`
+			})
+
+			It("leaves link objects to the Ruby renderer", func() {
+				expectUnsupported(`<% if_link("db") do |db| %><%= db.address %><% end %>`)
+				expectUnsupported(`<%= link("db").address %>`)
+			})
+
+			It("renders the else block for other links", func() {
+				expectRendered(`<% if_link("other") do |other| %>yes<% end.else do %>no<% end %>`, "no")
+			})
+		})
+	})
+
+	Describe("spec", func() {
+		It("exposes the job spec as an open struct", func() {
+			expectRendered(
+				`<%= spec.job.name %> <%= spec.networks.default.ip %> <%= spec.deployment %> <%= spec.bootstrap %>`,
+				"fake-job 10.0.0.5 fake-deployment true",
+			)
+		})
+
+		It("exposes name and index", func() {
+			expectRendered(`<%= name %>/<%= index %>`, "fake-job/0")
+		})
+
+		It("reports unknown fields and open struct methods as unsupported", func() {
+			expectUnsupported(`<%= spec.unknown.nil? %>`)
+			expectUnsupported(`<%= spec.networks.to_h %>`)
+			expectUnsupported(`<%= spec.networks.marshal_dump %>`)
+			expectUnsupported(`<%= spec.networks.class %>`)
+			expectUnsupported(`<%= spec.networks.to_h.to_json %>`)
+		})
+	})
+
+	Describe("nil", func() {
+		It("supports common conversion methods", func() {
+			expectRendered(
+				`<%= p("missing", nil).to_a %> <%= p("missing", nil).to_h %> <%= p("missing", nil).to_i %> <%= p("missing", nil).to_s %>|<%= p("missing", nil).inspect %>`,
+				`[] {} 0 |nil`,
+			)
+		})
+
+		It("reports other methods as unsupported", func() {
+			expectUnsupported(`<%= p("missing", nil).empty? %>`)
+			expectUnsupported(`<%= p("missing", nil).ip %>`)
+		})
+	})
+
+	Describe("context", func() {
+		It("reports integers that do not fit into 64 bits as unsupported", func() {
+			context.ContextJSON = `{
+				"job": {"name": "fake-job"},
+				"index": 0,
+				"job_properties": {"big": 123456789012345678901234567890},
+				"default_properties": {"big": null}
+			}`
+
+			expectUnsupported(`<%= p("big") %>`)
+		})
+
+		It("keeps floats that do not fit into 64 bit integers", func() {
+			context.ContextJSON = `{
+				"job": {"name": "fake-job"},
+				"index": 0,
+				"job_properties": {"big": 1.5e20},
+				"default_properties": {"big": null}
+			}`
+
+			expectRendered(`<%= p("big") %>`, "1.5e+20")
+		})
+	})
+
+	Describe("conditionals", func() {
+		It("supports if, elsif and else", func() {
+			template := `<% if p("int") > 10 %>big<% elsif p("int") == 5 %>five<% else %>small<% end %>`
+			expectRendered(template, "five")
+		})
+
+		It("supports unless", func() {
+			expectRendered(`<% unless p("enabled") %>disabled<% end %>`, "disabled")
+		})
+
+		It("supports boolean operators and negation", func() {
+			expectRendered(
+				`<%= !p("enabled") && p("int") == 5 %> <%= p("enabled") || "other" %> <%= not true %>`,
+				"true other false",
+			)
+		})
+
+		It("supports ternaries and modifiers", func() {
+			expectRendered(
+				`<%= p("enabled") ? "on" : "off" %><%= "!" if p("int") > 1 %><%= "?" unless true %>`,
+				"off!",
+			)
+		})
+
+		It("treats only nil and false as falsy", func() {
+			expectRendered(`<% if 0 %>zero<% end %><% if "" %>empty<% end %><% if nil %>nil<% end %>`, "zeroempty")
+		})
+
+		It("supports multiple statements within one tag", func() {
+			expectRendered("<%\n  x = p(\"int\")\n  if x == 5 then y = \"five\" end\n%><%= y %>", "five")
+		})
+	})
+
+	Describe("loops", func() {
+		It("iterates over arrays", func() {
+			expectRendered(`<% p("list").each do |item| %>[<%= item %>]<% end %>`, "[a][b]")
+		})
+
+		It("iterates over arrays with index", func() {
+			expectRendered(`<% p("list").each_with_index do |item, i| %><%= i %>=<%= item %> <% end %>`, "0=a 1=b ")
+		})
+
+		It("iterates over hashes in insertion order", func() {
+			expectRendered(`<% p("hash").each do |k, v| %><%= k %>=<%= v %>;<% end %>`, "z=1;a=2;")
+		})
+
+		It("iterates over open structs with each_pair", func() {
+			expectRendered(`<% properties.global.each_pair do |k, v| %><%= k %>;<% end %>`, "key;other;")
+		})
+
+		It("supports times", func() {
+			expectRendered(`<% 3.times do |i| %><%= i %><% end %>`, "012")
+		})
+
+		It("keeps variables assigned in outer scope", func() {
+			expectRendered(`<% n = 0 %><% p("list").each do |i| %><% n = n + 1 %><% end %><%= n %>`, "2")
+		})
+	})
+
+	Describe("expressions", func() {
+		It("supports string interpolation and escapes", func() {
+			expectRendered(`<%= "#{p("int")}:#{name}\t'" %>`, "5:fake-job\t'")
+		})
+
+		It("supports single quoted strings", func() {
+			expectRendered(`<%= 'a#{b}\n' %>`, `a#{b}\n`)
+		})
+
+		It("supports Ruby integer arithmetic", func() {
+			expectRendered(`<%= 7 / 2 %> <%= -7 / 2 %> <%= 7 % 3 %> <%= 2 * 3 + 1 %> <%= 1.5 * 2 %>`, "3 -4 1 7 3.0")
+		})
+
+		It("supports array and hash literals", func() {
+			expectRendered(`<%= [1, "a", nil] %> <%= {"k" => [1]}["k"] %>`, `[1, "a", nil] [1]`)
+		})
+
+		It("supports common string methods", func() {
+			expectRendered(
+				`<%= " Ab ".strip.upcase %> <%= "a,b,,".split(",") %> <%= "a-b-c".gsub("-", "_") %> <%= "10x".to_i + 1 %>`,
+				`AB ["a", "b"] a_b_c 11`,
+			)
+		})
+
+		It("supports common collection methods", func() {
+			expectRendered(
+				`<%= p("list").join(",") %> <%= p("list").map { |i| i.upcase }.join %> <%= p("list").map(&:upcase).size %> <%= p("hash").keys.sort %> <%= p("list").include?("b") %>`,
+				`a,b AB 2 ["a", "z"] true`,
+			)
+		})
+
+		It("renders JSON the way the Ruby renderer does", func() {
+			expectRendered(
+				`<%= p("hash").to_json %> <%= JSON.dump(p("defaulted")) %> <%= JSON.dump(p("list")) %> <%= p("float").to_json %>`,
+				`{"z":1,"a":2} "default-value" ["a","b"] 1.0`,
+			)
+		})
+
+		It("returns an error for Ruby exceptions", func() {
+			_, err := render(`<%= 1 / 0 %>`)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("#<ZeroDivisionError: divided by 0>"))
+		})
+	})
+
+	Describe("unsupported templates", func() {
+		It("reports trim mode tags as unsupported", func() {
+			expectUnsupported("<%- if true -%>x<% end %>")
+		})
+
+		It("reports unknown methods as unsupported", func() {
+			expectUnsupported(`<%= p("hash").to_yaml %>`)
+		})
+
+		It("reports unknown methods with arguments as unsupported", func() {
+			expectUnsupported(`<%= p("list").each_slice(2) %>`)
+		})
+
+		It("reports unknown identifiers as unsupported", func() {
+			expectUnsupported(`<%= Time.now %>`)
+			expectUnsupported(`<%= esc(p("int")) %>`)
+		})
+
+		It("reports Ruby constructs outside of the subset as unsupported", func() {
+			expectUnsupported(`<% case p("int") when 5 %>five<% end %>`)
+			expectUnsupported(`<% def helper; end %>`)
+			expectUnsupported(`<%= p("defaulted") =~ /value/ %>`)
+		})
+
+		It("reports unbalanced blocks as unsupported", func() {
+			expectUnsupported(`<% if true %>x`)
+			expectUnsupported(`<% end %>`)
+		})
+
+		It("includes template path and line", func() {
+			_, err := render("a\n<%= foo(1) %>")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("'/src'"))
+			Expect(err.Error()).To(ContainSubstring("line 2"))
+		})
+	})
+
+	Context("when reading template fails", func() {
+		It("returns an error", func() {
+			err := fs.WriteFileString("/src", "content")
+			Expect(err).ToNot(HaveOccurred())
+
+			fs.ReadFileError = errors.New("fake-read-error")
+			err = erbRenderer.Render("/src", "/dst", context)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-read-error"))
+		})
+	})
+
+	Context("when writing rendered template fails", func() {
+		It("returns an error", func() {
+			err := fs.WriteFileString("/src", "content")
+			Expect(err).ToNot(HaveOccurred())
+
+			fs.WriteFileError = errors.New("fake-write-error")
+			err = erbRenderer.Render("/src", "/dst", context)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-write-error"))
+		})
+	})
+})
@@ -0,0 +1,1308 @@
+package erbrenderer
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// rbRuntimeError mirrors a Ruby exception raised while evaluating a template
+type rbRuntimeError struct {
+	class   string
+	message string
+}
+
+func (e rbRuntimeError) Error() string {
+	return fmt.Sprintf("#<%s: %s>", e.class, e.message)
+}
+
+func newNoMethodError(method string, receiver interface{}) rbRuntimeError {
+	return rbRuntimeError{
+		class:   "NoMethodError",
+		message: fmt.Sprintf("undefined method `%s' for %s", method, rbDescribe(receiver)),
+	}
+}
+
+func newTypeError(format string, args ...interface{}) rbRuntimeError {
+	return rbRuntimeError{class: "TypeError", message: fmt.Sprintf(format, args...)}
+}
+
+func rbDescribe(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "nil:NilClass"
+	case bool:
+		return rbInspect(value) + ":" + rbClassName(value)
+	default:
+		return "an instance of " + rbClassName(value)
+	}
+}
+
+// rbTemplateContext exposes the same methods as TemplateEvaluationContext in the Ruby renderer
+type rbTemplateContext struct {
+	name          interface{}
+	index         interface{}
+	spec          rbOpenStruct
+	properties    rbOpenStruct
+	rawProperties *rbHash
+	links         *rbHash
+}
+
+type rbScope struct {
+	vars   map[string]interface{}
+	parent *rbScope
+}
+
+func newRbScope(parent *rbScope) *rbScope {
+	return &rbScope{vars: map[string]interface{}{}, parent: parent}
+}
+
+func (s *rbScope) lookup(name string) (interface{}, bool) {
+	for scope := s; scope != nil; scope = scope.parent {
+		if value, found := scope.vars[name]; found {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// assign updates a variable in the closest scope defining it, or defines it in the current scope
+func (s *rbScope) assign(name string, value interface{}) {
+	for scope := s; scope != nil; scope = scope.parent {
+		if _, found := scope.vars[name]; found {
+			scope.vars[name] = value
+			return
+		}
+	}
+	s.vars[name] = value
+}
+
+type rbEvaluator struct {
+	context rbTemplateContext
+	out     bytes.Buffer
+}
+
+// rbLineError attaches the template line to an evaluation error
+type rbLineError struct {
+	err  error
+	line int
+}
+
+func (e rbLineError) Error() string {
+	return e.err.Error()
+}
+
+func (e *rbEvaluator) render(template *erbTemplate) (string, error) {
+	err := e.evalNodes(template.body, newRbScope(nil))
+	if err != nil {
+		return "", err
+	}
+
+	return e.out.String(), nil
+}
+
+func (e *rbEvaluator) evalNodes(nodes []erbNode, scope *rbScope) error {
+	for _, node := range nodes {
+		err := e.evalNode(node, scope)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *rbEvaluator) evalNode(node erbNode, scope *rbScope) error {
+	switch typedNode := node.(type) {
+	case erbTextNode:
+		e.out.WriteString(typedNode.text)
+
+	case erbOutputNode:
+		value, err := e.evalExpr(typedNode.expr, scope)
+		if err != nil {
+			return e.atLine(err, typedNode.line)
+		}
+		e.out.WriteString(rbToS(value))
+
+	case erbStatementNode:
+		value, err := e.evalExpr(typedNode.expr, scope)
+		if err != nil {
+			return e.atLine(err, typedNode.line)
+		}
+		if len(typedNode.assignTo) > 0 {
+			scope.assign(typedNode.assignTo, value)
+		}
+
+	case erbIfNode:
+		for _, branch := range typedNode.branches {
+			value, err := e.evalExpr(branch.cond, scope)
+			if err != nil {
+				return e.atLine(err, branch.line)
+			}
+
+			if rbTruthy(value) != branch.negate {
+				return e.evalNodes(branch.body, scope)
+			}
+		}
+
+		return e.evalNodes(typedNode.elseBody, scope)
+
+	case *erbBlockNode:
+		return e.evalBlockNode(typedNode, scope)
+
+	default:
+		panic(fmt.Sprintf("Unknown ERB node %T", node))
+	}
+
+	return nil
+}
+
+func (e *rbEvaluator) atLine(err error, line int) error {
+	if _, ok := err.(rbLineError); ok {
+		return err
+	}
+	if unsupportedErr, ok := err.(*erbUnsupportedError); ok {
+		if unsupportedErr.line == 0 {
+			unsupportedErr.line = line
+		}
+		return unsupportedErr
+	}
+	return rbLineError{err: err, line: line}
+}
+
+func (e *rbEvaluator) evalBlockNode(node *erbBlockNode, scope *rbScope) error {
+	yield := func(args ...interface{}) error {
+		return e.evalNodes(node.body, e.blockScope(scope, node.params, args))
+	}
+
+	switch node.call.method {
+	case "if_p":
+		values, found, err := e.ifPValues(node.call.args, scope)
+		if err != nil {
+			return e.atLine(err, node.line)
+		}
+
+		if found {
+			return yield(values...)
+		}
+
+		return e.evalElseChain(node.elseChain, scope)
+
+	case "if_link":
+		found, err := e.hasLink(node.call.args, scope)
+		if err != nil {
+			return e.atLine(err, node.line)
+		}
+
+		if found {
+			// Link objects are only implemented by the Ruby renderer
+			return e.atLine(newUnsupportedSyntaxError("links"), node.line)
+		}
+
+		return e.evalElseChain(node.elseChain, scope)
+	}
+
+	receiver, err := e.evalExpr(node.call.receiver, scope)
+	if err != nil {
+		return e.atLine(err, node.line)
+	}
+
+	err = e.iterate(receiver, node.call.method, func(args ...interface{}) (interface{}, error) {
+		return nil, yield(args...)
+	})
+	if err != nil {
+		return e.atLine(err, node.line)
+	}
+
+	if node.elseChain != nil {
+		// Iterators return their receiver which does not respond to else
+		return e.atLine(newNoMethodError("else", receiver), node.elseChain.line)
+	}
+
+	return nil
+}
+
+func (e *rbEvaluator) evalElseChain(elseNode *erbElseNode, scope *rbScope) error {
+	if elseNode == nil {
+		return nil
+	}
+
+	if elseNode.ifP != nil {
+		return e.evalBlockNode(elseNode.ifP, scope)
+	}
+
+	return e.evalNodes(elseNode.body, scope)
+}
+
+// blockScope binds block parameters, splatting a single array argument across several parameters
+func (e *rbEvaluator) blockScope(scope *rbScope, params []string, args []interface{}) *rbScope {
+	blockScope := newRbScope(scope)
+
+	if len(params) > 1 && len(args) == 1 {
+		if array, ok := args[0].([]interface{}); ok {
+			args = array
+		}
+	}
+
+	for i, param := range params {
+		if i < len(args) {
+			blockScope.vars[param] = args[i]
+		} else {
+			blockScope.vars[param] = nil
+		}
+	}
+
+	return blockScope
+}
+
+// iterate implements each, each_with_index, each_pair and times
+func (e *rbEvaluator) iterate(receiver interface{}, method string, yield func(...interface{}) (interface{}, error)) error {
+	switch typedReceiver := receiver.(type) {
+	case []interface{}:
+		if method == "each" || method == "each_with_index" {
+			for i, item := range typedReceiver {
+				var err error
+				if method == "each" {
+					_, err = yield(item)
+				} else {
+					_, err = yield(item, int64(i))
+				}
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+	case *rbHash:
+		if method == "each" || method == "each_pair" || method == "each_with_index" {
+			for i, key := range typedReceiver.keys {
+				pair := []interface{}{key, typedReceiver.values[key]}
+
+				var err error
+				if method == "each_with_index" {
+					_, err = yield(pair, int64(i))
+				} else {
+					_, err = yield(pair)
+				}
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+	case rbOpenStruct:
+		if method == "each_pair" {
+			return e.iterate(typedReceiver.hash, method, yield)
+		}
+
+	case int64:
+		if method == "times" {
+			for i := int64(0); i < typedReceiver; i++ {
+				_, err := yield(i)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+	case nil:
+		return newNoMethodError(method, nil)
+	}
+
+	return newUnsupportedSyntaxError("method '%s' on %s", method, rbClassName(receiver))
+}
+
+// ifPValues returns property values when all of them are set
+func (e *rbEvaluator) ifPValues(argExprs []rbExpr, scope *rbScope) ([]interface{}, bool, error) {
+	args, err := e.evalExprs(argExprs, scope)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var values []interface{}
+
+	for _, arg := range args {
+		name, ok := arg.(string)
+		if !ok {
+			return nil, false, newUnsupportedSyntaxError("non-string property name")
+		}
+
+		value, err := e.lookupProperty(name)
+		if err != nil || value == nil {
+			return nil, false, err
+		}
+
+		values = append(values, value)
+	}
+
+	return values, true, nil
+}
+
+func (e *rbEvaluator) hasLink(argExprs []rbExpr, scope *rbScope) (bool, error) {
+	args, err := e.evalExprs(argExprs, scope)
+	if err != nil {
+		return false, err
+	}
+
+	if len(args) != 1 {
+		return false, rbRuntimeError{
+			class:   "ArgumentError",
+			message: fmt.Sprintf("wrong number of arguments (given %d, expected 1)", len(args)),
+		}
+	}
+
+	name, ok := args[0].(string)
+	if !ok {
+		return false, newUnsupportedSyntaxError("non-string link name")
+	}
+
+	if e.context.links == nil {
+		return false, nil
+	}
+
+	value, found := e.context.links.Get(name)
+
+	return found && value != nil, nil
+}
+
+func (e *rbEvaluator) link(args []interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, rbRuntimeError{
+			class:   "ArgumentError",
+			message: fmt.Sprintf("wrong number of arguments (given %d, expected 1)", len(args)),
+		}
+	}
+
+	name, ok := args[0].(string)
+	if !ok {
+		return nil, newUnsupportedSyntaxError("non-string link name")
+	}
+
+	if e.context.links != nil {
+		if value, found := e.context.links.Get(name); found && value != nil {
+			return nil, newUnsupportedSyntaxError("links")
+		}
+	}
+
+	return nil, rbRuntimeError{
+		class:   "TemplateEvaluationContext::UnknownLink",
+		message: fmt.Sprintf("Can't find link '%s'", name),
+	}
+}
+
+func (e *rbEvaluator) lookupProperty(name string) (interface{}, error) {
+	var ref interface{} = e.context.rawProperties
+
+	for _, key := range strings.Split(name, ".") {
+		hash, ok := ref.(*rbHash)
+		if !ok {
+			// Ruby would index into strings and numbers here
+			return nil, newUnsupportedSyntaxError("property '%s' traverses a non-hash value", name)
+		}
+
+		ref, _ = hash.Get(key)
+		if ref == nil {
+			return nil, nil
+		}
+	}
+
+	return ref, nil
+}
+
+func (e *rbEvaluator) evalExprs(exprs []rbExpr, scope *rbScope) ([]interface{}, error) {
+	values := make([]interface{}, len(exprs))
+
+	for i, expr := range exprs {
+		value, err := e.evalExpr(expr, scope)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+func (e *rbEvaluator) evalExpr(expr rbExpr, scope *rbScope) (interface{}, error) {
+	switch typedExpr := expr.(type) {
+	case rbLiteralExpr:
+		return typedExpr.value, nil
+
+	case rbStringExpr:
+		var buf bytes.Buffer
+
+		for _, part := range typedExpr.parts {
+			if part.expr == nil {
+				buf.WriteString(part.literal)
+				continue
+			}
+
+			value, err := e.evalExpr(part.expr, scope)
+			if err != nil {
+				return nil, err
+			}
+
+			buf.WriteString(rbToS(value))
+		}
+
+		return buf.String(), nil
+
+	case rbArrayExpr:
+		return e.evalExprs(typedExpr.items, scope)
+
+	case rbHashExpr:
+		hash := newRbHash()
+
+		for i, keyExpr := range typedExpr.keys {
+			key, err := e.evalExpr(keyExpr, scope)
+			if err != nil {
+				return nil, err
+			}
+
+			keyStr, ok := key.(string)
+			if !ok {
+				return nil, newUnsupportedSyntaxError("non-string hash key")
+			}
+
+			value, err := e.evalExpr(typedExpr.values[i], scope)
+			if err != nil {
+				return nil, err
+			}
+
+			hash.Set(keyStr, value)
+		}
+
+		return hash, nil
+
+	case rbIdentExpr:
+		if value, found := scope.lookup(typedExpr.name); found {
+			return value, nil
+		}
+		return e.callContext(typedExpr.name, nil)
+
+	case rbConstExpr:
+		return typedExpr, nil
+
+	case rbCallExpr:
+		return e.evalCall(typedExpr, scope)
+
+	case rbIndexExpr:
+		receiver, err := e.evalExpr(typedExpr.receiver, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		index, err := e.evalExpr(typedExpr.index, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		return e.index(receiver, index)
+
+	case rbUnaryExpr:
+		operand, err := e.evalExpr(typedExpr.operand, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		if typedExpr.op == "!" {
+			return !rbTruthy(operand), nil
+		}
+
+		switch typedOperand := operand.(type) {
+		case int64:
+			return -typedOperand, nil
+		case float64:
+			return -typedOperand, nil
+		default:
+			return nil, newNoMethodError("-@", operand)
+		}
+
+	case rbBinaryExpr:
+		return e.evalBinary(typedExpr, scope)
+
+	case rbTernaryExpr:
+		cond, err := e.evalExpr(typedExpr.cond, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		if rbTruthy(cond) {
+			return e.evalExpr(typedExpr.ifTrue, scope)
+		}
+
+		return e.evalExpr(typedExpr.ifFalse, scope)
+
+	default:
+		panic(fmt.Sprintf("Unknown Ruby expression %T", expr))
+	}
+}
+
+func (e *rbEvaluator) callContext(method string, args []interface{}) (interface{}, error) {
+	switch method {
+	case "name":
+		return e.context.name, nil
+	case "index":
+		return e.context.index, nil
+	case "spec":
+		return e.context.spec, nil
+	case "properties":
+		return e.context.properties, nil
+	case "raw_properties":
+		return e.context.rawProperties, nil
+	case "p":
+		return e.p(args)
+	case "link":
+		return e.link(args)
+	}
+
+	return nil, newUnsupportedSyntaxError("unknown method or variable '%s'", method)
+}
+
+func (e *rbEvaluator) p(args []interface{}) (interface{}, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, rbRuntimeError{
+			class:   "ArgumentError",
+			message: fmt.Sprintf("wrong number of arguments (given %d, expected 1..2)", len(args)),
+		}
+	}
+
+	var names []string
+
+	switch typedArg := args[0].(type) {
+	case string:
+		names = []string{typedArg}
+	case []interface{}:
+		for _, item := range typedArg {
+			name, ok := item.(string)
+			if !ok {
+				return nil, newUnsupportedSyntaxError("non-string property name")
+			}
+			names = append(names, name)
+		}
+	default:
+		return nil, newUnsupportedSyntaxError("non-string property name")
+	}
+
+	for _, name := range names {
+		value, err := e.lookupProperty(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if value != nil {
+			return value, nil
+		}
+	}
+
+	if len(args) == 2 {
+		return args[1], nil
+	}
+
+	return nil, rbRuntimeError{
+		class:   "TemplateEvaluationContext::UnknownProperty",
+		message: fmt.Sprintf("Can't find property '%s'", strings.Join(names, "', or '")),
+	}
+}
+
+func (e *rbEvaluator) evalCall(call rbCallExpr, scope *rbScope) (interface{}, error) {
+	args, err := e.evalExprs(call.args, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if call.receiver == nil {
+		if call.block != nil {
+			return nil, newUnsupportedSyntaxError("block for method '%s'", call.method)
+		}
+		return e.callContext(call.method, args)
+	}
+
+	receiver, err := e.evalExpr(call.receiver, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	var block func(...interface{}) (interface{}, error)
+
+	if call.block != nil {
+		block = func(blockArgs ...interface{}) (interface{}, error) {
+			return e.evalExpr(call.block.body, e.blockScope(scope, call.block.params, blockArgs))
+		}
+	}
+
+	return e.callMethod(receiver, call.method, args, block)
+}
+
+func (e *rbEvaluator) callMethod(receiver interface{}, method string, args []interface{}, block func(...interface{}) (interface{}, error)) (interface{}, error) {
+	if constant, ok := receiver.(rbConstExpr); ok {
+		if constant.name == "JSON" && method == "dump" && len(args) == 1 {
+			// JSON.dump is patched by the Ruby renderer to inspect strings and numbers
+			switch args[0].(type) {
+			case string, int64, float64:
+				return rbInspect(args[0]), nil
+			}
+			return e.toJSON(args[0])
+		}
+		return nil, newUnsupportedSyntaxError("method '%s' on %s", method, constant.name)
+	}
+
+	if openStruct, ok := receiver.(rbOpenStruct); ok && len(args) == 0 && block == nil {
+		if value, found := openStruct.hash.Get(method); found {
+			return value, nil
+		}
+	}
+
+	switch method {
+	case "nil?":
+		return receiver == nil, nil
+	case "to_s":
+		return rbToS(receiver), nil
+	case "inspect":
+		return rbInspect(receiver), nil
+	case "to_json":
+		return e.toJSON(receiver)
+	case "each", "each_with_index", "each_pair", "times":
+		if block == nil {
+			return nil, newUnsupportedSyntaxError("method '%s' without a block", method)
+		}
+		return receiver, e.iterate(receiver, method, block)
+	}
+
+	var result interface{}
+	var err error
+	var handled bool
+
+	switch typedReceiver := receiver.(type) {
+	case nil:
+		result, handled = e.nilMethod(method)
+	case string:
+		result, handled, err = e.stringMethod(typedReceiver, method, args)
+	case int64, float64:
+		result, handled, err = e.numberMethod(typedReceiver, method)
+	case []interface{}:
+		result, handled, err = e.arrayMethod(typedReceiver, method, args, block)
+	case *rbHash:
+		result, handled, err = e.hashMethod(typedReceiver, method, args, block)
+	}
+
+	if err != nil || handled {
+		return result, err
+	}
+
+	// Unknown open struct attributes and methods (e.g. to_h or class) as well as
+	// methods on nil are left to the Ruby renderer since it knows how each behaves
+	return nil, newUnsupportedSyntaxError("method '%s' on %s", method, rbClassName(receiver))
+}
+
+func (e *rbEvaluator) toJSON(value interface{}) (interface{}, error) {
+	result, err := rbToJSON(value)
+	if err != nil {
+		return nil, rbRuntimeError{class: "JSON::GeneratorError", message: err.Error()}
+	}
+	return result, nil
+}
+
+func (e *rbEvaluator) nilMethod(method string) (interface{}, bool) {
+	switch method {
+	case "to_i":
+		return int64(0), true
+	case "to_f":
+		return float64(0), true
+	case "to_a":
+		return []interface{}{}, true
+	case "to_h":
+		return newRbHash(), true
+	}
+	return nil, false
+}
+
+func (e *rbEvaluator) stringMethod(s string, method string, args []interface{}) (interface{}, bool, error) {
+	stringArg := func(i int) (string, error) {
+		if i >= len(args) {
+			return "", rbRuntimeError{class: "ArgumentError", message: "wrong number of arguments"}
+		}
+		str, ok := args[i].(string)
+		if !ok {
+			return "", newTypeError("no implicit conversion of %s into String", rbClassName(args[i]))
+		}
+		return str, nil
+	}
+
+	switch method {
+	case "to_i":
+		return rbParseLeadingInt(s), true, nil
+	case "to_f":
+		return rbParseLeadingFloat(s), true, nil
+	case "upcase":
+		return strings.ToUpper(s), true, nil
+	case "downcase":
+		return strings.ToLower(s), true, nil
+	case "strip":
+		return strings.TrimFunc(s, func(r rune) bool { return unicode.IsSpace(r) || r == 0 }), true, nil
+	case "size", "length":
+		return int64(len([]rune(s))), true, nil
+	case "empty?":
+		return len(s) == 0, true, nil
+
+	case "include?", "start_with?", "end_with?":
+		arg, err := stringArg(0)
+		if err != nil {
+			return nil, true, err
+		}
+		switch method {
+		case "include?":
+			return strings.Contains(s, arg), true, nil
+		case "start_with?":
+			return strings.HasPrefix(s, arg), true, nil
+		default:
+			return strings.HasSuffix(s, arg), true, nil
+		}
+
+	case "split":
+		if len(args) == 0 || args[0] == " " {
+			return rbStrings(strings.Fields(s)), true, nil
+		}
+
+		sep, err := stringArg(0)
+		if err != nil || len(args) > 1 || len(sep) == 0 {
+			return nil, false, nil
+		}
+
+		parts := strings.Split(s, sep)
+		for len(parts) > 0 && parts[len(parts)-1] == "" {
+			parts = parts[:len(parts)-1]
+		}
+
+		return rbStrings(parts), true, nil
+
+	case "gsub", "sub":
+		if len(args) != 2 {
+			return nil, false, nil
+		}
+
+		pattern, err := stringArg(0)
+		if err != nil {
+			return nil, false, nil
+		}
+
+		replacement, err := stringArg(1)
+		if err != nil || strings.Contains(replacement, `\`) {
+			// Back-references in replacements are left to Ruby
+			return nil, false, nil
+		}
+
+		if method == "sub" {
+			return strings.Replace(s, pattern, replacement, 1), true, nil
+		}
+		return strings.Replace(s, pattern, replacement, -1), true, nil
+	}
+
+	return nil, false, nil
+}
+
+func (e *rbEvaluator) numberMethod(number interface{}, method string) (interface{}, bool, error) {
+	switch method {
+	case "to_i":
+		if f, ok := number.(float64); ok {
+			if math.IsInf(f, 0) || math.IsNaN(f) {
+				return nil, true, rbRuntimeError{class: "FloatDomainError", message: rbFormatFloat(f)}
+			}
+			return int64(f), true, nil
+		}
+		return number, true, nil
+
+	case "to_f":
+		f, _ := rbNumber(number)
+		return f, true, nil
+	}
+
+	return nil, false, nil
+}
+
+func (e *rbEvaluator) arrayMethod(array []interface{}, method string, args []interface{}, block func(...interface{}) (interface{}, error)) (interface{}, bool, error) {
+	switch method {
+	case "size", "length":
+		return int64(len(array)), true, nil
+
+	case "count":
+		if block == nil && len(args) == 0 {
+			return int64(len(array)), true, nil
+		}
+
+	case "empty?":
+		return len(array) == 0, true, nil
+
+	case "first", "last":
+		if len(args) > 0 {
+			return nil, false, nil
+		}
+		if len(array) == 0 {
+			return nil, true, nil
+		}
+		if method == "first" {
+			return array[0], true, nil
+		}
+		return array[len(array)-1], true, nil
+
+	case "include?":
+		if len(args) != 1 {
+			return nil, false, nil
+		}
+		for _, item := range array {
+			if rbEqual(item, args[0]) {
+				return true, true, nil
+			}
+		}
+		return false, true, nil
+
+	case "join":
+		sep := ""
+		if len(args) == 1 {
+			str, ok := args[0].(string)
+			if !ok {
+				return nil, false, nil
+			}
+			sep = str
+		}
+		return rbJoin(array, sep), true, nil
+
+	case "compact":
+		result := []interface{}{}
+		for _, item := range array {
+			if item != nil {
+				result = append(result, item)
+			}
+		}
+		return result, true, nil
+
+	case "flatten":
+		return rbFlatten(array), true, nil
+
+	case "uniq":
+		result := []interface{}{}
+	outer:
+		for _, item := range array {
+			for _, seen := range result {
+				if rbEqual(item, seen) {
+					continue outer
+				}
+			}
+			result = append(result, item)
+		}
+		return result, true, nil
+
+	case "sort":
+		return rbSort(array)
+
+	case "any?":
+		for _, item := range array {
+			value := item
+			if block != nil {
+				var err error
+				value, err = block(item)
+				if err != nil {
+					return nil, true, err
+				}
+			}
+			if rbTruthy(value) {
+				return true, true, nil
+			}
+		}
+		return false, true, nil
+
+	case "map", "collect", "select", "reject":
+		if block == nil {
+			return nil, false, nil
+		}
+
+		result := []interface{}{}
+
+		for _, item := range array {
+			value, err := block(item)
+			if err != nil {
+				return nil, true, err
+			}
+
+			switch {
+			case method == "map" || method == "collect":
+				result = append(result, value)
+			case (method == "select") == rbTruthy(value):
+				result = append(result, item)
+			}
+		}
+
+		return result, true, nil
+	}
+
+	return nil, false, nil
+}
+
+func (e *rbEvaluator) hashMethod(hash *rbHash, method string, args []interface{}, block func(...interface{}) (interface{}, error)) (interface{}, bool, error) {
+	switch method {
+	case "size", "length":
+		return int64(hash.Len()), true, nil
+
+	case "count":
+		if block == nil && len(args) == 0 {
+			return int64(hash.Len()), true, nil
+		}
+
+	case "empty?":
+		return hash.Len() == 0, true, nil
+
+	case "any?":
+		if block == nil {
+			return hash.Len() > 0, true, nil
+		}
+
+	case "keys":
+		return rbStrings(hash.keys), true, nil
+
+	case "values":
+		values := []interface{}{}
+		for _, key := range hash.keys {
+			values = append(values, hash.values[key])
+		}
+		return values, true, nil
+
+	case "key?", "has_key?", "include?":
+		if len(args) != 1 {
+			return nil, false, nil
+		}
+		key, ok := args[0].(string)
+		if !ok {
+			return false, true, nil
+		}
+		_, found := hash.Get(key)
+		return found, true, nil
+
+	case "fetch":
+		if len(args) < 1 || len(args) > 2 {
+			return nil, false, nil
+		}
+		if key, ok := args[0].(string); ok {
+			if value, found := hash.Get(key); found {
+				return value, true, nil
+			}
+		}
+		if len(args) == 2 {
+			return args[1], true, nil
+		}
+		return nil, true, rbRuntimeError{class: "KeyError", message: "key not found: " + rbInspect(args[0])}
+
+	case "map", "collect":
+		if block == nil {
+			return nil, false, nil
+		}
+
+		result := []interface{}{}
+
+		for _, key := range hash.keys {
+			value, err := block([]interface{}{key, hash.values[key]})
+			if err != nil {
+				return nil, true, err
+			}
+			result = append(result, value)
+		}
+
+		return result, true, nil
+	}
+
+	return nil, false, nil
+}
+
+func (e *rbEvaluator) index(receiver interface{}, index interface{}) (interface{}, error) {
+	switch typedReceiver := receiver.(type) {
+	case []interface{}:
+		i, ok := index.(int64)
+		if !ok {
+			return nil, newTypeError("no implicit conversion of %s into Integer", rbClassName(index))
+		}
+		if i < 0 {
+			i += int64(len(typedReceiver))
+		}
+		if i < 0 || i >= int64(len(typedReceiver)) {
+			return nil, nil
+		}
+		return typedReceiver[i], nil
+
+	case *rbHash:
+		key, ok := index.(string)
+		if !ok {
+			return nil, nil
+		}
+		value, _ := typedReceiver.Get(key)
+		return value, nil
+
+	case rbOpenStruct:
+		key, ok := index.(string)
+		if !ok {
+			return nil, newUnsupportedSyntaxError("non-string open struct key")
+		}
+		value, _ := typedReceiver.hash.Get(key)
+		return value, nil
+
+	case nil:
+		return nil, newNoMethodError("[]", nil)
+	}
+
+	return nil, newUnsupportedSyntaxError("indexing %s", rbClassName(receiver))
+}
+
+func (e *rbEvaluator) evalBinary(expr rbBinaryExpr, scope *rbScope) (interface{}, error) {
+	left, err := e.evalExpr(expr.left, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	switch expr.op {
+	case "&&":
+		if !rbTruthy(left) {
+			return left, nil
+		}
+		return e.evalExpr(expr.right, scope)
+
+	case "||":
+		if rbTruthy(left) {
+			return left, nil
+		}
+		return e.evalExpr(expr.right, scope)
+	}
+
+	right, err := e.evalExpr(expr.right, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	switch expr.op {
+	case "==":
+		return rbEqual(left, right), nil
+	case "!=":
+		return !rbEqual(left, right), nil
+	case "<", ">", "<=", ">=":
+		return rbCompareOp(expr.op, left, right)
+	default:
+		return rbArithmetic(expr.op, left, right)
+	}
+}
+
+func rbCompareOp(op string, left, right interface{}) (interface{}, error) {
+	cmp, err := rbCompare(left, right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "<":
+		return cmp < 0, nil
+	case ">":
+		return cmp > 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+func rbCompare(left, right interface{}) (int, error) {
+	if leftNum, rightNum, ok := rbNumbers(left, right); ok {
+		switch {
+		case leftNum < rightNum:
+			return -1, nil
+		case leftNum > rightNum:
+			return 1, nil
+		default:
+			return 0, nil
+		}
+	}
+
+	leftStr, leftOk := left.(string)
+	rightStr, rightOk := right.(string)
+
+	if leftOk && rightOk {
+		return strings.Compare(leftStr, rightStr), nil
+	}
+
+	if left == nil {
+		return 0, newNoMethodError("<=>", nil)
+	}
+
+	return 0, rbRuntimeError{
+		class:   "ArgumentError",
+		message: fmt.Sprintf("comparison of %s with %s failed", rbClassName(left), rbInspect(right)),
+	}
+}
+
+func rbArithmetic(op string, left, right interface{}) (interface{}, error) {
+	leftInt, leftIsInt := left.(int64)
+	rightInt, rightIsInt := right.(int64)
+
+	if leftIsInt && rightIsInt {
+		switch op {
+		case "+":
+			return leftInt + rightInt, nil
+		case "-":
+			return leftInt - rightInt, nil
+		case "*":
+			return leftInt * rightInt, nil
+		case "/", "%":
+			if rightInt == 0 {
+				return nil, rbRuntimeError{class: "ZeroDivisionError", message: "divided by 0"}
+			}
+
+			// Ruby rounds integer division towards negative infinity
+			quotient, remainder := leftInt/rightInt, leftInt%rightInt
+			if remainder != 0 && (remainder < 0) != (rightInt < 0) {
+				quotient--
+				remainder += rightInt
+			}
+
+			if op == "/" {
+				return quotient, nil
+			}
+			return remainder, nil
+		}
+	}
+
+	if leftNum, rightNum, ok := rbNumbers(left, right); ok {
+		switch op {
+		case "+":
+			return leftNum + rightNum, nil
+		case "-":
+			return leftNum - rightNum, nil
+		case "*":
+			return leftNum * rightNum, nil
+		case "/":
+			return leftNum / rightNum, nil
+		case "%":
+			return leftNum - rightNum*math.Floor(leftNum/rightNum), nil
+		}
+	}
+
+	switch typedLeft := left.(type) {
+	case string:
+		switch op {
+		case "+":
+			rightStr, ok := right.(string)
+			if !ok {
+				return nil, newTypeError("no implicit conversion of %s into String", rbClassName(right))
+			}
+			return typedLeft + rightStr, nil
+
+		case "*":
+			if rightIsInt && rightInt >= 0 {
+				return strings.Repeat(typedLeft, int(rightInt)), nil
+			}
+		}
+
+	case []interface{}:
+		if rightArray, ok := right.([]interface{}); ok && op == "+" {
+			return append(append([]interface{}{}, typedLeft...), rightArray...), nil
+		}
+
+	case nil:
+		return nil, newNoMethodError(op, nil)
+	}
+
+	if _, ok := rbNumber(left); ok {
+		return nil, newTypeError("%s can't be coerced into %s", rbClassName(right), rbClassName(left))
+	}
+
+	return nil, newUnsupportedSyntaxError("operator '%s' on %s", op, rbClassName(left))
+}
+
+func rbStrings(strs []string) []interface{} {
+	result := make([]interface{}, len(strs))
+	for i, str := range strs {
+		result[i] = str
+	}
+	return result
+}
+
+func rbJoin(array []interface{}, sep string) string {
+	strs := make([]string, len(array))
+
+	for i, item := range array {
+		if nested, ok := item.([]interface{}); ok {
+			strs[i] = rbJoin(nested, sep)
+		} else {
+			strs[i] = rbToS(item)
+		}
+	}
+
+	return strings.Join(strs, sep)
+}
+
+func rbFlatten(array []interface{}) []interface{} {
+	result := []interface{}{}
+
+	for _, item := range array {
+		if nested, ok := item.([]interface{}); ok {
+			result = append(result, rbFlatten(nested)...)
+		} else {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+func rbSort(array []interface{}) (interface{}, bool, error) {
+	result := append([]interface{}{}, array...)
+
+	var sortErr error
+
+	sort.SliceStable(result, func(i, j int) bool {
+		cmp, err := rbCompare(result[i], result[j])
+		if err != nil && sortErr == nil {
+			sortErr = err
+		}
+		return cmp < 0
+	})
+
+	if sortErr != nil {
+		return nil, false, nil
+	}
+
+	return result, true, nil
+}
+
+func rbParseLeadingInt(s string) int64 {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+
+	end := 0
+	if end < len(s) && (s[end] == '-' || s[end] == '+') {
+		end++
+	}
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == '_') {
+		end++
+	}
+
+	i, err := strconv.ParseInt(strings.Replace(s[:end], "_", "", -1), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return i
+}
+
+func rbParseLeadingFloat(s string) float64 {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+
+	for end := len(s); end > 0; end-- {
+		f, err := strconv.ParseFloat(s[:end], 64)
+		if err == nil && !strings.ContainsAny(s[:end], "xXpP_") {
+			return f
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,662 @@
+package erbrenderer
+
+import (
+	"strconv"
+)
+
+type rbExpr interface{}
+
+type rbLiteralExpr struct {
+	value interface{}
+}
+
+type rbStringExpr struct {
+	parts []rbStringExprPart
+}
+
+type rbStringExprPart struct {
+	literal string
+	expr    rbExpr
+}
+
+type rbArrayExpr struct {
+	items []rbExpr
+}
+
+type rbHashExpr struct {
+	keys   []rbExpr
+	values []rbExpr
+}
+
+// rbIdentExpr is either a local variable or a call of a context method without arguments
+type rbIdentExpr struct {
+	name string
+}
+
+type rbConstExpr struct {
+	name string
+}
+
+type rbCallExpr struct {
+	receiver rbExpr // nil for context methods such as p()
+	method   string
+	args     []rbExpr
+	block    *rbBlockExpr
+}
+
+type rbBlockExpr struct {
+	params []string
+	body   rbExpr
+}
+
+type rbIndexExpr struct {
+	receiver rbExpr
+	index    rbExpr
+}
+
+type rbUnaryExpr struct {
+	op      string
+	operand rbExpr
+}
+
+type rbBinaryExpr struct {
+	op    string
+	left  rbExpr
+	right rbExpr
+}
+
+type rbTernaryExpr struct {
+	cond    rbExpr
+	ifTrue  rbExpr
+	ifFalse rbExpr
+}
+
+// supportedRbMethods lists every method name that the evaluator knows how to dispatch.
+// Calls with arguments or blocks to anything else are reported as unsupported at parse time.
+var supportedRbMethods = map[string]bool{
+	// context
+	"p": true, "link": true,
+
+	// any object
+	"nil?": true, "to_s": true, "to_json": true, "inspect": true, "to_a": true, "to_h": true,
+
+	// numbers and strings
+	"to_i": true, "to_f": true, "upcase": true, "downcase": true, "strip": true,
+	"start_with?": true, "end_with?": true, "split": true, "gsub": true, "sub": true,
+
+	// collections
+	"each": true, "each_with_index": true, "each_pair": true, "map": true, "collect": true,
+	"select": true, "reject": true, "join": true, "first": true, "last": true,
+	"size": true, "length": true, "count": true, "empty?": true, "any?": true,
+	"include?": true, "sort": true, "uniq": true, "compact": true, "flatten": true,
+	"keys": true, "values": true, "fetch": true, "key?": true, "has_key?": true,
+	"dump": true, "times": true,
+}
+
+type rbParser struct {
+	tokens []rbToken
+	pos    int
+}
+
+func newRbParser(tokens []rbToken) *rbParser {
+	return &rbParser{tokens: tokens}
+}
+
+func parseRbExpr(src string) (rbExpr, error) {
+	tokens, err := lexRuby(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := newRbParser(tokens)
+
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipNewlines()
+
+	if !p.at(rbTokenEOF) {
+		return nil, p.unexpected()
+	}
+
+	return expr, nil
+}
+
+func (p *rbParser) parseExpr() (rbExpr, error) {
+	return p.parseNot()
+}
+
+// parseNot handles the low precedence 'not', 'and' and 'or' keywords
+func (p *rbParser) parseNot() (rbExpr, error) {
+	if p.isIdent("not") {
+		p.advance()
+
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return rbUnaryExpr{op: "!", operand: operand}, nil
+	}
+
+	left, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isIdent("and") || p.isIdent("or") {
+		op := "&&"
+		if p.advance().text == "or" {
+			op = "||"
+		}
+
+		right, err := p.parseTernary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = rbBinaryExpr{op: op, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *rbParser) parseTernary() (rbExpr, error) {
+	cond, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.isOp("?") {
+		return cond, nil
+	}
+
+	p.advance()
+
+	ifTrue, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.at(rbTokenSymbol) {
+		// 'a ? b :c' lexes the else branch as a symbol
+		return nil, newUnsupportedSyntaxError("ambiguous ternary expression")
+	}
+
+	err = p.expectOp(":")
+	if err != nil {
+		return nil, err
+	}
+
+	ifFalse, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+
+	return rbTernaryExpr{cond: cond, ifTrue: ifTrue, ifFalse: ifFalse}, nil
+}
+
+var rbBinaryPrecedence = []map[string]bool{
+	{"||": true},
+	{"&&": true},
+	{"==": true, "!=": true},
+	{"<": true, ">": true, "<=": true, ">=": true},
+	{"+": true, "-": true},
+	{"*": true, "/": true, "%": true},
+}
+
+func (p *rbParser) parseBinary(level int) (rbExpr, error) {
+	if level == len(rbBinaryPrecedence) {
+		return p.parseUnary()
+	}
+
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.at(rbTokenOp) && rbBinaryPrecedence[level][p.peek().text] {
+		op := p.advance().text
+		p.skipNewlines()
+
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		left = rbBinaryExpr{op: op, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *rbParser) parseUnary() (rbExpr, error) {
+	if p.isOp("!") || p.isOp("-") {
+		op := p.advance().text
+
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return rbUnaryExpr{op: op, operand: operand}, nil
+	}
+
+	return p.parsePostfix()
+}
+
+func (p *rbParser) parsePostfix() (rbExpr, error) {
+	expr, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.isOp(".") || p.isOp("::"):
+			p.advance()
+
+			if !p.at(rbTokenIdent) {
+				return nil, p.unexpected()
+			}
+
+			expr, err = p.parseCall(expr, p.advance().text)
+			if err != nil {
+				return nil, err
+			}
+
+		case p.isOp("[") && !p.peek().spaceBefore:
+			p.advance()
+
+			index, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+
+			err = p.expectOp("]")
+			if err != nil {
+				return nil, err
+			}
+
+			expr = rbIndexExpr{receiver: expr, index: index}
+
+		default:
+			return expr, nil
+		}
+	}
+}
+
+// parseCall parses arguments and an optional brace block of a method call.
+// 'do' blocks are only allowed at the statement level and are handled by the template parser.
+func (p *rbParser) parseCall(receiver rbExpr, method string) (rbExpr, error) {
+	call := rbCallExpr{receiver: receiver, method: method}
+	hasParens := p.isOp("(") && !p.peek().spaceBefore
+
+	if hasParens {
+		args, err := p.parseArgs()
+		if err != nil {
+			return nil, err
+		}
+
+		if n := len(args); n > 0 {
+			if block, ok := args[n-1].(rbBlockExpr); ok {
+				call.block = &block
+				args = args[:n-1]
+			}
+		}
+
+		call.args = args
+	}
+
+	if p.isOp("{") && call.block == nil {
+		block, err := p.parseBraceBlock()
+		if err != nil {
+			return nil, err
+		}
+		call.block = block
+	}
+
+	if !hasParens && call.block == nil {
+		if receiver == nil {
+			return rbIdentExpr{name: method}, nil
+		}
+
+		// Could be an attribute of an open struct; checked during evaluation
+		return call, nil
+	}
+
+	if !supportedRbMethods[method] {
+		return nil, newUnsupportedSyntaxError("unsupported method '%s'", method)
+	}
+
+	return call, nil
+}
+
+func (p *rbParser) parseArgs() ([]rbExpr, error) {
+	p.advance()
+	p.skipNewlines()
+
+	var args []rbExpr
+
+	for !p.isOp(")") {
+		if p.isOp("&:") {
+			// map(&:upcase) is shorthand for map { |x| x.upcase }
+			p.advance()
+
+			if !p.at(rbTokenIdent) {
+				return nil, p.unexpected()
+			}
+
+			method := p.advance().text
+			if !supportedRbMethods[method] {
+				return nil, newUnsupportedSyntaxError("unsupported method '%s'", method)
+			}
+
+			args = append(args, rbBlockExpr{
+				params: []string{"__item"},
+				body:   rbCallExpr{receiver: rbIdentExpr{name: "__item"}, method: method},
+			})
+		} else {
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+
+		p.skipNewlines()
+
+		if p.isOp(",") {
+			p.advance()
+			p.skipNewlines()
+			continue
+		}
+
+		if !p.isOp(")") {
+			return nil, p.unexpected()
+		}
+	}
+
+	p.advance()
+
+	return args, nil
+}
+
+func (p *rbParser) parseBraceBlock() (*rbBlockExpr, error) {
+	p.advance()
+
+	params, err := p.parseBlockParams()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipNewlines()
+
+	body, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipNewlines()
+
+	err = p.expectOp("}")
+	if err != nil {
+		return nil, err
+	}
+
+	return &rbBlockExpr{params: params, body: body}, nil
+}
+
+// parseBlockParams parses '|a, b|' when present
+func (p *rbParser) parseBlockParams() ([]string, error) {
+	if !p.isOp("|") {
+		return nil, nil
+	}
+
+	p.advance()
+
+	var params []string
+
+	for {
+		if p.isOp("(") || p.isOp("*") {
+			return nil, newUnsupportedSyntaxError("destructuring block parameters")
+		}
+
+		if !p.at(rbTokenIdent) {
+			return nil, p.unexpected()
+		}
+
+		params = append(params, p.advance().text)
+
+		if p.isOp(",") {
+			p.advance()
+			continue
+		}
+
+		return params, p.expectOp("|")
+	}
+}
+
+func (p *rbParser) parsePrimary() (rbExpr, error) {
+	tok := p.peek()
+
+	switch tok.kind {
+	case rbTokenInt:
+		p.advance()
+		i, err := strconv.ParseInt(tok.text, 10, 64)
+		if err != nil {
+			return nil, newUnsupportedSyntaxError("integer literal '%s'", tok.text)
+		}
+		return rbLiteralExpr{value: i}, nil
+
+	case rbTokenFloat:
+		p.advance()
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, newUnsupportedSyntaxError("float literal '%s'", tok.text)
+		}
+		return rbLiteralExpr{value: f}, nil
+
+	case rbTokenString:
+		p.advance()
+		return p.buildString(tok)
+
+	case rbTokenSymbol:
+		return nil, newUnsupportedSyntaxError("symbol ':%s'", tok.text)
+
+	case rbTokenConst:
+		p.advance()
+		if tok.text != "JSON" {
+			return nil, newUnsupportedSyntaxError("constant '%s'", tok.text)
+		}
+		return rbConstExpr{name: tok.text}, nil
+
+	case rbTokenIdent:
+		switch tok.text {
+		case "nil":
+			p.advance()
+			return rbLiteralExpr{value: nil}, nil
+		case "true":
+			p.advance()
+			return rbLiteralExpr{value: true}, nil
+		case "false":
+			p.advance()
+			return rbLiteralExpr{value: false}, nil
+		}
+
+		if rbKeywords[tok.text] {
+			return nil, newUnsupportedSyntaxError("keyword '%s' inside an expression", tok.text)
+		}
+
+		p.advance()
+
+		return p.parseCall(nil, tok.text)
+
+	case rbTokenOp:
+		switch tok.text {
+		case "(":
+			p.advance()
+			p.skipNewlines()
+
+			expr, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+
+			p.skipNewlines()
+
+			return expr, p.expectOp(")")
+
+		case "[":
+			return p.parseArray()
+
+		case "{":
+			return p.parseHash()
+		}
+	}
+
+	return nil, p.unexpected()
+}
+
+var rbKeywords = map[string]bool{
+	"if": true, "unless": true, "elsif": true, "else": true, "end": true, "do": true,
+	"then": true, "while": true, "until": true, "for": true, "in": true, "case": true,
+	"when": true, "begin": true, "rescue": true, "ensure": true, "def": true, "class": true,
+	"module": true, "return": true, "yield": true, "and": true, "or": true, "not": true,
+	"next": true, "break": true,
+}
+
+func (p *rbParser) buildString(tok rbToken) (rbExpr, error) {
+	var parts []rbStringExprPart
+
+	for _, part := range tok.parts {
+		if !part.isCode {
+			if len(part.literal) > 0 {
+				parts = append(parts, rbStringExprPart{literal: part.literal})
+			}
+			continue
+		}
+
+		expr, err := parseRbExpr(part.code)
+		if err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, rbStringExprPart{expr: expr})
+	}
+
+	return rbStringExpr{parts: parts}, nil
+}
+
+func (p *rbParser) parseArray() (rbExpr, error) {
+	p.advance()
+	p.skipNewlines()
+
+	var items []rbExpr
+
+	for !p.isOp("]") {
+		item, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+		p.skipNewlines()
+
+		if p.isOp(",") {
+			p.advance()
+			p.skipNewlines()
+		} else if !p.isOp("]") {
+			return nil, p.unexpected()
+		}
+	}
+
+	p.advance()
+
+	return rbArrayExpr{items: items}, nil
+}
+
+func (p *rbParser) parseHash() (rbExpr, error) {
+	p.advance()
+	p.skipNewlines()
+
+	var hash rbHashExpr
+
+	for !p.isOp("}") {
+		key, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.isOp("=>") {
+			return nil, newUnsupportedSyntaxError("hash literal without '=>'")
+		}
+
+		p.advance()
+		p.skipNewlines()
+
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		hash.keys = append(hash.keys, key)
+		hash.values = append(hash.values, value)
+		p.skipNewlines()
+
+		if p.isOp(",") {
+			p.advance()
+			p.skipNewlines()
+		} else if !p.isOp("}") {
+			return nil, p.unexpected()
+		}
+	}
+
+	p.advance()
+
+	return hash, nil
+}
+
+func (p *rbParser) peek() rbToken {
+	return p.tokens[p.pos]
+}
+
+func (p *rbParser) advance() rbToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != rbTokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *rbParser) at(kind rbTokenKind) bool {
+	return p.peek().kind == kind
+}
+
+func (p *rbParser) isOp(op string) bool {
+	return p.at(rbTokenOp) && p.peek().text == op
+}
+
+func (p *rbParser) isIdent(name string) bool {
+	return p.at(rbTokenIdent) && p.peek().text == name
+}
+
+func (p *rbParser) expectOp(op string) error {
+	if !p.isOp(op) {
+		return p.unexpected()
+	}
+	p.advance()
+	return nil
+}
+
+func (p *rbParser) skipNewlines() {
+	for p.at(rbTokenNewline) {
+		p.advance()
+	}
+}
+
+func (p *rbParser) unexpected() error {
+	return newUnsupportedSyntaxError("unexpected %s", p.peek())
+}
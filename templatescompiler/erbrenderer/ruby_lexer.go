@@ -0,0 +1,323 @@
+package erbrenderer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type rbTokenKind int
+
+const (
+	rbTokenEOF rbTokenKind = iota
+	rbTokenNewline
+	rbTokenIdent
+	rbTokenConst
+	rbTokenInt
+	rbTokenFloat
+	rbTokenString
+	rbTokenSymbol
+	rbTokenOp
+)
+
+type rbToken struct {
+	kind rbTokenKind
+	text string
+
+	// parts holds literal and interpolated pieces of double quoted strings
+	parts []rbStringPart
+
+	// spaceBefore is set when the token was preceded by whitespace
+	spaceBefore bool
+}
+
+type rbStringPart struct {
+	literal string
+	code    string
+	isCode  bool
+}
+
+var rbOperators = []string{
+	"**", "==", "!=", ">=", "<=", "&&", "||", "=>", "<<", "::", "&:",
+	"+", "-", "*", "/", "%", "<", ">", "!", "=", "(", ")", "[", "]", "{", "}",
+	",", ".", "|", "?", ":", ";",
+}
+
+type rbLexer struct {
+	src []rune
+	pos int
+}
+
+func lexRuby(src string) ([]rbToken, error) {
+	l := &rbLexer{src: []rune(src)}
+
+	var tokens []rbToken
+
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, tok)
+
+		if tok.kind == rbTokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *rbLexer) next() (rbToken, error) {
+	spaceBefore := false
+
+	for l.pos < len(l.src) {
+		ch := l.src[l.pos]
+		if ch == ' ' || ch == '\t' || ch == '\r' {
+			l.pos++
+			spaceBefore = true
+		} else if ch == '\\' && l.peek(1) == '\n' {
+			l.pos += 2
+			spaceBefore = true
+		} else if ch == '#' {
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		} else {
+			break
+		}
+	}
+
+	if l.pos >= len(l.src) {
+		return rbToken{kind: rbTokenEOF, spaceBefore: spaceBefore}, nil
+	}
+
+	tok, err := l.scan()
+	tok.spaceBefore = spaceBefore
+
+	return tok, err
+}
+
+func (l *rbLexer) scan() (rbToken, error) {
+	ch := l.src[l.pos]
+
+	switch {
+	case ch == '\n':
+		l.pos++
+		return rbToken{kind: rbTokenNewline, text: "\n"}, nil
+
+	case ch == '"':
+		return l.scanDoubleQuoted()
+
+	case ch == '\'':
+		return l.scanSingleQuoted()
+
+	case unicode.IsDigit(ch):
+		return l.scanNumber(), nil
+
+	case ch == ':' && l.isIdentStart(l.peek(1)):
+		l.pos++
+		name := l.scanIdentName()
+		return rbToken{kind: rbTokenSymbol, text: name}, nil
+
+	case l.isIdentStart(ch):
+		name := l.scanIdentName()
+		if unicode.IsUpper([]rune(name)[0]) {
+			return rbToken{kind: rbTokenConst, text: name}, nil
+		}
+		return rbToken{kind: rbTokenIdent, text: name}, nil
+	}
+
+	for _, op := range rbOperators {
+		if l.hasPrefix(op) {
+			l.pos += len([]rune(op))
+			return rbToken{kind: rbTokenOp, text: op}, nil
+		}
+	}
+
+	return rbToken{}, newUnsupportedSyntaxError("unexpected character '%c'", ch)
+}
+
+func (l *rbLexer) scanIdentName() string {
+	start := l.pos
+
+	for l.pos < len(l.src) && l.isIdentPart(l.src[l.pos]) {
+		l.pos++
+	}
+
+	// Method names such as nil? and empty? carry a trailing question mark
+	if l.pos < len(l.src) && (l.src[l.pos] == '?' || l.src[l.pos] == '!') && l.peek(1) != '=' {
+		l.pos++
+	}
+
+	return string(l.src[start:l.pos])
+}
+
+func (l *rbLexer) scanNumber() rbToken {
+	start := l.pos
+	kind := rbTokenInt
+
+	for l.pos < len(l.src) && (unicode.IsDigit(l.src[l.pos]) || l.src[l.pos] == '_') {
+		l.pos++
+	}
+
+	if l.pos < len(l.src) && l.src[l.pos] == '.' && unicode.IsDigit(l.peek(1)) {
+		kind = rbTokenFloat
+		l.pos++
+
+		for l.pos < len(l.src) && unicode.IsDigit(l.src[l.pos]) {
+			l.pos++
+		}
+	}
+
+	text := strings.Replace(string(l.src[start:l.pos]), "_", "", -1)
+
+	return rbToken{kind: kind, text: text}
+}
+
+func (l *rbLexer) scanSingleQuoted() (rbToken, error) {
+	l.pos++
+
+	var buf []rune
+
+	for l.pos < len(l.src) {
+		ch := l.src[l.pos]
+
+		switch {
+		case ch == '\'':
+			l.pos++
+			return rbToken{kind: rbTokenString, parts: []rbStringPart{{literal: string(buf)}}}, nil
+
+		case ch == '\\' && (l.peek(1) == '\'' || l.peek(1) == '\\'):
+			buf = append(buf, l.peek(1))
+			l.pos += 2
+
+		default:
+			buf = append(buf, ch)
+			l.pos++
+		}
+	}
+
+	return rbToken{}, newUnsupportedSyntaxError("unterminated string")
+}
+
+func (l *rbLexer) scanDoubleQuoted() (rbToken, error) {
+	l.pos++
+
+	var parts []rbStringPart
+	var buf []rune
+
+	for l.pos < len(l.src) {
+		ch := l.src[l.pos]
+
+		switch {
+		case ch == '"':
+			l.pos++
+			parts = append(parts, rbStringPart{literal: string(buf)})
+			return rbToken{kind: rbTokenString, parts: parts}, nil
+
+		case ch == '\\':
+			escaped, ok := rbEscapes[l.peek(1)]
+			if !ok {
+				return rbToken{}, newUnsupportedSyntaxError("unsupported escape sequence '\\%c'", l.peek(1))
+			}
+			buf = append(buf, escaped)
+			l.pos += 2
+
+		case ch == '#' && l.peek(1) == '{':
+			code, err := l.scanInterpolation()
+			if err != nil {
+				return rbToken{}, err
+			}
+			parts = append(parts, rbStringPart{literal: string(buf)}, rbStringPart{code: code, isCode: true})
+			buf = nil
+
+		default:
+			buf = append(buf, ch)
+			l.pos++
+		}
+	}
+
+	return rbToken{}, newUnsupportedSyntaxError("unterminated string")
+}
+
+var rbEscapes = map[rune]rune{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	's':  ' ',
+	'0':  0,
+	'e':  0x1b,
+	'"':  '"',
+	'\'': '\'',
+	'\\': '\\',
+	'#':  '#',
+}
+
+func (l *rbLexer) scanInterpolation() (string, error) {
+	l.pos += 2
+	start := l.pos
+	depth := 1
+
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				code := string(l.src[start:l.pos])
+				l.pos++
+				return code, nil
+			}
+		case '"', '\'':
+			// Skip over nested string literals, e.g. "#{p("name")}"
+			quote := l.src[l.pos]
+			for l.pos++; l.pos < len(l.src) && l.src[l.pos] != quote; l.pos++ {
+				if l.src[l.pos] == '\\' {
+					l.pos++
+				} else if quote == '"' && l.src[l.pos] == '#' && l.peek(1) == '{' {
+					return "", newUnsupportedSyntaxError("nested string interpolation")
+				}
+			}
+		}
+		l.pos++
+	}
+
+	return "", newUnsupportedSyntaxError("unterminated string interpolation")
+}
+
+func (l *rbLexer) hasPrefix(s string) bool {
+	rs := []rune(s)
+	if l.pos+len(rs) > len(l.src) {
+		return false
+	}
+	return string(l.src[l.pos:l.pos+len(rs)]) == s
+}
+
+func (l *rbLexer) peek(offset int) rune {
+	if l.pos+offset < len(l.src) {
+		return l.src[l.pos+offset]
+	}
+	return 0
+}
+
+func (l *rbLexer) isIdentStart(ch rune) bool {
+	return ch == '_' || unicode.IsLetter(ch)
+}
+
+func (l *rbLexer) isIdentPart(ch rune) bool {
+	return ch == '_' || unicode.IsLetter(ch) || unicode.IsDigit(ch)
+}
+
+func (t rbToken) String() string {
+	switch t.kind {
+	case rbTokenEOF:
+		return "end of input"
+	case rbTokenNewline:
+		return "newline"
+	case rbTokenString:
+		return "string literal"
+	default:
+		return fmt.Sprintf("'%s'", t.text)
+	}
+}
@@ -0,0 +1,404 @@
+package erbrenderer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// rbHash keeps insertion order of keys the same way Ruby hashes do
+type rbHash struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// rbOpenStruct wraps a hash so that its keys are accessible as attributes (spec.networks.default.ip)
+type rbOpenStruct struct {
+	hash *rbHash
+}
+
+func newRbHash() *rbHash {
+	return &rbHash{values: map[string]interface{}{}}
+}
+
+func (h *rbHash) Get(key string) (interface{}, bool) {
+	value, found := h.values[key]
+	return value, found
+}
+
+func (h *rbHash) Set(key string, value interface{}) {
+	if _, found := h.values[key]; !found {
+		h.keys = append(h.keys, key)
+	}
+	h.values[key] = value
+}
+
+func (h *rbHash) Len() int {
+	return len(h.keys)
+}
+
+// decodeRbJSON decodes JSON into Ruby-like values preserving object key order
+func decodeRbJSON(bs []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(bs))
+	decoder.UseNumber()
+
+	return decodeRbJSONValue(decoder)
+}
+
+func decodeRbJSONValue(decoder *json.Decoder) (interface{}, error) {
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch typedTok := tok.(type) {
+	case json.Delim:
+		switch typedTok {
+		case '{':
+			hash := newRbHash()
+
+			for decoder.More() {
+				keyTok, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := decodeRbJSONValue(decoder)
+				if err != nil {
+					return nil, err
+				}
+
+				hash.Set(keyTok.(string), value)
+			}
+
+			_, err = decoder.Token()
+
+			return hash, err
+
+		case '[':
+			array := []interface{}{}
+
+			for decoder.More() {
+				value, err := decodeRbJSONValue(decoder)
+				if err != nil {
+					return nil, err
+				}
+
+				array = append(array, value)
+			}
+
+			_, err = decoder.Token()
+
+			return array, err
+		}
+
+	case json.Number:
+		if i, err := typedTok.Int64(); err == nil {
+			return i, nil
+		}
+		if !strings.ContainsAny(typedTok.String(), ".eE") {
+			// Ruby keeps arbitrarily large integers exact
+			return nil, newUnsupportedSyntaxError("integer '%s' does not fit into 64 bits", typedTok)
+		}
+		return typedTok.Float64()
+	}
+
+	return tok, nil
+}
+
+func rbTruthy(value interface{}) bool {
+	switch typedValue := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return typedValue
+	default:
+		return true
+	}
+}
+
+// rbToS formats values the way Ruby's to_s (and therefore <%= %>) does
+func rbToS(value interface{}) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typedValue
+	default:
+		return rbInspect(value)
+	}
+}
+
+func rbInspect(value interface{}) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return "nil"
+
+	case bool:
+		return strconv.FormatBool(typedValue)
+
+	case int64:
+		return strconv.FormatInt(typedValue, 10)
+
+	case float64:
+		return rbFormatFloat(typedValue)
+
+	case string:
+		return rbInspectString(typedValue)
+
+	case []interface{}:
+		items := make([]string, len(typedValue))
+		for i, item := range typedValue {
+			items[i] = rbInspect(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+
+	case *rbHash:
+		items := make([]string, len(typedValue.keys))
+		for i, key := range typedValue.keys {
+			items[i] = rbInspectString(key) + "=>" + rbInspect(typedValue.values[key])
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+
+	case rbOpenStruct:
+		items := make([]string, len(typedValue.hash.keys))
+		for i, key := range typedValue.hash.keys {
+			items[i] = " " + key + "=" + rbInspect(typedValue.hash.values[key])
+		}
+		return "#<OpenStruct" + strings.Join(items, ",") + ">"
+
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+func rbInspectString(s string) string {
+	var buf bytes.Buffer
+
+	buf.WriteByte('"')
+
+	for _, ch := range s {
+		switch ch {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(ch)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case 0x1b:
+			buf.WriteString(`\e`)
+		case '#':
+			buf.WriteRune(ch)
+		default:
+			if ch < 0x20 {
+				fmt.Fprintf(&buf, `\x%02X`, ch)
+			} else {
+				buf.WriteRune(ch)
+			}
+		}
+	}
+
+	buf.WriteByte('"')
+
+	// Ruby escapes '#' only when it would start an interpolation
+	return strings.NewReplacer(`#{`, `\#{`, `#$`, `\#$`, `#@`, `\#@`).Replace(buf.String())
+}
+
+func rbFormatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+
+	abs := math.Abs(f)
+
+	if f == 0 || (abs >= 1e-4 && abs < 1e16) {
+		s := strconv.FormatFloat(f, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s
+	}
+
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	parts := strings.SplitN(s, "e", 2)
+	if !strings.Contains(parts[0], ".") {
+		parts[0] += ".0"
+	}
+
+	return parts[0] + "e" + parts[1]
+}
+
+// rbToJSON produces the same compact output as Ruby's JSON.generate
+func rbToJSON(value interface{}) (string, error) {
+	var buf bytes.Buffer
+
+	err := writeRbJSON(&buf, value)
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func writeRbJSON(buf *bytes.Buffer, value interface{}) error {
+	switch typedValue := value.(type) {
+	case nil:
+		buf.WriteString("null")
+
+	case bool, int64:
+		buf.WriteString(rbInspect(typedValue))
+
+	case float64:
+		if math.IsInf(typedValue, 0) || math.IsNaN(typedValue) {
+			return fmt.Errorf("%s not allowed in JSON", rbFormatFloat(typedValue))
+		}
+		buf.WriteString(rbFormatFloat(typedValue))
+
+	case string:
+		writeRbJSONString(buf, typedValue)
+
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range typedValue {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			err := writeRbJSON(buf, item)
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+
+	case *rbHash:
+		buf.WriteByte('{')
+		for i, key := range typedValue.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeRbJSONString(buf, key)
+			buf.WriteByte(':')
+			err := writeRbJSON(buf, typedValue.values[key])
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+
+	case rbOpenStruct:
+		// OpenStruct#to_json falls back to Object#to_json which serializes to_s
+		writeRbJSONString(buf, rbInspect(typedValue))
+
+	default:
+		return fmt.Errorf("Cannot convert %T to JSON", value)
+	}
+
+	return nil
+}
+
+func writeRbJSONString(buf *bytes.Buffer, s string) {
+	var strBuf bytes.Buffer
+
+	encoder := json.NewEncoder(&strBuf)
+	encoder.SetEscapeHTML(false)
+
+	// Encoding a string never fails
+	_ = encoder.Encode(s)
+
+	buf.Write(bytes.TrimSuffix(strBuf.Bytes(), []byte("\n")))
+}
+
+func rbEqual(a, b interface{}) bool {
+	if aNum, bNum, ok := rbNumbers(a, b); ok {
+		return aNum == bNum
+	}
+
+	switch typedA := a.(type) {
+	case []interface{}:
+		typedB, ok := b.([]interface{})
+		if !ok || len(typedA) != len(typedB) {
+			return false
+		}
+		for i := range typedA {
+			if !rbEqual(typedA[i], typedB[i]) {
+				return false
+			}
+		}
+		return true
+
+	case *rbHash:
+		typedB, ok := b.(*rbHash)
+		if !ok || typedA.Len() != typedB.Len() {
+			return false
+		}
+		for _, key := range typedA.keys {
+			bValue, found := typedB.Get(key)
+			if !found || !rbEqual(typedA.values[key], bValue) {
+				return false
+			}
+		}
+		return true
+
+	case rbOpenStruct:
+		typedB, ok := b.(rbOpenStruct)
+		return ok && rbEqual(typedA.hash, typedB.hash)
+
+	default:
+		return a == b
+	}
+}
+
+// rbNumbers converts a pair of numbers to floats for comparison
+func rbNumbers(a, b interface{}) (float64, float64, bool) {
+	aNum, aOk := rbNumber(a)
+	bNum, bOk := rbNumber(b)
+	return aNum, bNum, aOk && bOk
+}
+
+func rbNumber(value interface{}) (float64, bool) {
+	switch typedValue := value.(type) {
+	case int64:
+		return float64(typedValue), true
+	case float64:
+		return typedValue, true
+	default:
+		return 0, false
+	}
+}
+
+func rbClassName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		if value.(bool) {
+			return "TrueClass"
+		}
+		return "FalseClass"
+	case int64:
+		return "Integer"
+	case float64:
+		return "Float"
+	case string:
+		return "String"
+	case []interface{}:
+		return "Array"
+	case *rbHash:
+		return "Hash"
+	case rbOpenStruct:
+		return "OpenStruct"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
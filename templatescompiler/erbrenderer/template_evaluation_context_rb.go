@@ -29,6 +29,7 @@ class TemplateEvaluationContext
   def initialize(spec)
     @name = spec["job"]["name"] if spec["job"].is_a?(Hash)
     @index = spec["index"]
+    @links = spec["links"] || {}
 
     if !spec['job_properties'].nil?
       properties1 = spec['job_properties']
@@ -72,9 +73,19 @@ class TemplateEvaluationContext
     yield *values
     InactiveElseBlock.new
   end
-  
+
+  def link(name)
+    link_spec = @links[name]
+    raise UnknownLink.new(name) if link_spec.nil?
+
+    EvaluationLink.new(link_spec["instances"] || [], link_spec["properties"] || {}, link_spec["address"])
+  end
+
   def if_link(name)
-    false
+    return ActiveElseBlock.new(self) if @links[name].nil?
+
+    yield link(name)
+    InactiveElseBlock.new
   end
 
   private
@@ -131,6 +142,63 @@ class TemplateEvaluationContext
     end
   end
 
+  class UnknownLink < StandardError
+    def initialize(name)
+      super("Can't find link '#{name}'")
+    end
+  end
+
+  EvaluationLinkInstance = Struct.new(:name, :index, :id, :az, :address, :bootstrap)
+
+  class EvaluationLink
+    attr_reader :instances, :properties, :address
+
+    def initialize(instances, properties, address)
+      @instances = instances.map do |i|
+        EvaluationLinkInstance.new(i["name"], i["index"], i["id"], i["az"], i["address"], i["bootstrap"])
+      end
+      @properties = properties
+      @address = address
+    end
+
+    def p(*args)
+      names = Array(args[0])
+
+      names.each do |name|
+        result = lookup_property(@properties, name)
+        return result unless result.nil?
+      end
+
+      return args[1] if args.length == 2
+      raise UnknownProperty.new(names)
+    end
+
+    def if_p(*names)
+      values = names.map do |name|
+        value = lookup_property(@properties, name)
+        return ActiveElseBlock.new(self) if value.nil?
+        value
+      end
+
+      yield *values
+      InactiveElseBlock.new
+    end
+
+    private
+
+    def lookup_property(collection, name)
+      keys = name.split(".")
+      ref = collection
+
+      keys.each do |key|
+        ref = ref[key]
+        return nil if ref.nil?
+      end
+
+      ref
+    end
+  end
+
   class ActiveElseBlock
     def initialize(template)
       @context = template
@@ -18,6 +18,7 @@ type jobEvaluationContext struct {
 	globalProperties     biproperty.Map
 	deploymentName       string
 	address              string
+	instance             *InstanceSpec
 	uuidGen              boshuuid.Generator
 	logger               boshlog.Logger
 	logTag               string
@@ -34,9 +35,15 @@ type RootContext struct {
 	Deployment string     `json:"deployment"`
 	Address    string     `json:"address,omitempty"`
 
+	// Name of the instance group; only set when rendering for a given instance
+	Name string `json:"name,omitempty"`
+
 	// Usually is accessed with <%= spec.networks.default.ip %>
 	NetworkContexts map[string]networkContext `json:"networks"`
 
+	// Usually is accessed with <% if_link("db") do |db| %>
+	Links map[string]LinkSpec `json:"links,omitempty"`
+
 	//TODO: this should be a map[string]interface{}
 	GlobalProperties  biproperty.Map  `json:"global_properties"`  // values from manifest's top-level properties
 	ClusterProperties biproperty.Map  `json:"cluster_properties"` // values from instance group (deployment job) properties
@@ -54,6 +61,42 @@ type networkContext struct {
 	Gateway string `json:"gateway"`
 }
 
+// InstanceSpec describes an instance of an instance group
+// that job templates are rendered for outside of a Director
+type InstanceSpec struct {
+	Name      string
+	ID        string
+	Index     int
+	AZ        string
+	Bootstrap bool
+	Address   string
+
+	Networks map[string]NetworkSpec
+	Links    map[string]LinkSpec
+}
+
+type NetworkSpec struct {
+	IP      string
+	Netmask string
+	Gateway string
+}
+
+// LinkSpec is a consumed link as seen by link(...) in ERB templates
+type LinkSpec struct {
+	Address    string             `json:"address,omitempty"`
+	Properties biproperty.Map     `json:"properties"`
+	Instances  []LinkInstanceSpec `json:"instances"`
+}
+
+type LinkInstanceSpec struct {
+	Name      string `json:"name"`
+	ID        string `json:"id"`
+	Index     int    `json:"index"`
+	AZ        string `json:"az"`
+	Address   string `json:"address"`
+	Bootstrap bool   `json:"bootstrap"`
+}
+
 func NewJobEvaluationContext(
 	releaseJob bireljob.Job,
 	releaseJobProperties *biproperty.Map,
@@ -77,6 +120,30 @@ func NewJobEvaluationContext(
 	}
 }
 
+// NewInstanceJobEvaluationContext uses given instance instead of
+// the single instance of an environment created by create-env
+func NewInstanceJobEvaluationContext(
+	releaseJob bireljob.Job,
+	releaseJobProperties *biproperty.Map,
+	jobProperties biproperty.Map,
+	globalProperties biproperty.Map,
+	deploymentName string,
+	instance InstanceSpec,
+	logger boshlog.Logger,
+) bierbrenderer.TemplateEvaluationContext {
+	return jobEvaluationContext{
+		releaseJob:           releaseJob,
+		releaseJobProperties: releaseJobProperties,
+		jobProperties:        jobProperties,
+		globalProperties:     globalProperties,
+		deploymentName:       deploymentName,
+		address:              instance.Address,
+		instance:             &instance,
+		logTag:               "jobEvaluationContext",
+		logger:               logger,
+	}
+}
+
 func (ec jobEvaluationContext) MarshalJSON() ([]byte, error) {
 	defaultProperties := ec.propertyDefaults(ec.releaseJob.Properties)
 	var err error
@@ -98,9 +165,30 @@ func (ec jobEvaluationContext) MarshalJSON() ([]byte, error) {
 		context.Address = ec.address
 	}
 
-	context.ID, err = ec.uuidGen.Generate()
-	if err != nil {
-		return []byte{}, bosherr.WrapErrorf(err, "Setting job eval context's ID to UUID: %#v", context)
+	if ec.instance != nil {
+		context.Name = ec.instance.Name
+		context.ID = ec.instance.ID
+		context.Index = ec.instance.Index
+		context.AZ = ec.instance.AZ
+		context.Bootstrap = ec.instance.Bootstrap
+		context.Links = ec.instance.Links
+
+		if len(ec.instance.Networks) > 0 {
+			context.NetworkContexts = map[string]networkContext{}
+
+			for name, network := range ec.instance.Networks {
+				context.NetworkContexts[name] = networkContext{
+					IP:      network.IP,
+					Netmask: network.Netmask,
+					Gateway: network.Gateway,
+				}
+			}
+		}
+	} else {
+		context.ID, err = ec.uuidGen.Generate()
+		if err != nil {
+			return []byte{}, bosherr.WrapErrorf(err, "Setting job eval context's ID to UUID: %#v", context)
+		}
 	}
 
 	ec.logger.Debug(ec.logTag, "Marshalling context %#v", context)
@@ -112,11 +112,68 @@ var _ = Describe("JobEvaluationContext", func() {
 		})
 	})
 
+	Context("when rendering for a given instance", func() {
+		JustBeforeEach(func() {
+			jobEvaluationContext = NewInstanceJobEvaluationContext(
+				*releaseJob,
+				jobProperties,
+				instanceGroupProperties,
+				deploymentProperties,
+				"fake-deployment-name",
+				InstanceSpec{
+					Name:      "fake-instance-group",
+					ID:        "fake-id",
+					Index:     2,
+					AZ:        "z1",
+					Bootstrap: false,
+					Address:   "fake-address",
+					Networks: map[string]NetworkSpec{
+						"fake-network": {IP: "10.0.0.2", Netmask: "255.255.255.0", Gateway: "10.0.0.1"},
+					},
+					Links: map[string]LinkSpec{
+						"fake-link": {
+							Address:    "fake-link-address",
+							Properties: biproperty.Map{"port": 5432},
+							Instances:  []LinkInstanceSpec{{Name: "db", Index: 1, Address: "10.0.0.3"}},
+						},
+					},
+				},
+				boshlog.NewLogger(boshlog.LevelNone),
+			)
+		})
+
+		It("uses instance values instead of defaults", func() {
+			generatedContext := act()
+			Expect(generatedContext.Name).To(Equal("fake-instance-group"))
+			Expect(generatedContext.ID).To(Equal("fake-id"))
+			Expect(generatedContext.Index).To(Equal(2))
+			Expect(generatedContext.AZ).To(Equal("z1"))
+			Expect(generatedContext.Bootstrap).To(BeFalse())
+			Expect(generatedContext.Address).To(Equal("fake-address"))
+			Expect(generatedContext.NetworkContexts).To(HaveLen(1))
+			Expect(generatedContext.NetworkContexts["fake-network"].IP).To(Equal("10.0.0.2"))
+		})
+
+		It("includes links", func() {
+			generatedContext := act()
+			Expect(generatedContext.Links).To(HaveLen(1))
+			Expect(generatedContext.Links["fake-link"].Address).To(Equal("fake-link-address"))
+			Expect(generatedContext.Links["fake-link"].Properties).To(Equal(biproperty.Map{"port": float64(5432)}))
+			Expect(generatedContext.Links["fake-link"].Instances).To(Equal([]LinkInstanceSpec{{Name: "db", Index: 1, Address: "10.0.0.3"}}))
+		})
+
+		It("does not generate an id", func() {
+			uuidGen.GenerateError = errors.Error("boom")
+			_, err := jobEvaluationContext.MarshalJSON()
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
 	getValueFor := func(key string) string {
 		logger := boshlog.NewLogger(boshlog.LevelNone)
 		fs := boshsys.NewOsFileSystem(logger)
 		commandRunner := boshsys.NewExecCmdRunner(logger)
-		erbRenderer = erbrenderer.NewERBRenderer(fs, commandRunner, logger)
+		erbRenderer = erbrenderer.NewCompatERBRenderer(fs, commandRunner, logger)
 
 		srcFile, err := ioutil.TempFile("", "source.txt.erb")
 		Expect(err).ToNot(HaveOccurred())
@@ -15,6 +15,7 @@ import (
 
 type JobRenderer interface {
 	Render(releaseJob bireljob.Job, releaseJobProperties *biproperty.Map, jobProperties biproperty.Map, globalProperties biproperty.Map, deploymentName string, address string) (RenderedJob, error)
+	RenderInstance(releaseJob bireljob.Job, releaseJobProperties *biproperty.Map, jobProperties biproperty.Map, globalProperties biproperty.Map, deploymentName string, instance InstanceSpec) (RenderedJob, error)
 }
 
 type jobRenderer struct {
@@ -43,6 +44,16 @@ func NewJobRenderer(
 func (r *jobRenderer) Render(releaseJob bireljob.Job, releaseJobProperties *biproperty.Map, jobProperties biproperty.Map, globalProperties biproperty.Map, deploymentName string, address string) (RenderedJob, error) {
 	context := NewJobEvaluationContext(releaseJob, releaseJobProperties, jobProperties, globalProperties, deploymentName, address, r.uuidGen, r.logger)
 
+	return r.render(releaseJob, context)
+}
+
+func (r *jobRenderer) RenderInstance(releaseJob bireljob.Job, releaseJobProperties *biproperty.Map, jobProperties biproperty.Map, globalProperties biproperty.Map, deploymentName string, instance InstanceSpec) (RenderedJob, error) {
+	context := NewInstanceJobEvaluationContext(releaseJob, releaseJobProperties, jobProperties, globalProperties, deploymentName, instance, r.logger)
+
+	return r.render(releaseJob, context)
+}
+
+func (r *jobRenderer) render(releaseJob bireljob.Job, context bierbrenderer.TemplateEvaluationContext) (RenderedJob, error) {
 	sourcePath := releaseJob.ExtractedPath()
 
 	destinationPath, err := r.fs.TempDir("rendered-jobs")
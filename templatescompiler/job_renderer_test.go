@@ -29,6 +29,7 @@ var _ = Describe("JobRenderer", func() {
 		globalProperties     biproperty.Map
 		srcPath              string
 		dstPath              string
+		logger               boshlog.Logger
 	)
 
 	BeforeEach(func() {
@@ -54,7 +55,7 @@ var _ = Describe("JobRenderer", func() {
 			"director.yml.erb": "config/director.yml",
 		}
 
-		logger := boshlog.NewLogger(boshlog.LevelNone)
+		logger = boshlog.NewLogger(boshlog.LevelNone)
 
 		context = NewJobEvaluationContext(*job, &releaseJobProperties, jobProperties, globalProperties, "fake-deployment-name", "1.2.3.4", nil, logger)
 
@@ -121,4 +122,42 @@ var _ = Describe("JobRenderer", func() {
 			})
 		})
 	})
+
+	Describe("RenderInstance", func() {
+		It("renders job templates for given instance", func() {
+			instance := InstanceSpec{Name: "fake-instance-group", ID: "fake-id", Index: 1}
+
+			instanceContext := NewInstanceJobEvaluationContext(*job, &releaseJobProperties, jobProperties, globalProperties, "fake-deployment-name", instance, logger)
+
+			fakeERBRenderer.SetRenderBehavior(
+				filepath.Join(srcPath, "templates/director.yml.erb"),
+				filepath.Join(dstPath, "config/director.yml"),
+				instanceContext,
+				nil,
+			)
+
+			fakeERBRenderer.SetRenderBehavior(
+				filepath.Join(srcPath, "monit"),
+				filepath.Join(dstPath, "monit"),
+				instanceContext,
+				nil,
+			)
+
+			renderedjob, err := jobRenderer.RenderInstance(*job, &releaseJobProperties, jobProperties, globalProperties, "fake-deployment-name", instance)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeERBRenderer.RenderInputs).To(Equal([]fakebirender.RenderInput{
+				{
+					SrcPath: filepath.Join(srcPath, "templates/director.yml.erb"),
+					DstPath: filepath.Join(renderedjob.Path(), "config/director.yml"),
+					Context: instanceContext,
+				},
+				{
+					SrcPath: filepath.Join(srcPath, "monit"),
+					DstPath: filepath.Join(renderedjob.Path(), "monit"),
+					Context: instanceContext,
+				},
+			}))
+		})
+	})
 })
@@ -42,6 +42,17 @@ func (_mr *_MockJobRendererRecorder) Render(arg0, arg1, arg2, arg3, arg4, arg5 i
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Render", arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+func (_m *MockJobRenderer) RenderInstance(_param0 job.Job, _param1 *property.Map, _param2 property.Map, _param3 property.Map, _param4 string, _param5 templatescompiler.InstanceSpec) (templatescompiler.RenderedJob, error) {
+	ret := _m.ctrl.Call(_m, "RenderInstance", _param0, _param1, _param2, _param3, _param4, _param5)
+	ret0, _ := ret[0].(templatescompiler.RenderedJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockJobRendererRecorder) RenderInstance(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "RenderInstance", arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
 // Mock of JobListRenderer interface
 type MockJobListRenderer struct {
 	ctrl     *gomock.Controller
@@ -0,0 +1,110 @@
+package uaa
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+//go:generate counterfeiter . CallbackListener
+
+// CallbackListener receives authorization code that UAA
+// passes to the redirect URI after user logs in via browser.
+type CallbackListener interface {
+	RedirectURI() string
+	Code() (string, error)
+	Close() error
+}
+
+type LocalCallbackListener struct {
+	listener net.Listener
+	server   *http.Server
+
+	state   string
+	timeout time.Duration
+
+	results chan callbackResult
+}
+
+type callbackResult struct {
+	code string
+	err  error
+}
+
+// NewLocalCallbackListener listens on a random localhost port;
+// callbacks with state other than the given one are rejected
+func NewLocalCallbackListener(state string, timeout time.Duration) (*LocalCallbackListener, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Listening for authorization code callback")
+	}
+
+	l := &LocalCallbackListener{
+		listener: listener,
+
+		state:   state,
+		timeout: timeout,
+
+		results: make(chan callbackResult, 1),
+	}
+
+	l.server = &http.Server{Handler: http.HandlerFunc(l.handle)}
+
+	go l.server.Serve(listener)
+
+	return l, nil
+}
+
+func (l *LocalCallbackListener) RedirectURI() string {
+	return fmt.Sprintf("http://%s/callback", l.listener.Addr().String())
+}
+
+func (l *LocalCallbackListener) Code() (string, error) {
+	select {
+	case result := <-l.results:
+		return result.code, result.err
+	case <-time.After(l.timeout):
+		return "", bosherr.Errorf("Timed out after %s waiting for authorization code callback", l.timeout)
+	}
+}
+
+func (l *LocalCallbackListener) Close() error {
+	return l.listener.Close()
+}
+
+func (l *LocalCallbackListener) handle(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != "/callback" {
+		http.NotFound(w, req)
+		return
+	}
+
+	query := req.URL.Query()
+
+	if query.Get("state") != l.state {
+		// Ignore requests that were not initiated by this login
+		http.Error(w, "Unexpected state", http.StatusBadRequest)
+		return
+	}
+
+	var result callbackResult
+
+	if errCode := query.Get("error"); len(errCode) > 0 {
+		result.err = bosherr.Errorf("UAA responded with error '%s': %s", errCode, query.Get("error_description"))
+		http.Error(w, "Failed to log in. You may close this window.", http.StatusBadRequest)
+	} else if code := query.Get("code"); len(code) > 0 {
+		result.code = code
+		fmt.Fprintln(w, "Successfully logged in. You may close this window.")
+	} else {
+		http.Error(w, "Missing authorization code", http.StatusBadRequest)
+		return
+	}
+
+	// Only the first result is used
+	select {
+	case l.results <- result:
+	default:
+	}
+}
@@ -0,0 +1,88 @@
+package uaa_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/uaa"
+)
+
+var _ = Describe("LocalCallbackListener", func() {
+	var (
+		listener *LocalCallbackListener
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		listener, err = NewLocalCallbackListener("state", 5*time.Second)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		listener.Close()
+	})
+
+	get := func(query string) (int, string) {
+		resp, err := http.Get(listener.RedirectURI() + "?" + query)
+		Expect(err).ToNot(HaveOccurred())
+
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+
+		return resp.StatusCode, string(body)
+	}
+
+	It("listens on localhost", func() {
+		Expect(listener.RedirectURI()).To(MatchRegexp(`^http://127\.0\.0\.1:\d+/callback$`))
+	})
+
+	It("returns authorization code passed to redirect URI", func() {
+		status, body := get("code=code&state=state")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(ContainSubstring("Successfully logged in"))
+
+		code, err := listener.Code()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal("code"))
+	})
+
+	It("ignores callbacks with unexpected state", func() {
+		status, _ := get("code=other-code&state=other-state")
+		Expect(status).To(Equal(http.StatusBadRequest))
+
+		get("code=code&state=state")
+
+		code, err := listener.Code()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal("code"))
+	})
+
+	It("returns error passed to redirect URI", func() {
+		status, _ := get("error=access_denied&error_description=desc&state=state")
+		Expect(status).To(Equal(http.StatusBadRequest))
+
+		_, err := listener.Code()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("UAA responded with error 'access_denied': desc"))
+	})
+
+	It("returns error if callback is not received in time", func() {
+		listener.Close()
+
+		var err error
+
+		listener, err = NewLocalCallbackListener("state", 10*time.Millisecond)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = listener.Code()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Timed out after 10ms waiting for authorization code callback"))
+	})
+})
@@ -8,6 +8,8 @@ import (
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshhttp "github.com/cloudfoundry/bosh-utils/httpclient"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+
+	boshproxy "github.com/cloudfoundry/bosh-cli/proxy"
 )
 
 type Factory struct {
@@ -49,7 +51,7 @@ func (f Factory) httpClient(config Config) (Client, error) {
 		f.logger.Debug(f.logTag, "Using custom root CAs")
 	}
 
-	rawClient := boshhttp.CreateDefaultClient(certPool)
+	rawClient := boshproxy.CreateDefaultClient(certPool)
 
 	httpClient := boshhttp.NewHTTPClient(rawClient, f.logger)
 
@@ -9,6 +9,11 @@ type UAA interface {
 
 	ClientCredentialsGrant() (Token, error)
 	OwnerPasswordCredentialsGrant([]PromptAnswer) (AccessToken, error)
+
+	// Single sign-on via one-time passcode or browser redirect
+	PasscodeGrant(passcode string) (AccessToken, error)
+	AuthorizationCodeURL(redirectURI, state string, pkce PKCE) string
+	AuthorizationCodeGrant(code, redirectURI string, pkce PKCE) (AccessToken, error)
 }
 
 //go:generate counterfeiter . Token
@@ -0,0 +1,36 @@
+package uaa
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// PKCE (RFC 7636) proves that authorization code is exchanged
+// by the same client that requested it, which matters for public clients
+// such as bosh_cli that cannot keep a client secret.
+type PKCE struct {
+	Verifier  string
+	Challenge string
+}
+
+func NewPKCE(random io.Reader) (PKCE, error) {
+	bytes := make([]byte, 32)
+
+	_, err := io.ReadFull(random, bytes)
+	if err != nil {
+		return PKCE{}, bosherr.WrapError(err, "Generating PKCE code verifier")
+	}
+
+	verifier := base64.RawURLEncoding.EncodeToString(bytes)
+	challenge := sha256.Sum256([]byte(verifier))
+
+	return PKCE{
+		Verifier:  verifier,
+		Challenge: base64.RawURLEncoding.EncodeToString(challenge[:]),
+	}, nil
+}
+
+func (p PKCE) Method() string { return "S256" }
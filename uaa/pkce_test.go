@@ -0,0 +1,33 @@
+package uaa_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/uaa"
+)
+
+var _ = Describe("NewPKCE", func() {
+	It("generates verifier and S256 challenge from random bytes", func() {
+		// Example from RFC 7636 Appendix B
+		random := bytes.NewReader([]byte{
+			116, 24, 223, 180, 151, 153, 224, 37, 79, 250, 96, 125, 216, 173,
+			187, 186, 22, 212, 37, 77, 105, 214, 191, 240, 91, 88, 5, 88, 83,
+			132, 141, 121,
+		})
+
+		pkce, err := NewPKCE(random)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pkce.Verifier).To(Equal("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+		Expect(pkce.Challenge).To(Equal("E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"))
+		Expect(pkce.Method()).To(Equal("S256"))
+	})
+
+	It("returns error if random bytes cannot be read", func() {
+		_, err := NewPKCE(bytes.NewReader([]byte{1, 2, 3}))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Generating PKCE code verifier"))
+	})
+})
@@ -63,6 +63,37 @@ func (u UAAImpl) OwnerPasswordCredentialsGrant(answers []PromptAnswer) (AccessTo
 	return token, nil
 }
 
+func (u UAAImpl) PasscodeGrant(passcode string) (AccessToken, error) {
+	resp, err := u.client.PasscodeGrant(passcode)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.newAccessToken(resp), nil
+}
+
+func (u UAAImpl) AuthorizationCodeURL(redirectURI, state string, pkce PKCE) string {
+	return u.client.AuthorizationCodeURL(redirectURI, state, pkce)
+}
+
+func (u UAAImpl) AuthorizationCodeGrant(code, redirectURI string, pkce PKCE) (AccessToken, error) {
+	resp, err := u.client.AuthorizationCodeGrant(code, redirectURI, pkce)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.newAccessToken(resp), nil
+}
+
+func (u UAAImpl) newAccessToken(resp TokenResp) AccessToken {
+	return AccessTokenImpl{
+		client:       u.client,
+		type_:        resp.Type,
+		accessValue:  resp.AccessToken,
+		refreshValue: resp.RefreshToken,
+	}
+}
+
 func (c Client) ClientCredentialsGrant() (TokenResp, error) {
 	query := gourl.Values{}
 
@@ -101,6 +132,58 @@ func (c Client) OwnerPasswordCredentialsGrant(answers []PromptAnswer) (TokenResp
 	return resp, nil
 }
 
+// PasscodeGrant uses one-time passcode obtained from UAA's /passcode page
+func (c Client) PasscodeGrant(passcode string) (TokenResp, error) {
+	query := gourl.Values{}
+
+	query.Add("grant_type", "password")
+	query.Add("passcode", passcode)
+
+	path := fmt.Sprintf("/oauth/token?%s", query.Encode())
+
+	var resp TokenResp
+
+	err := c.clientRequest.Post(path, nil, &resp)
+	if err != nil {
+		return resp, bosherr.WrapErrorf(err, "Requesting token via passcode grant")
+	}
+
+	return resp, nil
+}
+
+func (c Client) AuthorizationCodeURL(redirectURI, state string, pkce PKCE) string {
+	query := gourl.Values{}
+
+	query.Add("client_id", c.clientRequest.client)
+	query.Add("response_type", "code")
+	query.Add("redirect_uri", redirectURI)
+	query.Add("state", state)
+	query.Add("code_challenge", pkce.Challenge)
+	query.Add("code_challenge_method", pkce.Method())
+
+	return fmt.Sprintf("%s/oauth/authorize?%s", c.clientRequest.endpoint, query.Encode())
+}
+
+func (c Client) AuthorizationCodeGrant(code, redirectURI string, pkce PKCE) (TokenResp, error) {
+	query := gourl.Values{}
+
+	query.Add("grant_type", "authorization_code")
+	query.Add("code", code)
+	query.Add("redirect_uri", redirectURI)
+	query.Add("code_verifier", pkce.Verifier)
+
+	path := fmt.Sprintf("/oauth/token?%s", query.Encode())
+
+	var resp TokenResp
+
+	err := c.clientRequest.Post(path, nil, &resp)
+	if err != nil {
+		return resp, bosherr.WrapErrorf(err, "Requesting token via authorization code grant")
+	}
+
+	return resp, nil
+}
+
 func (c Client) RefreshTokenGrant(refreshValue string) (TokenResp, error) {
 	query := gourl.Values{}
 
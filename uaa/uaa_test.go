@@ -196,4 +196,87 @@ var _ = Describe("UAA", func() {
 			Expect(err.Error()).To(ContainSubstring("Unmarshaling UAA response"))
 		})
 	})
+
+	Describe("PasscodeGrant", func() {
+		It("obtains access token based on one-time passcode", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/oauth/token", "grant_type=password&passcode=passcode"),
+					ghttp.VerifyBasicAuth("client", "client-secret"),
+					ghttp.RespondWith(http.StatusOK, `{
+						"token_type": "bearer",
+						"access_token": "access-token",
+						"refresh_token": "refresh-token"
+					}`),
+				),
+			)
+
+			token, err := uaa.PasscodeGrant("passcode")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(token.Value()).To(Equal("access-token"))
+			Expect(token.RefreshToken().Value()).To(Equal("refresh-token"))
+		})
+
+		It("returns error if token response in non-200", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/oauth/token"),
+					ghttp.RespondWith(http.StatusUnauthorized, ``),
+				),
+			)
+
+			_, err := uaa.PasscodeGrant("passcode")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Requesting token via passcode grant"))
+			Expect(err.Error()).To(ContainSubstring("UAA responded with non-successful status code"))
+		})
+	})
+
+	Describe("AuthorizationCodeURL", func() {
+		It("returns authorize URL with PKCE challenge", func() {
+			pkce := PKCE{Verifier: "verifier", Challenge: "challenge"}
+
+			url := uaa.AuthorizationCodeURL("http://127.0.0.1:1234/callback", "state", pkce)
+			Expect(url).To(Equal(server.URL() + "/oauth/authorize?" +
+				"client_id=client&code_challenge=challenge&code_challenge_method=S256&" +
+				"redirect_uri=http%3A%2F%2F127.0.0.1%3A1234%2Fcallback&response_type=code&state=state"))
+		})
+	})
+
+	Describe("AuthorizationCodeGrant", func() {
+		It("exchanges authorization code and PKCE verifier for access token", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/oauth/token",
+						"code=code&code_verifier=verifier&grant_type=authorization_code&redirect_uri=http%3A%2F%2F127.0.0.1%3A1234%2Fcallback"),
+					ghttp.VerifyBasicAuth("client", "client-secret"),
+					ghttp.RespondWith(http.StatusOK, `{
+						"token_type": "bearer",
+						"access_token": "access-token",
+						"refresh_token": "refresh-token"
+					}`),
+				),
+			)
+
+			pkce := PKCE{Verifier: "verifier", Challenge: "challenge"}
+
+			token, err := uaa.AuthorizationCodeGrant("code", "http://127.0.0.1:1234/callback", pkce)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(token.Value()).To(Equal("access-token"))
+			Expect(token.RefreshToken().Value()).To(Equal("refresh-token"))
+		})
+
+		It("returns error if token response in non-200", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/oauth/token"),
+					ghttp.RespondWith(http.StatusBadRequest, ``),
+				),
+			)
+
+			_, err := uaa.AuthorizationCodeGrant("code", "uri", PKCE{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Requesting token via authorization code grant"))
+		})
+	})
 })
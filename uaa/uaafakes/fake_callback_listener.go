@@ -0,0 +1,134 @@
+// This file was generated by counterfeiter
+package uaafakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/bosh-cli/uaa"
+)
+
+type FakeCallbackListener struct {
+	RedirectURIStub        func() string
+	redirectURIMutex       sync.RWMutex
+	redirectURIArgsForCall []struct{}
+	redirectURIReturns     struct {
+		result1 string
+	}
+	CodeStub        func() (string, error)
+	codeMutex       sync.RWMutex
+	codeArgsForCall []struct{}
+	codeReturns     struct {
+		result1 string
+		result2 error
+	}
+	CloseStub        func() error
+	closeMutex       sync.RWMutex
+	closeArgsForCall []struct{}
+	closeReturns     struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeCallbackListener) RedirectURI() string {
+	fake.redirectURIMutex.Lock()
+	fake.redirectURIArgsForCall = append(fake.redirectURIArgsForCall, struct{}{})
+	fake.recordInvocation("RedirectURI", []interface{}{})
+	fake.redirectURIMutex.Unlock()
+	if fake.RedirectURIStub != nil {
+		return fake.RedirectURIStub()
+	} else {
+		return fake.redirectURIReturns.result1
+	}
+}
+
+func (fake *FakeCallbackListener) RedirectURICallCount() int {
+	fake.redirectURIMutex.RLock()
+	defer fake.redirectURIMutex.RUnlock()
+	return len(fake.redirectURIArgsForCall)
+}
+
+func (fake *FakeCallbackListener) RedirectURIReturns(result1 string) {
+	fake.RedirectURIStub = nil
+	fake.redirectURIReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeCallbackListener) Code() (string, error) {
+	fake.codeMutex.Lock()
+	fake.codeArgsForCall = append(fake.codeArgsForCall, struct{}{})
+	fake.recordInvocation("Code", []interface{}{})
+	fake.codeMutex.Unlock()
+	if fake.CodeStub != nil {
+		return fake.CodeStub()
+	} else {
+		return fake.codeReturns.result1, fake.codeReturns.result2
+	}
+}
+
+func (fake *FakeCallbackListener) CodeCallCount() int {
+	fake.codeMutex.RLock()
+	defer fake.codeMutex.RUnlock()
+	return len(fake.codeArgsForCall)
+}
+
+func (fake *FakeCallbackListener) CodeReturns(result1 string, result2 error) {
+	fake.CodeStub = nil
+	fake.codeReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCallbackListener) Close() error {
+	fake.closeMutex.Lock()
+	fake.closeArgsForCall = append(fake.closeArgsForCall, struct{}{})
+	fake.recordInvocation("Close", []interface{}{})
+	fake.closeMutex.Unlock()
+	if fake.CloseStub != nil {
+		return fake.CloseStub()
+	} else {
+		return fake.closeReturns.result1
+	}
+}
+
+func (fake *FakeCallbackListener) CloseCallCount() int {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return len(fake.closeArgsForCall)
+}
+
+func (fake *FakeCallbackListener) CloseReturns(result1 error) {
+	fake.CloseStub = nil
+	fake.closeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCallbackListener) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.redirectURIMutex.RLock()
+	defer fake.redirectURIMutex.RUnlock()
+	fake.codeMutex.RLock()
+	defer fake.codeMutex.RUnlock()
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeCallbackListener) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ uaa.CallbackListener = new(FakeCallbackListener)
@@ -39,6 +39,36 @@ type FakeUAA struct {
 		result1 uaa.AccessToken
 		result2 error
 	}
+	PasscodeGrantStub        func(string) (uaa.AccessToken, error)
+	passcodeGrantMutex       sync.RWMutex
+	passcodeGrantArgsForCall []struct {
+		arg1 string
+	}
+	passcodeGrantReturns struct {
+		result1 uaa.AccessToken
+		result2 error
+	}
+	AuthorizationCodeURLStub        func(string, string, uaa.PKCE) string
+	authorizationCodeURLMutex       sync.RWMutex
+	authorizationCodeURLArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 uaa.PKCE
+	}
+	authorizationCodeURLReturns struct {
+		result1 string
+	}
+	AuthorizationCodeGrantStub        func(string, string, uaa.PKCE) (uaa.AccessToken, error)
+	authorizationCodeGrantMutex       sync.RWMutex
+	authorizationCodeGrantArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 uaa.PKCE
+	}
+	authorizationCodeGrantReturns struct {
+		result1 uaa.AccessToken
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -167,6 +197,111 @@ func (fake *FakeUAA) OwnerPasswordCredentialsGrantReturns(result1 uaa.AccessToke
 	}{result1, result2}
 }
 
+func (fake *FakeUAA) PasscodeGrant(arg1 string) (uaa.AccessToken, error) {
+	fake.passcodeGrantMutex.Lock()
+	fake.passcodeGrantArgsForCall = append(fake.passcodeGrantArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("PasscodeGrant", []interface{}{arg1})
+	fake.passcodeGrantMutex.Unlock()
+	if fake.PasscodeGrantStub != nil {
+		return fake.PasscodeGrantStub(arg1)
+	} else {
+		return fake.passcodeGrantReturns.result1, fake.passcodeGrantReturns.result2
+	}
+}
+
+func (fake *FakeUAA) PasscodeGrantCallCount() int {
+	fake.passcodeGrantMutex.RLock()
+	defer fake.passcodeGrantMutex.RUnlock()
+	return len(fake.passcodeGrantArgsForCall)
+}
+
+func (fake *FakeUAA) PasscodeGrantArgsForCall(i int) string {
+	fake.passcodeGrantMutex.RLock()
+	defer fake.passcodeGrantMutex.RUnlock()
+	return fake.passcodeGrantArgsForCall[i].arg1
+}
+
+func (fake *FakeUAA) PasscodeGrantReturns(result1 uaa.AccessToken, result2 error) {
+	fake.PasscodeGrantStub = nil
+	fake.passcodeGrantReturns = struct {
+		result1 uaa.AccessToken
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUAA) AuthorizationCodeURL(arg1 string, arg2 string, arg3 uaa.PKCE) string {
+	fake.authorizationCodeURLMutex.Lock()
+	fake.authorizationCodeURLArgsForCall = append(fake.authorizationCodeURLArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 uaa.PKCE
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("AuthorizationCodeURL", []interface{}{arg1, arg2, arg3})
+	fake.authorizationCodeURLMutex.Unlock()
+	if fake.AuthorizationCodeURLStub != nil {
+		return fake.AuthorizationCodeURLStub(arg1, arg2, arg3)
+	} else {
+		return fake.authorizationCodeURLReturns.result1
+	}
+}
+
+func (fake *FakeUAA) AuthorizationCodeURLCallCount() int {
+	fake.authorizationCodeURLMutex.RLock()
+	defer fake.authorizationCodeURLMutex.RUnlock()
+	return len(fake.authorizationCodeURLArgsForCall)
+}
+
+func (fake *FakeUAA) AuthorizationCodeURLArgsForCall(i int) (string, string, uaa.PKCE) {
+	fake.authorizationCodeURLMutex.RLock()
+	defer fake.authorizationCodeURLMutex.RUnlock()
+	return fake.authorizationCodeURLArgsForCall[i].arg1, fake.authorizationCodeURLArgsForCall[i].arg2, fake.authorizationCodeURLArgsForCall[i].arg3
+}
+
+func (fake *FakeUAA) AuthorizationCodeURLReturns(result1 string) {
+	fake.AuthorizationCodeURLStub = nil
+	fake.authorizationCodeURLReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeUAA) AuthorizationCodeGrant(arg1 string, arg2 string, arg3 uaa.PKCE) (uaa.AccessToken, error) {
+	fake.authorizationCodeGrantMutex.Lock()
+	fake.authorizationCodeGrantArgsForCall = append(fake.authorizationCodeGrantArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 uaa.PKCE
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("AuthorizationCodeGrant", []interface{}{arg1, arg2, arg3})
+	fake.authorizationCodeGrantMutex.Unlock()
+	if fake.AuthorizationCodeGrantStub != nil {
+		return fake.AuthorizationCodeGrantStub(arg1, arg2, arg3)
+	} else {
+		return fake.authorizationCodeGrantReturns.result1, fake.authorizationCodeGrantReturns.result2
+	}
+}
+
+func (fake *FakeUAA) AuthorizationCodeGrantCallCount() int {
+	fake.authorizationCodeGrantMutex.RLock()
+	defer fake.authorizationCodeGrantMutex.RUnlock()
+	return len(fake.authorizationCodeGrantArgsForCall)
+}
+
+func (fake *FakeUAA) AuthorizationCodeGrantArgsForCall(i int) (string, string, uaa.PKCE) {
+	fake.authorizationCodeGrantMutex.RLock()
+	defer fake.authorizationCodeGrantMutex.RUnlock()
+	return fake.authorizationCodeGrantArgsForCall[i].arg1, fake.authorizationCodeGrantArgsForCall[i].arg2, fake.authorizationCodeGrantArgsForCall[i].arg3
+}
+
+func (fake *FakeUAA) AuthorizationCodeGrantReturns(result1 uaa.AccessToken, result2 error) {
+	fake.AuthorizationCodeGrantStub = nil
+	fake.authorizationCodeGrantReturns = struct {
+		result1 uaa.AccessToken
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeUAA) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -178,6 +313,12 @@ func (fake *FakeUAA) Invocations() map[string][][]interface{} {
 	defer fake.clientCredentialsGrantMutex.RUnlock()
 	fake.ownerPasswordCredentialsGrantMutex.RLock()
 	defer fake.ownerPasswordCredentialsGrantMutex.RUnlock()
+	fake.passcodeGrantMutex.RLock()
+	defer fake.passcodeGrantMutex.RUnlock()
+	fake.authorizationCodeURLMutex.RLock()
+	defer fake.authorizationCodeURLMutex.RUnlock()
+	fake.authorizationCodeGrantMutex.RLock()
+	defer fake.authorizationCodeGrantMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -77,6 +77,10 @@ func (ui *ColorUI) PrintTable(table Table) {
 	ui.parent.PrintTable(table)
 }
 
+func (ui *ColorUI) PrintJSON(val interface{}) {
+	ui.parent.PrintJSON(val)
+}
+
 func (ui *ColorUI) AskForText(label string) (string, error) {
 	return ui.parent.AskForText(label)
 }
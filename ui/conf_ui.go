@@ -71,6 +71,10 @@ func (ui *ConfUI) PrintTable(table Table) {
 	ui.parent.PrintTable(table)
 }
 
+func (ui *ConfUI) PrintJSON(val interface{}) {
+	ui.parent.PrintJSON(val)
+}
+
 func (ui *ConfUI) AskForText(label string) (string, error) {
 	return ui.parent.AskForText(label)
 }
@@ -1,12 +1,18 @@
 package fakes
 
 import (
+	"sync"
+
 	biui "github.com/cloudfoundry/bosh-cli/ui"
 )
 
 type FakeStage struct {
 	PerformCalls []*PerformCall
 	SubStages    []*FakeStage
+
+	ConcurrentCalled bool
+
+	lock sync.Mutex
 }
 
 type PerformCall struct {
@@ -24,11 +30,13 @@ func (s *FakeStage) Perform(name string, closure func() error) error {
 
 	call := &PerformCall{Name: name}
 
+	s.lock.Lock()
 	// lazily instantiate to make matching sub-stages easier
 	if s.PerformCalls == nil {
 		s.PerformCalls = []*PerformCall{}
 	}
 	s.PerformCalls = append(s.PerformCalls, call) //We want to record the calls in the same order as the real implementation would print them
+	s.lock.Unlock()
 
 	err := closure()
 
@@ -71,3 +79,8 @@ func (s *FakeStage) PerformComplex(name string, closure func(biui.Stage) error)
 
 	return err
 }
+
+func (s *FakeStage) Concurrent() biui.Stage {
+	s.ConcurrentCalled = true
+	return s
+}
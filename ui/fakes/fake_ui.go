@@ -15,6 +15,8 @@ type FakeUI struct {
 	Table  Table
 	Tables []Table
 
+	JSONs []interface{}
+
 	AskedTextLabels []string
 	AskedText       []Answer
 
@@ -69,6 +71,10 @@ func (ui *FakeUI) PrintTable(table Table) {
 	ui.Tables = append(ui.Tables, table)
 }
 
+func (ui *FakeUI) PrintJSON(val interface{}) {
+	ui.JSONs = append(ui.JSONs, val)
+}
+
 func (ui *FakeUI) AskForText(label string) (string, error) {
 	ui.AskedTextLabels = append(ui.AskedTextLabels, label)
 	answer := ui.AskedText[0]
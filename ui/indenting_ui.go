@@ -42,6 +42,10 @@ func (ui *indentingUI) PrintTable(table Table) {
 	ui.parent.PrintTable(table)
 }
 
+func (ui *indentingUI) PrintJSON(val interface{}) {
+	ui.parent.PrintJSON(val)
+}
+
 func (ui *indentingUI) AskForText(label string) (string, error) {
 	return ui.parent.AskForText(label)
 }
@@ -16,6 +16,10 @@ type UI interface {
 
 	PrintTable(Table)
 
+	// PrintJSON prints value marshalled as JSON; JSON UI outputs it
+	// as the whole document instead of collected tables, blocks and lines
+	PrintJSON(interface{})
+
 	AskForText(label string) (string, error)
 	AskForChoice(label string, options []string) (int, error)
 	AskForPassword(label string) (string, error)
@@ -14,6 +14,10 @@ type jsonUI struct {
 	parent UI
 	uiResp uiResp
 
+	// When set, document is output instead of uiResp
+	document    interface{}
+	hasDocument bool
+
 	logTag string
 	logger boshlog.Logger
 }
@@ -82,6 +86,11 @@ func (ui *jsonUI) PrintTable(table Table) {
 	ui.uiResp.Tables = append(ui.uiResp.Tables, resp)
 }
 
+func (ui *jsonUI) PrintJSON(val interface{}) {
+	ui.document = val
+	ui.hasDocument = true
+}
+
 func (ui *jsonUI) AskForText(_ string) (string, error) {
 	panic("Cannot ask for input in JSON UI")
 }
@@ -105,6 +114,17 @@ func (ui *jsonUI) IsInteractive() bool {
 func (ui *jsonUI) Flush() {
 	defer ui.parent.Flush()
 
+	if ui.hasDocument {
+		bytes, err := json.MarshalIndent(ui.document, "", "    ")
+		if err != nil {
+			ui.logger.Error(ui.logTag, "Failed to marshal UI document")
+			return
+		}
+
+		ui.parent.PrintBlock(string(bytes))
+		return
+	}
+
 	if !reflect.DeepEqual(ui.uiResp, uiResp{}) {
 		bytes, err := json.MarshalIndent(ui.uiResp, "", "    ")
 		if err != nil {
@@ -110,6 +110,20 @@ var _ = Describe("JSONUI", func() {
 		})
 	})
 
+	Describe("PrintJSON", func() {
+		It("outputs value as the whole document instead of tables, blocks and lines", func() {
+			ui.PrintLinef("fake-line1")
+			ui.PrintJSON(map[string]interface{}{"key": []string{"val"}})
+			ui.Flush()
+
+			Expect(parentUI.Blocks).To(Equal([]string{`{
+    "key": [
+        "val"
+    ]
+}`}))
+		})
+	})
+
 	Describe("PrintTable", func() {
 		It("includes in Tables", func() {
 			table := Table{
@@ -40,6 +40,10 @@ func (ui *nonInteractiveUI) PrintTable(table Table) {
 	ui.parent.PrintTable(table)
 }
 
+func (ui *nonInteractiveUI) PrintJSON(val interface{}) {
+	ui.parent.PrintJSON(val)
+}
+
 func (ui *nonInteractiveUI) AskForText(label string) (string, error) {
 	panic("Cannot ask for input in non-interactive UI")
 }
@@ -40,6 +40,8 @@ func (ui *NonTTYUI) PrintTable(table Table) {
 	ui.parent.PrintTable(table)
 }
 
+func (ui *NonTTYUI) PrintJSON(val interface{}) { ui.parent.PrintJSON(val) }
+
 func (ui *NonTTYUI) AskForText(label string) (string, error) {
 	return ui.parent.AskForText(label)
 }
@@ -57,6 +57,11 @@ func (ui *paddingUI) PrintTable(table Table) {
 	ui.parent.PrintTable(table)
 }
 
+func (ui *paddingUI) PrintJSON(val interface{}) {
+	ui.padBefore(paddingUIModeRaw)
+	ui.parent.PrintJSON(val)
+}
+
 func (ui *paddingUI) AskForText(label string) (string, error) {
 	ui.padBefore(paddingUIModeAskText)
 	return ui.parent.AskForText(label)
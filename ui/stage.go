@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"sync"
 	"time"
 
 	biuifmt "github.com/cloudfoundry/bosh-cli/ui/fmt"
@@ -11,6 +12,9 @@ import (
 type Stage interface {
 	Perform(name string, closure func() error) error
 	PerformComplex(name string, closure func(Stage) error) error
+
+	// Concurrent returns a stage that can be used from multiple goroutines
+	Concurrent() Stage
 }
 
 type stage struct {
@@ -83,3 +87,51 @@ func (s *stage) elapsedSince(startTime time.Time) string {
 func (s *stage) newSubStage() Stage {
 	return NewStage(NewIndentingUI(s.ui), s.timeService, s.logger)
 }
+
+func (s *stage) Concurrent() Stage {
+	return &concurrentStage{stage: s}
+}
+
+// concurrentStage prints each step on a single line once it completes
+// so that output of steps performed at the same time does not interleave
+type concurrentStage struct {
+	stage *stage
+	lock  sync.Mutex
+}
+
+func (s *concurrentStage) Perform(name string, closure func() error) error {
+	startTime := s.stage.timeService.Now()
+	err := closure()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.stage.simpleMode {
+		s.stage.ui.BeginLinef("\n")
+		s.stage.simpleMode = true
+	}
+
+	if err != nil {
+		if skipErr, ok := err.(SkipStageError); ok {
+			s.stage.ui.BeginLinef("%s... Skipped [%s] (%s)\n", name, skipErr.SkipMessage(), s.stage.elapsedSince(startTime))
+			s.stage.logger.Info(s.stage.logTag, "Skipped stage '%s': %s", name, skipErr.Error())
+			return nil
+		}
+		s.stage.ui.BeginLinef("%s... Failed (%s)\n", name, s.stage.elapsedSince(startTime))
+		return err
+	}
+	s.stage.ui.BeginLinef("%s... Finished (%s)\n", name, s.stage.elapsedSince(startTime))
+	return nil
+}
+
+// PerformComplex is not performed concurrently since its sub stages print progress
+func (s *concurrentStage) PerformComplex(name string, closure func(Stage) error) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.stage.PerformComplex(name, closure)
+}
+
+func (s *concurrentStage) Concurrent() Stage {
+	return s
+}
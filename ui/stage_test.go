@@ -204,4 +204,64 @@ Failed Complex stage 1 (00:01:00)
 			Expect(actionsPerformed).To(Equal([]string{"1"}))
 		})
 	})
+
+	Describe("Concurrent", func() {
+		var concurrentStage Stage
+
+		BeforeEach(func() {
+			concurrentStage = stage.Concurrent()
+		})
+
+		It("prints each stage on a single line once it completes", func() {
+			started := make(chan struct{})
+			release := make(chan struct{})
+			done := make(chan error)
+
+			go func() {
+				done <- concurrentStage.Perform("Simple stage 1", func() error {
+					close(started)
+					<-release
+					return nil
+				})
+			}()
+
+			<-started
+
+			err := concurrentStage.Perform("Simple stage 2", func() error {
+				return bosherr.Error("fake-stage-2-error")
+			})
+			Expect(err).To(HaveOccurred())
+
+			close(release)
+			Expect(<-done).ToNot(HaveOccurred())
+
+			Expect(uiOut.String()).To(Equal(
+				"Simple stage 2... Failed (00:00:00)\n" +
+					"Simple stage 1... Finished (00:00:00)\n",
+			))
+		})
+
+		It("logs skip errors", func() {
+			err := concurrentStage.Perform("Simple stage 1", func() error {
+				fakeTimeService.Increment(time.Minute)
+				return NewSkipStageError(bosherr.Error("fake-skip-error"), "fake-skip-message")
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(uiOut.String()).To(Equal("Simple stage 1... Skipped [fake-skip-message] (00:01:00)\n"))
+			Expect(logOutBuffer.String()).To(ContainSubstring("fake-skip-message: fake-skip-error"))
+		})
+
+		It("starts a new line after complex stage", func() {
+			err := concurrentStage.PerformComplex("Complex stage 1", func(stage Stage) error {
+				return stage.Perform("Simple stage A", func() error { return nil })
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = concurrentStage.Perform("Simple stage 1", func() error { return nil })
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(uiOut.String()).To(HaveSuffix("Finished Complex stage 1 (00:00:00)\n\nSimple stage 1... Finished (00:00:00)\n"))
+		})
+	})
 })
@@ -142,3 +142,46 @@ func (t Table) printFooter(w io.Writer, num int) error {
 
 	return nil
 }
+
+// AddColumn returns a copy of the table with a new first column
+// that holds the same value in every row, e.g. to tell apart
+// rows of tables collected from multiple sources.
+// Sections are flattened into rows since first column is taken.
+func (t Table) AddColumn(header string, val Value) Table {
+	if len(t.HeaderVals) > 0 {
+		t.HeaderVals = append([]Value{ValueString{header}}, t.HeaderVals...)
+	} else if len(t.Header) > 0 {
+		t.Header = append([]string{header}, t.Header...)
+	}
+
+	sortBy := []ColumnSort{{Column: 0, Asc: true}}
+
+	for _, s := range t.SortBy {
+		sortBy = append(sortBy, ColumnSort{Column: s.Column + 1, Asc: s.Asc})
+	}
+
+	t.SortBy = sortBy
+
+	var rows [][]Value
+
+	for _, s := range t.Sections {
+		for _, r := range s.Rows {
+			row := append([]Value{val}, r...)
+
+			if s.FirstColumn != nil && len(s.FirstColumn.String()) > 0 && len(r) > 0 {
+				row[1] = s.FirstColumn
+			}
+
+			rows = append(rows, row)
+		}
+	}
+
+	for _, r := range t.Rows {
+		rows = append(rows, append([]Value{val}, r...))
+	}
+
+	t.Sections = nil
+	t.Rows = rows
+
+	return t
+}
@@ -432,4 +432,66 @@ Header1|Header2|
 `))
 		})
 	})
+
+	Describe("AddColumn", func() {
+		It("adds column with given value to the front of every row", func() {
+			table := Table{
+				Content: "things",
+				Header:  []string{"Header1", "Header2"},
+
+				SortBy: []ColumnSort{{Column: 1, Asc: false}},
+
+				Rows: [][]Value{
+					{ValueString{"r1c1"}, ValueString{"r1c2"}},
+					{ValueString{"r2c1"}, ValueString{"r2c2"}},
+				},
+			}
+
+			Expect(table.AddColumn("Env", ValueString{"env"})).To(Equal(Table{
+				Content: "things",
+				Header:  []string{"Env", "Header1", "Header2"},
+
+				SortBy: []ColumnSort{{Column: 0, Asc: true}, {Column: 2, Asc: false}},
+
+				Rows: [][]Value{
+					{ValueString{"env"}, ValueString{"r1c1"}, ValueString{"r1c2"}},
+					{ValueString{"env"}, ValueString{"r2c1"}, ValueString{"r2c2"}},
+				},
+			}))
+
+			Expect(table.Rows[0]).To(HaveLen(2))
+		})
+
+		It("adds header value if header values are used", func() {
+			table := Table{HeaderVals: []Value{ValueString{"Header1"}}}
+
+			Expect(table.AddColumn("Env", ValueString{"env"}).HeaderVals).To(Equal(
+				[]Value{ValueString{"Env"}, ValueString{"Header1"}}))
+		})
+
+		It("flattens sections into rows keeping section first column", func() {
+			table := Table{
+				Sections: []Section{
+					{
+						FirstColumn: ValueString{"s1c1"},
+						Rows: [][]Value{
+							{ValueString{""}, ValueString{"r1c2"}},
+							{ValueString{""}, ValueString{"r2c2"}},
+						},
+					},
+					{
+						Rows: [][]Value{
+							{ValueString{"r3c1"}, ValueString{"r3c2"}},
+						},
+					},
+				},
+			}
+
+			Expect(table.AddColumn("Env", ValueString{"env"}).Rows).To(Equal([][]Value{
+				{ValueString{"env"}, ValueString{"s1c1"}, ValueString{"r1c2"}},
+				{ValueString{"env"}, ValueString{"s1c1"}, ValueString{"r2c2"}},
+				{ValueString{"env"}, ValueString{"r3c1"}, ValueString{"r3c2"}},
+			}))
+		})
+	})
 })
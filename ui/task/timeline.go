@@ -0,0 +1,315 @@
+package task
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// TimelineReporter collects task event output instead of printing it
+// so that stages and steps could be analyzed once task finishes
+type TimelineReporter struct {
+	state      string
+	events     []Event
+	outputRest string
+	err        error
+}
+
+func NewTimelineReporter() *TimelineReporter {
+	return &TimelineReporter{}
+}
+
+func (r *TimelineReporter) TaskStarted(id int) {}
+
+func (r *TimelineReporter) TaskFinished(id int, state string) {
+	r.state = state
+}
+
+func (r *TimelineReporter) TaskOutputChunk(id int, chunk []byte) {
+	r.outputRest += string(chunk)
+
+	for {
+		idx := strings.Index(r.outputRest, "\n")
+		if idx == -1 {
+			return
+		}
+
+		if len(r.outputRest[0:idx]) > 0 {
+			r.addEvent(r.outputRest[0:idx])
+		}
+
+		r.outputRest = r.outputRest[idx+1:]
+	}
+}
+
+func (r *TimelineReporter) addEvent(str string) {
+	var event Event
+
+	err := json.Unmarshal([]byte(str), &event)
+	if err != nil {
+		if r.err == nil {
+			r.err = bosherr.WrapErrorf(err, "Unmarshalling task event '%s'", str)
+		}
+		return
+	}
+
+	r.events = append(r.events, event)
+}
+
+// State returns last known task state; empty if task state was never retrieved
+func (r *TimelineReporter) State() string { return r.state }
+
+func (r *TimelineReporter) Timeline() (Timeline, error) {
+	if r.err != nil {
+		return Timeline{}, r.err
+	}
+
+	return NewTimeline(r.events), nil
+}
+
+type Timeline struct {
+	Stages []TimelineStage
+
+	// Task level errors (e.g. failure to acquire a lock)
+	Errors []string
+
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+type TimelineStage struct {
+	Name  string // e.g. "Updating instance api"
+	Steps []TimelineStep
+}
+
+type TimelineStep struct {
+	Stage string
+	Name  string // e.g. "api/7d3a5c (0) (canary)"
+
+	// Either finished, failed or started if step never finished
+	State string
+	Error string
+
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+type TimelineInstance struct {
+	Name     string // e.g. "api/7d3a5c"
+	Steps    int
+	Failed   bool
+	Duration time.Duration
+}
+
+func NewTimeline(events []Event) Timeline {
+	var timeline Timeline
+
+	for _, event := range events {
+		if !event.IsWorthKeeping() || event.Type == EventTypeDeprecation || event.Type == EventTypeWarning {
+			continue
+		}
+
+		if timeline.StartedAt.IsZero() {
+			timeline.StartedAt = event.Time()
+		}
+
+		timeline.FinishedAt = event.Time()
+
+		if event.Error != nil {
+			timeline.Errors = append(timeline.Errors, event.Error.Message)
+			continue
+		}
+
+		if len(event.Stage) == 0 {
+			continue
+		}
+
+		stage := timeline.findOrAddStage(event)
+
+		switch event.State {
+		case EventStateStarted:
+			stage.Steps = append(stage.Steps, TimelineStep{
+				Stage:     stage.Name,
+				Name:      event.Task,
+				State:     EventStateStarted,
+				StartedAt: event.Time(),
+			})
+
+		case EventStateFinished, EventStateFailed:
+			step := stage.findStartedStep(event.Task)
+			if step == nil {
+				// Start event might have been missed
+				stage.Steps = append(stage.Steps, TimelineStep{
+					Stage:     stage.Name,
+					Name:      event.Task,
+					StartedAt: event.Time(),
+				})
+				step = &stage.Steps[len(stage.Steps)-1]
+			}
+
+			step.State = event.State
+			step.Error = event.Data.Error
+			step.FinishedAt = event.Time()
+		}
+	}
+
+	// Steps that never finished are considered to last until the end of the task
+	for i := range timeline.Stages {
+		for j, step := range timeline.Stages[i].Steps {
+			if step.FinishedAt.IsZero() {
+				timeline.Stages[i].Steps[j].FinishedAt = timeline.FinishedAt
+			}
+		}
+	}
+
+	return timeline
+}
+
+func (t *Timeline) findOrAddStage(event Event) *TimelineStage {
+	name := event.Stage
+
+	if len(event.Tags) > 0 {
+		name += " " + strings.Join(event.Tags, ", ")
+	}
+
+	for i, stage := range t.Stages {
+		if stage.Name == name {
+			return &t.Stages[i]
+		}
+	}
+
+	t.Stages = append(t.Stages, TimelineStage{Name: name})
+
+	return &t.Stages[len(t.Stages)-1]
+}
+
+func (t Timeline) Duration() time.Duration { return t.FinishedAt.Sub(t.StartedAt) }
+
+func (t Timeline) Steps() []TimelineStep {
+	var steps []TimelineStep
+
+	for _, stage := range t.Stages {
+		steps = append(steps, stage.Steps...)
+	}
+
+	return steps
+}
+
+// CriticalPath returns the step that finished last in each stage;
+// since stages are performed one after another these steps determine
+// how long the task took and should be looked at first to speed it up
+func (t Timeline) CriticalPath() []TimelineStep {
+	var path []TimelineStep
+
+	for _, stage := range t.Stages {
+		if len(stage.Steps) == 0 {
+			continue
+		}
+
+		last := stage.Steps[0]
+
+		for _, step := range stage.Steps[1:] {
+			if step.FinishedAt.After(last.FinishedAt) ||
+				(step.FinishedAt.Equal(last.FinishedAt) && step.Duration() > last.Duration()) {
+				last = step
+			}
+		}
+
+		path = append(path, last)
+	}
+
+	return path
+}
+
+// SlowestInstances returns at most num instances with the longest total
+// duration of steps; steps that do not refer to instances
+// (including package compilation) are skipped
+func (t Timeline) SlowestInstances(num int) []TimelineInstance {
+	var instances []TimelineInstance
+
+	indices := map[string]int{}
+
+	for _, step := range t.Steps() {
+		name := step.InstanceName()
+		if len(name) == 0 || strings.HasPrefix(step.Stage, "Compiling packages") {
+			continue
+		}
+
+		idx, found := indices[name]
+		if !found {
+			idx = len(instances)
+			indices[name] = idx
+			instances = append(instances, TimelineInstance{Name: name})
+		}
+
+		instances[idx].Steps++
+		instances[idx].Duration += step.Duration()
+
+		if step.State == EventStateFailed {
+			instances[idx].Failed = true
+		}
+	}
+
+	sort.SliceStable(instances, func(i, j int) bool {
+		return instances[i].Duration > instances[j].Duration
+	})
+
+	if len(instances) > num {
+		instances = instances[:num]
+	}
+
+	return instances
+}
+
+func (s TimelineStage) StartedAt() time.Time {
+	var startedAt time.Time
+
+	for _, step := range s.Steps {
+		if startedAt.IsZero() || step.StartedAt.Before(startedAt) {
+			startedAt = step.StartedAt
+		}
+	}
+
+	return startedAt
+}
+
+func (s TimelineStage) FinishedAt() time.Time {
+	var finishedAt time.Time
+
+	for _, step := range s.Steps {
+		if step.FinishedAt.After(finishedAt) {
+			finishedAt = step.FinishedAt
+		}
+	}
+
+	return finishedAt
+}
+
+func (s TimelineStage) Duration() time.Duration { return s.FinishedAt().Sub(s.StartedAt()) }
+
+func (s *TimelineStage) findStartedStep(name string) *TimelineStep {
+	for i := len(s.Steps) - 1; i >= 0; i-- {
+		if s.Steps[i].Name == name && s.Steps[i].State == EventStateStarted {
+			return &s.Steps[i]
+		}
+	}
+
+	return nil
+}
+
+func (s TimelineStep) Duration() time.Duration { return s.FinishedAt.Sub(s.StartedAt) }
+
+// InstanceName returns instance group name and ID (e.g. "api/7d3a5c")
+// if step is performed against an instance; otherwise empty string
+func (s TimelineStep) InstanceName() string {
+	pieces := strings.Fields(s.Name)
+
+	if len(pieces) == 0 || strings.Count(pieces[0], "/") != 1 {
+		return ""
+	}
+
+	return pieces[0]
+}
@@ -0,0 +1,162 @@
+package task_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	boshuit "github.com/cloudfoundry/bosh-cli/ui/task"
+)
+
+var _ = Describe("TimelineReporter", func() {
+	var (
+		reporter *boshuit.TimelineReporter
+	)
+
+	BeforeEach(func() {
+		reporter = boshuit.NewTimelineReporter()
+	})
+
+	at := func(secs int64) time.Time { return time.Unix(1000+secs, 0).UTC() }
+
+	It("builds timeline from events split across chunks", func() {
+		reporter.TaskStarted(1)
+		reporter.TaskOutputChunk(1, []byte(`{"time":1000,"stage":"Preparing deployment","tags":[],"total":1,"task":"Binding deployment","index":1,"state":"started","progress":0}
+{"time":1002,"stage":"Preparing deployment","tags":[],"total":1,"task":"Binding deployment","index":1,"state":"in_progress","progress":50}
+{"time":1003,"stage":"Preparing deployment","tags":[],"total":1,"task":"Binding deployment","index":1,"state":"finis`))
+		reporter.TaskOutputChunk(1, []byte(`hed","progress":100}
+{"time":1004,"stage":"Updating instance","tags":["api"],"total":2,"task":"api/abc (0) (canary)","index":1,"state":"started","progress":0}
+{"time":1005,"stage":"Updating instance","tags":["api"],"total":2,"task":"api/def (1)","index":2,"state":"started","progress":0}
+{"time":1010,"stage":"Updating instance","tags":["api"],"total":2,"task":"api/abc (0) (canary)","index":1,"state":"failed","progress":100,"data":{"error":"fake-err"}}
+{"time":1011,"error":{"code":100,"message":"fake-task-err"}}
+`))
+		reporter.TaskFinished(1, "error")
+
+		Expect(reporter.State()).To(Equal("error"))
+
+		timeline, err := reporter.Timeline()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(timeline.StartedAt).To(Equal(at(0)))
+		Expect(timeline.FinishedAt).To(Equal(at(11)))
+		Expect(timeline.Duration()).To(Equal(11 * time.Second))
+		Expect(timeline.Errors).To(Equal([]string{"fake-task-err"}))
+
+		Expect(timeline.Stages).To(Equal([]boshuit.TimelineStage{
+			{
+				Name: "Preparing deployment",
+				Steps: []boshuit.TimelineStep{{
+					Stage:      "Preparing deployment",
+					Name:       "Binding deployment",
+					State:      "finished",
+					StartedAt:  at(0),
+					FinishedAt: at(3),
+				}},
+			},
+			{
+				Name: "Updating instance api",
+				Steps: []boshuit.TimelineStep{
+					{
+						Stage:      "Updating instance api",
+						Name:       "api/abc (0) (canary)",
+						State:      "failed",
+						Error:      "fake-err",
+						StartedAt:  at(4),
+						FinishedAt: at(10),
+					},
+					{
+						Stage:      "Updating instance api",
+						Name:       "api/def (1)",
+						State:      "started",
+						StartedAt:  at(5),
+						FinishedAt: at(11),
+					},
+				},
+			},
+		}))
+	})
+
+	It("returns error if event cannot be unmarshalled", func() {
+		reporter.TaskOutputChunk(1, []byte("-\n"))
+
+		_, err := reporter.Timeline()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unmarshalling task event '-'"))
+	})
+})
+
+var _ = Describe("Timeline", func() {
+	at := func(secs int64) time.Time { return time.Unix(1000+secs, 0).UTC() }
+
+	var (
+		timeline boshuit.Timeline
+	)
+
+	BeforeEach(func() {
+		timeline = boshuit.Timeline{
+			Stages: []boshuit.TimelineStage{
+				{
+					Name: "Compiling packages",
+					Steps: []boshuit.TimelineStep{
+						{Stage: "Compiling packages", Name: "ruby/abc", StartedAt: at(0), FinishedAt: at(100)},
+					},
+				},
+				{
+					Name: "Updating instance api",
+					Steps: []boshuit.TimelineStep{
+						{Stage: "Updating instance api", Name: "api/a (0)", StartedAt: at(100), FinishedAt: at(130)},
+						{Stage: "Updating instance api", Name: "api/b (1)", StartedAt: at(100), FinishedAt: at(150), State: "failed"},
+						{Stage: "Updating instance api", Name: "api/c (2)", StartedAt: at(140), FinishedAt: at(150)},
+					},
+				},
+				{
+					Name: "Updating instance db",
+					Steps: []boshuit.TimelineStep{
+						{Stage: "Updating instance db", Name: "db/a (0)", StartedAt: at(150), FinishedAt: at(190)},
+					},
+				},
+				{
+					Name: "Empty",
+				},
+			},
+		}
+	})
+
+	Describe("CriticalPath", func() {
+		It("returns the longest of steps that finished last in each stage", func() {
+			path := timeline.CriticalPath()
+			Expect(path).To(HaveLen(3))
+			Expect(path[0].Name).To(Equal("ruby/abc"))
+			Expect(path[1].Name).To(Equal("api/b (1)"))
+			Expect(path[2].Name).To(Equal("db/a (0)"))
+		})
+	})
+
+	Describe("SlowestInstances", func() {
+		It("returns instances sorted by total duration skipping package compilation", func() {
+			Expect(timeline.SlowestInstances(3)).To(Equal([]boshuit.TimelineInstance{
+				{Name: "api/b", Steps: 1, Failed: true, Duration: 50 * time.Second},
+				{Name: "db/a", Steps: 1, Duration: 40 * time.Second},
+				{Name: "api/a", Steps: 1, Duration: 30 * time.Second},
+			}))
+		})
+	})
+
+	Describe("TimelineStage", func() {
+		It("spans all of its steps", func() {
+			stage := timeline.Stages[1]
+			Expect(stage.StartedAt()).To(Equal(at(100)))
+			Expect(stage.FinishedAt()).To(Equal(at(150)))
+			Expect(stage.Duration()).To(Equal(50 * time.Second))
+		})
+	})
+
+	Describe("TimelineStep", func() {
+		It("returns instance name only for steps performed against instances", func() {
+			Expect(boshuit.TimelineStep{Name: "api/abc (0) (canary)"}.InstanceName()).To(Equal("api/abc"))
+			Expect(boshuit.TimelineStep{Name: "Binding deployment"}.InstanceName()).To(Equal(""))
+			Expect(boshuit.TimelineStep{Name: ""}.InstanceName()).To(Equal(""))
+		})
+	})
+})
@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+)
+
+const (
+	StageTimingStateFinished = "finished"
+	StageTimingStateFailed   = "failed"
+	StageTimingStateSkipped  = "skipped"
+)
+
+// StageTiming describes when performed stage started and finished,
+// including timings of its sub stages in the order they were started
+type StageTiming struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Duration   float64   `json:"duration_seconds"`
+
+	Stages []StageTiming `json:"stages,omitempty"`
+}
+
+type TimingStage interface {
+	Stage
+
+	// Timings returns timings of all stages performed so far
+	Timings() []StageTiming
+}
+
+type stageTiming struct {
+	name       string
+	state      string
+	err        error
+	startedAt  time.Time
+	finishedAt time.Time
+	stages     []*stageTiming
+}
+
+type timingStage struct {
+	stage       Stage
+	timeService clock.Clock
+
+	parent *stageTiming
+	lock   *sync.Mutex
+}
+
+// NewTimingStage records timings of stages performed via given stage
+func NewTimingStage(stage Stage, timeService clock.Clock) TimingStage {
+	return &timingStage{
+		stage:       stage,
+		timeService: timeService,
+
+		parent: &stageTiming{},
+		lock:   &sync.Mutex{},
+	}
+}
+
+func (s *timingStage) Perform(name string, closure func() error) error {
+	timing := s.start(name)
+
+	var closureErr error
+
+	err := s.stage.Perform(name, func() error {
+		closureErr = closure()
+		return closureErr
+	})
+
+	s.finish(timing, closureErr)
+
+	return err
+}
+
+func (s *timingStage) PerformComplex(name string, closure func(Stage) error) error {
+	timing := s.start(name)
+
+	var closureErr error
+
+	err := s.stage.PerformComplex(name, func(subStage Stage) error {
+		closureErr = closure(s.withStage(subStage, timing))
+		return closureErr
+	})
+
+	s.finish(timing, closureErr)
+
+	return err
+}
+
+func (s *timingStage) Concurrent() Stage {
+	return s.withStage(s.stage.Concurrent(), s.parent)
+}
+
+func (s *timingStage) Timings() []StageTiming {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.copyTimings(s.parent.stages)
+}
+
+func (s *timingStage) withStage(stage Stage, parent *stageTiming) Stage {
+	return &timingStage{
+		stage:       stage,
+		timeService: s.timeService,
+
+		parent: parent,
+		lock:   s.lock,
+	}
+}
+
+func (s *timingStage) start(name string) *stageTiming {
+	timing := &stageTiming{name: name, startedAt: s.timeService.Now()}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.parent.stages = append(s.parent.stages, timing)
+
+	return timing
+}
+
+func (s *timingStage) finish(timing *stageTiming, err error) {
+	finishedAt := s.timeService.Now()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	timing.finishedAt = finishedAt
+	timing.err = err
+
+	if err == nil {
+		timing.state = StageTimingStateFinished
+	} else if _, ok := err.(SkipStageError); ok {
+		timing.state = StageTimingStateSkipped
+	} else {
+		timing.state = StageTimingStateFailed
+	}
+}
+
+func (s *timingStage) copyTimings(timings []*stageTiming) []StageTiming {
+	var result []StageTiming
+
+	for _, timing := range timings {
+		copied := StageTiming{
+			Name:  timing.name,
+			State: timing.state,
+
+			StartedAt:  timing.startedAt,
+			FinishedAt: timing.finishedAt,
+
+			Stages: s.copyTimings(timing.stages),
+		}
+
+		if !timing.finishedAt.IsZero() {
+			copied.Duration = timing.finishedAt.Sub(timing.startedAt).Seconds()
+		}
+
+		if timing.err != nil {
+			copied.Error = timing.err.Error()
+		}
+
+		result = append(result, copied)
+	}
+
+	return result
+}
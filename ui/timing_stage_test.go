@@ -0,0 +1,152 @@
+package ui_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/cloudfoundry/bosh-cli/ui"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/pivotal-golang/clock/fakeclock"
+
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+)
+
+var _ = Describe("TimingStage", func() {
+	var (
+		startTime       time.Time
+		fakeTimeService *fakeclock.FakeClock
+		fakeStage       *fakeui.FakeStage
+		stage           TimingStage
+	)
+
+	BeforeEach(func() {
+		startTime = time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+		fakeTimeService = fakeclock.NewFakeClock(startTime)
+		fakeStage = fakeui.NewFakeStage()
+
+		stage = NewTimingStage(fakeStage, fakeTimeService)
+	})
+
+	Describe("Perform", func() {
+		It("performs stage via underlying stage and records its timing", func() {
+			err := stage.Perform("fake-stage", func() error {
+				fakeTimeService.Increment(time.Minute)
+				return nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeStage.PerformCalls).To(Equal([]*fakeui.PerformCall{{Name: "fake-stage"}}))
+
+			Expect(stage.Timings()).To(Equal([]StageTiming{
+				{
+					Name:       "fake-stage",
+					State:      "finished",
+					StartedAt:  startTime,
+					FinishedAt: startTime.Add(time.Minute),
+					Duration:   60,
+				},
+			}))
+		})
+
+		It("records failed stage", func() {
+			err := stage.Perform("fake-stage", func() error {
+				return errors.New("fake-err")
+			})
+			Expect(err).To(Equal(errors.New("fake-err")))
+
+			timings := stage.Timings()
+			Expect(timings).To(HaveLen(1))
+			Expect(timings[0].State).To(Equal("failed"))
+			Expect(timings[0].Error).To(Equal("fake-err"))
+		})
+
+		It("records skipped stage", func() {
+			err := stage.Perform("fake-stage", func() error {
+				return NewSkipStageError(bosherr.Error("fake-skip-err"), "fake-skip-msg")
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			timings := stage.Timings()
+			Expect(timings).To(HaveLen(1))
+			Expect(timings[0].State).To(Equal("skipped"))
+		})
+	})
+
+	Describe("PerformComplex", func() {
+		It("records timings of sub stages within complex stage", func() {
+			err := stage.PerformComplex("fake-complex-stage", func(subStage Stage) error {
+				err := subStage.Perform("fake-sub-stage-1", func() error {
+					fakeTimeService.Increment(time.Second)
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+
+				return subStage.Perform("fake-sub-stage-2", func() error {
+					fakeTimeService.Increment(2 * time.Second)
+					return nil
+				})
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeStage.SubStages).To(HaveLen(1))
+			Expect(fakeStage.SubStages[0].PerformCalls).To(HaveLen(2))
+
+			Expect(stage.Timings()).To(Equal([]StageTiming{
+				{
+					Name:       "fake-complex-stage",
+					State:      "finished",
+					StartedAt:  startTime,
+					FinishedAt: startTime.Add(3 * time.Second),
+					Duration:   3,
+					Stages: []StageTiming{
+						{
+							Name:       "fake-sub-stage-1",
+							State:      "finished",
+							StartedAt:  startTime,
+							FinishedAt: startTime.Add(time.Second),
+							Duration:   1,
+						},
+						{
+							Name:       "fake-sub-stage-2",
+							State:      "finished",
+							StartedAt:  startTime.Add(time.Second),
+							FinishedAt: startTime.Add(3 * time.Second),
+							Duration:   2,
+						},
+					},
+				},
+			}))
+		})
+
+		It("records failed complex stage", func() {
+			err := stage.PerformComplex("fake-complex-stage", func(subStage Stage) error {
+				return subStage.Perform("fake-sub-stage", func() error {
+					return errors.New("fake-err")
+				})
+			})
+			Expect(err).To(HaveOccurred())
+
+			timings := stage.Timings()
+			Expect(timings[0].State).To(Equal("failed"))
+			Expect(timings[0].Stages[0].State).To(Equal("failed"))
+		})
+	})
+
+	Describe("Concurrent", func() {
+		It("records timings of stages performed via concurrent stage", func() {
+			err := stage.Concurrent().Perform("fake-stage", func() error { return nil })
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeStage.ConcurrentCalled).To(BeTrue())
+
+			timings := stage.Timings()
+			Expect(timings).To(HaveLen(1))
+			Expect(timings[0].Name).To(Equal("fake-stage"))
+		})
+	})
+})
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -98,6 +99,16 @@ func (ui *WriterUI) PrintTable(table Table) {
 	}
 }
 
+func (ui *WriterUI) PrintJSON(val interface{}) {
+	bytes, err := json.MarshalIndent(val, "", "    ")
+	if err != nil {
+		ui.logger.Error(ui.logTag, "UI.PrintJSON failed: %s", err)
+		return
+	}
+
+	ui.PrintBlock(string(bytes) + "\n")
+}
+
 func (ui *WriterUI) AskForText(label string) (string, error) {
 	var text string
 
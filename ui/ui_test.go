@@ -134,6 +134,20 @@ var _ = Describe("UI", func() {
 		})
 	})
 
+	Describe("PrintJSON", func() {
+		It("prints value marshalled as indented JSON to outWriter", func() {
+			ui.PrintJSON(map[string]interface{}{"key": "val"})
+			Expect(uiOutBuffer.String()).To(Equal("{\n    \"key\": \"val\"\n}\n"))
+			Expect(uiErrBuffer.String()).To(Equal(""))
+		})
+
+		It("logs an error if value cannot be marshalled", func() {
+			ui.PrintJSON(map[string]interface{}{"key": func() {}})
+			Expect(uiOutBuffer.String()).To(Equal(""))
+			Expect(logErrBuffer.String()).To(ContainSubstring("UI.PrintJSON failed"))
+		})
+	})
+
 	Describe("PrintBlock", func() {
 		It("prints to outWriter as is", func() {
 			ui.PrintBlock("block")